@@ -0,0 +1,175 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeAvgRateRobust(t *testing.T) {
+	const step = 10 * time.Minute
+	steady := steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 12) // 50 мАч за 10 минут = 300 мАч/ч
+
+	jump := steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 6)
+	jump[3].CurrentCapacity -= 800 // скачок ёмкости – интервалы до и после него отбрасываются
+
+	charging := steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 6)
+	for i := range charging {
+		charging[i].CurrentCapacity = 3000 + i*50
+		charging[i].State = "charging"
+	}
+
+	sleep := steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 6)
+	for i := 3; i < len(sleep); i++ {
+		sleep[i].Timestamp = parseStoredTime(sleep[i].Timestamp).Add(2 * time.Hour).Format(time.RFC3339)
+	}
+
+	cases := []struct {
+		name      string
+		ms        []Measurement
+		intervals int
+		wantRate  float64
+		wantValid int
+	}{
+		{name: "пусто", ms: nil, intervals: 10},
+		{name: "одно измерение", ms: steady[:1], intervals: 10},
+		{name: "ровная разрядка", ms: steady, intervals: 10, wantRate: 300, wantValid: 10},
+		{name: "окно меньше истории", ms: steady, intervals: 3, wantRate: 300, wantValid: 3},
+		{name: "скачок ёмкости", ms: jump, intervals: 10, wantRate: 300, wantValid: 3},
+		{name: "зарядка", ms: charging, intervals: 10},
+		{name: "сон не входит в скорость", ms: sleep, intervals: 10, wantRate: 300, wantValid: 4},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, valid := computeAvgRateRobust(tc.ms, tc.intervals)
+			if math.Abs(rate-tc.wantRate) > 1e-6 {
+				t.Errorf("скорость %.2f мАч/ч, ожидалось %.2f", rate, tc.wantRate)
+			}
+			if valid != tc.wantValid {
+				t.Errorf("интервалов %d, ожидалось %d", valid, tc.wantValid)
+			}
+		})
+	}
+}
+
+func TestComputeDischargePower(t *testing.T) {
+	const step = 10 * time.Minute
+	threeCells := steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 12)
+	twoCells := fixtureHealthyBattery
+	twoCells.Voltage = 8000
+	lowVoltage := steadyDischarge(twoCells, fixtureStart, step, 12)
+	noVoltage := steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 12)
+	for i := range noVoltage {
+		noVoltage[i].Voltage = 0
+	}
+
+	// Одинаковые 300 мАч/ч при разном напряжении – разная мощность
+	cases := []struct {
+		name        string
+		ms          []Measurement
+		wantWatts   float64
+		wantNominal int
+	}{
+		{name: "12.5 В", ms: threeCells, wantWatts: 3.75, wantNominal: 11400},
+		{name: "8 В", ms: lowVoltage, wantWatts: 2.4, wantNominal: 7600},
+		{name: "без напряжения", ms: noVoltage},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			watts, _ := computeDischargePower(tc.ms, 10)
+			if math.Abs(watts-tc.wantWatts) > 1e-6 {
+				t.Errorf("мощность %.3f Вт, ожидалось %.3f", watts, tc.wantWatts)
+			}
+			if got := latestNominalVoltage(tc.ms); got != tc.wantNominal {
+				t.Errorf("номинальное напряжение %d мВ, ожидалось %d", got, tc.wantNominal)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCapacityTrend(t *testing.T) {
+	now := fixtureStart.AddDate(0, 0, 30)
+	freezeEnvironment(t, now)
+
+	// series строит n измерений через step до now с полной ёмкостью full(i)
+	series := func(n int, step time.Duration, full func(i int) int) []Measurement {
+		ms := make([]Measurement, n)
+		for i := range ms {
+			b := fixtureHealthyBattery
+			b.Full = full(i)
+			ms[i] = b.measurement(now.Add(time.Duration(i-n)*step), b.Full)
+		}
+		return ms
+	}
+	daily := func(days int, full func(i int) int) []Measurement {
+		return series(days, 24*time.Hour, full)
+	}
+
+	cases := []struct {
+		name        string
+		ms          []Measurement
+		wantHealthy bool
+		wantRate    float64 // %/мес от проектной ёмкости
+		wantDays    int     // прогноз до 80%
+	}{
+		{name: "мало измерений", ms: daily(5, func(int) int { return 4000 }), wantHealthy: true},
+		{name: "меньше недели", ms: series(12, 12*time.Hour, func(i int) int { return 4900 - i*50 }), wantHealthy: true},
+		{name: "стабильная ёмкость", ms: daily(20, func(int) int { return 4900 }), wantHealthy: true},
+		// −5 мАч в день = −150 мАч в месяц = −3% проектной; до 80% с 96.1%:
+		// 16.1/3 мес ≈ 161 день, дробная часть отбрасывается
+		{name: "быстрая деградация", ms: daily(20, func(i int) int { return 4900 - i*5 }), wantRate: -3, wantDays: 160},
+		{name: "старые измерения не учитываются", ms: append(daily(40, func(i int) int { return 3000 + i*100 })[:10],
+			daily(20, func(int) int { return 4900 })...), wantHealthy: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			trend := analyzeCapacityTrend(tc.ms)
+			if trend.IsHealthy != tc.wantHealthy {
+				t.Errorf("IsHealthy = %v, ожидалось %v (%+v)", trend.IsHealthy, tc.wantHealthy, trend)
+			}
+			if math.Abs(trend.DegradationRate-tc.wantRate) > 0.01 {
+				t.Errorf("деградация %.3f%%/мес, ожидалось %.3f", trend.DegradationRate, tc.wantRate)
+			}
+			if trend.ProjectedLifetime != tc.wantDays {
+				t.Errorf("прогноз %d дней, ожидалось %d", trend.ProjectedLifetime, tc.wantDays)
+			}
+		})
+	}
+}
+
+func TestDetectWearEvents(t *testing.T) {
+	const step = time.Hour
+	// Полная ёмкость по измерениям: износ 10.x%, шум на границе 11%, затем
+	// устойчиво 11% и скачок сразу до 13%
+	fulls := []int{4490, 4480, 4470, 4440, 4460, 4440, 4440, 4430, 4430, 4340, 4340, 4340}
+	ms := make([]Measurement, len(fulls))
+	for i, full := range fulls {
+		ms[i] = fixtureHealthyBattery.measurement(fixtureStart.Add(time.Duration(i)*step), full/2)
+		ms[i].FullChargeCap, ms[i].DesignCapacity, ms[i].CycleCount = full, 5000, 300+i
+	}
+
+	events := detectWearEvents(ms, map[string]int{})
+	var got []int
+	for _, e := range events {
+		got = append(got, e.Milestone)
+	}
+	want := []int{10, 11, 12, 13}
+	if len(got) != len(want) {
+		t.Fatalf("вехи %v, ожидалось %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("вехи %v, ожидалось %v", got, want)
+		}
+	}
+	if !events[0].Initial || events[1].Initial {
+		t.Errorf("начальной должна быть только первая веха: %+v", events[:2])
+	}
+	// 11% засчитана с шестого измерения: одиночный провал 4460 прерывает серию
+	if want := parseStoredTime(ms[5].Timestamp); !parseStoredTime(events[1].ReachedAt).Equal(want) {
+		t.Errorf("11%% в %s, ожидалось %s", events[1].ReachedAt, ms[5].Timestamp)
+	}
+	if events[2].ReachedAt != events[3].ReachedAt {
+		t.Errorf("вехи одного скачка в разное время: %s, %s", events[2].ReachedAt, events[3].ReachedAt)
+	}
+}
@@ -0,0 +1,172 @@
+// anomalies.go
+//
+// Типизированные аномалии: детектор сразу сообщает вид, серьезность, окно
+// времени и числа, на которых основан вывод, поэтому интерфейс и оповещения
+// не угадывают критичность по словам в тексте. Здесь же детекторы просадки
+// напряжения под нагрузкой, сбоев в показаниях ёмкости и повышенного
+// саморазряда во сне.
+
+package main
+
+import "time"
+
+// Виды аномалий
+const (
+	anomalyChargeJump     = "charge_jump"     // резкий рост заряда
+	anomalyChargeDrop     = "charge_drop"     // резкое падение заряда
+	anomalyStateChange    = "state_change"    // смена состояния питания
+	anomalyCapacityJump   = "capacity_jump"   // резкое изменение текущей ёмкости
+	anomalyThermal        = "thermal"         // длительный нагрев
+	anomalyVoltageSag     = "voltage_sag"     // просадка напряжения под нагрузкой
+	anomalyCapacityGlitch = "capacity_glitch" // сбой в показаниях ёмкости контроллером
+	anomalySleepDrain     = "sleep_drain"     // повышенный саморазряд во сне
+	anomalyCellImbalance  = "cell_imbalance"  // разбаланс напряжений ячеек
+)
+
+// Пороги новых детекторов
+const (
+	voltageSagLoad      = 1500             // ток разрядки, с которого нагрузка считается высокой, мА
+	voltageSagWarning   = 400              // просадка между соседними измерениями, мВ
+	voltageSagCritical  = 800              // просадка, при которой батарея может отключиться, мВ
+	capacityGlitchShare = 10.0             // выброс полной ёмкости относительно соседей, %
+	sleepGapMin         = 30 * time.Minute // пропуск в измерениях, который считается сном
+	sleepDrainMinDrop   = 3                // минимальная потеря заряда за сон, %
+	sleepDrainRate      = 1.5              // саморазряд во сне, выше которого это аномалия, %/ч
+	thermalCriticalTemp = 45               // пик перегрева, при котором он критичен, °C
+)
+
+// Anomaly – аномалия в измерениях
+type Anomaly struct {
+	Type     string             `json:"type"`
+	Severity string             `json:"severity"` // alertInfo, alertWarning или alertCritical
+	Start    time.Time          `json:"start"`
+	End      time.Time          `json:"end"`
+	Message  string             `json:"message"`
+	Metrics  map[string]float64 `json:"metrics,omitempty"`
+}
+
+// String возвращает описание аномалии для отчетов
+func (a Anomaly) String() string {
+	return a.Message
+}
+
+// Icon возвращает эмодзи серьезности
+func (a Anomaly) Icon() string {
+	return Alert{Level: a.Severity}.Icon()
+}
+
+// Window возвращает длительность окна, в котором замечена аномалия
+func (a Anomaly) Window() time.Duration {
+	return a.End.Sub(a.Start)
+}
+
+// anomalyMessages возвращает описания аномалий
+func anomalyMessages(anomalies []Anomaly) []string {
+	messages := make([]string, len(anomalies))
+	for i, a := range anomalies {
+		messages[i] = a.Message
+	}
+	return messages
+}
+
+// groupAnomalies раскладывает аномалии по серьезности
+func groupAnomalies(anomalies []Anomaly) map[string][]Anomaly {
+	groups := make(map[string][]Anomaly)
+	for _, a := range anomalies {
+		groups[a.Severity] = append(groups[a.Severity], a)
+	}
+	return groups
+}
+
+// detectVoltageSag ищет просадку напряжения при высоком токе разрядки, когда
+// заряд почти не изменился: признак растущего внутреннего сопротивления
+func detectVoltageSag(prev, curr Measurement, prevAt, currAt time.Time) (Anomaly, bool) {
+	if curr.Voltage <= 0 || prev.Voltage <= 0 || -curr.Amperage < voltageSagLoad || curr.Amperage >= prev.Amperage {
+		return Anomaly{}, false
+	}
+	sag := prev.Voltage - curr.Voltage
+	if sag < voltageSagWarning || prev.Percentage-curr.Percentage > 1 {
+		return Anomaly{}, false
+	}
+	severity := alertWarning
+	if sag >= voltageSagCritical {
+		severity = alertCritical
+	}
+	return Anomaly{
+		Type:     anomalyVoltageSag,
+		Severity: severity,
+		Start:    prevAt,
+		End:      currAt,
+		Message: T("anomaly.voltage_sag",
+			prev.Voltage, curr.Voltage, -curr.Amperage, currAt.Local().Format("15:04:05")),
+		Metrics: map[string]float64{"sag_mv": float64(sag), "current_ma": float64(-curr.Amperage)},
+	}, true
+}
+
+// detectCapacityGlitches ищет сбои контроллера: полная ёмкость на одно
+// измерение отскакивает от соседних, которые между собой совпадают, или
+// текущая ёмкость превышает полную
+func detectCapacityGlitches(ms []Measurement) []Anomaly {
+	var anomalies []Anomaly
+	for i, m := range ms {
+		at := parseStoredTime(m.Timestamp)
+		if m.FullChargeCap > 0 && m.CurrentCapacity > m.FullChargeCap*105/100 {
+			anomalies = append(anomalies, Anomaly{
+				Type:     anomalyCapacityGlitch,
+				Severity: alertInfo,
+				Start:    at,
+				End:      at,
+				Message: T("anomaly.capacity_over_full",
+					m.CurrentCapacity, m.FullChargeCap, at.Local().Format("15:04:05")),
+				Metrics: map[string]float64{"current_mah": float64(m.CurrentCapacity), "full_mah": float64(m.FullChargeCap)},
+			})
+			continue
+		}
+		if i == 0 || i == len(ms)-1 {
+			continue
+		}
+		prev, next := ms[i-1], ms[i+1]
+		if prev.FullChargeCap <= 0 || next.FullChargeCap <= 0 || prev.BatterySerial != next.BatterySerial {
+			continue
+		}
+		spike := float64(m.FullChargeCap-prev.FullChargeCap) / float64(prev.FullChargeCap) * 100
+		neighbours := float64(next.FullChargeCap-prev.FullChargeCap) / float64(prev.FullChargeCap) * 100
+		if (spike >= capacityGlitchShare || spike <= -capacityGlitchShare) && neighbours < 2 && neighbours > -2 {
+			anomalies = append(anomalies, Anomaly{
+				Type:     anomalyCapacityGlitch,
+				Severity: alertInfo,
+				Start:    parseStoredTime(prev.Timestamp),
+				End:      parseStoredTime(next.Timestamp),
+				Message: T("anomaly.capacity_spike",
+					prev.FullChargeCap, m.FullChargeCap, next.FullChargeCap, at.Local().Format("15:04:05")),
+				Metrics: map[string]float64{"spike_pct": spike},
+			})
+		}
+	}
+	return anomalies
+}
+
+// detectSleepDrain ищет пропуск в измерениях (сон) на батарее, за который
+// заряд упал быстрее sleepDrainRate: так проявляются приложения, не дающие
+// уснуть, Power Nap или неисправность батареи
+func detectSleepDrain(prev, curr Measurement, prevAt, currAt time.Time) (Anomaly, bool) {
+	gap := currAt.Sub(prevAt)
+	if !isSleepGap(prevAt, currAt) || sessionKind(prev.State) != sessionDischarge || sessionKind(curr.State) != sessionDischarge {
+		return Anomaly{}, false
+	}
+	drop := prev.Percentage - curr.Percentage
+	rate := float64(drop) / gap.Hours()
+	if drop < sleepDrainMinDrop || rate <= sleepDrainRate {
+		return Anomaly{}, false
+	}
+	return Anomaly{
+		Type:     anomalySleepDrain,
+		Severity: alertWarning,
+		Start:    prevAt,
+		End:      currAt,
+		Message: T("anomaly.sleep_drain",
+			prev.Percentage, curr.Percentage, formatDuration(gap), rate,
+			prevAt.Local().Format("02.01 15:04"), currAt.Local().Format("15:04")),
+		Metrics: map[string]float64{"drop_pct": float64(drop), "rate_pct_per_hour": rate, "hours": gap.Hours()},
+	}, true
+}
@@ -0,0 +1,207 @@
+// api.go
+//
+// REST API /api/v1 в batmon serve – точка интеграции для дашбордов парка и
+// будущих фронтендов (menubar, веб). Отдает те же данные, что CLI и экспорт,
+// теми же функциями: страницы измерений, отчет в формате JSON-выгрузки,
+// проверку здоровья batmon check и поток измерений по WebSocket. Каждый запрос – с заголовком
+// Authorization: Bearer <токен>. Токен берется из BATMON_API_TOKEN или из
+// файла api-token в папке данных, который создается при первом запуске.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	apiTokenEnv   = "BATMON_API_TOKEN"
+	apiTokenFile  = "api-token"
+	apiTokenBytes = 32
+)
+
+// apiTokenPath возвращает путь к файлу токена API
+func apiTokenPath() string {
+	dataDir, err := getDataDir()
+	if err != nil {
+		return apiTokenFile
+	}
+	return filepath.Join(dataDir, apiTokenFile)
+}
+
+// loadAPIToken возвращает токен API: из переменной окружения, из файла или
+// новый, сохраненный в файл. created – токен только что создан.
+func loadAPIToken(rotate bool) (token string, created bool, err error) {
+	if token := strings.TrimSpace(os.Getenv(apiTokenEnv)); token != "" {
+		if rotate {
+			return "", false, fmt.Errorf(T("api.err.token_in_config"), apiTokenEnv)
+		}
+		return token, false, nil
+	}
+	path := apiTokenPath()
+	if !rotate {
+		raw, err := os.ReadFile(path)
+		if err == nil && strings.TrimSpace(string(raw)) != "" {
+			return strings.TrimSpace(string(raw)), false, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", false, fmt.Errorf(T("api.err.token_read"), err)
+		}
+	}
+
+	buf := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, fmt.Errorf(T("api.err.token_create"), err)
+	}
+	token = hex.EncodeToString(buf)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", false, fmt.Errorf(T("api.err.token_save"), err)
+	}
+	return token, true, nil
+}
+
+// validToken сверяет токен из заголовка Authorization. allowQuery – токен
+// можно передать параметром access_token: браузерный WebSocket не умеет
+// выставлять заголовки.
+func validToken(r *http.Request, token string, allowQuery bool) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok && allowQuery {
+		got = r.URL.Query().Get("access_token")
+	}
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// requireToken пропускает только запросы с верным токеном
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validToken(r, token, false) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="batmon"`)
+			writeJSONError(w, http.StatusUnauthorized, errors.New(T("api.err.auth_header")))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiHealth – ответ /api/v1/health: проверка batmon check с порогами из config.json
+type apiHealth struct {
+	Status      string   `json:"status"` // ok, warning или critical
+	Code        int      `json:"code"`   // код выхода batmon check
+	Wear        float64  `json:"wear_percent"`
+	Cycles      int      `json:"cycle_count"`
+	Anomalies   int      `json:"anomalies"`
+	HealthScore int      `json:"health_score,omitempty"`
+	Problems    []string `json:"problems"`
+	Live        bool     `json:"live"` // истории нет, показатели сняты с батареи напрямую
+}
+
+// newAPIHealth переводит результат проверки в ответ API
+func newAPIHealth(check HealthCheck) apiHealth {
+	status := "ok"
+	switch check.Code {
+	case checkWarning:
+		status = "warning"
+	case checkCritical:
+		status = "critical"
+	}
+	problems := check.Problems
+	if problems == nil {
+		problems = []string{}
+	}
+	return apiHealth{
+		Status:      status,
+		Code:        check.Code,
+		Wear:        check.Wear,
+		Cycles:      check.Cycles,
+		Anomalies:   check.Anomalies,
+		HealthScore: check.Score,
+		Problems:    problems,
+		Live:        check.Live,
+	}
+}
+
+// allowedMethods возвращает значение заголовка Allow; GET-адреса ServeMux
+// обслуживает и для HEAD
+func allowedMethods(methods []string) string {
+	allow := append([]string(nil), methods...)
+	if slices.Contains(allow, http.MethodGet) && !slices.Contains(allow, http.MethodHead) {
+		allow = append(allow, http.MethodHead)
+	}
+	sort.Strings(allow)
+	return strings.Join(allow, ", ")
+}
+
+// registerAPIv1 регистрирует /api/v1 под токеном
+func registerAPIv1(mux *http.ServeMux, db *sqlx.DB, token string) {
+	routes := map[string][]string{} // путь -> методы: известному адресу с чужим методом – 405, а не 404
+	route := func(pattern string, h http.Handler) {
+		mux.Handle(pattern, h)
+		if method, path, ok := strings.Cut(pattern, " "); ok {
+			routes[path] = append(routes[path], method)
+		}
+	}
+	handle := func(pattern string, h http.HandlerFunc) {
+		route(pattern, requireToken(token, h))
+	}
+
+	handle("GET /api/v1/latest", handleLatest(db))
+	handle("GET /api/v1/measurements", handleMeasurements(db))
+
+	// ?from=7d&to=... – период, как у batmon report
+	handle("GET /api/v1/report", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		rng, err := parseReportRange(q.Get("from"), q.Get("to"), timeNow())
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		data, err := generateReportDataRange(db, rng)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newReportJSON(data))
+	})
+
+	handle("GET /api/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		check, err := collectHealthCheck(db)
+		if err != nil {
+			writeJSONError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		evaluateHealth(check, getConfig().Health)
+		writeJSON(w, http.StatusOK, newAPIHealth(*check))
+	})
+
+	route("GET /api/v1/ws", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validToken(r, token, true) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="batmon"`)
+			writeJSONError(w, http.StatusUnauthorized, errors.New(T("api.err.auth_header_ws")))
+			return
+		}
+		wsMeasurements(w, r, db)
+	}))
+
+	// Остальное под /api/v1/ перехватывает этот обработчик, поэтому 405 для
+	// известных адресов ServeMux уже не отдаст – отвечаем сами
+	handle("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		if methods, ok := routes[r.URL.Path]; ok {
+			allow := allowedMethods(methods)
+			w.Header().Set("Allow", allow)
+			writeJSONError(w, http.StatusMethodNotAllowed, fmt.Errorf(T("api.err.method"), r.Method, r.URL.Path, allow))
+			return
+		}
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf(T("api.err.not_found"), r.Method, r.URL.Path))
+	})
+}
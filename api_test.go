@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServeMuxRoutes(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(24*time.Hour))
+	db := newTestDB(t)
+	insertFixture(t, db, steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12))
+	const token = "test-token"
+	local := newServeMux(db, token, false)
+	remote := newServeMux(db, token, true)
+
+	cases := []struct {
+		name       string
+		mux        *http.ServeMux
+		method     string
+		target     string
+		host       string // пусто – example.com
+		remoteAddr string // пусто – 192.0.2.1:1234
+		auth       bool
+		status     int
+		allow      string
+	}{
+		{"v1 с токеном", local, "GET", "/api/v1/latest", "", "", true, http.StatusOK, ""},
+		{"v1 без токена", local, "GET", "/api/v1/latest", "127.0.0.1:8787", "127.0.0.1:5000", false, http.StatusUnauthorized, ""},
+		{"v1 чужой метод", local, "POST", "/api/v1/latest", "", "", true, http.StatusMethodNotAllowed, "GET, HEAD"},
+		{"v1 чужой метод ws", local, "DELETE", "/api/v1/ws", "", "", true, http.StatusMethodNotAllowed, "GET, HEAD"},
+		{"v1 чужой метод без токена", local, "POST", "/api/v1/latest", "", "", false, http.StatusUnauthorized, ""},
+		{"v1 неизвестный адрес", local, "GET", "/api/v1/nope", "", "", true, http.StatusNotFound, ""},
+		{"без версии с loopback", local, "GET", "/api/latest", "127.0.0.1:8787", "127.0.0.1:5000", false, http.StatusOK, ""},
+		{"без версии localhost ipv6", local, "GET", "/api/latest", "[::1]:8787", "[::1]:5000", false, http.StatusOK, ""},
+		{"без версии, чужой Host", local, "GET", "/api/latest", "evil.example:8787", "127.0.0.1:5000", false, http.StatusForbidden, ""},
+		{"без версии не с loopback", local, "GET", "/api/latest", "127.0.0.1:8787", "", false, http.StatusForbidden, ""},
+		{"без версии по сети без токена", remote, "GET", "/api/latest", "", "", false, http.StatusUnauthorized, ""},
+		{"без версии по сети с токеном", remote, "GET", "/api/latest", "", "", true, http.StatusOK, ""},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, c.target, nil)
+		if c.host != "" {
+			r.Host = c.host
+		}
+		if c.remoteAddr != "" {
+			r.RemoteAddr = c.remoteAddr
+		}
+		if c.auth {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		c.mux.ServeHTTP(w, r)
+		if w.Code != c.status {
+			t.Errorf("%s: %s %s – код %d, ожидался %d: %s", c.name, c.method, c.target, w.Code, c.status, w.Body)
+		}
+		if got := w.Header().Get("Allow"); got != c.allow {
+			t.Errorf("%s: Allow %q, ожидался %q", c.name, got, c.allow)
+		}
+	}
+}
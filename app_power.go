@@ -0,0 +1,448 @@
+// app_power.go
+//
+// Выборки энергопотребления по приложениям (powermetrics или top -o power)
+// и сводка за период: какие приложения сколько мАч/Вт·ч батареи израсходовали.
+// powermetrics точнее, но требует root: он используется, если batmon запущен
+// от root или sudo разрешает его без пароля, иначе – top.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	appSampleInterval = 5 * time.Minute  // как часто снимать список приложений
+	appSampleTopN     = 10               // сколько процессов сохранять за одну выборку
+	appSampleMaxAge   = 15 * time.Minute // выборка старше этого не относится к интервалу разрядки
+)
+
+const appPowerSchema = `CREATE TABLE IF NOT EXISTS app_power_samples (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp TEXT NOT NULL,
+	pid INTEGER,
+	app TEXT NOT NULL,
+	power REAL DEFAULT 0,
+	source TEXT DEFAULT 'top'
+);`
+
+// Источники выборок по приложениям
+const (
+	appSourceTop          = "top"
+	appSourcePowermetrics = "powermetrics"
+)
+
+// AppPowerSample – энергопотребление процесса в момент выборки.
+// Power – «Energy Impact» из top: безразмерная, но сравнимая между процессами величина.
+type AppPowerSample struct {
+	ID        int     `db:"id"`
+	Timestamp string  `db:"timestamp"`
+	PID       int     `db:"pid"`
+	App       string  `db:"app"`
+	Power     float64 `db:"power"`
+	Source    string  `db:"source"` // top или powermetrics
+}
+
+// AppEnergyUsage – итог по приложению за период
+type AppEnergyUsage struct {
+	App      string
+	MAh      float64 // израсходовано батареи, мАч
+	Wh       float64 // израсходовано батареи, Вт·ч
+	AvgPower float64 // среднее Energy Impact в выборках
+	MaxPower float64 // пиковое Energy Impact
+	Samples  int
+}
+
+// appPowerSampler выбирает способ выборки: при первом вызове пробует
+// powermetrics и, если он недоступен без пароля, дальше использует только top
+type appPowerSampler struct {
+	checked         bool
+	usePowermetrics bool
+}
+
+// sample снимает топ процессов по энергопотреблению
+func (s *appPowerSampler) sample() ([]AppPowerSample, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, nil // выборка по приложениям пока есть только на macOS
+	}
+	if !s.checked || s.usePowermetrics {
+		samples, err := samplePowermetrics()
+		if !s.checked {
+			s.checked = true
+			s.usePowermetrics = err == nil
+			if err != nil {
+				logInfof("ℹ️ powermetrics недоступен (%v), выборка приложений через top", err)
+			}
+		}
+		if err == nil {
+			return samples, nil
+		}
+	}
+	return sampleTop()
+}
+
+// sampleTop снимает топ процессов через top.
+// Первая выборка top всегда нулевая, поэтому берём две и разбираем последнюю.
+func sampleTop() ([]AppPowerSample, error) {
+	out, err := runCommand("top", "-l", "2", "-o", "power", "-n", strconv.Itoa(appSampleTopN), "-stats", "pid,command,power")
+	if err != nil {
+		return nil, fmt.Errorf("top: %w", err)
+	}
+	return parseTopPowerOutput(out, timeNow().UTC().Format(time.RFC3339)), nil
+}
+
+// samplePowermetrics снимает топ процессов через powermetrics
+func samplePowermetrics() ([]AppPowerSample, error) {
+	out, err := runPowermetrics("--samplers", "tasks", "--show-process-energy")
+	if err != nil {
+		return nil, err
+	}
+	samples := parsePowermetricsTasks(out, timeNow().UTC().Format(time.RFC3339), appSampleTopN)
+	if len(samples) == 0 {
+		return nil, errors.New(T("apps.err.no_processes"))
+	}
+	return samples, nil
+}
+
+// parsePowermetricsTasks разбирает таблицу "*** Running tasks ***" вывода
+// powermetrics --show-process-energy. Имя процесса может содержать пробелы
+// и цифры, поэтому число числовых колонок после ID определяется по блоку:
+// это наименьшее число числовых полей в конце строки. Energy Impact – последняя колонка.
+func parsePowermetricsTasks(out []byte, timestamp string, topN int) []AppPowerSample {
+	var rows [][]string
+	inTasks := false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "*** Running tasks"):
+			inTasks = true
+			continue
+		case strings.HasPrefix(line, "***"):
+			inTasks = false
+			continue
+		case !inTasks || line == "" || strings.HasPrefix(line, "Name "):
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] == "ALL_TASKS" || fields[0] == "DEAD_TASKS" {
+			continue
+		}
+		rows = append(rows, fields)
+	}
+
+	trailingNumbers := func(fields []string) int {
+		n := 0
+		for i := len(fields) - 1; i > 0; i-- {
+			if _, err := strconv.ParseFloat(fields[i], 64); err != nil {
+				break
+			}
+			n++
+		}
+		return n
+	}
+	numeric := 0 // ID + числовые колонки
+	for _, fields := range rows {
+		if n := trailingNumbers(fields); n > 0 && (numeric == 0 || n < numeric) {
+			numeric = n
+		}
+	}
+	if numeric < 2 {
+		return nil
+	}
+
+	var samples []AppPowerSample
+	for _, fields := range rows {
+		if trailingNumbers(fields) < numeric {
+			continue
+		}
+		idIdx := len(fields) - numeric
+		pid, err := strconv.Atoi(fields[idIdx])
+		if err != nil || pid <= 0 {
+			continue
+		}
+		power, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil || power <= 0 {
+			continue
+		}
+		samples = append(samples, AppPowerSample{
+			Timestamp: timestamp,
+			PID:       pid,
+			App:       strings.Join(fields[:idIdx], " "),
+			Power:     power,
+			Source:    appSourcePowermetrics,
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Power > samples[j].Power })
+	if len(samples) > topN {
+		samples = samples[:topN]
+	}
+	return samples
+}
+
+// parseTopPowerOutput разбирает последний блок вывода `top -stats pid,command,power`.
+// Имя команды может содержать пробелы: PID – первое поле, POWER – последнее.
+func parseTopPowerOutput(out []byte, timestamp string) []AppPowerSample {
+	var samples []AppPowerSample
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && fields[0] == "PID" && fields[len(fields)-1] == "POWER" {
+			samples = samples[:0] // начался новый блок – нужен только последний
+			continue
+		}
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		power, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil || power <= 0 {
+			continue
+		}
+		samples = append(samples, AppPowerSample{
+			Timestamp: timestamp,
+			PID:       pid,
+			App:       strings.Join(fields[1:len(fields)-1], " "),
+			Power:     power,
+			Source:    appSourceTop,
+		})
+	}
+	return samples
+}
+
+// insertAppPowerSamples сохраняет одну выборку в БД
+func insertAppPowerSamples(db *sqlx.DB, samples []AppPowerSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf(T("err.transaction"), err)
+	}
+	for _, s := range samples {
+		if _, err := tx.Exec(`INSERT INTO app_power_samples (timestamp, pid, app, power, source) VALUES (?, ?, ?, ?, ?)`,
+			s.Timestamp, s.PID, s.App, s.Power, s.Source); err != nil {
+			tx.Rollback()
+			return fmt.Errorf(T("apps.err.save"), err)
+		}
+	}
+	return tx.Commit()
+}
+
+// appsReportRange возвращает период сводки по приложениям: период отчета
+// или последняя неделя, если период не задан
+func appsReportRange(rng ReportRange, now time.Time) ReportRange {
+	if rng.IsZero() {
+		return ReportRange{From: now.AddDate(0, 0, -7), To: now}
+	}
+	if rng.To.IsZero() {
+		rng.To = now
+	}
+	return rng
+}
+
+// appsPeriodLabel возвращает подпись периода для заголовков сводки по приложениям
+func appsPeriodLabel(rng ReportRange) string {
+	if rng.IsZero() {
+		return T("apps.period.week")
+	}
+	return T("apps.period.range")
+}
+
+// computeTopAppsEnergy распределяет израсходованную батарею между приложениями
+// за период rng. Каждый интервал разрядки между измерениями делится
+// пропорционально долям Energy Impact в ближайшей предшествующей выборке.
+// Выборка, снятая незадолго до начала периода, тоже учитывается.
+func computeTopAppsEnergy(db *sqlx.DB, rng ReportRange, limit int) ([]AppEnergyUsage, error) {
+	fromStr := rng.From.UTC().Format(time.RFC3339)
+	toStr := rng.To.UTC().Format(time.RFC3339)
+	sampleFromStr := rng.From.Add(-appSampleMaxAge).UTC().Format(time.RFC3339)
+
+	var samples []AppPowerSample
+	if err := db.Select(&samples, `SELECT * FROM app_power_samples WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp`,
+		sampleFromStr, toStr); err != nil {
+		return nil, fmt.Errorf(T("apps.err.read"), err)
+	}
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	var ms []Measurement
+	if err := db.Select(&ms, `SELECT * FROM measurements WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp`,
+		fromStr, toStr); err != nil {
+		return nil, fmt.Errorf(T("apps.err.measurements"), err)
+	}
+
+	// Группируем выборки по моменту снятия
+	type snapshot struct {
+		at    time.Time
+		total float64
+		byApp map[string]float64
+	}
+	var snapshots []snapshot
+	for _, s := range samples {
+		t, err := time.Parse(time.RFC3339, s.Timestamp)
+		if err != nil {
+			continue
+		}
+		if len(snapshots) == 0 || !snapshots[len(snapshots)-1].at.Equal(t) {
+			snapshots = append(snapshots, snapshot{at: t, byApp: map[string]float64{}})
+		}
+		snap := &snapshots[len(snapshots)-1]
+		snap.byApp[s.App] += s.Power
+		snap.total += s.Power
+	}
+
+	usage := map[string]*AppEnergyUsage{}
+	get := func(app string) *AppEnergyUsage {
+		if u, ok := usage[app]; ok {
+			return u
+		}
+		u := &AppEnergyUsage{App: app}
+		usage[app] = u
+		return u
+	}
+
+	// Средняя и пиковая нагрузка – по всем выборкам
+	for _, snap := range snapshots {
+		for app, power := range snap.byApp {
+			u := get(app)
+			u.AvgPower += power
+			u.Samples++
+			if power > u.MaxPower {
+				u.MaxPower = power
+			}
+		}
+	}
+
+	// Атрибуция израсходованной ёмкости
+	si := 0
+	for i := 1; i < len(ms); i++ {
+		prev, curr := ms[i-1], ms[i]
+		if curr.State != "discharging" || prev.State != "discharging" {
+			continue
+		}
+		drop := prev.CurrentCapacity - curr.CurrentCapacity
+		if drop <= 0 || drop > 500 { // зарядка, нет изменений или аномальный скачок
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, prev.Timestamp)
+		if err != nil {
+			continue
+		}
+		for si+1 < len(snapshots) && !snapshots[si+1].at.After(start) {
+			si++
+		}
+		snap := snapshots[si]
+		if snap.at.After(start) || start.Sub(snap.at) > appSampleMaxAge || snap.total == 0 {
+			continue
+		}
+
+		voltage := curr.Voltage
+		if voltage == 0 {
+			voltage = prev.Voltage
+		}
+		for app, power := range snap.byApp {
+			share := power / snap.total
+			u := get(app)
+			u.MAh += float64(drop) * share
+			u.Wh += float64(drop) * float64(voltage) / 1e6 * share // мАч × мВ = мкВт·ч
+		}
+	}
+
+	result := make([]AppEnergyUsage, 0, len(usage))
+	for _, u := range usage {
+		if u.Samples > 0 {
+			u.AvgPower /= float64(u.Samples)
+		}
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MAh != result[j].MAh {
+			return result[i].MAh > result[j].MAh
+		}
+		return result[i].AvgPower > result[j].AvgPower
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// topAppNames возвращает имена первых n приложений для рекомендаций
+func topAppNames(apps []AppEnergyUsage, n int) string {
+	var names []string
+	for i, a := range apps {
+		if i >= n {
+			break
+		}
+		names = append(names, T("apps.top_item", a.App, a.MAh))
+	}
+	return strings.Join(names, ", ")
+}
+
+// withTopApps заменяет совет встроенного правила high_drain списком
+// приложений, больше всего расходовавших батарею
+func withTopApps(recs []Recommendation, apps []AppEnergyUsage) []Recommendation {
+	if len(apps) == 0 {
+		return recs
+	}
+	out := make([]Recommendation, len(recs))
+	for i, r := range recs {
+		if r.RuleID == "high_drain" {
+			r.Text = T("rec.high_drain_apps", topAppNames(apps, 3))
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// runAppsCommand – batmon apps: какие приложения расходовали батарею за период
+func runAppsCommand(args []string) error {
+	fs := newCommandFlags("apps")
+	top := fs.Int("top", appSampleTopN, localized("flag.apps.top", "сколько приложений показать"))
+	reportRange := addRangeFlags(fs)
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	rng, err := reportRange()
+	if err != nil {
+		return err
+	}
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	window := appsReportRange(rng, time.Now())
+	apps, err := computeTopAppsEnergy(db, window, *top)
+	if err != nil {
+		return err
+	}
+	fmt.Println(T("apps.title",
+		window.From.Local().Format("02.01.2006 15:04"), window.To.Local().Format("02.01.2006 15:04")))
+	if len(apps) == 0 {
+		fmt.Println(T("apps.empty"))
+		return nil
+	}
+	fmt.Printf("%3s  %-30s %10s %10s %14s\n", "#", T("apps.col.app"), T("unit.mah"), T("apps.col.wh"), "Energy Impact")
+	for i, app := range apps {
+		fmt.Printf("%3d  %-30s %10.0f %10.2f %6.1f / %5.1f\n",
+			i+1, truncateString(app.App, 30), app.MAh, app.Wh, app.AvgPower, app.MaxPower)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// backup.go
+//
+// Резервные копии БД: `batmon db backup` и `batmon db restore`, а перед
+// необратимыми операциями (очистка данных, удаление старых измерений, откат
+// схемы) – автоматическая копия в папке backups. Копия снимается через
+// VACUUM INTO: это согласованный снимок работающей базы без остановки сбора.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	autoBackupPrefix = "batmon-auto-"
+	autoBackupKeep   = 5 // сколько автоматических копий хранить
+)
+
+// getBackupDir возвращает папку автоматических резервных копий
+func getBackupDir() (string, error) {
+	dataDir, err := getDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(dataDir, "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf(T("backup.err.dir"), err)
+	}
+	return dir, nil
+}
+
+// backupDatabase сохраняет снимок БД в path. Снимок пишется во временный
+// файл рядом и проверяется, поэтому прерванная копия не затирает прежнюю.
+func backupDatabase(db *sqlx.DB, path string) error {
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if _, err := db.Exec("VACUUM INTO ?", tmp); err != nil {
+		return fmt.Errorf(T("backup.err.snapshot"), err)
+	}
+	if err := verifyBackup(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf(T("err.rename"), path, err)
+	}
+	return nil
+}
+
+// verifyBackup проверяет, что файл – целая БД batmon, схема которой не новее программы
+func verifyBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf(T("err.backup"), err)
+	}
+	db, err := sqlx.Connect("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf(T("backup.err.open"), err)
+	}
+	defer db.Close()
+	var check string
+	if err := db.Get(&check, "PRAGMA quick_check"); err != nil {
+		return fmt.Errorf(T("backup.err.check"), err)
+	}
+	if check != "ok" {
+		return fmt.Errorf(T("backup.err.corrupt"), check)
+	}
+	var tables int
+	if err := db.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'measurements'"); err != nil || tables == 0 {
+		return fmt.Errorf(T("backup.err.foreign"), path)
+	}
+	var version int
+	if err := db.Get(&version, "SELECT COALESCE(MAX(version), 0) FROM schema_version"); err == nil && version > latestSchemaVersion() {
+		return fmt.Errorf(T("backup.err.newer"), version, latestSchemaVersion())
+	}
+	return nil
+}
+
+// autoBackup сохраняет копию БД перед необратимой операцией и удаляет
+// старые автоматические копии сверх autoBackupKeep
+func autoBackup(db *sqlx.DB, reason string) (string, error) {
+	dir, err := getBackupDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s%s-%s.sqlite", autoBackupPrefix, time.Now().Format("20060102-150405"), reason)
+	path := filepath.Join(dir, name)
+	if err := backupDatabase(db, path); err != nil {
+		return "", fmt.Errorf(T("backup.err.before"), reason, err)
+	}
+	logInfof("💾 Резервная копия БД: %s", path)
+	pruneAutoBackups(dir)
+	return path, nil
+}
+
+// autoBackupFile делает автоматическую копию файла БД, если он есть
+func autoBackupFile(dbPath, reason string) (string, error) {
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	db, err := sqlx.Connect("sqlite3", dbPath)
+	if err != nil {
+		return "", fmt.Errorf(T("err.db_connect"), err)
+	}
+	defer db.Close()
+	return autoBackup(db, reason)
+}
+
+// pruneAutoBackups оставляет только последние автоматические копии;
+// копии, сделанные вручную, не трогаются
+func pruneAutoBackups(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), autoBackupPrefix) && strings.HasSuffix(e.Name(), ".sqlite") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names) // в имени время – по алфавиту значит по возрасту
+	for len(names) > autoBackupKeep {
+		os.Remove(filepath.Join(dir, names[0]))
+		names = names[1:]
+	}
+}
+
+// restoreDatabase заменяет текущую БД резервной копией src. Текущая база
+// сначала копируется в backups, поэтому восстановление тоже обратимо.
+// Сбор данных (TUI, фоновый режим) на время восстановления нужно остановить.
+func restoreDatabase(src string) (string, error) {
+	if err := verifyBackup(src); err != nil {
+		return "", err
+	}
+	dbPath := getDBPath()
+	previous, err := autoBackupFile(dbPath, "restore")
+	if err != nil {
+		return "", err
+	}
+
+	// Копируем во временный файл рядом с базой и переименовываем: при сбое
+	// посреди копирования текущая база останется на месте
+	raw, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf(T("backup.err.read"), err)
+	}
+	tmp := dbPath + ".restore"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return "", fmt.Errorf(T("backup.err.write"), err)
+	}
+	for _, file := range []string{dbPath + "-wal", dbPath + "-shm"} {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmp)
+			return "", fmt.Errorf(T("err.remove"), file, err)
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf(T("backup.err.replace"), err)
+	}
+	return previous, nil
+}
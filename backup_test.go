@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// useTestDataDir направляет базу и папку backups во временную папку
+func useTestDataDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+	orig := dbPathOverride
+	t.Cleanup(func() { dbPathOverride = orig })
+	dbPathOverride = filepath.Join(dir, "batmon.sqlite")
+	return dir
+}
+
+func allMeasurements(t *testing.T, path string) []Measurement {
+	t.Helper()
+	db, err := sqlx.Connect("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var ms []Measurement
+	if err := db.Select(&ms, `SELECT * FROM measurements ORDER BY id`); err != nil {
+		t.Fatal(err)
+	}
+	return ms
+}
+
+// Копия, восстановленная поверх измененной базы, возвращает ее прежние строки,
+// а замененная база остается в backups
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(24*time.Hour))
+	dir := useTestDataDir(t)
+	dbPath := getDBPath()
+
+	db, err := initDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insertFixture(t, db, steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12))
+	backup := filepath.Join(dir, "manual.sqlite")
+	if err := backupDatabase(db, backup); err != nil {
+		t.Fatalf("резервная копия: %v", err)
+	}
+	if _, err := os.Stat(backup + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("после копии остался временный файл: %v", err)
+	}
+	var saved []Measurement
+	if err := db.Select(&saved, `SELECT * FROM measurements ORDER BY id`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`DELETE FROM measurements WHERE id > 4`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	previous, err := restoreDatabase(backup)
+	if err != nil {
+		t.Fatalf("восстановление: %v", err)
+	}
+	if restored := allMeasurements(t, dbPath); !reflect.DeepEqual(restored, saved) {
+		t.Errorf("восстановлено %d измерений, ожидалось %d как в копии", len(restored), len(saved))
+	}
+	if previous == "" {
+		t.Fatal("замененная база не сохранена")
+	}
+	if replaced := allMeasurements(t, previous); len(replaced) != 4 {
+		t.Errorf("в копии замененной базы %d измерений, ожидалось 4", len(replaced))
+	}
+}
+
+// Поврежденная или чужая копия отклоняется до того, как тронуть текущую базу
+func TestRestoreRejectsBadBackup(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(24*time.Hour))
+	dir := useTestDataDir(t)
+	dbPath := getDBPath()
+
+	db, err := initDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insertFixture(t, db, steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12))
+	good := filepath.Join(dir, "good.sqlite")
+	if err := backupDatabase(db, good); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	raw, err := os.ReadFile(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := sqlx.Connect("sqlite3", filepath.Join(dir, "foreign.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign.MustExec(`CREATE TABLE notes (id INTEGER PRIMARY KEY, text TEXT)`)
+	foreign.Close()
+
+	cases := []struct {
+		name    string
+		content []byte // nil – файл уже создан или его нет
+		want    string // подстрока ошибки
+	}{
+		{"garbage.sqlite", []byte("это не база данных, а просто текст"), "резервн"},
+		{"truncated.sqlite", raw[:len(raw)/2], "резервн"},
+		{"foreign.sqlite", nil, "нет таблицы measurements"},
+		{"missing.sqlite", nil, "резервная копия"},
+	}
+	current, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		path := filepath.Join(dir, c.name)
+		if c.content != nil {
+			if err := os.WriteFile(path, c.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		previous, err := restoreDatabase(path)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: ошибка %v, ожидалась %q", c.name, err, c.want)
+		}
+		if previous != "" {
+			t.Errorf("%s: сделана копия текущей базы %s", c.name, previous)
+		}
+		if after, err := os.ReadFile(dbPath); err != nil || !bytes.Equal(after, current) {
+			t.Errorf("%s: текущая база изменилась (%v)", c.name, err)
+		}
+		if _, err := os.Stat(dbPath + ".restore"); !os.IsNotExist(err) {
+			t.Errorf("%s: остался временный файл восстановления", c.name)
+		}
+	}
+	if ms := allMeasurements(t, dbPath); len(ms) != 12 {
+		t.Errorf("в базе %d измерений, ожидалось 12", len(ms))
+	}
+}
@@ -0,0 +1,142 @@
+// baseline.go
+//
+// Базовая точка износа: первая запись о ёмкости батареи с момента установки
+// batmon. Сравнение с ней не зависит от периода отчета и показывает
+// личный тренд за всё время наблюдений.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// baselineSchema – базовая точка для каждой батареи (по серийному номеру)
+const baselineSchema = `
+CREATE TABLE IF NOT EXISTS battery_baseline (
+	battery_serial TEXT PRIMARY KEY,
+	recorded_at TEXT NOT NULL,
+	full_charge_capacity INTEGER NOT NULL,
+	design_capacity INTEGER NOT NULL,
+	cycle_count INTEGER NOT NULL DEFAULT 0,
+	os_version TEXT NOT NULL DEFAULT ''
+);`
+
+// BatteryBaseline – первое известное состояние батареи
+type BatteryBaseline struct {
+	BatterySerial  string `db:"battery_serial"`
+	RecordedAt     string `db:"recorded_at"` // RFC3339 UTC
+	FullChargeCap  int    `db:"full_charge_capacity"`
+	DesignCapacity int    `db:"design_capacity"`
+	CycleCount     int    `db:"cycle_count"`
+	OSVersion      string `db:"os_version"` // пусто, если точка восстановлена из старых измерений
+}
+
+// Date возвращает дату базовой точки в локальном часовом поясе
+func (b BatteryBaseline) Date() string {
+	t, err := time.Parse(time.RFC3339, b.RecordedAt)
+	if err != nil {
+		return b.RecordedAt
+	}
+	return t.Local().Format("02.01.2006")
+}
+
+// CapacityDelta возвращает изменение полной ёмкости относительно базовой точки (мАч, %)
+func (b BatteryBaseline) CapacityDelta(latest Measurement) (int, float64) {
+	if b.FullChargeCap <= 0 || latest.FullChargeCap <= 0 {
+		return 0, 0
+	}
+	delta := latest.FullChargeCap - b.FullChargeCap
+	return delta, float64(delta) / float64(b.FullChargeCap) * 100
+}
+
+// Summary возвращает строку вида "с момента установки batmon: −312 мАч (−3.5%)"
+func (b BatteryBaseline) Summary(latest Measurement) string {
+	delta, pct := b.CapacityDelta(latest)
+	return T("baseline.summary", signedInt(delta), signedFloat(pct))
+}
+
+// signedInt форматирует число с явным знаком и типографским минусом
+func signedInt(v int) string {
+	switch {
+	case v < 0:
+		return fmt.Sprintf("−%d", -v)
+	case v > 0:
+		return fmt.Sprintf("+%d", v)
+	}
+	return "±0"
+}
+
+// signedFloat форматирует процент с явным знаком и типографским минусом
+func signedFloat(v float64) string {
+	s := fmt.Sprintf("%.1f", v)
+	switch {
+	case s == "0.0" || s == "-0.0":
+		return "±0.0"
+	case v < 0:
+		return "−" + s[1:]
+	}
+	return "+" + s
+}
+
+// getBaseline возвращает базовую точку батареи или nil, если её еще нет
+func getBaseline(db *sqlx.DB, serial string) (*BatteryBaseline, error) {
+	var b BatteryBaseline
+	err := db.Get(&b, `SELECT * FROM battery_baseline WHERE battery_serial = ?`, serial)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("baseline.err.read"), err)
+	}
+	return &b, nil
+}
+
+// ensureBaseline записывает базовую точку для батареи из измерения, если её
+// еще нет. Для давних пользователей точка берется из самого раннего измерения
+// этой батареи в БД, чтобы тренд начинался с установки batmon, а не с обновления.
+func ensureBaseline(db *sqlx.DB, m Measurement) error {
+	if m.FullChargeCap <= 0 || m.DesignCapacity <= 0 {
+		return nil
+	}
+	if b, err := getBaseline(db, m.BatterySerial); err != nil || b != nil {
+		return err
+	}
+
+	first := m
+	osVer := osVersion()
+	var earliest Measurement
+	err := db.Get(&earliest, `SELECT * FROM measurements
+		WHERE full_charge_capacity > 0 AND design_capacity > 0 AND battery_serial = ?
+		ORDER BY timestamp ASC LIMIT 1`, m.BatterySerial)
+	if err == nil && earliest.Timestamp < m.Timestamp {
+		first = earliest
+		osVer = "" // версия ОС на момент старого измерения неизвестна
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf(T("baseline.err.first"), err)
+	}
+
+	_, err = db.Exec(`INSERT OR IGNORE INTO battery_baseline
+		(battery_serial, recorded_at, full_charge_capacity, design_capacity, cycle_count, os_version)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		m.BatterySerial, first.Timestamp, first.FullChargeCap, first.DesignCapacity, first.CycleCount, osVer)
+	if err != nil {
+		return fmt.Errorf(T("baseline.err.write"), err)
+	}
+	return nil
+}
+
+// currentBaseline возвращает базовую точку для батареи из последнего измерения.
+// Только читает БД: точку записывает коллектор, а отчеты и выгрузки не должны
+// менять базу.
+func currentBaseline(db *sqlx.DB, latest Measurement) *BatteryBaseline {
+	b, err := getBaseline(db, latest.BatterySerial)
+	if err != nil {
+		return nil
+	}
+	return b
+}
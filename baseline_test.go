@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Отчет только читает базовую точку: записывает ее коллектор
+func TestReportDoesNotWriteBaseline(t *testing.T) {
+	ms := steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 20)
+	freezeEnvironment(t, parseStoredTime(ms[len(ms)-1].Timestamp).Add(10*time.Minute))
+	db := newTestDB(t)
+	for i := range ms {
+		if err := insertMeasurement(db, &ms[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := generateReportData(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Baseline != nil {
+		t.Errorf("базовая точка без записи коллектора: %+v", data.Baseline)
+	}
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM battery_baseline`); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("отчет записал %d базовых точек", count)
+	}
+
+	if err := ensureBaseline(db, ms[len(ms)-1]); err != nil {
+		t.Fatal(err)
+	}
+	if b := currentBaseline(db, ms[len(ms)-1]); b == nil || b.RecordedAt != ms[0].Timestamp {
+		t.Errorf("базовая точка %+v, ожидалась от %s", b, ms[0].Timestamp)
+	}
+}
@@ -0,0 +1,135 @@
+// battery_identity.go
+//
+// Идентификация батареи по серийному номеру и сегментация истории
+// после замены аккумулятора.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BatteryReplacement описывает момент смены батареи (по серийному номеру)
+type BatteryReplacement struct {
+	Timestamp string `db:"timestamp"` // первое измерение с новой батареей (RFC3339 UTC)
+	OldSerial string `db:"old_serial"`
+	NewSerial string `db:"new_serial"`
+}
+
+// Date возвращает дату замены в локальном часовом поясе
+func (r BatteryReplacement) Date() string {
+	t, err := time.Parse(time.RFC3339, r.Timestamp)
+	if err != nil {
+		return r.Timestamp
+	}
+	return t.Local().Format("02.01.2006")
+}
+
+// Marker возвращает строку-маркер для отчетов и графиков
+func (r BatteryReplacement) Marker() string {
+	return T("replacement.marker", r.Date())
+}
+
+// splitBatterySegments делит измерения на отрезки по серийному номеру батареи.
+// Измерения без серийного номера (старые записи, другие платформы) не считаются
+// сменой батареи и присоединяются к текущему отрезку.
+func splitBatterySegments(ms []Measurement) [][]Measurement {
+	if len(ms) == 0 {
+		return nil
+	}
+
+	var segments [][]Measurement
+	start := 0
+	serial := ""
+	for i, m := range ms {
+		if m.BatterySerial == "" {
+			continue
+		}
+		if serial != "" && m.BatterySerial != serial {
+			segments = append(segments, ms[start:i])
+			start = i
+		}
+		serial = m.BatterySerial
+	}
+	return append(segments, ms[start:])
+}
+
+// currentBatterySegment возвращает измерения только для установленной сейчас батареи
+func currentBatterySegment(ms []Measurement) []Measurement {
+	segments := splitBatterySegments(ms)
+	if len(segments) == 0 {
+		return ms
+	}
+	return segments[len(segments)-1]
+}
+
+// detectBatteryReplacements находит замены батареи в наборе измерений
+func detectBatteryReplacements(ms []Measurement) []BatteryReplacement {
+	segments := splitBatterySegments(ms)
+	var replacements []BatteryReplacement
+	for i := 1; i < len(segments); i++ {
+		prev := segments[i-1]
+		first := segments[i][0]
+		oldSerial := ""
+		for j := len(prev) - 1; j >= 0; j-- {
+			if prev[j].BatterySerial != "" {
+				oldSerial = prev[j].BatterySerial
+				break
+			}
+		}
+		replacements = append(replacements, BatteryReplacement{
+			Timestamp: first.Timestamp,
+			OldSerial: oldSerial,
+			NewSerial: first.BatterySerial,
+		})
+	}
+	return replacements
+}
+
+// getBatteryReplacements ищет замены батареи по всей истории в БД,
+// а не только в окне измерений, попавших в отчет.
+func getBatteryReplacements(db *sqlx.DB) ([]BatteryReplacement, error) {
+	var replacements []BatteryReplacement
+	query := `SELECT timestamp, old_serial, new_serial FROM (
+		SELECT timestamp,
+			battery_serial AS new_serial,
+			LAG(battery_serial) OVER (ORDER BY timestamp) AS old_serial
+		FROM measurements
+		WHERE battery_serial != ''
+	) WHERE old_serial IS NOT NULL AND old_serial != new_serial
+	ORDER BY timestamp`
+	if err := db.Select(&replacements, query); err != nil {
+		return nil, fmt.Errorf(T("identity.err.replacements"), err)
+	}
+	return replacements, nil
+}
+
+// replacementChartMarker возвращает строку под графиком с отметкой замены батареи,
+// если замена попала в отображаемый диапазон измерений.
+func replacementChartMarker(chartData []Measurement, replacements []BatteryReplacement, width int) string {
+	if len(chartData) == 0 || len(replacements) == 0 {
+		return ""
+	}
+
+	step := float64(width) / float64(len(chartData))
+	for i := len(replacements) - 1; i >= 0; i-- {
+		r := replacements[i]
+		for j, m := range chartData {
+			if m.Timestamp < r.Timestamp {
+				continue
+			}
+			if j == 0 {
+				break // замена была раньше начала графика
+			}
+			x := int(float64(j) * step)
+			if x >= width {
+				x = width - 1
+			}
+			return fmt.Sprintf("      %*s▲ %s", x, "", r.Marker())
+		}
+	}
+	return ""
+}
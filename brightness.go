@@ -0,0 +1,116 @@
+// brightness.go
+//
+// Расход батареи с учетом яркости экрана и положения крышки. Яркий экран
+// при просмотре видео разряжает батарею заметно быстрее, и без этого
+// контекста такой расход неотличим от проблемы с батареей.
+
+package main
+
+import "time"
+
+// highBrightness – яркость, начиная с которой высокий расход считается ожидаемым, %
+const highBrightness = 67
+
+// BrightnessDrain – средний расход при определенной яркости экрана
+type BrightnessDrain struct {
+	Label string  // диапазон яркости или "крышка закрыта"
+	Rate  float64 // мАч/ч
+	Power float64 // средняя мощность, Вт; 0 – напряжение не записывалось
+	Hours float64 // часов работы от батареи в диапазоне
+}
+
+// Drain возвращает расход в ваттах, а без данных о напряжении – в мАч/ч
+func (b BrightnessDrain) Drain() string {
+	return formatDrain(b.Power, b.Rate)
+}
+
+// brightnessBucket возвращает индекс диапазона для измерения или -1, если контекст неизвестен
+func brightnessBucket(m Measurement) int {
+	switch {
+	case m.LidState == lidClosed:
+		return 3
+	case m.Brightness <= 0:
+		return -1
+	case m.Brightness <= 33:
+		return 0
+	case m.Brightness < highBrightness:
+		return 1
+	}
+	return 2
+}
+
+var brightnessBucketLabels = [...]string{"brightness.low", "brightness.mid", "brightness.high", "brightness.lid_closed"} // ключи перевода
+
+// drainByBrightness считает средний расход по диапазонам яркости. Интервал
+// относится к диапазону по первому измерению; пропуски дольше часа
+// (сон, выключение) и интервалы без разряда не учитываются.
+func drainByBrightness(ms []Measurement) []BrightnessDrain {
+	var drained, hours, energy, energyHours [len(brightnessBucketLabels)]float64
+	for i := 1; i < len(ms); i++ {
+		prev, curr := ms[i-1], ms[i]
+		if prev.State != "discharging" || curr.State != "discharging" {
+			continue
+		}
+		bucket := brightnessBucket(prev)
+		if bucket < 0 {
+			continue
+		}
+		t1, err1 := time.Parse(time.RFC3339, prev.Timestamp)
+		t2, err2 := time.Parse(time.RFC3339, curr.Timestamp)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		dt := t2.Sub(t1)
+		drop := prev.CurrentCapacity - curr.CurrentCapacity
+		if dt <= 0 || dt > time.Hour || drop < 0 {
+			continue
+		}
+		drained[bucket] += float64(drop)
+		hours[bucket] += dt.Hours()
+		if wh, ok := intervalEnergy(prev, curr); ok {
+			energy[bucket] += wh
+			energyHours[bucket] += dt.Hours()
+		}
+	}
+
+	var result []BrightnessDrain
+	for i, label := range brightnessBucketLabels {
+		if hours[i] <= 0 {
+			continue
+		}
+		d := BrightnessDrain{Label: T(label), Rate: drained[i] / hours[i], Hours: hours[i]}
+		if energyHours[i] > 0 {
+			d.Power = energy[i] / energyHours[i]
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// avgDischargeBrightness возвращает среднюю яркость экрана при работе от
+// батареи с открытой крышкой; ok=false, если яркость не записывалась
+func avgDischargeBrightness(ms []Measurement) (float64, bool) {
+	sum, n := 0, 0
+	for _, m := range ms {
+		if m.State == "discharging" && m.LidState != lidClosed && m.Brightness > 0 {
+			sum += m.Brightness
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return float64(sum) / float64(n), true
+}
+
+// displayContextNote возвращает пояснение к аномалии расхода: при ярком
+// экране или закрытой крышке. Для обычной яркости пояснение не нужно.
+func displayContextNote(m Measurement) string {
+	switch {
+	case m.LidState == lidClosed:
+		return T("brightness.note.lid")
+	case m.Brightness >= highBrightness:
+		return T("brightness.note.high", m.Brightness)
+	}
+	return ""
+}
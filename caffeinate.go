@@ -0,0 +1,203 @@
+// caffeinate.go
+//
+// Запрет сна от бездействия (caffeinate -i и аналоги): по умолчанию только
+// на время калибровочного теста, чтобы обычное использование измерялось с
+// привычными для пользователя засыпаниями. Режим задается в config.json,
+// на экране настроек и клавишей c на дашборде. Там же показываются
+// системные запреты сна (pmset -g assertions) – кто еще не дает Mac уснуть.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// CaffeinateMode – когда batmon запрещает сон от бездействия
+type CaffeinateMode string
+
+const (
+	caffeinateOff         CaffeinateMode = "off"         // никогда
+	caffeinateCalibration CaffeinateMode = "calibration" // только во время калибровочного теста
+	caffeinateAlways      CaffeinateMode = "always"      // пока открыт batmon
+)
+
+// caffeinateModes – режимы по кругу для переключения
+var caffeinateModes = []CaffeinateMode{caffeinateOff, caffeinateCalibration, caffeinateAlways}
+
+// UnmarshalJSON принимает и прежний формат "caffeinate": true/false
+func (m *CaffeinateMode) UnmarshalJSON(data []byte) error {
+	var on bool
+	if err := json.Unmarshal(data, &on); err == nil {
+		*m = caffeinateOff
+		if on {
+			*m = caffeinateAlways
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("collector.caffeinate: %w", err)
+	}
+	*m = CaffeinateMode(s)
+	return nil
+}
+
+// normalize заменяет неизвестный режим режимом по умолчанию
+func (m CaffeinateMode) normalize() CaffeinateMode {
+	for _, mode := range caffeinateModes {
+		if m == mode {
+			return m
+		}
+	}
+	return caffeinateCalibration
+}
+
+// Next возвращает следующий режим по кругу; delta < 0 – предыдущий
+func (m CaffeinateMode) Next(delta int) CaffeinateMode {
+	for i, mode := range caffeinateModes {
+		if mode == m.normalize() {
+			return caffeinateModes[(i+delta+len(caffeinateModes))%len(caffeinateModes)]
+		}
+	}
+	return caffeinateCalibration
+}
+
+// Label возвращает название режима на текущем языке
+func (m CaffeinateMode) Label() string {
+	return T("caffeinate." + string(m.normalize()))
+}
+
+// wantsAwake сообщает, нужно ли сейчас запрещать сон
+func (m CaffeinateMode) wantsAwake(calibrating bool) bool {
+	switch m.normalize() {
+	case caffeinateAlways:
+		return true
+	case caffeinateCalibration:
+		return calibrating
+	}
+	return false
+}
+
+// syncCaffeinate включает или выключает запрет сна по режиму из конфига
+// и наличию идущего (или приостановленного) калибровочного теста
+func (ds *DataService) syncCaffeinate() {
+	if ds.replay != nil {
+		return
+	}
+	calibrating := false
+	if t, err := getActiveCalibration(ds.db); err == nil && t != nil {
+		calibrating = true
+	}
+	if getConfig().Collector.Caffeinate.wantsAwake(calibrating) {
+		ds.startCaffeinate()
+	} else {
+		ds.stopCaffeinate()
+	}
+}
+
+// PowerAssertions – системные запреты сна из pmset -g assertions
+type PowerAssertions struct {
+	IdleSleep   int      // PreventUserIdleSystemSleep
+	SystemSleep int      // PreventSystemSleep
+	Display     int      // PreventUserIdleDisplaySleep
+	Owners      []string // процессы, запрещающие сон системы
+}
+
+// readPowerAssertions читает запреты сна; ok=false – не macOS или pmset недоступен
+func readPowerAssertions() (PowerAssertions, bool) {
+	if runtime.GOOS != "darwin" {
+		return PowerAssertions{}, false
+	}
+	out, err := runCommand("pmset", "-g", "assertions")
+	if err != nil {
+		return PowerAssertions{}, false
+	}
+	return parsePowerAssertions(string(out)), true
+}
+
+// parsePowerAssertions разбирает вывод pmset -g assertions: счетчики из
+// раздела "system-wide" и владельцев из строк "pid 123(caffeinate): ..."
+func parsePowerAssertions(out string) PowerAssertions {
+	var a PowerAssertions
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			var n int
+			if _, err := fmt.Sscanf(fields[1], "%d", &n); err == nil {
+				switch fields[0] {
+				case "PreventUserIdleSystemSleep":
+					a.IdleSleep = n
+				case "PreventSystemSleep":
+					a.SystemSleep = n
+				case "PreventUserIdleDisplaySleep":
+					a.Display = n
+				}
+			}
+			continue
+		}
+		if !strings.HasPrefix(line, "pid ") || !strings.Contains(line, "SystemSleep") {
+			continue
+		}
+		open, end := strings.Index(line, "("), strings.Index(line, ")")
+		if open < 0 || end <= open {
+			continue
+		}
+		if owner := line[open+1 : end]; !seen[owner] {
+			seen[owner] = true
+			a.Owners = append(a.Owners, owner)
+		}
+	}
+	return a
+}
+
+// Summary возвращает строку для дашборда
+func (a PowerAssertions) Summary() string {
+	if a.IdleSleep+a.SystemSleep == 0 {
+		return T("caffeinate.assertions.none")
+	}
+	if len(a.Owners) == 0 {
+		return T("caffeinate.assertions", a.IdleSleep+a.SystemSleep)
+	}
+	return T("caffeinate.assertions.owners", strings.Join(a.Owners, ", "))
+}
+
+// caffeinateStatusLine возвращает строку статуса запрета сна для дашборда
+func (a *App) caffeinateStatusLine() string {
+	mode := getConfig().Collector.Caffeinate
+	state := T("caffeinate.inactive")
+	if a.dataService != nil && a.dataService.caffeineActive {
+		state = T("caffeinate.active")
+	}
+	line := T("caffeinate.status", mode.Label(), state)
+	if a.assertionsOK {
+		line += "\n" + a.assertions.Summary()
+	}
+	return line
+}
+
+// refreshPowerStatus приводит запрет сна к режиму и перечитывает системные запреты
+func (a *App) refreshPowerStatus() {
+	if a.dataService == nil {
+		return
+	}
+	a.dataService.syncCaffeinate()
+	a.assertions, a.assertionsOK = readPowerAssertions()
+}
+
+// toggleCaffeinate переключает режим запрета сна и сохраняет его в конфиге
+func (a *App) toggleCaffeinate() {
+	cfg := getConfig()
+	cfg.Collector.Caffeinate = cfg.Collector.Caffeinate.Next(1)
+	setConfig(cfg)
+	if err := saveConfig(getConfigPath(), cfg); err != nil {
+		a.lastError = err
+	}
+	a.refreshPowerStatus()
+}
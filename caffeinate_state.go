@@ -0,0 +1,160 @@
+// caffeinate_state.go
+//
+// Учет запущенного caffeinate (systemd-inhibit, PowerShell на Windows) в
+// файле состояния caffeinate.json в папке данных. Если batmon убили, а его
+// caffeinate -i остался, Mac больше не засыпал от бездействия, пока
+// пользователь не находил процесс вручную. Теперь при запуске TUI процесс
+// из файла проверяется: если batmon, который его запустил, уже не работает,
+// а PID по-прежнему принадлежит той же утилите, процесс завершается. На
+// macOS caffeinate к тому же запускается с -w и сам выходит вместе с batmon.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// caffeinateState – запрет сна, запущенный batmon
+type caffeinateState struct {
+	PID       int    `json:"pid"`
+	Command   string `json:"command"`   // имя утилиты, например caffeinate
+	OwnerPID  int    `json:"owner_pid"` // batmon, запустивший процесс
+	Owner     string `json:"owner"`     // имя исполняемого файла batmon
+	StartedAt string `json:"started_at"`
+}
+
+// caffeinateStatePath возвращает путь к файлу состояния; пусто – папки данных нет
+func caffeinateStatePath() string {
+	dir, err := getDataDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "caffeinate.json")
+}
+
+// saveCaffeinateState записывает запущенный процесс в файл состояния
+func saveCaffeinateState(path string, cmd *exec.Cmd) error {
+	if path == "" || cmd.Process == nil {
+		return nil
+	}
+	raw, err := json.Marshal(caffeinateState{
+		PID:       cmd.Process.Pid,
+		Command:   filepath.Base(cmd.Path),
+		OwnerPID:  os.Getpid(),
+		Owner:     filepath.Base(os.Args[0]),
+		StartedAt: timeNow().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		return fmt.Errorf(T("caffeinate.err.state"), err)
+	}
+	return nil
+}
+
+// clearCaffeinateState удаляет файл состояния завершившегося процесса pid.
+// Файл, записанный для другого процесса (перезапуск запрета сна, другой
+// запуск batmon), остается.
+func clearCaffeinateState(path string, pid int) {
+	if path == "" {
+		return
+	}
+	if s, err := loadCaffeinateState(path); err == nil && (s.OwnerPID != os.Getpid() || s.PID != pid) {
+		return
+	}
+	os.Remove(path)
+}
+
+// loadCaffeinateState читает файл состояния
+func loadCaffeinateState(path string) (caffeinateState, error) {
+	var s caffeinateState
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return s, fmt.Errorf(T("caffeinate.err.state"), err)
+	}
+	return s, nil
+}
+
+// reapOrphanedCaffeinate завершает процесс из файла состояния, оставшийся
+// от аварийно завершенного batmon. Возвращает PID завершенного процесса
+// (0 – сирот нет). Процесс не трогается, если его владелец еще работает
+// или PID уже занят другой программой.
+func reapOrphanedCaffeinate(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+	s, err := loadCaffeinateState(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		os.Remove(path) // испорченный файл не поможет найти процесс
+		return 0, err
+	}
+	if s.OwnerPID != os.Getpid() && sameProcess(s.OwnerPID, s.Owner) {
+		return 0, nil // запрет сна держит другой работающий batmon
+	}
+	defer os.Remove(path)
+	if s.PID <= 0 || !sameProcess(s.PID, s.Command) {
+		return 0, nil // процесс уже завершился
+	}
+	p, err := os.FindProcess(s.PID)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.Kill(); err != nil {
+		return 0, fmt.Errorf(T("caffeinate.err.stop"), s.Command, s.PID, err)
+	}
+	return s.PID, nil
+}
+
+// sameProcess сообщает, работает ли процесс pid с исполняемым файлом name.
+// Сверка имени защищает от PID, переиспользованного системой.
+func sameProcess(pid int, name string) bool {
+	if pid <= 0 || name == "" {
+		return false
+	}
+	running, ok := processName(pid)
+	if !ok {
+		return false
+	}
+	normalize := func(s string) string {
+		return strings.TrimSuffix(strings.ToLower(filepath.Base(s)), ".exe")
+	}
+	return normalize(running) == normalize(name)
+}
+
+// processName возвращает имя исполняемого файла процесса; ok=false – процесса нет
+func processName(pid int) (string, bool) {
+	if runtime.GOOS == "windows" {
+		// "powershell.exe","1234","Console","1","80 000 K"
+		out, err := runCommand("tasklist", "/FI", "PID eq "+strconv.Itoa(pid), "/FO", "CSV", "/NH")
+		if err != nil {
+			return "", false
+		}
+		fields := strings.Split(strings.TrimSpace(string(out)), ",")
+		if len(fields) < 2 || strings.Trim(fields[1], `"`) != strconv.Itoa(pid) {
+			return "", false
+		}
+		return strings.Trim(fields[0], `"`), true
+	}
+	out, err := runCommand("ps", "-p", strconv.Itoa(pid), "-o", "comm=")
+	if err != nil {
+		return "", false
+	}
+	name := strings.TrimSpace(string(out))
+	return name, name != ""
+}
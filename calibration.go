@@ -0,0 +1,767 @@
+// calibration.go
+//
+// Полный анализ батареи (100% → 0%): тест начинается при полном заряде,
+// коллектор отмечает контрольные точки разрядки, тест завершается при
+// заряде ниже 5%. Итоговый отчет сравнивает измеренное время работы
+// с оценкой macOS, полученной в начале теста. Подключение зарядки ставит
+// тест на паузу: его можно продолжить после отключения зарядки или
+// завершить досрочно с тем покрытием, что уже набрано.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	calibrationStartMin = 98 // минимальный заряд для начала теста, %
+	calibrationEndBelow = 5  // тест завершается, когда заряд опускается ниже, %
+)
+
+// Статусы теста калибровки
+const (
+	calibrationRunning   = "running"
+	calibrationPaused    = "paused" // подключена зарядка, ждем решения пользователя
+	calibrationCompleted = "completed"
+	calibrationAborted   = "aborted"
+)
+
+// calibrationMilestones – контрольные точки заряда, время которых фиксируется
+var calibrationMilestones = []int{90, 75, 50, 25, 10, calibrationEndBelow}
+
+// calibrationSchema – тесты калибровки и достигнутые контрольные точки
+const calibrationSchema = `
+CREATE TABLE IF NOT EXISTS calibration_tests (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	status TEXT NOT NULL,
+	started_at TEXT NOT NULL,
+	discharge_started_at TEXT NOT NULL DEFAULT '',
+	finished_at TEXT NOT NULL DEFAULT '',
+	start_percent INTEGER NOT NULL,
+	discharge_start_percent INTEGER NOT NULL DEFAULT 0,
+	end_percent INTEGER NOT NULL DEFAULT 0,
+	start_capacity INTEGER NOT NULL DEFAULT 0,
+	end_capacity INTEGER NOT NULL DEFAULT 0,
+	full_charge_capacity INTEGER NOT NULL DEFAULT 0,
+	design_capacity INTEGER NOT NULL DEFAULT 0,
+	apple_estimate_seconds INTEGER NOT NULL DEFAULT 0,
+	apple_estimate_percent INTEGER NOT NULL DEFAULT 0,
+	note TEXT NOT NULL DEFAULT '',
+	paused_at TEXT NOT NULL DEFAULT '',
+	paused_seconds INTEGER NOT NULL DEFAULT 0,
+	recharged_percent INTEGER NOT NULL DEFAULT 0,
+	recharged_capacity INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS calibration_milestones (
+	test_id INTEGER NOT NULL,
+	percent INTEGER NOT NULL,
+	reached_at TEXT NOT NULL,
+	PRIMARY KEY (test_id, percent)
+);`
+
+// CalibrationTest – тест полной разрядки
+type CalibrationTest struct {
+	ID                    int    `db:"id"`
+	Status                string `db:"status"`
+	StartedAt             string `db:"started_at"`
+	DischargeStartedAt    string `db:"discharge_started_at"` // пусто, пока не отключена зарядка
+	FinishedAt            string `db:"finished_at"`
+	StartPercent          int    `db:"start_percent"`
+	DischargeStartPercent int    `db:"discharge_start_percent"`
+	EndPercent            int    `db:"end_percent"`
+	StartCapacity         int    `db:"start_capacity"` // мАч
+	EndCapacity           int    `db:"end_capacity"`   // мАч
+	FullChargeCap         int    `db:"full_charge_capacity"`
+	DesignCapacity        int    `db:"design_capacity"`
+	AppleEstimateSeconds  int    `db:"apple_estimate_seconds"` // оценка macOS в начале разрядки
+	AppleEstimatePercent  int    `db:"apple_estimate_percent"` // заряд в момент оценки
+	Note                  string `db:"note"`                   // причина прерывания или паузы
+	PausedAt              string `db:"paused_at"`              // пусто, если зарядка не подключена
+	PausedSeconds         int    `db:"paused_seconds"`         // время на зарядке, не входит в разрядку
+	RechargedPercent      int    `db:"recharged_percent"`      // заряд, набранный во время пауз, %
+	RechargedCapacity     int    `db:"recharged_capacity"`     // ёмкость, набранная во время пауз, мАч
+}
+
+// CalibrationMilestone – момент достижения контрольной точки заряда
+type CalibrationMilestone struct {
+	TestID    int    `db:"test_id"`
+	Percent   int    `db:"percent"`
+	ReachedAt string `db:"reached_at"`
+}
+
+// parseStoredTime разбирает время из БД; пустая строка – нулевое время
+func parseStoredTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+// Elapsed возвращает время разрядки без пауз на зарядке: до завершения
+// теста, до начала текущей паузы или до now
+func (t CalibrationTest) Elapsed(now time.Time) time.Duration {
+	start := parseStoredTime(t.DischargeStartedAt)
+	if start.IsZero() {
+		return 0
+	}
+	end := now
+	if finished := parseStoredTime(t.FinishedAt); !finished.IsZero() {
+		end = finished
+	} else if paused := parseStoredTime(t.PausedAt); !paused.IsZero() {
+		end = paused
+	}
+	return end.Sub(start) - time.Duration(t.PausedSeconds)*time.Second
+}
+
+// Discharged возвращает разряженный за тест процент с учетом подзарядок
+func (t CalibrationTest) Discharged() int {
+	return t.DischargeStartPercent + t.RechargedPercent - t.EndPercent
+}
+
+// Partial сообщает, что тест завершен досрочно, не дойдя до конца разрядки
+func (t CalibrationTest) Partial() bool {
+	return t.Status == calibrationCompleted && t.EndPercent >= calibrationEndBelow
+}
+
+// AppleEstimate возвращает оценку macOS, пересчитанную на разрядку от старта до 0%
+func (t CalibrationTest) AppleEstimate() time.Duration {
+	if t.AppleEstimateSeconds <= 0 || t.AppleEstimatePercent <= 0 {
+		return 0
+	}
+	estimate := time.Duration(t.AppleEstimateSeconds) * time.Second
+	return time.Duration(float64(estimate) * float64(t.DischargeStartPercent) / float64(t.AppleEstimatePercent))
+}
+
+// CalibrationResult – итог завершенного теста
+type CalibrationResult struct {
+	Test              CalibrationTest
+	Milestones        []CalibrationMilestone
+	MeasuredRuntime   time.Duration // фактическое время разрядки
+	FullRuntime       time.Duration // время, пересчитанное на разрядку 100% → 0%
+	AppleEstimate     time.Duration // оценка macOS на ту же разрядку
+	Deviation         float64       // отклонение факта от оценки, %
+	DeliveredCapacity int           // отданная ёмкость, мАч
+	AvgCurrent        float64       // средний ток разрядки, мА
+}
+
+// calibrationResult считает итог теста
+func calibrationResult(t CalibrationTest, milestones []CalibrationMilestone) CalibrationResult {
+	r := CalibrationResult{
+		Test:            t,
+		Milestones:      milestones,
+		MeasuredRuntime: t.Elapsed(time.Now()),
+		AppleEstimate:   t.AppleEstimate(),
+	}
+	drop := t.Discharged()
+	if drop > 0 {
+		r.FullRuntime = time.Duration(float64(r.MeasuredRuntime) * 100 / float64(drop))
+	}
+	if r.AppleEstimate > 0 {
+		// Сравниваем на одном и том же объеме разрядки: от старта до конца теста
+		expected := time.Duration(float64(r.AppleEstimate) * float64(drop) / float64(t.DischargeStartPercent))
+		if expected > 0 {
+			r.Deviation = (r.MeasuredRuntime.Hours() - expected.Hours()) / expected.Hours() * 100
+		}
+	}
+	if t.StartCapacity > 0 && t.EndCapacity > 0 {
+		r.DeliveredCapacity = t.StartCapacity + t.RechargedCapacity - t.EndCapacity
+		if hours := r.MeasuredRuntime.Hours(); hours > 0 {
+			r.AvgCurrent = float64(r.DeliveredCapacity) / hours
+		}
+	}
+	return r
+}
+
+// getActiveCalibration возвращает идущий или приостановленный тест или nil
+func getActiveCalibration(db *sqlx.DB) (*CalibrationTest, error) {
+	var t CalibrationTest
+	err := db.Get(&t, `SELECT * FROM calibration_tests WHERE status IN (?, ?) ORDER BY id DESC LIMIT 1`,
+		calibrationRunning, calibrationPaused)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("calibration.err.read"), err)
+	}
+	return &t, nil
+}
+
+// getLastCalibration возвращает последний тест в любом статусе или nil
+func getLastCalibration(db *sqlx.DB) (*CalibrationTest, error) {
+	var t CalibrationTest
+	err := db.Get(&t, `SELECT * FROM calibration_tests ORDER BY id DESC LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("calibration.err.read"), err)
+	}
+	return &t, nil
+}
+
+// getCalibrationMilestones возвращает достигнутые контрольные точки теста
+func getCalibrationMilestones(db *sqlx.DB, testID int) ([]CalibrationMilestone, error) {
+	var ms []CalibrationMilestone
+	err := db.Select(&ms, `SELECT * FROM calibration_milestones WHERE test_id = ? ORDER BY percent DESC`, testID)
+	if err != nil {
+		return nil, fmt.Errorf(T("calibration.err.milestones"), err)
+	}
+	return ms, nil
+}
+
+// startCalibration начинает тест по последнему измерению
+func startCalibration(db *sqlx.DB, latest Measurement) (*CalibrationTest, error) {
+	if active, err := getActiveCalibration(db); err != nil {
+		return nil, err
+	} else if active != nil {
+		return nil, fmt.Errorf(T("calibration.err.running"), parseStoredTime(active.StartedAt).Local().Format("02.01 15:04"))
+	}
+	if latest.Percentage < calibrationStartMin {
+		return nil, fmt.Errorf(T("calibration.err.not_full"), latest.Percentage)
+	}
+
+	_, err := db.Exec(`INSERT INTO calibration_tests (status, started_at, start_percent,
+		full_charge_capacity, design_capacity) VALUES (?, ?, ?, ?, ?)`,
+		calibrationRunning, timeNow().UTC().Format(time.RFC3339), latest.Percentage,
+		latest.FullChargeCap, latest.DesignCapacity)
+	if err != nil {
+		return nil, fmt.Errorf(T("calibration.err.create"), err)
+	}
+	return getActiveCalibration(db)
+}
+
+// abortCalibration прерывает идущий или приостановленный тест
+func abortCalibration(db *sqlx.DB, reason string) error {
+	_, err := db.Exec(`UPDATE calibration_tests SET status = ?, finished_at = ?, note = ? WHERE status IN (?, ?)`,
+		calibrationAborted, timeNow().UTC().Format(time.RFC3339), reason, calibrationRunning, calibrationPaused)
+	if err != nil {
+		return fmt.Errorf(T("calibration.err.abort"), err)
+	}
+	return nil
+}
+
+// pauseCalibration ставит тест на паузу при подключении зарядки и запоминает
+// заряд в этот момент – он станет концом теста, если тест завершат досрочно
+func pauseCalibration(db *sqlx.DB, t CalibrationTest, m Measurement) error {
+	note := T("calibration.note.paused", m.Percentage)
+	_, err := db.Exec(`UPDATE calibration_tests SET status = ?, paused_at = ?, end_percent = ?, end_capacity = ?, note = ?
+		WHERE id = ? AND status = ?`, calibrationPaused, m.Timestamp, m.Percentage, m.CurrentCapacity, note, t.ID, calibrationRunning)
+	if err != nil {
+		return fmt.Errorf(T("calibration.err.pause"), err)
+	}
+	logInfof("⏸️ Тест полной разрядки приостановлен: %s", note)
+	sendAlert(alertInfo, T("calibration.alert.paused"),
+		T("calibration.alert.paused.body", m.Percentage))
+	return nil
+}
+
+// resumeCalibration продолжает приостановленный тест. Время паузы и набранный
+// заряд учитываются, когда зарядку отключат.
+func resumeCalibration(db *sqlx.DB) error {
+	res, err := db.Exec(`UPDATE calibration_tests SET status = ?, note = '' WHERE status = ?`, calibrationRunning, calibrationPaused)
+	if err != nil {
+		return fmt.Errorf(T("calibration.err.resume"), err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New(T("calibration.err.not_paused"))
+	}
+	return nil
+}
+
+// finishCalibration досрочно завершает приостановленный тест на заряде,
+// при котором подключили зарядку
+func finishCalibration(db *sqlx.DB) error {
+	res, err := db.Exec(`UPDATE calibration_tests SET status = ?, finished_at = paused_at, paused_at = '',
+		note = ? || note WHERE status = ? AND discharge_started_at != ''`, calibrationCompleted, T("calibration.note.partial"), calibrationPaused)
+	if err != nil {
+		return fmt.Errorf(T("calibration.err.finish"), err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New(T("calibration.err.not_paused"))
+	}
+	return nil
+}
+
+// advanceCalibration продвигает идущий тест по новому измерению: фиксирует
+// начало разрядки и оценку macOS, контрольные точки и завершение теста.
+// Подключение зарядки во время разрядки ставит тест на паузу.
+func advanceCalibration(db *sqlx.DB, m Measurement, source BatterySource) error {
+	t, err := getActiveCalibration(db)
+	if err != nil || t == nil || t.Status != calibrationRunning {
+		return err
+	}
+	now := m.Timestamp
+	state := strings.ToLower(m.State)
+
+	if t.DischargeStartedAt == "" {
+		if state != "discharging" {
+			return nil // ждем отключения зарядки
+		}
+		t.DischargeStartedAt = now
+		t.DischargeStartPercent = m.Percentage
+		t.StartCapacity = m.CurrentCapacity
+		if _, err := db.Exec(`UPDATE calibration_tests SET discharge_started_at = ?, discharge_start_percent = ?,
+			start_capacity = ? WHERE id = ?`, now, m.Percentage, m.CurrentCapacity, t.ID); err != nil {
+			return fmt.Errorf(T("calibration.err.discharge_start"), err)
+		}
+	}
+
+	if state != "discharging" {
+		if t.PausedAt == "" {
+			return pauseCalibration(db, *t, m)
+		}
+		return nil // тест продолжен пользователем, ждем отключения зарядки
+	}
+
+	// Первое измерение после паузы: исключаем время на зарядке и набранный заряд
+	if t.PausedAt != "" {
+		pause := parseStoredTime(now).Sub(parseStoredTime(t.PausedAt))
+		t.PausedSeconds += int(pause.Seconds())
+		t.RechargedPercent += max(0, m.Percentage-t.EndPercent)
+		t.RechargedCapacity += max(0, m.CurrentCapacity-t.EndCapacity)
+		if _, err := db.Exec(`UPDATE calibration_tests SET paused_at = '', paused_seconds = ?, recharged_percent = ?,
+			recharged_capacity = ? WHERE id = ?`, t.PausedSeconds, t.RechargedPercent, t.RechargedCapacity, t.ID); err != nil {
+			return fmt.Errorf(T("calibration.err.discharge_resume"), err)
+		}
+	}
+
+	// Оценку macOS запоминаем один раз – в начале разрядки, как только она появится
+	if t.AppleEstimateSeconds == 0 {
+		if estimator, ok := source.(RemainingEstimator); ok {
+			if estimate, ok, err := estimator.Remaining(); err == nil && ok {
+				if _, err := db.Exec(`UPDATE calibration_tests SET apple_estimate_seconds = ?, apple_estimate_percent = ?
+					WHERE id = ?`, int(estimate.Seconds()), m.Percentage, t.ID); err != nil {
+					return fmt.Errorf(T("calibration.err.macos"), err)
+				}
+			}
+		}
+	}
+
+	for _, p := range calibrationMilestones {
+		if m.Percentage <= p && p < t.DischargeStartPercent {
+			if _, err := db.Exec(`INSERT OR IGNORE INTO calibration_milestones (test_id, percent, reached_at)
+				VALUES (?, ?, ?)`, t.ID, p, now); err != nil {
+				return fmt.Errorf(T("calibration.err.milestone"), p, err)
+			}
+		}
+	}
+
+	if m.Percentage < calibrationEndBelow {
+		_, err := db.Exec(`UPDATE calibration_tests SET status = ?, finished_at = ?, end_percent = ?, end_capacity = ?
+			WHERE id = ? AND status = ?`, calibrationCompleted, now, m.Percentage, m.CurrentCapacity, t.ID, calibrationRunning)
+		if err != nil {
+			return fmt.Errorf(T("calibration.err.finish"), err)
+		}
+	}
+	return nil
+}
+
+// exportCalibrationMarkdown записывает отчет о тесте в Markdown
+func exportCalibrationMarkdown(r CalibrationResult, filename string) error {
+	var b strings.Builder
+	t := r.Test
+	b.WriteString(T("calibration.md.title"))
+	fmt.Fprintf(&b, T("calibration.md.started"), parseStoredTime(t.DischargeStartedAt).Local().Format("02.01.2006 15:04"))
+	fmt.Fprintf(&b, T("calibration.md.finished"), parseStoredTime(t.FinishedAt).Local().Format("02.01.2006 15:04"))
+
+	if t.Partial() {
+		fmt.Fprintf(&b, T("calibration.md.partial"),
+			strings.TrimPrefix(t.Note, T("calibration.note.partial")), t.Discharged(), t.DischargeStartPercent+t.RechargedPercent-calibrationEndBelow)
+	}
+	b.WriteString(T("calibration.md.runtime"))
+	fmt.Fprintf(&b, T("calibration.md.measured"), formatDuration(r.MeasuredRuntime), t.DischargeStartPercent, t.EndPercent)
+	if t.PausedSeconds > 0 {
+		fmt.Fprintf(&b, T("calibration.md.paused"),
+			formatDuration(time.Duration(t.PausedSeconds)*time.Second), t.RechargedPercent)
+	}
+	if r.FullRuntime > 0 {
+		fmt.Fprintf(&b, T("calibration.md.full"), formatDuration(r.FullRuntime))
+	}
+	if r.AppleEstimate > 0 {
+		fmt.Fprintf(&b, T("calibration.md.apple"), formatDuration(r.AppleEstimate))
+		fmt.Fprintf(&b, T("calibration.md.deviation"), r.Deviation)
+	} else {
+		b.WriteString(T("calibration.md.apple_none"))
+	}
+
+	b.WriteString(T("calibration.md.capacity"))
+	if r.DeliveredCapacity > 0 {
+		fmt.Fprintf(&b, T("calibration.md.delivered"), r.DeliveredCapacity)
+		fmt.Fprintf(&b, T("calibration.md.current"), r.AvgCurrent)
+	}
+	if t.DesignCapacity > 0 {
+		fmt.Fprintf(&b, T("calibration.md.full_design"),
+			t.FullChargeCap, t.DesignCapacity, computeWear(t.DesignCapacity, t.FullChargeCap))
+	}
+
+	if len(r.Milestones) > 0 {
+		b.WriteString(T("calibration.md.milestones"))
+		b.WriteString(T("calibration.md.milestones.header"))
+		b.WriteString("|-------|-------|-------------------|\n")
+		start := parseStoredTime(t.DischargeStartedAt)
+		for _, m := range r.Milestones {
+			at := parseStoredTime(m.ReachedAt)
+			fmt.Fprintf(&b, "| %d%% | %s | %s |\n", m.Percent, at.Local().Format("15:04"), formatDuration(at.Sub(start)))
+		}
+	}
+
+	b.WriteString("\n---\n*" + T("report.footer") + "*\n")
+
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := io.WriteString(w, b.String())
+		return err
+	}, verifyMarkdownReport)
+}
+
+// latestCompletedCalibration возвращает итог последнего завершенного теста
+func latestCompletedCalibration(db *sqlx.DB) (*CalibrationResult, error) {
+	var t CalibrationTest
+	err := db.Get(&t, `SELECT * FROM calibration_tests WHERE status = ? ORDER BY id DESC LIMIT 1`, calibrationCompleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New(T("calibration.err.none_finished"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("calibration.err.read"), err)
+	}
+	milestones, err := getCalibrationMilestones(db, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	r := calibrationResult(t, milestones)
+	return &r, nil
+}
+
+// defaultCalibrationReportPath возвращает путь отчета в Documents
+func defaultCalibrationReportPath(t CalibrationTest) string {
+	name := fmt.Sprintf("batmon_calibration_%s.md", parseStoredTime(t.DischargeStartedAt).Local().Format("2006-01-02"))
+	path, err := getExportPath(name)
+	if err != nil {
+		return name
+	}
+	return path
+}
+
+// runCalibrationCommand – batmon calibration [status|start|abort|resume|finish|report]
+func runCalibrationCommand(args []string) error {
+	fs := newCommandFlags("calibration")
+	md := fs.String("md", "", localized("flag.calibration.md", "report: файл отчета Markdown"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	action := "status"
+	if fs.NArg() > 0 {
+		action = fs.Arg(0)
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	switch action {
+	case "status":
+		t, err := getLastCalibration(db)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			fmt.Println(T("calibration.none"))
+			return nil
+		}
+		fmt.Println(calibrationStatusLine(*t, time.Now()))
+		return nil
+	case "start":
+		latest, err := getLastNMeasurements(db, 1)
+		if err != nil {
+			return fmt.Errorf(T("err.data"), err)
+		}
+		if len(latest) == 0 {
+			return errors.New(T("err.no_measurements_collect"))
+		}
+		if _, err := startCalibration(db, latest[0]); err != nil {
+			return err
+		}
+		color.New(color.FgGreen).Println(T("calibration.cli.started"))
+		return nil
+	case "abort":
+		return abortCalibration(db, T("calibration.note.aborted"))
+	case "resume":
+		if err := resumeCalibration(db); err != nil {
+			return err
+		}
+		color.New(color.FgGreen).Println(T("calibration.cli.resumed"))
+		return nil
+	case "finish":
+		if err := finishCalibration(db); err != nil {
+			return err
+		}
+		color.New(color.FgGreen).Println(T("calibration.cli.finished"))
+		return nil
+	case "report":
+		r, err := latestCompletedCalibration(db)
+		if err != nil {
+			return err
+		}
+		path := *md
+		if path == "" {
+			path = defaultCalibrationReportPath(r.Test)
+		}
+		if err := exportCalibrationMarkdown(*r, path); err != nil {
+			return fmt.Errorf(T("calibration.err.export"), err)
+		}
+		fmt.Println(T("calibration.cli.saved", path))
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, T("calibration.cli.unknown_action", action))
+	return errUsage
+}
+
+// calibrationStatusLine возвращает однострочный статус теста
+func calibrationStatusLine(t CalibrationTest, now time.Time) string {
+	switch {
+	case t.Status == calibrationCompleted:
+		return T("calibration.status.completed",
+			parseStoredTime(t.FinishedAt).Local().Format("02.01 15:04"), formatDuration(t.Elapsed(now)),
+			t.DischargeStartPercent, t.EndPercent)
+	case t.Status == calibrationAborted:
+		return T("calibration.status.aborted", parseStoredTime(t.FinishedAt).Local().Format("02.01 15:04"), t.Note)
+	case t.Status == calibrationPaused:
+		return T("calibration.status.paused",
+			parseStoredTime(t.PausedAt).Local().Format("02.01 15:04"), t.Note)
+	case t.DischargeStartedAt == "":
+		return T("calibration.status.waiting")
+	case t.PausedAt != "":
+		return T("calibration.status.resumed")
+	}
+	return T("calibration.status.running", formatDuration(t.Elapsed(now)), t.DischargeStartPercent)
+}
+
+// CalibrationModel – состояние экрана полного анализа батареи
+type CalibrationModel struct {
+	test       *CalibrationTest
+	milestones []CalibrationMilestone
+	message    string // результат последнего действия
+}
+
+// loadCalibration перечитывает последний тест из БД
+func (a *App) loadCalibration() {
+	t, err := getLastCalibration(a.dataService.db)
+	if err != nil {
+		a.calibration.message = "❌ " + err.Error()
+		return
+	}
+	a.calibration.test = t
+	a.calibration.milestones = nil
+	if t != nil {
+		a.calibration.milestones, _ = getCalibrationMilestones(a.dataService.db, t.ID)
+	}
+}
+
+// initCalibration открывает экран полного анализа
+func (a *App) initCalibration() {
+	a.calibration = CalibrationModel{}
+	a.loadCalibration()
+}
+
+// updateCalibration обрабатывает нажатия на экране полного анализа
+func (a *App) updateCalibration(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	running := a.calibration.test != nil && a.calibration.test.Status == calibrationRunning
+	paused := a.calibration.test != nil && a.calibration.test.Status == calibrationPaused
+	switch msg.String() {
+	case "ctrl+c", "q", "й":
+		a.state = StateMenu
+		return a, nil
+	case "enter":
+		if running || paused {
+			return a, nil
+		}
+		if a.latest == nil {
+			a.calibration.message = T("calibration.tui.no_data")
+			return a, nil
+		}
+		if _, err := startCalibration(a.dataService.db, *a.latest); err != nil {
+			a.calibration.message = "❌ " + err.Error()
+			return a, nil
+		}
+		a.dataService.syncCaffeinate() // в режиме calibration тест проходит без сна системы
+		a.calibration.message = T("calibration.tui.started")
+		a.loadCalibration()
+	case "r", "к":
+		if paused {
+			if err := resumeCalibration(a.dataService.db); err != nil {
+				a.calibration.message = "❌ " + err.Error()
+			} else {
+				a.calibration.message = T("calibration.tui.resumed")
+			}
+			a.loadCalibration()
+		}
+	case "f", "а":
+		if paused {
+			if err := finishCalibration(a.dataService.db); err != nil {
+				a.calibration.message = "❌ " + err.Error()
+			} else {
+				a.calibration.message = T("calibration.tui.finished")
+			}
+			a.loadCalibration()
+		}
+	case "x", "ч":
+		if running || paused {
+			if err := abortCalibration(a.dataService.db, T("calibration.note.aborted")); err != nil {
+				a.calibration.message = "❌ " + err.Error()
+			} else {
+				a.calibration.message = T("calibration.tui.aborted")
+			}
+			a.loadCalibration()
+		}
+	case "d", "в":
+		a.state = StateDashboard
+		a.initDashboard()
+		return a, updateData(a.dataService, a.dashboard.chartView)
+	case "e", "у":
+		r, err := latestCompletedCalibration(a.dataService.db)
+		if err != nil {
+			a.calibration.message = "❌ " + err.Error()
+			return a, nil
+		}
+		path := defaultCalibrationReportPath(r.Test)
+		if err := exportCalibrationMarkdown(*r, path); err != nil {
+			a.calibration.message = "❌ " + err.Error()
+		} else {
+			a.calibration.message = T("calibration.tui.saved", path)
+		}
+	}
+	return a, nil
+}
+
+// renderCalibration рендерит экран полного анализа батареи
+func (a *App) renderCalibration() string {
+	title := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true).
+		Render(T("calibration.tui.title")) + "\n\n"
+
+	section := func(c lipgloss.Color, text string) string {
+		return lipgloss.NewStyle().Foreground(c).Bold(true).Render(text) + "\n"
+	}
+
+	var body strings.Builder
+	t := a.calibration.test
+	var controls string
+
+	switch {
+	case t != nil && t.Status == calibrationRunning:
+		body.WriteString(section(theme.Accent, T("calibration.tui.progress")))
+		body.WriteString(calibrationStatusLine(*t, time.Now()) + "\n")
+		if a.latest != nil && t.DischargeStartedAt != "" {
+			total := float64(t.DischargeStartPercent + t.RechargedPercent - calibrationEndBelow)
+			done := float64(t.DischargeStartPercent + t.RechargedPercent - a.latest.Percentage)
+			progress := 0.0
+			if total > 0 {
+				progress = math.Max(0, math.Min(1, done/total))
+			}
+			body.WriteString(T("calibration.tui.progress.line",
+				a.latest.Percentage, renderProgressLine(progress, 30), progress*100))
+		}
+		if estimate := t.AppleEstimate(); estimate > 0 {
+			body.WriteString(T("calibration.tui.apple", formatDuration(estimate)))
+		}
+		body.WriteString("\n" + section(theme.Good, T("calibration.tui.milestones")))
+		reached := make(map[int]string)
+		for _, m := range a.calibration.milestones {
+			reached[m.Percent] = m.ReachedAt
+		}
+		start := parseStoredTime(t.DischargeStartedAt)
+		for _, p := range calibrationMilestones {
+			if at, ok := reached[p]; ok {
+				body.WriteString(fmt.Sprintf("✅ %3d%%  %s\n", p, formatDuration(parseStoredTime(at).Sub(start))))
+			} else if p < t.DischargeStartPercent || t.DischargeStartedAt == "" {
+				body.WriteString(fmt.Sprintf("⬜ %3d%%\n", p))
+			}
+		}
+		body.WriteString(T("calibration.tui.running_tip"))
+		controls = T("calibration.tui.keys.running")
+
+	case t != nil && t.Status == calibrationPaused:
+		body.WriteString(section(theme.Warning, T("calibration.tui.paused")))
+		body.WriteString(T("calibration.tui.paused.note", t.Note))
+		body.WriteString(T("calibration.tui.paused.progress",
+			t.Discharged(), formatDuration(t.Elapsed(time.Now())), len(a.calibration.milestones)))
+		body.WriteString(T("calibration.tui.paused.resume"))
+		body.WriteString(T("calibration.tui.paused.finish"))
+		controls = T("calibration.tui.keys.paused")
+
+	case t != nil && t.Status == calibrationCompleted:
+		r := calibrationResult(*t, a.calibration.milestones)
+		if t.Partial() {
+			body.WriteString(section(theme.Warning, T("calibration.tui.partial")))
+		} else {
+			body.WriteString(section(theme.Good, T("calibration.tui.completed")))
+		}
+		body.WriteString(T("calibration.tui.runtime", formatDuration(r.MeasuredRuntime), t.DischargeStartPercent, t.EndPercent))
+		if r.FullRuntime > 0 {
+			body.WriteString(T("calibration.tui.full", formatDuration(r.FullRuntime)))
+		}
+		if r.AppleEstimate > 0 {
+			body.WriteString(T("calibration.tui.deviation", formatDuration(r.AppleEstimate), r.Deviation))
+		}
+		if r.DeliveredCapacity > 0 {
+			body.WriteString(T("calibration.tui.delivered", r.DeliveredCapacity, r.AvgCurrent))
+		}
+		body.WriteString("\n")
+		body.WriteString(a.renderCalibrationPrecheck())
+		controls = T("calibration.tui.keys.completed")
+
+	default:
+		if t != nil && t.Status == calibrationAborted {
+			body.WriteString(calibrationStatusLine(*t, time.Now()) + "\n\n")
+		}
+		body.WriteString(section(theme.Warning, T("calibration.tui.howto")))
+		body.WriteString(T("calibration.tui.howto.1"))
+		body.WriteString(T("calibration.tui.howto.2"))
+		body.WriteString(T("calibration.tui.howto.3"))
+		body.WriteString(T("calibration.tui.howto.4", calibrationEndBelow))
+		body.WriteString(a.renderCalibrationPrecheck())
+		controls = T("calibration.tui.keys.idle")
+	}
+
+	if a.calibration.message != "" {
+		body.WriteString("\n" + a.calibration.message + "\n")
+	}
+
+	footer := lipgloss.NewStyle().Foreground(theme.Border).Render(controls)
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Accent).
+		Padding(1, 2).
+		Width(70).
+		Render(title + body.String() + "\n" + footer)
+}
+
+// renderCalibrationPrecheck показывает, готова ли батарея к новому тесту
+func (a *App) renderCalibrationPrecheck() string {
+	if a.latest == nil {
+		return T("calibration.tui.waiting")
+	}
+	if a.latest.Percentage >= calibrationStartMin {
+		return T("calibration.tui.ready", a.latest.Percentage)
+	}
+	return T("calibration.tui.not_ready", a.latest.Percentage, calibrationStartMin)
+}
+
+// renderProgressLine рисует простую полосу прогресса из блоков
+func renderProgressLine(progress float64, width int) string {
+	filled := int(progress * float64(width))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
@@ -0,0 +1,264 @@
+// calibration_reminder.go
+//
+// Напоминание о калибровке: когда батарея последний раз прошла полный цикл
+// 100% → меньше 10% (тест калибровки или обычная разрядка из таблицы
+// sessions). Если цикл был давно, дашборд показывает напоминание, а коллектор
+// раз в неделю присылает уведомление. В отчете – история таких циклов.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultCalibrationIntervalDays = 90
+	calibrationCycleHigh           = 98 // заряд в начале полного цикла, %
+	calibrationCycleLow            = 10 // заряд в конце полного цикла – меньше этого, %
+	calibrationNotifyEvery         = 7 * 24 * time.Hour
+	calibrationHistoryLimit        = 10 // циклов в отчете
+)
+
+// calibrationAdvice возвращает общий совет анализа здоровья при большом износе
+func calibrationAdvice() string {
+	return T("rule.calibration")
+}
+
+// CalibrationConfig – настройки напоминания о калибровке (раздел calibration в config.json)
+type CalibrationConfig struct {
+	Reminder     bool `json:"reminder"`      // напоминать о калибровке
+	IntervalDays int  `json:"interval_days"` // напоминать, если полного цикла не было столько дней
+	Notify       bool `json:"notify"`        // системное уведомление (не чаще раза в неделю)
+}
+
+// defaultCalibrationConfig – напоминание включено, раз в 90 дней
+func defaultCalibrationConfig() CalibrationConfig {
+	return CalibrationConfig{Reminder: true, IntervalDays: defaultCalibrationIntervalDays, Notify: true}
+}
+
+// Interval возвращает интервал напоминания
+func (c CalibrationConfig) Interval() time.Duration {
+	days := c.IntervalDays
+	if days <= 0 {
+		days = defaultCalibrationIntervalDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// CalibrationCycle – полный цикл разрядки 100% → меньше 10%
+type CalibrationCycle struct {
+	Start        time.Time
+	End          time.Time
+	StartPercent int
+	EndPercent   int
+	Test         bool // тест калибровки, а не обычная разрядка
+}
+
+// Label возвращает подпись источника цикла
+func (c CalibrationCycle) Label() string {
+	if c.Test {
+		return T("calibration_cycle.test")
+	}
+	return T("calibration_cycle.discharge")
+}
+
+// String возвращает строку для истории калибровок
+func (c CalibrationCycle) String() string {
+	return T("calibration_cycle.row", c.End.Local().Format("02.01.2006"),
+		c.StartPercent, c.EndPercent, formatDuration(c.End.Sub(c.Start)), c.Label())
+}
+
+// CalibrationReminder – итог проверки: когда был последний цикл и пора ли напомнить
+type CalibrationReminder struct {
+	History []CalibrationCycle // полные циклы, новые первыми
+	Last    *CalibrationCycle  // nil – полных циклов еще не было
+	Since   time.Time          // от чего отсчитывается интервал: последний цикл или первое измерение
+	Due     bool
+}
+
+// Recent возвращает последние циклы для отчета
+func (r CalibrationReminder) Recent() []CalibrationCycle {
+	if len(r.History) > calibrationHistoryLimit {
+		return r.History[:calibrationHistoryLimit]
+	}
+	return r.History
+}
+
+// Message возвращает текст напоминания
+func (r CalibrationReminder) Message() string {
+	days := int(timeNow().Sub(r.Since).Hours() / 24)
+	if r.Last == nil {
+		return T("calibration.reminder.never", calibrationCycleLow, days)
+	}
+	return T("calibration.reminder.last", days, r.Last.End.Local().Format("02.01.2006"))
+}
+
+// calibrationHistory возвращает полные циклы, новые первыми: завершенные
+// тесты калибровки и разрядки из таблицы sessions. Разрядки, прерванные сном,
+// лежат в sessions несколькими сессиями подряд и склеиваются до первой зарядки.
+func calibrationHistory(db *sqlx.DB) ([]CalibrationCycle, error) {
+	var tests []CalibrationTest
+	if err := db.Select(&tests, `SELECT * FROM calibration_tests WHERE status = ? AND end_percent < ? ORDER BY id`,
+		calibrationCompleted, calibrationCycleLow); err != nil {
+		return nil, fmt.Errorf(T("calibration.err.tests"), err)
+	}
+	var sessions []SessionRecord
+	if err := db.Select(&sessions, `SELECT * FROM sessions ORDER BY start_time`); err != nil {
+		return nil, fmt.Errorf(T("sessions.err.read"), err)
+	}
+
+	var cycles []CalibrationCycle
+	for _, t := range tests {
+		start := t.DischargeStartedAt
+		if start == "" {
+			start = t.StartedAt
+		}
+		cycles = append(cycles, CalibrationCycle{
+			Start: parseStoredTime(start), End: parseStoredTime(t.FinishedAt),
+			StartPercent: t.DischargeStartPercent, EndPercent: t.EndPercent, Test: true,
+		})
+	}
+	for _, c := range dischargeCycles(sessions) {
+		if !overlapsCalibrationTest(c, cycles) {
+			cycles = append(cycles, c)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].End.After(cycles[j].End) })
+	return cycles, nil
+}
+
+// dischargeCycles находит в сессиях полные разрядки. Сессии идут по времени.
+func dischargeCycles(sessions []SessionRecord) []CalibrationCycle {
+	var cycles []CalibrationCycle
+	var run *CalibrationCycle
+	flush := func() {
+		if run != nil && run.StartPercent >= calibrationCycleHigh && run.EndPercent < calibrationCycleLow {
+			cycles = append(cycles, *run)
+		}
+		run = nil
+	}
+	for _, s := range sessions {
+		if s.Kind != sessionDischarge {
+			flush()
+			continue
+		}
+		end := parseStoredTime(s.EndTime)
+		if run == nil {
+			run = &CalibrationCycle{Start: parseStoredTime(s.StartTime), StartPercent: s.StartPercent}
+		}
+		run.End, run.EndPercent = end, s.EndPercent
+	}
+	flush()
+	return cycles
+}
+
+// overlapsCalibrationTest сообщает, что разрядка – это уже учтенный тест калибровки
+func overlapsCalibrationTest(c CalibrationCycle, cycles []CalibrationCycle) bool {
+	for _, t := range cycles {
+		if t.Test && c.Start.Before(t.End) && t.Start.Before(c.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCalibrationReminder определяет, пора ли напомнить о калибровке. Без
+// полных циклов интервал отсчитывается от первого измерения, чтобы не
+// напоминать сразу после установки.
+func checkCalibrationReminder(db *sqlx.DB, cfg CalibrationConfig) (CalibrationReminder, error) {
+	var r CalibrationReminder
+	cycles, err := calibrationHistory(db)
+	if err != nil {
+		return r, err
+	}
+	r.History = cycles
+	if len(cycles) > 0 {
+		r.Last = &cycles[0]
+		r.Since = cycles[0].End
+	} else {
+		var first string
+		if err := db.Get(&first, `SELECT COALESCE(MIN(timestamp), '') FROM measurements`); err != nil {
+			return r, fmt.Errorf(T("err.first_measurement"), err)
+		}
+		if first == "" {
+			return r, nil
+		}
+		r.Since = parseStoredTime(first)
+	}
+	r.Due = cfg.Reminder && timeNow().Sub(r.Since) >= cfg.Interval()
+	return r, nil
+}
+
+// calibrationRecommendations сверяет рекомендации с историей калибровок:
+// общий совет убирается, если полная разрядка была недавно, а при
+// просроченной калибровке его место занимает напоминание
+func calibrationRecommendations(recs []string, r CalibrationReminder) []string {
+	out := make([]string, 0, len(recs)+1)
+	for _, rec := range recs {
+		if rec != calibrationAdvice() || (r.Last == nil && !r.Due) {
+			out = append(out, rec)
+		}
+	}
+	if r.Due {
+		out = append(out, r.Message())
+	}
+	return out
+}
+
+// updateCalibrationReminder пересчитывает напоминание и, если пора, присылает
+// уведомление – не чаще calibrationNotifyEvery, с учетом перезапусков
+func (dc *DataCollector) updateCalibrationReminder() {
+	cfg := getConfig().Calibration
+	r, err := checkCalibrationReminder(dc.db, cfg)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+		return
+	}
+	dc.calibrationReminder.Store(&r)
+	if !r.Due || !cfg.Notify {
+		return
+	}
+	var raw string
+	err = dc.db.Get(&raw, `SELECT updated_at FROM analysis_state WHERE name = 'calibration_reminder'`)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logWarnf("⚠️ чтение напоминания о калибровке: %v", err)
+		return
+	}
+	if raw != "" && timeNow().Sub(parseStoredTime(raw)) < calibrationNotifyEvery {
+		return
+	}
+	message := r.Message()
+	logInfof("🎯 %s", message)
+	sendAlert(alertInfo, T("calibration_reminder.alert"), message)
+	_, err = dc.db.Exec(`INSERT OR REPLACE INTO analysis_state (name, state, updated_at) VALUES ('calibration_reminder', ?, ?)`,
+		r.Since.UTC().Format(time.RFC3339), timeNow().UTC().Format(time.RFC3339))
+	if err != nil {
+		logWarnf("⚠️ сохранение напоминания о калибровке: %v", err)
+	}
+}
+
+// CalibrationReminder возвращает последний итог проверки; nil – еще не проверялось
+func (dc *DataCollector) CalibrationReminder() *CalibrationReminder {
+	return dc.calibrationReminder.Load()
+}
+
+// calibrationReminderLine возвращает строку напоминания для дашборда или
+// пустую строку, если калибровка не нужна
+func (a *App) calibrationReminderLine() string {
+	if a.dataService == nil || a.dataService.collector == nil {
+		return ""
+	}
+	r := a.dataService.collector.CalibrationReminder()
+	if r == nil || !r.Due {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(theme.Info).Render("🎯 " + r.Message())
+}
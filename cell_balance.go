@@ -0,0 +1,103 @@
+// cell_balance.go
+//
+// Напряжения отдельных ячеек и детектор их разбалансировки. Суммарное
+// напряжение батареи скрывает, что одна ячейка садится раньше остальных, а
+// растущий разброс между ячейками – ранний признак отказа батареи.
+// Напряжения берутся из BatteryData.CellVoltage контроллера (есть на многих
+// моделях MacBook) и хранятся в measurements.cell_voltages строкой "мВ,мВ,…".
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cellImbalanceWarning    = 50 // разброс напряжений ячеек, мВ, после которого предупреждение
+	cellImbalanceCritical   = 100
+	cellImbalanceMinSamples = 3 // разброс в одном измерении под нагрузкой бывает и у здоровой батареи
+)
+
+// formatCellVoltages записывает напряжения ячеек для столбца cell_voltages
+func formatCellVoltages(cells []int) string {
+	parts := make([]string, len(cells))
+	for i, v := range cells {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseCellVoltages разбирает столбец cell_voltages; нечисловые и нулевые
+// значения пропускаются
+func parseCellVoltages(s string) []int {
+	var cells []int
+	for _, part := range strings.Split(s, ",") {
+		if v, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && v > 0 {
+			cells = append(cells, v)
+		}
+	}
+	return cells
+}
+
+// cellSpread возвращает разброс напряжений ячеек, мВ; false – ячеек меньше двух
+func cellSpread(m Measurement) (int, bool) {
+	cells := parseCellVoltages(m.CellVoltages)
+	if len(cells) < 2 {
+		return 0, false
+	}
+	lo, hi := cells[0], cells[0]
+	for _, v := range cells[1:] {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	return hi - lo, true
+}
+
+// cellImbalanceAnomalies ищет периоды, когда разброс ячеек держался выше
+// cellImbalanceWarning хотя бы cellImbalanceMinSamples измерений подряд
+func cellImbalanceAnomalies(ms []Measurement) []Anomaly {
+	var anomalies []Anomaly
+	var start, end time.Time
+	var samples, peak int
+	var peakCells string
+	finish := func() {
+		if samples >= cellImbalanceMinSamples {
+			severity := alertWarning
+			if peak >= cellImbalanceCritical {
+				severity = alertCritical
+			}
+			anomalies = append(anomalies, Anomaly{
+				Type:     anomalyCellImbalance,
+				Severity: severity,
+				Start:    start,
+				End:      end,
+				Message: T("anomaly.cell_imbalance",
+					peak, peakCells, samples, start.Local().Format("02.01 15:04"), end.Local().Format("15:04")),
+				Metrics: map[string]float64{"spread_mv": float64(peak), "samples": float64(samples)},
+			})
+		}
+		samples, peak = 0, 0
+	}
+	for _, m := range ms {
+		spread, ok := cellSpread(m)
+		if !ok {
+			continue // измерение без ячеек не прерывает период
+		}
+		if spread < cellImbalanceWarning {
+			finish()
+			continue
+		}
+		at := parseStoredTime(m.Timestamp)
+		if samples == 0 {
+			start = at
+		}
+		end = at
+		samples++
+		if spread > peak {
+			peak, peakCells = spread, strings.ReplaceAll(m.CellVoltages, ",", "/")
+		}
+	}
+	finish()
+	return anomalies
+}
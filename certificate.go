@@ -0,0 +1,451 @@
+// certificate.go
+//
+// Сертификат состояния батареи – одностраничный HTML-документ для
+// объявления о продаже: измеренная ёмкость, циклы, возраст батареи, период
+// наблюдения, оценка здоровья и контрольная сумма измерений, на которых он
+// основан. Возраст считается от даты изготовления, если ее сообщает
+// контроллер батареи, иначе – не меньше срока наблюдения.
+// PDF получается печатью страницы из браузера.
+//
+// Выданные сертификаты записываются в таблицу certificates вместе с
+// охваченным периодом: очистка по сроку хранения удаляет старые измерения,
+// и пересчитать сумму по истории для давнего сертификата уже нельзя.
+
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BatteryCertificate – данные сертификата состояния батареи
+type BatteryCertificate struct {
+	IssuedAt         time.Time
+	Serial           string
+	DesignCapacity   int // мАч
+	FullChargeCap    int // мАч
+	Wear             float64
+	CycleCount       int
+	Condition        string
+	HealthScore      int
+	HealthStatus     string
+	HealthModel      HealthModel
+	FirstSeen        time.Time
+	LastSeen         time.Time
+	MeasurementCount int
+	DataHash         string    // SHA-256 измерений текущей батареи
+	Manufactured     time.Time // дата изготовления; нулевая – неизвестна
+}
+
+// certificatesSchema – выданные сертификаты: по ним код проверяется и после
+// удаления старых измерений
+const certificatesSchema = `
+CREATE TABLE IF NOT EXISTS certificates (
+	data_hash TEXT PRIMARY KEY,
+	issued_at TEXT NOT NULL,
+	serial TEXT NOT NULL DEFAULT '',
+	first_seen TEXT NOT NULL,
+	last_seen TEXT NOT NULL,
+	measurement_count INTEGER NOT NULL,
+	design_capacity INTEGER NOT NULL,
+	full_charge_capacity INTEGER NOT NULL,
+	cycle_count INTEGER NOT NULL,
+	condition TEXT NOT NULL DEFAULT '',
+	health_score INTEGER NOT NULL DEFAULT 0,
+	health_status TEXT NOT NULL DEFAULT '',
+	health_model TEXT NOT NULL DEFAULT '',
+	manufactured_at TEXT NOT NULL DEFAULT ''
+);`
+
+// IssuedCertificate – запись о выданном сертификате
+type IssuedCertificate struct {
+	DataHash         string `db:"data_hash"`
+	IssuedAt         string `db:"issued_at"`
+	Serial           string `db:"serial"`
+	FirstSeen        string `db:"first_seen"` // охваченный период измерений
+	LastSeen         string `db:"last_seen"`
+	MeasurementCount int    `db:"measurement_count"`
+	DesignCapacity   int    `db:"design_capacity"`
+	FullChargeCap    int    `db:"full_charge_capacity"`
+	CycleCount       int    `db:"cycle_count"`
+	Condition        string `db:"condition"`
+	HealthScore      int    `db:"health_score"`
+	HealthStatus     string `db:"health_status"`
+	HealthModel      string `db:"health_model"`
+	ManufacturedAt   string `db:"manufactured_at"` // пусто – дата изготовления неизвестна
+}
+
+// Certificate восстанавливает данные сертификата из записи
+func (r IssuedCertificate) Certificate() BatteryCertificate {
+	return BatteryCertificate{
+		IssuedAt:         parseStoredTime(r.IssuedAt).Local(),
+		Serial:           r.Serial,
+		DesignCapacity:   r.DesignCapacity,
+		FullChargeCap:    r.FullChargeCap,
+		Wear:             computeWear(r.DesignCapacity, r.FullChargeCap),
+		CycleCount:       r.CycleCount,
+		Condition:        r.Condition,
+		HealthScore:      r.HealthScore,
+		HealthStatus:     r.HealthStatus,
+		HealthModel:      HealthModel(r.HealthModel),
+		FirstSeen:        parseStoredTime(r.FirstSeen).Local(),
+		LastSeen:         parseStoredTime(r.LastSeen).Local(),
+		MeasurementCount: r.MeasurementCount,
+		DataHash:         r.DataHash,
+		Manufactured:     parseManufactured(r.ManufacturedAt),
+	}
+}
+
+// parseManufactured разбирает сохраненную дату изготовления
+func parseManufactured(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// saveIssuedCertificate записывает выданный сертификат
+func saveIssuedCertificate(db *sqlx.DB, c BatteryCertificate) error {
+	r := IssuedCertificate{
+		DataHash:         c.DataHash,
+		IssuedAt:         c.IssuedAt.UTC().Format(time.RFC3339),
+		Serial:           c.Serial,
+		FirstSeen:        c.FirstSeen.UTC().Format(time.RFC3339),
+		LastSeen:         c.LastSeen.UTC().Format(time.RFC3339),
+		MeasurementCount: c.MeasurementCount,
+		DesignCapacity:   c.DesignCapacity,
+		FullChargeCap:    c.FullChargeCap,
+		CycleCount:       c.CycleCount,
+		Condition:        c.Condition,
+		HealthScore:      c.HealthScore,
+		HealthStatus:     c.HealthStatus,
+		HealthModel:      string(c.HealthModel),
+	}
+	if !c.Manufactured.IsZero() {
+		r.ManufacturedAt = c.Manufactured.Format("2006-01-02")
+	}
+	// Повторная выдача по тем же данным оставляет первую запись
+	_, err := db.NamedExec(`INSERT OR IGNORE INTO certificates (data_hash, issued_at, serial, first_seen, last_seen,
+		measurement_count, design_capacity, full_charge_capacity, cycle_count, condition,
+		health_score, health_status, health_model, manufactured_at)
+		VALUES (:data_hash, :issued_at, :serial, :first_seen, :last_seen,
+		:measurement_count, :design_capacity, :full_charge_capacity, :cycle_count, :condition,
+		:health_score, :health_status, :health_model, :manufactured_at)`, r)
+	if err != nil {
+		return fmt.Errorf(T("cert.err.save"), err)
+	}
+	return nil
+}
+
+// findIssuedCertificate ищет выданный сертификат по полной сумме или короткому коду
+func findIssuedCertificate(db *sqlx.DB, hash string) (*IssuedCertificate, error) {
+	want, err := normalizeCertificateCode(hash)
+	if err != nil {
+		return nil, err
+	}
+	var r IssuedCertificate
+	err = db.Get(&r, `SELECT * FROM certificates WHERE data_hash LIKE ? ORDER BY issued_at LIMIT 1`, strings.ToLower(want)+"%")
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("cert.err.read"), err)
+	}
+	return &r, nil
+}
+
+// normalizeCertificateCode приводит код к сумме без дефисов в верхнем регистре
+func normalizeCertificateCode(hash string) (string, error) {
+	want := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(hash), "-", ""))
+	if len(want) < 12 {
+		return "", errors.New(T("cert.err.short_code"))
+	}
+	return want, nil
+}
+
+// ObservedDays возвращает длительность наблюдения в днях
+func (c BatteryCertificate) ObservedDays() int {
+	return int(c.LastSeen.Sub(c.FirstSeen).Hours() / 24)
+}
+
+// Age возвращает возраст батареи на момент выдачи: от даты изготовления, а
+// если она неизвестна – не меньше срока с первого измерения
+func (c BatteryCertificate) Age() string {
+	if !c.Manufactured.IsZero() {
+		return T("cert.age.manufactured", formatBatteryAge(c.Manufactured, c.IssuedAt), c.Manufactured.Format("01.2006"))
+	}
+	return T("cert.age.at_least", formatBatteryAge(c.FirstSeen, c.IssuedAt))
+}
+
+// formatBatteryAge возвращает срок от from до to: "2 г. 3 мес.", "5 мес." или "12 дн."
+func formatBatteryAge(from, to time.Time) string {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	switch {
+	case months < 1:
+		return T("age.days", max(int(to.Sub(from).Hours()/24), 0))
+	case months < 12:
+		return T("age.months", months)
+	case months%12 == 0:
+		return T("age.years", months/12)
+	}
+	return T("age.years_months", months/12, months%12)
+}
+
+// batteryManufactured возвращает дату изготовления батареи с серийным номером
+// serial по данным источника; нулевая дата – источник ее не сообщает или
+// установлена другая батарея
+func batteryManufactured(serial string) time.Time {
+	d, err := newBatterySource().Details()
+	if err != nil || (serial != "" && d.Serial != serial) {
+		return time.Time{}
+	}
+	return d.Manufactured
+}
+
+// ShortCode возвращает укороченную контрольную сумму для сверки на глаз: "1A2B-3C4D-5E6F"
+func (c BatteryCertificate) ShortCode() string {
+	code := strings.ToUpper(c.DataHash)
+	if len(code) < 12 {
+		return code
+	}
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12]
+}
+
+// writeCertificateRecord пишет каноническое представление измерения.
+// Порядок и набор полей фиксированы – менять их нельзя, иначе выданные
+// сертификаты перестанут проверяться.
+func writeCertificateRecord(w io.Writer, m Measurement) {
+	fmt.Fprintf(w, "%s|%d|%s|%d|%d|%d|%d|%d|%d|%d|%s\n",
+		m.Timestamp, m.Percentage, m.State, m.CycleCount,
+		m.FullChargeCap, m.DesignCapacity, m.CurrentCapacity, m.Temperature,
+		m.Voltage, m.Amperage, m.BatterySerial)
+}
+
+// certificateHash считает SHA-256 по каноническому представлению измерений
+func certificateHash(ms []Measurement) string {
+	h := sha256.New()
+	for _, m := range ms {
+		writeCertificateRecord(h, m)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// currentBatteryHistory возвращает всю историю измерений текущей батареи
+func currentBatteryHistory(db *sqlx.DB) ([]Measurement, error) {
+	ms, err := getMeasurementsSince(db, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf(T("err.data"), err)
+	}
+	segment := currentBatterySegment(ms)
+	if len(segment) == 0 {
+		return nil, errors.New(T("cert.err.no_data"))
+	}
+	return segment, nil
+}
+
+// buildCertificate собирает сертификат по измерениям одной батареи
+func buildCertificate(segment []Measurement) (BatteryCertificate, error) {
+	latest := segment[len(segment)-1]
+	if latest.DesignCapacity == 0 || latest.FullChargeCap == 0 {
+		return BatteryCertificate{}, errors.New(T("cert.err.no_capacity"))
+	}
+	first, _ := time.Parse(time.RFC3339, segment[0].Timestamp)
+	last, _ := time.Parse(time.RFC3339, latest.Timestamp)
+
+	cert := BatteryCertificate{
+		IssuedAt:         time.Now(),
+		Serial:           latest.BatterySerial,
+		DesignCapacity:   latest.DesignCapacity,
+		FullChargeCap:    latest.FullChargeCap,
+		Wear:             computeWear(latest.DesignCapacity, latest.FullChargeCap),
+		CycleCount:       latest.CycleCount,
+		Condition:        latest.AppleCondition,
+		FirstSeen:        first.Local(),
+		LastSeen:         last.Local(),
+		MeasurementCount: len(segment),
+		DataHash:         certificateHash(segment),
+	}
+	if health := analyzeBatteryHealth(segment); health != nil {
+		cert.HealthScore = health.HealthScore
+		cert.HealthStatus = health.HealthStatus
+		cert.HealthModel = health.ScoreModel
+	}
+	return cert, nil
+}
+
+// verifyCertificateHash ищет момент выдачи сертификата: после него сбор данных
+// продолжается, поэтому сумма сверяется с каждым префиксом истории батареи.
+// Принимает полную сумму или короткий код. Нужен для сертификатов, выданных
+// до появления таблицы certificates, и работает, пока измерения не удалены.
+func verifyCertificateHash(segment []Measurement, hash string) (BatteryCertificate, bool, error) {
+	want, err := normalizeCertificateCode(hash)
+	if err != nil {
+		return BatteryCertificate{}, false, err
+	}
+	h := sha256.New()
+	for i, m := range segment {
+		writeCertificateRecord(h, m)
+		if strings.HasPrefix(strings.ToUpper(hex.EncodeToString(h.Sum(nil))), want) {
+			cert, err := buildCertificate(segment[:i+1])
+			return cert, err == nil, err
+		}
+	}
+	return BatteryCertificate{}, false, nil
+}
+
+const certificateTemplate = `<!DOCTYPE html>
+<html lang="{{t "lang"}}">
+<head>
+<meta charset="UTF-8">
+<meta name="batmon-data-hash" content="{{.DataHash}}">
+<title>{{t "cert.title"}}</title>
+<style>
+  @page { size: A4; margin: 15mm; }
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; color: #222; max-width: 720px; margin: 24px auto; }
+  h1 { font-size: 24px; margin: 0 0 4px; }
+  .sub { color: #666; margin-bottom: 20px; }
+  .score { font-size: 48px; font-weight: 700; }
+  .status { font-size: 18px; color: #444; }
+  table { width: 100%; border-collapse: collapse; margin: 20px 0; }
+  td { padding: 8px 4px; border-bottom: 1px solid #e5e5e5; }
+  td:first-child { color: #666; width: 45%; }
+  .hash { font-family: Menlo, Consolas, monospace; font-size: 11px; word-break: break-all; color: #555; }
+  .code { font-family: Menlo, Consolas, monospace; font-size: 20px; letter-spacing: 2px; }
+  .note { font-size: 12px; color: #777; margin-top: 24px; }
+  @media print { .noprint { display: none; } body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>🔋 {{t "cert.title"}}</h1>
+<div class="sub">{{t "cert.issued" (.IssuedAt.Format "02.01.2006 15:04")}}</div>
+
+<div class="score">{{.HealthScore}}/100</div>
+<div class="status">{{.HealthStatus}}</div>
+{{if .HealthModel}}<div class="sub">{{t "cert.model" .HealthModel.Label}}</div>{{end}}
+
+<table>
+  <tr><td>{{t "cert.serial"}}</td><td>{{if .Serial}}{{.Serial}}{{else}}{{t "cert.serial.unknown"}}{{end}}</td></tr>
+  <tr><td>{{t "cert.full_cap"}}</td><td>{{t "cert.mah" .FullChargeCap}}</td></tr>
+  <tr><td>{{t "cert.design_cap"}}</td><td>{{t "cert.mah" .DesignCapacity}}</td></tr>
+  <tr><td>{{t "cert.wear"}}</td><td>{{printf "%.1f" .Wear}}%</td></tr>
+  <tr><td>{{t "cert.cycles"}}</td><td>{{.CycleCount}}</td></tr>
+  <tr><td>{{t "cert.age"}}</td><td>{{.Age}}</td></tr>
+  {{if .Condition}}<tr><td>{{t "cert.condition"}}</td><td>{{.Condition}}</td></tr>{{end}}
+  <tr><td>{{t "cert.period"}}</td><td>{{.FirstSeen.Format "02.01.2006"}} – {{.LastSeen.Format "02.01.2006"}} ({{t "cert.days" .ObservedDays}})</td></tr>
+  <tr><td>{{t "cert.measurements"}}</td><td>{{.MeasurementCount}}</td></tr>
+</table>
+
+<div>{{t "cert.code"}} <span class="code">{{.ShortCode}}</span></div>
+<div class="hash">SHA-256: {{.DataHash}}</div>
+
+<p class="note">{{t "cert.note"}} <code>batmon -verify-certificate {{.ShortCode}}</code>
+{{t "cert.note.where"}}</p>
+<p class="note noprint">{{t "cert.pdf"}}</p>
+</body>
+</html>
+`
+
+// exportCertificateHTML записывает сертификат в HTML-файл
+func exportCertificateHTML(cert BatteryCertificate, filename string) error {
+	t, err := template.New("certificate").Funcs(template.FuncMap{"t": T}).Parse(certificateTemplate)
+	if err != nil {
+		return fmt.Errorf(T("err.template"), err)
+	}
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		return t.Execute(w, cert)
+	}, verifyHTMLReport)
+}
+
+// issueCertificate записывает сертификат в базу и только потом в файл:
+// сертификат, код которого нельзя проверить, выдавать нельзя
+func issueCertificate(db *sqlx.DB, cert BatteryCertificate, path string) error {
+	if err := saveIssuedCertificate(db, cert); err != nil {
+		return err
+	}
+	if err := exportCertificateHTML(cert, path); err != nil {
+		return fmt.Errorf(T("cert.err.export"), err)
+	}
+	return nil
+}
+
+// runCertificateExport создает сертификат по данным из БД
+func runCertificateExport(filename string) error {
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	segment, err := currentBatteryHistory(db)
+	if err != nil {
+		return err
+	}
+	cert, err := buildCertificate(segment)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(filename, ".html") && !strings.HasSuffix(filename, ".htm") {
+		filename += ".html"
+	}
+	path, err := getExportPath(filename)
+	if err != nil {
+		return fmt.Errorf(T("cert.err.path"), err)
+	}
+	cert.Manufactured = batteryManufactured(cert.Serial)
+	if err := issueCertificate(db, cert, path); err != nil {
+		return err
+	}
+	fmt.Println(T("cert.cli.saved", path))
+	fmt.Println(T("cert.cli.code", cert.ShortCode()))
+	return nil
+}
+
+// verifyCertificate ищет код среди выданных сертификатов, а если не нашел –
+// пересчитывает сумму по истории батареи
+func verifyCertificate(db *sqlx.DB, hash string) (BatteryCertificate, bool, error) {
+	issued, err := findIssuedCertificate(db, hash)
+	if err != nil {
+		return BatteryCertificate{}, false, err
+	}
+	if issued != nil {
+		return issued.Certificate(), true, nil
+	}
+	segment, err := currentBatteryHistory(db)
+	if err != nil {
+		return BatteryCertificate{}, false, err
+	}
+	return verifyCertificateHash(segment, hash)
+}
+
+// runCertificateVerify сверяет код из сертификата с локальными данными
+func runCertificateVerify(hash string) error {
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	cert, ok, err := verifyCertificate(db, hash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(T("cert.err.mismatch"))
+	}
+	fmt.Println(T("cert.cli.verified",
+		cert.LastSeen.Format("02.01.2006 15:04"), cert.MeasurementCount, cert.Wear, cert.CycleCount))
+	return nil
+}
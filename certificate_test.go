@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Сертификат проверяется и после того, как очистка удалила измерения, по
+// которым считалась его сумма
+func TestCertificateVerifyAfterRetention(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(48*time.Hour))
+	db := newTestDB(t)
+	insertFixture(t, db, steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 60))
+
+	segment, err := currentBatteryHistory(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := buildCertificate(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Сертификат до появления таблицы проверяется по истории
+	if _, ok, err := verifyCertificate(db, cert.ShortCode()); err != nil || !ok {
+		t.Fatalf("проверка по истории: ok=%v, err=%v", ok, err)
+	}
+
+	if err := saveIssuedCertificate(db, cert); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveIssuedCertificate(db, cert); err != nil {
+		t.Fatalf("повторная выдача: %v", err)
+	}
+	cutoff := fixtureStart.Add(3 * time.Hour).UTC().Format(time.RFC3339)
+	if _, err := db.Exec(`DELETE FROM measurements WHERE timestamp < ?`, cutoff); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, code := range []string{cert.ShortCode(), cert.DataHash} {
+		got, ok, err := verifyCertificate(db, code)
+		if err != nil || !ok {
+			t.Fatalf("%s: ok=%v, err=%v", code, ok, err)
+		}
+		if got.MeasurementCount != cert.MeasurementCount || got.CycleCount != cert.CycleCount ||
+			got.HealthModel != cert.HealthModel || !got.FirstSeen.Equal(cert.FirstSeen) {
+			t.Errorf("%s: сертификат %+v, ожидался %+v", code, got, cert)
+		}
+	}
+
+	if _, ok, err := verifyCertificate(db, "0000-0000-0000"); err != nil || ok {
+		t.Errorf("чужой код: ok=%v, err=%v", ok, err)
+	}
+	if _, _, err := verifyCertificate(db, "ABC"); err == nil {
+		t.Error("короткий код принят")
+	}
+}
+
+// Сертификат не записывается в файл, если его не удалось сохранить в базе:
+// такой код проверить было бы нельзя
+func TestIssueCertificateSavesFirst(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(48*time.Hour))
+	db := newTestDB(t)
+	insertFixture(t, db, steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12))
+	segment, err := currentBatteryHistory(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := buildCertificate(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert.Manufactured = time.Date(2023, 3, 15, 0, 0, 0, 0, time.Local)
+
+	path := filepath.Join(t.TempDir(), "cert.html")
+	if err := issueCertificate(db, cert, path); err != nil {
+		t.Fatal(err)
+	}
+	html, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(html), cert.Age()) {
+		t.Errorf("в сертификате нет возраста %q", cert.Age())
+	}
+	if got, ok, err := verifyCertificate(db, cert.ShortCode()); err != nil || !ok || !got.Manufactured.Equal(cert.Manufactured) {
+		t.Errorf("выданный сертификат: %+v, ok=%v, err=%v", got, ok, err)
+	}
+
+	if _, err := db.Exec(`DROP TABLE certificates`); err != nil {
+		t.Fatal(err)
+	}
+	failed := filepath.Join(t.TempDir(), "failed.html")
+	if err := issueCertificate(db, cert, failed); err == nil {
+		t.Fatal("сертификат выдан без записи в базу")
+	}
+	if _, err := os.Stat(failed); !os.IsNotExist(err) {
+		t.Errorf("файл сертификата создан без записи в базу: %v", err)
+	}
+}
+
+func TestCertificateAge(t *testing.T) {
+	freezeEnvironment(t, fixtureStart)
+	issued := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		cert BatteryCertificate
+		want string
+	}{
+		{BatteryCertificate{IssuedAt: issued, Manufactured: time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)},
+			"2 г. 3 мес., изготовлена 03.2023"},
+		{BatteryCertificate{IssuedAt: issued, Manufactured: time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)},
+			"11 мес., изготовлена 06.2024"},
+		{BatteryCertificate{IssuedAt: issued, FirstSeen: issued.AddDate(0, 0, -12)},
+			"не менее 12 дн. (дата изготовления неизвестна)"},
+	}
+	for _, c := range cases {
+		if got := c.cert.Age(); got != c.want {
+			t.Errorf("возраст %q, ожидался %q", got, c.want)
+		}
+	}
+
+	// Дата изготовления Smart Battery: год от 1980, месяц и день в младших битах
+	if got := smartBatteryDate(43<<9 | 3<<5 | 15); !got.Equal(time.Date(2023, 3, 15, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("smartBatteryDate: %v", got)
+	}
+	if got := smartBatteryDate(0x7fffffff); !got.IsZero() {
+		t.Errorf("недопустимая дата принята: %v", got)
+	}
+}
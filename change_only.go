@@ -0,0 +1,85 @@
+// change_only.go
+//
+// Запись только изменений: в этом режиме измерение попадает в БД, если
+// относительно последнего записанного изменился заряд, режим питания,
+// ёмкость, число циклов или батарея, либо температура ушла дальше порога.
+// Раз в несколько минут пишется контрольное измерение, даже если ничего не
+// изменилось, – чтобы сессии и сводки по дням не принимали тишину за сон.
+// 90 дней одинаковых строк от сети раз в 30 секунд – это в основном
+// потраченное место. Интерфейс по-прежнему получает все измерения из буфера.
+
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultKeepAliveMinutes = 10 // контрольное измерение; меньше sessionMaxGap
+	defaultTempEpsilon      = 1  // изменение температуры, °C, достойное записи
+)
+
+// ChangeOnlyConfig – режим записи только изменений в config.json
+type ChangeOnlyConfig struct {
+	Enabled          bool `json:"enabled"`
+	KeepAliveMinutes int  `json:"keep_alive_minutes"` // контрольное измерение не реже; 0 – по умолчанию
+	TempEpsilon      int  `json:"temp_epsilon"`       // порог изменения температуры, °C; 0 – по умолчанию
+	CapacityEpsilon  int  `json:"capacity_epsilon"`   // порог изменения текущей ёмкости, мАч; 0 – любое
+}
+
+// KeepAlive возвращает период контрольных измерений; он не длиннее
+// sessionMaxGap, иначе тишина в БД разорвет сессию
+func (c ChangeOnlyConfig) KeepAlive() time.Duration {
+	minutes := c.KeepAliveMinutes
+	if minutes <= 0 {
+		minutes = defaultKeepAliveMinutes
+	}
+	d := time.Duration(minutes) * time.Minute
+	if d > sessionMaxGap {
+		return sessionMaxGap
+	}
+	return d
+}
+
+// changeFilter помнит последнее записанное измерение. Сбор в TUI идет в
+// отдельных горутинах, поэтому фильтр защищен мьютексом.
+type changeFilter struct {
+	mu     sync.Mutex
+	last   *Measurement
+	lastAt time.Time
+}
+
+// Skip сообщает, можно ли не записывать измерение, и запоминает его, если
+// запись нужна
+func (f *changeFilter) Skip(m Measurement, cfg ChangeOnlyConfig) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !cfg.Enabled {
+		f.last = nil
+		return false
+	}
+	now := timeNow()
+	if f.last != nil && now.Sub(f.lastAt) < cfg.KeepAlive() && !measurementChanged(*f.last, m, cfg) {
+		return true
+	}
+	f.last, f.lastAt = &m, now
+	return false
+}
+
+// measurementChanged сообщает, отличается ли измерение от записанного по
+// отслеживаемым значениям
+func measurementChanged(prev, m Measurement, cfg ChangeOnlyConfig) bool {
+	tempEpsilon := cfg.TempEpsilon
+	if tempEpsilon <= 0 {
+		tempEpsilon = defaultTempEpsilon
+	}
+	return m.Percentage != prev.Percentage ||
+		!strings.EqualFold(m.State, prev.State) ||
+		m.CycleCount != prev.CycleCount ||
+		m.FullChargeCap != prev.FullChargeCap ||
+		m.BatterySerial != prev.BatterySerial ||
+		abs(m.CurrentCapacity-prev.CurrentCapacity) > cfg.CapacityEpsilon ||
+		abs(m.Temperature-prev.Temperature) >= tempEpsilon
+}
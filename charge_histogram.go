@@ -0,0 +1,117 @@
+// charge_histogram.go
+//
+// Гистограмма распределения заряда: сколько времени батарея провела в каждом
+// диапазоне (0–20, 20–40, …, 80–100%). Отдельно считается время на 100% и
+// ниже 20% – именно эти зоны сильнее всего влияют на износ, по ним вкладка
+// «Прогнозы» дает советы по продлению срока службы.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	chargeBucketWidth  = 20 // ширина диапазона гистограммы, %
+	chargeBucketCount  = 100 / chargeBucketWidth
+	chargeLowZone      = 20 // заряд, ниже которого батарея изнашивается быстрее, %
+	lowZoneAdviceShare = 10 // % времени ниже 20%, после которого в отчете совет
+)
+
+// ChargeHistogram – время в каждом диапазоне заряда за период отчета
+type ChargeHistogram struct {
+	Buckets [chargeBucketCount]time.Duration // 0–20, 20–40, …, 80–100%
+	Full    time.Duration                    // на 100% (входит в последний диапазон)
+	Low     time.Duration                    // ниже 20% (совпадает с первым диапазоном)
+	Total   time.Duration
+}
+
+// chargeHistogram раскладывает наблюдаемое время по диапазонам заряда.
+// Интервал относится к заряду в его начале; пропуски дольше часа (сон,
+// выключение) не учитываются, как в fullChargeTime.
+func chargeHistogram(ms []Measurement) ChargeHistogram {
+	var h ChargeHistogram
+	for i := 1; i < len(ms); i++ {
+		prev := ms[i-1]
+		dt := parseStoredTime(ms[i].Timestamp).Sub(parseStoredTime(prev.Timestamp))
+		if dt <= 0 || dt > time.Hour {
+			continue
+		}
+		h.Total += dt
+		h.Buckets[chargeBucket(prev.Percentage)] += dt
+		if prev.Percentage >= chargeFullZone {
+			h.Full += dt
+		}
+		if prev.Percentage < chargeLowZone {
+			h.Low += dt
+		}
+	}
+	return h
+}
+
+// chargeBucket возвращает индекс диапазона для заряда; 100% входит в последний
+func chargeBucket(percentage int) int {
+	return max(min(percentage/chargeBucketWidth, chargeBucketCount-1), 0)
+}
+
+// Share возвращает долю времени от наблюдаемого, %
+func (h ChargeHistogram) Share(d time.Duration) float64 {
+	if h.Total == 0 {
+		return 0
+	}
+	return float64(d) / float64(h.Total) * 100
+}
+
+// Advice возвращает советы по зонам 100% и ниже 20% (пусто – поводов нет)
+func (h ChargeHistogram) Advice() []string {
+	var advice []string
+	if share := h.Share(h.Full); share >= fullZoneAdviceShare {
+		advice = append(advice, T("histogram.advice.full", share, defaultChargeCeiling))
+	}
+	if share := h.Share(h.Low); share >= lowZoneAdviceShare {
+		advice = append(advice, T("histogram.advice.low", share, chargeLowZone))
+	}
+	return advice
+}
+
+// renderChargeHistogram рендерит гистограмму распределения заряда для вкладки «Прогнозы»
+func renderChargeHistogram(h ChargeHistogram) string {
+	if h.Total == 0 {
+		return ""
+	}
+	const barWidth = 30
+	var longest time.Duration
+	for _, d := range h.Buckets {
+		if d > longest {
+			longest = d
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(theme.Secondary).Render(T("histogram.title")) + "\n")
+	for i := chargeBucketCount - 1; i >= 0; i-- {
+		color := theme.Good
+		if i == 0 || i == chargeBucketCount-1 {
+			color = theme.Caution // крайние диапазоны вреднее для батареи
+		}
+		bar := ""
+		if h.Buckets[i] > 0 {
+			bar = strings.Repeat("█", max(1, int(float64(h.Buckets[i])/float64(longest)*barWidth)))
+		}
+		label := fmt.Sprintf("%d–%d%%", i*chargeBucketWidth, (i+1)*chargeBucketWidth)
+		content.WriteString(fmt.Sprintf("%-8s %s %5.1f%% %s\n", label,
+			lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("%-*s", barWidth, bar)),
+			h.Share(h.Buckets[i]), formatDuration(h.Buckets[i])))
+	}
+	content.WriteString(T("histogram.full", formatDuration(h.Full), h.Share(h.Full)))
+	content.WriteString(T("histogram.low", chargeLowZone, formatDuration(h.Low), h.Share(h.Low)))
+	for _, advice := range h.Advice() {
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Caution).Render("⚠️ "+advice) + "\n")
+	}
+	content.WriteString("\n")
+	return content.String()
+}
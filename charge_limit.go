@@ -0,0 +1,120 @@
+// charge_limit.go
+//
+// Советник по ограничению заряда: batmon не управляет зарядкой сам, но по
+// настройке charge_limit в config.json сообщает, когда заряд поднялся до
+// потолка (например, 80% – пора отключить адаптер) или опустился до пола
+// (30% – пора подключить). Вместо уведомления или вместе с ним можно
+// запускать собственный скрипт (см. hooks.go), например для умной розетки. Отчет
+// показывает, сколько времени батарея провела на 100% от сети.
+
+package main
+
+import "time"
+
+const (
+	defaultChargeCeiling  = 80
+	defaultChargeFloor    = 30
+	chargeFullZone        = 100 // заряд, вредный при долгом нахождении от сети, %
+	chargeLimitHysteresis = 2   // %, на столько заряд должен вернуться, чтобы сообщить снова
+	fullZoneAdviceShare   = 50  // % времени на 100%, после которого в отчете совет
+)
+
+// События советника
+const (
+	chargeEventCeiling = "ceiling" // заряд достиг потолка
+	chargeEventFloor   = "floor"   // заряд опустился до пола
+)
+
+// ChargeLimitConfig – настройки советника по ограничению заряда
+type ChargeLimitConfig struct {
+	Enabled bool   `json:"enabled"`
+	Ceiling int    `json:"ceiling"`        // заряд для сообщения "отключите адаптер", %; 0 – не сообщать
+	Floor   int    `json:"floor"`          // заряд для сообщения "подключите адаптер", %; 0 – не сообщать
+	Notify  bool   `json:"notify"`         // системное уведомление
+	Hook    string `json:"hook,omitempty"` // прежний скрипт порога: BATMON_EVENT – ceiling или floor
+}
+
+// defaultChargeLimitConfig – советник выключен, пороги 80/30%
+func defaultChargeLimitConfig() ChargeLimitConfig {
+	return ChargeLimitConfig{Ceiling: defaultChargeCeiling, Floor: defaultChargeFloor, Notify: true}
+}
+
+// chargeLimitZone возвращает событие, в зоне которого находится измерение.
+// Текущее событие держится, пока заряд не отойдет от порога на
+// chargeLimitHysteresis, чтобы колебания на пороге не повторяли сообщение.
+func chargeLimitZone(m Measurement, cfg ChargeLimitConfig, current string) string {
+	onBattery := m.State == "discharging"
+	switch {
+	case cfg.Ceiling > 0 && !onBattery && m.Percentage >= cfg.Ceiling:
+		return chargeEventCeiling
+	case cfg.Floor > 0 && onBattery && m.Percentage <= cfg.Floor:
+		return chargeEventFloor
+	case current == chargeEventCeiling && !onBattery && m.Percentage > cfg.Ceiling-chargeLimitHysteresis:
+		return current
+	case current == chargeEventFloor && onBattery && m.Percentage < cfg.Floor+chargeLimitHysteresis:
+		return current
+	}
+	return ""
+}
+
+// chargeLimitMessage возвращает текст сообщения о событии
+func chargeLimitMessage(event string, m Measurement, cfg ChargeLimitConfig) string {
+	if event == chargeEventCeiling {
+		return T("charge_limit.ceiling", m.Percentage, cfg.Ceiling)
+	}
+	return T("charge_limit.floor", m.Percentage, cfg.Floor)
+}
+
+// updateChargeLimit сообщает о входе в зону потолка или пола один раз
+func (dc *DataCollector) updateChargeLimit(m Measurement) {
+	config := getConfig()
+	cfg := config.ChargeLimit
+	if !cfg.Enabled {
+		dc.chargeLimitZone = ""
+		return
+	}
+	zone := chargeLimitZone(m, cfg, dc.chargeLimitZone)
+	if zone != "" && zone != dc.chargeLimitZone {
+		message := chargeLimitMessage(zone, m, cfg)
+		logInfof("🔌 %s", message)
+		if cfg.Notify {
+			sendAlert(alertInfo, T("charge_limit.alert"), message)
+		}
+		vars := map[string]string{"THRESHOLD": zone, "MESSAGE": message}
+		// Прежний charge_limit.hook по-прежнему получает зону в BATMON_EVENT
+		runHooks([]string{cfg.Hook}, zone, m, vars)
+		runHooks(config.Hooks.ChargeThreshold, hookChargeThreshold, m, vars)
+	}
+	dc.chargeLimitZone = zone
+}
+
+// fullChargeTime считает время на 100% от сети и его долю от наблюдаемого
+// времени. Интервал относится к состоянию в его начале; пропуски дольше часа
+// (сон, выключение) не учитываются, как в hotChargingByWeek.
+func fullChargeTime(ms []Measurement) (time.Duration, float64) {
+	var full, total time.Duration
+	for i := 1; i < len(ms); i++ {
+		prev := ms[i-1]
+		dt := parseStoredTime(ms[i].Timestamp).Sub(parseStoredTime(prev.Timestamp))
+		if dt <= 0 || dt > time.Hour {
+			continue
+		}
+		total += dt
+		if prev.Percentage >= chargeFullZone && prev.State != "discharging" {
+			full += dt
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return full, float64(full) / float64(total) * 100
+}
+
+// fullChargeRecommendation советует ограничить заряд, если батарея большую
+// часть времени держится на 100%
+func fullChargeRecommendation(share float64) string {
+	if share < fullZoneAdviceShare {
+		return ""
+	}
+	return T("rec.full_charge", share, defaultChargeCeiling)
+}
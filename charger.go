@@ -0,0 +1,223 @@
+// charger.go
+//
+// Сведения о подключенном адаптере питания: мощность, семейство и
+// производитель из AdapterDetails в ioreg. Каждое подключение (или смена)
+// адаптера записывается в БД, чтобы скорость зарядки можно было сравнить
+// между адаптерами и заметить слабые или неоригинальные.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	chargerMinWatts    = 30 // адаптеры слабее не успевают заряжать MacBook под нагрузкой, Вт
+	chargerSlowRate    = 15 // скорость зарядки до 80% ниже этой считается медленной, %/ч
+	chargerFastPortion = 80 // выше этого заряда зарядка замедляется штатно, %
+)
+
+// chargersSchema – подключения адаптеров питания
+const chargersSchema = `
+CREATE TABLE IF NOT EXISTS charger_connections (
+	connected_at TEXT PRIMARY KEY,
+	watts INTEGER NOT NULL DEFAULT 0,
+	voltage INTEGER NOT NULL DEFAULT 0,
+	current INTEGER NOT NULL DEFAULT 0,
+	family TEXT NOT NULL DEFAULT '',
+	name TEXT NOT NULL DEFAULT '',
+	manufacturer TEXT NOT NULL DEFAULT '',
+	serial TEXT NOT NULL DEFAULT '',
+	official INTEGER NOT NULL DEFAULT 0
+);`
+
+// AdapterInfo – подключенный адаптер питания
+type AdapterInfo struct {
+	ConnectedAt  string `db:"connected_at"` // RFC3339 UTC; пусто, пока не записан
+	Watts        int    `db:"watts"`
+	Voltage      int    `db:"voltage"` // мВ
+	Current      int    `db:"current"` // мА
+	Family       string `db:"family"`  // код семейства, hex
+	Name         string `db:"name"`
+	Manufacturer string `db:"manufacturer"`
+	Serial       string `db:"serial"`
+	Official     bool   `db:"official"` // оригинальный адаптер Apple
+}
+
+// AdapterReader – источник, который знает подключенный адаптер питания.
+// Без подключенного адаптера Adapter возвращает nil.
+type AdapterReader interface {
+	Adapter() (*AdapterInfo, error)
+}
+
+// Label возвращает название адаптера для отчетов
+func (a AdapterInfo) Label() string {
+	name := a.Name
+	if name == "" {
+		name = T("charger.default_name")
+	}
+	if a.Watts > 0 && !strings.Contains(name, fmt.Sprintf("%dW", a.Watts)) {
+		name = T("charger.watts", name, a.Watts)
+	}
+	if !a.Official {
+		name += T("charger.third_party")
+	}
+	return name
+}
+
+// key идентифицирует адаптер: смена ключа – это смена адаптера
+func (a AdapterInfo) key() string {
+	return fmt.Sprintf("%s|%s|%s|%d", a.Serial, a.Name, a.Family, a.Watts)
+}
+
+// isOfficialAdapter определяет оригинальный адаптер Apple по производителю и названию
+func isOfficialAdapter(manufacturer, name string) bool {
+	return strings.HasPrefix(manufacturer, "Apple") ||
+		(manufacturer == "" && strings.Contains(name, "Power Adapter"))
+}
+
+// getLastCharger возвращает последний записанный адаптер или nil
+func getLastCharger(db *sqlx.DB) (*AdapterInfo, error) {
+	var a AdapterInfo
+	err := db.Get(&a, `SELECT * FROM charger_connections ORDER BY connected_at DESC LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("charger.err.read"), err)
+	}
+	return &a, nil
+}
+
+// getChargers возвращает подключения адаптеров по возрастанию времени
+func getChargers(db *sqlx.DB) ([]AdapterInfo, error) {
+	var chargers []AdapterInfo
+	if err := db.Select(&chargers, `SELECT * FROM charger_connections ORDER BY connected_at`); err != nil {
+		return nil, fmt.Errorf(T("charger.err.list"), err)
+	}
+	return chargers, nil
+}
+
+// insertCharger записывает подключение адаптера
+func insertCharger(db *sqlx.DB, a AdapterInfo) error {
+	_, err := db.NamedExec(`INSERT OR REPLACE INTO charger_connections
+		(connected_at, watts, voltage, current, family, name, manufacturer, serial, official)
+		VALUES (:connected_at, :watts, :voltage, :current, :family, :name, :manufacturer, :serial, :official)`, a)
+	if err != nil {
+		return fmt.Errorf(T("charger.err.write"), err)
+	}
+	return nil
+}
+
+// chargerTracker записывает подключения адаптера: новое подключение после
+// работы от батареи или смену адаптера без отключения
+type chargerTracker struct {
+	loaded  bool
+	lastKey string // пусто – адаптер не подключен
+}
+
+// track сверяет текущий адаптер с последним записанным
+func (c *chargerTracker) track(db *sqlx.DB, a *AdapterInfo, timestamp string) error {
+	if !c.loaded {
+		// После перезапуска не дублируем запись об уже подключенном адаптере
+		c.loaded = true
+		if last, err := getLastCharger(db); err != nil {
+			return err
+		} else if last != nil && a != nil {
+			c.lastKey = last.key()
+		}
+	}
+	if a == nil {
+		c.lastKey = ""
+		return nil
+	}
+	if a.key() == c.lastKey {
+		return nil
+	}
+	c.lastKey = a.key()
+	a.ConnectedAt = timestamp
+	return insertCharger(db, *a)
+}
+
+// ChargerStats – скорость зарядки с одним адаптером
+type ChargerStats struct {
+	Adapter AdapterInfo
+	Rate    float64 // средняя скорость зарядки до 80%, %/ч
+	Hours   float64 // часов зарядки до 80%
+}
+
+// chargerStats относит интервалы зарядки к последнему подключенному перед
+// ними адаптеру. Выше 80% зарядка штатно замедляется и не учитывается.
+func chargerStats(ms []Measurement, chargers []AdapterInfo) []ChargerStats {
+	if len(chargers) == 0 {
+		return nil
+	}
+	charged := make([]float64, len(chargers))
+	hours := make([]float64, len(chargers))
+	for i := 1; i < len(ms); i++ {
+		prev, curr := ms[i-1], ms[i]
+		if prev.State != "charging" || curr.State != "charging" || prev.Percentage >= chargerFastPortion {
+			continue
+		}
+		t1, err1 := time.Parse(time.RFC3339, prev.Timestamp)
+		t2, err2 := time.Parse(time.RFC3339, curr.Timestamp)
+		dt := t2.Sub(t1)
+		if err1 != nil || err2 != nil || dt <= 0 || dt > time.Hour || curr.Percentage < prev.Percentage {
+			continue
+		}
+		idx := -1
+		for j, c := range chargers {
+			if c.ConnectedAt <= prev.Timestamp {
+				idx = j
+			}
+		}
+		if idx < 0 {
+			continue
+		}
+		charged[idx] += float64(curr.Percentage - prev.Percentage)
+		hours[idx] += dt.Hours()
+	}
+
+	// Один и тот же адаптер мог подключаться много раз – объединяем по ключу
+	var result []ChargerStats
+	byKey := make(map[string]int)
+	for i, c := range chargers {
+		pos, ok := byKey[c.key()]
+		if !ok {
+			pos = len(result)
+			byKey[c.key()] = pos
+			result = append(result, ChargerStats{Adapter: c})
+		}
+		r := &result[pos]
+		if total := r.Hours + hours[i]; total > 0 {
+			r.Rate = (r.Rate*r.Hours + charged[i]) / total
+			r.Hours = total
+		}
+		r.Adapter = c // последнее подключение
+	}
+	return result
+}
+
+// chargerRecommendations предупреждает о слабых, медленных и неоригинальных адаптерах
+func chargerRecommendations(stats []ChargerStats) []string {
+	var recs []string
+	for _, s := range stats {
+		a := s.Adapter
+		switch {
+		case a.Watts > 0 && a.Watts < chargerMinWatts:
+			recs = append(recs, T("rec.charger.weak", a.Label(), chargerMinWatts))
+		case s.Hours >= 0.5 && s.Rate < chargerSlowRate:
+			recs = append(recs, T("rec.charger.slow", a.Label(), s.Rate, chargerFastPortion))
+		}
+		if !a.Official && a.Name != "" {
+			recs = append(recs, T("rec.charger.unofficial", a.Name))
+		}
+	}
+	return recs
+}
@@ -0,0 +1,251 @@
+// charging.go
+//
+// Кривая зарядки: для каждой сессии зарядки – время от 20 до 80% (быстрая
+// фаза), от 80 до 100% (дозаряд малым током) и средняя мощность. Медленная
+// быстрая фаза говорит о слабом адаптере или кабеле, затянутый дозаряд – о
+// батарее, которая плохо принимает заряд (или об оптимизированной зарядке,
+// которая держит 80%).
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	chargeCurveLow    = 20                  // начало быстрой фазы, %
+	chargeTrickleMax  = 90 * time.Minute    // дозаряд 80→100% дольше этого считается затянутым
+	chargeCurvesShown = 20                  // сессий зарядки в отчетах
+	chargeCurveWindow = 14 * 24 * time.Hour // период кривых зарядки в отчете без явного периода
+	chargeFastSlow    = 4 * time.Hour       // 20→80% дольше этого – медленная зарядка (60% при chargerSlowRate %/ч)
+)
+
+// ChargeCurve – кривая одной сессии зарядки
+type ChargeCurve struct {
+	Start        time.Time
+	End          time.Time
+	StartPercent int
+	EndPercent   int
+	Fast         time.Duration // от 20 до 80%; 0 – сессия не прошла этот участок целиком
+	Trickle      time.Duration // от 80 до 100%; 0 – сессия не прошла этот участок целиком
+	AvgWatts     float64       // средняя мощность зарядки, Вт; 0 – нет данных о токе
+}
+
+// Slow сообщает, что быстрая фаза шла медленнее обычного
+func (c ChargeCurve) Slow() bool {
+	return c.Fast > chargeFastSlow
+}
+
+// LongTrickle сообщает, что дозаряд от 80 до 100% затянулся
+func (c ChargeCurve) LongTrickle() bool {
+	return c.Trickle > chargeTrickleMax
+}
+
+// Notes возвращает замечания к сессии для отчетов
+func (c ChargeCurve) Notes() string {
+	switch {
+	case c.Slow() && c.LongTrickle():
+		return T("report.charging.slow") + ", " + T("report.charging.long_trickle")
+	case c.Slow():
+		return T("report.charging.slow")
+	case c.LongTrickle():
+		return T("report.charging.long_trickle")
+	}
+	return ""
+}
+
+// detectChargeCurves делит измерения на сессии зарядки и считает их кривые.
+// Сессия – измерения в состоянии charging (и finishing – дозаряд на macOS)
+// без пропусков дольше sessionMaxGap. Первое измерение после зарядки с
+// зарядом 100% (состояние charged) завершает кривую.
+func detectChargeCurves(ms []Measurement) []ChargeCurve {
+	type point struct {
+		at    time.Time
+		pct   int
+		power int
+	}
+	var points []point
+	var curves []ChargeCurve
+
+	finish := func() {
+		if len(points) < 2 {
+			points = points[:0]
+			return
+		}
+		c := ChargeCurve{
+			Start:        points[0].at,
+			End:          points[len(points)-1].at,
+			StartPercent: points[0].pct,
+			EndPercent:   points[len(points)-1].pct,
+		}
+		reached := func(pct int) (time.Time, bool) {
+			for _, p := range points {
+				if p.pct >= pct {
+					return p.at, true
+				}
+			}
+			return time.Time{}, false
+		}
+		at80, ok80 := reached(chargerFastPortion)
+		if at20, ok := reached(chargeCurveLow); ok && ok80 && c.StartPercent <= chargeCurveLow {
+			c.Fast = at80.Sub(at20)
+		}
+		if at100, ok := reached(100); ok && ok80 && c.StartPercent <= chargerFastPortion {
+			c.Trickle = at100.Sub(at80)
+		}
+		var watts float64
+		var samples int
+		for _, p := range points {
+			if p.power > 0 {
+				watts += float64(p.power) / 1000
+				samples++
+			}
+		}
+		if samples > 0 {
+			c.AvgWatts = watts / float64(samples)
+		}
+		curves = append(curves, c)
+		points = points[:0]
+	}
+
+	for _, m := range ms {
+		at := parseStoredTime(m.Timestamp)
+		if at.IsZero() {
+			continue
+		}
+		if len(points) > 0 && at.Sub(points[len(points)-1].at) > sessionMaxGap {
+			finish()
+		}
+		if sessionKind(m.State) != sessionCharge && !strings.EqualFold(m.State, "finishing") {
+			if len(points) > 0 && m.Percentage >= 100 {
+				points = append(points, point{at: at, pct: m.Percentage})
+			}
+			finish()
+			continue
+		}
+		points = append(points, point{at: at, pct: m.Percentage, power: m.Power})
+	}
+	finish()
+	return curves
+}
+
+// formatOptionalDuration форматирует длительность, 0 – прочерк
+func formatOptionalDuration(d time.Duration) string {
+	if d <= 0 {
+		return "—"
+	}
+	return formatDuration(d)
+}
+
+// formatOptionalWatts форматирует мощность, 0 – прочерк
+func formatOptionalWatts(w float64) string {
+	if w <= 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%.0f", w)
+}
+
+// ChargingAnalysis – кривые зарядки за период отчета, новые первыми
+type ChargingAnalysis struct {
+	Curves []ChargeCurve
+}
+
+// loadChargingAnalysis считает кривые зарядки за период отчета. Без явного
+// периода отчет видит лишь последние измерения, поэтому кривые строятся за
+// chargeCurveWindow; читаются только нужные для них столбцы.
+func loadChargingAnalysis(db *sqlx.DB, rng ReportRange, ms []Measurement, now time.Time) (ChargingAnalysis, error) {
+	if rng.IsZero() {
+		ms = nil
+		err := db.Select(&ms, `SELECT timestamp, state, percentage, power FROM measurements
+			WHERE timestamp >= ? ORDER BY timestamp`, now.Add(-chargeCurveWindow).UTC().Format(time.RFC3339))
+		if err != nil {
+			return ChargingAnalysis{}, fmt.Errorf(T("charging.err.curves"), err)
+		}
+	}
+	return analyzeCharging(ms), nil
+}
+
+// analyzeCharging считает кривые зарядки и оставляет последние chargeCurvesShown
+func analyzeCharging(ms []Measurement) ChargingAnalysis {
+	curves := detectChargeCurves(ms)
+	sort.Slice(curves, func(i, j int) bool { return curves[i].Start.After(curves[j].Start) })
+	if len(curves) > chargeCurvesShown {
+		curves = curves[:chargeCurvesShown]
+	}
+	return ChargingAnalysis{Curves: curves}
+}
+
+// averages возвращает средние 20→80%, 80→100% и мощность по сессиям, где они известны
+func (a ChargingAnalysis) averages() (fast, trickle time.Duration, watts float64) {
+	var nFast, nTrickle, nWatts int
+	for _, c := range a.Curves {
+		if c.Fast > 0 {
+			fast += c.Fast
+			nFast++
+		}
+		if c.Trickle > 0 {
+			trickle += c.Trickle
+			nTrickle++
+		}
+		if c.AvgWatts > 0 {
+			watts += c.AvgWatts
+			nWatts++
+		}
+	}
+	if nFast > 0 {
+		fast /= time.Duration(nFast)
+	}
+	if nTrickle > 0 {
+		trickle /= time.Duration(nTrickle)
+	}
+	if nWatts > 0 {
+		watts /= float64(nWatts)
+	}
+	return fast, trickle, watts
+}
+
+// Summary возвращает средние показатели зарядки; пустая строка – сессий зарядки нет
+func (a ChargingAnalysis) Summary() string {
+	if len(a.Curves) == 0 {
+		return ""
+	}
+	fast, trickle, watts := a.averages()
+	summary := T("report.charging.summary", len(a.Curves), formatOptionalDuration(fast), formatOptionalDuration(trickle))
+	if watts > 0 {
+		summary += ", " + T("report.charging.watts_avg", watts)
+	}
+	return summary
+}
+
+// Recommendations предупреждает, если медленная зарядка или затянутый
+// дозаряд повторяются в большинстве сессий, где их можно оценить
+func (a ChargingAnalysis) Recommendations() []string {
+	var slow, fastKnown, long, trickleKnown int
+	for _, c := range a.Curves {
+		if c.Fast > 0 {
+			fastKnown++
+			if c.Slow() {
+				slow++
+			}
+		}
+		if c.Trickle > 0 {
+			trickleKnown++
+			if c.LongTrickle() {
+				long++
+			}
+		}
+	}
+	var recs []string
+	if fastKnown >= 2 && slow*2 > fastKnown {
+		recs = append(recs, T("rec.charging.slow", chargeCurveLow, chargerFastPortion, formatDuration(chargeFastSlow), slow, fastKnown))
+	}
+	if trickleKnown >= 2 && long*2 > trickleKnown {
+		recs = append(recs, T("rec.charging.long_trickle", chargerFastPortion, formatDuration(chargeTrickleMax), long, trickleKnown))
+	}
+	return recs
+}
@@ -0,0 +1,500 @@
+// chart_image.go
+//
+// Графики в файл: `batmon chart --metric percentage --out chart.png`
+// рисует историю заряда, ёмкости, температуры или мощности в PNG или SVG
+// для документов и тикетов. Рендер на стандартной библиотеке: SVG – с
+// подписями, PNG – с подписями осей встроенным растровым шрифтом (цифры и
+// знаки, заголовки графиков в PNG не выводятся). Заметки batmon note
+// отмечаются на графиках полосами и линиями.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	chartDefaultWidth  = 1200
+	chartDefaultHeight = 400 // высота одной панели
+	chartDefaultPeriod = 7 * 24 * time.Hour
+	chartMarginLeft    = 70
+	chartMarginRight   = 20
+	chartMarginTop     = 36
+	chartMarginBottom  = 36
+	chartYTicks        = 5
+	chartXTicks        = 6
+)
+
+// noteColor – цвет заметок на графике
+var noteColor = color.RGBA{130, 80, 223, 255}
+
+// chartMetric – метрика для графика
+type chartMetric struct {
+	name  string
+	title string
+	unit  string
+	color color.RGBA
+	value func(Measurement) (float64, bool) // false – значения нет
+}
+
+// chartMetrics – доступные метрики в порядке вывода в справке
+var chartMetrics = []chartMetric{
+	{"percentage", "chart.metric.percentage", "chart.unit.percent", color.RGBA{46, 160, 67, 255}, func(m Measurement) (float64, bool) {
+		return float64(m.Percentage), true
+	}},
+	{"capacity", "chart.metric.capacity", "chart.unit.mah", color.RGBA{31, 111, 235, 255}, func(m Measurement) (float64, bool) {
+		return float64(m.FullChargeCap), m.FullChargeCap > 0
+	}},
+	{"temperature", "chart.metric.temperature", "chart.unit.celsius", color.RGBA{218, 54, 51, 255}, func(m Measurement) (float64, bool) {
+		return float64(m.Temperature), m.Temperature > 0
+	}},
+	{"power", "chart.metric.power", "chart.unit.watts", color.RGBA{191, 135, 0, 255}, func(m Measurement) (float64, bool) {
+		return measurementWatts(m)
+	}},
+}
+
+// findChartMetric ищет метрику по имени
+func findChartMetric(name string) (chartMetric, bool) {
+	for _, m := range chartMetrics {
+		if m.name == name {
+			return m, true
+		}
+	}
+	return chartMetric{}, false
+}
+
+// chartMetricNames возвращает имена метрик через запятую
+func chartMetricNames() string {
+	names := make([]string, len(chartMetrics))
+	for i, m := range chartMetrics {
+		names[i] = m.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// chartPoint – точка ряда; gap – перед точкой разрыв линии (сон, нет значения)
+type chartPoint struct {
+	at    time.Time
+	value float64
+	gap   bool
+}
+
+// chartPanel – график одной метрики
+type chartPanel struct {
+	metric   chartMetric
+	points   []chartPoint
+	min, max float64
+	step     float64 // шаг делений по оси значений
+	from, to time.Time
+}
+
+// buildChartPanel собирает ряд метрики. Пропуск данных дольше sessionMaxGap
+// или отсутствие значения разрывают линию.
+func buildChartPanel(metric chartMetric, ms []Measurement) (chartPanel, error) {
+	panel := chartPanel{metric: metric, min: math.Inf(1), max: math.Inf(-1)}
+	var last time.Time
+	gap := false
+	for _, m := range ms {
+		at := parseStoredTime(m.Timestamp)
+		if at.IsZero() {
+			continue
+		}
+		v, ok := metric.value(m)
+		if !ok {
+			gap = true
+			continue
+		}
+		panel.points = append(panel.points, chartPoint{at: at, value: v, gap: gap || (!last.IsZero() && at.Sub(last) > sessionMaxGap)})
+		panel.min = math.Min(panel.min, v)
+		panel.max = math.Max(panel.max, v)
+		last = at
+		gap = false
+	}
+	if len(panel.points) < 2 {
+		return panel, fmt.Errorf(T("chart.err.not_enough"), T(metric.title))
+	}
+	panel.from, panel.to = panel.points[0].at, panel.points[len(panel.points)-1].at
+	panel.min, panel.max, panel.step = niceRange(panel.min, panel.max)
+	return panel, nil
+}
+
+// niceRange расширяет диапазон до круглых границ и возвращает шаг делений из ряда 1-2-5
+func niceRange(lo, hi float64) (float64, float64, float64) {
+	if hi-lo < 1e-9 {
+		lo, hi = lo-1, hi+1
+	}
+	step := niceStep((hi - lo) / chartYTicks)
+	return math.Floor(lo/step) * step, math.Ceil(hi/step) * step, step
+}
+
+// yTicks возвращает значения делений оси
+func (p chartPanel) yTicks() []float64 {
+	var ticks []float64
+	for v := p.min; v <= p.max+p.step/2; v += p.step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+// niceStep округляет шаг делений до 1, 2 или 5 × 10^n
+func niceStep(raw float64) float64 {
+	exp := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / exp; {
+	case f <= 1:
+		return exp
+	case f <= 2:
+		return 2 * exp
+	case f <= 5:
+		return 5 * exp
+	}
+	return 10 * exp
+}
+
+// chartTimeLayout выбирает формат подписей времени по длине периода
+func chartTimeLayout(from, to time.Time) string {
+	if to.Sub(from) <= 36*time.Hour {
+		return "15:04"
+	}
+	return "02.01"
+}
+
+// formatChartValue печатает значение деления без лишних нулей
+func formatChartValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+// chartGeometry переводит время и значение в координаты панели
+type chartGeometry struct {
+	left, top, width, height int
+	panel                    chartPanel
+}
+
+func (g chartGeometry) x(t time.Time) float64 {
+	span := g.panel.to.Sub(g.panel.from).Seconds()
+	if span <= 0 {
+		return float64(g.left)
+	}
+	return float64(g.left) + t.Sub(g.panel.from).Seconds()/span*float64(g.width)
+}
+
+func (g chartGeometry) y(v float64) float64 {
+	return float64(g.top) + (g.panel.max-v)/(g.panel.max-g.panel.min)*float64(g.height)
+}
+
+// panelGeometry возвращает область построения панели с номером i
+func panelGeometry(panel chartPanel, i, width, height int) chartGeometry {
+	return chartGeometry{
+		left:   chartMarginLeft,
+		top:    i*height + chartMarginTop,
+		width:  width - chartMarginLeft - chartMarginRight,
+		height: height - chartMarginTop - chartMarginBottom,
+		panel:  panel,
+	}
+}
+
+// noteSpan возвращает границы заметки на панели по оси X; false – заметка вне периода
+func (g chartGeometry) noteSpan(n Note) (x0, x1 float64, ok bool) {
+	start, end := n.Start(), n.End()
+	if end.Before(g.panel.from) || start.After(g.panel.to) {
+		return 0, 0, false
+	}
+	x0 = math.Max(g.x(start), float64(g.left))
+	x1 = math.Min(g.x(end), float64(g.left+g.width))
+	return x0, x1, true
+}
+
+// writeChartSVG рисует панели друг под другом в SVG; заметки – полосами
+// (промежуток) или линиями (момент) с подписью
+func writeChartSVG(w io.Writer, panels []chartPanel, notes []Note, width, height int) error {
+	bw := bufio.NewWriter(w)
+	total := height * len(panels)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		width, total, width, total)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, total)
+	for i, panel := range panels {
+		g := panelGeometry(panel, i, width, height)
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="15" font-weight="bold">%s, %s</text>`+"\n",
+			g.left, g.top-14, svgEscape(T(panel.metric.title)), svgEscape(T(panel.metric.unit)))
+
+		for _, v := range panel.yTicks() {
+			y := g.y(v)
+			fmt.Fprintf(bw, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#e5e5e5"/>`+"\n", g.left, y, g.left+g.width, y)
+			fmt.Fprintf(bw, `<text x="%d" y="%.1f" text-anchor="end" fill="#555">%s</text>`+"\n", g.left-6, y+4, formatChartValue(v))
+		}
+		layout := chartTimeLayout(panel.from, panel.to)
+		for k := 0; k <= chartXTicks; k++ {
+			t := panel.from.Add(time.Duration(float64(panel.to.Sub(panel.from)) * float64(k) / chartXTicks))
+			x := g.x(t)
+			fmt.Fprintf(bw, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#e5e5e5"/>`+"\n", x, g.top, x, g.top+g.height)
+			anchor := "middle"
+			if k == chartXTicks {
+				anchor = "end"
+			}
+			fmt.Fprintf(bw, `<text x="%.1f" y="%d" text-anchor="%s" fill="#555">%s</text>`+"\n", x, g.top+g.height+18, anchor, t.Local().Format(layout))
+		}
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#999"/>`+"\n", g.left, g.top, g.width, g.height)
+
+		for k, n := range notes {
+			x0, x1, ok := g.noteSpan(n)
+			if !ok {
+				continue
+			}
+			if x1-x0 >= 1 {
+				fmt.Fprintf(bw, `<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="rgb(%d,%d,%d)" fill-opacity="0.12"/>`+"\n",
+					x0, g.top, x1-x0, g.height, noteColor.R, noteColor.G, noteColor.B)
+			}
+			fmt.Fprintf(bw, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="rgb(%d,%d,%d)" stroke-dasharray="4 3"/>`+"\n",
+				x0, g.top, x0, g.top+g.height, noteColor.R, noteColor.G, noteColor.B)
+			// Подписи соседних заметок разносятся по высоте
+			fmt.Fprintf(bw, `<text x="%.1f" y="%d" fill="rgb(%d,%d,%d)"><title>%s</title>%s %s</text>`+"\n",
+				x0+3, g.top+14+(k%3)*14, noteColor.R, noteColor.G, noteColor.B, svgEscape(n.String()), noteGlyph, svgEscape(n.Text))
+		}
+
+		c := panel.metric.color
+		fmt.Fprintf(bw, `<path fill="none" stroke="rgb(%d,%d,%d)" stroke-width="2" d="`, c.R, c.G, c.B)
+		for j, p := range panel.points {
+			cmd := "L"
+			if j == 0 || p.gap {
+				cmd = "M"
+			}
+			fmt.Fprintf(bw, "%s%.1f %.1f ", cmd, g.x(p.at), g.y(p.value))
+		}
+		bw.WriteString(`"/>` + "\n")
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// svgEscape экранирует текст для SVG
+func svgEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// writeChartPNG рисует панели друг под другом в PNG; заметки – полосами и
+// линиями без подписей (в растровом шрифте нет букв)
+func writeChartPNG(w io.Writer, panels []chartPanel, notes []Note, width, height int) error {
+	img := image.NewRGBA(image.Rect(0, 0, width, height*len(panels)))
+	fillRect(img, img.Bounds(), color.RGBA{255, 255, 255, 255})
+	grid := color.RGBA{229, 229, 229, 255}
+	frame := color.RGBA{153, 153, 153, 255}
+	label := color.RGBA{85, 85, 85, 255}
+
+	for i, panel := range panels {
+		g := panelGeometry(panel, i, width, height)
+		for _, v := range panel.yTicks() {
+			y := g.y(v)
+			drawLine(img, float64(g.left), y, float64(g.left+g.width), y, grid, 1)
+			text := formatChartValue(v)
+			drawGlyphs(img, g.left-8-glyphTextWidth(text), int(y)-glyphHeight/2, text, label)
+		}
+		layout := chartTimeLayout(panel.from, panel.to)
+		for k := 0; k <= chartXTicks; k++ {
+			t := panel.from.Add(time.Duration(float64(panel.to.Sub(panel.from)) * float64(k) / chartXTicks))
+			x := g.x(t)
+			drawLine(img, x, float64(g.top), x, float64(g.top+g.height), grid, 1)
+			text := t.Local().Format(layout)
+			tx := min(int(x)-glyphTextWidth(text)/2, width-glyphTextWidth(text)-2)
+			drawGlyphs(img, tx, g.top+g.height+8, text, label)
+		}
+		top, bottom := float64(g.top), float64(g.top+g.height)
+		left, right := float64(g.left), float64(g.left+g.width)
+		drawLine(img, left, top, right, top, frame, 1)
+		drawLine(img, left, bottom, right, bottom, frame, 1)
+		drawLine(img, left, top, left, bottom, frame, 1)
+		drawLine(img, right, top, right, bottom, frame, 1)
+
+		for _, n := range notes {
+			x0, x1, ok := g.noteSpan(n)
+			if !ok {
+				continue
+			}
+			if x1-x0 >= 1 {
+				shadeRect(img, image.Rect(int(x0), g.top+1, int(x1), g.top+g.height), noteColor)
+			}
+			drawLine(img, x0, top, x0, bottom, noteColor, 1)
+		}
+
+		for j := 1; j < len(panel.points); j++ {
+			p, q := panel.points[j-1], panel.points[j]
+			if q.gap {
+				continue
+			}
+			drawLine(img, g.x(p.at), g.y(p.value), g.x(q.at), g.y(q.value), panel.metric.color, 2)
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// shadeRect подкрашивает прямоугольник полупрозрачным цветом
+func shadeRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			p := img.RGBAAt(x, y)
+			img.SetRGBA(x, y, color.RGBA{blend(p.R, c.R), blend(p.G, c.G), blend(p.B, c.B), 255})
+		}
+	}
+}
+
+// blend смешивает канал фона с каналом цвета в пропорции 88:12
+func blend(bg, fg uint8) uint8 {
+	return uint8((int(bg)*88 + int(fg)*12) / 100)
+}
+
+// fillRect заливает прямоугольник цветом
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawLine рисует отрезок заданной толщины шагами по длинной оси
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA, thickness int) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		x := int(math.Round(x0 + (x1-x0)*t))
+		y := int(math.Round(y0 + (y1-y0)*t))
+		for dy := 0; dy < thickness; dy++ {
+			for dx := 0; dx < thickness; dx++ {
+				if (image.Point{x + dx, y + dy}).In(img.Bounds()) {
+					img.SetRGBA(x+dx, y+dy, c)
+				}
+			}
+		}
+	}
+}
+
+// Растровый шрифт 3×5 для подписей осей PNG, масштаб glyphScale
+const (
+	glyphScale  = 2
+	glyphHeight = 5 * glyphScale
+)
+
+var glyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'-': {"...", "...", "###", "...", "..."},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+	' ': {"...", "...", "...", "...", "..."},
+}
+
+// glyphTextWidth возвращает ширину строки в пикселях
+func glyphTextWidth(s string) int {
+	return len([]rune(s)) * 4 * glyphScale
+}
+
+// drawGlyphs печатает строку растровым шрифтом; неизвестные знаки пропускаются
+func drawGlyphs(img *image.RGBA, x, y int, s string, c color.RGBA) {
+	for _, r := range s {
+		if g, ok := glyphs[r]; ok {
+			for row, line := range g {
+				for col, px := range line {
+					if px == '#' {
+						fillRect(img, image.Rect(x+col*glyphScale, y+row*glyphScale,
+							x+(col+1)*glyphScale, y+(row+1)*glyphScale).Intersect(img.Bounds()), c)
+					}
+				}
+			}
+		}
+		x += 4 * glyphScale
+	}
+}
+
+// runChartCommand рисует графики метрик за период в PNG или SVG
+func runChartCommand(args []string) error {
+	fs := newCommandFlags("chart")
+	metricList := fs.String("metric", "percentage", localized("flag.chart.metric", "метрики через запятую: ")+chartMetricNames())
+	out := fs.String("out", "", localized("flag.chart.out", "файл графика: .png или .svg"))
+	width := fs.Int("width", chartDefaultWidth, localized("flag.chart.width", "ширина, пикселей"))
+	height := fs.Int("height", chartDefaultHeight, localized("flag.chart.height", "высота одного графика, пикселей"))
+	reportRange := addRangeFlags(fs)
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(*out))
+	if *out == "" || (ext != ".png" && ext != ".svg") {
+		fmt.Fprintln(os.Stderr, T("chart.cli.no_out"))
+		return errUsage
+	}
+	if *width < 300 || *height < 150 {
+		fmt.Fprintln(os.Stderr, T("chart.cli.too_small"))
+		return errUsage
+	}
+	var metrics []chartMetric
+	for _, name := range strings.Split(*metricList, ",") {
+		metric, ok := findChartMetric(strings.TrimSpace(name))
+		if !ok {
+			fmt.Fprintln(os.Stderr, T("chart.cli.unknown_metric", name, chartMetricNames()))
+			return errUsage
+		}
+		metrics = append(metrics, metric)
+	}
+	rng, err := reportRange()
+	if err != nil {
+		return err
+	}
+	if rng.IsZero() {
+		rng.From = time.Now().Add(-chartDefaultPeriod)
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+	ms, err := loadReportMeasurements(db, rng, reportLastN)
+	if err != nil {
+		return fmt.Errorf(T("err.data"), err)
+	}
+	// Точек больше, чем пикселей по ширине, на графике не видно
+	ms = downsampleMeasurements(ms, *width)
+
+	panels := make([]chartPanel, 0, len(metrics))
+	for _, metric := range metrics {
+		panel, err := buildChartPanel(metric, ms)
+		if err != nil {
+			return err
+		}
+		panels = append(panels, panel)
+	}
+
+	notes, err := getNotes(db, ReportRange{From: panels[0].from, To: panels[0].to})
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	write := func(w io.Writer) error { return writeChartSVG(w, panels, notes, *width, *height) }
+	if ext == ".png" {
+		write = func(w io.Writer) error { return writeChartPNG(w, panels, notes, *width, *height) }
+	}
+	if err := writeFileAtomic(*out, write, nil); err != nil {
+		return fmt.Errorf(T("chart.err.write"), err)
+	}
+	fmt.Println(T("chart.cli.saved", *out, rng.Label()))
+	return nil
+}
@@ -0,0 +1,211 @@
+// chart_window.go
+//
+// Окно истории для графиков дашборда (30м/2ч/6ч/24ч): короткие окна
+// берутся из буфера памяти, длинные – из БД с прореживанием. Клавиши +/-
+// меняют масштаб окна, h/l сдвигают его в прошлое и обратно.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// chartWindows – доступные окна графиков дашборда, переключаются клавишей w
+var chartWindows = []time.Duration{
+	30 * time.Minute,
+	2 * time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+const (
+	defaultChartWindow = 30 * time.Minute
+	chartMaxPoints     = 240 // больше точек экран всё равно не покажет
+	minChartSpan       = 5 * time.Minute
+	maxChartSpan       = 30 * 24 * time.Hour
+)
+
+// formatChartWindow возвращает короткую подпись окна: "30м", "2ч", "4д"
+func formatChartWindow(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return T("chart.window.minutes", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return T("chart.window.hours", int(d.Hours()))
+	}
+	return T("chart.window.days", int(d.Hours()/24))
+}
+
+// chartWindowConfigValue возвращает окно в формате конфига: "30m", "2h"
+func chartWindowConfigValue(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh", int(d.Hours()))
+}
+
+// parseChartWindow разбирает окно из конфига ("30m", "2h" ...),
+// неизвестные значения заменяются окном по умолчанию.
+func parseChartWindow(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return defaultChartWindow
+	}
+	for _, w := range chartWindows {
+		if w == d {
+			return d
+		}
+	}
+	return defaultChartWindow
+}
+
+// nextChartWindow возвращает следующее окно по кругу
+func nextChartWindow(current time.Duration) time.Duration {
+	for i, w := range chartWindows {
+		if w == current {
+			return chartWindows[(i+1)%len(chartWindows)]
+		}
+	}
+	return chartWindows[0]
+}
+
+// ChartView – видимый участок истории на графиках: длительность окна и его
+// конец. Нулевой конец означает «сейчас» – окно следует за новыми измерениями.
+type ChartView struct {
+	Span time.Duration
+	End  time.Time
+}
+
+// Live сообщает, что окно заканчивается текущим моментом
+func (v ChartView) Live() bool {
+	return v.End.IsZero()
+}
+
+// Range возвращает границы окна
+func (v ChartView) Range(now time.Time) (from, to time.Time) {
+	to = now
+	if !v.Live() {
+		to = v.End
+	}
+	return to.Add(-v.Span), to
+}
+
+// Zoom меняет длительность окна в factor раз (меньше 1 – приближение), сохраняя конец
+func (v ChartView) Zoom(factor float64) ChartView {
+	v.Span = time.Duration(float64(v.Span) * factor).Round(time.Minute)
+	if v.Span < minChartSpan {
+		v.Span = minChartSpan
+	}
+	if v.Span > maxChartSpan {
+		v.Span = maxChartSpan
+	}
+	return v
+}
+
+// Pan сдвигает окно на половину его длительности: назад при steps < 0,
+// вперед при steps > 0. Окно, дошедшее до now, снова следует за измерениями.
+func (v ChartView) Pan(steps int, now time.Time) ChartView {
+	_, to := v.Range(now)
+	to = to.Add(time.Duration(steps) * v.Span / 2)
+	if !to.Before(now) {
+		v.End = time.Time{}
+	} else {
+		v.End = to
+	}
+	return v
+}
+
+// Label возвращает подпись окна: длительность и, если окно сдвинуто, его конец
+func (v ChartView) Label() string {
+	if v.Live() {
+		return formatChartWindow(v.Span)
+	}
+	return T("chart.window.until", formatChartWindow(v.Span), v.End.Local().Format("02.01 15:04"))
+}
+
+// getMeasurementsSince возвращает измерения начиная с момента since в хронологическом порядке
+func getMeasurementsSince(db *sqlx.DB, since time.Time) ([]Measurement, error) {
+	var ms []Measurement
+	query := `SELECT * FROM measurements WHERE timestamp >= ? ORDER BY timestamp`
+	if err := db.Select(&ms, query, since.UTC().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// filterMeasurementsSince оставляет измерения не старше since
+func filterMeasurementsSince(ms []Measurement, since time.Time) []Measurement {
+	sinceStr := since.UTC().Format(time.RFC3339)
+	for i, m := range ms {
+		if m.Timestamp >= sinceStr {
+			return ms[i:]
+		}
+	}
+	return nil
+}
+
+// downsampleMeasurements усредняет измерения по корзинам, чтобы их было не больше maxPoints.
+// Заряд и ёмкость усредняются, остальные поля берутся из последнего измерения корзины.
+func downsampleMeasurements(ms []Measurement, maxPoints int) []Measurement {
+	if maxPoints <= 0 || len(ms) <= maxPoints {
+		return ms
+	}
+
+	result := make([]Measurement, 0, maxPoints)
+	step := float64(len(ms)) / float64(maxPoints)
+	for i := 0; i < maxPoints; i++ {
+		start := int(float64(i) * step)
+		end := int(float64(i+1) * step)
+		if end > len(ms) {
+			end = len(ms)
+		}
+		if start >= end {
+			continue
+		}
+
+		bucket := ms[start:end]
+		avg := bucket[len(bucket)-1]
+		var pct, capacity, temp int
+		for _, m := range bucket {
+			pct += m.Percentage
+			capacity += m.CurrentCapacity
+			temp += m.Temperature
+		}
+		avg.Percentage = pct / len(bucket)
+		avg.CurrentCapacity = capacity / len(bucket)
+		avg.Temperature = temp / len(bucket)
+		result = append(result, avg)
+	}
+	return result
+}
+
+// GetView возвращает измерения видимого окна, прореженные для графиков.
+// Если буфер памяти покрывает окно целиком, к БД не обращаемся.
+func (ds *DataService) GetView(view ChartView) []Measurement {
+	since, until := view.Range(ds.Now())
+	sinceStr, untilStr := since.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339)
+
+	buffered := ds.buffer.GetLast(ds.buffer.Size())
+	inBuffer := func() []Measurement {
+		ms := filterMeasurementsSince(buffered, since)
+		for i, m := range ms {
+			if m.Timestamp > untilStr {
+				return ms[:i]
+			}
+		}
+		return ms
+	}
+	if len(buffered) > 0 && buffered[0].Timestamp <= sinceStr {
+		return downsampleMeasurements(inBuffer(), chartMaxPoints)
+	}
+
+	ms, err := getMeasurementsInRange(ds.db, ReportRange{From: since, To: until})
+	if err != nil {
+		// При ошибке БД показываем хотя бы то, что есть в памяти
+		return downsampleMeasurements(inBuffer(), chartMaxPoints)
+	}
+	return downsampleMeasurements(ms, chartMaxPoints)
+}
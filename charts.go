@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -19,6 +21,36 @@ type Chart struct {
 	Color       lipgloss.Color
 	ShowAxes    bool
 	FixedRange  bool // Флаг для фиксированного диапазона значений
+	Bands       []ChartBand // Цветовые полосы по порогам значений (по возрастанию)
+	From, To    time.Time   // Видимый период для подписей оси X (нулевые – номера точек)
+	Times       []time.Time // Время каждой точки Data для подписей оси X (см. SetSeries)
+	Cursor      int         // Колонка перекрестья в области данных; -1 – без перекрестья
+	Events      []ChartEvent // Отметки событий строкой под осью X (нужны Times)
+}
+
+// ChartEvent – отметка события под осью X у ближайшей по времени точки
+type ChartEvent struct {
+	At    time.Time
+	Glyph string // один символ шириной в колонку
+	Color lipgloss.Color
+	Minor bool // не перекрывает другие отметки в той же колонке
+}
+
+// ChartBand – цвет столбцов графика со значением выше порога
+type ChartBand struct {
+	Above float64
+	Color lipgloss.Color
+}
+
+// colorFor возвращает цвет столбца: цвет последней превышенной полосы или основной
+func (c *Chart) colorFor(value float64) lipgloss.Color {
+	color := c.Color
+	for _, band := range c.Bands {
+		if value > band.Above {
+			color = band.Color
+		}
+	}
+	return color
 }
 
 // NewChart создает новый график
@@ -27,9 +59,10 @@ func NewChart(title string, width, height int) *Chart {
 		Title:    title,
 		Width:    width,
 		Height:   height,
-		Color:    lipgloss.Color("39"), // Синий цвет по умолчанию
+		Color:    theme.Accent, // по умолчанию
 		ShowAxes: true,
 		Data:     make([]float64, 0),
+		Cursor:   -1,
 	}
 }
 
@@ -67,6 +100,34 @@ func (c *Chart) SetData(data []float64) {
 	}
 }
 
+// SetSeries устанавливает данные вместе со временем каждой точки:
+// ось X подписывается реальным временем, а не номерами точек
+func (c *Chart) SetSeries(times []time.Time, data []float64) {
+	c.SetData(data)
+	c.Times = nil
+	if len(times) == len(data) {
+		c.Times = append([]time.Time(nil), times...)
+	}
+}
+
+// DataWidth возвращает число колонок области данных
+func (c *Chart) DataWidth() int {
+	if c.ShowAxes {
+		return c.Width - 6
+	}
+	return c.Width
+}
+
+// PointAt возвращает индекс точки Data под колонкой col области данных
+func (c *Chart) PointAt(col int) int {
+	width := c.DataWidth()
+	if len(c.Data) == 0 || width <= 1 {
+		return 0
+	}
+	col = max(min(col, width-1), 0)
+	return int(math.Round(float64(col*(len(c.Data)-1)) / float64(width-1)))
+}
+
 // SetSize устанавливает новые размеры для графика
 func (c *Chart) SetSize(width, height int) {
 	if width > 0 {
@@ -112,6 +173,9 @@ func (c *Chart) renderChart() []string {
 	chartHeight := c.Height
 	if c.ShowAxes {
 		chartHeight -= 2 // Место для осей
+		if c.hasEventRow() {
+			chartHeight-- // Строка отметок событий
+		}
 	}
 	
 	lines := make([]string, chartHeight)
@@ -136,7 +200,7 @@ func (c *Chart) renderChart() []string {
 		if c.ShowAxes {
 			yValue := c.MaxValue - (float64(row)/float64(chartHeight-1))*(c.MaxValue-c.MinValue)
 			yLabel := fmt.Sprintf("%4.0f│", yValue)
-			line += lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(yLabel)
+			line += lipgloss.NewStyle().Foreground(theme.Border).Render(yLabel)
 		}
 		
 		// Данные графика
@@ -164,8 +228,15 @@ func (c *Chart) renderChart() []string {
 				char = plotChars[charIndex]
 			}
 			
-			// Применяем цвет
-			styledChar := lipgloss.NewStyle().Foreground(c.Color).Render(char)
+			// Применяем цвет; колонку перекрестья выделяем фоном
+			style := lipgloss.NewStyle().Foreground(c.colorFor(value))
+			if col == c.Cursor {
+				style = style.Background(theme.Selection)
+				if char == " " {
+					char = "│"
+				}
+			}
+			styledChar := style.Render(char)
 			line += styledChar
 		}
 		
@@ -234,25 +305,125 @@ func (c *Chart) renderAxes() []string {
 	
 	// X-ось
 	xAxis := "    └" + strings.Repeat("─", c.Width-6)
-	lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(xAxis))
+	lines = append(lines, lipgloss.NewStyle().Foreground(theme.Border).Render(xAxis))
+	if c.hasEventRow() {
+		lines = append(lines, c.renderEvents())
+	}
 	
-	// Подписи к X-оси (опционально)
-	if len(c.Data) > 1 {
+	// Подписи к X-оси: время точек, границы видимого периода или номера точек
+	if len(c.Times) == len(c.Data) && len(c.Data) > 1 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(theme.Border).Render(c.renderTimeLabels()))
+	} else if !c.From.IsZero() && !c.To.IsZero() {
+		layout := "15:04"
+		if c.To.Sub(c.From) >= 24*time.Hour {
+			layout = "02.01 15:04"
+		}
+		from, to := c.From.Local().Format(layout), c.To.Local().Format(layout)
+		gap := max(c.Width-5-len(from)-len(to), 1)
+		xLabels := "     " + from + strings.Repeat(" ", gap) + to
+		lines = append(lines, lipgloss.NewStyle().Foreground(theme.Border).Render(xLabels))
+	} else if len(c.Data) > 1 {
 		xLabels := fmt.Sprintf("     0%s%d", strings.Repeat(" ", c.Width-10), len(c.Data)-1)
-		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(xLabels))
+		lines = append(lines, lipgloss.NewStyle().Foreground(theme.Border).Render(xLabels))
 	}
 	
 	return lines
 }
 
+// hasEventRow сообщает, что под осью X есть строка отметок событий
+func (c *Chart) hasEventRow() bool {
+	return len(c.Events) > 0 && len(c.Times) == len(c.Data) && len(c.Data) > 1
+}
+
+// columnOf возвращает колонку области данных, в которую prepareDataForWidth
+// помещает точку idx
+func (c *Chart) columnOf(idx int) int {
+	width, n := c.DataWidth(), len(c.Data)
+	if n <= width {
+		return int(math.Round(float64(idx*(width-1)) / float64(n-1)))
+	}
+	return idx * width / n
+}
+
+// renderEvents рисует отметки событий под колонками ближайших точек.
+// События вне графика не показываются.
+func (c *Chart) renderEvents() string {
+	width := c.DataWidth()
+	row := make([]string, width)
+	minor := make([]bool, width)
+	first, last := c.Times[0], c.Times[len(c.Times)-1]
+	for _, e := range c.Events {
+		if e.At.Before(first) || e.At.After(last) {
+			continue
+		}
+		idx := sort.Search(len(c.Times), func(i int) bool { return !c.Times[i].Before(e.At) })
+		if idx > 0 && e.At.Sub(c.Times[idx-1]) < c.Times[idx].Sub(e.At) {
+			idx--
+		}
+		col := min(c.columnOf(idx), width-1)
+		if row[col] != "" && (e.Minor || !minor[col]) {
+			continue
+		}
+		row[col] = lipgloss.NewStyle().Foreground(e.Color).Render(e.Glyph)
+		minor[col] = e.Minor
+	}
+	var line strings.Builder
+	line.WriteString("     ")
+	for _, cell := range row {
+		if cell == "" {
+			cell = " "
+		}
+		line.WriteString(cell)
+	}
+	return line.String()
+}
+
+// renderTimeLabels подписывает ось X временем точек. Формат зависит от
+// охвата графика, а число подписей – от ширины: между ними не меньше трех пробелов.
+func (c *Chart) renderTimeLabels() string {
+	first, last := c.Times[0], c.Times[len(c.Times)-1]
+	layout := "15:04"
+	switch span := last.Sub(first); {
+	case span >= 4*24*time.Hour:
+		layout = "02.01"
+	case span >= 20*time.Hour:
+		layout = "02.01 15:04"
+	}
+
+	const offset = 5 // ширина подписей оси Y
+	dataWidth := c.Width - 6
+	labelWidth := len([]rune(first.Local().Format(layout)))
+	if dataWidth < labelWidth {
+		return ""
+	}
+	gaps := (dataWidth - labelWidth) / (labelWidth + 3) // промежутков между подписями
+
+	row := []rune(strings.Repeat(" ", offset+dataWidth))
+	for i := 0; i <= gaps; i++ {
+		col := 0
+		if gaps > 0 {
+			col = i * (dataWidth - labelWidth) / gaps
+		}
+		// Подписи, как и точки в prepareDataForWidth, равномерно делят ось:
+		// первая относится к первой точке, последняя – к последней
+		idx := 0
+		if gaps > 0 {
+			idx = int(math.Round(float64(i*(len(c.Times)-1)) / float64(gaps)))
+		}
+		label := []rune(c.Times[idx].Local().Format(layout))
+		copy(row[offset+col:], label)
+	}
+	return strings.TrimRight(string(row), " ")
+}
+
 // renderEmpty рендерит пустой график
 func (c *Chart) renderEmpty() string {
-	emptyMsg := "Нет данных для отображения"
+	emptyMsg := T("tui.charts.no_data_chart")
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
+		Foreground(theme.Border).
 		Align(lipgloss.Center).
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(theme.Border).
 		Width(c.Width).
 		Height(c.Height)
 	
@@ -261,8 +432,8 @@ func (c *Chart) renderEmpty() string {
 
 // BatteryChart создает график заряда батареи
 func NewBatteryChart(width, height int) *Chart {
-	chart := NewChart("⚡ Заряд батареи (%)", width, height)
-	chart.Color = lipgloss.Color("46") // Зеленый цвет
+	chart := NewChart(T("chart.title.charge"), width, height)
+	chart.Color = theme.Good
 	// Фиксируем диапазон для процентов заряда от 0 до 100
 	chart.MinValue = 0
 	chart.MaxValue = 100
@@ -272,17 +443,29 @@ func NewBatteryChart(width, height int) *Chart {
 
 // CapacityChart создает график емкости батареи
 func NewCapacityChart(width, height int) *Chart {
-	chart := NewChart("🔋 Емкость (мАч)", width, height)
-	chart.Color = lipgloss.Color("39") // Синий цвет
+	chart := NewChart(T("chart.title.capacity"), width, height)
+	chart.Color = theme.Accent
 	// Не фиксируем диапазон, чтобы он автоматически подстраивался под данные
 	chart.FixedRange = false
 	return chart
 }
 
-// TemperatureChart создает график температуры
-func NewTemperatureChart(width, height int) *Chart {
-	chart := NewChart("🌡️ Температура (°C)", width, height)
-	chart.Color = lipgloss.Color("196") // Красный цвет
+// TemperatureChart создает график температуры: зеленый до порога
+// предупреждения, желтый до порога тревоги, красный выше
+func NewTemperatureChart(width, height int, warn, alarm int) *Chart {
+	chart := NewChart(T("chart.title.temperature", warn, alarm), width, height)
+	chart.Color = theme.Good
+	chart.Bands = []ChartBand{
+		{Above: float64(warn), Color: theme.Warning},
+		{Above: float64(alarm), Color: theme.Critical},
+	}
+	return chart
+}
+
+// PowerChart создает график мощности батареи
+func NewPowerChart(width, height int) *Chart {
+	chart := NewChart(T("chart.title.power"), width, height)
+	chart.Color = theme.Caution
 	return chart
 }
 
@@ -297,7 +480,7 @@ type Sparkline struct {
 func NewSparkline(width int) *Sparkline {
 	return &Sparkline{
 		Width: width,
-		Color: lipgloss.Color("39"),
+		Color: theme.Accent,
 		Data:  make([]float64, 0),
 	}
 }
@@ -0,0 +1,157 @@
+// check.go
+//
+// Проверка здоровья батареи для скриптов MDM и CI: `batmon check` сравнивает
+// износ, циклы и число аномалий с порогами и возвращает код выхода
+// 0 – в норме, 1 – предупреждение, 2 – критично, 3 – оценить не удалось.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Коды выхода batmon check (как у плагинов Nagios)
+const (
+	checkOK       = 0
+	checkWarning  = 1
+	checkCritical = 2
+	checkUnknown  = 3
+)
+
+// HealthThresholds – пороги batmon check; 0 – показатель не проверяется
+type HealthThresholds struct {
+	WearWarning       float64 `json:"wear_warning"`       // износ, %
+	WearCritical      float64 `json:"wear_critical"`      // износ, %
+	CyclesWarning     int     `json:"cycles_warning"`     // циклы заряда
+	CyclesCritical    int     `json:"cycles_critical"`    // циклы заряда
+	AnomaliesWarning  int     `json:"anomalies_warning"`  // аномалии в истории
+	AnomaliesCritical int     `json:"anomalies_critical"` // аномалии в истории
+	ScoreModel        string  `json:"score_model"`        // модель рейтинга: heuristic, apple_condition, capacity, cycles
+}
+
+// defaultHealthThresholds – пороги по умолчанию: Apple считает батарею
+// изношенной при 80% ёмкости и рассчитывает ее на 1000 циклов
+func defaultHealthThresholds() HealthThresholds {
+	return HealthThresholds{
+		WearWarning:       20,
+		WearCritical:      30,
+		CyclesWarning:     800,
+		CyclesCritical:    1000,
+		AnomaliesWarning:  3,
+		AnomaliesCritical: 10,
+		ScoreModel:        string(healthModelHeuristic),
+	}
+}
+
+// exitCodeError – результат команды с собственным кодом выхода; сообщение
+// команда уже вывела
+type exitCodeError struct {
+	code int
+}
+
+func (e exitCodeError) Error() string {
+	return T("check.exit_code", e.code)
+}
+
+// HealthCheck – результат проверки
+type HealthCheck struct {
+	Wear      float64
+	Cycles    int
+	Anomalies int
+	Score     int      // рейтинг здоровья 0-100; 0 – оценка без истории
+	Live      bool     // история пуста, показатели сняты с батареи напрямую
+	Problems  []string // что превысило пороги
+	Code      int
+}
+
+// evaluateHealth сравнивает показатели с порогами
+func evaluateHealth(check *HealthCheck, t HealthThresholds) {
+	check.Code = checkOK
+	flag := func(value, warning, critical float64, key string) {
+		switch {
+		case critical > 0 && value >= critical:
+			check.Code = max(check.Code, checkCritical)
+			check.Problems = append(check.Problems, fmt.Sprintf(T(key)+T("check.above_critical"), value, critical))
+		case warning > 0 && value >= warning:
+			check.Code = max(check.Code, checkWarning)
+			check.Problems = append(check.Problems, fmt.Sprintf(T(key)+" ≥ %g", value, warning))
+		}
+	}
+	flag(check.Wear, t.WearWarning, t.WearCritical, T("check.wear"))
+	flag(float64(check.Cycles), float64(t.CyclesWarning), float64(t.CyclesCritical), T("check.cycles"))
+	flag(float64(check.Anomalies), float64(t.AnomaliesWarning), float64(t.AnomaliesCritical), T("check.anomalies"))
+}
+
+// collectHealthCheck берет показатели из истории, а если ее нет – с батареи
+func collectHealthCheck(db *sqlx.DB) (*HealthCheck, error) {
+	ms, err := loadReportMeasurements(db, ReportRange{}, reportLastN)
+	if err != nil {
+		return nil, fmt.Errorf(T("check.err.data"), err)
+	}
+	if health := analyzeBatteryHealth(ms); health != nil && ms[len(ms)-1].DesignCapacity > 0 {
+		return &HealthCheck{Wear: health.WearPercentage, Cycles: health.CycleCount, Anomalies: len(health.Anomalies),
+			Score: health.HealthScore}, nil
+	}
+
+	details, err := newBatterySource().Details()
+	if err != nil {
+		return nil, fmt.Errorf(T("check.err.no_history"), err)
+	}
+	if details.DesignCapacity <= 0 {
+		return nil, errors.New(T("check.err.no_design"))
+	}
+	return &HealthCheck{
+		Wear:   computeWear(details.DesignCapacity, details.FullChargeCap),
+		Cycles: details.CycleCount,
+		Live:   true,
+	}, nil
+}
+
+// runCheckCommand печатает сводку одной строкой и завершается с кодом состояния
+func runCheckCommand(args []string) error {
+	t := getConfig().Health
+	fs := newCommandFlags("check")
+	fs.Float64Var(&t.WearWarning, "wear-warn", t.WearWarning, localized("flag.check.wear-warn", "износ для предупреждения, %"))
+	fs.Float64Var(&t.WearCritical, "wear-crit", t.WearCritical, localized("flag.check.wear-crit", "критический износ, %"))
+	fs.IntVar(&t.CyclesWarning, "cycles-warn", t.CyclesWarning, localized("flag.check.cycles-warn", "циклы для предупреждения"))
+	fs.IntVar(&t.CyclesCritical, "cycles-crit", t.CyclesCritical, localized("flag.check.cycles-crit", "критическое число циклов"))
+	fs.IntVar(&t.AnomaliesWarning, "anomalies-warn", t.AnomaliesWarning, localized("flag.check.anomalies-warn", "аномалии для предупреждения"))
+	fs.IntVar(&t.AnomaliesCritical, "anomalies-crit", t.AnomaliesCritical, localized("flag.check.anomalies-crit", "критическое число аномалий"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		fmt.Println(T("check.unknown_db", err))
+		return exitCodeError{checkUnknown}
+	}
+	defer db.Close()
+
+	check, err := collectHealthCheck(db)
+	if err != nil {
+		fmt.Printf("UNKNOWN: %v\n", err)
+		return exitCodeError{checkUnknown}
+	}
+	evaluateHealth(check, t)
+
+	label := [...]string{"OK", "WARNING", "CRITICAL"}[check.Code]
+	summary := T("check.summary", label, check.Wear, check.Cycles)
+	if check.Live {
+		summary += T("check.live")
+	} else {
+		summary += T("check.summary.anomalies", check.Anomalies)
+	}
+	if len(check.Problems) > 0 {
+		summary += " – " + strings.Join(check.Problems, "; ")
+	}
+	fmt.Println(summary)
+	if check.Code != checkOK {
+		return exitCodeError{check.Code}
+	}
+	return nil
+}
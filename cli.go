@@ -0,0 +1,554 @@
+// cli.go
+//
+// Подкоманды командной строки: collect, report, export, db, fleet, serve, diag, doctor,
+// bench, calibration, schema, rules, note и служебные tmux-status, replay, verify-certificate. Глобальный
+// флаг --db задаёт альтернативный путь к базе данных для любой команды и для TUI.
+// Без подкоманды запускается интерактивный интерфейс.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jmoiron/sqlx"
+)
+
+// dbPathOverride – путь к БД из флага --db (пусто – путь по умолчанию)
+var dbPathOverride string
+
+// errUsage – ошибка в аргументах команды, справка уже выведена
+var errUsage = messageError("cli.err.usage")
+
+// errHelpShown – запрошена справка по команде (-h), она уже выведена;
+// команда при этом не выполняется
+var errHelpShown = messageError("cli.err.help_shown")
+
+// cliCommand – подкоманда batmon
+type cliCommand struct {
+	name    string
+	args    string // подсказка по аргументам для справки; перевод – под ключом "cmd.<name>.args"
+	summary string // по-русски; перевод – в каталоге i18n.go под ключом "cmd.<name>"
+	run     func(args []string) error
+}
+
+// cliCommands возвращает список подкоманд в порядке вывода в справке
+func cliCommands() []cliCommand {
+	return []cliCommand{
+		{"check", "[--wear-warn 20] [--cycles-crit 1000] ...", "проверка здоровья с кодом выхода 0/1/2 (для MDM и CI)", runCheckCommand},
+		{"status", "[--json]", "разовый статус батареи для скриптов и виджетов", runStatusCommand},
+		{"collect", "[--interval 30s] [--powermetrics] [--once]", "фоновый сбор данных без интерфейса", runCollectCommand},
+		{"report", "[--from 7d] [--to дата] [--compare снимок] | schedule [--weekly] [--email адрес] [--smtp host:port]", "текстовый отчет в терминал или отчет по почте", runReportCommand},
+		{"snapshot", "[save|list|compare|delete] <имя>", "снимки состояния и сравнение «было – стало»", runSnapshotCommand},
+		{"export", "[--md файл] [--html файл] [--certificate файл] [--from] [--to] [--compare снимок]", "экспорт отчетов, форматы можно сочетать", runExportCommand},
+		{"chart", "--out файл.png|svg [--metric percentage,capacity] [--from 7d] [--to]", "график истории в PNG или SVG", runChartCommand},
+		{"db", "[path|stats|cleanup|backup|restore|import|optimize|version|migrate]", "обслуживание базы данных", runDBCommand},
+		{"fleet", "[import [--host имя] <файл|папка>...|report [--sort wear] [--md файл]|remove <хост>]", "сводка по батареям нескольких MacBook из снимков status --json", runFleetCommand},
+		{"serve", "[--addr 127.0.0.1:8787] [--remote] | token [--rotate]", "HTTP API с данными батареи (/api/v1 с токеном)", runServeCommand},
+		{"diag", "", "диагностика источника данных и текущий статус", runDiagCommand},
+		{"doctor", "", "состояние сборщика: утилиты, последнее измерение, база, диск, caffeinate", runDoctorCommand},
+		{"apps", "[--from 14:00] [--to 15:00] [--top 10]", "какие приложения расходовали батарею за период", runAppsCommand},
+		{"metrics", "", "проверить производные метрики из config.json", runMetricsCommand},
+		{"bench", "[--n 130000] [--runs 3] [--cpuprofile файл] [--memprofile файл]", "замер скорости анализа на синтетической истории", runBenchCommand},
+		{"calibration", "[--md файл] [status|start|abort|resume|finish|report]", "полный тест батареи 100% → 0%", runCalibrationCommand},
+		{"tmux-status", "[секунды]", "строка для статус-бара tmux", runTmuxStatusCommand},
+		{"replay", "[--speed 60] <файл.sqlite|файл.json>", "воспроизвести записанную сессию в дашборде", runReplayCommand},
+		{"verify-certificate", "<код>", "подтвердить код проверки сертификата", runVerifyCertificateCommand},
+		{"schema", "[--json]", "схема БД и JSON Schema выгрузок для проверки совместимости", runSchemaCommand},
+		{"rules", "[--json]", "действующие правила рекомендаций (встроенные и из config.json)", runRulesCommand},
+		{"note", "[add [--at|--from --to|--session N] <текст>|list|delete N]", "заметки к моменту, промежутку или сессии", runNoteCommand},
+		{"version", "", "версия программы", func([]string) error { showVersion(); return nil }},
+		{"help", "", "подробная справка", func([]string) error { showHelp(); return nil }},
+	}
+}
+
+// legacyFlags – старые флаги вида "-export-md файл", переводимые в подкоманды
+var legacyFlags = map[string][]string{
+	"-export-md":           {"export", "--md"},
+	"--export-md":          {"export", "--md"},
+	"-export-html":         {"export", "--html"},
+	"--export-html":        {"export", "--html"},
+	"-export-certificate":  {"export", "--certificate"},
+	"--export-certificate": {"export", "--certificate"},
+	"-verify-certificate":  {"verify-certificate"},
+	"--verify-certificate": {"verify-certificate"},
+	"-v":                   {"version"},
+}
+
+// runCLI разбирает аргументы и выполняет подкоманду. handled=false означает,
+// что подкоманды нет и нужно запускать интерактивный интерфейс.
+func runCLI(args []string) (handled bool, exitCode int) {
+	if len(args) > 0 {
+		if mapped, ok := legacyFlags[args[0]]; ok {
+			args = append(append([]string{}, mapped...), args[1:]...)
+		}
+	}
+
+	global := flag.NewFlagSet("batmon", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	global.StringVar(&dbPathOverride, "db", "", localized("flag.db", "путь к базе данных"))
+	verbose := global.Bool("verbose", false, localized("flag.verbose", "подробный журнал (уровень DEBUG)"))
+	showVer := global.Bool("version", false, localized("flag.version", "версия программы"))
+	showHlp := global.Bool("help", false, localized("flag.help", "справка"))
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printCLIUsage(os.Stdout)
+			return true, 0
+		}
+		color.New(color.FgRed).Fprintf(os.Stderr, "❌ %v\n", err)
+		printCLIUsage(os.Stderr)
+		return true, 2
+	}
+	if err := initLogging(getConfig().Log, *verbose); err != nil {
+		logWarnf("⚠️ Журнал: %v", err)
+	}
+
+	switch {
+	case *showVer:
+		showVersion()
+		return true, 0
+	case *showHlp:
+		showHelp()
+		return true, 0
+	case global.NArg() == 0:
+		return false, 0
+	}
+
+	name := global.Arg(0)
+	for _, cmd := range cliCommands() {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(global.Args()[1:])
+		var exitErr exitCodeError
+		switch {
+		case err == nil, errors.Is(err, errHelpShown):
+			return true, 0
+		case errors.Is(err, errUsage):
+			return true, 2
+		case errors.As(err, &exitErr):
+			return true, exitErr.code
+		default:
+			color.New(color.FgRed).Fprintf(os.Stderr, "❌ %v\n", err)
+			return true, 1
+		}
+	}
+
+	color.New(color.FgRed).Fprintf(os.Stderr, "❌ %s\n\n", T("cli.unknown_command", name))
+	printCLIUsage(os.Stderr)
+	return true, 2
+}
+
+// printCLIUsage выводит краткий список подкоманд
+func printCLIUsage(w io.Writer) {
+	fmt.Fprintln(w, T("cli.usage"))
+	fmt.Fprintln(w, T("cli.no_command"))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, T("cli.commands"))
+	for _, cmd := range cliCommands() {
+		usage := cmd.name
+		if cmd.args != "" {
+			usage += " " + localized("cmd."+cmd.name+".args", cmd.args)
+		}
+		fmt.Fprintf(w, "  %-58s %s\n", usage, localized("cmd."+cmd.name, cmd.summary))
+	}
+}
+
+// newCommandFlags создает набор флагов подкоманды с общим флагом --db
+func newCommandFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("batmon "+name, flag.ContinueOnError)
+	fs.StringVar(&dbPathOverride, "db", dbPathOverride, localized("flag.db", "путь к базе данных"))
+	return fs
+}
+
+// parseCommandFlags разбирает флаги подкоманды; ошибки и -h уже выведены flag.
+// После -h возвращает errHelpShown, чтобы команда не выполнялась.
+func parseCommandFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errHelpShown
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// runCollectCommand собирает данные в фоне до сигнала завершения
+func runCollectCommand(args []string) error {
+	fs := newCommandFlags("collect")
+	interval := fs.Duration("interval", 0, localized("flag.collect.interval", "период опроса (по умолчанию адаптивный)"))
+	detailed := fs.Bool("powermetrics", false, localized("flag.collect.powermetrics", "подробный режим: мощность CPU/GPU/ANE (нужен root или sudo без пароля)"))
+	once := fs.Bool("once", false, localized("flag.collect.once", "одно измерение с записью в БД и выход (для cron/launchd)"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	if *detailed {
+		cfg := getConfig()
+		cfg.Power.Powermetrics = true
+		setConfig(cfg)
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	if *once {
+		return collectOnce(db)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	color.New(color.FgGreen).Println(T("cli.collect.started", getDBPath()))
+	if *interval <= 0 {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		backgroundDataCollection(db, ctx, &wg)
+		return nil
+	}
+
+	collector := NewDataCollector(db)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if err := collector.collectAndStore(); err != nil {
+			color.New(color.FgYellow).Println(T("cli.collect.failed", err))
+		}
+		select {
+		case <-ctx.Done():
+			return collector.Close()
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectOnce делает один цикл сбора и записывает его сразу, без ожидания
+// пакета. Ничего не выводит (cron шлет письмо на любой вывод): ошибка сбора
+// или записи печатается в stderr и дает ненулевой код выхода.
+func collectOnce(db *sqlx.DB) error {
+	defer setLogConsole(nil)()
+	collector := NewDataCollector(db)
+	err := collector.collectAndStore()
+	if closeErr := collector.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// addRangeFlags добавляет флаги периода отчета --from и --to
+func addRangeFlags(fs *flag.FlagSet) func() (ReportRange, error) {
+	from := fs.String("from", "", localized("flag.from", "начало периода: 7d, 24h, 14:00, 2025-01-31 или \"2025-01-31 18:00\""))
+	to := fs.String("to", "", localized("flag.to", "конец периода в том же формате (дата без времени – до конца дня)"))
+	return func() (ReportRange, error) {
+		return parseReportRange(*from, *to, time.Now())
+	}
+}
+
+// runReportCommand выводит отчет в терминал без ожидания ввода
+func runReportCommand(args []string) error {
+	fs := newCommandFlags("report")
+	reportRange := addRangeFlags(fs)
+	compare := fs.String("compare", "", localized("flag.report.compare", "сравнить с сохраненным снимком (batmon snapshot save)"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.Arg(0) == "schedule" {
+		return runReportScheduleCommand(fs.Args()[1:])
+	}
+	rng, err := reportRange()
+	if err != nil {
+		return err
+	}
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+	return printReport(db, rng, *compare)
+}
+
+// runExportCommand экспортирует отчеты в один или несколько форматов
+func runExportCommand(args []string) error {
+	fs := newCommandFlags("export")
+	md := fs.String("md", "", localized("flag.export.md", "файл отчета Markdown"))
+	html := fs.String("html", "", localized("flag.export.html", "файл отчета HTML"))
+	certificate := fs.String("certificate", "", localized("flag.export.certificate", "файл сертификата состояния батареи"))
+	quiet := fs.Bool("quiet", false, localized("flag.export.quiet", "не выводить ход экспорта"))
+	compare := fs.String("compare", "", localized("flag.export.compare", "добавить сравнение с сохраненным снимком"))
+	reportRange := addRangeFlags(fs)
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	rng, err := reportRange()
+	if err != nil {
+		return err
+	}
+	if *md == "" && *html == "" && *certificate == "" {
+		fmt.Fprintln(os.Stderr, T("cli.export.no_format"))
+		fs.PrintDefaults()
+		return errUsage
+	}
+
+	if *md != "" || *html != "" {
+		if err := runExportMode(*md, *html, rng, *compare, *quiet); err != nil {
+			return fmt.Errorf(T("err.export"), err)
+		}
+	}
+	if *certificate != "" {
+		if err := runCertificateExport(*certificate); err != nil {
+			return fmt.Errorf(T("cert.err.export"), err)
+		}
+	}
+	return nil
+}
+
+// runDBCommand выполняет обслуживание базы данных
+func runDBCommand(args []string) error {
+	fs := newCommandFlags("db")
+	days := fs.Int("days", 90, localized("flag.db.days", "cleanup: хранить данные за последние N дней"))
+	to := fs.Int("to", -1, localized("flag.db.to", "migrate: версия схемы (по умолчанию последняя, меньшая – откат)"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	action := "stats"
+	if fs.NArg() > 0 {
+		action = fs.Arg(0)
+	}
+	switch action {
+	case "path":
+		fmt.Println(getDBPath())
+		if syncDir() != "" {
+			if info, err := readSyncLock(getDBPath()); err == nil && info != nil && info.Fresh(time.Now()) {
+				fmt.Println(T("cli.db.sync_lock", info.Host, parseStoredTime(info.UpdatedAt).Local().Format("02.01 15:04")))
+			}
+		}
+		return nil
+	case "stats":
+		return showDatabaseStats()
+	case "cleanup":
+		if *days <= 0 {
+			return errors.New(T("cli.err.days"))
+		}
+		db, err := initDB(getDBPath())
+		if err != nil {
+			return fmt.Errorf(T("err.db_init"), err)
+		}
+		defer db.Close()
+		if _, err := autoBackup(db, "cleanup"); err != nil {
+			return err
+		}
+		if err := NewDataRetention(newSQLiteMeasurements(db), time.Duration(*days)*24*time.Hour).Cleanup(); err != nil {
+			return fmt.Errorf(T("err.cleanup"), err)
+		}
+		color.New(color.FgGreen).Println(T("cli.db.cleaned", *days))
+		return nil
+	case "backup":
+		db, err := initDB(getDBPath())
+		if err != nil {
+			return fmt.Errorf(T("err.db_init"), err)
+		}
+		defer db.Close()
+		path := fs.Arg(1)
+		if path == "" {
+			dir, err := getBackupDir()
+			if err != nil {
+				return err
+			}
+			path = filepath.Join(dir, "batmon-"+time.Now().Format("20060102-150405")+".sqlite")
+		}
+		if err := backupDatabase(db, path); err != nil {
+			return err
+		}
+		color.New(color.FgGreen).Println(T("cli.db.backup", path))
+		return nil
+	case "restore":
+		if fs.NArg() < 2 {
+			fmt.Fprintln(os.Stderr, T("cli.db.restore.usage"))
+			return errUsage
+		}
+		previous, err := restoreDatabase(fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf(T("err.restore"), err)
+		}
+		color.New(color.FgGreen).Println(T("cli.db.restored", fs.Arg(1)))
+		if previous != "" {
+			fmt.Println(T("cli.db.previous", previous))
+		}
+		return nil
+	case "import":
+		if fs.NArg() < 2 {
+			fmt.Fprintln(os.Stderr, T("cli.db.import.usage"))
+			return errUsage
+		}
+		db, err := initDB(getDBPath())
+		if err != nil {
+			return fmt.Errorf(T("err.db_init"), err)
+		}
+		defer db.Close()
+		if _, err := autoBackup(db, "import"); err != nil {
+			return err
+		}
+		result, err := importDatabase(db, fs.Arg(1))
+		if err != nil {
+			return fmt.Errorf(T("err.import"), err)
+		}
+		if result.Total > 0 {
+			fmt.Println(T("cli.db.import.source", result.Total,
+				parseStoredTime(result.From).Local().Format("02.01.2006"), parseStoredTime(result.To).Local().Format("02.01.2006")))
+		}
+		color.New(color.FgGreen).Println(T("cli.db.imported", result.Imported, result.Duplicates))
+		return nil
+	case "optimize":
+		db, err := initDB(getDBPath())
+		if err != nil {
+			return fmt.Errorf(T("err.db_init"), err)
+		}
+		defer db.Close()
+		started := time.Now()
+		if err := optimizeDatabase(db); err != nil {
+			return err
+		}
+		for _, idx := range measurementIndexes {
+			fmt.Printf("📇 %s: %s(%s)\n", idx.Name, idx.Table, idx.Column)
+		}
+		color.New(color.FgGreen).Println(T("cli.db.optimized", time.Since(started).Round(time.Millisecond)))
+		return nil
+	case "version", "migrate":
+		db, err := initDB(getDBPath())
+		if err != nil {
+			return fmt.Errorf(T("err.db_init"), err)
+		}
+		defer db.Close()
+		if action == "migrate" && *to >= 0 {
+			if current, err := currentSchemaVersion(db); err == nil && *to < current {
+				if _, err := autoBackup(db, "migrate"); err != nil {
+					return err
+				}
+			}
+			if err := migrateTo(db, *to); err != nil {
+				return err
+			}
+			color.New(color.FgGreen).Println(T("cli.db.migrated", *to))
+			if *to < latestSchemaVersion() {
+				color.Yellow(T("cli.db.migrate_again", latestSchemaVersion()))
+			}
+			return nil
+		}
+		versions, err := getSchemaVersions(db)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			fmt.Printf("%3d  %-30s %s\n", v.Version, migrationTitle(v.Version, v.Name), parseStoredTime(v.AppliedAt).Local().Format("02.01.2006 15:04"))
+		}
+		fmt.Println(T("cli.db.schema_version", len(versions), latestSchemaVersion()))
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, T("cli.db.unknown_action", action))
+	return errUsage
+}
+
+// runDiagCommand выводит диагностику без ожидания ввода
+func runDiagCommand(args []string) error {
+	fs := newCommandFlags("diag")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	printSystemInfo()
+	fmt.Println()
+	if err := showQuickStatus(); err != nil {
+		return err
+	}
+	details, err := newBatterySource().Details()
+	if err != nil {
+		return fmt.Errorf(T("err.details"), err)
+	}
+	fmt.Println(T("cli.diag.cycles", details.CycleCount))
+	nominal := detailsNominalVoltage(details)
+	fmt.Println(T("cli.diag.capacity",
+		details.CurrentCapacity, formatCapacity(details.FullChargeCap, nominal), formatCapacity(details.DesignCapacity, nominal)))
+	fmt.Println(T("cli.diag.electrical",
+		details.Temperature, details.Voltage, details.Amperage))
+	if details.Condition != "" {
+		fmt.Println(T("cli.diag.condition", details.Condition))
+	}
+	if len(details.CellVoltages) > 0 {
+		fmt.Println(T("cli.diag.cells", details.CellVoltages))
+	}
+	if details.PermanentFailure != 0 {
+		fmt.Println(T("cli.diag.permanent_failure", details.PermanentFailure))
+	}
+	return nil
+}
+
+// runTmuxStatusCommand печатает строку для tmux
+func runTmuxStatusCommand(args []string) error {
+	fs := newCommandFlags("tmux-status")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	ttl := tmuxDefaultCacheTTL
+	if fs.NArg() > 0 {
+		seconds, err := strconv.Atoi(fs.Arg(0))
+		if err != nil || seconds < 0 {
+			fmt.Fprintln(os.Stderr, T("cli.tmux.usage"))
+			return errUsage
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+	return runTmuxStatus(ttl)
+}
+
+// runReplayCommand воспроизводит запись в дашборде
+func runReplayCommand(args []string) error {
+	fs := newCommandFlags("replay")
+	speed := fs.Float64("speed", defaultReplaySpeed, localized("flag.replay.speed", "ускорение воспроизведения"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, T("cli.replay.usage"))
+		return errUsage
+	}
+	// Совместимость со старой формой: batmon replay <файл> [скорость]
+	if fs.NArg() > 1 {
+		v, err := strconv.ParseFloat(fs.Arg(1), 64)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, T("cli.replay.speed_number"))
+			return errUsage
+		}
+		*speed = v
+	}
+	if *speed <= 0 {
+		fmt.Fprintln(os.Stderr, T("cli.replay.speed_positive"))
+		return errUsage
+	}
+	return runReplayMode(fs.Arg(0), *speed)
+}
+
+// runVerifyCertificateCommand сверяет код сертификата с локальными данными
+func runVerifyCertificateCommand(args []string) error {
+	fs := newCommandFlags("verify-certificate")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, T("cli.verify.usage"))
+		return errUsage
+	}
+	return runCertificateVerify(fs.Arg(0))
+}
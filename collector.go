@@ -0,0 +1,96 @@
+// collector.go
+//
+// Источники данных о батарее. Реализация выбирается по runtime.GOOS:
+// pmset/ioreg на macOS, sysfs на Linux, WMI на Windows. Переменная
+// окружения BATMON_SOURCE=replay:<файл.json> подменяет источник записью.
+
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// batterySourceEnv – переменная окружения для выбора источника данных
+const batterySourceEnv = "BATMON_SOURCE"
+
+// BatteryDetails – подробные параметры батареи, которые собираются реже базовых
+type BatteryDetails struct {
+	CycleCount       int
+	FullChargeCap    int // мАч
+	DesignCapacity   int // мАч
+	CurrentCapacity  int // мАч
+	Temperature      int // °C
+	Voltage          int // мВ
+	Amperage         int // мА (+ заряд, - разряд)
+	Condition        string
+	Serial           string
+	CellVoltages     []int     // мВ по ячейкам; пусто – источник не сообщает
+	PermanentFailure int       // PermanentFailureStatus контроллера; не 0 – батарея заблокирована
+	Manufactured     time.Time // дата изготовления; нулевая – источник не сообщает
+}
+
+// BatterySource – источник данных о батарее: платформенный или записанный
+type BatterySource interface {
+	// Name возвращает короткое имя источника для логов и диагностики
+	Name() string
+	// Status возвращает процент заряда и состояние питания (быстрый вызов)
+	Status() (int, string, error)
+	// Details возвращает подробные параметры батареи (может быть медленным)
+	Details() (BatteryDetails, error)
+}
+
+// RemainingEstimator – источник, который умеет отдавать оценку оставшегося
+// времени работы от самой ОС (на macOS – «4:30 remaining» из pmset)
+type RemainingEstimator interface {
+	// Remaining возвращает оценку ОС; ok=false, если оценки пока нет
+	Remaining() (estimate time.Duration, ok bool, err error)
+}
+
+// Положение крышки ноутбука
+const (
+	lidOpen   = "open"
+	lidClosed = "closed"
+)
+
+// DisplayContext – состояние экрана в момент измерения
+type DisplayContext struct {
+	Brightness int    // яркость встроенного экрана, %; 0 – нет данных
+	LidState   string // lidOpen или lidClosed; пусто – нет данных
+}
+
+// DisplayContextReader – источник, который знает яркость экрана и положение крышки
+type DisplayContextReader interface {
+	DisplayContext() (DisplayContext, error)
+}
+
+// newBatterySource выбирает источник данных: запись из BATMON_SOURCE
+// или платформенный источник для текущей ОС
+func newBatterySource() BatterySource {
+	if spec := os.Getenv(batterySourceEnv); spec != "" {
+		if path, ok := strings.CutPrefix(spec, "replay:"); ok {
+			fixture, err := loadBatteryFixture(path)
+			if err == nil {
+				return newReplaySource(fixture)
+			}
+			logWarnf("⚠️ %s: %v, используется системный источник", batterySourceEnv, err)
+		} else {
+			logWarnf("⚠️ %s: неизвестный источник %q, используется системный", batterySourceEnv, spec)
+		}
+	}
+	return newPlatformSource()
+}
+
+// newPlatformSource возвращает источник данных для текущей ОС
+func newPlatformSource() BatterySource {
+	switch runtime.GOOS {
+	case "linux":
+		return newSysfsSource(sysfsPowerSupplyRoot)
+	case "windows":
+		return wmiSource{}
+	default:
+		return newMacSource()
+	}
+}
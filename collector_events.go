@@ -0,0 +1,106 @@
+// collector_events.go
+//
+// Подписка на новые измерения коллектора. Дашборд получает измерение сразу
+// после сбора, а не по таймеру обновления, и показывает отсчет до следующего.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// measurementSubscribers – подписчики на новые измерения коллектора
+type measurementSubscribers struct {
+	mu   sync.Mutex
+	subs map[chan Measurement]struct{}
+}
+
+// Subscribe возвращает канал новых измерений и функцию отписки. Канал
+// буферизован на одно измерение: медленный подписчик получает самое свежее,
+// а не задерживает сбор.
+func (dc *DataCollector) Subscribe() (<-chan Measurement, func()) {
+	ch := make(chan Measurement, 1)
+	dc.events.mu.Lock()
+	if dc.events.subs == nil {
+		dc.events.subs = make(map[chan Measurement]struct{})
+	}
+	dc.events.subs[ch] = struct{}{}
+	dc.events.mu.Unlock()
+
+	return ch, func() {
+		dc.events.mu.Lock()
+		defer dc.events.mu.Unlock()
+		if _, ok := dc.events.subs[ch]; ok {
+			delete(dc.events.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish рассылает измерение подписчикам, заменяя непрочитанное
+func (dc *DataCollector) publish(m Measurement) {
+	dc.events.mu.Lock()
+	defer dc.events.mu.Unlock()
+	for ch := range dc.events.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- m
+	}
+}
+
+// liveTickMsg – секундный тик для отсчета до следующего измерения
+type liveTickMsg time.Time
+
+// liveTick запускает секундный тик дашборда
+func liveTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return liveTickMsg(t)
+	})
+}
+
+// waitForMeasurement ждет измерение от коллектора и отдает его дашборду
+// как dataUpdateMsg; после закрытия канала команда ничего не возвращает
+func waitForMeasurement(ds *DataService, ch <-chan Measurement, view ChartView) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-ch; !ok {
+			return nil
+		}
+		msg := updateData(ds, view)().(dataUpdateMsg)
+		msg.live = true
+		return msg
+	}
+}
+
+// NextSample возвращает время следующего измерения; нулевое – неизвестно
+// (воспроизведение записи или сбор еще не запущен)
+func (ds *DataService) NextSample() time.Time {
+	if n := ds.nextSample.Load(); n > 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
+}
+
+// liveStatusLine возвращает строку индикатора: «в эфире», время последнего
+// измерения и отсчет до следующего
+func (a *App) liveStatusLine() string {
+	if a.dataService == nil || a.dataService.events == nil || a.latest == nil {
+		return ""
+	}
+	line := lipgloss.NewStyle().Foreground(theme.Good).Bold(true).Render("● LIVE")
+	line += " · " + T("live.sample", parseStoredTime(a.latest.Timestamp).Local().Format("15:04:05"))
+	if next := a.dataService.NextSample(); !next.IsZero() {
+		left := time.Until(next).Round(time.Second)
+		if left > 0 {
+			line += " · " + T("live.next", int(left.Minutes()), int(left.Seconds())%60)
+		} else {
+			line += " · " + T("live.collecting")
+		}
+	}
+	return line
+}
@@ -0,0 +1,379 @@
+// collector_mac.go
+//
+// Сбор данных о батарее на macOS через pmset, ioreg и system_profiler.
+// Разбор вывода отделён от запуска команд, чтобы его можно было
+// переиспользовать для записанных фикстур.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	pmsetBatteryRe   = regexp.MustCompile(`(\d+)%\s*;\s*(\w+)`)
+	pmsetRemainingRe = regexp.MustCompile(`(\d+):(\d{2}) remaining`)
+)
+
+// macSource читает данные через pmset, ioreg и system_profiler
+type macSource struct{}
+
+func (macSource) Name() string { return "pmset/ioreg" }
+
+func (macSource) Status() (int, string, error) {
+	out, err := runCommand("pmset", "-g", "batt")
+	if err != nil {
+		return 0, "", fmt.Errorf("pmset: %w", err)
+	}
+	return parsePMSetOutput(out)
+}
+
+func (macSource) Remaining() (time.Duration, bool, error) {
+	out, err := runCommand("pmset", "-g", "batt")
+	if err != nil {
+		return 0, false, fmt.Errorf("pmset: %w", err)
+	}
+	estimate, ok := parsePMSetRemaining(out)
+	return estimate, ok, nil
+}
+
+func (macSource) Details() (BatteryDetails, error) {
+	// -a – вывод в XML plist: в нем доступны вложенные BatteryData и
+	// CellVoltage, а формат не зависит от версии macOS
+	ioreg, err := runCommand("ioreg", "-a", "-rn", "AppleSmartBattery")
+	if err != nil {
+		return BatteryDetails{}, fmt.Errorf("ioreg: %w", err)
+	}
+	// system_profiler нужен только для состояния батареи, его ошибка не фатальна
+	sp, spErr := runCommand("system_profiler", "SPPowerDataType", "-detailLevel", "full")
+	if spErr != nil {
+		sp = nil
+	}
+	return mergeMacOutputs(ioreg, sp)
+}
+
+// parsePMSetOutput извлекает процент заряда и состояние питания из вывода pmset -g batt
+func parsePMSetOutput(out []byte) (int, string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		m := pmsetBatteryRe.FindStringSubmatch(scanner.Text())
+		if len(m) == 3 {
+			pct, _ := strconv.Atoi(m[1])
+			return pct, strings.ToLower(m[2]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, "", fmt.Errorf(T("mac.err.pmset"), err)
+	}
+	return 0, "", errors.New(T("mac.err.no_battery"))
+}
+
+// parsePMSetRemaining извлекает оценку оставшегося времени из вывода pmset -g batt.
+// Пока macOS считает оценку, pmset пишет "(no estimate)" – тогда ok=false.
+func parsePMSetRemaining(out []byte) (time.Duration, bool) {
+	m := pmsetRemainingRe.FindSubmatch(out)
+	if m == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(string(m[1]))
+	minutes, _ := strconv.Atoi(string(m[2]))
+	estimate := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	// 0:00 remaining pmset показывает на зарядке и сразу после отключения
+	return estimate, estimate > 0
+}
+
+// parseSystemProfilerOutput извлекает число циклов и состояние батареи из вывода
+// system_profiler SPPowerDataType. На Apple Silicon остальные параметры недоступны.
+func parseSystemProfilerOutput(out []byte) (cycle int, condition string, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "Cycle Count:"):
+			cycle, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Cycle Count:")))
+		case strings.HasPrefix(line, "Condition:"):
+			condition = strings.TrimSpace(strings.TrimPrefix(line, "Condition:"))
+		}
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return 0, "", fmt.Errorf(T("mac.err.system_profiler"), scanErr)
+	}
+	return cycle, condition, nil
+}
+
+// parseIORegistryOutput извлекает подробные параметры батареи из вывода ioreg -rn AppleSmartBattery
+func parseIORegistryOutput(out []byte) (BatteryDetails, error) {
+	var d BatteryDetails
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Параметры в формате "ParameterName" = Value
+		parts := strings.SplitN(line, " = ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.Trim(parts[0], `"`)
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "CycleCount":
+			d.CycleCount, _ = strconv.Atoi(value)
+		case "AppleRawMaxCapacity":
+			d.FullChargeCap, _ = strconv.Atoi(value)
+		case "DesignCapacity":
+			d.DesignCapacity, _ = strconv.Atoi(value)
+		case "AppleRawCurrentCapacity":
+			d.CurrentCapacity, _ = strconv.Atoi(value)
+		case "Temperature":
+			// Температура в сотых долях градуса
+			if temp, err := strconv.Atoi(value); err == nil {
+				d.Temperature = temp / 100
+			}
+		case "Serial", "BatterySerialNumber":
+			// Серийный номер батареи – по нему определяется её замена
+			if d.Serial == "" {
+				d.Serial = strings.Trim(value, `"`)
+			}
+		case "Voltage":
+			d.Voltage, _ = strconv.Atoi(value)
+		case "Amperage":
+			// Отрицательный ток ioreg печатает как uint64 в дополнительном коде
+			if amp, err := strconv.ParseUint(value, 10, 64); err == nil {
+				d.Amperage = int(int64(amp))
+			}
+		case "PermanentFailureStatus":
+			d.PermanentFailure, _ = strconv.Atoi(value)
+		case "ManufactureDate":
+			if packed, err := strconv.Atoi(value); err == nil {
+				d.Manufactured = smartBatteryDate(packed)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return BatteryDetails{}, fmt.Errorf(T("mac.err.ioreg_scan"), err)
+	}
+	return d, nil
+}
+
+// parseIORegistryPlist извлекает параметры батареи из вывода
+// ioreg -a -rn AppleSmartBattery. В отличие от текстового вывода здесь
+// доступны вложенные словари: напряжения ячеек и статус отказа лежат в
+// BatteryData.
+func parseIORegistryPlist(out []byte) (BatteryDetails, error) {
+	root, err := decodePlist(out)
+	if err != nil {
+		return BatteryDetails{}, fmt.Errorf(T("mac.err.ioreg_parse"), err)
+	}
+	battery, ok := root.(map[string]any)
+	if arr, isArr := root.([]any); isArr && len(arr) > 0 {
+		battery, ok = arr[0].(map[string]any)
+	}
+	if !ok {
+		return BatteryDetails{}, errors.New(T("mac.err.ioreg_missing"))
+	}
+	data, _ := battery["BatteryData"].(map[string]any)
+
+	var d BatteryDetails
+	d.CycleCount, _ = plistInt(battery, "CycleCount")
+	if d.FullChargeCap, ok = plistInt(battery, "AppleRawMaxCapacity"); !ok {
+		d.FullChargeCap, _ = plistInt(battery, "NominalChargeCapacity")
+	}
+	d.DesignCapacity, _ = plistInt(battery, "DesignCapacity")
+	d.CurrentCapacity, _ = plistInt(battery, "AppleRawCurrentCapacity")
+	if temp, ok := plistInt(battery, "Temperature"); ok {
+		d.Temperature = temp / 100 // в сотых долях градуса
+	}
+	d.Voltage, _ = plistInt(battery, "Voltage")
+	d.Amperage, _ = plistInt(battery, "Amperage") // дополнительный код учтен в decodePlist
+	if d.Serial = plistString(battery, "Serial"); d.Serial == "" {
+		d.Serial = plistString(battery, "BatterySerialNumber")
+	}
+	if d.CellVoltages = plistInts(data, "CellVoltage"); len(d.CellVoltages) == 0 {
+		d.CellVoltages = plistInts(battery, "CellVoltages")
+	}
+	if d.PermanentFailure, ok = plistInt(battery, "PermanentFailureStatus"); !ok {
+		d.PermanentFailure, _ = plistInt(data, "PermanentFailureStatus")
+	}
+	if packed, ok := plistInt(battery, "ManufactureDate"); ok {
+		d.Manufactured = smartBatteryDate(packed)
+	}
+	return d, nil
+}
+
+// smartBatteryDate разбирает дату изготовления в формате Smart Battery:
+// день – биты 0-4, месяц – 5-8, год от 1980 – 9-15. Значение вне этого
+// формата (на части Mac с Apple Silicon там другое число) дает нулевую дату.
+func smartBatteryDate(packed int) time.Time {
+	day, month, year := packed&0x1f, (packed>>5)&0x0f, 1980+(packed>>9)&0x7f
+	if packed <= 0 || packed > 0xffff || day < 1 || day > 31 || month < 1 || month > 12 || year < 2000 {
+		return time.Time{}
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+}
+
+// isPlistOutput сообщает, что вывод – XML plist (ioreg -a), а не текст
+func isPlistOutput(out []byte) bool {
+	trimmed := bytes.TrimSpace(out)
+	return bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<plist"))
+}
+
+// mergeMacOutputs объединяет вывод ioreg и system_profiler: состояние батареи
+// берётся из system_profiler, оттуда же число циклов, если его нет в ioreg.
+// Пустой sp допустим – тогда состояние остаётся неизвестным. Вывод ioreg
+// принимается и в plist, и в текстовом виде (старые записи replay).
+func mergeMacOutputs(ioreg, sp []byte) (BatteryDetails, error) {
+	parse := parseIORegistryOutput
+	if isPlistOutput(ioreg) {
+		parse = parseIORegistryPlist
+	}
+	d, err := parse(ioreg)
+	if err != nil {
+		return BatteryDetails{}, err
+	}
+	if len(sp) == 0 {
+		return d, nil
+	}
+	if spCycle, spCondition, spErr := parseSystemProfilerOutput(sp); spErr == nil {
+		d.Condition = spCondition
+		if d.CycleCount == 0 {
+			d.CycleCount = spCycle
+		}
+	}
+	return d, nil
+}
+
+var (
+	adapterDetailsRe = regexp.MustCompile(`(?m)^\s*"AdapterDetails" = (\{.*\})\s*$`)
+	externalConnRe   = regexp.MustCompile(`"ExternalConnected" = (Yes|No)`)
+	adapterFieldRe   = regexp.MustCompile(`"(\w+)"=("[^"]*"|\d+)`)
+	adapterNestedRe  = regexp.MustCompile(`\([^()]*\)`)
+)
+
+func (macSource) Adapter() (*AdapterInfo, error) {
+	out, err := runCommand("ioreg", "-rn", "AppleSmartBattery")
+	if err != nil {
+		return nil, fmt.Errorf("ioreg: %w", err)
+	}
+	return parseAdapterDetails(out), nil
+}
+
+// parseAdapterDetails извлекает сведения об адаптере из строки "AdapterDetails"
+// вывода ioreg -rn AppleSmartBattery. Без адаптера возвращает nil.
+func parseAdapterDetails(out []byte) *AdapterInfo {
+	if m := externalConnRe.FindSubmatch(out); m != nil && string(m[1]) == "No" {
+		return nil
+	}
+	m := adapterDetailsRe.FindSubmatch(out)
+	if m == nil {
+		return nil
+	}
+	// Вложенные списки (меню напряжений USB-PD) повторяют ключи вроде Current
+	details := adapterNestedRe.ReplaceAll(m[1], nil)
+	fields := make(map[string]string)
+	for _, f := range adapterFieldRe.FindAllSubmatch(details, -1) {
+		fields[string(f[1])] = strings.Trim(string(f[2]), `"`)
+	}
+	intField := func(name string) uint64 {
+		v, _ := strconv.ParseUint(fields[name], 10, 64)
+		return v
+	}
+	a := &AdapterInfo{
+		Watts:        int(intField("Watts")),
+		Voltage:      int(intField("AdapterVoltage")),
+		Current:      int(intField("Current")),
+		Name:         fields["Name"],
+		Manufacturer: fields["Manufacturer"],
+		Serial:       fields["SerialString"],
+	}
+	if a.Watts == 0 {
+		return nil // "AdapterDetails" = {"FamilyCode"=0} – адаптер не подключен
+	}
+	if family := intField("FamilyCode"); family != 0 {
+		a.Family = fmt.Sprintf("0x%x", uint32(family))
+	}
+	if a.Name == "" {
+		a.Name = fields["Description"]
+	}
+	a.Official = isOfficialAdapter(a.Manufacturer, a.Name)
+	return a
+}
+
+var (
+	clamshellStateRe  = regexp.MustCompile(`"AppleClamshellState"\s*=\s*(Yes|No)`)
+	brightnessToolRe  = regexp.MustCompile(`display 0: brightness ([\d.]+)`)
+	displayParamsRe   = regexp.MustCompile(`"brightness"=\{([^}]*)\}`)
+	displayParamMaxRe = regexp.MustCompile(`"max"=(\d+)`)
+	displayParamValRe = regexp.MustCompile(`"value"=(\d+)`)
+)
+
+// DisplayContext читает положение крышки (ioreg) и яркость встроенного экрана:
+// сначала утилитой brightness, затем из IODisplayParameters. Отсутствие
+// данных не ошибка – поля остаются пустыми.
+func (macSource) DisplayContext() (DisplayContext, error) {
+	var ctx DisplayContext
+	if out, err := runCommand("ioreg", "-r", "-k", "AppleClamshellState", "-d", "1"); err == nil {
+		ctx.LidState = parseClamshellState(out)
+	}
+	if out, err := runCommand("brightness", "-l"); err == nil {
+		ctx.Brightness = parseBrightnessToolOutput(out)
+	}
+	if ctx.Brightness == 0 {
+		if out, err := runCommand("ioreg", "-r", "-k", "IODisplayParameters", "-d", "1"); err == nil {
+			ctx.Brightness = parseIODisplayBrightness(out)
+		}
+	}
+	return ctx, nil
+}
+
+// parseClamshellState разбирает "AppleClamshellState" = Yes/No из вывода ioreg
+func parseClamshellState(out []byte) string {
+	m := clamshellStateRe.FindSubmatch(out)
+	if m == nil {
+		return ""
+	}
+	if string(m[1]) == "Yes" {
+		return lidClosed
+	}
+	return lidOpen
+}
+
+// parseBrightnessToolOutput разбирает "display 0: brightness 0.750000" из brightness -l
+func parseBrightnessToolOutput(out []byte) int {
+	m := brightnessToolRe.FindSubmatch(out)
+	if m == nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(string(m[1]), 64)
+	if err != nil {
+		return 0
+	}
+	return int(v*100 + 0.5)
+}
+
+// parseIODisplayBrightness переводит "brightness"={"min"=0,"max"=65536,"value"=49152}
+// из IODisplayParameters в проценты
+func parseIODisplayBrightness(out []byte) int {
+	block := displayParamsRe.FindSubmatch(out)
+	if block == nil {
+		return 0
+	}
+	maxM := displayParamMaxRe.FindSubmatch(block[1])
+	valM := displayParamValRe.FindSubmatch(block[1])
+	if maxM == nil || valM == nil {
+		return 0
+	}
+	maxV, _ := strconv.Atoi(string(maxM[1]))
+	val, _ := strconv.Atoi(string(valM[1]))
+	if maxV <= 0 {
+		return 0
+	}
+	return (val*100 + maxV/2) / maxV
+}
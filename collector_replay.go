@@ -0,0 +1,108 @@
+// collector_replay.go
+//
+// Источник данных, воспроизводящий записанный вывод pmset/ioreg/system_profiler
+// из JSON-фикстуры (формат testdata/recordings). Нужен для тестов конвейера
+// сбора и для запуска TUI на машинах без батареи.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// batteryFixture – запись вывода системных утилит с реального MacBook.
+// Каждый сэмпл соответствует одному циклу сбора данных.
+type batteryFixture struct {
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Interval       string                 `json:"interval"`
+	SystemProfiler string                 `json:"system_profiler"`
+	Samples        []batteryFixtureSample `json:"samples"`
+}
+
+// batteryFixtureSample – вывод pmset и ioreg в одном цикле сбора
+type batteryFixtureSample struct {
+	PMSet string `json:"pmset"`
+	IOReg string `json:"ioreg"`
+}
+
+// loadBatteryFixture читает фикстуру из JSON-файла
+func loadBatteryFixture(path string) (batteryFixture, error) {
+	var fx batteryFixture
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fx, fmt.Errorf(T("replay.err.read"), err)
+	}
+	if err := json.Unmarshal(raw, &fx); err != nil {
+		return fx, fmt.Errorf(T("replay.err.parse"), path, err)
+	}
+	if len(fx.Samples) == 0 {
+		return fx, fmt.Errorf(T("replay.err.empty"), path)
+	}
+	return fx, nil
+}
+
+// replaySource воспроизводит фикстуру по кругу: каждый вызов Status
+// переходит к следующему сэмплу, Details отдаёт данные текущего.
+type replaySource struct {
+	mu      sync.Mutex
+	fixture batteryFixture
+	current int
+}
+
+func newReplaySource(fixture batteryFixture) *replaySource {
+	return &replaySource{fixture: fixture, current: -1}
+}
+
+func (r *replaySource) Name() string {
+	if r.fixture.Name != "" {
+		return "replay (" + r.fixture.Name + ")"
+	}
+	return "replay"
+}
+
+func (r *replaySource) Status() (int, string, error) {
+	r.mu.Lock()
+	r.current = (r.current + 1) % len(r.fixture.Samples)
+	sample := r.fixture.Samples[r.current]
+	r.mu.Unlock()
+	return parsePMSetOutput([]byte(sample.PMSet))
+}
+
+func (r *replaySource) Remaining() (time.Duration, bool, error) {
+	r.mu.Lock()
+	idx := r.current
+	if idx < 0 {
+		idx = 0
+	}
+	sample := r.fixture.Samples[idx]
+	r.mu.Unlock()
+	estimate, ok := parsePMSetRemaining([]byte(sample.PMSet))
+	return estimate, ok, nil
+}
+
+func (r *replaySource) Details() (BatteryDetails, error) {
+	r.mu.Lock()
+	idx := r.current
+	if idx < 0 {
+		idx = 0
+	}
+	sample := r.fixture.Samples[idx]
+	r.mu.Unlock()
+	return mergeMacOutputs([]byte(sample.IOReg), []byte(r.fixture.SystemProfiler))
+}
+
+func (r *replaySource) Adapter() (*AdapterInfo, error) {
+	r.mu.Lock()
+	idx := r.current
+	if idx < 0 {
+		idx = 0
+	}
+	sample := r.fixture.Samples[idx]
+	r.mu.Unlock()
+	return parseAdapterDetails([]byte(sample.IOReg)), nil
+}
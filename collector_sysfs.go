@@ -0,0 +1,205 @@
+// collector_sysfs.go
+//
+// Сбор данных о батарее на Linux через /sys/class/power_supply/BAT*/.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const sysfsPowerSupplyRoot = "/sys/class/power_supply"
+
+// Подсветка экрана и крышка ноутбука: нужны только для контекста измерений
+const (
+	sysfsBacklightRoot = "/sys/class/backlight"
+	procLidRoot        = "/proc/acpi/button/lid"
+)
+
+// sysfsSource читает параметры батареи из sysfs.
+// Ядро отдаёт значения в микро-единицах (мкАч, мкВт·ч, мкВ, мкА).
+type sysfsSource struct {
+	root string
+}
+
+func newSysfsSource(root string) *sysfsSource {
+	return &sysfsSource{root: root}
+}
+
+func (c *sysfsSource) Name() string { return "sysfs" }
+
+// batteryDir находит первую батарею (BAT0, BAT1, ...)
+func (c *sysfsSource) batteryDir() (string, error) {
+	matches, err := filepath.Glob(filepath.Join(c.root, "BAT*"))
+	if err != nil {
+		return "", fmt.Errorf(T("sysfs.err.search"), err)
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf(T("sysfs.err.not_found"), c.root)
+	}
+	sort.Strings(matches)
+	return matches[0], nil
+}
+
+// readString читает текстовый атрибут sysfs
+func (c *sysfsSource) readString(dir, name string) (string, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// readInt читает числовой атрибут sysfs; отсутствующий атрибут даёт ok=false
+func (c *sysfsSource) readInt(dir, name string) (int, bool) {
+	s, err := c.readString(dir, name)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// normalizeSysfsState приводит статус ядра к состояниям pmset
+func normalizeSysfsState(status string) string {
+	switch strings.ToLower(status) {
+	case "charging":
+		return "charging"
+	case "discharging":
+		return "discharging"
+	case "full":
+		return "charged"
+	case "not charging":
+		return "ac" // как "AC attached; not charging" у pmset
+	default:
+		return strings.ToLower(status)
+	}
+}
+
+func (c *sysfsSource) Status() (int, string, error) {
+	dir, err := c.batteryDir()
+	if err != nil {
+		return 0, "", err
+	}
+	pct, ok := c.readInt(dir, "capacity")
+	if !ok {
+		return 0, "", fmt.Errorf(T("sysfs.err.capacity"), dir)
+	}
+	status, err := c.readString(dir, "status")
+	if err != nil {
+		return 0, "", fmt.Errorf("sysfs: %w", err)
+	}
+	return pct, normalizeSysfsState(status), nil
+}
+
+func (c *sysfsSource) Details() (BatteryDetails, error) {
+	dir, err := c.batteryDir()
+	if err != nil {
+		return BatteryDetails{}, err
+	}
+
+	var d BatteryDetails
+	microVolts, _ := c.readInt(dir, "voltage_now")
+	d.Voltage = microVolts / 1000
+
+	if cycles, ok := c.readInt(dir, "cycle_count"); ok {
+		d.CycleCount = cycles
+	}
+
+	// Ёмкость: charge_* в мкАч, либо energy_* в мкВт·ч (пересчитываем через напряжение)
+	if now, ok := c.readInt(dir, "charge_now"); ok {
+		full, _ := c.readInt(dir, "charge_full")
+		design, _ := c.readInt(dir, "charge_full_design")
+		d.CurrentCapacity, d.FullChargeCap, d.DesignCapacity = now/1000, full/1000, design/1000
+	} else if now, ok := c.readInt(dir, "energy_now"); ok {
+		full, _ := c.readInt(dir, "energy_full")
+		design, _ := c.readInt(dir, "energy_full_design")
+		refVoltage, ok := c.readInt(dir, "voltage_min_design")
+		if !ok || refVoltage == 0 {
+			refVoltage = microVolts
+		}
+		if refVoltage == 0 {
+			return BatteryDetails{}, errors.New(T("sysfs.err.voltage"))
+		}
+		toMAh := func(microWh int) int {
+			return int(int64(microWh) * 1000 / int64(refVoltage))
+		}
+		d.CurrentCapacity, d.FullChargeCap, d.DesignCapacity = toMAh(now), toMAh(full), toMAh(design)
+	} else {
+		return BatteryDetails{}, fmt.Errorf(T("sysfs.err.charge"), dir)
+	}
+
+	// Ток: current_now в мкА или power_now в мкВт. Знак ядро не гарантирует,
+	// поэтому выставляем его по состоянию (+ заряд, - разряд), как у ioreg.
+	if microAmps, ok := c.readInt(dir, "current_now"); ok {
+		d.Amperage = abs(microAmps) / 1000
+	} else if microWatts, ok := c.readInt(dir, "power_now"); ok && microVolts > 0 {
+		d.Amperage = int(int64(abs(microWatts)) * 1000 / int64(microVolts))
+	}
+	if status, err := c.readString(dir, "status"); err == nil && normalizeSysfsState(status) == "discharging" {
+		d.Amperage = -d.Amperage
+	}
+
+	// Температура в десятых долях градуса (есть не у всех контроллеров)
+	if temp, ok := c.readInt(dir, "temp"); ok {
+		d.Temperature = temp / 10
+	}
+
+	d.Serial, _ = c.readString(dir, "serial_number")
+	if year, ok := c.readInt(dir, "manufacture_year"); ok {
+		month, _ := c.readInt(dir, "manufacture_month")
+		day, _ := c.readInt(dir, "manufacture_day")
+		d.Manufactured = time.Date(year, time.Month(max(month, 1)), max(day, 1), 0, 0, 0, 0, time.Local)
+	}
+	if health, err := c.readString(dir, "health"); err == nil {
+		d.Condition = health
+	}
+
+	return d, nil
+}
+
+// DisplayContext читает яркость подсветки из /sys/class/backlight и положение
+// крышки из /proc/acpi/button/lid. Отсутствие данных не ошибка.
+func (c *sysfsSource) DisplayContext() (DisplayContext, error) {
+	var ctx DisplayContext
+	backlights, _ := filepath.Glob(filepath.Join(sysfsBacklightRoot, "*"))
+	sort.Strings(backlights)
+	for _, dir := range backlights {
+		maxV, ok := c.readInt(dir, "max_brightness")
+		if !ok || maxV <= 0 {
+			continue
+		}
+		if v, ok := c.readInt(dir, "brightness"); ok {
+			ctx.Brightness = (v*100 + maxV/2) / maxV
+			break
+		}
+	}
+	lids, _ := filepath.Glob(filepath.Join(procLidRoot, "*", "state"))
+	if len(lids) > 0 {
+		if raw, err := os.ReadFile(lids[0]); err == nil {
+			ctx.LidState = parseLidState(string(raw))
+		}
+	}
+	return ctx, nil
+}
+
+// parseLidState разбирает "state:      open" из /proc/acpi/button/lid/*/state
+func parseLidState(s string) string {
+	switch {
+	case strings.Contains(s, "closed"):
+		return lidClosed
+	case strings.Contains(s, "open"):
+		return lidOpen
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+// collector_wmi.go
+//
+// Сбор данных о батарее на Windows через WMI (Win32_Battery и классы
+// root/wmi: BatteryStatus, BatteryFullChargedCapacity, BatteryStaticData,
+// BatteryCycleCount). Запросы выполняются через PowerShell.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// wmiStatusScript – быстрый запрос процента заряда и состояния
+const wmiStatusScript = `$b = Get-CimInstance -ClassName Win32_Battery | Select-Object -First 1
+[pscustomobject]@{ Charge = $b.EstimatedChargeRemaining; BatteryStatus = $b.BatteryStatus } | ConvertTo-Json -Compress`
+
+// wmiDetailsScript – подробный запрос. Классы root/wmi есть не у всех драйверов,
+// поэтому ошибки их чтения подавляются, а отсутствующие поля приходят как null.
+const wmiDetailsScript = `$ErrorActionPreference = 'SilentlyContinue'
+$s = Get-CimInstance -Namespace root/wmi -ClassName BatteryStatus | Select-Object -First 1
+$f = Get-CimInstance -Namespace root/wmi -ClassName BatteryFullChargedCapacity | Select-Object -First 1
+$d = Get-CimInstance -Namespace root/wmi -ClassName BatteryStaticData | Select-Object -First 1
+$c = Get-CimInstance -Namespace root/wmi -ClassName BatteryCycleCount | Select-Object -First 1
+$t = Get-CimInstance -Namespace root/wmi -ClassName BatteryTemperature | Select-Object -First 1
+[pscustomobject]@{
+  RemainingCapacity = $s.RemainingCapacity; Voltage = $s.Voltage
+  ChargeRate = $s.ChargeRate; DischargeRate = $s.DischargeRate
+  Charging = $s.Charging; Discharging = $s.Discharging
+  FullChargedCapacity = $f.FullChargedCapacity; DesignedCapacity = $d.DesignedCapacity
+  SerialNumber = $d.SerialNumber; CycleCount = $c.CycleCount; Temperature = $t.Temperature
+} | ConvertTo-Json -Compress`
+
+// wmiStatus – ответ wmiStatusScript
+type wmiStatus struct {
+	Charge        *int `json:"Charge"`
+	BatteryStatus int  `json:"BatteryStatus"`
+}
+
+// wmiDetails – ответ wmiDetailsScript. Ёмкости в мВт·ч, напряжение в мВ,
+// скорости в мВт, температура в десятых долях кельвина.
+type wmiDetails struct {
+	RemainingCapacity   int    `json:"RemainingCapacity"`
+	Voltage             int    `json:"Voltage"`
+	ChargeRate          int    `json:"ChargeRate"`
+	DischargeRate       int    `json:"DischargeRate"`
+	Charging            bool   `json:"Charging"`
+	Discharging         bool   `json:"Discharging"`
+	FullChargedCapacity int    `json:"FullChargedCapacity"`
+	DesignedCapacity    int    `json:"DesignedCapacity"`
+	SerialNumber        string `json:"SerialNumber"`
+	CycleCount          int    `json:"CycleCount"`
+	Temperature         int    `json:"Temperature"`
+}
+
+// wmiSource читает данные о батарее через WMI
+type wmiSource struct{}
+
+func (wmiSource) Name() string { return "WMI" }
+
+// runPowerShell выполняет скрипт и разбирает JSON-ответ
+func runPowerShell(script string, v interface{}) error {
+	out, err := runCommand("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	if err != nil {
+		return fmt.Errorf("powershell: %w", err)
+	}
+	out = []byte(strings.TrimSpace(string(out)))
+	if len(out) == 0 {
+		return errors.New(T("wmi.err.empty"))
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf(T("wmi.err.parse"), err)
+	}
+	return nil
+}
+
+// normalizeWMIState переводит код Win32_Battery.BatteryStatus в состояния pmset
+func normalizeWMIState(code int) string {
+	switch code {
+	case 1, 4, 5: // разряжается (в т.ч. низкий и критический заряд)
+		return "discharging"
+	case 3: // полностью заряжена
+		return "charged"
+	case 6, 7, 8, 9: // заряжается
+		return "charging"
+	case 2: // подключено питание, не заряжается
+		return "ac"
+	default:
+		return "unknown"
+	}
+}
+
+func (wmiSource) Status() (int, string, error) {
+	var st wmiStatus
+	if err := runPowerShell(wmiStatusScript, &st); err != nil {
+		return 0, "", err
+	}
+	if st.Charge == nil {
+		return 0, "", errors.New(T("wmi.err.no_charge"))
+	}
+	return *st.Charge, normalizeWMIState(st.BatteryStatus), nil
+}
+
+func (wmiSource) Details() (BatteryDetails, error) {
+	var w wmiDetails
+	if err := runPowerShell(wmiDetailsScript, &w); err != nil {
+		return BatteryDetails{}, err
+	}
+	if w.Voltage <= 0 {
+		return BatteryDetails{}, errors.New(T("wmi.err.no_status"))
+	}
+
+	// WMI отдаёт энергию (мВт·ч), а batmon хранит заряд (мАч): пересчитываем через напряжение
+	toMAh := func(mWh int) int {
+		return int(int64(mWh) * 1000 / int64(w.Voltage))
+	}
+
+	d := BatteryDetails{
+		CycleCount:      w.CycleCount,
+		FullChargeCap:   toMAh(w.FullChargedCapacity),
+		DesignCapacity:  toMAh(w.DesignedCapacity),
+		CurrentCapacity: toMAh(w.RemainingCapacity),
+		Voltage:         w.Voltage,
+		Serial:          strings.TrimSpace(w.SerialNumber),
+	}
+	switch {
+	case w.Discharging && w.DischargeRate > 0:
+		d.Amperage = -toMAh(w.DischargeRate)
+	case w.Charging && w.ChargeRate > 0:
+		d.Amperage = toMAh(w.ChargeRate)
+	}
+	if w.Temperature > 0 {
+		d.Temperature = (w.Temperature - 2731) / 10 // десятые доли кельвина → °C
+	}
+	return d, nil
+}
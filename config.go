@@ -0,0 +1,209 @@
+// config.go
+//
+// Пользовательские настройки batmon (config.json в папке данных) и
+// разрешения сетевых функций. По умолчанию batmon полностью офлайн.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Config – настройки, читаемые из config.json
+type Config struct {
+	Language       string                `json:"language,omitempty"` // язык интерфейса: ru или en; пусто – по LANG
+	Theme          ThemeConfig           `json:"theme"`              // цветовая тема интерфейса
+	Network        NetworkConfig         `json:"network"`
+	Dashboard      DashboardConfig       `json:"dashboard"`
+	Collector      CollectorConfig       `json:"collector"` // интервал опроса, срок хранения, caffeinate
+	Export         ExportConfig          `json:"export"`    // папка и шаблон имени отчетов
+	Power          PowerConfig           `json:"power"`
+	Storage        StorageConfig         `json:"storage"`
+	Health         HealthThresholds      `json:"health"`             // пороги batmon check
+	ChargeLimit    ChargeLimitConfig     `json:"charge_limit"`       // советник по ограничению заряда
+	Calibration    CalibrationConfig     `json:"calibration"`        // напоминание о полной разрядке
+	Hooks          HooksConfig           `json:"hooks"`              // пользовательские скрипты на события
+	Webhooks       []WebhookConfig       `json:"webhooks,omitempty"` // вебхуки для оповещений (нужно network.webhooks)
+	ReportSchedule ReportScheduleConfig  `json:"report_schedule"`    // отчет по почте (нужно network.email)
+	Metrics        []DerivedMetricConfig `json:"metrics,omitempty"`  // производные метрики
+	Rules          []RecommendationRule  `json:"rules,omitempty"`    // правила рекомендаций поверх встроенных
+	Log            LogConfig             `json:"log"`                // уровень и ротация batmon.log
+}
+
+// PowerConfig – поведение batmon при низком заряде
+type PowerConfig struct {
+	LowBatteryThreshold int  `json:"low_battery_threshold"` // ниже этого заряда (%) реже пишем в БД; 0 – не ограничивать
+	Powermetrics        bool `json:"powermetrics"`          // подробный режим: мощность CPU/GPU/ANE через powermetrics
+	WriteBatchSize      int  `json:"write_batch_size"`      // измерений в пакете записи в БД; 1 – писать каждое сразу
+}
+
+// DashboardConfig – настройки интерактивного дашборда
+type DashboardConfig struct {
+	ChartWindow string `json:"chart_window"` // окно графиков: 30m, 2h, 6h или 24h
+}
+
+// NetworkConfig – явные разрешения для каждой сетевой подсистемы.
+// Любая функция, выходящая в сеть, обязана проверить своё разрешение.
+type NetworkConfig struct {
+	Upload      bool `json:"upload"`       // выгрузка отчетов
+	MQTT        bool `json:"mqtt"`         // публикация в MQTT-брокер
+	Webhooks    bool `json:"webhooks"`     // уведомления через вебхуки
+	UpdateCheck bool `json:"update_check"` // проверка новых версий
+	Email       bool `json:"email"`        // отчеты по почте
+}
+
+// NetworkFeature – идентификатор сетевой подсистемы (совпадает с ключом в config.json)
+type NetworkFeature string
+
+const (
+	NetworkUpload      NetworkFeature = "upload"
+	NetworkMQTT        NetworkFeature = "mqtt"
+	NetworkWebhooks    NetworkFeature = "webhooks"
+	NetworkUpdateCheck NetworkFeature = "update_check"
+	NetworkEmail       NetworkFeature = "email"
+)
+
+// networkFeatureNames – ключи перевода названий для строки статуса
+var networkFeatureNames = map[NetworkFeature]string{
+	NetworkUpload:      "network.feature.upload",
+	NetworkMQTT:        "network.feature.mqtt",
+	NetworkWebhooks:    "network.feature.webhooks",
+	NetworkUpdateCheck: "network.feature.update_check",
+	NetworkEmail:       "network.feature.email",
+}
+
+// ErrNetworkDisabled возвращается, когда сетевая функция не разрешена в конфиге
+var ErrNetworkDisabled error = messageError("network.err.off")
+
+var (
+	configMu      sync.RWMutex
+	currentConfig = defaultConfig()
+)
+
+// defaultConfig возвращает настройки по умолчанию: все сетевые функции выключены
+func defaultConfig() Config {
+	return Config{
+		Dashboard:   DashboardConfig{ChartWindow: chartWindowConfigValue(defaultChartWindow)},
+		Collector:   defaultCollectorConfig(),
+		Power:       PowerConfig{LowBatteryThreshold: defaultLowBatteryThreshold, WriteBatchSize: defaultWriteBatchSize},
+		Health:      defaultHealthThresholds(),
+		ChargeLimit: defaultChargeLimitConfig(),
+		Calibration: defaultCalibrationConfig(),
+	}
+}
+
+// getConfigPath возвращает путь к config.json
+func getConfigPath() string {
+	dataDir, err := getDataDir()
+	if err != nil {
+		return "config.json"
+	}
+	return filepath.Join(dataDir, "config.json")
+}
+
+// loadConfig читает конфиг из файла. Отсутствующий файл – не ошибка,
+// в этом случае используются настройки по умолчанию.
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf(T("config.err.read"), err)
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return defaultConfig(), fmt.Errorf(T("err.parse"), path, err)
+	}
+	return cfg, nil
+}
+
+// saveConfig записывает конфиг в файл
+func saveConfig(path string, cfg Config) error {
+	raw, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf(T("config.err.marshal"), err)
+	}
+	if err := os.WriteFile(path, append(raw, '\n'), 0644); err != nil {
+		return fmt.Errorf(T("config.err.write"), err)
+	}
+	return nil
+}
+
+// initConfig загружает config.json в текущие настройки
+func initConfig() error {
+	cfg, err := loadConfig(getConfigPath())
+	setConfig(cfg)
+	return err
+}
+
+// getConfig возвращает копию текущих настроек
+func getConfig() Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return currentConfig
+}
+
+// setConfig заменяет текущие настройки
+func setConfig(cfg Config) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	currentConfig = cfg
+}
+
+// Allowed сообщает, разрешена ли сетевая функция
+func (n NetworkConfig) Allowed(feature NetworkFeature) bool {
+	switch feature {
+	case NetworkUpload:
+		return n.Upload
+	case NetworkMQTT:
+		return n.MQTT
+	case NetworkWebhooks:
+		return n.Webhooks
+	case NetworkUpdateCheck:
+		return n.UpdateCheck
+	case NetworkEmail:
+		return n.Email
+	}
+	return false
+}
+
+// Enabled возвращает список разрешённых сетевых функций
+func (n NetworkConfig) Enabled() []NetworkFeature {
+	var enabled []NetworkFeature
+	for _, f := range []NetworkFeature{NetworkUpload, NetworkMQTT, NetworkWebhooks, NetworkUpdateCheck, NetworkEmail} {
+		if n.Allowed(f) {
+			enabled = append(enabled, f)
+		}
+	}
+	return enabled
+}
+
+// StatusLine возвращает строку статуса сети для экрана настроек
+func (n NetworkConfig) StatusLine() string {
+	enabled := n.Enabled()
+	if len(enabled) == 0 {
+		return T("network.status.offline")
+	}
+	names := make([]string, len(enabled))
+	for i, f := range enabled {
+		names[i] = T(networkFeatureNames[f])
+	}
+	return T("network.status.allowed", strings.Join(names, ", "))
+}
+
+// requireNetwork проверяет разрешение перед любым сетевым обращением.
+// Сетевые подсистемы обязаны вызывать её до открытия соединения.
+func requireNetwork(feature NetworkFeature) error {
+	if getConfig().Network.Allowed(feature) {
+		return nil
+	}
+	return fmt.Errorf(T("network.err.disabled"),
+		ErrNetworkDisabled, T(networkFeatureNames[feature]), feature, getConfigPath())
+}
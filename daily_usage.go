@@ -0,0 +1,154 @@
+// daily_usage.go
+//
+// Таблица daily_usage: суточные сводки (время от батареи, на зарядке,
+// с включенным экраном, расход заряда). Хранятся отдельно от измерений,
+// чтобы вопрос «сколько часов я работал от батареи на этой неделе» не
+// требовал перебора 30-секундных измерений и переживал их очистку.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// dailyUsageDays – дней в разделе «Использование по дням» без явного периода
+const dailyUsageDays = 7
+
+// dailyUsageSchema – суточные сводки в местном часовом поясе
+const dailyUsageSchema = `
+CREATE TABLE IF NOT EXISTS daily_usage (
+	day TEXT PRIMARY KEY,
+	battery_seconds INTEGER NOT NULL DEFAULT 0,
+	ac_seconds INTEGER NOT NULL DEFAULT 0,
+	charge_seconds INTEGER NOT NULL DEFAULT 0,
+	screen_seconds INTEGER NOT NULL DEFAULT 0,
+	drain REAL NOT NULL DEFAULT 0,
+	sessions INTEGER NOT NULL DEFAULT 0
+);`
+
+// dailyUsageRow – строка таблицы daily_usage
+type dailyUsageRow struct {
+	Day            string  `db:"day"` // 2006-01-02
+	BatterySeconds int     `db:"battery_seconds"`
+	ACSeconds      int     `db:"ac_seconds"`
+	ChargeSeconds  int     `db:"charge_seconds"`
+	ScreenSeconds  int     `db:"screen_seconds"`
+	Drain          float64 `db:"drain"`
+	Sessions       int     `db:"sessions"`
+}
+
+// syncDailyUsage пересчитывает сводки начиная с последнего сохраненного дня:
+// он мог быть еще не закончен. Измерения берутся с запасом sessionMaxGap,
+// чтобы интервал через полночь попал в новый день.
+func syncDailyUsage(db *sqlx.DB) error {
+	var lastDay string
+	if err := db.Get(&lastDay, `SELECT COALESCE(MAX(day), '') FROM daily_usage`); err != nil {
+		return fmt.Errorf(T("daily.err.read"), err)
+	}
+	var from time.Time
+	if lastDay != "" {
+		day, err := time.ParseInLocation("2006-01-02", lastDay, time.Local)
+		if err != nil {
+			return fmt.Errorf(T("daily.err.day"), lastDay, err)
+		}
+		from = day.Add(-sessionMaxGap)
+	}
+	ms, err := getMeasurementsSince(db, from)
+	if err != nil {
+		return fmt.Errorf(T("daily.err.measurements"), err)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf(T("daily.err.transaction"), err)
+	}
+	defer tx.Rollback()
+	for _, d := range summarizeByDay(detectSessions(ms), time.Local) {
+		day := d.Date.Format("2006-01-02")
+		if day < lastDay {
+			continue // хвост предыдущего дня уже учтен
+		}
+		_, err := tx.Exec(`INSERT OR REPLACE INTO daily_usage
+			(day, battery_seconds, ac_seconds, charge_seconds, screen_seconds, drain, sessions)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			day, int(d.BatteryTime.Seconds()), int(d.ACTime.Seconds()), int(d.ChargeTime.Seconds()),
+			int(d.ScreenTime.Seconds()), d.Drain, d.Sessions)
+		if err != nil {
+			return fmt.Errorf(T("daily.err.save"), day, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// getDailyUsage возвращает сохраненные сводки за последние days дней, включая сегодня
+func getDailyUsage(db *sqlx.DB, days int, now time.Time) ([]DailySummary, error) {
+	from := startOfDay(now, time.Local).AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	var rows []dailyUsageRow
+	if err := db.Select(&rows, `SELECT * FROM daily_usage WHERE day >= ? ORDER BY day`, from); err != nil {
+		return nil, fmt.Errorf(T("daily.err.read"), err)
+	}
+	result := make([]DailySummary, 0, len(rows))
+	for _, r := range rows {
+		day, err := time.ParseInLocation("2006-01-02", r.Day, time.Local)
+		if err != nil {
+			continue
+		}
+		result = append(result, DailySummary{
+			Date:        day,
+			BatteryTime: time.Duration(r.BatterySeconds) * time.Second,
+			ACTime:      time.Duration(r.ACSeconds) * time.Second,
+			ChargeTime:  time.Duration(r.ChargeSeconds) * time.Second,
+			ScreenTime:  time.Duration(r.ScreenSeconds) * time.Second,
+			Drain:       r.Drain,
+			Sessions:    r.Sessions,
+		})
+	}
+	return result, nil
+}
+
+// DailyTotals – итог по дням сводки
+type DailyTotals struct {
+	BatteryTime time.Duration
+	ChargeTime  time.Duration
+	ScreenTime  time.Duration
+	FullCycles  float64
+}
+
+// dailyTotals суммирует сводки по дням
+func dailyTotals(days []DailySummary) DailyTotals {
+	var t DailyTotals
+	for _, d := range days {
+		t.BatteryTime += d.BatteryTime
+		t.ChargeTime += d.ChargeTime
+		t.ScreenTime += d.ScreenTime
+		t.FullCycles += d.FullCycles()
+	}
+	return t
+}
+
+// dailyBar рисует текстовую полосу времени от батареи: один блок – час
+func dailyBar(d DailySummary) string {
+	return strings.Repeat("█", int(d.BatteryTime.Hours()+0.5))
+}
+
+// BatteryHours возвращает время от батареи в часах (для графиков)
+func (d DailySummary) BatteryHours() float64 {
+	return d.BatteryTime.Hours()
+}
+
+// ChargeHours возвращает время на зарядке в часах (для графиков)
+func (d DailySummary) ChargeHours() float64 {
+	return d.ChargeTime.Hours()
+}
+
+// screenLabel возвращает время с включенным экраном или прочерк, если яркость не записывалась
+func screenLabel(d time.Duration) string {
+	if d <= 0 {
+		return "—"
+	}
+	return formatDuration(d)
+}
@@ -0,0 +1,198 @@
+// dashboard_charts.go
+//
+// Правый график дашборда: ёмкость, температура или мощность, переключаются
+// клавишей t. График температуры раскрашен по порогам предупреждения и
+// тревоги для текущего состояния питания (см. thermalThresholds). Здесь же
+// крупное табло мгновенной мощности: при поиске причин разряда ватты во
+// времени нагляднее процентов. В режиме перекрестья (клавиша x) стрелки
+// двигают курсор по графикам, а строка под ними показывает точные значения.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Графики правой половины дашборда в порядке переключения
+const (
+	dashboardChartCapacity = iota
+	dashboardChartTemperature
+	dashboardChartPower
+	dashboardChartCount
+)
+
+// nextDashboardChart возвращает следующий правый график по кругу
+func nextDashboardChart(current int) int {
+	return (current + 1) % dashboardChartCount
+}
+
+// renderSecondaryChart рендерит выбранный правый график дашборда
+func (a *App) renderSecondaryChart(source []Measurement, width, height int, windowLabel string) string {
+	var chart *Chart
+	var times []time.Time
+	var data []float64
+	add := func(m Measurement, v float64) {
+		times = append(times, parseStoredTime(m.Timestamp))
+		data = append(data, v)
+	}
+	emptyTitle := T("dashboard.chart.capacity")
+
+	switch a.dashboard.secondaryChart {
+	case dashboardChartTemperature:
+		warn, alarm := thermalThresholds(*a.latest)
+		chart = NewTemperatureChart(width, height, warn, alarm)
+		emptyTitle = T("dashboard.chart.temperature")
+		for _, m := range source {
+			if m.Temperature > 0 { // 0 – датчик не ответил
+				add(m, float64(m.Temperature))
+			}
+		}
+	case dashboardChartPower:
+		chart = NewPowerChart(width, height)
+		emptyTitle = T("dashboard.chart.power")
+		for _, m := range source {
+			if w, ok := measurementWatts(m); ok {
+				add(m, w)
+			}
+		}
+	default:
+		chart = NewCapacityChart(width, height)
+		for _, m := range source {
+			add(m, float64(m.CurrentCapacity))
+		}
+	}
+
+	if len(data) == 0 {
+		return lipgloss.NewStyle().
+			Width(width).
+			Height(height).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(theme.Border).
+			Align(lipgloss.Center, lipgloss.Center).
+			Render(emptyTitle + "\n\n" + T("tui.charts.no_data"))
+	}
+	chart.Title += windowLabel
+	chart.From, chart.To = a.dashboard.chartView.Range(a.dataService.Now())
+	chart.SetSeries(times, data)
+	chart.Cursor = a.chartCursorColumn(chart)
+	return chart.Render()
+}
+
+// measurementWatts возвращает мощность батареи в ваттах по модулю: по
+// напряжению и току, а если их нет – по сохраненной мощности
+func measurementWatts(m Measurement) (float64, bool) {
+	if m.Voltage > 0 && m.Amperage != 0 {
+		return math.Abs(float64(m.Voltage)*float64(m.Amperage)) / 1e6, true
+	}
+	return math.Abs(float64(m.Power)) / 1000, m.Power != 0
+}
+
+// bigDigits – цифры табло мощности (три строки на символ)
+var bigDigits = map[rune][3]string{
+	'0': {" _ ", "| |", "|_|"},
+	'1': {"   ", "  |", "  |"},
+	'2': {" _ ", " _|", "|_ "},
+	'3': {" _ ", " _|", " _|"},
+	'4': {"   ", "|_|", "  |"},
+	'5': {" _ ", "|_ ", " _|"},
+	'6': {" _ ", "|_ ", "|_|"},
+	'7': {" _ ", "  |", "  |"},
+	'8': {" _ ", "|_|", "|_|"},
+	'9': {" _ ", "|_|", " _|"},
+	'.': {"  ", "  ", " ."},
+}
+
+// renderBigNumber рисует число крупными цифрами
+func renderBigNumber(s string) string {
+	var rows [3]strings.Builder
+	for _, r := range s {
+		glyph, ok := bigDigits[r]
+		if !ok {
+			continue
+		}
+		for i := range rows {
+			rows[i].WriteString(glyph[i])
+		}
+	}
+	return rows[0].String() + "\n" + rows[1].String() + "\n" + rows[2].String()
+}
+
+// renderWattReadout рендерит табло мгновенной мощности с направлением тока
+// и средним за окно графиков
+func (a *App) renderWattReadout(source []Measurement, width int) string {
+	watts, ok := measurementWatts(*a.latest)
+	if !ok {
+		return ""
+	}
+	direction := T("dashboard.watts.discharge")
+	color := theme.Caution
+	if a.latest.Amperage > 0 || (a.latest.Amperage == 0 && a.latest.State == "charging") {
+		direction = T("dashboard.watts.charge")
+		color = theme.Good
+	}
+
+	var sum float64
+	var n int
+	for _, m := range source {
+		if w, ok := measurementWatts(m); ok {
+			sum += w
+			n++
+		}
+	}
+	details := T("dashboard.watts.direction", direction)
+	if n > 1 {
+		details += T("dashboard.watts.average", a.dashboard.chartView.Label(), sum/float64(n))
+	}
+	if a.latest.Voltage > 0 {
+		details += T("dashboard.watts.vi", float64(a.latest.Voltage)/1000, math.Abs(float64(a.latest.Amperage))/1000)
+	}
+
+	digits := lipgloss.NewStyle().Foreground(color).Bold(true).Render(renderBigNumber(fmt.Sprintf("%.1f", watts)))
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 1).
+		Width(width).
+		Render(lipgloss.JoinHorizontal(lipgloss.Center, digits, "  ", details))
+}
+
+// chartCursorColumn возвращает колонку перекрестья для графиков шириной
+// width (-1 – режим перекрестья выключен), удерживая ее в пределах графика
+func (a *App) chartCursorColumn(chart *Chart) int {
+	if !a.dashboard.cursorOn {
+		return -1
+	}
+	a.dashboard.cursorCol = max(min(a.dashboard.cursorCol, chart.DataWidth()-1), 0)
+	return a.dashboard.cursorCol
+}
+
+// moveChartCursor сдвигает перекрестье на delta колонок (правую границу
+// проверяет chartCursorColumn при отрисовке)
+func (a *App) moveChartCursor(delta int) {
+	a.dashboard.cursorCol = max(a.dashboard.cursorCol+delta, 0)
+}
+
+// chartCursorLine возвращает строку с точными значениями измерения под перекрестьем
+func chartCursorLine(m Measurement) string {
+	parts := []string{
+		"⌖ " + parseStoredTime(m.Timestamp).Local().Format("02.01 15:04:05"),
+		T("dashboard.cursor.charge", m.Percentage),
+		formatBatteryStateShort(m.State),
+	}
+	if m.CurrentCapacity > 0 {
+		parts = append(parts, T("dashboard.cursor.capacity", formatCapacity(m.CurrentCapacity, nominalVoltage(m))))
+	}
+	if m.Temperature > 0 {
+		parts = append(parts, fmt.Sprintf("%d°C", m.Temperature))
+	}
+	if w, ok := measurementWatts(m); ok {
+		parts = append(parts, T("dashboard.cursor.watts", w))
+	}
+	return lipgloss.NewStyle().Foreground(theme.Highlight).Render(strings.Join(parts, " · ")) +
+		lipgloss.NewStyle().Foreground(theme.Muted).Render(T("dashboard.cursor.keys"))
+}
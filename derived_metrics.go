@@ -0,0 +1,509 @@
+// derived_metrics.go
+//
+// Пользовательские производные метрики: выражения над полями измерения,
+// заданные в config.json, например
+//
+//	"metrics": [{"name": "watts", "expr": "voltage*amperage/1e6", "unit": "Вт"}]
+//
+// Значения считаются для каждого измерения, сохраняются в таблицу
+// derived_metrics и попадают в графики и экспорт отчетов.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// derivedMetricsSchema – значения производных метрик по измерениям
+const derivedMetricsSchema = `
+CREATE TABLE IF NOT EXISTS derived_metrics (
+	timestamp TEXT NOT NULL,
+	name TEXT NOT NULL,
+	value REAL NOT NULL,
+	PRIMARY KEY (timestamp, name)
+);`
+
+// DerivedMetricConfig – описание метрики в config.json
+type DerivedMetricConfig struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+	Unit string `json:"unit,omitempty"`
+}
+
+// metricNamePattern – допустимое имя метрики
+var metricNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// metricVariables возвращает значения полей измерения, доступные в выражениях.
+// Короткие имена full, design и current – синонимы ёмкостей.
+func metricVariables(m Measurement) map[string]float64 {
+	return map[string]float64{
+		"percentage":           float64(m.Percentage),
+		"cycle_count":          float64(m.CycleCount),
+		"cycles":               float64(m.CycleCount),
+		"full_charge_capacity": float64(m.FullChargeCap),
+		"full":                 float64(m.FullChargeCap),
+		"design_capacity":      float64(m.DesignCapacity),
+		"design":               float64(m.DesignCapacity),
+		"current_capacity":     float64(m.CurrentCapacity),
+		"current":              float64(m.CurrentCapacity),
+		"temperature":          float64(m.Temperature),
+		"voltage":              float64(m.Voltage),
+		"amperage":             float64(m.Amperage),
+		"power":                float64(m.Power),
+	}
+}
+
+// metricFunctions – функции, доступные в выражениях
+var metricFunctions = map[string]func(args []float64) (float64, error){
+	"abs": func(args []float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, errors.New(T("metric.err.abs"))
+		}
+		return math.Abs(args[0]), nil
+	},
+	"min": func(args []float64) (float64, error) {
+		if len(args) < 2 {
+			return 0, errors.New(T("metric.err.min"))
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = math.Min(v, a)
+		}
+		return v, nil
+	},
+	"max": func(args []float64) (float64, error) {
+		if len(args) < 2 {
+			return 0, errors.New(T("metric.err.max"))
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = math.Max(v, a)
+		}
+		return v, nil
+	},
+}
+
+// metricExpr – скомпилированное выражение
+type metricExpr func(vars map[string]float64) float64
+
+// DerivedMetric – проверенная метрика, готовая к вычислению
+type DerivedMetric struct {
+	DerivedMetricConfig
+	eval metricExpr
+}
+
+// Eval вычисляет метрику для измерения. ok=false, если результат не число
+// (например, деление на ноль при отсутствующих данных).
+func (d DerivedMetric) Eval(m Measurement) (float64, bool) {
+	v := d.eval(metricVariables(m))
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// compileDerivedMetric проверяет имя и разбирает выражение метрики
+func compileDerivedMetric(cfg DerivedMetricConfig) (DerivedMetric, error) {
+	if !metricNamePattern.MatchString(cfg.Name) {
+		return DerivedMetric{}, fmt.Errorf(T("metric.err.name"), cfg.Name)
+	}
+	if _, ok := metricVariables(Measurement{})[cfg.Name]; ok {
+		return DerivedMetric{}, fmt.Errorf(T("metric.err.field_name"), cfg.Name)
+	}
+	p := &metricParser{src: cfg.Expr}
+	expr, err := p.parse()
+	if err != nil {
+		return DerivedMetric{}, fmt.Errorf(T("err.metric"), cfg.Name, err)
+	}
+	return DerivedMetric{DerivedMetricConfig: cfg, eval: expr}, nil
+}
+
+// configuredMetrics компилирует метрики из конфига; ошибочные пропускаются
+// и возвращаются отдельно, повторяющиеся имена считаются ошибкой
+func configuredMetrics() ([]DerivedMetric, []error) {
+	var metrics []DerivedMetric
+	var errs []error
+	seen := map[string]bool{}
+	for _, cfg := range getConfig().Metrics {
+		if seen[cfg.Name] {
+			errs = append(errs, fmt.Errorf(T("metric.err.duplicate"), cfg.Name))
+			continue
+		}
+		seen[cfg.Name] = true
+		m, err := compileDerivedMetric(cfg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, errs
+}
+
+// insertDerivedMetrics вычисляет и сохраняет метрики для измерения в транзакции записи
+func insertDerivedMetrics(tx *sqlx.Tx, m Measurement, metrics []DerivedMetric) error {
+	for _, d := range metrics {
+		v, ok := d.Eval(m)
+		if !ok {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO derived_metrics (timestamp, name, value) VALUES (?, ?, ?)`,
+			m.Timestamp, d.Name, v); err != nil {
+			return fmt.Errorf(T("metric.err.save"), d.Name, err)
+		}
+	}
+	return nil
+}
+
+// DerivedSeries – значения метрики по измерениям отчета
+type DerivedSeries struct {
+	Name   string
+	Unit   string
+	Expr   string
+	Times  []string // время измерения (RFC3339 UTC)
+	Values []float64
+}
+
+// Latest возвращает последнее значение
+func (s DerivedSeries) Latest() float64 {
+	if len(s.Values) == 0 {
+		return 0
+	}
+	return s.Values[len(s.Values)-1]
+}
+
+// SeriesStats – минимум, среднее и максимум ряда
+type SeriesStats struct {
+	Min, Avg, Max float64
+}
+
+// Stats возвращает минимум, среднее и максимум
+func (s DerivedSeries) Stats() SeriesStats {
+	if len(s.Values) == 0 {
+		return SeriesStats{}
+	}
+	st := SeriesStats{Min: s.Values[0], Max: s.Values[0]}
+	for _, v := range s.Values {
+		st.Min = math.Min(st.Min, v)
+		st.Max = math.Max(st.Max, v)
+		st.Avg += v
+	}
+	st.Avg /= float64(len(s.Values))
+	return st
+}
+
+// Label возвращает имя метрики с единицей измерения
+func (s DerivedSeries) Label() string {
+	if s.Unit == "" {
+		return s.Name
+	}
+	return s.Name + ", " + s.Unit
+}
+
+// Clock возвращает локальное время i-го значения для подписей графиков
+func (s DerivedSeries) Clock(i int) string {
+	t, err := time.Parse(time.RFC3339, s.Times[i])
+	if err != nil {
+		return s.Times[i]
+	}
+	return t.Local().Format("15:04")
+}
+
+// buildDerivedSeries собирает ряды метрик для измерений отчета. Сохраненные
+// значения берутся из БД, для измерений без них (метрика добавлена позже)
+// значение вычисляется на лету.
+func buildDerivedSeries(db *sqlx.DB, ms []Measurement, metrics []DerivedMetric) ([]DerivedSeries, error) {
+	if len(metrics) == 0 || len(ms) == 0 {
+		return nil, nil
+	}
+	var stored []struct {
+		Timestamp string  `db:"timestamp"`
+		Name      string  `db:"name"`
+		Value     float64 `db:"value"`
+	}
+	err := db.Select(&stored, `SELECT timestamp, name, value FROM derived_metrics WHERE timestamp >= ? AND timestamp <= ?`,
+		ms[0].Timestamp, ms[len(ms)-1].Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf(T("metric.err.read"), err)
+	}
+	values := make(map[string]float64, len(stored))
+	for _, s := range stored {
+		values[s.Name+"|"+s.Timestamp] = s.Value
+	}
+
+	series := make([]DerivedSeries, 0, len(metrics))
+	for _, d := range metrics {
+		s := DerivedSeries{Name: d.Name, Unit: d.Unit, Expr: d.Expr}
+		for _, m := range ms {
+			v, ok := values[d.Name+"|"+m.Timestamp]
+			if !ok {
+				v, ok = d.Eval(m)
+			}
+			if ok {
+				s.Times = append(s.Times, m.Timestamp)
+				s.Values = append(s.Values, v)
+			}
+		}
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+// metricParser – разбор выражений рекурсивным спуском:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/") unary }
+//	unary   = "-" unary | primary
+//	primary = число | поле | функция "(" expr { "," expr } ")" | "(" expr ")"
+type metricParser struct {
+	src string
+	pos int
+}
+
+func (p *metricParser) parse() (metricExpr, error) {
+	if strings.TrimSpace(p.src) == "" {
+		return nil, errors.New(T("metric.err.empty"))
+	}
+	e, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf(T("metric.err.extra"), p.src[p.pos], p.pos+1)
+	}
+	return e, nil
+}
+
+func (p *metricParser) skipSpaces() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// accept пропускает символ c, если он следующий
+func (p *metricParser) accept(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *metricParser) expr() (metricExpr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept('+'):
+			right, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(v map[string]float64) float64 { return l(v) + right(v) }
+		case p.accept('-'):
+			right, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(v map[string]float64) float64 { return l(v) - right(v) }
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *metricParser) term() (metricExpr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept('*'):
+			right, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(v map[string]float64) float64 { return l(v) * right(v) }
+		case p.accept('/'):
+			right, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			l := left
+			left = func(v map[string]float64) float64 { return l(v) / right(v) }
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *metricParser) unary() (metricExpr, error) {
+	if p.accept('-') {
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(v map[string]float64) float64 { return -e(v) }, nil
+	}
+	return p.primary()
+}
+
+func (p *metricParser) primary() (metricExpr, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.src) {
+		return nil, errors.New(T("metric.err.eof"))
+	}
+	if p.accept('(') {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(')') {
+			return nil, fmt.Errorf(T("metric.err.paren"), p.pos+1)
+		}
+		return e, nil
+	}
+
+	c := rune(p.src[p.pos])
+	start := p.pos
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.src) && (unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// Экспонента: 1e6, 2.5E-3
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.src) && unicode.IsDigit(rune(p.src[p.pos])) {
+				p.pos++
+			}
+		}
+		n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf(T("metric.err.number"), p.src[start:p.pos])
+		}
+		return func(map[string]float64) float64 { return n }, nil
+
+	case unicode.IsLetter(c) || c == '_':
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos])) || p.src[p.pos] == '_') {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if p.accept('(') {
+			return p.call(name)
+		}
+		if _, ok := metricVariables(Measurement{})[name]; !ok {
+			return nil, fmt.Errorf(T("metric.err.field"), name, strings.Join(metricVariableNames(), ", "))
+		}
+		return func(v map[string]float64) float64 { return v[name] }, nil
+	}
+	return nil, fmt.Errorf(T("metric.err.char"), c, p.pos+1)
+}
+
+// call разбирает аргументы функции после открывающей скобки
+func (p *metricParser) call(name string) (metricExpr, error) {
+	fn, ok := metricFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf(T("metric.err.func"), name)
+	}
+	var args []metricExpr
+	for {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+		if p.accept(')') {
+			break
+		}
+		if !p.accept(',') {
+			return nil, fmt.Errorf(T("metric.err.comma"), p.pos+1)
+		}
+	}
+	// Проверяем число аргументов сразу, а не при первом вычислении
+	if _, err := fn(make([]float64, len(args))); err != nil {
+		return nil, err
+	}
+	return func(v map[string]float64) float64 {
+		vals := make([]float64, len(args))
+		for i, a := range args {
+			vals[i] = a(v)
+		}
+		r, _ := fn(vals)
+		return r
+	}, nil
+}
+
+// metricVariableNames возвращает имена полей для подсказок в ошибках
+func metricVariableNames() []string {
+	vars := metricVariables(Measurement{})
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runMetricsCommand – batmon metrics: проверить метрики из конфига и показать
+// их значения для последнего измерения
+func runMetricsCommand(args []string) error {
+	fs := newCommandFlags("metrics")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	metrics, errs := configuredMetrics()
+	for _, err := range errs {
+		fmt.Printf("❌ %v\n", err)
+	}
+	if len(metrics) == 0 {
+		fmt.Println(T("metrics.none", getConfigPath()))
+		fmt.Println(T("metrics.example"))
+		fmt.Println(T("metrics.fields", strings.Join(metricVariableNames(), ", ")))
+		return nil
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+	latest, err := getLastNMeasurements(db, 1)
+	if err != nil {
+		return fmt.Errorf(T("err.data"), err)
+	}
+	for _, d := range metrics {
+		value := T("metrics.no_data")
+		if len(latest) > 0 {
+			if v, ok := d.Eval(latest[0]); ok {
+				value = strconv.FormatFloat(v, 'f', 3, 64)
+				if d.Unit != "" {
+					value += " " + d.Unit
+				}
+			}
+		}
+		fmt.Printf("📐 %-16s = %-28s → %s\n", d.Name, d.Expr, value)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+// diskspace_unix.go
+//
+// Свободное место на диске для batmon doctor (macOS, Linux).
+
+package main
+
+import "syscall"
+
+// diskFree возвращает свободное для пользователя место на диске с path, байт
+func diskFree(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
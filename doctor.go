@@ -0,0 +1,462 @@
+// doctor.go
+//
+// Диагностика сборщика: отвечают ли pmset, ioreg и system_profiler (и как
+// быстро), когда было последнее удачное измерение, можно ли писать в базу,
+// хватает ли места на диске, работает ли caffeinate и что пишет журнал.
+// Когда сбор тихо ломался, пользователь видел только застывший дашборд;
+// теперь каждая проблема описана вместе с тем, что с ней сделать. Те же
+// проверки печатает batmon doctor и показывает панель дашборда (клавиша d).
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	doctorSlowCommand  = 3 * time.Second // дольше – утилита отвечает подозрительно медленно
+	doctorDiskWarning  = 1 << 30         // свободного места меньше 1 ГБ – предупреждение
+	doctorDiskCritical = 100 << 20       // меньше 100 МБ – запись скоро остановится
+	doctorLogWindow    = time.Hour       // окно подсчета ошибок в журнале
+)
+
+// doctorStatus – итог проверки
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// Icon возвращает значок статуса
+func (s doctorStatus) Icon() string {
+	return [...]string{"✅", "⚠️", "❌"}[s]
+}
+
+// Color возвращает цвет статуса в теме
+func (s doctorStatus) Color() lipgloss.Color {
+	return [...]lipgloss.Color{theme.Good, theme.Warning, theme.Critical}[s]
+}
+
+// DoctorCheck – результат одной проверки
+type DoctorCheck struct {
+	Name    string
+	Status  doctorStatus
+	Detail  string        // что обнаружено
+	Fix     string        // что сделать; пусто – все в порядке
+	Latency time.Duration // время ответа; 0 – не измерялось
+}
+
+// CollectorHealthState – итоги последних попыток сбора в этом процессе
+type CollectorHealthState struct {
+	LastSuccess time.Time
+	LastError   error
+	LastErrorAt time.Time
+	Failures    int // неудачных попыток подряд
+}
+
+// collectorHealth запоминает итоги сбора; сбор в TUI идет в отдельных
+// горутинах, поэтому состояние защищено мьютексом
+type collectorHealth struct {
+	mu    sync.Mutex
+	state CollectorHealthState
+}
+
+// record запоминает итог попытки сбора
+func (h *collectorHealth) record(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err != nil {
+		h.state.LastError, h.state.LastErrorAt = err, timeNow()
+		h.state.Failures++
+		return
+	}
+	h.state.LastSuccess = timeNow()
+	h.state.Failures = 0
+}
+
+// State возвращает копию состояния
+func (h *collectorHealth) State() CollectorHealthState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state
+}
+
+// doctorProbe – утилита, от которой зависит сбор, и безопасный способ ее вызвать
+type doctorProbe struct {
+	name   string
+	args   []string
+	expect string // подстрока, без которой вывод считается пустым
+}
+
+// doctorProbes возвращает утилиты сбора для этой ОС
+func doctorProbes() []doctorProbe {
+	if runtime.GOOS != "darwin" {
+		return nil
+	}
+	return []doctorProbe{
+		{"pmset", []string{"-g", "batt"}, "%"},
+		{"ioreg", []string{"-rn", "AppleSmartBattery"}, "AppleSmartBattery"},
+		{"system_profiler", []string{"SPPowerDataType"}, "Power"},
+	}
+}
+
+// runDoctor выполняет все проверки. db и path – база сборщика; health –
+// итоги сбора в работающем TUI (nil в командной строке).
+func runDoctor(db *sqlx.DB, path string, health *collectorHealth, caffeinateActive bool) []DoctorCheck {
+	var checks []DoctorCheck
+	for _, p := range doctorProbes() {
+		checks = append(checks, checkProbe(p))
+	}
+	checks = append(checks, checkSource())
+	checks = append(checks, checkLastSample(db, health))
+	checks = append(checks, checkDBWritable(db, path))
+	checks = append(checks, checkDiskSpace(path))
+	checks = append(checks, checkCaffeinate(health != nil, caffeinateActive))
+	checks = append(checks, checkLogErrors())
+	return checks
+}
+
+// doctorWorst возвращает худший статус среди проверок
+func doctorWorst(checks []DoctorCheck) doctorStatus {
+	worst := doctorOK
+	for _, c := range checks {
+		if c.Status > worst {
+			worst = c.Status
+		}
+	}
+	return worst
+}
+
+// checkProbe вызывает утилиту и проверяет ответ и время
+func checkProbe(p doctorProbe) DoctorCheck {
+	c := DoctorCheck{Name: p.name}
+	started := time.Now()
+	out, err := runCommand(p.name, p.args...)
+	c.Latency = time.Since(started)
+	command := p.name + " " + strings.Join(p.args, " ")
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		c.Status, c.Detail = doctorFail, T("doctor.probe.not_found")
+		c.Fix = T("doctor.probe.not_found.fix", p.name)
+	case err != nil:
+		c.Status, c.Detail = doctorFail, T("doctor.probe.error", err)
+		c.Fix = T("doctor.probe.error.fix", command)
+	case !strings.Contains(string(out), p.expect):
+		c.Status, c.Detail = doctorWarn, T("doctor.probe.no_battery")
+		c.Fix = T("doctor.probe.no_battery.fix", command)
+	case c.Latency > doctorSlowCommand:
+		c.Status, c.Detail = doctorWarn, T("doctor.probe.slow")
+		c.Fix = T("doctor.probe.slow.fix")
+	default:
+		c.Detail = T("doctor.probe.ok")
+	}
+	return c
+}
+
+// checkSource снимает заряд тем же источником, что и сборщик
+func checkSource() DoctorCheck {
+	source := newBatterySource()
+	c := DoctorCheck{Name: T("doctor.source", source.Name())}
+	started := time.Now()
+	pct, state, err := source.Status()
+	c.Latency = time.Since(started)
+	if err != nil {
+		c.Status, c.Detail = doctorFail, err.Error()
+		c.Fix = T("doctor.source.fix")
+		return c
+	}
+	c.Detail = fmt.Sprintf("%d%%, %s", pct, formatBatteryState(state))
+	if c.Latency > doctorSlowCommand {
+		c.Status = doctorWarn
+		c.Fix = T("doctor.source.slow")
+	}
+	return c
+}
+
+// expectedSampleGap возвращает наибольший ожидаемый промежуток между
+// измерениями с учетом адаптивного опроса
+func expectedSampleGap(cfg Config) time.Duration {
+	gap := cfg.Collector.PollInterval()
+	if samplingPolicyName(cfg.Collector.Sampling) == "adaptive" {
+		for _, d := range []time.Duration{
+			secondsOr(cfg.Collector.Sampling.IdleInterval, defaultIdleInterval),
+			secondsOr(cfg.Collector.Sampling.FullInterval, defaultFullInterval),
+		} {
+			if d > gap {
+				gap = d
+			}
+		}
+	}
+	return gap
+}
+
+// checkLastSample проверяет, как давно было удачное измерение. В TUI
+// учитываются итоги сбора в памяти, в командной строке – только база, куда
+// измерения попадают с задержкой пакетной записи и режима изменений.
+func checkLastSample(db *sqlx.DB, health *collectorHealth) DoctorCheck {
+	c := DoctorCheck{Name: T("doctor.last_sample")}
+	cfg := getConfig()
+	gap := expectedSampleGap(cfg)
+
+	if health != nil {
+		state := health.State()
+		switch {
+		case state.Failures > 0:
+			c.Status = doctorWarn
+			if state.Failures >= 3 {
+				c.Status = doctorFail
+			}
+			c.Detail = T("doctor.last_sample.failures",
+				state.Failures, state.LastErrorAt.Local().Format("15:04:05"), state.LastError)
+			c.Fix = T("doctor.last_sample.failures.fix")
+			return c
+		case !state.LastSuccess.IsZero():
+			age := timeNow().Sub(state.LastSuccess)
+			c.Detail = T("doctor.last_sample.age", formatDuration(age), state.LastSuccess.Local().Format("15:04:05"))
+			if age > 3*gap {
+				c.Status = doctorWarn
+				c.Fix = T("doctor.last_sample.stale")
+			}
+			return c
+		}
+	}
+
+	ms, err := getLastNMeasurements(db, 1)
+	if err != nil {
+		c.Status, c.Detail = doctorFail, T("doctor.last_sample.read", err)
+		c.Fix = T("doctor.last_sample.read.fix")
+		return c
+	}
+	if len(ms) == 0 {
+		c.Status, c.Detail = doctorWarn, T("doctor.last_sample.empty")
+		c.Fix = T("doctor.last_sample.empty.fix")
+		return c
+	}
+	at := parseStoredTime(ms[0].Timestamp)
+	age := timeNow().Sub(at)
+	c.Detail = T("doctor.last_sample.age", formatDuration(age), at.Local().Format("02.01 15:04"))
+	if writeBatchSize() > 1 {
+		gap += writeBatchMaxDelay
+	}
+	if cfg.Storage.ChangeOnly.Enabled {
+		gap += cfg.Storage.ChangeOnly.KeepAlive()
+	}
+	if age > 3*gap {
+		c.Status = doctorWarn
+		c.Fix = T("doctor.last_sample.old")
+	}
+	return c
+}
+
+// checkDBWritable проверяет запись в базу пробной транзакцией с откатом и
+// создание файлов в ее папке (нужно для -wal и -shm)
+func checkDBWritable(db *sqlx.DB, path string) DoctorCheck {
+	c := DoctorCheck{Name: T("doctor.db_write"), Detail: path}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		c.Status, c.Detail = doctorFail, T("doctor.db_write.conn", err)
+		c.Fix = T("doctor.db_write.conn.fix")
+		return c
+	}
+	defer conn.Close()
+
+	started := time.Now()
+	_, err = conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+	if err == nil {
+		_, err = conn.ExecContext(ctx, "CREATE TABLE doctor_write_probe (x INTEGER)")
+		conn.ExecContext(ctx, "ROLLBACK")
+	}
+	c.Latency = time.Since(started)
+	if err != nil {
+		c.Status, c.Detail = doctorFail, err.Error()
+		if strings.Contains(err.Error(), "locked") || strings.Contains(err.Error(), "busy") {
+			c.Fix = T("doctor.db_write.locked")
+		} else {
+			c.Fix = T("doctor.db_write.denied", path)
+		}
+		return c
+	}
+
+	if path == "" || path == ":memory:" {
+		return c
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".batmon-doctor-*")
+	if err != nil {
+		c.Status, c.Detail = doctorFail, T("doctor.db_write.dir", err)
+		c.Fix = T("doctor.db_write.dir.fix", filepath.Dir(path))
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return c
+}
+
+// checkDiskSpace проверяет свободное место на диске с базой
+func checkDiskSpace(path string) DoctorCheck {
+	c := DoctorCheck{Name: T("doctor.disk")}
+	if path == "" || path == ":memory:" {
+		c.Detail = T("doctor.disk.memory")
+		return c
+	}
+	free, err := diskFree(filepath.Dir(path))
+	if err != nil {
+		c.Status, c.Detail = doctorWarn, T("doctor.disk.error", err)
+		return c
+	}
+	c.Detail = T("doctor.disk.free", formatBytes(free))
+	switch {
+	case free < doctorDiskCritical:
+		c.Status = doctorFail
+		c.Fix = T("doctor.disk.critical")
+	case free < doctorDiskWarning:
+		c.Status = doctorWarn
+		c.Fix = T("doctor.disk.low")
+	}
+	return c
+}
+
+// checkCaffeinate сверяет запрет сна с режимом и ищет осиротевший процесс
+func checkCaffeinate(live, active bool) DoctorCheck {
+	mode := getConfig().Collector.Caffeinate
+	c := DoctorCheck{Name: T("doctor.caffeinate"), Detail: mode.Label()}
+	name, _, ok := sleepInhibitorCommand()
+	if !ok {
+		c.Detail += T("doctor.caffeinate.unsupported")
+		return c
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		c.Status, c.Detail = doctorWarn, T("doctor.caffeinate.missing", name)
+		c.Fix = T("doctor.caffeinate.missing.fix")
+		return c
+	}
+	if live {
+		if active {
+			c.Detail += T("doctor.caffeinate.running", name)
+		} else {
+			c.Detail += T("doctor.caffeinate.stopped")
+		}
+	}
+	path := caffeinateStatePath()
+	if path == "" {
+		return c
+	}
+	s, err := loadCaffeinateState(path)
+	if err != nil || s.OwnerPID == os.Getpid() || !sameProcess(s.PID, s.Command) {
+		return c
+	}
+	if !sameProcess(s.OwnerPID, s.Owner) {
+		c.Status = doctorWarn
+		c.Detail = T("doctor.caffeinate.orphan", s.Command, s.PID)
+		c.Fix = T("doctor.caffeinate.orphan.fix", s.PID)
+	} else if !live {
+		c.Detail += T("doctor.caffeinate.other", s.Command, s.PID)
+	}
+	return c
+}
+
+// checkLogErrors считает ошибки и предупреждения в журнале за последний час
+func checkLogErrors() DoctorCheck {
+	c := DoctorCheck{Name: T("doctor.log")}
+	path := logPath()
+	entries, err := readLogTail(path, logsViewEntries)
+	if errors.Is(err, os.ErrNotExist) {
+		c.Detail = T("doctor.log.empty")
+		return c
+	}
+	if err != nil {
+		c.Status, c.Detail = doctorWarn, err.Error()
+		return c
+	}
+	var errorsCount, warnings int
+	var last *logEntry
+	since := timeNow().Add(-doctorLogWindow)
+	for i := range entries {
+		e := &entries[i]
+		if e.Time.Before(since) {
+			continue
+		}
+		switch e.Level {
+		case levelError:
+			errorsCount++
+			last = e
+		case levelWarn:
+			warnings++
+			if last == nil || last.Level != levelError {
+				last = e
+			}
+		}
+	}
+	c.Detail = T("doctor.log.counts", errorsCount, warnings)
+	if last != nil {
+		c.Status = doctorWarn
+		if errorsCount > 0 {
+			c.Status = doctorFail
+		}
+		c.Detail += T("doctor.log.last", last.Message)
+		c.Fix = T("doctor.log.fix", path)
+	}
+	return c
+}
+
+// renderDoctorChecks форматирует проверки для терминала и панели TUI
+func renderDoctorChecks(checks []DoctorCheck, colored bool) string {
+	width := 0
+	for _, c := range checks {
+		width = max(width, lipgloss.Width(c.Name))
+	}
+	var b strings.Builder
+	for _, c := range checks {
+		name := c.Name + strings.Repeat(" ", width-lipgloss.Width(c.Name))
+		if colored {
+			name = lipgloss.NewStyle().Foreground(c.Status.Color()).Render(name)
+		}
+		line := fmt.Sprintf("%s %s  %s", c.Status.Icon(), name, c.Detail)
+		if c.Latency > 0 {
+			line += fmt.Sprintf(" (%v)", c.Latency.Round(time.Millisecond))
+		}
+		b.WriteString(line + "\n")
+		if c.Fix != "" {
+			b.WriteString(strings.Repeat(" ", width+4) + "→ " + c.Fix + "\n")
+		}
+	}
+	return b.String()
+}
+
+// runDoctorCommand печатает диагностику сборщика; код выхода 1 – есть сбои
+func runDoctorCommand(args []string) error {
+	fs := newCommandFlags("doctor")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	path := getDBPath()
+	db, err := initDB(path)
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	color.New(color.FgCyan, color.Bold).Println(T("doctor.title"))
+	checks := runDoctor(db, path, nil, false)
+	fmt.Print(renderDoctorChecks(checks, false))
+	if doctorWorst(checks) == doctorFail {
+		return exitCodeError{1}
+	}
+	return nil
+}
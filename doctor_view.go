@@ -0,0 +1,94 @@
+// doctor_view.go
+//
+// Панель «Состояние сборщика» в TUI: те же проверки, что batmon doctor, по
+// клавише d на дашборде. Проверки вызывают system_profiler и пробуют запись
+// в базу, поэтому выполняются в фоне, а панель показывает «проверка…».
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DoctorModel – состояние панели диагностики
+type DoctorModel struct {
+	checks  []DoctorCheck
+	running bool
+	ranAt   time.Time
+}
+
+// doctorResultMsg – результаты проверок из фоновой команды
+type doctorResultMsg []DoctorCheck
+
+// openDoctor открывает панель и запускает проверки
+func (a *App) openDoctor() tea.Cmd {
+	a.state = StateDoctor
+	return a.runDoctorChecks()
+}
+
+// runDoctorChecks запускает проверки в фоне
+func (a *App) runDoctorChecks() tea.Cmd {
+	if a.dataService == nil || a.doctor.running {
+		return nil
+	}
+	a.doctor.running = true
+	ds := a.dataService
+	active := ds.caffeineActive
+	return func() tea.Msg {
+		return doctorResultMsg(runDoctor(ds.store.DB(), ds.store.Path(), &ds.collector.health, active))
+	}
+}
+
+// updateDoctor обрабатывает нажатия на панели диагностики
+func (a *App) updateDoctor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q", "й", "esc", "d", "в":
+		a.state = StateDashboard
+	case "r", "к":
+		return a, a.runDoctorChecks()
+	}
+	return a, nil
+}
+
+// collectorStalled сообщает, что сбор подряд не удается – повод подсказать
+// панель диагностики прямо на дашборде
+func (a *App) collectorStalled() bool {
+	if a.dataService == nil || a.dataService.replay != nil {
+		return false
+	}
+	return a.dataService.collector.health.State().Failures > 0
+}
+
+// renderDoctor рендерит панель диагностики
+func (a *App) renderDoctor() string {
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).Render(T("doctor.title")) + "\n\n")
+	switch {
+	case a.doctor.running && len(a.doctor.checks) == 0:
+		content.WriteString(T("doctor.running") + "\n")
+	case len(a.doctor.checks) == 0:
+		content.WriteString(T("doctor.unavailable") + "\n")
+	default:
+		content.WriteString(renderDoctorChecks(a.doctor.checks, true))
+		status := T("doctor.checked", a.doctor.ranAt.Format("15:04:05"))
+		if a.doctor.running {
+			status = T("doctor.running")
+		}
+		content.WriteString("\n" + lipgloss.NewStyle().Foreground(theme.Muted).Render(status) + "\n")
+	}
+	content.WriteString("\n" + lipgloss.NewStyle().Foreground(theme.Muted).Render(T("doctor.help")))
+
+	border := theme.Accent
+	if len(a.doctor.checks) > 0 {
+		border = doctorWorst(a.doctor.checks).Color()
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Padding(1, 2).
+		Render(content.String())
+}
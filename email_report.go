@@ -0,0 +1,400 @@
+// email_report.go
+//
+// Отчет по расписанию: `batmon report schedule --weekly --email ...`
+// сохраняет расписание в config.json, а коллектор раз в день или неделю
+// собирает HTML-отчет за прошедший период и отправляет его письмом. Долгая
+// деградация батареи заметна, только если кто-то открывает отчеты, – письмо
+// приходит само. Отправка требует разрешения network.email.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Периоды отчета по расписанию
+const (
+	scheduleDaily  = "daily"
+	scheduleWeekly = "weekly"
+)
+
+// smtpPasswordEnv – переменная окружения с паролем SMTP, важнее пароля в конфиге
+const smtpPasswordEnv = "BATMON_SMTP_PASSWORD"
+
+// ReportScheduleConfig – расписание отчета по почте
+type ReportScheduleConfig struct {
+	Period       string `json:"period,omitempty"`        // daily или weekly; пусто – расписание выключено
+	Email        string `json:"email,omitempty"`         // получатели через запятую
+	SMTP         string `json:"smtp,omitempty"`          // сервер host:port; 465 – TLS, иначе STARTTLS
+	SMTPUser     string `json:"smtp_user,omitempty"`     // логин; пусто – без авторизации
+	SMTPPassword string `json:"smtp_password,omitempty"` // пароль, если не задан BATMON_SMTP_PASSWORD
+	From         string `json:"from,omitempty"`          // адрес отправителя; пусто – smtp_user
+}
+
+// Interval возвращает длительность периода расписания (0 – выключено)
+func (c ReportScheduleConfig) Interval() time.Duration {
+	switch c.Period {
+	case scheduleDaily:
+		return 24 * time.Hour
+	case scheduleWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// recipients возвращает адреса получателей
+func (c ReportScheduleConfig) recipients() []string {
+	var to []string
+	for _, addr := range strings.Split(c.Email, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	return to
+}
+
+// sender возвращает адрес отправителя
+func (c ReportScheduleConfig) sender() string {
+	if c.From != "" {
+		return c.From
+	}
+	return c.SMTPUser
+}
+
+// validate проверяет, что письмо можно отправить
+func (c ReportScheduleConfig) validate() error {
+	switch {
+	case len(c.recipients()) == 0:
+		return errors.New(T("email.err.no_email"))
+	case c.SMTP == "":
+		return errors.New(T("email.err.no_smtp"))
+	case c.sender() == "":
+		return errors.New(T("email.err.no_from"))
+	}
+	if _, _, err := net.SplitHostPort(c.SMTP); err != nil {
+		return fmt.Errorf(T("email.err.smtp_addr"), c.SMTP, err)
+	}
+	return nil
+}
+
+// runReportScheduleCommand настраивает расписание или отправляет отчет сразу
+func runReportScheduleCommand(args []string) error {
+	cfg := getConfig()
+	schedule := cfg.ReportSchedule
+	fs := newCommandFlags("report schedule")
+	daily := fs.Bool("daily", false, localized("flag.report.schedule.daily", "отправлять отчет за сутки каждый день"))
+	weekly := fs.Bool("weekly", false, localized("flag.report.schedule.weekly", "отправлять отчет за неделю раз в неделю"))
+	off := fs.Bool("off", false, localized("flag.report.schedule.off", "выключить расписание"))
+	sendNow := fs.Bool("send-now", false, localized("flag.report.schedule.send-now", "отправить отчет сейчас, не дожидаясь расписания"))
+	fs.StringVar(&schedule.Email, "email", schedule.Email, localized("flag.report.schedule.email", "получатели через запятую"))
+	fs.StringVar(&schedule.SMTP, "smtp", schedule.SMTP, localized("flag.report.schedule.smtp", "SMTP-сервер host:port"))
+	fs.StringVar(&schedule.SMTPUser, "smtp-user", schedule.SMTPUser, fmt.Sprintf(localized("flag.report.schedule.smtp-user", "логин SMTP (пароль – в %s)"), smtpPasswordEnv))
+	fs.StringVar(&schedule.From, "from-addr", schedule.From, localized("flag.report.schedule.from-addr", "адрес отправителя"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	switch {
+	case *off:
+		schedule.Period = ""
+	case *daily:
+		schedule.Period = scheduleDaily
+	case *weekly:
+		schedule.Period = scheduleWeekly
+	}
+
+	changed := fs.NFlag() > 0 && !(fs.NFlag() == 1 && *sendNow)
+	if !changed && !*sendNow {
+		printReportSchedule(schedule)
+		return nil
+	}
+	if schedule.Period != "" || *sendNow {
+		if err := schedule.validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return errUsage
+		}
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	if changed {
+		cfg.ReportSchedule = schedule
+		if err := saveConfig(getConfigPath(), cfg); err != nil {
+			return err
+		}
+		setConfig(cfg)
+		// Первое письмо по расписанию – через период после настройки
+		if err := saveReportSentAt(db, timeNow()); err != nil {
+			return err
+		}
+		printReportSchedule(schedule)
+	}
+	if *sendNow {
+		if err := sendScheduledReport(db, schedule); err != nil {
+			return err
+		}
+		fmt.Println(T("email.sent", schedule.Email))
+	}
+	return nil
+}
+
+// printReportSchedule выводит текущее расписание
+func printReportSchedule(c ReportScheduleConfig) {
+	if c.Period == "" {
+		fmt.Println(T("email.schedule.off"))
+		return
+	}
+	label := T("email.schedule.weekly")
+	if c.Period == scheduleDaily {
+		label = T("email.schedule.daily")
+	}
+	fmt.Println(T("email.schedule.on", label, c.Email, c.SMTP))
+	if !getConfig().Network.Allowed(NetworkEmail) {
+		fmt.Println(T("email.schedule.network_off", NetworkEmail, getConfigPath()))
+	}
+}
+
+// sendScheduledReport собирает HTML-отчет за период расписания и отправляет его
+func sendScheduledReport(db *sqlx.DB, c ReportScheduleConfig) error {
+	if err := requireNetwork(NetworkEmail); err != nil {
+		return err
+	}
+	interval := c.Interval()
+	if interval == 0 {
+		interval = 7 * 24 * time.Hour
+	}
+	rng := ReportRange{From: timeNow().Add(-interval)}
+	data, err := generateReportDataRange(db, rng)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "batmon-report")
+	if err != nil {
+		return fmt.Errorf(T("email.err.tmpdir"), err)
+	}
+	defer os.RemoveAll(dir)
+	name := fmt.Sprintf("batmon-report-%s.html", data.GeneratedAt.Format("2006-01-02"))
+	if err := exportToHTML(data, filepath.Join(dir, name)); err != nil {
+		return err
+	}
+	html, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf(T("email.err.read_report"), err)
+	}
+
+	msg, err := buildReportEmail(c, reportEmailSummary(data), name, html)
+	if err != nil {
+		return err
+	}
+	if err := sendMail(c, msg); err != nil {
+		return err
+	}
+	return saveReportSentAt(db, timeNow())
+}
+
+// reportEmailSummary – короткая сводка в тексте письма; подробности во вложении
+func reportEmailSummary(data ReportData) string {
+	var b strings.Builder
+	host, _ := os.Hostname()
+	fmt.Fprintf(&b, "%s: %s\n\n", T("report.title"), host)
+	fmt.Fprintf(&b, "%s: %s\n", T("report.period"), data.Range.Label())
+	if data.HealthAnalysis != nil {
+		fmt.Fprintf(&b, "%s: %s\n", T("report.health"), T("report.rating", data.HealthAnalysis.HealthStatus, data.HealthAnalysis.HealthScore, data.HealthAnalysis.ScoreModel.Label()))
+	}
+	fmt.Fprintf(&b, "%s: %.1f%%\n", T("report.wear"), data.Wear)
+	fmt.Fprintf(&b, "%s: %d\n", T("report.cycles"), data.Latest.CycleCount)
+	if len(data.Anomalies) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", T("report.anomalies", len(data.Anomalies)))
+	}
+	if len(data.Recommendations) > 0 {
+		b.WriteString("\n")
+	}
+	for _, rec := range data.Recommendations {
+		fmt.Fprintf(&b, "• %s\n", rec)
+	}
+	return b.String()
+}
+
+// buildReportEmail собирает письмо: текст сводки и HTML-отчет вложением
+// (почтовые клиенты режут скрипты, графики видны только в браузере)
+func buildReportEmail(c ReportScheduleConfig, summary, name string, html []byte) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf(T("email.err.compose"), err)
+	}
+	writeBase64(text, []byte(summary))
+
+	attachment, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType("text/html", map[string]string{"charset": "UTF-8", "name": name})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf(T("email.err.compose"), err)
+	}
+	writeBase64(attachment, html)
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf(T("email.err.compose"), err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", c.sender())
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(c.recipients(), ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", T("report.title")))
+	fmt.Fprintf(&msg, "Date: %s\r\n", timeNow().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// writeBase64 пишет данные в base64 строками по 76 символов (RFC 2045)
+func writeBase64(w interface{ Write([]byte) (int, error) }, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
+
+// sendMail отправляет письмо: на порт 465 через TLS, на остальные – с STARTTLS,
+// если сервер его поддерживает
+func sendMail(c ReportScheduleConfig, msg []byte) error {
+	host, port, _ := net.SplitHostPort(c.SMTP)
+	var auth smtp.Auth
+	if c.SMTPUser != "" {
+		password := os.Getenv(smtpPasswordEnv)
+		if password == "" {
+			password = c.SMTPPassword
+		}
+		auth = smtp.PlainAuth("", c.SMTPUser, password, host)
+	}
+	if port != "465" {
+		if err := smtp.SendMail(c.SMTP, auth, c.sender(), c.recipients(), msg); err != nil {
+			return fmt.Errorf(T("email.err.send"), err)
+		}
+		return nil
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", c.SMTP, &tls.Config{ServerName: host})
+	if err != nil {
+		return fmt.Errorf(T("err.connect"), c.SMTP, err)
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf(T("err.connect"), c.SMTP, err)
+	}
+	defer client.Close()
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf(T("email.err.auth"), err)
+		}
+	}
+	if err := client.Mail(c.sender()); err != nil {
+		return fmt.Errorf(T("email.err.send"), err)
+	}
+	for _, to := range c.recipients() {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf(T("email.err.recipient"), to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf(T("email.err.send"), err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf(T("email.err.send"), err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf(T("email.err.send"), err)
+	}
+	return client.Quit()
+}
+
+// loadReportSentAt возвращает время последней отправки (нулевое – не отправлялся)
+func loadReportSentAt(db *sqlx.DB) (time.Time, error) {
+	var raw string
+	err := db.Get(&raw, `SELECT state FROM analysis_state WHERE name = 'report_schedule'`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf(T("email.err.schedule_read"), err)
+	}
+	return parseStoredTime(raw), nil
+}
+
+// saveReportSentAt запоминает время последней отправки
+func saveReportSentAt(db *sqlx.DB, at time.Time) error {
+	stamp := at.UTC().Format(time.RFC3339)
+	_, err := db.Exec(`INSERT OR REPLACE INTO analysis_state (name, state, updated_at) VALUES ('report_schedule', ?, ?)`,
+		stamp, stamp)
+	if err != nil {
+		return fmt.Errorf(T("email.err.schedule_save"), err)
+	}
+	return nil
+}
+
+// updateReportSchedule отправляет отчет, когда подошел срок. Письмо
+// собирается в фоне, чтобы медленный SMTP не задерживал сбор.
+func (dc *DataCollector) updateReportSchedule() {
+	schedule := getConfig().ReportSchedule
+	interval := schedule.Interval()
+	if interval == 0 || dc.reportSending.Load() {
+		return
+	}
+	sentAt, err := loadReportSentAt(dc.db)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+		return
+	}
+	if sentAt.IsZero() {
+		// Расписание задано вручную в config.json – отсчет с этого момента
+		if err := saveReportSentAt(dc.db, timeNow()); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+		return
+	}
+	if timeNow().Sub(sentAt) < interval {
+		return
+	}
+	dc.reportSending.Store(true)
+	go func() {
+		defer dc.reportSending.Store(false)
+		if err := sendScheduledReport(dc.db, schedule); err != nil {
+			logWarnf("⚠️ Отчет по расписанию: %v", err)
+			// Повтор не раньше чем через час, а не каждые 5 минут
+			saveReportSentAt(dc.db, timeNow().Add(time.Hour-interval))
+			return
+		}
+		logInfof("📬 Отчет отправлен: %s", schedule.Email)
+	}()
+}
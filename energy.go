@@ -0,0 +1,116 @@
+// energy.go
+//
+// Ёмкость в ватт-часах и расход в ваттах. Контроллер сообщает заряд в мАч, но
+// мАч батарей с разным числом ячеек несравнимы: 5000 мАч при 11.4 В – это
+// вдвое больше энергии, чем при 7.6 В. Вт·ч считаются через номинальное
+// напряжение батареи, а мощность разрядки – через измеренное напряжение
+// каждого интервала.
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// cellNominalVoltage – номинальное напряжение ячейки батарей MacBook, мВ
+	// (например, 49.9 Вт·ч / 4380 мАч / 3 ячейки у MacBook Air M1)
+	cellNominalVoltage = 3800
+	// cellMaxVoltage – напряжение полностью заряженной ячейки, мВ: по нему
+	// число последовательных ячеек оценивается, если контроллер их не называет
+	cellMaxVoltage = 4400
+)
+
+// nominalVoltage возвращает номинальное напряжение батареи, мВ: число
+// последовательных ячеек × номинал ячейки. 0 – напряжение не записывалось.
+func nominalVoltage(m Measurement) int {
+	cells := len(parseCellVoltages(m.CellVoltages))
+	if cells == 0 {
+		if m.Voltage <= 0 {
+			return 0
+		}
+		cells = (m.Voltage + cellMaxVoltage - 1) / cellMaxVoltage
+	}
+	return cells * cellNominalVoltage
+}
+
+// detailsNominalVoltage – nominalVoltage для разового опроса источника
+func detailsNominalVoltage(d BatteryDetails) int {
+	return nominalVoltage(Measurement{Voltage: d.Voltage, CellVoltages: formatCellVoltages(d.CellVoltages)})
+}
+
+// latestNominalVoltage возвращает номинальное напряжение по последнему
+// измерению с напряжением: оно записывается не в каждом режиме сбора
+func latestNominalVoltage(ms []Measurement) int {
+	for i := len(ms) - 1; i >= 0; i-- {
+		if v := nominalVoltage(ms[i]); v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// capacityWh переводит ёмкость в Вт·ч по номинальному напряжению в мВ
+func capacityWh(mAh, voltage int) float64 {
+	return float64(mAh) * float64(voltage) / 1e6
+}
+
+// roundWh округляет Вт·ч до сотых для JSON
+func roundWh(wh float64) float64 {
+	return math.Round(wh*100) / 100
+}
+
+// formatCapacity возвращает ёмкость в мАч и, если напряжение известно, в Вт·ч:
+// «5103 мАч (58.2 Вт·ч)»
+func formatCapacity(mAh, voltage int) string {
+	s := fmt.Sprintf("%d %s", mAh, T("unit.mah"))
+	if voltage > 0 && mAh > 0 {
+		s += fmt.Sprintf(" (%.1f %s)", capacityWh(mAh, voltage), T("unit.wh"))
+	}
+	return s
+}
+
+// intervalEnergy возвращает энергию, отданную батареей между двумя
+// измерениями, Вт·ч: убыль заряда × среднее напряжение интервала.
+// ok=false, если у одного из измерений нет напряжения.
+func intervalEnergy(prev, curr Measurement) (float64, bool) {
+	if prev.Voltage <= 0 || curr.Voltage <= 0 {
+		return 0, false
+	}
+	drop := prev.CurrentCapacity - curr.CurrentCapacity
+	return float64(drop) * float64(prev.Voltage+curr.Voltage) / 2 / 1e6, true
+}
+
+// computeDischargePower вычисляет среднюю мощность разрядки, Вт, по тем же
+// интервалам, что и computeAvgRateRobust. Интервалы без напряжения не
+// учитываются; 0 – напряжение не записывалось.
+func computeDischargePower(ms []Measurement, intervals int) (float64, int) {
+	var energy, hours float64
+	valid := 0
+	eachRobustInterval(ms, intervals, func(prev, curr Measurement, h float64) {
+		if wh, ok := intervalEnergy(prev, curr); ok {
+			energy += wh
+			hours += h
+			valid++
+		}
+	})
+	if hours == 0 {
+		return 0, valid
+	}
+	return energy / hours, valid
+}
+
+// formatDrain возвращает расход в ваттах, если он посчитан по энергии, иначе
+// в мАч/ч
+func formatDrain(watts, mAhPerHour float64) string {
+	if watts > 0 {
+		return fmt.Sprintf("%.1f %s", watts, T("unit.watt"))
+	}
+	return fmt.Sprintf("%.0f %s", mAhPerHour, T("unit.mah_per_hour"))
+}
+
+// Capacity форматирует ёмкость для отчета с пересчетом в Вт·ч
+func (d ReportData) Capacity(mAh int) string {
+	return formatCapacity(mAh, d.NominalVoltage)
+}
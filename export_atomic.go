@@ -0,0 +1,124 @@
+// export_atomic.go
+//
+// Атомарная запись отчётов: содержимое пишется во временный файл рядом
+// с целевым, проверяется и только затем переименовывается. Сбой посреди
+// записи не оставляет полуготовых отчётов, а временные файлы от прошлых
+// аварийных запусков удаляются при старте.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	exportTempPrefix = ".batmon-export-"
+	exportTempMaxAge = time.Hour // более свежие файлы могут принадлежать идущему экспорту
+)
+
+// writeFileAtomic записывает файл через временный файл в той же папке.
+// write формирует содержимое, verify проверяет готовый временный файл
+// перед переименованием. При любой ошибке временный файл удаляется.
+func writeFileAtomic(path string, write func(io.Writer) error, verify func(string) error) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), exportTempPrefix+"*.tmp")
+	if err != nil {
+		return fmt.Errorf(T("atomic.err.tmp"), err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err = write(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf(T("atomic.err.sync"), err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf(T("atomic.err.close"), err)
+	}
+	if verify != nil {
+		if err = verify(tmpPath); err != nil {
+			return fmt.Errorf(T("atomic.err.verify"), err)
+		}
+	}
+	if err = os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf(T("atomic.err.chmod"), err)
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf(T("err.rename"), path, err)
+	}
+	return nil
+}
+
+// verifyMarkdownReport проверяет, что Markdown-отчет читается и дописан до конца
+func verifyMarkdownReport(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(raw, []byte("# ")) {
+		return errors.New(T("atomic.err.no_title"))
+	}
+	if !bytes.Contains(raw, []byte(T("report.footer"))) {
+		return errors.New(T("atomic.err.truncated"))
+	}
+	return nil
+}
+
+// verifyHTMLReport проверяет, что HTML-отчет читается и документ закрыт
+func verifyHTMLReport(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := strings.TrimSpace(string(raw))
+	if !strings.HasPrefix(content, "<!DOCTYPE html>") {
+		return errors.New(T("atomic.err.no_doctype"))
+	}
+	if !strings.HasSuffix(content, "</html>") {
+		return errors.New(T("atomic.err.doc_truncated"))
+	}
+	return nil
+}
+
+// cleanupStaleExportTemps удаляет временные файлы экспорта, оставшиеся
+// после аварийно завершённых запусков
+func cleanupStaleExportTemps(dirs ...string) {
+	cutoff := time.Now().Add(-exportTempMaxAge)
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, exportTempPrefix+"*.tmp"))
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.ModTime().After(cutoff) {
+				continue
+			}
+			if err := os.Remove(path); err == nil {
+				logInfof("🧹 Удален временный файл незавершенного экспорта: %s", path)
+			}
+		}
+	}
+}
+
+// exportTempDirs возвращает папки, куда по умолчанию сохраняются отчеты
+func exportTempDirs() []string {
+	dirs := []string{"."}
+	if documentsDir, err := getDocumentsDir(); err == nil {
+		dirs = append(dirs, documentsDir)
+	}
+	return dirs
+}
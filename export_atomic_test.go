@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Каждый экспорт Markdown проходит verifyMarkdownReport: отчет без общего
+// подвала считается обрезанным и не сохраняется
+func TestAtomicExports(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(48*time.Hour))
+	dir := t.TempDir()
+
+	at := func(h int) string { return fixtureStart.Add(time.Duration(h) * time.Hour).UTC().Format(time.RFC3339) }
+	test := CalibrationTest{
+		ID: 1, Status: calibrationCompleted, StartedAt: at(0), DischargeStartedAt: at(1), FinishedAt: at(9),
+		StartPercent: 100, DischargeStartPercent: 100, EndPercent: 4,
+		StartCapacity: 4500, EndCapacity: 180, FullChargeCap: 4500, DesignCapacity: 5000,
+	}
+	milestones := []CalibrationMilestone{{TestID: 1, Percent: 50, ReachedAt: at(5)}}
+	for _, locale := range []string{localeRU, localeEN} {
+		t.Setenv("LANG", locale+"_US.UTF-8")
+		path := filepath.Join(dir, "calibration-"+locale+".md")
+		if err := exportCalibrationMarkdown(calibrationResult(test, milestones), path); err != nil {
+			t.Fatalf("%s: экспорт калибровки: %v", locale, err)
+		}
+		if raw, _ := os.ReadFile(path); !strings.Contains(string(raw), T("report.footer")) {
+			t.Errorf("%s: в отчете о калибровке нет подвала", locale)
+		}
+	}
+	t.Setenv("LANG", "ru_RU.UTF-8")
+
+	truncated := filepath.Join(dir, "truncated.md")
+	err := writeFileAtomic(truncated, func(w io.Writer) error {
+		_, err := io.WriteString(w, "# Отчет\n\nбез подвала\n")
+		return err
+	}, verifyMarkdownReport)
+	if err == nil {
+		t.Fatal("обрезанный отчет сохранен")
+	}
+	if _, statErr := os.Stat(truncated); !errors.Is(statErr, os.ErrNotExist) {
+		t.Errorf("после ошибки остался файл: %v", statErr)
+	}
+	if temps, _ := filepath.Glob(filepath.Join(dir, exportTempPrefix+"*")); len(temps) > 0 {
+		t.Errorf("остались временные файлы: %v", temps)
+	}
+}
@@ -0,0 +1,424 @@
+// export_form.go
+//
+// Экран экспорта: форма с выбором форматов (Markdown, HTML, JSON, CSV),
+// именем файла и периодом. Экспорт идет в фоне, ход передается через канал
+// сообщениями Bubble Tea, а по завершении показывается список созданных
+// файлов или ошибка. Здесь же выгрузка данных отчета в JSON и измерений в CSV.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// exportFormats – форматы экспорта в порядке на экране
+var exportFormats = []struct {
+	ext   string
+	label string
+}{
+	{"md", "Markdown"},
+	{"html", "HTML"},
+	{"json", "JSON"},
+	{"csv", "CSV"},
+}
+
+// Поля формы экспорта после флажков форматов
+const (
+	exportFieldName = iota + 4 // индексы 0-3 – флажки exportFormats
+	exportFieldFrom
+	exportFieldTo
+	exportFieldRun
+	exportFieldCount
+)
+
+// Этапы экрана экспорта
+const (
+	exportPhaseForm = iota
+	exportPhaseRunning
+	exportPhaseDone
+)
+
+// ExportForm – состояние экрана экспорта
+type ExportForm struct {
+	formats  []bool // выбранные exportFormats
+	name     textinput.Model
+	from     textinput.Model
+	to       textinput.Model
+	focus    int
+	phase    int
+	progress string   // текущий шаг фонового экспорта
+	results  []string // созданные файлы
+	err      error    // ошибка экспорта или формы
+}
+
+// exportProgressMsg – шаг фонового экспорта; done – экспорт завершен
+type exportProgressMsg struct {
+	step  string
+	done  bool
+	paths []string
+	err   error
+}
+
+// newExportInput создает поле ввода формы
+func newExportInput(placeholder, value string, width int) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.CharLimit = 512
+	input.Width = width
+	input.SetValue(value)
+	return input
+}
+
+// initExport открывает форму экспорта: HTML, путь по шаблону из конфига, последние измерения
+func (a *App) initExport() {
+	name := strings.TrimSuffix(defaultExportName("html"), ".html")
+	if dir, err := exportDir(exportVars{At: timeNow()}); err == nil {
+		name = dir + string(os.PathSeparator) + name
+	}
+	a.export = ExportForm{
+		formats: []bool{false, true, false, false},
+		name:    newExportInput("batmon_report_{date}", name, 60),
+		from:    newExportInput("7d, 2025-01-31, 14:00", "", 24),
+		to:      newExportInput(T("export.form.to_placeholder"), "", 24),
+		focus:   exportFieldRun,
+	}
+}
+
+// focusExportField переводит фокус формы, включая курсор в полях ввода
+func (f *ExportForm) focusExportField(field int) {
+	f.focus = (field + exportFieldCount) % exportFieldCount
+	for i, input := range []*textinput.Model{&f.name, &f.from, &f.to} {
+		if f.focus == exportFieldName+i {
+			input.Focus()
+		} else {
+			input.Blur()
+		}
+	}
+}
+
+// updateExport обрабатывает нажатия на экране экспорта
+func (a *App) updateExport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := &a.export
+	switch f.phase {
+	case exportPhaseRunning:
+		return a, nil // выход из экрана не прерывает запись файлов
+	case exportPhaseDone:
+		switch msg.String() {
+		case "ctrl+c", "q", "й":
+			a.state = StateMenu
+		default:
+			f.phase = exportPhaseForm
+		}
+		return a, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		a.state = StateMenu
+		return a, nil
+	case "tab", "down":
+		f.focusExportField(f.focus + 1)
+		return a, nil
+	case "shift+tab", "up":
+		f.focusExportField(f.focus - 1)
+		return a, nil
+	case "enter":
+		return a, a.startExport()
+	case " ", "x":
+		if f.focus < len(exportFormats) {
+			f.formats[f.focus] = !f.formats[f.focus]
+			return a, nil
+		}
+		if f.focus == exportFieldRun {
+			return a, a.startExport()
+		}
+	}
+
+	var cmd tea.Cmd
+	switch f.focus {
+	case exportFieldName:
+		f.name, cmd = f.name.Update(msg)
+	case exportFieldFrom:
+		f.from, cmd = f.from.Update(msg)
+	case exportFieldTo:
+		f.to, cmd = f.to.Update(msg)
+	}
+	return a, cmd
+}
+
+// startExport проверяет форму и запускает экспорт в фоне
+func (a *App) startExport() tea.Cmd {
+	f := &a.export
+	f.err = nil
+	var formats []string
+	for i, on := range f.formats {
+		if on {
+			formats = append(formats, exportFormats[i].ext)
+		}
+	}
+	if len(formats) == 0 {
+		f.err = errors.New(T("export.form.no_format"))
+		return nil
+	}
+	name := strings.TrimSpace(f.name.Value())
+	for _, format := range exportFormats {
+		name = strings.TrimSuffix(name, "."+format.ext)
+	}
+	if name == "" {
+		f.err = errors.New(T("export.form.no_name"))
+		return nil
+	}
+	rng, err := parseReportRange(strings.TrimSpace(f.from.Value()), strings.TrimSpace(f.to.Value()), time.Now())
+	if err != nil {
+		f.err = err
+		return nil
+	}
+
+	f.phase = exportPhaseRunning
+	f.progress = T("export.form.preparing")
+	progress := make(chan exportProgressMsg, len(formats)+2)
+	go func() {
+		defer close(progress)
+		paths, err := a.runExport(name, formats, rng, progress)
+		progress <- exportProgressMsg{done: true, paths: paths, err: err}
+	}()
+	return waitExportProgress(progress)
+}
+
+// waitExportProgress ждет следующего сообщения фонового экспорта
+func waitExportProgress(progress <-chan exportProgressMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-progress
+		if !ok {
+			return nil
+		}
+		if !msg.done {
+			return exportStepMsg{exportProgressMsg: msg, next: progress}
+		}
+		return msg
+	}
+}
+
+// exportStepMsg – промежуточный шаг экспорта с каналом для следующего
+type exportStepMsg struct {
+	exportProgressMsg
+	next <-chan exportProgressMsg
+}
+
+// handleExportProgress обновляет экран по ходу фонового экспорта
+func (a *App) handleExportProgress(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case exportStepMsg:
+		a.export.progress = msg.step
+		return waitExportProgress(msg.next)
+	case exportProgressMsg:
+		a.export.phase = exportPhaseDone
+		a.export.results = msg.paths
+		a.export.err = msg.err
+	}
+	return nil
+}
+
+// runExport формирует отчет за период и записывает его во всех выбранных форматах.
+// name – путь без расширения, шаблоны раскрываются для каждого формата.
+func (a *App) runExport(name string, formats []string, rng ReportRange, progress chan<- exportProgressMsg) ([]string, error) {
+	db, release, err := a.openReportDB()
+	if err != nil {
+		return nil, fmt.Errorf(T("err.db_open"), err)
+	}
+	defer release()
+
+	data, err := generateReportDataRange(db, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for i, format := range formats {
+		progress <- exportProgressMsg{step: T("export.form.step", strings.ToUpper(format), i+1, len(formats))}
+		path, err := resolveExportPath(name+"."+format, exportVars{At: data.GeneratedAt, Serial: data.Latest.BatterySerial, Format: format})
+		if err != nil {
+			return paths, err
+		}
+		switch format {
+		case "md":
+			err = exportToMarkdown(data, path)
+		case "html":
+			err = exportToHTML(data, path)
+		case "json":
+			err = exportToJSON(data, path)
+		case "csv":
+			var ms []Measurement
+			if ms, err = loadReportMeasurements(db, rng, reportLastN); err == nil {
+				err = exportToCSV(ms, path)
+			}
+		}
+		if err != nil {
+			return paths, fmt.Errorf(T("export.form.err.format"), strings.ToUpper(format), err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// renderExport рендерит форму экспорта, ход экспорта или его результат
+func (a *App) renderExport() string {
+	f := a.export
+	selected := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	marker := func(field int) string {
+		if f.focus == field {
+			return selected.Render("▶ ")
+		}
+		return "  "
+	}
+
+	var content strings.Builder
+	content.WriteString(T("export.form.title"))
+	switch f.phase {
+	case exportPhaseRunning:
+		content.WriteString(T("export.form.running", f.progress) + "\n")
+	case exportPhaseDone:
+		if len(f.results) > 0 {
+			content.WriteString(T("export.form.created"))
+			for _, path := range f.results {
+				content.WriteString("   " + path + "\n")
+			}
+		}
+		if f.err != nil {
+			content.WriteString("\n❌ " + f.err.Error() + "\n")
+		}
+		content.WriteString(T("export.form.done_keys"))
+	default:
+		content.WriteString(T("export.form.formats"))
+		for i, format := range exportFormats {
+			box := "[ ]"
+			if f.formats[i] {
+				box = "[x]"
+			}
+			content.WriteString(marker(i) + box + " " + format.label + "\n")
+		}
+		content.WriteString("\n" + marker(exportFieldName) + T("export.form.name") + "\n  " + f.name.View() + "\n")
+		content.WriteString(marker(exportFieldFrom) + T("export.form.from") + f.from.View() + "\n")
+		content.WriteString(marker(exportFieldTo) + T("export.form.to") + f.to.View() + "\n")
+		content.WriteString(T("export.form.range_hint"))
+		content.WriteString(marker(exportFieldRun) + selected.Render(T("export.form.run")) + "\n\n")
+		if f.err != nil {
+			content.WriteString("❌ " + f.err.Error() + "\n\n")
+		}
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Border).Render(
+			T("export.form.vars") + "\n" + T("export.form.keys")))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1).
+		Render(content.String())
+}
+
+// reportJSON – данные отчета для выгрузки в JSON
+type reportJSON struct {
+	GeneratedAt      time.Time     `json:"generated_at"`
+	Period           string        `json:"period"`
+	Latest           Measurement   `json:"latest"`
+	Wear             float64       `json:"wear_percent"`
+	HealthScore      int           `json:"health_score,omitempty"`
+	HealthStatus     string        `json:"health_status,omitempty"`
+	HealthModel      HealthModel   `json:"health_model,omitempty"`
+	AvgRate          float64       `json:"avg_rate_mah_per_hour"`
+	RobustRate       float64       `json:"robust_rate_mah_per_hour"`
+	DischargePower   float64       `json:"discharge_power_watts,omitempty"`
+	FullChargeWh     float64       `json:"full_charge_wh,omitempty"`
+	DesignWh         float64       `json:"design_wh,omitempty"`
+	RemainingSeconds int64         `json:"remaining_seconds,omitempty"`
+	Anomalies        []Anomaly     `json:"anomalies"`
+	Recommendations  []string      `json:"recommendations"`
+	Notes            []Note        `json:"notes"`
+	Measurements     []Measurement `json:"measurements"`
+}
+
+// newReportJSON собирает выгрузку отчета: ее же отдает /api/v1/report
+func newReportJSON(data ReportData) reportJSON {
+	out := reportJSON{
+		GeneratedAt:      data.GeneratedAt,
+		Period:           data.Range.Label(),
+		Latest:           data.Latest,
+		Wear:             data.Wear,
+		AvgRate:          data.AvgRate,
+		RobustRate:       data.RobustRate,
+		DischargePower:   data.DischargePower,
+		FullChargeWh:     roundWh(capacityWh(data.Latest.FullChargeCap, data.NominalVoltage)),
+		DesignWh:         roundWh(capacityWh(data.Latest.DesignCapacity, data.NominalVoltage)),
+		RemainingSeconds: int64(data.RemainingTime / time.Second),
+		Anomalies:        data.Anomalies,
+		Recommendations:  data.Recommendations,
+		Notes:            data.Notes,
+		Measurements:     data.Measurements,
+	}
+	if data.HealthAnalysis != nil {
+		out.HealthScore = data.HealthAnalysis.HealthScore
+		out.HealthStatus = data.HealthAnalysis.HealthStatus
+		out.HealthModel = data.HealthAnalysis.ScoreModel
+	}
+	return out
+}
+
+// exportToJSON сохраняет сводку отчета и измерения для графиков в JSON
+func exportToJSON(data ReportData, filename string) error {
+	out := newReportJSON(data)
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return fmt.Errorf(T("export.err.json"), err)
+		}
+		return nil
+	}, verifyJSONReport)
+}
+
+// verifyJSONReport проверяет, что JSON-отчет записан целиком
+func verifyJSONReport(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(raw) {
+		return errors.New(T("export.err.json_broken"))
+	}
+	return nil
+}
+
+// csvColumns – заголовок CSV-выгрузки измерений
+var csvColumns = []string{"timestamp", "percentage", "state", "cycle_count", "full_charge_capacity",
+	"design_capacity", "current_capacity", "temperature", "voltage", "amperage", "power", "battery_serial"}
+
+// exportToCSV сохраняет измерения в CSV, по строке на измерение
+func exportToCSV(ms []Measurement, filename string) error {
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		cw.Write(csvColumns)
+		for _, m := range ms {
+			cw.Write([]string{m.Timestamp, strconv.Itoa(m.Percentage), m.State, strconv.Itoa(m.CycleCount),
+				strconv.Itoa(m.FullChargeCap), strconv.Itoa(m.DesignCapacity), strconv.Itoa(m.CurrentCapacity),
+				strconv.Itoa(m.Temperature), strconv.Itoa(m.Voltage), strconv.Itoa(m.Amperage),
+				strconv.Itoa(m.Power), m.BatterySerial})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf(T("export.err.csv"), err)
+		}
+		return nil
+	}, nil)
+}
@@ -0,0 +1,102 @@
+// export_path.go
+//
+// Куда сохраняются отчеты: папка и шаблон имени файла из config.json с
+// подстановками {date}, {time}, {host}, {serial} и {format}. Недостающие
+// папки создаются при экспорте.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultExportFilename – шаблон имени отчета без расширения
+const defaultExportFilename = "batmon_report_{date}"
+
+// ExportConfig – папка и имя файлов экспорта
+type ExportConfig struct {
+	Dir      string `json:"dir,omitempty"`      // папка отчетов; пусто – ~/Documents
+	Filename string `json:"filename,omitempty"` // шаблон имени без расширения; пусто – batmon_report_{date}
+}
+
+// exportVars – значения подстановок в шаблонах экспорта
+type exportVars struct {
+	At     time.Time
+	Serial string // серийный номер батареи; пусто – unknown
+	Format string // md, html ...; пусто – по расширению файла
+}
+
+// expandExportTemplate подставляет значения в шаблон пути
+func expandExportTemplate(tmpl string, v exportVars) string {
+	host, _ := os.Hostname()
+	host = strings.TrimSuffix(host, ".local")
+	serial := v.Serial
+	if serial == "" {
+		serial = "unknown"
+	}
+	format := v.Format
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(tmpl), ".")
+	}
+	safe := strings.NewReplacer("/", "-", "\\", "-", " ", "_")
+	return strings.NewReplacer(
+		"{date}", v.At.Local().Format("2006-01-02"),
+		"{time}", v.At.Local().Format("15-04"),
+		"{host}", safe.Replace(host),
+		"{serial}", safe.Replace(serial),
+		"{format}", format,
+	).Replace(tmpl)
+}
+
+// expandHome раскрывает ~ в начале пути
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// exportDir возвращает папку отчетов из конфига или ~/Documents
+func exportDir(v exportVars) (string, error) {
+	if dir := getConfig().Export.Dir; dir != "" {
+		return expandHome(expandExportTemplate(dir, v)), nil
+	}
+	return getDocumentsDir()
+}
+
+// defaultExportName возвращает шаблон имени отчета из конфига с расширением формата
+func defaultExportName(format string) string {
+	name := getConfig().Export.Filename
+	if name == "" {
+		name = defaultExportFilename
+	}
+	return name + "." + format
+}
+
+// resolveExportPath подставляет значения в имя файла, кладет файл без
+// пути в папку отчетов и создает недостающие папки
+func resolveExportPath(name string, v exportVars) (string, error) {
+	if v.Format == "" {
+		v.Format = strings.TrimPrefix(filepath.Ext(name), ".")
+	}
+	path := expandHome(expandExportTemplate(name, v))
+	if !filepath.IsAbs(path) && !strings.ContainsRune(path, filepath.Separator) {
+		dir, err := exportDir(v)
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf(T("export.err.dir"), err)
+	}
+	return path, nil
+}
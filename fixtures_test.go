@@ -0,0 +1,136 @@
+package main
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// fixtureStart – начало всех синтетических записей; часы тестов стоят сразу после них
+var fixtureStart = time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC)
+
+// newTestDB открывает пустую БД в памяти со всеми миграциями
+func newTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := initDB(":memory:")
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newFileTestDB открывает базу в файле во временной папке – для команд,
+// которым нужно несколько соединений или путь к базе
+func newFileTestDB(t *testing.T, name string) (*sqlx.DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	db, err := initDB(path)
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+// freezeEnvironment останавливает часы на now, переводит местное время в UTC,
+// язык отчетов в русский и отключает определение модели Mac, чтобы результат
+// не зависел от машины
+func freezeEnvironment(t *testing.T, now time.Time) {
+	t.Helper()
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "ru_RU.UTF-8")
+
+	hwModelOnce.Do(func() {})
+	origNow, origLocal, origModel := timeNow, time.Local, hwModel
+	t.Cleanup(func() { timeNow, time.Local, hwModel = origNow, origLocal, origModel })
+	timeNow = func() time.Time { return now }
+	time.Local = time.UTC
+	hwModel = ""
+}
+
+// insertFixture сохраняет измерения в БД и, как коллектор, записывает
+// базовую точку батареи последнего измерения
+func insertFixture(t *testing.T, db *sqlx.DB, ms []Measurement) {
+	t.Helper()
+	for i := range ms {
+		if err := insertMeasurement(db, &ms[i]); err != nil {
+			t.Fatalf("измерение %d: %v", i, err)
+		}
+	}
+	if len(ms) > 0 {
+		if err := ensureBaseline(db, ms[len(ms)-1]); err != nil {
+			t.Fatalf("базовая точка: %v", err)
+		}
+	}
+}
+
+// fixtureBattery – неизменные параметры батареи для генераторов
+type fixtureBattery struct {
+	Serial   string
+	Design   int // мАч
+	Full     int // мАч
+	Cycles   int
+	Voltage  int // мВ
+	DrainMAh int // расход за шаг, мАч
+}
+
+var fixtureHealthyBattery = fixtureBattery{Serial: "F5D0001", Design: 5000, Full: 4900, Cycles: 120, Voltage: 12500, DrainMAh: 50}
+
+// measurement строит измерение разрядки батареи b с текущей ёмкостью current
+func (b fixtureBattery) measurement(at time.Time, current int) Measurement {
+	return Measurement{
+		Timestamp:       at.UTC().Format(time.RFC3339),
+		Percentage:      current * 100 / b.Full,
+		State:           "discharging",
+		CycleCount:      b.Cycles,
+		FullChargeCap:   b.Full,
+		DesignCapacity:  b.Design,
+		CurrentCapacity: current,
+		Temperature:     31,
+		Voltage:         b.Voltage,
+		Amperage:        -b.DrainMAh * 6, // шаг 10 минут: мАч за шаг × 6 = мА
+		Power:           -b.Voltage * b.DrainMAh * 6 / 1000,
+		AppleCondition:  "Normal",
+		BatterySerial:   b.Serial,
+	}
+}
+
+// steadyDischarge – ровная разрядка с полного заряда: n измерений через step
+func steadyDischarge(b fixtureBattery, start time.Time, step time.Duration, n int) []Measurement {
+	ms := make([]Measurement, n)
+	for i := range ms {
+		ms[i] = b.measurement(start.Add(time.Duration(i)*step), b.Full-i*b.DrainMAh)
+	}
+	return ms
+}
+
+// noisyDischarge – разрядка с шумом в ёмкости, напряжении и температуре и
+// одним сбоем контроллера; seed задает воспроизводимый шум
+func noisyDischarge(b fixtureBattery, start time.Time, step time.Duration, n int, seed int64) []Measurement {
+	rnd := rand.New(rand.NewSource(seed))
+	ms := steadyDischarge(b, start, step, n)
+	for i := range ms {
+		ms[i].CurrentCapacity += rnd.Intn(41) - 20
+		ms[i].Voltage += rnd.Intn(301) - 150
+		ms[i].Temperature += rnd.Intn(5) - 2
+	}
+	glitch := n / 2
+	ms[glitch].CurrentCapacity = b.Full + 400 // текущая ёмкость больше полной
+	return ms
+}
+
+// replacementHistory – разрядка старой изношенной батареи, пропуск в сутки и
+// разрядка новой с другим серийным номером
+func replacementHistory(start time.Time, step time.Duration, n int) []Measurement {
+	old := fixtureBattery{Serial: "F5D0OLD", Design: 5000, Full: 3600, Cycles: 1100, Voltage: 11800, DrainMAh: 60}
+	ms := steadyDischarge(old, start, step, n)
+	replaced := start.Add(time.Duration(n)*step + 24*time.Hour)
+	fresh := fixtureHealthyBattery
+	fresh.Serial, fresh.Cycles, fresh.Full = "F5D0NEW", 1, 5000
+	return append(ms, steadyDischarge(fresh, replaced, step, n)...)
+}
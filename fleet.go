@@ -0,0 +1,436 @@
+// fleet.go
+//
+// Парк машин: `batmon fleet import` принимает JSON-снимки `batmon status --json`
+// с нескольких MacBook и складывает их в таблицу fleet_status, отдельно по
+// имени хоста, а `batmon fleet report` сводит последние снимки в одну таблицу
+// сравнения – модель, циклы, износ и рейтинг здоровья. Так IT-администратор
+// видит все ноутбуки компании сразу, не ставя batmon сервером. Собственные
+// измерения машины, на которой идет импорт, при этом не затрагиваются.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/jmoiron/sqlx"
+)
+
+// fleetSchema – снимки статуса других машин; host – пространство имен машины
+const fleetSchema = `
+CREATE TABLE IF NOT EXISTS fleet_status (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	host TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	imported_at TEXT NOT NULL,
+	model TEXT NOT NULL DEFAULT '',
+	serial TEXT NOT NULL DEFAULT '',
+	percentage INTEGER NOT NULL DEFAULT 0,
+	cycle_count INTEGER NOT NULL DEFAULT 0,
+	full_charge_capacity INTEGER NOT NULL DEFAULT 0,
+	design_capacity INTEGER NOT NULL DEFAULT 0,
+	wear REAL NOT NULL DEFAULT 0,
+	health_score INTEGER NOT NULL DEFAULT 0,
+	condition TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL,
+	UNIQUE (host, timestamp)
+);`
+
+// FleetRecord – снимок статуса машины парка
+type FleetRecord struct {
+	ID             int     `db:"id" json:"-"`
+	Host           string  `db:"host" json:"host"`
+	Timestamp      string  `db:"timestamp" json:"timestamp"` // время снимка, RFC3339 UTC
+	ImportedAt     string  `db:"imported_at" json:"imported_at"`
+	Model          string  `db:"model" json:"model"`
+	Serial         string  `db:"serial" json:"serial"`
+	Percentage     int     `db:"percentage" json:"percentage"`
+	CycleCount     int     `db:"cycle_count" json:"cycle_count"`
+	FullChargeCap  int     `db:"full_charge_capacity" json:"full_charge_capacity"`
+	DesignCapacity int     `db:"design_capacity" json:"design_capacity"`
+	Wear           float64 `db:"wear" json:"wear"`
+	HealthScore    int     `db:"health_score" json:"health_score"` // 0 – на машине не было истории
+	Condition      string  `db:"condition" json:"condition"`
+	Status         string  `db:"status" json:"-"` // снимок как есть, с полями, которых эта версия не знает
+}
+
+// ModelName возвращает название модели по справочнику или идентификатор hw.model
+func (r FleetRecord) ModelName() string {
+	if m, ok := macModels[r.Model]; ok {
+		return m.Name
+	}
+	if r.Model == "" {
+		return "–"
+	}
+	return r.Model
+}
+
+// Score возвращает рейтинг для таблицы
+func (r FleetRecord) Score() string {
+	if r.HealthScore <= 0 {
+		return "–"
+	}
+	return fmt.Sprintf("%d/100", r.HealthScore)
+}
+
+// Check сравнивает износ и циклы машины с порогами batmon check
+func (r FleetRecord) Check(t HealthThresholds) HealthCheck {
+	check := HealthCheck{Wear: r.Wear, Cycles: r.CycleCount}
+	evaluateHealth(&check, t)
+	return check
+}
+
+// Date возвращает время снимка в местном времени
+func (r FleetRecord) Date() string {
+	return parseStoredTime(r.Timestamp).Local().Format("02.01.2006 15:04")
+}
+
+// FleetImportResult – итог импорта снимков
+type FleetImportResult struct {
+	Files      int
+	Imported   int
+	Duplicates int // такой снимок машины уже есть
+	Hosts      []string
+}
+
+// parseFleetSnapshots делит файл на снимки: один JSON-объект, массив
+// объектов или объекты подряд (по строке на снимок)
+func parseFleetSnapshots(raw []byte) ([]json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	var snapshots []json.RawMessage
+	if bytes.HasPrefix(raw, []byte("[")) {
+		if err := json.Unmarshal(raw, &snapshots); err != nil {
+			return nil, err
+		}
+		return snapshots, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	for {
+		var s json.RawMessage
+		if err := dec.Decode(&s); err == io.EOF {
+			return snapshots, nil
+		} else if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+}
+
+// fleetRecord переводит снимок в запись парка. Имя хоста берется из снимка,
+// а у снимков старых версий batmon без hostname – из host.
+func fleetRecord(raw json.RawMessage, host string) (FleetRecord, error) {
+	var s BatteryStatus
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return FleetRecord{}, err
+	}
+	if s.Timestamp == "" {
+		return FleetRecord{}, errors.New(T("fleet.err.not_status"))
+	}
+	if s.Hostname != "" {
+		host = s.Hostname
+	}
+	return FleetRecord{
+		Host:           host,
+		Timestamp:      parseStoredTime(s.Timestamp).UTC().Format(time.RFC3339),
+		ImportedAt:     timeNow().UTC().Format(time.RFC3339),
+		Model:          s.Model,
+		Serial:         s.Serial,
+		Percentage:     s.Percentage,
+		CycleCount:     s.CycleCount,
+		FullChargeCap:  s.FullChargeCapacity,
+		DesignCapacity: s.DesignCapacity,
+		Wear:           s.WearPercent,
+		HealthScore:    s.HealthScore,
+		Condition:      s.Condition,
+		Status:         string(raw),
+	}, nil
+}
+
+// importFleetFiles импортирует снимки из файлов и папок (в папках – все
+// *.json). host – имя машины для снимков без hostname; пусто – имя файла.
+func importFleetFiles(db *sqlx.DB, paths []string, host string) (*FleetImportResult, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+
+	result := &FleetImportResult{}
+	hosts := map[string]bool{}
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		snapshots, err := parseFleetSnapshots(raw)
+		if err != nil {
+			return nil, fmt.Errorf(T("fleet.err.json"), file, err)
+		}
+		fileHost := host
+		if fileHost == "" {
+			fileHost = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		for _, snapshot := range snapshots {
+			r, err := fleetRecord(snapshot, fileHost)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			res, err := db.NamedExec(`INSERT OR IGNORE INTO fleet_status (host, timestamp, imported_at, model, serial,
+				percentage, cycle_count, full_charge_capacity, design_capacity, wear, health_score, condition, status)
+				VALUES (:host, :timestamp, :imported_at, :model, :serial, :percentage, :cycle_count,
+				:full_charge_capacity, :design_capacity, :wear, :health_score, :condition, :status)`, r)
+			if err != nil {
+				return nil, fmt.Errorf(T("fleet.err.save"), r.Host, err)
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				result.Duplicates++
+				continue
+			}
+			result.Imported++
+			if !hosts[r.Host] {
+				hosts[r.Host] = true
+				result.Hosts = append(result.Hosts, r.Host)
+			}
+		}
+		result.Files++
+	}
+	sort.Strings(result.Hosts)
+	return result, nil
+}
+
+// fleetLatest возвращает последний снимок каждой машины
+func fleetLatest(db *sqlx.DB) ([]FleetRecord, error) {
+	var records []FleetRecord
+	err := db.Select(&records, `SELECT f.* FROM fleet_status f
+		JOIN (SELECT host, MAX(timestamp) AS timestamp FROM fleet_status GROUP BY host) l
+		ON f.host = l.host AND f.timestamp = l.timestamp ORDER BY f.host`)
+	if err != nil {
+		return nil, fmt.Errorf(T("fleet.err.read"), err)
+	}
+	return records, nil
+}
+
+// sortFleet упорядочивает машины: проблемные первыми (по износу, циклам
+// или рейтингу) либо по имени хоста
+func sortFleet(records []FleetRecord, by string) error {
+	var less func(a, b FleetRecord) bool
+	switch by {
+	case "wear":
+		less = func(a, b FleetRecord) bool { return a.Wear > b.Wear }
+	case "cycles":
+		less = func(a, b FleetRecord) bool { return a.CycleCount > b.CycleCount }
+	case "health":
+		// машины без рейтинга – в конце
+		less = func(a, b FleetRecord) bool {
+			return a.HealthScore > 0 && (b.HealthScore <= 0 || a.HealthScore < b.HealthScore)
+		}
+	case "host":
+		less = func(a, b FleetRecord) bool { return a.Host < b.Host }
+	default:
+		return fmt.Errorf(T("fleet.err.sort"), by)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return less(records[i], records[j]) })
+	return nil
+}
+
+// fleetMarker возвращает значок состояния машины
+func fleetMarker(code int) string {
+	switch code {
+	case checkCritical:
+		return "⛔"
+	case checkWarning:
+		return "⚠️"
+	}
+	return "✅"
+}
+
+// exportFleetMarkdown сохраняет таблицу сравнения в Markdown
+func exportFleetMarkdown(records []FleetRecord, t HealthThresholds, filename string) error {
+	var b strings.Builder
+	b.WriteString(T("fleet.md.title"))
+	fmt.Fprintf(&b, T("fleet.md.generated"), timeNow().Format("02.01.2006 15:04"))
+	fmt.Fprintf(&b, T("fleet.md.machines"), len(records))
+	b.WriteString(T("fleet.md.header"))
+	b.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for _, r := range records {
+		check := r.Check(t)
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %.1f%% | %s | %s | %s |\n", fleetMarker(check.Code), r.Host, r.ModelName(),
+			r.CycleCount, r.Wear, r.Score(), r.Date(), strings.Join(check.Problems, ", "))
+	}
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := io.WriteString(w, b.String())
+		return err
+	}, nil)
+}
+
+// printFleet выводит таблицу сравнения в терминал
+func printFleet(records []FleetRecord, t HealthThresholds) {
+	hostWidth, modelWidth := utf8.RuneCountInString(T("fleet.col.host")), utf8.RuneCountInString(T("fleet.col.model"))
+	for _, r := range records {
+		hostWidth = max(hostWidth, utf8.RuneCountInString(r.Host))
+		modelWidth = max(modelWidth, utf8.RuneCountInString(r.ModelName()))
+	}
+	fmt.Printf("   %-*s  %-*s  %6s  %6s  %7s  %s\n", hostWidth, T("fleet.col.host"), modelWidth, T("fleet.col.model"), T("fleet.col.cycles"), T("fleet.col.wear"), T("fleet.col.score"), T("fleet.col.snapshot"))
+	attention := 0
+	for _, r := range records {
+		check := r.Check(t)
+		line := fmt.Sprintf("%-*s  %-*s  %6d  %5.1f%%  %7s  %s", hostWidth, r.Host, modelWidth, r.ModelName(),
+			r.CycleCount, r.Wear, r.Score(), r.Date())
+		switch check.Code {
+		case checkCritical:
+			color.Red("%s %s", fleetMarker(check.Code), line)
+		case checkWarning:
+			color.Yellow("%s %s", fleetMarker(check.Code), line)
+		default:
+			fmt.Printf("%s %s\n", fleetMarker(check.Code), line)
+		}
+		if check.Code != checkOK {
+			attention++
+		}
+	}
+	fmt.Println(T("fleet.summary",
+		len(records), attention, t.WearWarning, t.CyclesWarning))
+}
+
+// runFleetCommand – batmon fleet import|report|remove
+func runFleetCommand(args []string) error {
+	fs := newCommandFlags("fleet")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	action := "report"
+	if fs.NArg() > 0 {
+		action = fs.Arg(0)
+	}
+	rest := fs.Args()
+	if len(rest) > 0 {
+		rest = rest[1:]
+	}
+	switch action {
+	case "import":
+		return runFleetImportCommand(rest)
+	case "report":
+		return runFleetReportCommand(rest)
+	case "remove":
+		return runFleetRemoveCommand(rest)
+	}
+	fmt.Fprintln(os.Stderr, T("fleet.unknown_action", action))
+	return errUsage
+}
+
+// runFleetImportCommand импортирует снимки статуса других машин
+func runFleetImportCommand(args []string) error {
+	fs := newCommandFlags("fleet import")
+	host := fs.String("host", "", localized("flag.fleet.import.host", "имя машины для снимков без hostname (по умолчанию – имя файла)"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, T("fleet.import.usage"))
+		return errUsage
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	result, err := importFleetFiles(db, fs.Args(), *host)
+	if err != nil {
+		return err
+	}
+	color.Green(T("fleet.imported"), result.Files, result.Imported, result.Duplicates)
+	if len(result.Hosts) > 0 {
+		fmt.Println(T("fleet.hosts", strings.Join(result.Hosts, ", ")))
+	}
+	return nil
+}
+
+// runFleetReportCommand выводит таблицу сравнения машин
+func runFleetReportCommand(args []string) error {
+	t := getConfig().Health
+	fs := newCommandFlags("fleet report")
+	sortBy := fs.String("sort", "wear", localized("flag.fleet.report.sort", "порядок: wear, cycles, health или host"))
+	md := fs.String("md", "", localized("flag.fleet.report.md", "сохранить таблицу в Markdown"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	records, err := fleetLatest(db)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println(T("fleet.empty"))
+		return nil
+	}
+	if err := sortFleet(records, *sortBy); err != nil {
+		return err
+	}
+	if *md != "" {
+		if err := exportFleetMarkdown(records, t, *md); err != nil {
+			return fmt.Errorf(T("fleet.err.export"), err)
+		}
+		fmt.Println(T("fleet.saved", *md))
+		return nil
+	}
+	printFleet(records, t)
+	return nil
+}
+
+// runFleetRemoveCommand удаляет снимки машины, списанной из парка
+func runFleetRemoveCommand(args []string) error {
+	fs := newCommandFlags("fleet remove")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	host := fs.Arg(0)
+	if host == "" {
+		fmt.Fprintln(os.Stderr, T("fleet.remove.usage"))
+		return errUsage
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	res, err := db.Exec(`DELETE FROM fleet_status WHERE host = ?`, host)
+	if err != nil {
+		return fmt.Errorf(T("fleet.err.remove"), err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf(T("fleet.err.no_host"), host)
+	}
+	color.Green(T("fleet.removed"), host)
+	return nil
+}
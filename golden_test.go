@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+var updateGolden = flag.Bool("update", false, "перезаписать эталонные отчеты в testdata/golden")
+
+// assertGolden сравнивает файл отчета с эталоном testdata/golden/name;
+// с флагом -update эталон перезаписывается
+func assertGolden(t *testing.T, path, name string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("чтение %s: %v", path, err)
+	}
+	golden := filepath.Join("testdata", "golden", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatalf("запись эталона: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("эталон %s: %v (go test -run %s -update)", golden, err, t.Name())
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s отличается от эталона %s; проверьте diff и обновите его: go test -run %s -update",
+			filepath.Base(path), golden, t.Name())
+	}
+}
+
+func TestGoldenReports(t *testing.T) {
+	const step = 10 * time.Minute
+	cases := []struct {
+		name string
+		ms   []Measurement
+		lang string // LANG отчета; пусто – русский
+	}{
+		{name: "steady", ms: steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 60)},
+		{name: "noisy", ms: noisyDischarge(fixtureHealthyBattery, fixtureStart, step, 60, 1)},
+		{name: "replacement", ms: replacementHistory(fixtureStart, step, 40)},
+		{name: "replacement_en", ms: replacementHistory(fixtureStart, step, 40), lang: "en_US.UTF-8"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			last := parseStoredTime(tc.ms[len(tc.ms)-1].Timestamp)
+			freezeEnvironment(t, last.Add(step))
+			if tc.lang != "" {
+				t.Setenv("LANG", tc.lang)
+			}
+			db := newTestDB(t)
+			insertFixture(t, db, tc.ms)
+
+			data, err := generateReportData(db)
+			if err != nil {
+				t.Fatalf("generateReportData: %v", err)
+			}
+			dir := t.TempDir()
+			md, html := filepath.Join(dir, "report.md"), filepath.Join(dir, "report.html")
+			if err := exportToMarkdown(data, md); err != nil {
+				t.Fatalf("exportToMarkdown: %v", err)
+			}
+			if err := exportToHTML(data, html); err != nil {
+				t.Fatalf("exportToHTML: %v", err)
+			}
+			assertGolden(t, md, tc.name+".md")
+			assertGolden(t, html, tc.name+".html")
+		})
+	}
+}
+
+// Текстовый отчет batmon report на английском не содержит русских строк
+func TestPrintReportEnglish(t *testing.T) {
+	ms := replacementHistory(fixtureStart, 10*time.Minute, 40)
+	freezeEnvironment(t, parseStoredTime(ms[len(ms)-1].Timestamp).Add(10*time.Minute))
+	t.Setenv("LANG", "en_US.UTF-8")
+	db := newTestDB(t)
+	insertFixture(t, db, ms)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdout, origColor := os.Stdout, color.Output
+	os.Stdout, color.Output = w, w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	err = printReport(db, ReportRange{From: fixtureStart}, "")
+	os.Stdout, color.Output = origStdout, origColor
+	w.Close()
+	out := <-done
+	if err != nil {
+		t.Fatalf("printReport: %v", err)
+	}
+
+	cyrillic := regexp.MustCompile(`[А-Яа-яЁё]`)
+	for _, line := range strings.Split(string(out), "\n") {
+		if cyrillic.MatchString(line) {
+			t.Errorf("русская строка в английском отчете: %q", line)
+		}
+	}
+	if !strings.Contains(string(out), "=== SUMMARY ===") {
+		t.Errorf("нет английского резюме:\n%s", out)
+	}
+}
@@ -0,0 +1,73 @@
+// health_breakdown.go
+//
+// Разбор рейтинга здоровья по факторам. Одно число без объяснения вызывает
+// вопросы: почему 43, если износ всего 10%? Поэтому вместе с рейтингом
+// сохраняется список факторов с их вкладом в баллах, а вкладки «Обзор» и
+// «Метрики» показывают его под рейтингом. Вклады в сумме дают рейтинг минус
+// 100. Основной рейтинг раскладывается по факторам своей модели (у эвристики –
+// износ, циклы, аномалии и деградация, см. health_models.go), рейтинг вкладки
+// «Метрики» – на штрафы за износ, циклы, температуру и стабильность напряжения.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ScoreFactor – вклад одного фактора в рейтинг здоровья
+type ScoreFactor struct {
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"` // значение, от которого зависит вклад
+	Points int    `json:"points"`           // вклад в баллах; отрицательный – штраф
+}
+
+// scoreFromFactors возвращает рейтинг: 100 плюс вклады факторов, не ниже 0
+func scoreFromFactors(factors []ScoreFactor) int {
+	score := 100
+	for _, f := range factors {
+		score += f.Points
+	}
+	return max(score, 0)
+}
+
+// formatScorePoints форматирует вклад со знаком: −12, +3, 0
+func formatScorePoints(points int) string {
+	switch {
+	case points < 0:
+		return fmt.Sprintf("−%d", -points)
+	case points > 0:
+		return fmt.Sprintf("+%d", points)
+	}
+	return "0"
+}
+
+// renderScoreBreakdown рендерит факторы рейтинга строками «фактор (значение) вклад»;
+// prefix ставится в начало каждой строки (рамка панели «Обзор»)
+func renderScoreBreakdown(factors []ScoreFactor, prefix string) string {
+	width := 0
+	labels := make([]string, len(factors))
+	for i, f := range factors {
+		labels[i] = f.Name
+		if f.Detail != "" {
+			labels[i] += " (" + f.Detail + ")"
+		}
+		width = max(width, lipgloss.Width(labels[i]))
+	}
+	var content strings.Builder
+	for i, f := range factors {
+		color := theme.Muted
+		switch {
+		case f.Points <= -10:
+			color = theme.Critical
+		case f.Points < 0:
+			color = theme.Warning
+		}
+		pad := strings.Repeat(" ", width-lipgloss.Width(labels[i]))
+		content.WriteString(fmt.Sprintf("%s  %s%s %s\n", prefix, labels[i], pad,
+			lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("%4s", formatScorePoints(f.Points)))))
+	}
+	return content.String()
+}
@@ -0,0 +1,219 @@
+// health_models.go
+//
+// Модели рейтинга здоровья. Политики замены батарей у всех разные: одним
+// важен только остаток ёмкости, другим – ресурс циклов, третьи доверяют
+// оценке macOS. Модель выбирается в config.json (health.score_model) или на
+// экране настроек, а отчеты показывают рядом с рейтингом, какая модель его
+// посчитала:
+//
+//	heuristic        – эвристика batmon: износ и циклы, штрафы за аномалии и деградацию
+//	apple_condition  – состояние батареи по macOS плюс износ
+//	capacity         – только остаточная ёмкость
+//	cycles           – в основном циклы относительно ресурса health.cycles_critical
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// HealthModel – модель рейтинга здоровья
+type HealthModel string
+
+const (
+	healthModelHeuristic HealthModel = "heuristic"
+	healthModelApple     HealthModel = "apple_condition"
+	healthModelCapacity  HealthModel = "capacity"
+	healthModelCycles    HealthModel = "cycles"
+)
+
+// healthModels – модели по кругу для переключения
+var healthModels = []HealthModel{healthModelHeuristic, healthModelApple, healthModelCapacity, healthModelCycles}
+
+// normalize заменяет неизвестную модель моделью по умолчанию
+func (m HealthModel) normalize() HealthModel {
+	for _, model := range healthModels {
+		if m == model {
+			return m
+		}
+	}
+	return healthModelHeuristic
+}
+
+// Next возвращает следующую модель по кругу; delta < 0 – предыдущую
+func (m HealthModel) Next(delta int) HealthModel {
+	for i, model := range healthModels {
+		if model == m.normalize() {
+			return healthModels[(i+delta+len(healthModels))%len(healthModels)]
+		}
+	}
+	return healthModelHeuristic
+}
+
+// Label возвращает название модели на текущем языке
+func (m HealthModel) Label() string {
+	return T("health_model." + string(m.normalize()))
+}
+
+// healthScoreInput – показатели, из которых модели считают рейтинг
+type healthScoreInput struct {
+	Wear      float64 // износ, %
+	Cycles    int
+	Condition string // состояние по macOS: Normal, Service Recommended…
+	Anomalies int
+	Trend     TrendAnalysis
+	CycleLife int // ресурс циклов батареи
+}
+
+// healthScore – рейтинг, посчитанный моделью
+type healthScore struct {
+	Model     HealthModel
+	Status    string
+	Score     int
+	Breakdown []ScoreFactor
+}
+
+// scoreHealth считает рейтинг выбранной моделью
+func scoreHealth(model HealthModel, in healthScoreInput) healthScore {
+	model = model.normalize()
+	var s healthScore
+	switch model {
+	case healthModelApple:
+		s = scoreAppleCondition(in)
+	case healthModelCapacity:
+		s = scoreCapacity(in)
+	case healthModelCycles:
+		s = scoreCycles(in)
+	default:
+		s = scoreHeuristic(in)
+	}
+	s.Model = model
+	return s
+}
+
+// heuristicSteps – ступени эвристики: износ и циклы, ниже которых батарея
+// остается на ступени, рейтинг и оценка ступени
+var heuristicSteps = []struct {
+	wear   float64
+	cycles int
+	score  int
+	status string // ключ каталога
+}{
+	{5, 300, 95, "health.excellent"},
+	{10, 500, 85, "health.good"},
+	{20, 800, 70, "health.fair"},
+	{30, 1200, 50, "health.attention"},
+	{math.Inf(1), math.MaxInt, 30, "health.poor"},
+}
+
+// heuristicStep возвращает первую ступень, до порога которой не дошел показатель
+func heuristicStep(below func(i int) bool) int {
+	for i := range heuristicSteps {
+		if below(i) {
+			return i
+		}
+	}
+	return len(heuristicSteps) - 1
+}
+
+// scoreHeuristic – прежняя формула batmon: ступени по износу и циклам,
+// штрафы за нестабильную работу и быструю деградацию. Ступень задает худший
+// из двух показателей: износ получает штраф своей ступени, а циклы – разницу,
+// на которую они опускают рейтинг ниже.
+func scoreHeuristic(in healthScoreInput) healthScore {
+	wearStep := heuristicStep(func(i int) bool { return in.Wear < heuristicSteps[i].wear })
+	cycleStep := heuristicStep(func(i int) bool { return in.Cycles < heuristicSteps[i].cycles })
+	step := heuristicSteps[max(wearStep, cycleStep)]
+	wearScore := heuristicSteps[wearStep].score
+
+	s := healthScore{Status: T(step.status), Score: step.score}
+	s.Breakdown = []ScoreFactor{
+		{T("score.wear"), fmt.Sprintf("%.1f%%", in.Wear), wearScore - 100},
+		{T("score.cycles"), fmt.Sprint(in.Cycles), step.score - wearScore},
+	}
+
+	// Корректировка на основе аномалий
+	if in.Anomalies > 5 {
+		s.Score -= 10
+		s.Status += T("health.unstable")
+		s.Breakdown = append(s.Breakdown, ScoreFactor{T("score.anomalies"), fmt.Sprint(in.Anomalies), -10})
+	}
+
+	// Корректировка на основе тренда
+	if !in.Trend.IsHealthy && in.Trend.DegradationRate < -1.0 {
+		s.Score -= 15
+		s.Status += T("health.fast_degradation")
+		s.Breakdown = append(s.Breakdown,
+			ScoreFactor{T("score.degradation"), T("score.degradation.value", in.Trend.DegradationRate), -15})
+	}
+	return s
+}
+
+// appleConditionPenalty – штраф за состояние батареи по оценке macOS
+func appleConditionPenalty(condition string) int {
+	switch strings.ToLower(strings.TrimSpace(condition)) {
+	case "replace soon":
+		return 40
+	case "service recommended", "service battery", "check battery":
+		return 60
+	case "replace now":
+		return 70
+	}
+	return 0 // Normal или нет данных
+}
+
+// scoreAppleCondition – оценка macOS определяет рейтинг, износ уточняет его
+// внутри состояния
+func scoreAppleCondition(in healthScoreInput) healthScore {
+	condition := strings.TrimSpace(in.Condition)
+	if condition == "" {
+		condition = T("score.no_data")
+	}
+	factors := []ScoreFactor{
+		{T("score.apple_condition"), condition, -appleConditionPenalty(in.Condition)},
+		{T("score.wear"), fmt.Sprintf("%.1f%%", in.Wear), -int(math.Round(math.Min(in.Wear, 100)))},
+	}
+	return scoreByFactors(factors)
+}
+
+// scoreCapacity – рейтинг равен остаточной ёмкости в процентах от проектной
+func scoreCapacity(in healthScoreInput) healthScore {
+	return scoreByFactors([]ScoreFactor{
+		{T("score.wear"), fmt.Sprintf("%.1f%%", in.Wear), -int(math.Round(math.Min(in.Wear, 100)))},
+	})
+}
+
+// scoreCycles – 70 баллов зависят от израсходованного ресурса циклов и
+// 30 – от износа (полный штраф с 30%)
+func scoreCycles(in healthScoreInput) healthScore {
+	life := in.CycleLife
+	if life <= 0 {
+		life = defaultHealthThresholds().CyclesCritical
+	}
+	used := math.Min(float64(in.Cycles)/float64(life), 1)
+	return scoreByFactors([]ScoreFactor{
+		{T("score.cycles"), T("score.cycles.value", in.Cycles, life), -int(math.Round(used * 70))},
+		{T("score.wear"), fmt.Sprintf("%.1f%%", in.Wear), -int(math.Round(math.Min(in.Wear, 30)))},
+	})
+}
+
+// scoreByFactors считает рейтинг по факторам и подбирает к нему словесную оценку
+func scoreByFactors(factors []ScoreFactor) healthScore {
+	score := scoreFromFactors(factors)
+	var status string
+	switch {
+	case score >= 90:
+		status = T("health.excellent")
+	case score >= 80:
+		status = T("health.good")
+	case score >= 65:
+		status = T("health.fair")
+	case score >= 45:
+		status = T("health.attention")
+	default:
+		status = T("health.poor")
+	}
+	return healthScore{Status: status, Score: score, Breakdown: factors}
+}
@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestHealthScoreModels(t *testing.T) {
+	in := healthScoreInput{Wear: 12, Cycles: 500, Condition: "Service Recommended", Anomalies: 6, CycleLife: 1000}
+	cases := []struct {
+		model  HealthModel
+		score  int
+		status string
+	}{
+		{healthModelHeuristic, 60, "Удовлетворительное (нестабильная работа)"},
+		{healthModelApple, 28, "Плохое"},
+		{healthModelCapacity, 88, "Хорошее"},
+		{healthModelCycles, 53, "Требует внимания"},
+		{"unknown", 60, "Удовлетворительное (нестабильная работа)"},
+	}
+	for _, c := range cases {
+		got := scoreHealth(c.model, in)
+		if got.Score != c.score || got.Status != c.status {
+			t.Errorf("%s: рейтинг %d %q, ожидалось %d %q", c.model, got.Score, got.Status, c.score, c.status)
+		}
+		if sum := scoreFromFactors(got.Breakdown); sum != got.Score {
+			t.Errorf("%s: сумма факторов %d не равна рейтингу %d", c.model, sum, got.Score)
+		}
+	}
+	if got := scoreHealth("unknown", in).Model; got != healthModelHeuristic {
+		t.Errorf("неизвестная модель заменена на %q", got)
+	}
+}
+
+// У эвристики каждый штраф – отдельный фактор, и вместе они дают рейтинг
+func TestHeuristicBreakdown(t *testing.T) {
+	freezeEnvironment(t, fixtureStart)
+	cases := []struct {
+		in     healthScoreInput
+		points []int // износ, циклы, затем аномалии и деградация
+	}{
+		{healthScoreInput{Wear: 2, Cycles: 120}, []int{-5, 0}},
+		{healthScoreInput{Wear: 3, Cycles: 900}, []int{-5, -45}},
+		{healthScoreInput{Wear: 25, Cycles: 100}, []int{-50, 0}},
+		{healthScoreInput{Wear: 12, Cycles: 500, Anomalies: 6}, []int{-30, 0, -10}},
+		{healthScoreInput{Wear: 8, Cycles: 1300, Anomalies: 9,
+			Trend: TrendAnalysis{DegradationRate: -2}}, []int{-15, -55, -10, -15}},
+	}
+	for _, c := range cases {
+		got := scoreHeuristic(c.in)
+		if len(got.Breakdown) != len(c.points) {
+			t.Fatalf("%+v: факторы %+v", c.in, got.Breakdown)
+		}
+		sum := 0
+		for i, f := range got.Breakdown {
+			if f.Points != c.points[i] {
+				t.Errorf("%+v: %s %d, ожидалось %d", c.in, f.Name, f.Points, c.points[i])
+			}
+			sum += f.Points
+		}
+		if sum != got.Score-100 {
+			t.Errorf("%+v: сумма факторов %d, рейтинг %d", c.in, sum, got.Score)
+		}
+	}
+	if f := scoreHeuristic(cases[1].in).Breakdown; f[0].Name != "Износ" || f[1].Name != "Циклы" {
+		t.Errorf("факторы %+v", f)
+	}
+}
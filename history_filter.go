@@ -0,0 +1,165 @@
+// history_filter.go
+//
+// Фильтр вкладки «История»: выражения вида "temp>40 state=discharging
+// from=7d" переводятся в условия SQL-запроса к таблице measurements, так что
+// фильтр применяется ко всей базе, а не к загруженной странице. Условия через
+// пробел или запятую объединяются по И.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyFilterFields – поля фильтра: столбец measurements и множитель
+// значения (мощность вводится в ваттах, а хранится в милливаттах)
+var historyFilterFields = map[string]struct {
+	column string
+	scale  float64 // 0 – текстовое поле
+}{
+	"temp":        {"temperature", 1},
+	"temperature": {"temperature", 1},
+	"charge":      {"percentage", 1},
+	"percent":     {"percentage", 1},
+	"cycles":      {"cycle_count", 1},
+	"capacity":    {"full_charge_capacity", 1},
+	"voltage":     {"voltage", 1},
+	"amperage":    {"amperage", 1},
+	"power":       {"power", 1000},
+	"brightness":  {"brightness", 1},
+	"state":       {"state", 0},
+	"lid":         {"lid_state", 0},
+}
+
+// historyFilterOps – операторы сравнения; двухсимвольные проверяются первыми
+var historyFilterOps = []string{">=", "<=", "!=", "=", ">", "<"}
+
+// historyFilter – разобранный фильтр истории
+type historyFilter struct {
+	conds []string
+	args  []interface{}
+}
+
+// parseHistoryFilter разбирает выражение фильтра. Поддерживаются сравнения
+// полей historyFilterFields (temp>40, state=discharging, power>=20),
+// границы времени from=/to= и time с операторами <, <=, >, >= в формате
+// --from/--to (7d, 24h, 14:00, 2025-01-31).
+func parseHistoryFilter(expr string, now time.Time) (historyFilter, error) {
+	var f historyFilter
+	terms := strings.FieldsFunc(expr, func(r rune) bool { return r == ' ' || r == ',' })
+	for _, term := range terms {
+		field, op, value, ok := splitHistoryTerm(term)
+		if !ok {
+			return historyFilter{}, fmt.Errorf(T("filter.err.operator"), term)
+		}
+		field = strings.ToLower(field)
+
+		switch field {
+		case "from", "to":
+			if op != "=" {
+				return historyFilter{}, fmt.Errorf(T("filter.err.use_eq"), term, field)
+			}
+			op = ">="
+			if field == "to" {
+				op = "<="
+			}
+			fallthrough
+		case "time":
+			if op == "=" || op == "!=" {
+				return historyFilter{}, fmt.Errorf(T("filter.err.time_op"), term)
+			}
+			t, err := parseReportTime(value, now, op == "<=" || op == "<")
+			if err != nil {
+				return historyFilter{}, fmt.Errorf(T("filter.err.condition"), term, err)
+			}
+			f.conds = append(f.conds, "timestamp "+op+" ?")
+			f.args = append(f.args, t.UTC().Format(time.RFC3339))
+			continue
+		}
+
+		def, known := historyFilterFields[field]
+		if !known {
+			return historyFilter{}, fmt.Errorf(T("filter.err.field"), field, historyFilterFieldNames())
+		}
+		if def.scale == 0 {
+			if op != "=" && op != "!=" {
+				return historyFilter{}, fmt.Errorf(T("filter.err.text_op"), term)
+			}
+			f.conds = append(f.conds, def.column+" "+op+" ?")
+			f.args = append(f.args, value)
+			continue
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return historyFilter{}, fmt.Errorf(T("filter.err.number"), term, value)
+		}
+		f.conds = append(f.conds, def.column+" "+op+" ?")
+		f.args = append(f.args, n*def.scale)
+	}
+	return f, nil
+}
+
+// splitHistoryTerm делит условие на поле, оператор и значение
+func splitHistoryTerm(term string) (field, op, value string, ok bool) {
+	for i := range term {
+		for _, candidate := range historyFilterOps {
+			if strings.HasPrefix(term[i:], candidate) {
+				field, value = term[:i], term[i+len(candidate):]
+				return field, candidate, value, field != "" && value != ""
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// historyFilterFieldNames возвращает поля фильтра через запятую
+func historyFilterFieldNames() string {
+	return "temp, charge, cycles, capacity, voltage, amperage, power, brightness, state, lid"
+}
+
+// newHistoryFilterInput создает поле ввода фильтра истории
+func newHistoryFilterInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "temp>40 state=discharging from=7d"
+	input.Prompt = "/ "
+	input.CharLimit = 256
+	input.Width = 50
+	return input
+}
+
+// updateHistoryFilterInput обрабатывает ввод в поле фильтра: Enter применяет
+// выражение, Esc отменяет правку
+func (a *App) updateHistoryFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		a.state = StateMenu
+		return a, nil
+	case "esc":
+		a.report.filterInput.SetValue(a.report.filterExpr)
+		a.report.filterInput.Blur()
+		a.report.filterErr = nil
+		return a, nil
+	case "enter":
+		expr := strings.TrimSpace(a.report.filterInput.Value())
+		filter, err := parseHistoryFilter(expr, time.Now())
+		if err != nil {
+			a.report.filterErr = err
+			return a, nil
+		}
+		a.report.filterExpr = expr
+		a.report.filter = filter
+		a.report.filterErr = nil
+		a.report.filterInput.Blur()
+		a.resetHistoryPage()
+		return a, nil
+	}
+	var cmd tea.Cmd
+	a.report.filterInput, cmd = a.report.filterInput.Update(msg)
+	return a, cmd
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHistoryFilter(t *testing.T) {
+	freezeEnvironment(t, fixtureStart)
+	now := fixtureStart.Add(10 * time.Hour) // 18:00 UTC
+	utc := func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+
+	cases := []struct {
+		expr  string
+		conds []string
+		args  []interface{}
+	}{
+		{"", nil, nil},
+		{"temp>40", []string{"temperature > ?"}, []interface{}{40.0}},
+		{"TEMP>=40.5, charge<20", []string{"temperature >= ?", "percentage < ?"}, []interface{}{40.5, 20.0}},
+		{"power>=20", []string{"power >= ?"}, []interface{}{20000.0}},
+		{"state=discharging lid!=closed", []string{"state = ?", "lid_state != ?"}, []interface{}{"discharging", "closed"}},
+		{"cycles!=0", []string{"cycle_count != ?"}, []interface{}{0.0}},
+		{"from=7d", []string{"timestamp >= ?"}, []interface{}{utc(now.AddDate(0, 0, -7))}},
+		{"from=24h to=now", []string{"timestamp >= ?", "timestamp <= ?"},
+			[]interface{}{utc(now.Add(-24 * time.Hour)), utc(now)}},
+		{"to=2025-03-01", []string{"timestamp <= ?"}, []interface{}{"2025-03-01T23:59:59Z"}},
+		{"time>14:00", []string{"timestamp > ?"}, []interface{}{"2025-03-03T14:00:00Z"}},
+	}
+	for _, c := range cases {
+		f, err := parseHistoryFilter(c.expr, now)
+		if err != nil {
+			t.Errorf("%q: %v", c.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(f.conds, c.conds) || !reflect.DeepEqual(f.args, c.args) {
+			t.Errorf("%q: условия %q %v, ожидались %q %v", c.expr, f.conds, f.args, c.conds, c.args)
+		}
+	}
+
+	errCases := []struct {
+		expr string
+		want string // подстрока ошибки
+	}{
+		{"temp", "нужен оператор"},
+		{"temp>", "нужен оператор"},
+		{">40", "нужен оператор"},
+		{"timestamp>0", "неизвестное поле"},
+		{"1=1", "неизвестное поле"},
+		{"temperature;DROP>1", "неизвестное поле"},
+		{"temp>hot", "не число"},
+		{"state>discharging", "текстовое поле"},
+		{"from>7d", "используйте ="},
+		{"time=14:00", "через <, <=, >, >="},
+		{"from=-3d", "не удалось разобрать время"},
+		{"from=7days", "не удалось разобрать время"},
+		{"to=2025-13-40", "не удалось разобрать время"},
+	}
+	for _, c := range errCases {
+		if _, err := parseHistoryFilter(c.expr, now); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q: ошибка %v, ожидалась %q", c.expr, err, c.want)
+		}
+	}
+}
+
+// Условия фильтра выполняются запросом истории к базе
+func TestHistoryFilterQuery(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(24*time.Hour))
+	db := newTestDB(t)
+	ms := steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12)
+	for i := range ms {
+		ms[i].Temperature = 30 + i
+	}
+	ms[11].State = "charging"
+	insertFixture(t, db, ms)
+
+	f, err := parseHistoryFilter("temp>=35 state=discharging", timeNow())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := countHistory(db, historyQuery{filter: f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 { // 35–40°C, измерение на 41°C заряжается
+		t.Errorf("подходит %d измерений, ожидалось 6", n)
+	}
+}
@@ -0,0 +1,284 @@
+// history_table.go
+//
+// Вкладка «История» отчета: таблица измерений с сортировкой по любой колонке
+// и постраничным просмотром всей базы. Каждая страница читается из БД
+// отдельным запросом с ORDER BY по выбранной колонке и LIMIT/OFFSET, поэтому
+// в памяти держится только видимая страница. Enter открывает карточку
+// выбранного измерения со всеми полями.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jmoiron/sqlx"
+)
+
+// historyColumns – колонки таблицы истории и выражения сортировки для них
+var historyColumns = []struct {
+	title string // ключ перевода заголовка
+	order string
+}{
+	{"history.col.time", "timestamp"},
+	{"history.col.charge", "percentage"},
+	{"history.col.state", "state"},
+	{"history.col.cycles", "cycle_count"},
+	{"history.col.temp", "temperature"},
+	{"history.col.wear", "1.0 - CAST(full_charge_capacity AS REAL) / NULLIF(design_capacity, 0)"},
+}
+
+// historyQuery – параметры выборки страницы истории
+type historyQuery struct {
+	rng    ReportRange // пустой – вся база
+	state  string      // "" – все состояния
+	filter historyFilter
+	column int // индекс в historyColumns
+	desc   bool
+	offset int
+	limit  int
+}
+
+// where возвращает условие WHERE (пустое – без условий) и его аргументы
+func (q historyQuery) where() (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if !q.rng.From.IsZero() {
+		conds = append(conds, "timestamp >= ?")
+		args = append(args, q.rng.From.UTC().Format(time.RFC3339))
+	}
+	if !q.rng.To.IsZero() {
+		conds = append(conds, "timestamp <= ?")
+		args = append(args, q.rng.To.UTC().Format(time.RFC3339))
+	}
+	if q.state != "" {
+		conds = append(conds, "state = ?")
+		args = append(args, q.state)
+	}
+	conds = append(conds, q.filter.conds...)
+	args = append(args, q.filter.args...)
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// countHistory возвращает число измерений, подходящих под запрос
+func countHistory(db *sqlx.DB, q historyQuery) (int, error) {
+	where, args := q.where()
+	var n int
+	if err := db.Get(&n, "SELECT COUNT(*) FROM measurements"+where, args...); err != nil {
+		return 0, fmt.Errorf(T("history.err.count"), err)
+	}
+	return n, nil
+}
+
+// queryHistoryPage возвращает страницу измерений в порядке выбранной колонки.
+// При равных значениях новые измерения идут первыми.
+func queryHistoryPage(db *sqlx.DB, q historyQuery) ([]Measurement, error) {
+	where, args := q.where()
+	dir := "ASC"
+	if q.desc {
+		dir = "DESC"
+	}
+	order := historyColumns[q.column%len(historyColumns)].order
+	query := fmt.Sprintf("SELECT * FROM measurements%s ORDER BY %s %s, timestamp DESC LIMIT ? OFFSET ?", where, order, dir)
+	var ms []Measurement
+	if err := db.Select(&ms, query, append(args, q.limit, q.offset)...); err != nil {
+		return nil, fmt.Errorf(T("history.err.read"), err)
+	}
+	return ms, nil
+}
+
+// historyTableColumns возвращает колонки таблицы истории с отметкой сортировки
+func historyTableColumns(widths []int, sortColumn int, desc bool) []table.Column {
+	columns := make([]table.Column, len(historyColumns))
+	for i, c := range historyColumns {
+		title := T(c.title)
+		if i == sortColumn {
+			if desc {
+				title += "↓"
+			} else {
+				title += "↑"
+			}
+		}
+		columns[i] = table.Column{Title: title, Width: widths[i]}
+	}
+	return columns
+}
+
+// historyPageSize возвращает число строк на странице – сколько помещается в таблицу
+func (a *App) historyPageSize() int {
+	return max(a.report.historyTable.Height(), 5)
+}
+
+// historyQuery собирает запрос текущей страницы из состояния вкладки
+func (a *App) historyQuery() historyQuery {
+	q := historyQuery{
+		rng:    reportRangePreset(a.report.rangePreset, time.Now()),
+		column: a.report.sortColumn,
+		desc:   a.report.sortDesc,
+		filter: a.report.filter,
+		limit:  a.historyPageSize(),
+	}
+	q.offset = a.report.historyPage * q.limit
+	if a.report.filterState != "all" {
+		q.state = a.report.filterState
+	}
+	return q
+}
+
+// historyPageCount возвращает число страниц истории (не меньше одной)
+func (a *App) historyPageCount() int {
+	size := a.historyPageSize()
+	return max((a.report.historyTotal+size-1)/size, 1)
+}
+
+// resetHistoryPage возвращает историю на первую страницу после смены фильтра или сортировки
+func (a *App) resetHistoryPage() {
+	a.report.historyPage = 0
+	a.report.historyTable.SetCursor(0)
+	a.report.historyDetails = false
+}
+
+// moveHistoryCursor двигает курсор таблицы, переходя на соседнюю страницу у краев
+func (a *App) moveHistoryCursor(delta int) {
+	cursor := a.report.historyTable.Cursor()
+	switch {
+	case delta < 0 && cursor == 0:
+		if a.report.historyPage > 0 {
+			a.report.historyPage--
+			a.report.historyTable.SetCursor(a.historyPageSize() - 1)
+		}
+	case delta > 0 && cursor >= len(a.report.historyRows)-1:
+		if a.report.historyPage < a.historyPageCount()-1 {
+			a.report.historyPage++
+			a.report.historyTable.SetCursor(0)
+		}
+	case delta < 0:
+		a.report.historyTable.MoveUp(1)
+	default:
+		a.report.historyTable.MoveDown(1)
+	}
+}
+
+// turnHistoryPage листает историю на delta страниц
+func (a *App) turnHistoryPage(delta int) {
+	page := a.report.historyPage + delta
+	page = max(min(page, a.historyPageCount()-1), 0)
+	if page != a.report.historyPage {
+		a.report.historyPage = page
+		a.report.historyTable.SetCursor(0)
+	}
+}
+
+// selectedHistoryMeasurement возвращает измерение под курсором (nil – страница пуста)
+func (a *App) selectedHistoryMeasurement() *Measurement {
+	cursor := a.report.historyTable.Cursor()
+	if cursor < 0 || cursor >= len(a.report.historyRows) {
+		return nil
+	}
+	return &a.report.historyRows[cursor]
+}
+
+// loadHistoryPage читает из БД текущую страницу истории и общее число записей
+func (a *App) loadHistoryPage() error {
+	db, release, err := a.openReportDB()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	q := a.historyQuery()
+	total, err := countHistory(db, q)
+	if err != nil {
+		return err
+	}
+	a.report.historyTotal = total
+	if a.report.historyPage >= a.historyPageCount() {
+		a.report.historyPage = a.historyPageCount() - 1
+		q.offset = a.report.historyPage * q.limit
+	}
+	rows, err := queryHistoryPage(db, q)
+	if err != nil {
+		return err
+	}
+	a.report.historyRows = rows
+	return nil
+}
+
+// updateHistoryTable обновляет данные в таблице истории
+func (a *App) updateHistoryTable(measurements []Measurement) {
+	widths := a.calculateReportTableColumnWidths(max(a.windowWidth-10, 50))
+	a.report.historyTable.SetColumns(historyTableColumns(widths, a.report.sortColumn, a.report.sortDesc))
+
+	rows := make([]table.Row, 0, len(measurements))
+	for _, m := range measurements {
+		wear := "-"
+		if m.DesignCapacity > 0 {
+			wear = fmt.Sprintf("%.1f%%", computeWear(m.DesignCapacity, m.FullChargeCap))
+		}
+		rows = append(rows, table.Row{
+			parseStoredTime(m.Timestamp).Local().Format("02.01 15:04:05"),
+			fmt.Sprintf("%d%%", m.Percentage),
+			formatBatteryStateShort(m.State),
+			fmt.Sprintf("%d", m.CycleCount),
+			fmt.Sprintf("%d°C", m.Temperature),
+			wear,
+		})
+	}
+	a.report.historyTable.SetRows(rows)
+	if cursor := a.report.historyTable.Cursor(); cursor >= len(rows) {
+		a.report.historyTable.SetCursor(max(len(rows)-1, 0))
+	}
+}
+
+// renderHistoryDetails рендерит карточку выбранного измерения
+func renderHistoryDetails(m Measurement) string {
+	var content strings.Builder
+	line := func(label, value string) {
+		content.WriteString(fmt.Sprintf("%-22s %s\n", label+":", value))
+	}
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+
+	line(T("history.col.time"), parseStoredTime(m.Timestamp).Local().Format("02.01.2006 15:04:05"))
+	line(T("history.col.charge"), fmt.Sprintf("%d%%", m.Percentage))
+	line(T("history.col.state"), formatBatteryStateShort(m.State))
+	line(T("history.col.cycles"), fmt.Sprintf("%d", m.CycleCount))
+	nominal := nominalVoltage(m)
+	line(T("history.detail.current_cap"), formatCapacity(m.CurrentCapacity, nominal))
+	line(T("history.detail.full_cap"), formatCapacity(m.FullChargeCap, nominal))
+	line(T("history.detail.design_cap"), formatCapacity(m.DesignCapacity, nominal))
+	if m.DesignCapacity > 0 {
+		line(T("history.col.wear"), fmt.Sprintf("%.1f%%", computeWear(m.DesignCapacity, m.FullChargeCap)))
+	}
+	line(T("history.detail.temperature"), fmt.Sprintf("%d°C", m.Temperature))
+	line(T("history.detail.voltage"), T("history.detail.voltage.value", m.Voltage))
+	line(T("history.detail.amperage"), T("history.detail.amperage.value", m.Amperage))
+	line(T("history.detail.power"), T("history.detail.power.value", float64(m.Power)/1000))
+	line(T("history.detail.apple"), orDash(m.AppleCondition))
+	line(T("history.detail.serial"), orDash(m.BatterySerial))
+	if m.Brightness > 0 {
+		line(T("history.detail.brightness"), fmt.Sprintf("%d%%", m.Brightness))
+	}
+	line(T("history.detail.lid"), orDash(m.LidState))
+	if spread, ok := cellSpread(m); ok {
+		line(T("history.detail.cells"), T("history.detail.cells.value", strings.ReplaceAll(m.CellVoltages, ",", " / "), spread))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Accent).
+		Padding(0, 1).
+		Render(lipgloss.NewStyle().Bold(true).Render(T("history.detail.title", m.ID)) + "\n\n" +
+			strings.TrimRight(content.String(), "\n") + "\n\n" +
+			lipgloss.NewStyle().Foreground(theme.Muted).Render(T("history.detail.close")))
+}
@@ -0,0 +1,122 @@
+// hooks.go
+//
+// Пользовательские скрипты на события коллектора: аномалия, порог заряда,
+// начало и конец сессии, готовая сводка за день. Пути задаются в разделе
+// hooks файла config.json, данные события передаются через переменные
+// окружения BATMON_*. Так batmon становится источником событий для любой
+// автоматизации, не встраивая каждую интеграцию в себя.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// hookTimeout – время на выполнение скрипта; зависший скрипт завершается
+const hookTimeout = 30 * time.Second
+
+// События для скриптов (значение BATMON_EVENT)
+const (
+	hookAnomaly         = "anomaly"
+	hookChargeThreshold = "charge_threshold"
+	hookSessionStart    = "session_start"
+	hookSessionEnd      = "session_end"
+	hookDailyReport     = "daily_report"
+)
+
+// HooksConfig – скрипты на события; у каждого события может быть несколько
+type HooksConfig struct {
+	Anomaly         []string `json:"anomaly,omitempty"`          // BATMON_MESSAGE, BATMON_TYPE и BATMON_SEVERITY аномалии
+	ChargeThreshold []string `json:"charge_threshold,omitempty"` // BATMON_THRESHOLD – ceiling или floor
+	SessionStart    []string `json:"session_start,omitempty"`    // BATMON_SESSION – discharge, charge или idle
+	SessionEnd      []string `json:"session_end,omitempty"`      // BATMON_SESSION – вид закончившейся сессии
+	DailyReport     []string `json:"daily_report,omitempty"`     // BATMON_DAY и итоги закончившегося дня
+}
+
+// runHooks запускает скрипты события в фоне, не задерживая сбор
+func runHooks(paths []string, event string, m Measurement, vars map[string]string) {
+	for _, path := range paths {
+		if path != "" {
+			go runHook(path, event, m, vars)
+		}
+	}
+}
+
+// runHook запускает скрипт с переменными события и последнего измерения
+func runHook(path, event string, m Measurement, vars map[string]string) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Env = append(os.Environ(),
+		"BATMON_EVENT="+event,
+		"BATMON_TIMESTAMP="+m.Timestamp,
+		"BATMON_PERCENT="+strconv.Itoa(m.Percentage),
+		"BATMON_STATE="+m.State,
+	)
+	for name, value := range vars {
+		cmd.Env = append(cmd.Env, "BATMON_"+name+"="+value)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		logWarnf("⚠️ скрипт %s (%s): %v %s", path, event, err, out)
+	}
+}
+
+// runEventHooks сравнивает два последних измерения и запускает скрипты
+// аномалий, смены сессии и закончившегося дня
+func (dc *DataCollector) runEventHooks() {
+	hooks := getConfig().Hooks
+	last := dc.buffer.GetLast(2)
+	if len(last) < 2 {
+		return
+	}
+	prev, curr := last[0], last[1]
+
+	if len(hooks.Anomaly) > 0 {
+		for _, anomaly := range detectBatteryAnomalies(last) {
+			runHooks(hooks.Anomaly, hookAnomaly, curr, map[string]string{
+				"MESSAGE":  anomaly.Message,
+				"TYPE":     anomaly.Type,
+				"SEVERITY": anomaly.Severity,
+			})
+		}
+	}
+
+	prevAt, currAt := parseStoredTime(prev.Timestamp), parseStoredTime(curr.Timestamp)
+	if sessionKind(prev.State) != sessionKind(curr.State) || currAt.Sub(prevAt) > sessionMaxGap {
+		runHooks(hooks.SessionEnd, hookSessionEnd, prev, map[string]string{"SESSION": sessionKind(prev.State)})
+		runHooks(hooks.SessionStart, hookSessionStart, curr, map[string]string{"SESSION": sessionKind(curr.State)})
+	}
+
+	if day := startOfDay(prevAt, time.Local); len(hooks.DailyReport) > 0 && !prevAt.IsZero() && day.Before(startOfDay(currAt, time.Local)) {
+		vars, err := dailyReportVars(dc, day)
+		if err != nil {
+			logWarnf("⚠️ %v", err)
+			return
+		}
+		runHooks(hooks.DailyReport, hookDailyReport, curr, vars)
+	}
+}
+
+// dailyReportVars обновляет сводку за закончившийся день и возвращает ее итоги
+func dailyReportVars(dc *DataCollector, day time.Time) (map[string]string, error) {
+	if err := syncDailyUsage(dc.db); err != nil {
+		return nil, err
+	}
+	var row dailyUsageRow
+	if err := dc.db.Get(&row, `SELECT * FROM daily_usage WHERE day = ?`, day.Format("2006-01-02")); err != nil {
+		return nil, fmt.Errorf(T("hooks.err.daily"), day.Format("2006-01-02"), err)
+	}
+	return map[string]string{
+		"DAY":             row.Day,
+		"BATTERY_MINUTES": strconv.Itoa(row.BatterySeconds / 60),
+		"CHARGE_MINUTES":  strconv.Itoa(row.ChargeSeconds / 60),
+		"SCREEN_MINUTES":  strconv.Itoa(row.ScreenSeconds / 60),
+		"DRAIN":           strconv.FormatFloat(row.Drain, 'f', 1, 64),
+		"SESSIONS":        strconv.Itoa(row.Sessions),
+	}, nil
+}
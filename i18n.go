@@ -0,0 +1,3330 @@
+// i18n.go
+//
+// Локализация интерфейса: сообщения берутся из каталога по идентификатору,
+// язык – из config.json ("language": "ru" или "en"), а если он не задан –
+// из LC_ALL, LC_MESSAGES или LANG. Русский остается языком по умолчанию.
+// Через каталог проходит все, что видит пользователь: интерфейс, вывод
+// команд, отчеты и ошибки. Журнал (batmon.log и его копия в терминале)
+// пишется по-русски на любом языке – его прикладывают к сообщениям об ошибках.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Поддерживаемые языки
+const (
+	localeRU = "ru"
+	localeEN = "en"
+)
+
+// currentLocale возвращает язык интерфейса
+func currentLocale() string {
+	if lang := normalizeLocale(getConfig().Language); lang != "" {
+		return lang
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			if lang := normalizeLocale(value); lang != "" {
+				return lang
+			}
+			break // переменная задана, но язык без каталога или "C" – дальше не ищем
+		}
+	}
+	return localeRU
+}
+
+// normalizeLocale приводит "ru_RU.UTF-8", "en-GB" и т.п. к языку каталога.
+// Для "C", "POSIX" и пустой строки возвращает пусто – язык не выбран;
+// остальные языки без собственного каталога получают английский.
+func normalizeLocale(value string) string {
+	lang := strings.ToLower(value)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case "", "c", "posix":
+		return ""
+	case localeRU:
+		return localeRU
+	}
+	return localeEN
+}
+
+// T возвращает сообщение на текущем языке; с аргументами – как fmt.Sprintf
+func T(id string, args ...any) string {
+	msg, ok := messages[currentLocale()][id]
+	if !ok {
+		if msg, ok = messages[localeRU][id]; !ok {
+			msg = id
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// localized возвращает перевод сообщения id, а если его нет – исходный
+// русский текст. Так описаны строки, которые живут рядом с кодом (например,
+// описания подкоманд в cliCommands).
+func localized(id, fallback string) string {
+	if msg, ok := messages[currentLocale()][id]; ok {
+		return msg
+	}
+	return fallback
+}
+
+// messageError – ошибка-метка для errors.Is, текст которой переводится при
+// выводе: переменные пакета создаются раньше, чем известен язык
+type messageError string
+
+func (e messageError) Error() string { return T(string(e)) }
+
+// messages – каталог сообщений по языкам
+var messages = map[string]map[string]string{
+	localeRU: {
+		"lang": "ru",
+
+		"cli.usage":      "Использование: batmon [--db путь] [--verbose] [команда] [флаги]",
+		"cli.no_command": "Без команды запускается интерактивный интерфейс.",
+		"cli.commands":   "Команды:",
+
+		"menu.title":           "🔋 BatMon - Мониторинг батареи MacBook",
+		"menu.full":            "🔋 Полный анализ батареи (100% → 0%)",
+		"menu.full.desc":       "Запустите при 100% заряде, разрядите до 0% для полной диагностики",
+		"menu.quick":           "⚡ Быстрая диагностика",
+		"menu.quick.desc":      "Проверить текущее состояние батареи и показать рекомендации",
+		"menu.report":          "📊 Детальный отчет",
+		"menu.report.desc":     "Анализ всех сохраненных данных с графиками и прогнозами",
+		"menu.export":          "📄 Экспорт отчетов",
+		"menu.export.desc":     "Сохранить результаты в Markdown или HTML с графиками",
+		"menu.settings":        "⚙️  Настройки",
+		"menu.settings.desc":   "Интервал опроса, хранение, пороги, язык, очистка данных",
+		"menu.logs":            "🧾 Журнал",
+		"menu.logs.desc":       "Сообщения сборщика, предупреждения и ошибки из batmon.log",
+		"logs.title":           "🧾 Журнал BatMon",
+		"logs.filter":          "%s · уровень от %s",
+		"logs.empty":           "Записей этого уровня нет",
+		"logs.error":           "Журнал недоступен: %v",
+		"logs.help":            "↑/↓ PgUp/PgDn – прокрутка · l – уровень · r – обновить · q – назад",
+		"doctor.title":         "🩺 Состояние сборщика",
+		"doctor.running":       "Проверка…",
+		"doctor.unavailable":   "Проверки недоступны: сервис данных не запущен",
+		"doctor.checked":       "проверено в %s",
+		"doctor.help":          "r – проверить снова · q – назад",
+		"menu.help":            "❓ Справка",
+		"menu.help.desc":       "Как правильно использовать программу для анализа батареи",
+		"menu.quit":            "❌ Выход",
+		"menu.quit.desc":       "Завершить работу программы",
+		"tab.overview":         "Обзор",
+		"tab.charts":           "Графики",
+		"tab.anomalies":        "Аномалии",
+		"tab.history":          "История",
+		"tab.forecast":         "Прогноз",
+		"tab.sessions":         "Сессии",
+		"tab.charging":         "Зарядка",
+		"tab.metrics":          "Метрики",
+		"help.title":           "🔋 Справка по BatMon",
+		"help.purpose":         "🎯 ГЛАВНАЯ ЦЕЛЬ",
+		"help.purpose.text":    "Понять, нужно ли менять батарею MacBook",
+		"help.howto":           "🚀 КАК ПОЛЬЗОВАТЬСЯ",
+		"help.howto.1":         "1. Зарядите до 100%",
+		"help.howto.2":         "2. Выберите '🔋 Полный анализ батареи' и нажмите Enter",
+		"help.howto.3":         "3. Разрядите до 5% – тест завершится сам",
+		"help.howto.4":         "4. Нажмите e – отчет сравнит время работы с оценкой macOS",
+		"help.modes":           "📋 РЕЖИМЫ РАБОТЫ",
+		"help.modes.quick":     "⚡ Быстрая диагностика - моментальная проверка",
+		"help.modes.full":      "🔋 Полный анализ - основной тест (100%→0%)",
+		"help.modes.report":    "📊 Детальный отчет - графики и тренды",
+		"help.criteria":        "🔍 ОЦЕНКА СОСТОЯНИЯ",
+		"help.criteria.good":   "✅ Хорошо: ",
+		"help.criteria.good.v": "износ <20%, циклы <1000",
+		"help.criteria.warn":   "⚠️  Внимание: ",
+		"help.criteria.warn.v": "износ 20-30%, циклы 1000+",
+		"help.criteria.bad":    "🔴 Замена: ",
+		"help.criteria.bad.v":  "износ >30%, циклы >1500",
+		"help.tips":            "💡 СОВЕТЫ",
+		"help.tips.1":          "• Минимум 2-3 часа для точного анализа",
+		"help.tips.2":          "• Не закрывайте программу во время теста",
+		"help.tips.3":          "• Во время калибровки MacBook не засыпает от бездействия (режим – клавиша c на дашборде)",
+		"help.tips.4":          "• Сохраняйте отчеты для отслеживания",
+		"help.tips.5":          "• Ctrl+T – сменить цветовую тему (темная, светлая, контрастная)",
+		"help.back":            "Нажмите 'q' для выхода в главное меню",
+
+		"unit.mah":                     "мАч",
+		"unit.wh":                      "Вт·ч",
+		"unit.watt":                    "Вт",
+		"unit.mah_per_hour":            "мАч/ч",
+		"fmt.hours_minutes":            "%d ч %d мин",
+		"fmt.minutes":                  "%d мин",
+		"report.title":                 "🔋 Отчет о состоянии батареи MacBook",
+		"report.created":               "Дата создания",
+		"report.period":                "Период",
+		"report.summary":               "💼 Краткое резюме",
+		"report.health":                "Здоровье батареи",
+		"report.rating":                "%s (рейтинг %d/100, модель: %s)",
+		"report.cycles":                "Циклы",
+		"report.wear":                  "Износ",
+		"report.remaining":             "Оставшееся время",
+		"report.baseline":              "Полная ёмкость %s",
+		"report.baseline.detail":       "(%d мАч на %s)",
+		"report.replacement":           "(серийный номер %s → %s); тренды считаются только по новой батарее",
+		"report.current":               "🔋 Текущее состояние батареи",
+		"report.current.short":         "🔋 Текущее состояние",
+		"report.param":                 "Параметр",
+		"report.value":                 "Значение",
+		"report.measured_at":           "Время измерения",
+		"report.charge":                "Заряд",
+		"report.state":                 "Состояние",
+		"report.charge_cycles":         "Циклы зарядки",
+		"report.full_cap":              "Полная ёмкость",
+		"report.design_cap":            "Проектная ёмкость",
+		"report.current_cap":           "Текущая ёмкость",
+		"report.temperature":           "Температура",
+		"report.health_analysis":       "📊 Анализ здоровья батареи",
+		"report.overall":               "Общее состояние",
+		"report.overall.value":         "%s (оценка: %d/100)",
+		"report.wear_battery":          "Износ батареи",
+		"report.trend":                 "Тренд деградации",
+		"report.trend.value":           "%.2f%% в месяц",
+		"report.projection":            "Прогноз до 80% емкости",
+		"report.projection.value":      "~%d дней",
+		"report.charts":                "📊 Графики",
+		"report.anomalies":             "⚠️ Обнаруженные аномалии (%d)",
+		"report.anomalies.more":        "... и еще %d аномалий",
+		"report.recommendations":       "💡 Рекомендации",
+		"report.top_apps":              "🔌 Приложения с наибольшим расходом %s",
+		"report.app":                   "Приложение",
+		"report.drain_mah":             "Расход, мАч",
+		"report.drain_wh":              "Расход, Вт·ч",
+		"report.energy_impact":         "Energy Impact (ср./макс.)",
+		"report.history":               "📈 За всё время наблюдений",
+		"report.monthly":               "🗓️ Ёмкость по месяцам",
+		"report.wear_timeline":         "📉 Шкала деградации",
+		"report.wear_timeline.note":    "Когда износ переходил каждый следующий процент; полоса – недели на процент: чем короче, тем быстрее деградация.",
+		"report.wear_pace":             "На процент",
+		"report.date":                  "Дата",
+		"report.month":                 "Месяц",
+		"report.hot":                   "🔥 Горячая зарядка по неделям",
+		"report.hot.note":              "Минуты зарядки с температурой выше порога (ниже при заряде от 80%) – показатель риска износа.",
+		"report.full_zone":             "🔝 Время на 100%",
+		"report.compare":               "🆚 Сравнение со снимком «%s» от %s",
+		"report.compare.replaced":      "Снимок сделан на другой батарее (замена)",
+		"report.compare.then":          "Было",
+		"report.compare.now":           "Стало",
+		"report.compare.delta":         "Изменение",
+		"report.avg_drain":             "Средняя разрядка",
+		"report.runtime":               "Работа от полного заряда",
+		"report.score":                 "Оценка здоровья",
+		"unit.pct_per_hour":            "%/ч",
+		"report.model":                 "💻 Сравнение с моделью: %s",
+		"report.sleep":                 "😴 Разряд во сне: %d%% за %s (%.1f%%/ч, периодов сна: %d)",
+		"report.sleep_wakes":           "⏰ Пробуждения во сне",
+		"report.notes":                 "✎ Заметки",
+		"report.throttling":            "🔥 Троттлинг: процессор или батарея",
+		"report.wake_reasons":          "Чаще всего будили Mac:",
+		"report.standby":               "🌙 Саморазряд во сне по неделям",
+		"report.charging_curve":        "⚡ Зарядка",
+		"report.calibration":           "🎯 Калибровка: полные разрядки 100% → <10%",
+		"settings.title":               "⚙️  Настройки",
+		"settings.interval":            "Интервал опроса батареи",
+		"settings.retention":           "Хранить измерения",
+		"settings.days":                "%d дн.",
+		"settings.low_battery":         "Щадящий режим ниже заряда",
+		"settings.wear_warning":        "Износ: предупреждение",
+		"settings.wear_critical":       "Износ: критично",
+		"settings.cycles_warning":      "Циклы: предупреждение",
+		"settings.cycles_critical":     "Циклы: критично",
+		"settings.language":            "Язык интерфейса",
+		"settings.language.auto":       "авто (%s)",
+		"settings.sampling":            "Частота опроса",
+		"sampling.adaptive":            "адаптивная",
+		"sampling.fixed":               "постоянная",
+		"settings.caffeinate":          "Запрет сна (caffeinate)",
+		"settings.net_context":         "Трафик сети и Bluetooth в измерениях",
+		"settings.score_model":         "Модель рейтинга здоровья",
+		"health_model.heuristic":       "эвристика batmon",
+		"health_model.apple_condition": "состояние по Apple",
+		"health_model.capacity":        "только ёмкость",
+		"health_model.cycles":          "по циклам",
+		"caffeinate.off":               "выкл",
+		"caffeinate.calibration":       "только при калибровке",
+		"caffeinate.always":            "всегда",
+		"caffeinate.status":            "☕ Запрет сна: %s – %s",
+		"caffeinate.active":            "активен",
+		"caffeinate.inactive":          "не активен",
+		"caffeinate.assertions":        "🔒 Системных запретов сна: %d",
+		"caffeinate.assertions.owners": "🔒 Сон запрещают: %s",
+		"caffeinate.assertions.none":   "💤 Системных запретов сна нет",
+		"live.sample":                  "измерение в %s",
+		"live.next":                    "следующее через %d:%02d",
+		"live.collecting":              "сбор…",
+		"live.stalled":                 "⚠️ сбор данных не удается · 'd' – состояние сборщика",
+		"settings.off":                 "выкл",
+		"settings.on":                  "вкл",
+		"settings.clear":               "🗑️  Очистить данные…",
+		"settings.saved":               "Сохранено: %s – %s",
+		"settings.cleared":             "База данных очищена",
+		"settings.help":                "↑/↓ выбор • ←/→ изменить • Enter на очистке – подтвердить • q назад",
+		"report.charging.summary":      "Сессий зарядки: %d; в среднем 20→80%%: %s, 80→100%%: %s",
+		"report.charging.watts_avg":    "мощность %.0f Вт",
+		"report.charging.start":        "Начало",
+		"report.charging.range":        "Заряд",
+		"report.charging.fast":         "20→80%",
+		"report.charging.trickle":      "80→100%",
+		"report.charging.watts":        "Вт",
+		"report.charging.notes":        "Замечания",
+		"report.charging.slow":         "медленная зарядка",
+		"report.charging.long_trickle": "затянутый дозаряд",
+		"report.standby.summary":       "Во сне от батареи теряется в среднем %.1f%%/ч (за %s сна), ориентир Apple – около %.0f%%/ч",
+		"report.standby.trend":         "за последние недели %s%%/ч",
+		"report.standby.hours":         "Часов сна",
+		"report.standby.rate":          "%/ч",
+		"report.model.wear":            "%s при %d циклах обычно имеет износ %.0f%%, у вашего – %.0f%%",
+		"report.model.cycles":          "Остаток ресурса циклов: %.0f%% из %d",
+		"report.model.runtime":         "Заявленное время работы %s, измеренное от полного заряда – %s",
+		"report.model.design":          "Паспортная ёмкость батареи %d мАч, у модели – %d мАч: возможно, батарея неоригинальная",
+		"report.full_zone.note":        "Сколько времени батарея держалась на 100% от сети за период отчета (доля от наблюдаемого времени). Долгий полный заряд ускоряет износ.",
+		"report.week":                  "Неделя",
+		"report.minutes":               "Минут",
+		"report.chargers":              "🔌 Адаптеры питания",
+		"report.adapter":               "Адаптер",
+		"report.power":                 "Мощность",
+		"report.last_connected":        "Последнее подключение",
+		"report.charge_rate":           "Зарядка до 80%, %/ч",
+		"report.watts":                 "%d Вт",
+		"report.brightness":            "🔆 Расход по яркости экрана",
+		"report.mode":                  "Режим",
+		"report.drain_rate":            "Расход",
+		"report.battery_hours":         "Часов от батареи",
+		"report.derived":               "📐 Производные метрики",
+		"report.metric":                "Метрика",
+		"report.expr":                  "Выражение",
+		"report.latest":                "Последнее",
+		"report.min":                   "Мин.",
+		"report.avg":                   "Сред.",
+		"report.max":                   "Макс.",
+		"report.daily":                 "📅 Использование по дням",
+		"report.heatmap":               "🗓️ Тепловая карта использования",
+		"report.heatmap.note":          "Строка – день, колонка – час. Чем ярче ячейка, тем быстрее в этот час разряжалась батарея; подсказка над ячейкой показывает минуты от батареи и скорость разряда.",
+		"report.heatmap.cell":          "%s %02d:00 – %.0f мин от батареи",
+		"report.heatmap.rate":          ", %.1f%%/ч",
+		"weekday.0":                    "Вс",
+		"weekday.1":                    "Пн",
+		"weekday.2":                    "Вт",
+		"weekday.3":                    "Ср",
+		"weekday.4":                    "Чт",
+		"weekday.5":                    "Пт",
+		"weekday.6":                    "Сб",
+		"report.total":                 "Итого",
+		"report.total.value":           "от батареи %s, на зарядке %s, израсходовано %.1f полных заряда",
+		"report.day":                   "День",
+		"report.on_battery":            "От батареи",
+		"report.charging":              "На зарядке",
+		"report.on_ac":                 "От сети",
+		"report.screen":                "Экран",
+		"report.drain":                 "Расход заряда",
+		"report.full_charges":          "Полных зарядов",
+		"report.sessions":              "Сессий",
+		"report.battery_hours_bar":     "Часы от батареи",
+		"report.drain_stats":           "📈 Статистика разрядки",
+		"report.simple_rate":           "Простая скорость разрядки",
+		"report.robust_rate":           "Робастная скорость разрядки",
+		"report.discharge_power":       "Средняя мощность разрядки",
+		"report.power.value":           "%.1f Вт (по энергии, %d интервалов)",
+		"report.rate.value":            "%.2f мАч/час",
+		"report.robust.value":          "%.2f мАч/час (на основе %d валидных интервалов)",
+		"report.remaining_work":        "Оставшееся время работы",
+		"report.recent":                "📋 Последние измерения",
+		"report.time":                  "Время",
+		"report.cycle":                 "Цикл",
+		"report.full_short":            "Полная емк.",
+		"report.design_short":          "Проект. емк.",
+		"report.current_short":         "Текущ. емк.",
+		"report.temp_short":            "Темп.",
+		"report.footer":                "Отчет сгенерирован утилитой batmon v2.0",
+
+		"report.no_data":              "Нет записей для отчёта.",
+		"report.period.samples":       "📅 Период: %s (%d измерений)",
+		"report.overall.model":        "%s (оценка: %d/100, модель: %s)",
+		"report.cli.summary":          "💼 === КРАТКОЕ РЕЗЮМЕ ===",
+		"report.cli.current":          "=== Текущее состояние батареи ===",
+		"report.cli.health":           "=== Анализ здоровья батареи ===",
+		"report.cli.drain":            "=== Статистика разрядки ===",
+		"report.cli.recent":           "=== Последние измерения (от старых к новым) ===",
+		"report.cli.daily":            "📅 За %d дней: от батареи %s, на зарядке %s, израсходовано %.1f полных заряда",
+		"report.cli.monthly":          "🗓️ Полная ёмкость: %s – %.0f мАч, %s – %.0f мАч (%d мес. истории)",
+		"report.cli.adapter":          "🔌 Последний адаптер: %s (%s)",
+		"report.cli.calibration_last": "🎯 Последняя полная разрядка: %s",
+		"report.cli.replacement":      "%s (серийный номер %s → %s)",
+		"report.cli.cycles":           "🔄 Кол-во циклов: %d",
+		"report.cli.hot_week":         "🔥 Горячая зарядка за эту неделю: %.0f мин",
+		"report.cli.full_zone":        "🔝 На 100%% от сети: %s (%.0f%% времени)",
+		"report.cli.anomalies":        "\n⚠️  Обнаружено аномалий за последние измерения: %d",
+		"report.cli.anomalies.more":   "... и еще %d",
+		"report.cli.not_enough":       "недостаточно данных",
+		"report.cli.unknown":          "неизвестно",
+		"report.cli.full_short":       "ПЕ",
+		"report.cli.design_short":     "ПроЕ",
+		"report.cli.current_short":    "ТекЕ",
+		"report.cli.temp_short":       "Темп",
+
+		"report.js.charge":         "Заряд (%)",
+		"report.js.charge_title":   "Заряд батареи (%)",
+		"report.js.capacity":       "Емкость (мАч)",
+		"report.js.capacity_title": "Текущая емкость (мАч)",
+		"report.js.on_battery":     "От батареи, ч",
+		"report.js.charging":       "На зарядке, ч",
+		"report.js.hours":          "Часы",
+		"report.js.daily":          "Использование по дням",
+
+		"state.unknown":     "Неизвестно",
+		"state.almost_full": " (почти полная)",
+		"state.low":         " (низкий заряд)",
+
+		"health.excellent":        "Отличное",
+		"health.good":             "Хорошее",
+		"health.fair":             "Удовлетворительное",
+		"health.attention":        "Требует внимания",
+		"health.poor":             "Плохое",
+		"health.unstable":         " (нестабильная работа)",
+		"health.fast_degradation": " (быстрая деградация)",
+		"score.wear":              "Износ",
+		"score.cycles":            "Циклы",
+		"score.cycles.value":      "%d из %d",
+		"score.anomalies":         "Аномалии",
+		"score.degradation":       "Деградация ёмкости",
+		"score.degradation.value": "%.1f%%/мес",
+		"score.apple_condition":   "Состояние по Apple",
+		"score.no_data":           "нет данных",
+
+		"range.last_n": "последние %d измерений",
+		"range.all":    "всё время",
+		"range.since":  "с %s",
+		"range.until":  "по %s",
+
+		"baseline.summary":    "с момента установки batmon: %s мАч (%s%%)",
+		"replacement.marker":  "🔁 Батарея заменена %s",
+		"history.observed":    "Наблюдения с %s: %d измерений, аномалий: %d",
+		"history.discharge":   "Скорость разрядки: в среднем %.0f ± %.0f мАч/ч, последние интервалы – %.0f мАч/ч",
+		"history.temperature": "Температура: в среднем %.1f°C, максимум %.0f°C",
+		"history.trend":       "Тренд полной ёмкости: %s%% от проектной в месяц",
+
+		"rule.replace":               "Рассмотрите замену батареи",
+		"rule.power_settings":        "Проверьте настройки энергосбережения",
+		"rule.end_of_life":           "Батарея приближается к концу жизненного цикла",
+		"rule.high_drain_brightness": `Высокое энергопотребление при яркости экрана {{printf "%.0f" .Brightness}}% - уменьшите яркость`,
+		"rule.high_drain":            "Высокое энергопотребление - закройте ресурсоемкие приложения",
+		"rec.high_drain_apps":        "Высокое энергопотребление - больше всего батареи за неделю израсходовали: %s",
+		"rule.degradation":           `Быстрая деградация батареи ({{printf "%.2f" .Degradation}}% в месяц) - проверьте условия эксплуатации`,
+		"rule.full_charge":           "Не держите батарею постоянно на 100% заряда",
+		"rule.calibration":           "Рассмотрите калибровку батареи (полный разряд и заряд)",
+		"thermal.alarm.charging":     "Высокая температура на зарядке (%d°C при %d%%) - отключите зарядку или снимите нагрузку, нагрев при высоком заряде ускоряет износ",
+		"thermal.alarm":              "Высокая температура батареи (%d°C) - избегайте нагрузки",
+		"thermal.warning.charging":   "Батарея нагревается на зарядке (%d°C при %d%%) - обеспечьте охлаждение, при возможности ограничьте заряд до %d%%",
+		"thermal.warning":            "Повышенная температура батареи - рассмотрите улучшение охлаждения",
+		"calibration.reminder.never": "Полной разрядки 100%% → <%d%% не было %d дн. наблюдений – пройдите тест калибровки, чтобы контроллер уточнил ёмкость",
+		"calibration.reminder.last":  "Последняя полная разрядка была %d дн. назад (%s) – пора пройти тест калибровки",
+
+		"rec.hot_charging":          "За эту неделю %s горячей зарядки - заряжайте на твердой поверхности и не нагружайте MacBook на зарядке при высоком заряде",
+		"rec.standby":               "Во сне батарея теряет %.1f%%/ч – больше ориентира Apple (около %.0f%%/ч): проверьте `pmset -g assertions`, Power Nap и пробуждения по сети (`pmset -g log | grep Wake`)",
+		"rec.full_charge":           "Батарея %.0f%% времени держится на 100%% от сети - включите оптимизированную зарядку или ограничение заряда до %d%%",
+		"rec.charger.weak":          "Маломощный адаптер %s – под нагрузкой батарея может разряжаться даже на зарядке, используйте адаптер от %d Вт",
+		"rec.charger.slow":          "Медленная зарядка с адаптером %s: %.0f%%/ч до %d%% – проверьте кабель и мощность адаптера",
+		"rec.charger.unofficial":    "Используется неоригинальный адаптер %s – при перегреве или нестабильной зарядке замените его на сертифицированный",
+		"rec.charging.slow":         "Зарядка от %d до %d%% идет дольше %s в %d из %d сессий – проверьте мощность адаптера и кабель",
+		"rec.charging.long_trickle": "Дозаряд от %d до 100%% затягивается дольше %s в %d из %d сессий – если это не оптимизированная зарядка, батарея плохо принимает заряд",
+
+		// команды
+		"cli.unknown_command":        "Неизвестная команда: %s",
+		"cli.collect.started":        "🔄 Сбор данных в %s, Ctrl+C для остановки",
+		"cli.collect.failed":         "⚠️ Ошибка сбора данных: %v",
+		"cli.export.no_format":       "❌ Укажите хотя бы один формат: --md, --html или --certificate",
+		"cli.db.sync_lock":           "🔒 Запись: %s (обновлено %s)",
+		"cli.db.cleaned":             "✅ Удалены данные старше %d дн.",
+		"cli.db.backup":              "✅ Резервная копия: %s",
+		"cli.db.restore.usage":       "❌ Укажите файл резервной копии: batmon db restore <путь>",
+		"cli.db.restored":            "✅ База восстановлена из %s",
+		"cli.db.previous":            "💾 Прежняя база сохранена в %s",
+		"cli.db.import.usage":        "❌ Укажите файл базы: batmon db import <другая.sqlite>",
+		"cli.db.import.source":       "📂 В источнике %d измерений: %s – %s",
+		"cli.db.imported":            "✅ Добавлено %d измерений, пропущено совпадающих по времени: %d",
+		"cli.db.optimized":           "✅ Индексы проверены, статистика обновлена за %s",
+		"cli.db.migrated":            "✅ Схема приведена к версии %d",
+		"cli.db.migrate_again":       "⚠️ При следующем запуске batmon снова применит миграции до версии %d",
+		"cli.db.schema_version":      "📐 Версия схемы: %d из %d",
+		"cli.db.unknown_action":      "❌ Неизвестное действие db: %s (path, stats, cleanup, backup, restore, import, optimize, version, migrate)",
+		"cli.diag.cycles":            "🔄 Циклов: %d",
+		"cli.diag.capacity":          "⚡ Ёмкость: %d / %s (проектная %s)",
+		"cli.diag.electrical":        "🌡️ Температура: %d°C, напряжение %d мВ, ток %d мА",
+		"cli.diag.condition":         "🍎 Состояние: %s",
+		"cli.diag.cells":             "🔋 Ячейки: %v мВ",
+		"cli.diag.permanent_failure": "⛔ Контроллер сообщает о постоянном отказе батареи (PermanentFailureStatus=%#x)",
+		"cli.tmux.usage":             "❌ Интервал кэша указывается в секундах",
+		"cli.replay.usage":           "❌ Укажите файл записи: batmon replay [--speed 60] <файл.sqlite|файл.json>",
+		"cli.replay.speed_number":    "❌ Скорость воспроизведения должна быть числом",
+		"cli.replay.speed_positive":  "❌ Скорость воспроизведения должна быть положительной",
+		"cli.verify.usage":           "❌ Укажите код проверки из сертификата",
+
+		// batmon help и batmon version
+		"cli.help.title":               "❓ Справка BatMon v2.0",
+		"cli.help.about":               "🔋 О программе:",
+		"cli.help.about.1":             "BatMon - это продвинутая утилита для мониторинга состояния батареи MacBook.",
+		"cli.help.about.2":             "Поддерживает интерактивный мониторинг, детальную аналитику и экспорт отчетов.",
+		"cli.help.features":            "📊 Возможности:",
+		"cli.help.features.1":          "• Интерактивный дашборд с графиками",
+		"cli.help.features.2":          "• Анализ трендов и прогноз деградации",
+		"cli.help.features.3":          "• Мониторинг температуры и расширенных метрик",
+		"cli.help.features.4":          "• Экспорт в Markdown и HTML форматы",
+		"cli.help.features.5":          "• Автоматическая ретенция данных",
+		"cli.help.features.6":          "• Цветной вывод и эмодзи индикаторы",
+		"cli.help.tui":                 "🫧 Интерфейс Bubble Tea (по умолчанию):",
+		"cli.help.tui.intro":           "Современный интерфейс с:",
+		"cli.help.tui.1":               "• Интерактивными компонентами и анимациями",
+		"cli.help.tui.2":               "• Отличной отзывчивостью и производительностью",
+		"cli.help.tui.3":               "• Адаптивными макетами",
+		"cli.help.tui.4":               "• Красивой стилизацией",
+		"cli.help.tui.run":             "Запуск: ./batmon",
+		"cli.help.commands":            "⌨️ Команды:",
+		"cli.help.examples":            "Примеры:",
+		"cli.help.example.tmux":        "  set -g status-right '#(batmon tmux-status)'   # виджет для tmux, кэш 30 с",
+		"cli.help.example.certificate": "  batmon export --certificate cert.html          # сертификат для продажи, печать в PDF",
+		"cli.help.no_battery":          "🧪 Без батареи:",
+		"cli.help.no_battery.replay":   "BATMON_SOURCE=replay:<файл.json> batmon - воспроизвести записанный вывод pmset/ioreg",
+		"cli.help.modes":               "🎯 Режимы работы:",
+		"cli.help.modes.1":             "1. Интерактивный мониторинг - при работе от батареи",
+		"cli.help.modes.2":             "2. Детальный отчет - анализ сохраненных данных",
+		"cli.help.modes.3":             "3. Экспорт отчетов - сохранение в файлы",
+		"cli.help.modes.4":             "4. Статистика - информация о данных и системе",
+		"cli.help.requirements":        "🔧 Требования:",
+		"cli.help.requirements.1":      "• macOS (протестировано на Apple Silicon)",
+		"cli.help.requirements.2":      "• Go 1.24+ для сборки из исходников",
+		"cli.help.requirements.3":      "• MacBook с батареей",
+		"cli.help.support":             "🆘 Поддержка:",
+		"cli.help.support.issues":      "• Issues: сообщайте о проблемах через GitHub Issues",
+		"cli.help.back":                "Нажмите Enter для возврата в меню...",
+		"cli.version.tagline":          "Мониторинг батареи MacBook (Apple Silicon)",
+
+		// метрики и аномалии
+		"metrics.trend.stable":    "стабильное",
+		"metrics.trend.rising":    "растущее потребление",
+		"metrics.trend.falling":   "снижающееся потребление",
+		"score.temperature":       "Температура",
+		"score.voltage_stability": "Стабильность напряжения",
+		"anomaly.charge_jump":     "Резкий рост заряда: %d%% → %d%% за %.1f мин (%s)",
+		"anomaly.charge_drop":     "Резкое падение заряда: %d%% → %d%% за %.1f мин (%s)%s",
+		"anomaly.state_change":    "Смена состояния: %s → %s (%s)",
+		"anomaly.capacity_jump":   "Резкое изменение емкости: %d → %d мАч за %.1f мин (%s)",
+
+		// консольное меню и batmon diag
+		"console.title":                "🔋 BatMon v2.0 - Мониторинг батареи MacBook",
+		"console.status_failed":        "⚠️ Не удалось получить текущий статус: %v\n",
+		"console.choose":               "📋 Выберите действие:",
+		"console.menu.1":               "  1️⃣  Запустить интерактивный мониторинг",
+		"console.menu.2":               "  2️⃣  Показать детальный отчет",
+		"console.menu.3":               "  3️⃣  Экспортировать отчеты",
+		"console.menu.4":               "  4️⃣  Статистика и настройки",
+		"console.menu.5":               "  5️⃣  Справка",
+		"console.menu.0":               "  0️⃣  Выход",
+		"console.prompt":               "Ваш выбор (0-5): ",
+		"console.bye":                  "\n👋 До свидания!",
+		"console.invalid":              "\n❌ Неверный выбор. Нажмите Enter для продолжения...",
+		"console.current":              "💡 Текущий статус: ",
+		"console.charging":             " 🔌 На зарядке",
+		"console.on_battery":           " 🔋 От батареи",
+		"console.charged":              " ✅ Заряжена",
+		"console.monitor.start":        "🔄 Запуск интерактивного мониторинга...",
+		"console.monitor.auto":         "💡 Программа определит режим работы автоматически",
+		"console.monitor.signal":       "\n⏹️ Получен сигнал завершения...",
+		"console.monitor.power_failed": "⚠️ Ошибка определения питания: %v",
+		"console.monitor.power":        "⚡ Состояние питания: %s (%d%%)",
+		"console.monitor.battery":      "🔋 Работа от батареи - запуск мониторинга и дашборда...",
+		"console.monitor.background":   "🔋 Данные собираются в фоне. Используйте главное меню для мониторинга.",
+		"console.monitor.ac":           "🔌 Работа от сети - показ сохраненных данных...",
+		"console.report.loading":       "📊 Загрузка детального отчета...",
+		"console.back":                 "\nНажмите Enter для возврата в меню...",
+		"console.export.title":         "📄 Экспорт отчетов",
+		"console.export.1":             "  1️⃣  Экспорт в Markdown (.md)",
+		"console.export.2":             "  2️⃣  Экспорт в HTML (.html)",
+		"console.export.3":             "  3️⃣  Экспорт в оба формата",
+		"console.export.0":             "  0️⃣  Назад в главное меню",
+		"console.export.prompt":        "Выберите формат (0-3): ",
+		"console.export.filename":      "📝 Введите имя файла (без расширения): ",
+		"console.export.default_name":  "💡 Используется имя по умолчанию: %s",
+		"console.export.generating":    "📊 Генерация отчета...",
+		"console.export.failed":        "❌ Ошибка экспорта: %v",
+		"console.export.done":          "✅ Экспорт выполнен успешно!",
+		"console.continue_nl":          "\nНажмите Enter для продолжения...",
+		"console.continue":             "Нажмите Enter для продолжения...",
+		"console.clear.title":          "🗑️  Очистка базы данных",
+		"console.clear.warning":        "⚠️  ВНИМАНИЕ: Эта операция удалит ВСЕ сохраненные данные!",
+		"console.clear.list":           "Будут удалены:",
+		"console.clear.list.1":         "  • Все измерения батареи",
+		"console.clear.list.2":         "  • История состояний",
+		"console.clear.list.3":         "  • Статистика использования",
+		"console.clear.confirm":        "Вы уверены? (y/н): ",
+		"console.clear.aborted":        "❌ Очистка отменена: %v",
+		"console.clear.backup":         "💾 Резервная копия: %s (вернуть: batmon db restore <путь>)",
+		"console.clear.remove_failed":  "⚠️  Не удалось удалить %s: %v",
+		"console.clear.done":           "✅ База данных успешно очищена!",
+		"console.clear.cancelled":      "❌ Операция отменена",
+		"console.stats.title":          "📊 Статистика данных:",
+		"console.stats.records":        "   📦 Записей в БД: %v",
+		"console.stats.size":           "   💾 Размер БД: %.1f МБ",
+		"console.stats.buffer":         "   🗄️ Буфер памяти: %v/%v записей",
+		"console.stats.oldest":         "   📅 Самая старая запись: %s",
+		"console.stats.newest":         "   📅 Самая новая запись: %s",
+		"console.metrics.loading":      "🔬 Загрузка расширенных метрик...",
+		"console.metrics.not_enough":   "⚠️ Недостаточно данных для анализа",
+		"console.metrics.title":        "🔬 Расширенные метрики:",
+		"console.metrics.efficiency":   "⚡ Энергоэффективность: %.1f%%",
+		"console.metrics.voltage":      "🔧 Стабильность напряжения: %.1f%%",
+		"console.metrics.charging":     "🔋 Эффективность зарядки: %.2f",
+		"console.metrics.trend":        "📊 Тренд мощности: %s",
+		"console.metrics.rating":       "🏆 Рейтинг здоровья: %d/100",
+		"console.metrics.apple":        "🍎 Статус Apple: %s",
+		"console.cleanup.start":        "🧹 Очистка старых данных...",
+		"console.cleanup.failed":       "❌ Ошибка очистки: %v",
+		"console.cleanup.done":         "✅ Очистка выполнена успешно",
+		"sysinfo.title":                "💻 Информация о системе:",
+		"sysinfo.go":                   "🔧 Версия Go: %s",
+		"sysinfo.db":                   "💾 База данных: SQLite с WAL режимом",
+		"sysinfo.db_file":              "📁 Файл БД: %s",
+		"sysinfo.source":               "🔌 Источник данных: %s",
+		"sysinfo.unknown_model":        "нет в справочнике моделей",
+		"sysinfo.model":                "💻 Модель: %s (%s)",
+		"sysinfo.tool_ok":              "✅ %s доступен",
+		"sysinfo.tool_missing":         "❌ %s недоступен",
+		"report.cli.brightness":        "%s: %s (%.1f ч)",
+
+		// дашборд
+		"export.done":                   "✅ Экспорт завершен! Созданы файлы:",
+		"calibration.paused_by_charger": "🔌 Подключена зарядка – тест приостановлен",
+		"dashboard.col.time":            "Время",
+		"dashboard.col.charge":          "Заряд",
+		"dashboard.col.state":           "Состояние",
+		"dashboard.col.temp":            "Темп.",
+		"app.unknown_state":             "Неизвестное состояние приложения",
+		"dashboard.scroll":              "   ↕ Скролл: %d/%d (↑↓/kj)",
+		"loading.title":                 "🔋 ПОЛНЫЙ АНАЛИЗ БАТАРЕИ",
+		"loading.collecting":            "🔄 Собираем данные о батарее...\n\n",
+		"loading.todo":                  "📋 ЧТО НУЖНО ДЕЛАТЬ:",
+		"loading.todo.1":                "1. Оставьте программу работать\n",
+		"loading.todo.2":                "2. Используйте MacBook как обычно\n",
+		"loading.todo.3":                "3. Разрядите батарею до 10-0%\n",
+		"loading.todo.4":                "4. После разрядки получите отчет\n\n",
+		"loading.tips":                  "💡 СОВЕТЫ:",
+		"loading.tips.1":                "• Минимум 2-3 часа для качественного анализа\n",
+		"loading.tips.2":                "• Не закрывайте программу\n",
+		"loading.tips.3":                "• При низком заряде сохраните работу\n\n",
+		"loading.caffeinate":            "☕ Предотвращение засыпания активно",
+		"tui.back_to_menu":              "Нажмите 'q' для выхода в главное меню",
+		"dashboard.compact":             "🔋 Мониторинг батареи\n\nЗаряд: %d%% │ %s\nСостояние: %s\nЦиклы: %d │ Износ: %.1f%%\nТемпература: %d°C\n\n⌨️  'q'/'й' - выход │ 'r'/'к' - обновить",
+		"dashboard.quality.poor":        "Недостаточно",
+		"dashboard.quality.excellent":   "Отлично",
+		"dashboard.quality.good":        "Хорошо",
+		"dashboard.panel":               "🔋 Текущее состояние\n\n⚡ Заряд: %d%%\n%s\n\n📉 Износ: %.1f%%\n%s\n\n🔄 Состояние: %s\n🔁 Циклы: %d\n🌡️  Температура: %d°C\n⚡ Напряжение: %d мВ\n🔌 Ток: %d мА\n\n💚 Здоровье: %s\n\n📊 Качество данных: %s\n⏱️  Собрано: %.1fч (%d точек)",
+		"dashboard.recent":              "Последние измерения\n",
+		"dashboard.keys":                "Управление:\n",
+		"dashboard.keys.quit":           "  'q'/'й' - выход\n",
+		"dashboard.keys.refresh":        "  'r'/'к' - обновить\n",
+		"dashboard.keys.window":         "  'w'/'ц' - окно графиков (%s)\n",
+		"dashboard.keys.zoom":           "  '+'/'-' - масштаб, 'h'/'l' - сдвиг\n",
+		"dashboard.keys.crosshair":      "  'x'/'ч' - перекрестье (←→)\n",
+		"dashboard.keys.metric":         "  't'/'е' - график ёмкости/температуры/мощности\n",
+		"dashboard.keys.caffeinate":     "  'c'/'с' - запрет сна (%s)\n",
+		"dashboard.keys.doctor":         "  'd'/'в' - состояние сборщика\n",
+		"dashboard.keys.scroll":         "  ↑↓/jk - скролл\n\n",
+
+		// вкладка обзора отчета
+		"state.charging":             "Зарядка",
+		"state.discharging":          "Разрядка",
+		"state.charged":              "Заряжена",
+		"state.ac":                   "От сети",
+		"history.filter.all":         "Все",
+		"tui.report.failed":          "❌ Ошибка загрузки отчета: %v\nНажмите 'q' для выхода в меню",
+		"tui.report.title":           "📊 Детальный отчет о состоянии батареи\n",
+		"tui.report.overall":         "🔋 ОБЩЕЕ СОСТОЯНИЕ\n",
+		"tui.report.health":          "│ Состояние: %s %s\n",
+		"tui.report.rating":          "│ Рейтинг:   %s %d/100\n",
+		"tui.report.model":           "│ Модель:    %s\n",
+		"tui.report.wear":            "│ Износ:     %.1f%%\n",
+		"tui.report.cycles":          "│ Циклы:     %d\n",
+		"tui.report.current":         "⚡ ТЕКУЩЕЕ СОСТОЯНИЕ\n",
+		"tui.report.charge":          "│ Заряд:     %s %d%%\n",
+		"tui.report.state":           "│ Статус:    %s %s\n",
+		"tui.report.remaining":       "│ Осталось:  %s\n",
+		"tui.report.temp":            "│ Темп-ра:   %s %d°C\n",
+		"tui.report.performance":     "📈 АНАЛИЗ ПРОИЗВОДИТЕЛЬНОСТИ\n",
+		"tui.report.power":           "│ Мощность разряда:   %.1f Вт\n",
+		"tui.report.rate":            "│ Скорость разряда:   %.1f мАч/ч\n",
+		"tui.report.consumption":     "│ Потребление:        %d мВт\n",
+		"tui.report.voltage":         "│ Напряжение:         %.2f В\n",
+		"tui.report.intervals":       "│ Валидных интервалов: %d\n",
+		"tui.report.battery":         "💊 ЗДОРОВЬЕ БАТАРЕИ\n",
+		"tui.report.current_cap":     "│ Текущая емкость:    %s\n",
+		"tui.report.full_cap":        "│ Полная емкость:     %s\n",
+		"tui.report.design_cap":      "│ Проектная емкость:  %s\n",
+		"tui.report.apple":           "│ Статус Apple:       %s\n",
+		"tui.report.problems":        "⚠️  ОБНАРУЖЕННЫЕ ПРОБЛЕМЫ\n",
+		"tui.report.recommendations": "💡 РЕКОМЕНДАЦИИ\n",
+		"tui.report.top_apps":        "🔌 ПРИЛОЖЕНИЯ С НАИБОЛЬШИМ РАСХОДОМ (%s)\n",
+		"tui.report.top_app":         "│ %2d. %-22s %6.0f мАч %5.2f Вт·ч\n",
+		"tui.report.recent":          "📋 ПОСЛЕДНИЕ ИЗМЕРЕНИЯ\n",
+		"tui.report.recent.header":   "│   Время  │ Заряд % │    Состояние    │ Темп °C  │\n",
+
+		// виджеты обзора
+		"tui.help.filter":       "/ фильтр",
+		"tui.help.map":          "m карта",
+		"widget.health":         "💚 Здоровье батареи",
+		"widget.charge":         "🔋 Текущий заряд",
+		"widget.wear":           "⚙️ Износ батареи",
+		"widget.baseline":       "📌 С установки batmon",
+		"widget.baseline.value": "%s мАч (%s%%)",
+		"widget.cycles":         "🔄 Циклы зарядки",
+		"widget.full_cap":       "⚡ Полная ёмкость",
+		"widget.power":          "🔌 Мощность разряда",
+		"widget.power.value":    "%.1f Вт",
+		"widget.remaining":      "⏱️ Осталось времени",
+		"widget.full_zone":      "🔝 На 100% от сети",
+		"widget.temperature":    "🌡️ Температура",
+
+		// вкладки отчета, приветствие и быстрая диагностика
+		"dashboard.chart.empty":               "📊 График заряда\n\nНет данных для отображения",
+		"tui.charts.title":                    "📈 Графики производительности батареи\n",
+		"tui.charts.charge":                   "🔋 История заряда (последние 24 часа)\n",
+		"tui.charts.rate":                     "⚡ Скорость разряда\n",
+		"tui.charts.temperature":              "🌡️ Температурный профиль\n",
+		"tui.charts.no_data":                  "Нет данных для отображения",
+		"tui.charts.not_enough":               "Недостаточно данных",
+		"tui.charts.no_discharge":             "Нет данных о разряде",
+		"tui.charts.rate_range":               "\nМин: %.1f%%/ч  Макс: %.1f%%/ч",
+		"tui.charts.no_temperature":           "Нет данных",
+		"tui.anomalies.title":                 "⚠️ Анализ аномалий и проблем\n",
+		"tui.anomalies.none":                  "✅ Аномалий не обнаружено!\n\n",
+		"tui.anomalies.normal":                "Батарея работает в штатном режиме.\n",
+		"tui.anomalies.critical":              "🚨 Критические проблемы:\n",
+		"tui.anomalies.warning":               "⚡ Требуют внимания:\n",
+		"tui.anomalies.info":                  "ℹ️ Информация:\n",
+		"tui.anomalies.thermal":               "\n🌡️ Перегрев (выше %d°C дольше %s):\n",
+		"tui.anomalies.thermal.charging":      ", на зарядке",
+		"tui.anomalies.thermal.event":         "  • %s – %s, пик %d°C, в среднем %.1f°C%s\n",
+		"tui.anomalies.recommendations":       "\n💡 Рекомендации по улучшению:\n",
+		"tui.anomalies.stats":                 "\n\n📊 Статистика аномалий:\n",
+		"tui.anomalies.stats.found":           "• Обнаружено проблем: %d\n",
+		"tui.anomalies.stats.recommendations": "• Рекомендаций: %d\n",
+		"tui.anomalies.stats.intervals":       "• Валидных интервалов: %d\n",
+		"tui.history.title":                   "📜 История измерений\n",
+		"tui.history.filter":                  "Фильтр: %s | Сортировка: %s\n",
+		"tui.history.expr":                    "Выражение: %s (x – сбросить)",
+		"tui.history.page":                    "Страница %d из %d · записей: %d",
+		"tui.sessions.title":                  "🔋 Сессии разрядки и зарядки\n",
+		"tui.sessions.none":                   "Сессий пока нет – они появятся после первой разрядки или зарядки.\n",
+		"tui.sessions.col.kind":               "Тип",
+		"tui.sessions.col.start":              "Начало",
+		"tui.sessions.col.duration":           "Длительность",
+		"tui.sessions.col.charge":             "Заряд",
+		"tui.sessions.col.rate":               "Скорость",
+		"tui.sessions.row":                    "%5d %-12s %-17s %-15s %3d%% → %3d%% %5.1f%%/ч",
+		"tui.sessions.recent":                 "Последних сессий: %d",
+		"tui.sessions.range":                  "Сессий за период %s: %d",
+		"tui.sessions.note_hint":              "Заметка к сессии: batmon note add --session <№> <текст>",
+		"tui.charging.none":                   "Сессий зарядки пока нет.\n",
+		"tui.charging.col.watts":              "Вт",
+		"tui.charging.col.notes":              "Замечания",
+		"tui.charging.legend":                 "Медленная зарядка: 20→80%% дольше %s; затянутый дозаряд: 80→100%% дольше %s",
+		"tui.forecast.title":                  "🔮 Прогнозы и аналитика\n",
+		"tui.forecast.runtime":                "⏱️ Прогноз времени работы:\n",
+		"tui.forecast.current":                "• При текущей нагрузке: %s\n",
+		"tui.forecast.light":                  "• При легкой нагрузке: %s\n",
+		"tui.forecast.heavy":                  "• При тяжелой нагрузке: %s\n",
+		"tui.forecast.wear":                   "📉 Прогноз износа батареи:\n",
+		"tui.forecast.wear.month":             "Через %d мес: %.1f%% износа (%d циклов)",
+		"tui.forecast.tips":                   "💡 Советы по продлению срока службы:\n",
+		"tui.forecast.tip.1":                  "Держите заряд в диапазоне 20-80% для минимального износа",
+		"tui.forecast.tip.2":                  "Избегайте полной разрядки батареи",
+		"tui.forecast.tip.3":                  "Используйте оригинальное зарядное устройство",
+		"tui.forecast.tip.4":                  "Избегайте перегрева (>45°C) и переохлаждения (<10°C)",
+		"tui.forecast.tip.5":                  "При длительной работе от сети извлекайте батарею (если возможно)",
+		"tui.forecast.excellent":              "\n✅ Батарея в отличном состоянии!",
+		"tui.forecast.good":                   "\n⚡ Батарея в хорошем состоянии",
+		"tui.forecast.replace":                "\n⚠️ Рекомендуется замена батареи",
+		"welcome.subtitle":                    "Интеллектуальный анализ батареи MacBook",
+		"welcome.purpose":                     "🎯 ЦЕЛЬ ПРОГРАММЫ",
+		"welcome.purpose.text":                "Помочь вам принять обоснованное решение:\n",
+		"welcome.question":                    "НУЖНО ЛИ МЕНЯТЬ БАТАРЕЮ В ВАШЕМ MacBook?",
+		"welcome.how":                         "🔍 КАК ЭТО РАБОТАЕТ",
+		"welcome.how.1":                       "1. Программа собирает данные о работе батареи\n",
+		"welcome.how.2":                       "2. Анализирует реальные показатели vs. заявленные\n",
+		"welcome.how.3":                       "3. Выявляет аномалии и проблемы\n",
+		"welcome.how.4":                       "4. Даёт чёткую рекомендацию с обоснованием\n\n",
+		"welcome.why":                         "⚠️ ЗАЧЕМ ЭТО НУЖНО",
+		"welcome.why.text":                    "Стандартные показатели macOS могут обманывать:\n",
+		"welcome.why.1":                       "• Батарея показывает 5 часов, а садится за 2 часа\n",
+		"welcome.why.2":                       "• Заряд резко проваливается с 90% до 40%\n",
+		"welcome.why.3":                       "• Перегрев при обычной нагрузке\n\n",
+		"welcome.why.promise":                 "BatMon выявит такие проблемы и объяснит их причины!",
+		"welcome.start":                       "🚀 НАЧНЁМ!",
+		"welcome.start.text":                  "Для максимально точного анализа:\n",
+		"welcome.start.1":                     "1. Зарядите MacBook до 100%\n",
+		"welcome.start.2":                     "2. Выберите 'Полный анализ батареи'\n",
+		"welcome.start.3":                     "3. Используйте MacBook как обычно до разрядки\n",
+		"welcome.start.4":                     "4. MacBook не будет засыпать (кроме закрытия крышки)\n\n",
+		"welcome.continue":                    "Нажмите Enter или Пробел для продолжения\n",
+		"welcome.quit":                        "'q' для выхода",
+		"quick.no_data":                       "❌ Данные о батарее недоступны\n\nНажмите 'q' для выхода в меню",
+		"quick.title":                         "⚡ БЫСТРАЯ ДИАГНОСТИКА БАТАРЕИ",
+		"quick.current":                       "📊 ТЕКУЩЕЕ СОСТОЯНИЕ",
+		"quick.charge":                        "🔋 Заряд: %s\n",
+		"quick.state":                         "🔄 Состояние: %s\n",
+		"quick.temperature":                   "🌡️ Температура: %s\n",
+		"quick.health":                        "💚 ЗДОРОВЬЕ БАТАРЕИ",
+		"quick.wear":                          "📉 Износ: %s\n",
+		"quick.cycles":                        "🔁 Циклы: %s\n",
+		"quick.overall":                       "💚 Общая оценка: %s\n\n",
+		"quick.recommendation":                "🎯 БЫСТРАЯ РЕКОМЕНДАЦИЯ",
+		"quick.good":                          "✅ Батарея в хорошем состоянии. Замена не требуется.",
+		"quick.plan":                          "⚠️ Батарея работает, но стоит планировать замену.",
+		"quick.replace":                       "🔴 Рекомендуется замена батареи.",
+		"quick.tip":                           "💡 СОВЕТ",
+		"quick.tip.1":                         "Для полного анализа выберите '🔋 Полный анализ батареи'\n",
+		"quick.tip.2":                         "или '📊 Детальный отчет' для графиков и трендов\n\n",
+
+		// экспорт из командной строки
+		"export.title":    "🔋 Batmon - Экспорт отчетов",
+		"export.markdown": "📝 Экспортирую отчет в Markdown: %s",
+		"export.html":     "🌐 Экспортирую отчет в HTML: %s",
+
+		// тест полной разрядки
+		"calibration.note.paused":          "подключена зарядка при %d%%",
+		"calibration.alert.paused":         "batmon: тест батареи на паузе",
+		"calibration.alert.paused.body":    "Подключена зарядка при %d%%. Продолжите тест после отключения зарядки или завершите его досрочно.",
+		"calibration.note.partial":         "частичный тест: ",
+		"calibration.md.title":             "# 🔋 Отчет о полном тесте батареи (100% → 0%)\n\n",
+		"calibration.md.started":           "**Начало разрядки:** %s  \n",
+		"calibration.md.finished":          "**Завершение:** %s\n\n",
+		"calibration.md.partial":           "> ⚠️ Тест завершен досрочно (%s): разряжено %d%% из %d%%, время пересчитано на полную разрядку.\n\n",
+		"calibration.md.runtime":           "## ⏱️ Время работы\n\n",
+		"calibration.md.measured":          "- **Измерено:** %s (%d%% → %d%%)\n",
+		"calibration.md.paused":            "- **Паузы на зарядке:** %s, подзаряжено %d%% (не входят в разрядку)\n",
+		"calibration.md.full":              "- **Пересчет на 100%% → 0%%:** %s\n",
+		"calibration.md.apple":             "- **Оценка macOS в начале теста:** %s\n",
+		"calibration.md.deviation":         "- **Отклонение от оценки:** %+.0f%%\n",
+		"calibration.md.apple_none":        "- **Оценка macOS:** недоступна\n",
+		"calibration.md.capacity":          "\n## ⚡ Ёмкость\n\n",
+		"calibration.md.delivered":         "- **Отдано за тест:** %d мАч\n",
+		"calibration.md.current":           "- **Средний ток разрядки:** %.0f мА\n",
+		"calibration.md.full_design":       "- **Полная / проектная ёмкость:** %d / %d мАч (износ %.1f%%)\n",
+		"calibration.md.milestones":        "\n## 📍 Контрольные точки\n\n",
+		"calibration.md.milestones.header": "| Заряд | Время | С начала разрядки |\n",
+		"calibration.none":                 "Тестов калибровки еще не было. Запустите: batmon calibration start",
+		"calibration.cli.started":          "✅ Тест начат. Отключите зарядку и не останавливайте сбор данных (batmon collect или интерфейс)",
+		"calibration.note.aborted":         "прерван пользователем",
+		"calibration.cli.resumed":          "▶️ Тест продолжен. Отключите зарядку – разрядка продолжится с текущего заряда",
+		"calibration.cli.finished":         "✅ Тест завершен досрочно. Отчет: batmon calibration report",
+		"calibration.cli.saved":            "✅ Отчет о тесте сохранен: %s",
+		"calibration.cli.unknown_action":   "❌ Неизвестное действие calibration: %s (status, start, abort, resume, finish, report)",
+		"calibration.status.completed":     "✅ Тест завершен %s: %s разрядки (%d%% → %d%%)",
+		"calibration.status.aborted":       "⏹️ Тест прерван %s: %s",
+		"calibration.status.paused":        "⏸️ Тест на паузе с %s: %s. Продолжить – batmon calibration resume, завершить досрочно – batmon calibration finish",
+		"calibration.status.waiting":       "⏳ Тест начат – отключите зарядку, чтобы начать разрядку",
+		"calibration.status.resumed":       "⏳ Тест продолжен – отключите зарядку, чтобы продолжить разрядку",
+		"calibration.status.running":       "🔋 Идет разрядка: %s с начала (старт при %d%%)",
+		"calibration.tui.no_data":          "❌ Нет данных о батарее – подождите первого измерения",
+		"calibration.tui.started":          "✅ Тест начат. Отключите зарядку и работайте как обычно",
+		"calibration.tui.resumed":          "▶️ Тест продолжен. Отключите зарядку",
+		"calibration.tui.finished":         "✅ Тест завершен досрочно",
+		"calibration.tui.aborted":          "⏹️ Тест прерван",
+		"calibration.tui.saved":            "✅ Отчет сохранен: %s",
+		"calibration.tui.title":            "🔋 ПОЛНЫЙ АНАЛИЗ БАТАРЕИ (100% → 0%)",
+		"calibration.tui.progress":         "📊 ХОД ТЕСТА",
+		"calibration.tui.progress.line":    "Заряд: %d%%  Прогресс: %s %.0f%%\n",
+		"calibration.tui.apple":            "Оценка macOS на старте: %s\n",
+		"calibration.tui.milestones":       "📍 КОНТРОЛЬНЫЕ ТОЧКИ",
+		"calibration.tui.running_tip":      "\n💡 Не подключайте зарядку до конца теста. Засыпание системы отключено.\n",
+		"calibration.tui.keys.running":     "d – дашборд · x – прервать тест · q – меню",
+		"calibration.tui.paused":           "⏸️ ТЕСТ НА ПАУЗЕ",
+		"calibration.tui.paused.note":      "Во время разрядки %s. Данные на зарядке в тест не попадут.\n\n",
+		"calibration.tui.paused.progress":  "Разряжено: %d%% за %s, достигнуто контрольных точек: %d\n\n",
+		"calibration.tui.paused.resume":    "r – продолжить: тест возобновится, когда зарядку отключат\n",
+		"calibration.tui.paused.finish":    "f – завершить досрочно: отчет по уже набранной разрядке\n",
+		"calibration.tui.keys.paused":      "r – продолжить · f – завершить · x – прервать · q – меню",
+		"calibration.tui.partial":          "✅ ТЕСТ ЗАВЕРШЕН ДОСРОЧНО",
+		"calibration.tui.completed":        "✅ ТЕСТ ЗАВЕРШЕН",
+		"calibration.tui.runtime":          "Время разрядки: %s (%d%% → %d%%)\n",
+		"calibration.tui.full":             "Пересчет на 100%% → 0%%: %s\n",
+		"calibration.tui.deviation":        "Оценка macOS: %s (отклонение %+.0f%%)\n",
+		"calibration.tui.delivered":        "Отдано: %d мАч, средний ток %.0f мА\n",
+		"calibration.tui.keys.completed":   "e – сохранить отчет · enter – новый тест · q – меню",
+		"calibration.tui.howto":            "📋 КАК ПРОВЕСТИ ТЕСТ",
+		"calibration.tui.howto.1":          "1. Зарядите MacBook до 100%\n",
+		"calibration.tui.howto.2":          "2. Нажмите Enter и отключите зарядку\n",
+		"calibration.tui.howto.3":          "3. Работайте как обычно, не закрывая batmon\n",
+		"calibration.tui.howto.4":          "4. Тест завершится сам при заряде ниже %d%%\n\n",
+		"calibration.tui.keys.idle":        "enter – начать тест · d – дашборд · q – меню",
+		"calibration.tui.waiting":          "⏳ Ожидание первого измерения...\n",
+		"calibration.tui.ready":            "✅ Заряд %d%% – можно начинать\n",
+		"calibration.tui.not_ready":        "❌ Заряд %d%% – зарядите до 100%% (минимум %d%%)\n",
+
+		// диагностика сборщика
+		"doctor.probe.not_found":          "не найден в PATH",
+		"doctor.probe.not_found.fix":      "BatMon вызывает системные утилиты macOS: проверьте, что /usr/bin и /usr/sbin есть в PATH (which %s)",
+		"doctor.probe.error":              "ошибка: %v",
+		"doctor.probe.error.fix":          "выполните «%s» в терминале и посмотрите сообщение",
+		"doctor.probe.no_battery":         "ответ без данных о батарее",
+		"doctor.probe.no_battery.fix":     "на Mac без батареи это нормально; иначе проверьте вывод «%s»",
+		"doctor.probe.slow":               "отвечает медленно",
+		"doctor.probe.slow.fix":           "система перегружена или утилита зависает; при постоянных задержках увеличьте интервал опроса в настройках",
+		"doctor.probe.ok":                 "отвечает",
+		"doctor.source":                   "источник %s",
+		"doctor.source.fix":               "batmon diag покажет подробности; без источника новые измерения не появятся",
+		"doctor.source.slow":              "источник отвечает медленно: увеличьте интервал опроса в настройках",
+		"doctor.last_sample":              "последнее измерение",
+		"doctor.last_sample.failures":     "%d ошибок сбора подряд, последняя в %s: %v",
+		"doctor.last_sample.failures.fix": "проверьте утилиты выше и экран «Журнал»",
+		"doctor.last_sample.age":          "%s назад (%s)",
+		"doctor.last_sample.stale":        "сбор давно не запускался: Mac спал или цикл опроса завис – перезапустите BatMon",
+		"doctor.last_sample.read":         "чтение базы: %v",
+		"doctor.last_sample.read.fix":     "проверьте файл базы (batmon db path) или восстановите копию: batmon db restore",
+		"doctor.last_sample.empty":        "в базе нет измерений",
+		"doctor.last_sample.empty.fix":    "запустите интерфейс или batmon collect",
+		"doctor.last_sample.old":          "если BatMon сейчас не запущен, это нормально; для сбора в фоне – batmon collect",
+		"doctor.db_write":                 "запись в базу",
+		"doctor.db_write.conn":            "соединение: %v",
+		"doctor.db_write.conn.fix":        "проверьте путь к базе (--db) и права на файл",
+		"doctor.db_write.locked":          "базу держит другой процесс: закройте второй BatMon или batmon collect",
+		"doctor.db_write.denied":          "нет прав на запись: ls -l %s",
+		"doctor.db_write.dir":             "папка базы недоступна для записи: %v",
+		"doctor.db_write.dir.fix":         "SQLite создает рядом с базой файлы -wal и -shm: проверьте права на %s",
+		"doctor.disk":                     "место на диске",
+		"doctor.disk.memory":              "база в памяти",
+		"doctor.disk.error":               "не удалось узнать: %v",
+		"doctor.disk.free":                "свободно %s",
+		"doctor.disk.critical":            "освободите место: запись в базу и журнал скоро остановится",
+		"doctor.disk.low":                 "мало места; сократите срок хранения в настройках или выполните batmon db cleanup --days 30",
+		"doctor.caffeinate":               "запрет сна",
+		"doctor.caffeinate.unsupported":   ", на этой ОС недоступен",
+		"doctor.caffeinate.missing":       "%s не найден",
+		"doctor.caffeinate.missing.fix":   "калибровочный тест может прерваться засыпанием: настройте сон вручную",
+		"doctor.caffeinate.running":       ", %s запущен",
+		"doctor.caffeinate.stopped":       ", не запущен",
+		"doctor.caffeinate.orphan":        "осиротевший %s (PID %d) от завершившегося BatMon не дает Mac уснуть",
+		"doctor.caffeinate.orphan.fix":    "запустите интерфейс BatMon – он завершит процесс, или: kill %d",
+		"doctor.caffeinate.other":         ", %s (PID %d) держит другой запуск BatMon",
+		"doctor.log":                      "журнал",
+		"doctor.log.empty":                "пуст",
+		"doctor.log.counts":               "за час: ошибок %d, предупреждений %d",
+		"doctor.log.last":                 "; последнее: %s",
+		"doctor.log.fix":                  "подробности – экран «Журнал» или %s",
+
+		// batmon check
+		"check.exit_code":         "код выхода %d",
+		"check.above_critical":    " ≥ %g (критично)",
+		"check.wear":              "износ %.1f%%",
+		"check.cycles":            "циклов %.0f",
+		"check.anomalies":         "аномалий %.0f",
+		"check.err.data":          "получение данных: %w",
+		"check.err.no_history":    "нет истории и не удалось прочитать батарею: %w",
+		"check.err.no_design":     "источник не сообщает проектную ёмкость",
+		"check.unknown_db":        "UNKNOWN: инициализация БД: %v",
+		"check.summary":           "%s: износ %.1f%%, циклов %d",
+		"check.live":              " (без истории)",
+		"check.summary.anomalies": ", аномалий %d",
+
+		// таблица истории
+		"history.col.time":              "Время",
+		"history.col.charge":            "Заряд",
+		"history.col.state":             "Состояние",
+		"history.col.cycles":            "Циклы",
+		"history.col.temp":              "Темп.",
+		"history.col.wear":              "Износ",
+		"history.detail.current_cap":    "Текущая ёмкость",
+		"history.detail.full_cap":       "Полная ёмкость",
+		"history.detail.design_cap":     "Проектная ёмкость",
+		"history.detail.temperature":    "Температура",
+		"history.detail.voltage":        "Напряжение",
+		"history.detail.voltage.value":  "%d мВ",
+		"history.detail.amperage":       "Ток",
+		"history.detail.amperage.value": "%d мА",
+		"history.detail.power":          "Мощность",
+		"history.detail.power.value":    "%.2f Вт",
+		"history.detail.apple":          "Состояние по Apple",
+		"history.detail.serial":         "Серийный номер",
+		"history.detail.brightness":     "Яркость экрана",
+		"history.detail.lid":            "Крышка",
+		"history.detail.cells":          "Ячейки",
+		"history.detail.cells.value":    "%s мВ (разброс %d мВ)",
+		"history.detail.title":          "Измерение #%d",
+		"history.detail.close":          "Enter/Esc – закрыть",
+
+		// форма экспорта
+		"export.form.to_placeholder": "пусто – до сейчас",
+		"export.form.no_format":      "выберите хотя бы один формат",
+		"export.form.no_name":        "укажите имя файла",
+		"export.form.preparing":      "подготовка данных",
+		"export.form.step":           "%s (%d из %d)",
+		"export.form.title":          "📄 Экспорт отчетов\n\n",
+		"export.form.running":        "⏳ Экспорт: %s",
+		"export.form.created":        "✅ Созданы файлы:\n",
+		"export.form.done_keys":      "\nЛюбая клавиша – вернуться к форме, q – в главное меню",
+		"export.form.formats":        "Форматы (пробел – выбрать):\n",
+		"export.form.name":           "Файл (без расширения):",
+		"export.form.from":           "С:  ",
+		"export.form.to":             "По: ",
+		"export.form.range_hint":     "  Пустой период – последние измерения, как в отчете\n\n",
+		"export.form.run":            "[ Экспортировать ]",
+		"export.form.vars":           "Подстановки: {date}, {time}, {host}, {serial}, {format}",
+		"export.form.keys":           "Tab/↑↓ – поле • Enter – экспорт • Esc – главное меню",
+		"export.form.err.format":     "экспорт в %s: %w",
+
+		// сертификат состояния батареи
+		"cert.title":            "Сертификат состояния батареи",
+		"cert.issued":           "Выдан %s утилитой batmon",
+		"cert.model":            "Модель оценки: %s",
+		"cert.serial":           "Серийный номер батареи",
+		"cert.serial.unknown":   "не определен",
+		"cert.full_cap":         "Измеренная ёмкость",
+		"cert.mah":              "%d мАч",
+		"cert.design_cap":       "Проектная ёмкость",
+		"cert.wear":             "Износ",
+		"cert.cycles":           "Циклы заряда",
+		"cert.age":              "Возраст батареи",
+		"cert.condition":        "Состояние по данным macOS",
+		"cert.period":           "Период наблюдения",
+		"cert.days":             "%d дн.",
+		"cert.measurements":     "Измерений",
+		"cert.code":             "Код проверки:",
+		"cert.note":             "Контрольная сумма рассчитана по всем измерениям этой батареи и сохранена в базе batmon. Продавец может подтвердить её командой",
+		"cert.note.where":       "на этом компьютере, в том числе после очистки старых измерений.",
+		"cert.pdf":              "Чтобы сохранить в PDF, выберите «Печать» → «Сохранить как PDF».",
+		"cert.age.manufactured": "%s, изготовлена %s",
+		"cert.age.at_least":     "не менее %s (дата изготовления неизвестна)",
+		"age.days":              "%d дн.",
+		"age.months":            "%d мес.",
+		"age.years":             "%d г.",
+		"age.years_months":      "%d г. %d мес.",
+		"cert.cli.saved":        "✅ Сертификат сохранен: %s",
+		"cert.cli.code":         "🔐 Код проверки: %s",
+		"cert.cli.verified":     "✅ Сертификат подтвержден: выдан по данным на %s, %d измерений, износ %.1f%%, %d циклов",
+		"cert.err.no_data":      "нет данных для сертификата",
+		"cert.err.no_capacity":  "ёмкость батареи еще не измерена, продолжите мониторинг",
+		"cert.err.mismatch":     "код не совпадает с данными этой батареи – данные изменены или сертификат выдан на другом компьютере",
+
+		// ограничение заряда
+		"charge_limit.ceiling": "Заряд %d%% (потолок %d%%) - отключите адаптер, чтобы батарея не держалась на высоком заряде",
+		"charge_limit.floor":   "Заряд %d%% (пол %d%%) - подключите адаптер, глубокая разрядка ускоряет износ",
+		"charge_limit.alert":   "batmon: ограничение заряда",
+
+		// кэш отчета
+		"report.cache.built_at": "данные от %s",
+
+		// вкладка расширенных метрик
+		"metrics.tab.title":              "🔬 Расширенные метрики\n",
+		"metrics.tab.no_data":            "Недостаточно данных для анализа.\n",
+		"metrics.tab.stability":          "🔧 Стабильность напряжения",
+		"metrics.tab.stability.meaning":  "Насколько ровно держится напряжение батареи. Ниже 95% – скачки под нагрузкой, признак роста внутреннего сопротивления.",
+		"metrics.tab.stability.formula":  "100 × (1 − σ/среднее) по напряжению всех измерений периода (коэффициент вариации).",
+		"metrics.tab.efficiency":         "⚡ Энергоэффективность",
+		"metrics.tab.efficiency.meaning": "Насколько экономно расходуется энергия: чем меньше средняя мощность, тем выше значение.",
+		"metrics.tab.efficiency.formula": "100 − средняя |мощность| в мВт / 100; 0, если средняя мощность выше 10 Вт.",
+		"metrics.tab.health":             "🏆 Рейтинг здоровья",
+		"metrics.tab.health.meaning":     "Общая оценка батареи по износу, циклам, температуре и стабильности напряжения.",
+		"metrics.tab.health.formula":     "100 − износ × 0.5 − циклы / 10 − градусы выше 45°C − (95 − стабильность напряжения, если она ниже 95%).",
+		"metrics.tab.formula":            "Расчет: %s",
+		"metrics.tab.charging":           "🔋 Эффективность зарядки",
+		"metrics.tab.charging.value":     "%.2f мАч/мВт\n",
+		"metrics.tab.charging.none":      "нет данных о мощности зарядки\n",
+		"metrics.tab.charging.meaning":   "Сколько запасенной ёмкости приходится на милливатт мощности зарядки. Условная величина: важна не сама цифра, а ее изменение со временем.\n",
+		"metrics.tab.charging.formula":   "среднее отношение текущей ёмкости к мощности по измерениям с положительной мощностью (на зарядке).",
+		"metrics.tab.trend":              "📊 Тренд мощности",
+		"metrics.tab.trend.none":         "мало данных",
+		"metrics.tab.trend.formula":      "три последних измерения мощности – растет, снижается или без явного направления.",
+		"metrics.tab.apple":              "🍎 Статус Apple",
+		"metrics.tab.apple.note":         "Состояние из системы; если macOS его не сообщает – оценка по рейтингу здоровья (85+ Normal, 70+ Service Recommended).",
+
+		// яркость экрана
+		"brightness.low":        "🔅 яркость до 33%",
+		"brightness.mid":        "🔆 яркость 34–66%",
+		"brightness.high":       "☀️ яркость от 67%",
+		"brightness.lid_closed": "🌙 крышка закрыта",
+		"brightness.note.lid":   ", крышка закрыта",
+		"brightness.note.high":  ", яркость экрана %d%%",
+
+		// аномалии показаний
+		"anomaly.voltage_sag":        "Просадка напряжения под нагрузкой: %d → %d мВ при токе %d мА (%s)",
+		"anomaly.capacity_over_full": "Сбой показаний ёмкости: текущая %d мАч больше полной %d мАч (%s)",
+		"anomaly.capacity_spike":     "Сбой показаний ёмкости: полная ёмкость %d → %d → %d мАч (%s)",
+		"anomaly.sleep_drain":        "Повышенный саморазряд во сне: %d%% → %d%% за %s (%.1f%%/ч, %s–%s)",
+
+		// замер производительности
+		"bench.usage":        "❌ Нужно хотя бы 2 измерения и 1 прогон",
+		"bench.title":        "⏱️ Замер конвейера анализа: измерений %d, прогонов %d\n",
+		"bench.insert":       "Запись синтетической истории: %s\n",
+		"bench.stage.export": "экспорт Markdown и HTML",
+		"bench.stage.tui":    "отчет в TUI, все вкладки",
+		"bench.col.stage":    "Этап",
+		"bench.col.best":     "лучший",
+		"bench.col.mean":     "средний",
+		"bench.col.allocs":   "выделений",
+		"bench.col.bytes":    "памяти",
+		"bytes.gb":           "%.1f ГБ",
+		"bytes.mb":           "%.1f МБ",
+		"bytes.kb":           "%.1f КБ",
+		"bytes.b":            "%d Б",
+
+		// графики в PNG и SVG
+		"chart.metric.percentage":  "Заряд",
+		"chart.metric.capacity":    "Полная ёмкость",
+		"chart.metric.temperature": "Температура",
+		"chart.metric.power":       "Мощность",
+		"chart.unit.percent":       "%",
+		"chart.unit.mah":           "мАч",
+		"chart.unit.celsius":       "°C",
+		"chart.unit.watts":         "Вт",
+		"chart.cli.no_out":         "❌ Укажите файл --out с расширением .png или .svg",
+		"chart.cli.too_small":      "❌ Слишком маленький график: нужно не меньше 300×150",
+		"chart.cli.unknown_metric": "❌ Неизвестная метрика %q, доступны: %s",
+		"chart.cli.saved":          "✅ График сохранен: %s (%s)",
+
+		// окно графиков
+		"chart.window.minutes": "%dм",
+		"chart.window.hours":   "%dч",
+		"chart.window.days":    "%dд",
+		"chart.window.until":   "%s до %s",
+
+		// графики в терминале
+		"tui.charts.no_data_chart": "Нет данных для отображения",
+		"chart.title.charge":       "⚡ Заряд батареи (%)",
+		"chart.title.capacity":     "🔋 Емкость (мАч)",
+		"chart.title.temperature":  "🌡️ Температура (°C) · %d/%d",
+		"chart.title.power":        "⚡ Мощность (Вт)",
+
+		// распределение заряда
+		"histogram.advice.full": "%.0f%% времени на 100%% – включите оптимизированную зарядку или ограничение до %d%%",
+		"histogram.advice.low":  "%.0f%% времени ниже %d%% – ставьте на зарядку раньше, глубокий разряд ускоряет износ",
+		"histogram.title":       "📊 Распределение заряда по времени:",
+		"histogram.full":        "• На 100%%: %s (%.0f%%)\n",
+		"histogram.low":         "• Ниже %d%%: %s (%.0f%%)\n",
+
+		// графики дашборда
+		"dashboard.chart.capacity":    "📈 График емкости",
+		"dashboard.chart.temperature": "🌡️ График температуры",
+		"dashboard.chart.power":       "⚡ График мощности",
+		"dashboard.watts.discharge":   "разряд",
+		"dashboard.watts.charge":      "заряд",
+		"dashboard.watts.direction":   "Вт · %s",
+		"dashboard.watts.average":     "\nсреднее за %s: %.1f Вт",
+		"dashboard.watts.vi":          "\n%.2f В × %.2f А",
+		"dashboard.cursor.charge":     "заряд %d%%",
+		"dashboard.cursor.capacity":   "ёмкость %s",
+		"dashboard.cursor.watts":      "%.1f Вт",
+		"dashboard.cursor.keys":       "   ←→ сдвиг, x – выход",
+
+		// парк машин
+		"fleet.md.title":       "# 💻 Парк MacBook: состояние батарей\n\n",
+		"fleet.md.generated":   "**Сформирован:** %s  \n",
+		"fleet.md.machines":    "**Машин:** %d\n\n",
+		"fleet.md.header":      "| | Хост | Модель | Циклы | Износ | Рейтинг | Снимок | Замечания |\n",
+		"fleet.col.host":       "Хост",
+		"fleet.col.model":      "Модель",
+		"fleet.col.cycles":     "Циклы",
+		"fleet.col.wear":       "Износ",
+		"fleet.col.score":      "Рейтинг",
+		"fleet.col.snapshot":   "Снимок",
+		"fleet.summary":        "\nМашин: %d, требуют внимания: %d (пороги batmon check: износ %g%%, циклов %d)",
+		"fleet.unknown_action": "❌ Неизвестное действие fleet: %s (import, report, remove)",
+		"fleet.import.usage":   "❌ Укажите файлы или папку со снимками: batmon fleet import [--host имя] <файл.json|папка>...",
+		"fleet.imported":       "✅ Файлов: %d, снимков добавлено: %d, уже были: %d",
+		"fleet.hosts":          "💻 Машины: %s",
+		"fleet.empty":          "Снимков парка нет. На каждой машине выполните batmon status --json > имя.json и импортируйте: batmon fleet import <папка>",
+		"fleet.saved":          "✅ Отчет парка сохранен: %s",
+		"fleet.remove.usage":   "❌ Укажите машину: batmon fleet remove <хост>",
+		"fleet.removed":        "✅ Машина %q удалена из парка",
+
+		// заметки
+		"note.unknown_action": "❌ Неизвестное действие %q: add, list или delete",
+		"note.delete.usage":   "❌ Укажите номер заметки: batmon note delete <номер>",
+		"note.deleted":        "🗑️ Заметка %d удалена",
+		"note.add.usage":      "❌ Укажите текст: batmon note add [--at время | --from время --to время | --session номер] <текст>",
+		"note.added":          "%s Заметка %d: %s",
+		"note.empty":          "Заметок нет. Добавьте: batmon note add \"поставил macOS 15.2\"",
+
+		// снимки
+		"snapshot.usage":          "❌ Укажите имя снимка: batmon snapshot %s <имя>",
+		"snapshot.saved":          "✅ Снимок %q сохранен: износ %.1f%%, циклов %d",
+		"snapshot.empty":          "Снимков нет. Сохраните текущее состояние: batmon snapshot save <имя>",
+		"snapshot.row":            "%-20s %s  износ %5.1f%%  циклов %4d  работа от заряда %s",
+		"snapshot.deleted":        "🗑️ Снимок %q удален",
+		"snapshot.unknown_action": "❌ Неизвестное действие %q: save, list, compare или delete",
+
+		// описание схемы
+		"schema.source.report":      "экспорт JSON (экран экспорта), GET /api/v1/report",
+		"schema.source.status":      "batmon status --json",
+		"schema.source.measurement": "GET /api/v1/latest, элементы GET /api/v1/measurements",
+		"schema.source.health":      "GET /api/v1/health",
+		"schema.title":              "📐 Схема БД версии %d (batmon %s)",
+		"schema.index":              "индекс",
+		"schema.unique_index":       "уникальный индекс",
+		"schema.exports":            "\n📤 Выгрузки:",
+		"schema.export_row":         "   %-12s %d полей – %s",
+		"schema.csv_row":            "   %-12s %d столбцов – экспорт CSV",
+		"schema.json_hint":          "\nПолное описание с JSON Schema: batmon schema --json",
+
+		// правила
+		"rules.source.builtin":  "встроенное",
+		"rules.source.override": "изменено в config.json",
+		"rules.row":             "%s %-22s %s\n   если %s\n   → %s",
+		"rules.error":           "   ошибка: %v",
+		"rules.metrics":         "\nМетрики условий: %s",
+
+		// batmon status
+		"status.remaining": ", осталось ",
+		"status.wear":      ", износ %.1f%%, циклов %d",
+
+		// tmux
+		"tmux.cache_failed": "⚠️ не удалось записать кэш tmux: %v",
+
+		// очистка базы в интерфейсе
+		"clear.title":             "🗑️ Очистка базы данных\n\n",
+		"clear.warning":           "⚠️  ВНИМАНИЕ: Эта операция удалит ВСЕ сохраненные данные!\n\n",
+		"clear.will_delete":       "Будут удалены:\n",
+		"clear.item.measurements": "• Все измерения батареи\n",
+		"clear.item.states":       "• История состояний\n",
+		"clear.item.stats":        "• Статистика использования\n\n",
+		"clear.backup":            "💾 Перед очисткой копия базы сохранится в папке backups (вернуть: batmon db restore <путь>)\n\n",
+		"clear.confirm":           "Нажмите Y для подтверждения очистки\n",
+		"clear.cancel":            "Нажмите q или N для отмены",
+
+		// события питания
+		"power_event.sleep":       "Сон",
+		"power_event.wake":        "Пробуждение",
+		"power_event.dark_wake":   "Темное пробуждение",
+		"power_event.battery":     "Питание от батареи",
+		"power_event.ac":          "Питание от сети",
+		"sleep_wakes.summary":     "%s: −%d%% за %s, пробуждений: %d (темных: %d)",
+		"sleep_wakes.reason":      ", чаще всего – %s",
+		"power_event.legend":      "z сон  ↑ пробуждение  · темное пробуждение  ϟ источник питания",
+		"power_event.legend.note": "заметка",
+
+		// мощность SoC
+		"thermal_pressure.nominal":  "нормальное",
+		"thermal_pressure.moderate": "умеренное",
+		"thermal_pressure.heavy":    "высокое",
+		"thermal_pressure.critical": "критическое",
+		"thermal_pressure.none":     "нет данных",
+		"soc_power.title":           "⚙️ Питание SoC (powermetrics)\n",
+		"soc_power.components":      "CPU: %s  GPU: %s  ANE: %s  Всего: %s\n",
+		"soc_power.package":         "Пакет CPU: %s\n",
+		"soc_power.battery":         "Батарея отдает: %s, из них вне SoC: %s\n",
+		"soc_power.pressure":        "Тепловое давление: %s",
+		"soc_power.watts":           "%.2f Вт",
+
+		// троттлинг
+		"throttling.period.pressure":     ", давление %s",
+		"throttling.period.speed":        ", частота CPU до %d%%",
+		"throttling.period.temp":         ", батарея %.1f°C",
+		"throttling.none":                "Троттлинга не было (наблюдение %s).",
+		"throttling.summary":             "Троттлинг %s из %s (%.0f%% времени), периодов: %d.",
+		"throttling.summary.temp":        " Батарея при троттлинге %.1f°C, без него %.1f°C.",
+		"throttling.summary.drain":       " Разрядка при троттлинге %.1f%%/ч, без него %.1f%%/ч.",
+		"throttling.verdict.cpu":         "Троттлинг шел при нормальной температуре батареи (%.1f°C): Mac замедлял перегретый процессор, батарея тут ни при чем.",
+		"throttling.verdict.both":        "При троттлинге батарея тоже перегрета: причина общая – нагрузка и охлаждение. Такой нагрев ускоряет и износ батареи, снизьте нагрузку и не закрывайте вентиляцию.",
+		"throttling.verdict.drain":       "Под троттлингом батарея садится в %.1f раза быстрее: быструю разрядку вызывает нагрузка, а не износ.",
+		"throttling.verdict.hot_battery": "Батарея была выше %d°C без троттлинга %s: это нагрев самой батареи или зарядки, а не процессора.",
+
+		// тепловая карта
+		"heatmap.empty":       "Нет данных о работе от батареи",
+		"heatmap.legend.rate": "скорость разряда",
+		"heatmap.legend.time": "время от батареи",
+		"heatmap.less":        "\nменьше ",
+		"heatmap.more":        " больше · цвет: %s (m – переключить)",
+
+		// вехи износа
+		"wear_event.reached": "Износ батареи достиг %d%% (%d циклов)",
+		"wear_event.pace":    ": %d%% → %d%% за %d дн. и %d циклов",
+		"wear_event.alert":   "batmon: износ батареи",
+		"wear_event.initial": "%d%% – начало наблюдений",
+		"wear_event.step":    "%.0f дн., %d циклов",
+
+		// нагрев при зарядке
+		"thermal.alert.title": "batmon: горячая зарядка",
+
+		// периоды нагрева
+		"thermal_event.charging": " на зарядке",
+		"thermal_event.message":  "Длительный нагрев%s: выше %d°C %s, пик %d°C (%s–%s)",
+
+		// расход в режиме сна по неделям
+		"standby.week": "%s %s %.1f%%/ч\n",
+
+		// напоминание о калибровке
+		"calibration_cycle.test":      "🧪 тест калибровки",
+		"calibration_cycle.discharge": "🔋 разрядка",
+		"calibration_cycle.row":       "%s: %d%% → %d%% за %s (%s)",
+		"calibration_reminder.alert":  "batmon: калибровка батареи",
+
+		// ошибки
+		"api.err.token_in_config":          "токен задан в %s – смените его там",
+		"api.err.token_read":               "чтение токена API: %w",
+		"api.err.token_create":             "создание токена API: %w",
+		"api.err.token_save":               "сохранение токена API: %w",
+		"api.err.auth_header":              "нужен заголовок Authorization: Bearer <токен> (batmon serve token)",
+		"api.err.auth_header_ws":           "нужен заголовок Authorization: Bearer <токен> или параметр access_token",
+		"api.err.method":                   "метод %s не поддерживается для %s, допустимы: %s",
+		"api.err.not_found":                "неизвестный адрес %s %s",
+		"apps.err.no_processes":            "powermetrics: нет данных о процессах",
+		"err.transaction":                  "транзакция: %w",
+		"apps.err.save":                    "сохранение выборки приложений: %w",
+		"apps.err.read":                    "выборки приложений: %w",
+		"apps.err.measurements":            "измерения: %w",
+		"err.db_init":                      "инициализация БД: %w",
+		"backup.err.dir":                   "создание папки резервных копий: %w",
+		"backup.err.snapshot":              "снимок БД: %w",
+		"err.rename":                       "переименование в %s: %w",
+		"err.backup":                       "резервная копия: %w",
+		"backup.err.open":                  "открытие резервной копии: %w",
+		"backup.err.check":                 "проверка резервной копии: %w",
+		"backup.err.corrupt":               "резервная копия повреждена: %s",
+		"backup.err.foreign":               "%s не похож на базу batmon: нет таблицы measurements",
+		"backup.err.newer":                 "схема резервной копии версии %d новее поддерживаемой (%d) - обновите batmon",
+		"backup.err.before":                "резервная копия перед операцией %q: %w",
+		"err.db_connect":                   "соединение с БД: %w",
+		"backup.err.read":                  "чтение резервной копии: %w",
+		"backup.err.write":                 "запись БД: %w",
+		"err.remove":                       "удаление %s: %w",
+		"backup.err.replace":               "замена БД: %w",
+		"baseline.err.read":                "чтение базовой точки: %w",
+		"baseline.err.first":               "поиск первого измерения: %w",
+		"baseline.err.write":               "запись базовой точки: %w",
+		"identity.err.replacements":        "поиск замен батареи: %w",
+		"bench.err.tmpdir":                 "временный каталог: %w",
+		"bench.err.cpuprofile":             "профиль CPU: %w",
+		"bench.err.memprofile":             "профиль памяти: %w",
+		"caffeinate.err.state":             "файл состояния caffeinate: %w",
+		"caffeinate.err.stop":              "остановка %s (PID %d): %w",
+		"calibration.err.read":             "чтение теста калибровки: %w",
+		"calibration.err.milestones":       "чтение контрольных точек: %w",
+		"calibration.err.running":          "тест уже идет с %s",
+		"calibration.err.not_full":         "заряд %d%%: зарядите MacBook до 100%% перед началом теста",
+		"calibration.err.create":           "создание теста калибровки: %w",
+		"calibration.err.abort":            "прерывание теста калибровки: %w",
+		"calibration.err.pause":            "пауза теста калибровки: %w",
+		"calibration.err.resume":           "продолжение теста калибровки: %w",
+		"calibration.err.not_paused":       "нет приостановленного теста",
+		"calibration.err.finish":           "завершение теста калибровки: %w",
+		"calibration.err.discharge_start":  "начало разрядки: %w",
+		"calibration.err.discharge_resume": "продолжение разрядки: %w",
+		"calibration.err.macos":            "сохранение оценки macOS: %w",
+		"calibration.err.milestone":        "контрольная точка %d%%: %w",
+		"calibration.err.none_finished":    "завершенных тестов нет",
+		"err.data":                         "получение данных: %w",
+		"err.no_measurements_collect":      "нет измерений – запустите сбор данных (batmon collect)",
+		"calibration.err.export":           "экспорт отчета калибровки: %w",
+		"calibration.err.tests":            "чтение тестов калибровки: %w",
+		"sessions.err.read":                "чтение сессий: %w",
+		"err.first_measurement":            "чтение первого измерения: %w",
+		"cert.err.save":                    "сохранение сертификата: %w",
+		"cert.err.read":                    "чтение сертификатов: %w",
+		"cert.err.short_code":              "контрольная сумма слишком короткая",
+		"err.template":                     "парсинг шаблона: %w",
+		"cert.err.export":                  "экспорт сертификата: %w",
+		"cert.err.path":                    "не удалось определить путь для сертификата: %w",
+		"charger.err.read":                 "чтение адаптера: %w",
+		"charger.err.list":                 "чтение адаптеров: %w",
+		"charger.err.write":                "запись адаптера: %w",
+		"charging.err.curves":              "чтение измерений для кривых зарядки: %w",
+		"chart.err.not_enough":             "%s: недостаточно данных для графика",
+		"chart.err.write":                  "запись графика: %w",
+		"err.export":                       "экспорт: %w",
+		"cli.err.days":                     "--days должен быть положительным",
+		"err.cleanup":                      "очистка: %w",
+		"err.restore":                      "восстановление: %w",
+		"err.import":                       "импорт: %w",
+		"err.details":                      "подробные данные: %w",
+		"mac.err.pmset":                    "сканирование pmset: %w",
+		"mac.err.no_battery":               "данные о батарее не найдены",
+		"mac.err.system_profiler":          "сканирование system_profiler: %w",
+		"mac.err.ioreg_scan":               "сканирование ioreg: %w",
+		"mac.err.ioreg_parse":              "разбор ioreg: %w",
+		"mac.err.ioreg_missing":            "разбор ioreg: AppleSmartBattery не найден",
+		"replay.err.read":                  "чтение записи: %w",
+		"replay.err.parse":                 "разбор записи %s: %w",
+		"replay.err.empty":                 "запись %s не содержит сэмплов",
+		"sysfs.err.search":                 "поиск батареи в sysfs: %w",
+		"sysfs.err.not_found":              "батарея не найдена в %s",
+		"sysfs.err.capacity":               "sysfs: нет атрибута capacity в %s",
+		"sysfs.err.voltage":                "sysfs: нет напряжения для пересчёта energy_* в мАч",
+		"sysfs.err.charge":                 "sysfs: нет атрибутов charge_now/energy_now в %s",
+		"wmi.err.empty":                    "WMI: пустой ответ, батарея не найдена",
+		"wmi.err.parse":                    "разбор ответа WMI: %w",
+		"wmi.err.no_charge":                "WMI: Win32_Battery не вернул уровень заряда",
+		"wmi.err.no_status":                "WMI: BatteryStatus недоступен (нет напряжения)",
+		"config.err.read":                  "чтение конфига: %w",
+		"err.parse":                        "разбор %s: %w",
+		"config.err.marshal":               "сериализация конфига: %w",
+		"config.err.write":                 "запись конфига: %w",
+		"network.err.disabled":             "%w: %s (включите network.%s в %s)",
+		"daily.err.read":                   "чтение сводок по дням: %w",
+		"daily.err.day":                    "разбор дня %s: %w",
+		"daily.err.measurements":           "получение измерений для сводок: %w",
+		"daily.err.transaction":            "транзакция сводок: %w",
+		"daily.err.save":                   "сохранение сводки за %s: %w",
+		"metric.err.abs":                   "abs ожидает 1 аргумент",
+		"metric.err.min":                   "min ожидает минимум 2 аргумента",
+		"metric.err.max":                   "max ожидает минимум 2 аргумента",
+		"metric.err.name":                  "метрика %q: имя должно состоять из латинских строчных букв, цифр и _",
+		"metric.err.field_name":            "метрика %q: имя совпадает с полем измерения",
+		"err.metric":                       "метрика %q: %w",
+		"metric.err.duplicate":             "метрика %q объявлена несколько раз",
+		"metric.err.save":                  "сохранение метрики %s: %w",
+		"metric.err.read":                  "чтение производных метрик: %w",
+		"metric.err.empty":                 "пустое выражение",
+		"metric.err.extra":                 "лишний символ %q в позиции %d",
+		"metric.err.eof":                   "неожиданный конец выражения",
+		"metric.err.paren":                 "нет закрывающей скобки в позиции %d",
+		"metric.err.number":                "неверное число %q",
+		"metric.err.field":                 "неизвестное поле %q (доступны: %s)",
+		"metric.err.char":                  "неожиданный символ %q в позиции %d",
+		"metric.err.func":                  "неизвестная функция %q (доступны: abs, min, max)",
+		"metric.err.comma":                 "ожидалась запятая или скобка в позиции %d",
+		"email.err.no_email":               "не указан --email",
+		"email.err.no_smtp":                "не указан --smtp host:port",
+		"email.err.no_from":                "не указан отправитель: --from-addr или --smtp-user",
+		"email.err.smtp_addr":              "адрес SMTP %q: %w",
+		"email.err.tmpdir":                 "временная папка: %w",
+		"email.err.read_report":            "чтение отчета: %w",
+		"email.err.compose":                "формирование письма: %w",
+		"email.err.send":                   "отправка письма: %w",
+		"err.connect":                      "подключение к %s: %w",
+		"email.err.auth":                   "авторизация SMTP: %w",
+		"email.err.recipient":              "получатель %s: %w",
+		"email.err.schedule_read":          "чтение расписания отчета: %w",
+		"email.err.schedule_save":          "сохранение расписания отчета: %w",
+		"atomic.err.tmp":                   "создание временного файла: %w",
+		"atomic.err.sync":                  "сброс на диск: %w",
+		"atomic.err.close":                 "закрытие временного файла: %w",
+		"atomic.err.verify":                "проверка отчета: %w",
+		"atomic.err.chmod":                 "права на файл: %w",
+		"atomic.err.no_title":              "нет заголовка отчета",
+		"atomic.err.truncated":             "отчет обрезан",
+		"atomic.err.no_doctype":            "нет объявления документа",
+		"atomic.err.doc_truncated":         "документ обрезан",
+		"err.db_open":                      "подключение к БД: %w",
+		"export.err.json":                  "запись JSON: %w",
+		"export.err.json_broken":           "JSON поврежден",
+		"export.err.csv":                   "запись CSV: %w",
+		"export.err.dir":                   "создание папки для экспорта: %w",
+		"fleet.err.not_status":             "нет поля timestamp – это не вывод batmon status --json",
+		"fleet.err.json":                   "%s: разбор JSON: %w",
+		"fleet.err.save":                   "сохранение снимка %s: %w",
+		"fleet.err.read":                   "чтение парка: %w",
+		"fleet.err.sort":                   "неизвестная сортировка %q (wear, cycles, health, host)",
+		"fleet.err.export":                 "экспорт отчета парка: %w",
+		"fleet.err.remove":                 "удаление снимков: %w",
+		"fleet.err.no_host":                "машины %q в парке нет",
+		"filter.err.operator":              "условие %q: нужен оператор (=, !=, <, <=, >, >=)",
+		"filter.err.use_eq":                "условие %q: для %s используйте =",
+		"filter.err.time_op":               "условие %q: время сравнивается через <, <=, >, >=",
+		"filter.err.condition":             "условие %q: %w",
+		"filter.err.field":                 "неизвестное поле %q (доступны: %s, from, to, time)",
+		"filter.err.text_op":               "условие %q: текстовое поле сравнивается через = или !=",
+		"filter.err.number":                "условие %q: %q – не число",
+		"history.err.count":                "подсчет истории: %w",
+		"history.err.read":                 "чтение истории: %w",
+		"hooks.err.daily":                  "чтение сводки за %s: %w",
+		"import.err.current":               "%s – это текущая база",
+		"import.err.attach":                "подключение %s: %w",
+		"err.read":                         "чтение %s: %w",
+		"import.err.transaction":           "транзакция импорта: %w",
+		"import.err.measurements":          "импорт измерений: %w",
+		"import.err.reset":                 "сброс производных данных: %w",
+		"import.err.columns_current":       "столбцы текущей базы: %w",
+		"import.err.columns_source":        "столбцы импортируемой базы: %w",
+		"import.err.no_timestamp":          "в импортируемой базе нет столбца timestamp",
+		"analysis.err.read":                "чтение состояния анализа: %w",
+		"analysis.err.marshal":             "сериализация состояния анализа: %w",
+		"analysis.err.save":                "сохранение состояния анализа: %w",
+		"analysis.err.new":                 "чтение новых измерений: %w",
+		"measurement.err.read":             "чтение измерения: %w",
+		"db.err.optimize":                  "оптимизация БД: %w",
+		"db.err.indexes":                   "создание индексов: %w",
+		"iokit.err.no_internal":            "IOPowerSources: внутренняя батарея не найдена",
+		"iokit.err.no_smart_battery":       "IOKit: AppleSmartBattery не найден",
+		"log.err.level":                    "неизвестный уровень журнала %q (debug, info, warn, error)",
+		"log.err.file":                     "файл журнала: %w",
+		"logs.err.no_dir":                  "папка данных недоступна",
+		"err.home":                         "не удалось получить домашнюю папку: %w",
+		"err.data_dir":                     "не удалось создать папку для данных: %w",
+		"db.err.load":                      "загрузка из БД: %w",
+		"db.err.migrate":                   "миграция схемы: %w",
+		"report.err.no_data_range":         "нет данных за период: %s",
+		"report.err.no_data":               "нет данных для отчета",
+		"err.collect":                      "сбор данных %s: %w",
+		"db.err.stats":                     "статистика БД: %w",
+		"err.history_data":                 "получение исторических данных: %w",
+		"err.status":                       "получение статуса: %w",
+		"report.err.print":                 "вывод отчёта: %w",
+		"report.err.generate":              "генерация данных отчета: %w",
+		"export.err.md_path":               "не удалось определить путь для Markdown файла: %w",
+		"export.err.md":                    "экспорт в Markdown: %w",
+		"export.err.html_path":             "не удалось определить путь для HTML файла: %w",
+		"export.err.html":                  "экспорт в HTML: %w",
+		"err.db_connect_failed":            "ошибка подключения к БД: %w",
+		"err.generate_failed":              "ошибка генерации данных: %w",
+		"replay.err.no_clear":              "в режиме воспроизведения записи очистка недоступна",
+		"db.err.reinit":                    "не удалось переинициализировать БД: %v",
+		"store.err.transaction":            "транзакция записи: %w",
+		"store.err.prepare":                "подготовка вставки измерений: %w",
+		"store.err.save":                   "сохранение измерения %s: %w",
+		"store.err.batch":                  "запись пакета измерений: %w",
+		"store.err.cleanup":                "очистка старых данных: %w",
+		"store.err.count":                  "подсчет записей: %w",
+		"migrate.err.table":                "создание таблицы версий схемы: %w",
+		"migrate.err.version":              "чтение версии схемы: %w",
+		"migrate.err.versions":             "чтение версий схемы: %w",
+		"migrate.err.newer":                "схема БД версии %d новее поддерживаемой (%d) - обновите batmon",
+		"migrate.err.unknown":              "неизвестная версия схемы %d (доступны 0-%d)",
+		"migrate.err.step":                 "миграция %d (%s): %w",
+		"migrate.err.record":               "миграция %d (%s): запись версии: %w",
+		"net.err.disabled":                 "сетевой контекст отключен до перезапуска: %w",
+		"net.err.unsupported":              "счетчики сети на %s не поддерживаются",
+		"net.err.save":                     "сохранение сетевого контекста: %w",
+		"net.err.read":                     "чтение сетевого контекста: %w",
+		"note.err.save":                    "сохранение заметки: %w",
+		"note.err.read":                    "чтение заметок: %w",
+		"note.err.no_session":              "сессия %d не найдена (номера – на вкладке «Сессии» отчета)",
+		"note.err.delete":                  "удаление заметки: %w",
+		"note.err.not_found":               "заметка %d не найдена",
+		"plist.err.no_value":               "plist: нет значения",
+		"power_events.err.pmset":           "журнал pmset: %w",
+		"power_events.err.read":            "чтение событий питания: %w",
+		"power_events.err.transaction":     "транзакция событий питания: %w",
+		"power_events.err.save":            "сохранение события питания: %w",
+		"powermetrics.err.no_power":        "powermetrics: нет данных о мощности",
+		"powermetrics.err.save":            "сохранение выборки powermetrics: %w",
+		"powermetrics.err.read":            "чтение выборки powermetrics: %w",
+		"err.open":                         "открытие %s: %w",
+		"replay.err.measurements":          "чтение измерений из %s: %w",
+		"replay.err.no_measurements":       "в %s нет измерений",
+		"err.tui":                          "запуск интерфейса: %w",
+		"range.err.parse":                  "не удалось разобрать время %q (примеры: 7d, 24h, 14:00, 2025-01-31, \"2025-01-31 18:00\")",
+		"range.err.order":                  "начало периода должно быть раньше конца",
+		"rollup.err.hourly":                "свертка измерений по часам: %w",
+		"rollup.err.monthly":               "ёмкость по месяцам: %w",
+		"rule.err.empty":                   "правило %q: пустое условие",
+		"rule.err.parse":                   "правило %q: не разобрано условие %q (нужно «метрика оператор число»)",
+		"rule.err.metric":                  "правило %q: неизвестная метрика %q (доступны: %s)",
+		"rule.err.number":                  "правило %q: неверное число %q",
+		"rule.err.template":                "правило %q: шаблон сообщения: %w",
+		"rule.err.invalid":                 "правил с ошибками: %d – они не применяются",
+		"sampling.err.policy":              "неизвестная политика опроса %q, используется %s",
+		"schema.err.tables":                "чтение списка таблиц: %w",
+		"schema.err.columns":               "чтение столбцов %s: %w",
+		"schema.err.indexes":               "чтение индексов %s: %w",
+		"schema.err.index":                 "чтение индекса %s: %w",
+		"serve.err.addr":                   "адрес %q: %w",
+		"serve.err.loopback":               "адрес %q: разрешены только 127.0.0.1, ::1 и localhost",
+		"serve.err.unversioned":            "адреса без версии доступны только с этой машины по 127.0.0.1 или localhost – используйте /api/v1 с токеном",
+		"serve.err.no_measurements":        "нет измерений",
+		"serve.err.limit":                  "limit должен быть от 1 до %d",
+		"serve.err.streaming":              "потоковая передача не поддерживается",
+		"serve.err.remote":                 "%w; чтобы слушать сеть, добавьте --remote",
+		"sessions.err.measurements":        "получение измерений для сессий: %w",
+		"sessions.err.transaction":         "транзакция сессий: %w",
+		"sessions.err.delete_open":         "удаление открытой сессии: %w",
+		"sessions.err.save":                "сохранение сессии: %w",
+		"db.err.clear":                     "ошибка очистки БД: %v",
+		"shutdown.err.timeout":             "не завершилось за %v: %w",
+		"snapshot.err.rate":                "скорость разрядки: %w",
+		"snapshot.err.save":                "сохранение снимка: %w",
+		"snapshot.err.not_found_hint":      "снимок %q не найден (список: batmon snapshot list)",
+		"snapshot.err.read":                "чтение снимка: %w",
+		"snapshot.err.list":                "чтение снимков: %w",
+		"snapshot.err.delete":              "удаление снимка: %w",
+		"snapshot.err.not_found":           "снимок %q не найден",
+		"standby.err.measurements":         "чтение измерений для анализа сна: %w",
+		"store.err.checkpoint":             "контрольная точка WAL: %w",
+		"store.err.close":                  "закрытие БД: %w",
+		"sync.err.read_lock":               "чтение блокировки: %w",
+		"sync.err.in_use":                  "база используется на %s (обновлено %s)",
+		"sync.err.lock":                    "блокировка базы: %w",
+		"theme.err.unknown":                "неизвестная тема %q (доступны: %s)",
+		"theme.err.role":                   "неизвестная роль цвета %q в теме",
+		"thermal.err.read":                 "чтение температуры: %w",
+		"thermal_events.err.read":          "чтение температурных событий: %w",
+		"thermal_events.err.measurements":  "получение измерений для температурных событий: %w",
+		"thermal_events.err.transaction":   "транзакция температурных событий: %w",
+		"thermal_events.err.delete_open":   "удаление открытого температурного события: %w",
+		"thermal_events.err.save":          "сохранение температурного события: %w",
+		"throttling.err.save":              "сохранение теплового давления: %w",
+		"throttling.err.read":              "чтение теплового давления: %w",
+		"wear_events.err.read":             "чтение вех износа: %w",
+		"wear_events.err.measurements":     "получение измерений для вех износа: %w",
+		"wear_events.err.save":             "сохранение вехи износа: %w",
+		"webhook.err.template":             "шаблон вебхука: %w",
+		"webhook.err.not_json":             "шаблон вебхука дает не JSON: %s",
+		"err.webhook":                      "вебхук %s: %w",
+		"webhook.err.status":               "вебхук %s: %s",
+		"alert_level.err.read":             "чтение уровня оповещения: %w",
+		"alert_level.err.save":             "сохранение уровня оповещения: %w",
+		"queue.err.save":                   "сохранение в БД: %w",
+
+		// ошибки-метки
+		"cli.err.usage":          "неверные аргументы",
+		"cli.err.help_shown":     "справка выведена",
+		"network.err.off":        "сетевая функция отключена",
+		"iokit.err.unavailable":  "IOKit недоступен в этой сборке",
+		"shutdown.err.timed_out": "превышено время ожидания",
+
+		// расход по приложениям
+		"apps.period.week":  "за неделю",
+		"apps.period.range": "за период",
+		"apps.top_item":     "%s (%.0f мАч)",
+		"apps.title":        "🔌 Расход батареи по приложениям: %s – %s",
+		"apps.empty":        "Нет выборок приложений за этот период (они снимаются при работе от батареи раз в 5 минут)",
+		"apps.col.app":      "Приложение",
+		"apps.col.wh":       "Вт·ч",
+
+		// разбаланс ячеек
+		"anomaly.cell_imbalance": "Разбаланс ячеек: разброс до %d мВ (%s мВ), %d измерений (%s–%s)",
+
+		// адаптеры питания
+		"charger.default_name": "адаптер",
+		"charger.watts":        "%s %d Вт",
+		"charger.third_party":  " (неоригинальный)",
+
+		// сетевые функции
+		"network.feature.upload":       "выгрузка",
+		"network.feature.mqtt":         "MQTT",
+		"network.feature.webhooks":     "вебхуки",
+		"network.feature.update_check": "проверка обновлений",
+		"network.feature.email":        "почта",
+		"network.status.offline":       "сеть: полностью офлайн",
+		"network.status.allowed":       "сеть: разрешено – %s",
+
+		// производные метрики
+		"metrics.none":    "Производные метрики не заданы. Добавьте в %s, например:",
+		"metrics.example": "  \"metrics\": [{\"name\": \"watts\", \"expr\": \"voltage*amperage/1e6\", \"unit\": \"Вт\"}]",
+		"metrics.fields":  "Поля: %s",
+		"metrics.no_data": "нет данных",
+
+		// отчет по почте
+		"email.sent":                 "✅ Отчет отправлен: %s",
+		"email.schedule.off":         "📭 Отчет по расписанию выключен",
+		"email.schedule.weekly":      "раз в неделю",
+		"email.schedule.daily":       "каждый день",
+		"email.schedule.on":          "📬 Отчет %s на %s через %s",
+		"email.schedule.network_off": "⚠️ Отправка выключена: включите network.%s в %s",
+
+		// сетевой контекст
+		"net_context.traffic":   "совпало с обменом по сети %s/с",
+		"net_context.bluetooth": "устройств Bluetooth: %d",
+
+		// запрет засыпания
+		"platform.inhibit_why": "Измерение батареи",
+
+		// воспроизведение
+		"replay.title": "⏯️ BatMon - воспроизведение %s (x%g)",
+
+		// пресеты периода
+		"range.preset.last": "последние измерения",
+		"range.preset.24h":  "24 часа",
+		"range.preset.7d":   "7 дней",
+		"range.preset.30d":  "30 дней",
+		"range.preset.all":  "всё время",
+
+		// HTTP API
+		"serve.token_created": "🔑 Создан токен API (%s): %s",
+
+		// сессии
+		"session.kind.charge":    "🔌 Зарядка",
+		"session.kind.discharge": "🔋 Разрядка",
+
+		// оповещения о состоянии
+		"webhook.health_alert": "batmon: состояние батареи",
+	},
+	localeEN: {
+		"lang": "en",
+
+		"cli.usage":              "Usage: batmon [--db path] [--verbose] [command] [flags]",
+		"cli.no_command":         "Without a command the interactive interface starts.",
+		"cli.commands":           "Commands:",
+		"cmd.check":              "health check with exit code 0/1/2 (for MDM and CI)",
+		"cmd.status":             "one-shot battery status for scripts and widgets",
+		"cmd.collect":            "background data collection without the UI",
+		"cmd.report":             "text report in the terminal or a report by email",
+		"cmd.export":             "export reports, formats can be combined",
+		"cmd.chart":              "history chart as PNG or SVG",
+		"cmd.snapshot":           "health snapshots and then-vs-now comparison",
+		"cmd.db":                 "database maintenance",
+		"cmd.fleet":              "battery overview of several MacBooks from status --json snapshots",
+		"cmd.serve":              "HTTP API with battery data (/api/v1 with a token)",
+		"cmd.diag":               "data source diagnostics and current status",
+		"cmd.doctor":             "collector health: tools, last sample, database, disk, caffeinate",
+		"cmd.apps":               "which apps drained the battery in a period",
+		"cmd.metrics":            "check derived metrics from config.json",
+		"cmd.bench":              "time the analysis pipeline on synthetic history",
+		"cmd.calibration":        "full battery test 100% → 0%",
+		"cmd.tmux-status":        "tmux status bar line",
+		"cmd.replay":             "replay a recorded session in the dashboard",
+		"cmd.verify-certificate": "verify a certificate code",
+		"cmd.schema":             "DB schema and export JSON Schema for compatibility checks",
+		"cmd.rules":              "effective recommendation rules (built-in and from config.json)",
+		"cmd.note":               "notes for a moment, time range or session",
+		"cmd.version":            "program version",
+		"cmd.help":               "detailed help",
+
+		"menu.title":           "🔋 BatMon - MacBook Battery Monitor",
+		"menu.full":            "🔋 Full battery test (100% → 0%)",
+		"menu.full.desc":       "Start at 100% and discharge to 0% for a complete diagnosis",
+		"menu.quick":           "⚡ Quick check",
+		"menu.quick.desc":      "Check the current battery state and show recommendations",
+		"menu.report":          "📊 Detailed report",
+		"menu.report.desc":     "Analysis of all stored data with charts and forecasts",
+		"menu.export":          "📄 Export reports",
+		"menu.export.desc":     "Save results as Markdown or HTML with charts",
+		"menu.settings":        "⚙️  Settings",
+		"menu.settings.desc":   "Polling interval, retention, thresholds, language, clearing data",
+		"menu.logs":            "🧾 Logs",
+		"menu.logs.desc":       "Collector messages, warnings and errors from batmon.log",
+		"logs.title":           "🧾 BatMon log",
+		"logs.filter":          "%s · level %s and above",
+		"logs.empty":           "No entries at this level",
+		"logs.error":           "Log unavailable: %v",
+		"logs.help":            "↑/↓ PgUp/PgDn – scroll · l – level · r – reload · q – back",
+		"doctor.title":         "🩺 Collector health",
+		"doctor.running":       "Checking…",
+		"doctor.unavailable":   "Checks unavailable: data service is not running",
+		"doctor.checked":       "checked at %s",
+		"doctor.help":          "r – check again · q – back",
+		"menu.help":            "❓ Help",
+		"menu.help.desc":       "How to use the program to analyse your battery",
+		"menu.quit":            "❌ Quit",
+		"menu.quit.desc":       "Exit the program",
+		"tab.overview":         "Overview",
+		"tab.charts":           "Charts",
+		"tab.anomalies":        "Anomalies",
+		"tab.history":          "History",
+		"tab.forecast":         "Forecast",
+		"tab.sessions":         "Sessions",
+		"tab.charging":         "Charging",
+		"tab.metrics":          "Metrics",
+		"help.title":           "🔋 BatMon Help",
+		"help.purpose":         "🎯 MAIN GOAL",
+		"help.purpose.text":    "Find out whether the MacBook battery needs replacing",
+		"help.howto":           "🚀 HOW TO USE",
+		"help.howto.1":         "1. Charge to 100%",
+		"help.howto.2":         "2. Choose '🔋 Full battery test' and press Enter",
+		"help.howto.3":         "3. Discharge to 5% – the test finishes by itself",
+		"help.howto.4":         "4. Press e – the report compares runtime with the macOS estimate",
+		"help.modes":           "📋 MODES",
+		"help.modes.quick":     "⚡ Quick check - instant check",
+		"help.modes.full":      "🔋 Full test - the main test (100%→0%)",
+		"help.modes.report":    "📊 Detailed report - charts and trends",
+		"help.criteria":        "🔍 HEALTH CRITERIA",
+		"help.criteria.good":   "✅ Good: ",
+		"help.criteria.good.v": "wear <20%, cycles <1000",
+		"help.criteria.warn":   "⚠️  Attention: ",
+		"help.criteria.warn.v": "wear 20-30%, cycles 1000+",
+		"help.criteria.bad":    "🔴 Replace: ",
+		"help.criteria.bad.v":  "wear >30%, cycles >1500",
+		"help.tips":            "💡 TIPS",
+		"help.tips.1":          "• At least 2-3 hours for an accurate analysis",
+		"help.tips.2":          "• Do not close the program during the test",
+		"help.tips.3":          "• During calibration the MacBook does not idle-sleep (mode – key c on the dashboard)",
+		"help.tips.4":          "• Keep reports to track changes",
+		"help.tips.5":          "• Ctrl+T switches the color theme (dark, light, high contrast)",
+		"help.back":            "Press 'q' to return to the main menu",
+
+		"unit.mah":                     "mAh",
+		"unit.wh":                      "Wh",
+		"unit.watt":                    "W",
+		"unit.mah_per_hour":            "mAh/h",
+		"fmt.hours_minutes":            "%d h %d min",
+		"fmt.minutes":                  "%d min",
+		"report.title":                 "🔋 MacBook Battery Health Report",
+		"report.created":               "Generated",
+		"report.period":                "Period",
+		"report.summary":               "💼 Summary",
+		"report.health":                "Battery health",
+		"report.rating":                "%s (score %d/100, model: %s)",
+		"report.cycles":                "Cycles",
+		"report.wear":                  "Wear",
+		"report.remaining":             "Time remaining",
+		"report.baseline":              "Full charge capacity %s",
+		"report.baseline.detail":       "(%d mAh on %s)",
+		"report.replacement":           "(serial %s → %s); trends use the new battery only",
+		"report.current":               "🔋 Current Battery State",
+		"report.current.short":         "🔋 Current State",
+		"report.param":                 "Parameter",
+		"report.value":                 "Value",
+		"report.measured_at":           "Measured at",
+		"report.charge":                "Charge",
+		"report.state":                 "State",
+		"report.charge_cycles":         "Charge cycles",
+		"report.full_cap":              "Full charge capacity",
+		"report.design_cap":            "Design capacity",
+		"report.current_cap":           "Current capacity",
+		"report.temperature":           "Temperature",
+		"report.health_analysis":       "📊 Battery Health Analysis",
+		"report.overall":               "Overall condition",
+		"report.overall.value":         "%s (score: %d/100)",
+		"report.wear_battery":          "Battery wear",
+		"report.trend":                 "Degradation trend",
+		"report.trend.value":           "%.2f%% per month",
+		"report.projection":            "Projected time to 80% capacity",
+		"report.projection.value":      "~%d days",
+		"report.charts":                "📊 Charts",
+		"report.anomalies":             "⚠️ Detected anomalies (%d)",
+		"report.anomalies.more":        "... and %d more anomalies",
+		"report.recommendations":       "💡 Recommendations",
+		"report.top_apps":              "🔌 Top energy consumers %s",
+		"report.app":                   "App",
+		"report.drain_mah":             "Drain, mAh",
+		"report.drain_wh":              "Drain, Wh",
+		"report.energy_impact":         "Energy Impact (avg/max)",
+		"report.history":               "📈 All-Time Observations",
+		"report.monthly":               "🗓️ Capacity by Month",
+		"report.wear_timeline":         "📉 Degradation Timeline",
+		"report.wear_timeline.note":    "When wear crossed each whole percent; the bar shows weeks per percent: the shorter, the faster the degradation.",
+		"report.wear_pace":             "Per percent",
+		"report.date":                  "Date",
+		"report.month":                 "Month",
+		"report.hot":                   "🔥 Hot Charging by Week",
+		"report.hot.note":              "Minutes of charging above the temperature threshold (lower above 80% charge) – a wear risk indicator.",
+		"report.full_zone":             "🔝 Time at 100%",
+		"report.compare":               "🆚 Comparison with snapshot “%s” from %s",
+		"report.compare.replaced":      "The snapshot was taken on a different battery (replacement)",
+		"report.compare.then":          "Then",
+		"report.compare.now":           "Now",
+		"report.compare.delta":         "Change",
+		"report.avg_drain":             "Average drain",
+		"report.runtime":               "Runtime from full charge",
+		"report.score":                 "Health score",
+		"unit.pct_per_hour":            "%/h",
+		"report.model":                 "💻 Comparison with the model: %s",
+		"report.sleep":                 "😴 Drain during sleep: %d%% over %s (%.1f%%/h, sleep periods: %d)",
+		"report.sleep_wakes":           "⏰ Wakes During Sleep",
+		"report.notes":                 "✎ Notes",
+		"report.throttling":            "🔥 Throttling: CPU or Battery",
+		"report.wake_reasons":          "Most frequent wake reasons:",
+		"report.standby":               "🌙 Standby Drain by Week",
+		"report.charging_curve":        "⚡ Charging",
+		"report.calibration":           "🎯 Calibration: full discharges 100% → <10%",
+		"settings.title":               "⚙️  Settings",
+		"settings.interval":            "Battery polling interval",
+		"settings.retention":           "Keep measurements",
+		"settings.days":                "%d days",
+		"settings.low_battery":         "Low-power mode below",
+		"settings.wear_warning":        "Wear: warning",
+		"settings.wear_critical":       "Wear: critical",
+		"settings.cycles_warning":      "Cycles: warning",
+		"settings.cycles_critical":     "Cycles: critical",
+		"settings.language":            "Interface language",
+		"settings.language.auto":       "auto (%s)",
+		"settings.sampling":            "Polling rate",
+		"sampling.adaptive":            "adaptive",
+		"sampling.fixed":               "fixed",
+		"settings.caffeinate":          "Sleep prevention (caffeinate)",
+		"settings.net_context":         "Network and Bluetooth activity in samples",
+		"settings.score_model":         "Health score model",
+		"health_model.heuristic":       "batmon heuristic",
+		"health_model.apple_condition": "Apple condition",
+		"health_model.capacity":        "capacity only",
+		"health_model.cycles":          "cycle-weighted",
+		"caffeinate.off":               "off",
+		"caffeinate.calibration":       "calibration only",
+		"caffeinate.always":            "always",
+		"caffeinate.status":            "☕ Sleep prevention: %s – %s",
+		"caffeinate.active":            "active",
+		"caffeinate.inactive":          "inactive",
+		"caffeinate.assertions":        "🔒 System sleep assertions: %d",
+		"caffeinate.assertions.owners": "🔒 Sleep prevented by: %s",
+		"caffeinate.assertions.none":   "💤 No system sleep assertions",
+		"live.sample":                  "sample at %s",
+		"live.next":                    "next in %d:%02d",
+		"live.collecting":              "collecting…",
+		"live.stalled":                 "⚠️ data collection is failing · 'd' – collector health",
+		"settings.off":                 "off",
+		"settings.on":                  "on",
+		"settings.clear":               "🗑️  Clear data…",
+		"settings.saved":               "Saved: %s – %s",
+		"settings.cleared":             "Database cleared",
+		"settings.help":                "↑/↓ select • ←/→ change • Enter on clear – confirm • q back",
+		"report.charging.summary":      "Charging sessions: %d; average 20→80%%: %s, 80→100%%: %s",
+		"report.charging.watts_avg":    "power %.0f W",
+		"report.charging.start":        "Start",
+		"report.charging.range":        "Charge",
+		"report.charging.fast":         "20→80%",
+		"report.charging.trickle":      "80→100%",
+		"report.charging.watts":        "W",
+		"report.charging.notes":        "Notes",
+		"report.charging.slow":         "slow charging",
+		"report.charging.long_trickle": "long trickle",
+		"report.standby.summary":       "On battery the Mac loses %.1f%%/h on average while asleep (over %s of sleep); Apple's expectation is about %.0f%%/h",
+		"report.standby.trend":         "%s%%/h over recent weeks",
+		"report.standby.hours":         "Hours asleep",
+		"report.standby.rate":          "%/h",
+		"report.model.wear":            "%s at %d cycles typically shows %.0f%% wear; yours shows %.0f%%",
+		"report.model.cycles":          "Remaining cycle life: %.0f%% of %d",
+		"report.model.runtime":         "Rated runtime %s, measured from full charge – %s",
+		"report.model.design":          "Battery design capacity is %d mAh, the model's is %d mAh: the battery may be non-original",
+		"report.full_zone.note":        "How long the battery sat at 100% on AC power during the report period (share of observed time). Staying fully charged accelerates wear.",
+		"report.week":                  "Week",
+		"report.minutes":               "Minutes",
+		"report.chargers":              "🔌 Power Adapters",
+		"report.adapter":               "Adapter",
+		"report.power":                 "Power",
+		"report.last_connected":        "Last connected",
+		"report.charge_rate":           "Charging to 80%, %/h",
+		"report.watts":                 "%d W",
+		"report.brightness":            "🔆 Drain by Screen Brightness",
+		"report.mode":                  "Mode",
+		"report.drain_rate":            "Drain",
+		"report.battery_hours":         "Hours on battery",
+		"report.derived":               "📐 Derived Metrics",
+		"report.metric":                "Metric",
+		"report.expr":                  "Expression",
+		"report.latest":                "Latest",
+		"report.min":                   "Min",
+		"report.avg":                   "Avg",
+		"report.max":                   "Max",
+		"report.daily":                 "📅 Daily Usage",
+		"report.heatmap":               "🗓️ Usage Heatmap",
+		"report.heatmap.note":          "Rows are days, columns are hours. The brighter the cell, the faster the battery drained in that hour; hover a cell to see minutes on battery and the drain rate.",
+		"report.heatmap.cell":          "%s %02d:00 – %.0f min on battery",
+		"report.heatmap.rate":          ", %.1f%%/h",
+		"weekday.0":                    "Sun",
+		"weekday.1":                    "Mon",
+		"weekday.2":                    "Tue",
+		"weekday.3":                    "Wed",
+		"weekday.4":                    "Thu",
+		"weekday.5":                    "Fri",
+		"weekday.6":                    "Sat",
+		"report.total":                 "Total",
+		"report.total.value":           "on battery %s, charging %s, %.1f full charges used",
+		"report.day":                   "Day",
+		"report.on_battery":            "On battery",
+		"report.charging":              "Charging",
+		"report.on_ac":                 "On AC",
+		"report.screen":                "Screen",
+		"report.drain":                 "Charge used",
+		"report.full_charges":          "Full charges",
+		"report.sessions":              "Sessions",
+		"report.battery_hours_bar":     "Battery hours",
+		"report.drain_stats":           "📈 Discharge Statistics",
+		"report.simple_rate":           "Simple discharge rate",
+		"report.robust_rate":           "Robust discharge rate",
+		"report.discharge_power":       "Average discharge power",
+		"report.power.value":           "%.1f W (energy-based, %d intervals)",
+		"report.rate.value":            "%.2f mAh/h",
+		"report.robust.value":          "%.2f mAh/h (from %d valid intervals)",
+		"report.remaining_work":        "Estimated runtime left",
+		"report.recent":                "📋 Recent Measurements",
+		"report.time":                  "Time",
+		"report.cycle":                 "Cycle",
+		"report.full_short":            "Full cap.",
+		"report.design_short":          "Design cap.",
+		"report.current_short":         "Current cap.",
+		"report.temp_short":            "Temp.",
+		"report.footer":                "Report generated by batmon v2.0",
+
+		"report.no_data":              "No records for the report.",
+		"report.period.samples":       "📅 Period: %s (%d measurements)",
+		"report.overall.model":        "%s (score: %d/100, model: %s)",
+		"report.cli.summary":          "💼 === SUMMARY ===",
+		"report.cli.current":          "=== Current Battery State ===",
+		"report.cli.health":           "=== Battery Health Analysis ===",
+		"report.cli.drain":            "=== Discharge Statistics ===",
+		"report.cli.recent":           "=== Recent Measurements (oldest first) ===",
+		"report.cli.daily":            "📅 Over %d days: on battery %s, charging %s, %.1f full charges used",
+		"report.cli.monthly":          "🗓️ Full charge capacity: %s – %.0f mAh, %s – %.0f mAh (%d months of history)",
+		"report.cli.adapter":          "🔌 Last adapter: %s (%s)",
+		"report.cli.calibration_last": "🎯 Last full discharge: %s",
+		"report.cli.replacement":      "%s (serial %s → %s)",
+		"report.cli.cycles":           "🔄 Cycle count: %d",
+		"report.cli.hot_week":         "🔥 Hot charging this week: %.0f min",
+		"report.cli.full_zone":        "🔝 At 100%% on AC: %s (%.0f%% of the time)",
+		"report.cli.anomalies":        "\n⚠️  Anomalies in recent measurements: %d",
+		"report.cli.anomalies.more":   "... and %d more",
+		"report.cli.not_enough":       "not enough data",
+		"report.cli.unknown":          "unknown",
+		"report.cli.full_short":       "FCC",
+		"report.cli.design_short":     "DC",
+		"report.cli.current_short":    "CC",
+		"report.cli.temp_short":       "Temp",
+
+		"report.js.charge":         "Charge (%)",
+		"report.js.charge_title":   "Battery charge (%)",
+		"report.js.capacity":       "Capacity (mAh)",
+		"report.js.capacity_title": "Current capacity (mAh)",
+		"report.js.on_battery":     "On battery, h",
+		"report.js.charging":       "Charging, h",
+		"report.js.hours":          "Hours",
+		"report.js.daily":          "Daily usage",
+
+		"state.unknown":     "Unknown",
+		"state.almost_full": " (almost full)",
+		"state.low":         " (low charge)",
+
+		"health.excellent":        "Excellent",
+		"health.good":             "Good",
+		"health.fair":             "Fair",
+		"health.attention":        "Needs attention",
+		"health.poor":             "Poor",
+		"health.unstable":         " (unstable operation)",
+		"health.fast_degradation": " (rapid degradation)",
+		"score.wear":              "Wear",
+		"score.cycles":            "Cycles",
+		"score.cycles.value":      "%d of %d",
+		"score.anomalies":         "Anomalies",
+		"score.degradation":       "Capacity degradation",
+		"score.degradation.value": "%.1f%%/mo",
+		"score.apple_condition":   "Apple condition",
+		"score.no_data":           "no data",
+
+		"range.last_n": "last %d measurements",
+		"range.all":    "all time",
+		"range.since":  "since %s",
+		"range.until":  "until %s",
+
+		"baseline.summary":    "since batmon was installed: %s mAh (%s%%)",
+		"replacement.marker":  "🔁 Battery replaced %s",
+		"history.observed":    "Observed since %s: %d measurements, %d anomalies",
+		"history.discharge":   "Discharge rate: %.0f ± %.0f mAh/h on average, recent intervals – %.0f mAh/h",
+		"history.temperature": "Temperature: %.1f°C on average, %.0f°C max",
+		"history.trend":       "Full charge capacity trend: %s%% of design per month",
+
+		"rec.hot_charging":          "%s of hot charging this week - charge on a hard surface and avoid heavy load while charging at a high level",
+		"rec.standby":               "The battery loses %.1f%%/h while asleep – more than Apple's expectation (about %.0f%%/h): check `pmset -g assertions`, Power Nap and network wakes (`pmset -g log | grep Wake`)",
+		"rec.full_charge":           "The battery sits at 100%% on AC %.0f%% of the time - turn on optimized charging or limit the charge to %d%%",
+		"rec.charger.weak":          "Low-power adapter %s – under load the battery may drain even while charging, use an adapter of %d W or more",
+		"rec.charger.slow":          "Slow charging with adapter %s: %.0f%%/h up to %d%% – check the cable and adapter power",
+		"rec.charger.unofficial":    "Non-original adapter %s in use – if it overheats or charging is unstable, replace it with a certified one",
+		"rec.charging.slow":         "Charging from %d to %d%% takes longer than %s in %d of %d sessions – check the adapter power and cable",
+		"rec.charging.long_trickle": "Trickle charging from %d to 100%% takes longer than %s in %d of %d sessions – unless this is optimized charging, the battery accepts charge poorly",
+
+		"rule.replace":               "Consider replacing the battery",
+		"rule.power_settings":        "Check your energy saver settings",
+		"rule.end_of_life":           "The battery is nearing the end of its life cycle",
+		"rule.high_drain_brightness": `High power draw at {{printf "%.0f" .Brightness}}% screen brightness - lower the brightness`,
+		"rule.high_drain":            "High power draw - close resource-heavy apps",
+		"rec.high_drain_apps":        "High power draw - the apps that used the most battery this week: %s",
+		"rule.degradation":           `Rapid battery degradation ({{printf "%.2f" .Degradation}}% per month) - check the operating conditions`,
+		"rule.full_charge":           "Do not keep the battery at 100% all the time",
+		"rule.calibration":           "Consider calibrating the battery (full discharge and charge)",
+		"thermal.alarm.charging":     "High temperature while charging (%d°C at %d%%) - unplug the charger or reduce the load, heat at a high charge level speeds up wear",
+		"thermal.alarm":              "High battery temperature (%d°C) - avoid heavy load",
+		"thermal.warning.charging":   "The battery is warming up while charging (%d°C at %d%%) - improve cooling and limit the charge to %d%% if possible",
+		"thermal.warning":            "Elevated battery temperature - consider improving cooling",
+		"calibration.reminder.never": "No full discharge 100%% → <%d%% in %d days of observation – run a calibration test so the controller can refine the capacity",
+		"calibration.reminder.last":  "The last full discharge was %d days ago (%s) – time for a calibration test",
+
+		"flag.db":                        "path to the database",
+		"flag.from":                      "period start: 7d, 24h, 14:00, 2025-01-31 or \"2025-01-31 18:00\"",
+		"flag.to":                        "period end in the same format (a date without time means the end of the day)",
+		"flag.apps.top":                  "how many apps to show",
+		"flag.bench.n":                   "number of synthetic measurements",
+		"flag.bench.runs":                "runs of each stage",
+		"flag.bench.cpuprofile":          "write a CPU profile to the file",
+		"flag.bench.memprofile":          "write a memory profile to the file",
+		"flag.calibration.md":            "report: Markdown report file",
+		"flag.chart.metric":              "comma-separated metrics: ",
+		"flag.chart.out":                 "chart file: .png or .svg",
+		"flag.chart.width":               "width, pixels",
+		"flag.chart.height":              "height of one chart, pixels",
+		"flag.check.wear-warn":           "wear for a warning, %",
+		"flag.check.wear-crit":           "critical wear, %",
+		"flag.check.cycles-warn":         "cycles for a warning",
+		"flag.check.cycles-crit":         "critical cycle count",
+		"flag.check.anomalies-warn":      "anomalies for a warning",
+		"flag.check.anomalies-crit":      "critical anomaly count",
+		"flag.collect.interval":          "polling interval (adaptive by default)",
+		"flag.collect.powermetrics":      "detailed mode: CPU/GPU/ANE power (needs root or passwordless sudo)",
+		"flag.collect.once":              "take one measurement, store it and exit (for cron/launchd)",
+		"flag.report.compare":            "compare with a saved snapshot (batmon snapshot save)",
+		"flag.report.schedule.daily":     "send a daily report every day",
+		"flag.report.schedule.weekly":    "send a weekly report once a week",
+		"flag.report.schedule.off":       "turn the schedule off",
+		"flag.report.schedule.send-now":  "send a report now without waiting for the schedule",
+		"flag.report.schedule.email":     "comma-separated recipients",
+		"flag.report.schedule.smtp":      "SMTP server host:port",
+		"flag.report.schedule.smtp-user": "SMTP login (password in %s)",
+		"flag.report.schedule.from-addr": "sender address",
+		"flag.export.md":                 "Markdown report file",
+		"flag.export.html":               "HTML report file",
+		"flag.export.certificate":        "battery health certificate file",
+		"flag.export.quiet":              "do not print export progress",
+		"flag.export.compare":            "add a comparison with a saved snapshot",
+		"flag.db.days":                   "cleanup: keep data for the last N days",
+		"flag.db.to":                     "migrate: schema version (latest by default, lower rolls back)",
+		"flag.replay.speed":              "replay speed-up",
+		"flag.fleet.import.host":         "machine name for snapshots without hostname (file name by default)",
+		"flag.fleet.report.sort":         "order: wear, cycles, health or host",
+		"flag.fleet.report.md":           "save the table as Markdown",
+		"flag.note.add.at":               "moment: 14:00, 2025-01-31 or \"2025-01-31 18:00\" (now by default)",
+		"flag.note.add.session":          "discharge or charging session number",
+		"flag.note.list.json":            "print notes as JSON",
+		"flag.rules.json":                "print rules as JSON",
+		"flag.schema.json":               "print the description as JSON",
+		"flag.serve.addr":                "address to listen on",
+		"flag.serve.remote":              "allow a non-loopback address (token required for all requests)",
+		"flag.serve.rotate":              "token: issue a new token",
+		"flag.status.json":               "print JSON",
+
+		// команды
+		"cli.unknown_command":        "Unknown command: %s",
+		"cli.collect.started":        "🔄 Collecting data into %s, Ctrl+C to stop",
+		"cli.collect.failed":         "⚠️ Data collection failed: %v",
+		"cli.export.no_format":       "❌ Specify at least one format: --md, --html or --certificate",
+		"cli.db.sync_lock":           "🔒 Writer: %s (updated %s)",
+		"cli.db.cleaned":             "✅ Deleted data older than %d days",
+		"cli.db.backup":              "✅ Backup: %s",
+		"cli.db.restore.usage":       "❌ Specify a backup file: batmon db restore <path>",
+		"cli.db.restored":            "✅ Database restored from %s",
+		"cli.db.previous":            "💾 The previous database was saved to %s",
+		"cli.db.import.usage":        "❌ Specify a database file: batmon db import <other.sqlite>",
+		"cli.db.import.source":       "📂 The source has %d measurements: %s – %s",
+		"cli.db.imported":            "✅ Added %d measurements, skipped with matching timestamps: %d",
+		"cli.db.optimized":           "✅ Indexes checked, statistics updated in %s",
+		"cli.db.migrated":            "✅ Schema is now at version %d",
+		"cli.db.migrate_again":       "⚠️ batmon will apply the migrations up to version %d again on the next start",
+		"cli.db.schema_version":      "📐 Schema version: %d of %d",
+		"cli.db.unknown_action":      "❌ Unknown db action: %s (path, stats, cleanup, backup, restore, import, optimize, version, migrate)",
+		"cli.diag.cycles":            "🔄 Cycles: %d",
+		"cli.diag.capacity":          "⚡ Capacity: %d / %s (design %s)",
+		"cli.diag.electrical":        "🌡️ Temperature: %d°C, voltage %d mV, current %d mA",
+		"cli.diag.condition":         "🍎 Condition: %s",
+		"cli.diag.cells":             "🔋 Cells: %v mV",
+		"cli.diag.permanent_failure": "⛔ The controller reports a permanent battery failure (PermanentFailureStatus=%#x)",
+		"cli.tmux.usage":             "❌ The cache interval is given in seconds",
+		"cli.replay.usage":           "❌ Specify a recording: batmon replay [--speed 60] <file.sqlite|file.json>",
+		"cli.replay.speed_number":    "❌ The replay speed must be a number",
+		"cli.replay.speed_positive":  "❌ The replay speed must be positive",
+		"cli.verify.usage":           "❌ Specify the verification code from the certificate",
+
+		// подсказки по аргументам команд и глобальные флаги
+		"cmd.report.args":             "[--from 7d] [--to date] [--compare snapshot] | schedule [--weekly] [--email address] [--smtp host:port]",
+		"cmd.snapshot.args":           "[save|list|compare|delete] <name>",
+		"cmd.export.args":             "[--md file] [--html file] [--certificate file] [--from] [--to] [--compare snapshot]",
+		"cmd.chart.args":              "--out file.png|svg [--metric percentage,capacity] [--from 7d] [--to]",
+		"cmd.fleet.args":              "[import [--host name] <file|folder>...|report [--sort wear] [--md file]|remove <host>]",
+		"cmd.bench.args":              "[--n 130000] [--runs 3] [--cpuprofile file] [--memprofile file]",
+		"cmd.calibration.args":        "[--md file] [status|start|abort|resume|finish|report]",
+		"cmd.tmux-status.args":        "[seconds]",
+		"cmd.replay.args":             "[--speed 60] <file.sqlite|file.json>",
+		"cmd.verify-certificate.args": "<code>",
+		"cmd.note.args":               "[add [--at|--from --to|--session N] <text>|list|delete N]",
+		"flag.verbose":                "verbose log (DEBUG level)",
+		"flag.version":                "program version",
+		"flag.help":                   "help",
+
+		// batmon help и batmon version
+		"cli.help.title":               "❓ BatMon v2.0 help",
+		"cli.help.about":               "🔋 About:",
+		"cli.help.about.1":             "BatMon is an advanced tool for monitoring MacBook battery health.",
+		"cli.help.about.2":             "It offers interactive monitoring, detailed analytics and report export.",
+		"cli.help.features":            "📊 Features:",
+		"cli.help.features.1":          "• Interactive dashboard with charts",
+		"cli.help.features.2":          "• Trend analysis and degradation forecast",
+		"cli.help.features.3":          "• Temperature and extended metrics monitoring",
+		"cli.help.features.4":          "• Export to Markdown and HTML",
+		"cli.help.features.5":          "• Automatic data retention",
+		"cli.help.features.6":          "• Colored output and emoji indicators",
+		"cli.help.tui":                 "🫧 Bubble Tea interface (default):",
+		"cli.help.tui.intro":           "A modern interface with:",
+		"cli.help.tui.1":               "• Interactive components and animations",
+		"cli.help.tui.2":               "• Great responsiveness and performance",
+		"cli.help.tui.3":               "• Adaptive layouts",
+		"cli.help.tui.4":               "• Polished styling",
+		"cli.help.tui.run":             "Run: ./batmon",
+		"cli.help.commands":            "⌨️ Commands:",
+		"cli.help.examples":            "Examples:",
+		"cli.help.example.tmux":        "  set -g status-right '#(batmon tmux-status)'   # tmux widget, 30 s cache",
+		"cli.help.example.certificate": "  batmon export --certificate cert.html          # certificate for resale, print to PDF",
+		"cli.help.no_battery":          "🧪 Without a battery:",
+		"cli.help.no_battery.replay":   "BATMON_SOURCE=replay:<file.json> batmon - replay recorded pmset/ioreg output",
+		"cli.help.modes":               "🎯 Modes:",
+		"cli.help.modes.1":             "1. Interactive monitoring - while on battery",
+		"cli.help.modes.2":             "2. Detailed report - analysis of stored data",
+		"cli.help.modes.3":             "3. Report export - saving to files",
+		"cli.help.modes.4":             "4. Statistics - data and system information",
+		"cli.help.requirements":        "🔧 Requirements:",
+		"cli.help.requirements.1":      "• macOS (tested on Apple Silicon)",
+		"cli.help.requirements.2":      "• Go 1.24+ to build from source",
+		"cli.help.requirements.3":      "• A MacBook with a battery",
+		"cli.help.support":             "🆘 Support:",
+		"cli.help.support.issues":      "• Issues: report problems via GitHub Issues",
+		"cli.help.back":                "Press Enter to return to the menu...",
+		"cli.version.tagline":          "MacBook battery monitor (Apple Silicon)",
+
+		// метрики и аномалии
+		"metrics.trend.stable":    "stable",
+		"metrics.trend.rising":    "rising consumption",
+		"metrics.trend.falling":   "falling consumption",
+		"score.temperature":       "Temperature",
+		"score.voltage_stability": "Voltage stability",
+		"anomaly.charge_jump":     "Sudden charge rise: %d%% → %d%% in %.1f min (%s)",
+		"anomaly.charge_drop":     "Sudden charge drop: %d%% → %d%% in %.1f min (%s)%s",
+		"anomaly.state_change":    "State change: %s → %s (%s)",
+		"anomaly.capacity_jump":   "Sudden capacity change: %d → %d mAh in %.1f min (%s)",
+
+		// консольное меню и batmon diag
+		"console.title":                "🔋 BatMon v2.0 - MacBook Battery Monitor",
+		"console.status_failed":        "⚠️ Could not read the current status: %v\n",
+		"console.choose":               "📋 Choose an action:",
+		"console.menu.1":               "  1️⃣  Start interactive monitoring",
+		"console.menu.2":               "  2️⃣  Show the detailed report",
+		"console.menu.3":               "  3️⃣  Export reports",
+		"console.menu.4":               "  4️⃣  Statistics and settings",
+		"console.menu.5":               "  5️⃣  Help",
+		"console.menu.0":               "  0️⃣  Quit",
+		"console.prompt":               "Your choice (0-5): ",
+		"console.bye":                  "\n👋 Goodbye!",
+		"console.invalid":              "\n❌ Invalid choice. Press Enter to continue...",
+		"console.current":              "💡 Current status: ",
+		"console.charging":             " 🔌 Charging",
+		"console.on_battery":           " 🔋 On battery",
+		"console.charged":              " ✅ Charged",
+		"console.monitor.start":        "🔄 Starting interactive monitoring...",
+		"console.monitor.auto":         "💡 The mode is detected automatically",
+		"console.monitor.signal":       "\n⏹️ Received a shutdown signal...",
+		"console.monitor.power_failed": "⚠️ Could not detect the power source: %v",
+		"console.monitor.power":        "⚡ Power state: %s (%d%%)",
+		"console.monitor.battery":      "🔋 On battery - starting monitoring and the dashboard...",
+		"console.monitor.background":   "🔋 Data is collected in the background. Use the main menu for monitoring.",
+		"console.monitor.ac":           "🔌 On AC power - showing stored data...",
+		"console.report.loading":       "📊 Loading the detailed report...",
+		"console.back":                 "\nPress Enter to return to the menu...",
+		"console.export.title":         "📄 Export reports",
+		"console.export.1":             "  1️⃣  Export to Markdown (.md)",
+		"console.export.2":             "  2️⃣  Export to HTML (.html)",
+		"console.export.3":             "  3️⃣  Export to both formats",
+		"console.export.0":             "  0️⃣  Back to the main menu",
+		"console.export.prompt":        "Choose a format (0-3): ",
+		"console.export.filename":      "📝 Enter a file name (without extension): ",
+		"console.export.default_name":  "💡 Using the default name: %s",
+		"console.export.generating":    "📊 Generating the report...",
+		"console.export.failed":        "❌ Export failed: %v",
+		"console.export.done":          "✅ Export completed!",
+		"console.continue_nl":          "\nPress Enter to continue...",
+		"console.continue":             "Press Enter to continue...",
+		"console.clear.title":          "🗑️  Clear the database",
+		"console.clear.warning":        "⚠️  WARNING: this deletes ALL stored data!",
+		"console.clear.list":           "The following will be deleted:",
+		"console.clear.list.1":         "  • All battery measurements",
+		"console.clear.list.2":         "  • State history",
+		"console.clear.list.3":         "  • Usage statistics",
+		"console.clear.confirm":        "Are you sure? (y/n): ",
+		"console.clear.aborted":        "❌ Clearing cancelled: %v",
+		"console.clear.backup":         "💾 Backup: %s (restore with: batmon db restore <path>)",
+		"console.clear.remove_failed":  "⚠️  Could not delete %s: %v",
+		"console.clear.done":           "✅ The database has been cleared!",
+		"console.clear.cancelled":      "❌ Operation cancelled",
+		"console.stats.title":          "📊 Data statistics:",
+		"console.stats.records":        "   📦 Records in the DB: %v",
+		"console.stats.size":           "   💾 DB size: %.1f MB",
+		"console.stats.buffer":         "   🗄️ Memory buffer: %v/%v records",
+		"console.stats.oldest":         "   📅 Oldest record: %s",
+		"console.stats.newest":         "   📅 Newest record: %s",
+		"console.metrics.loading":      "🔬 Loading extended metrics...",
+		"console.metrics.not_enough":   "⚠️ Not enough data for analysis",
+		"console.metrics.title":        "🔬 Extended metrics:",
+		"console.metrics.efficiency":   "⚡ Power efficiency: %.1f%%",
+		"console.metrics.voltage":      "🔧 Voltage stability: %.1f%%",
+		"console.metrics.charging":     "🔋 Charging efficiency: %.2f",
+		"console.metrics.trend":        "📊 Power trend: %s",
+		"console.metrics.rating":       "🏆 Health rating: %d/100",
+		"console.metrics.apple":        "🍎 Apple status: %s",
+		"console.cleanup.start":        "🧹 Cleaning up old data...",
+		"console.cleanup.failed":       "❌ Cleanup failed: %v",
+		"console.cleanup.done":         "✅ Cleanup completed",
+		"sysinfo.title":                "💻 System information:",
+		"sysinfo.go":                   "🔧 Go version: %s",
+		"sysinfo.db":                   "💾 Database: SQLite in WAL mode",
+		"sysinfo.db_file":              "📁 DB file: %s",
+		"sysinfo.source":               "🔌 Data source: %s",
+		"sysinfo.unknown_model":        "not in the model catalog",
+		"sysinfo.model":                "💻 Model: %s (%s)",
+		"sysinfo.tool_ok":              "✅ %s is available",
+		"sysinfo.tool_missing":         "❌ %s is not available",
+		"report.cli.brightness":        "%s: %s (%.1f h)",
+
+		// дашборд
+		"export.done":                   "✅ Export finished! Files created:",
+		"calibration.paused_by_charger": "🔌 Charger connected – the test is paused",
+		"dashboard.col.time":            "Time",
+		"dashboard.col.charge":          "Charge",
+		"dashboard.col.state":           "State",
+		"dashboard.col.temp":            "Temp.",
+		"app.unknown_state":             "Unknown application state",
+		"dashboard.scroll":              "   ↕ Scroll: %d/%d (↑↓/kj)",
+		"loading.title":                 "🔋 FULL BATTERY TEST",
+		"loading.collecting":            "🔄 Collecting battery data...\n\n",
+		"loading.todo":                  "📋 WHAT TO DO:",
+		"loading.todo.1":                "1. Keep the program running\n",
+		"loading.todo.2":                "2. Use the MacBook as usual\n",
+		"loading.todo.3":                "3. Discharge the battery to 10-0%\n",
+		"loading.todo.4":                "4. Get the report once it is discharged\n\n",
+		"loading.tips":                  "💡 TIPS:",
+		"loading.tips.1":                "• At least 2-3 hours for a reliable analysis\n",
+		"loading.tips.2":                "• Do not close the program\n",
+		"loading.tips.3":                "• Save your work when the charge is low\n\n",
+		"loading.caffeinate":            "☕ Sleep prevention is active",
+		"tui.back_to_menu":              "Press 'q' to return to the main menu",
+		"dashboard.compact":             "🔋 Battery monitor\n\nCharge: %d%% │ %s\nState: %s\nCycles: %d │ Wear: %.1f%%\nTemperature: %d°C\n\n⌨️  'q' - quit │ 'r' - refresh",
+		"dashboard.quality.poor":        "Not enough",
+		"dashboard.quality.excellent":   "Excellent",
+		"dashboard.quality.good":        "Good",
+		"dashboard.panel":               "🔋 Current state\n\n⚡ Charge: %d%%\n%s\n\n📉 Wear: %.1f%%\n%s\n\n🔄 State: %s\n🔁 Cycles: %d\n🌡️  Temperature: %d°C\n⚡ Voltage: %d mV\n🔌 Current: %d mA\n\n💚 Health: %s\n\n📊 Data quality: %s\n⏱️  Collected: %.1fh (%d points)",
+		"dashboard.recent":              "Recent measurements\n",
+		"dashboard.keys":                "Controls:\n",
+		"dashboard.keys.quit":           "  'q' - quit\n",
+		"dashboard.keys.refresh":        "  'r' - refresh\n",
+		"dashboard.keys.window":         "  'w' - chart window (%s)\n",
+		"dashboard.keys.zoom":           "  '+'/'-' - zoom, 'h'/'l' - pan\n",
+		"dashboard.keys.crosshair":      "  'x' - crosshair (←→)\n",
+		"dashboard.keys.metric":         "  't' - capacity/temperature/power chart\n",
+		"dashboard.keys.caffeinate":     "  'c' - sleep prevention (%s)\n",
+		"dashboard.keys.doctor":         "  'd' - collector health\n",
+		"dashboard.keys.scroll":         "  ↑↓/jk - scroll\n\n",
+
+		// вкладка обзора отчета
+		"state.charging":             "Charging",
+		"state.discharging":          "Discharging",
+		"state.charged":              "Charged",
+		"state.ac":                   "On AC",
+		"history.filter.all":         "All",
+		"tui.report.failed":          "❌ Could not load the report: %v\nPress 'q' to return to the menu",
+		"tui.report.title":           "📊 Detailed battery health report\n",
+		"tui.report.overall":         "🔋 OVERALL HEALTH\n",
+		"tui.report.health":          "│ Health:    %s %s\n",
+		"tui.report.rating":          "│ Rating:    %s %d/100\n",
+		"tui.report.model":           "│ Model:     %s\n",
+		"tui.report.wear":            "│ Wear:      %.1f%%\n",
+		"tui.report.cycles":          "│ Cycles:    %d\n",
+		"tui.report.current":         "⚡ CURRENT STATE\n",
+		"tui.report.charge":          "│ Charge:    %s %d%%\n",
+		"tui.report.state":           "│ Status:    %s %s\n",
+		"tui.report.remaining":       "│ Remaining: %s\n",
+		"tui.report.temp":            "│ Temp:      %s %d°C\n",
+		"tui.report.performance":     "📈 PERFORMANCE\n",
+		"tui.report.power":           "│ Discharge power:    %.1f W\n",
+		"tui.report.rate":            "│ Discharge rate:     %.1f mAh/h\n",
+		"tui.report.consumption":     "│ Consumption:        %d mW\n",
+		"tui.report.voltage":         "│ Voltage:            %.2f V\n",
+		"tui.report.intervals":       "│ Valid intervals:    %d\n",
+		"tui.report.battery":         "💊 BATTERY HEALTH\n",
+		"tui.report.current_cap":     "│ Current capacity:   %s\n",
+		"tui.report.full_cap":        "│ Full capacity:      %s\n",
+		"tui.report.design_cap":      "│ Design capacity:    %s\n",
+		"tui.report.apple":           "│ Apple status:       %s\n",
+		"tui.report.problems":        "⚠️  DETECTED PROBLEMS\n",
+		"tui.report.recommendations": "💡 RECOMMENDATIONS\n",
+		"tui.report.top_apps":        "🔌 APPS WITH THE HIGHEST DRAIN (%s)\n",
+		"tui.report.top_app":         "│ %2d. %-22s %6.0f mAh %5.2f Wh\n",
+		"tui.report.recent":          "📋 RECENT MEASUREMENTS\n",
+		"tui.report.recent.header":   "│   Time   │ Charge %│      State      │ Temp °C  │\n",
+
+		// виджеты обзора
+		"tui.help.filter":       "/ filter",
+		"tui.help.map":          "m map",
+		"widget.health":         "💚 Battery health",
+		"widget.charge":         "🔋 Current charge",
+		"widget.wear":           "⚙️ Battery wear",
+		"widget.baseline":       "📌 Since batmon was installed",
+		"widget.baseline.value": "%s mAh (%s%%)",
+		"widget.cycles":         "🔄 Charge cycles",
+		"widget.full_cap":       "⚡ Full capacity",
+		"widget.power":          "🔌 Discharge power",
+		"widget.power.value":    "%.1f W",
+		"widget.remaining":      "⏱️ Time remaining",
+		"widget.full_zone":      "🔝 At 100% on AC",
+		"widget.temperature":    "🌡️ Temperature",
+
+		// вкладки отчета, приветствие и быстрая диагностика
+		"dashboard.chart.empty":               "📊 Charge chart\n\nNo data to display",
+		"tui.charts.title":                    "📈 Battery performance charts\n",
+		"tui.charts.charge":                   "🔋 Charge history (last 24 hours)\n",
+		"tui.charts.rate":                     "⚡ Discharge rate\n",
+		"tui.charts.temperature":              "🌡️ Temperature profile\n",
+		"tui.charts.no_data":                  "No data to display",
+		"tui.charts.not_enough":               "Not enough data",
+		"tui.charts.no_discharge":             "No discharge data",
+		"tui.charts.rate_range":               "\nMin: %.1f%%/h  Max: %.1f%%/h",
+		"tui.charts.no_temperature":           "No data",
+		"tui.anomalies.title":                 "⚠️ Anomalies and problems\n",
+		"tui.anomalies.none":                  "✅ No anomalies found!\n\n",
+		"tui.anomalies.normal":                "The battery is working normally.\n",
+		"tui.anomalies.critical":              "🚨 Critical problems:\n",
+		"tui.anomalies.warning":               "⚡ Need attention:\n",
+		"tui.anomalies.info":                  "ℹ️ Information:\n",
+		"tui.anomalies.thermal":               "\n🌡️ Overheating (above %d°C for longer than %s):\n",
+		"tui.anomalies.thermal.charging":      ", while charging",
+		"tui.anomalies.thermal.event":         "  • %s – %s, peak %d°C, average %.1f°C%s\n",
+		"tui.anomalies.recommendations":       "\n💡 Recommendations:\n",
+		"tui.anomalies.stats":                 "\n\n📊 Anomaly statistics:\n",
+		"tui.anomalies.stats.found":           "• Problems found: %d\n",
+		"tui.anomalies.stats.recommendations": "• Recommendations: %d\n",
+		"tui.anomalies.stats.intervals":       "• Valid intervals: %d\n",
+		"tui.history.title":                   "📜 Measurement history\n",
+		"tui.history.filter":                  "Filter: %s | Sort: %s\n",
+		"tui.history.expr":                    "Expression: %s (x – clear)",
+		"tui.history.page":                    "Page %d of %d · records: %d",
+		"tui.sessions.title":                  "🔋 Discharge and charge sessions\n",
+		"tui.sessions.none":                   "No sessions yet – they appear after the first discharge or charge.\n",
+		"tui.sessions.col.kind":               "Type",
+		"tui.sessions.col.start":              "Start",
+		"tui.sessions.col.duration":           "Duration",
+		"tui.sessions.col.charge":             "Charge",
+		"tui.sessions.col.rate":               "Rate",
+		"tui.sessions.row":                    "%5d %-12s %-17s %-15s %3d%% → %3d%% %5.1f%%/h",
+		"tui.sessions.recent":                 "Recent sessions: %d",
+		"tui.sessions.range":                  "Sessions for %s: %d",
+		"tui.sessions.note_hint":              "Note for a session: batmon note add --session <#> <text>",
+		"tui.charging.none":                   "No charge sessions yet.\n",
+		"tui.charging.col.watts":              "W",
+		"tui.charging.col.notes":              "Notes",
+		"tui.charging.legend":                 "Slow charge: 20→80%% longer than %s; slow top-up: 80→100%% longer than %s",
+		"tui.forecast.title":                  "🔮 Forecasts and analytics\n",
+		"tui.forecast.runtime":                "⏱️ Runtime forecast:\n",
+		"tui.forecast.current":                "• At the current load: %s\n",
+		"tui.forecast.light":                  "• At a light load: %s\n",
+		"tui.forecast.heavy":                  "• At a heavy load: %s\n",
+		"tui.forecast.wear":                   "📉 Battery wear forecast:\n",
+		"tui.forecast.wear.month":             "In %d mo: %.1f%% wear (%d cycles)",
+		"tui.forecast.tips":                   "💡 Tips for a longer battery life:\n",
+		"tui.forecast.tip.1":                  "Keep the charge between 20-80% for the least wear",
+		"tui.forecast.tip.2":                  "Avoid fully draining the battery",
+		"tui.forecast.tip.3":                  "Use the original charger",
+		"tui.forecast.tip.4":                  "Avoid overheating (>45°C) and overcooling (<10°C)",
+		"tui.forecast.tip.5":                  "Remove the battery during long work on AC (if possible)",
+		"tui.forecast.excellent":              "\n✅ The battery is in excellent condition!",
+		"tui.forecast.good":                   "\n⚡ The battery is in good condition",
+		"tui.forecast.replace":                "\n⚠️ Battery replacement is recommended",
+		"welcome.subtitle":                    "Smart MacBook battery analysis",
+		"welcome.purpose":                     "🎯 WHAT IT IS FOR",
+		"welcome.purpose.text":                "To help you make an informed decision:\n",
+		"welcome.question":                    "DOES YOUR MacBook NEED A NEW BATTERY?",
+		"welcome.how":                         "🔍 HOW IT WORKS",
+		"welcome.how.1":                       "1. The program collects battery data\n",
+		"welcome.how.2":                       "2. Compares real figures with the rated ones\n",
+		"welcome.how.3":                       "3. Finds anomalies and problems\n",
+		"welcome.how.4":                       "4. Gives a clear recommendation with reasons\n\n",
+		"welcome.why":                         "⚠️ WHY IT MATTERS",
+		"welcome.why.text":                    "The standard macOS figures can be misleading:\n",
+		"welcome.why.1":                       "• The battery shows 5 hours but dies in 2\n",
+		"welcome.why.2":                       "• The charge suddenly drops from 90% to 40%\n",
+		"welcome.why.3":                       "• Overheating under a normal load\n\n",
+		"welcome.why.promise":                 "BatMon finds such problems and explains their causes!",
+		"welcome.start":                       "🚀 LET'S START!",
+		"welcome.start.text":                  "For the most accurate analysis:\n",
+		"welcome.start.1":                     "1. Charge your MacBook to 100%\n",
+		"welcome.start.2":                     "2. Choose 'Full battery test'\n",
+		"welcome.start.3":                     "3. Use your MacBook as usual until it runs down\n",
+		"welcome.start.4":                     "4. The MacBook will not sleep (unless you close the lid)\n\n",
+		"welcome.continue":                    "Press Enter or Space to continue\n",
+		"welcome.quit":                        "'q' to quit",
+		"quick.no_data":                       "❌ Battery data is unavailable\n\nPress 'q' to return to the menu",
+		"quick.title":                         "⚡ QUICK BATTERY CHECK",
+		"quick.current":                       "📊 CURRENT STATE",
+		"quick.charge":                        "🔋 Charge: %s\n",
+		"quick.state":                         "🔄 State: %s\n",
+		"quick.temperature":                   "🌡️ Temperature: %s\n",
+		"quick.health":                        "💚 BATTERY HEALTH",
+		"quick.wear":                          "📉 Wear: %s\n",
+		"quick.cycles":                        "🔁 Cycles: %s\n",
+		"quick.overall":                       "💚 Overall: %s\n\n",
+		"quick.recommendation":                "🎯 QUICK RECOMMENDATION",
+		"quick.good":                          "✅ The battery is in good condition. No replacement needed.",
+		"quick.plan":                          "⚠️ The battery works, but plan a replacement.",
+		"quick.replace":                       "🔴 Battery replacement is recommended.",
+		"quick.tip":                           "💡 TIP",
+		"quick.tip.1":                         "For a complete analysis choose '🔋 Full battery test'\n",
+		"quick.tip.2":                         "or '📊 Detailed report' for charts and trends\n\n",
+
+		// экспорт из командной строки
+		"export.title":    "🔋 Batmon - Report export",
+		"export.markdown": "📝 Exporting the report to Markdown: %s",
+		"export.html":     "🌐 Exporting the report to HTML: %s",
+
+		// тест полной разрядки
+		"calibration.note.paused":          "charger connected at %d%%",
+		"calibration.alert.paused":         "batmon: battery test paused",
+		"calibration.alert.paused.body":    "Charger connected at %d%%. Resume the test after unplugging the charger or finish it early.",
+		"calibration.note.partial":         "partial test: ",
+		"calibration.md.title":             "# 🔋 Full battery test report (100% → 0%)\n\n",
+		"calibration.md.started":           "**Discharge started:** %s  \n",
+		"calibration.md.finished":          "**Finished:** %s\n\n",
+		"calibration.md.partial":           "> ⚠️ The test was finished early (%s): discharged %d%% of %d%%, the time is scaled to a full discharge.\n\n",
+		"calibration.md.runtime":           "## ⏱️ Runtime\n\n",
+		"calibration.md.measured":          "- **Measured:** %s (%d%% → %d%%)\n",
+		"calibration.md.paused":            "- **Charging pauses:** %s, recharged %d%% (not counted as discharge)\n",
+		"calibration.md.full":              "- **Scaled to 100%% → 0%%:** %s\n",
+		"calibration.md.apple":             "- **macOS estimate at the start:** %s\n",
+		"calibration.md.deviation":         "- **Deviation from the estimate:** %+.0f%%\n",
+		"calibration.md.apple_none":        "- **macOS estimate:** unavailable\n",
+		"calibration.md.capacity":          "\n## ⚡ Capacity\n\n",
+		"calibration.md.delivered":         "- **Delivered during the test:** %d mAh\n",
+		"calibration.md.current":           "- **Average discharge current:** %.0f mA\n",
+		"calibration.md.full_design":       "- **Full / design capacity:** %d / %d mAh (wear %.1f%%)\n",
+		"calibration.md.milestones":        "\n## 📍 Milestones\n\n",
+		"calibration.md.milestones.header": "| Charge | Time | Since discharge start |\n",
+		"calibration.none":                 "No calibration tests yet. Run: batmon calibration start",
+		"calibration.cli.started":          "✅ Test started. Unplug the charger and keep data collection running (batmon collect or the interface)",
+		"calibration.note.aborted":         "aborted by the user",
+		"calibration.cli.resumed":          "▶️ Test resumed. Unplug the charger – the discharge continues from the current charge",
+		"calibration.cli.finished":         "✅ Test finished early. Report: batmon calibration report",
+		"calibration.cli.saved":            "✅ Test report saved: %s",
+		"calibration.cli.unknown_action":   "❌ Unknown calibration action: %s (status, start, abort, resume, finish, report)",
+		"calibration.status.completed":     "✅ Test finished %s: %s of discharge (%d%% → %d%%)",
+		"calibration.status.aborted":       "⏹️ Test aborted %s: %s",
+		"calibration.status.paused":        "⏸️ Test paused since %s: %s. Resume – batmon calibration resume, finish early – batmon calibration finish",
+		"calibration.status.waiting":       "⏳ Test started – unplug the charger to begin the discharge",
+		"calibration.status.resumed":       "⏳ Test resumed – unplug the charger to continue the discharge",
+		"calibration.status.running":       "🔋 Discharging: %s since the start (started at %d%%)",
+		"calibration.tui.no_data":          "❌ No battery data – wait for the first measurement",
+		"calibration.tui.started":          "✅ Test started. Unplug the charger and work as usual",
+		"calibration.tui.resumed":          "▶️ Test resumed. Unplug the charger",
+		"calibration.tui.finished":         "✅ Test finished early",
+		"calibration.tui.aborted":          "⏹️ Test aborted",
+		"calibration.tui.saved":            "✅ Report saved: %s",
+		"calibration.tui.title":            "🔋 FULL BATTERY TEST (100% → 0%)",
+		"calibration.tui.progress":         "📊 TEST PROGRESS",
+		"calibration.tui.progress.line":    "Charge: %d%%  Progress: %s %.0f%%\n",
+		"calibration.tui.apple":            "macOS estimate at the start: %s\n",
+		"calibration.tui.milestones":       "📍 MILESTONES",
+		"calibration.tui.running_tip":      "\n💡 Do not plug in the charger until the test ends. System sleep is disabled.\n",
+		"calibration.tui.keys.running":     "d – dashboard · x – abort the test · q – menu",
+		"calibration.tui.paused":           "⏸️ TEST PAUSED",
+		"calibration.tui.paused.note":      "During the discharge: %s. Data while charging is not part of the test.\n\n",
+		"calibration.tui.paused.progress":  "Discharged: %d%% in %s, milestones reached: %d\n\n",
+		"calibration.tui.paused.resume":    "r – resume: the test continues once the charger is unplugged\n",
+		"calibration.tui.paused.finish":    "f – finish early: a report on the discharge so far\n",
+		"calibration.tui.keys.paused":      "r – resume · f – finish · x – abort · q – menu",
+		"calibration.tui.partial":          "✅ TEST FINISHED EARLY",
+		"calibration.tui.completed":        "✅ TEST FINISHED",
+		"calibration.tui.runtime":          "Discharge time: %s (%d%% → %d%%)\n",
+		"calibration.tui.full":             "Scaled to 100%% → 0%%: %s\n",
+		"calibration.tui.deviation":        "macOS estimate: %s (deviation %+.0f%%)\n",
+		"calibration.tui.delivered":        "Delivered: %d mAh, average current %.0f mA\n",
+		"calibration.tui.keys.completed":   "e – save the report · enter – new test · q – menu",
+		"calibration.tui.howto":            "📋 HOW TO RUN THE TEST",
+		"calibration.tui.howto.1":          "1. Charge your MacBook to 100%\n",
+		"calibration.tui.howto.2":          "2. Press Enter and unplug the charger\n",
+		"calibration.tui.howto.3":          "3. Work as usual without closing batmon\n",
+		"calibration.tui.howto.4":          "4. The test ends by itself when the charge drops below %d%%\n\n",
+		"calibration.tui.keys.idle":        "enter – start the test · d – dashboard · q – menu",
+		"calibration.tui.waiting":          "⏳ Waiting for the first measurement...\n",
+		"calibration.tui.ready":            "✅ Charge %d%% – ready to start\n",
+		"calibration.tui.not_ready":        "❌ Charge %d%% – charge to 100%% (at least %d%%)\n",
+
+		// диагностика сборщика
+		"doctor.probe.not_found":          "not found in PATH",
+		"doctor.probe.not_found.fix":      "BatMon calls macOS system tools: check that /usr/bin and /usr/sbin are in PATH (which %s)",
+		"doctor.probe.error":              "error: %v",
+		"doctor.probe.error.fix":          "run “%s” in a terminal and read the message",
+		"doctor.probe.no_battery":         "no battery data in the output",
+		"doctor.probe.no_battery.fix":     "this is normal on a Mac without a battery; otherwise check the output of “%s”",
+		"doctor.probe.slow":               "responds slowly",
+		"doctor.probe.slow.fix":           "the system is overloaded or the tool hangs; if delays persist, increase the poll interval in settings",
+		"doctor.probe.ok":                 "responds",
+		"doctor.source":                   "source %s",
+		"doctor.source.fix":               "batmon diag shows the details; no new measurements appear without a source",
+		"doctor.source.slow":              "the source responds slowly: increase the poll interval in settings",
+		"doctor.last_sample":              "last measurement",
+		"doctor.last_sample.failures":     "%d collection errors in a row, the last at %s: %v",
+		"doctor.last_sample.failures.fix": "check the tools above and the “Log” screen",
+		"doctor.last_sample.age":          "%s ago (%s)",
+		"doctor.last_sample.stale":        "collection has not run for a while: the Mac was asleep or the poll loop hung – restart BatMon",
+		"doctor.last_sample.read":         "reading the database: %v",
+		"doctor.last_sample.read.fix":     "check the database file (batmon db path) or restore a backup: batmon db restore",
+		"doctor.last_sample.empty":        "no measurements in the database",
+		"doctor.last_sample.empty.fix":    "start the interface or batmon collect",
+		"doctor.last_sample.old":          "this is normal if BatMon is not running now; for background collection – batmon collect",
+		"doctor.db_write":                 "database writes",
+		"doctor.db_write.conn":            "connection: %v",
+		"doctor.db_write.conn.fix":        "check the database path (--db) and the file permissions",
+		"doctor.db_write.locked":          "another process holds the database: close the second BatMon or batmon collect",
+		"doctor.db_write.denied":          "no write permission: ls -l %s",
+		"doctor.db_write.dir":             "the database folder is not writable: %v",
+		"doctor.db_write.dir.fix":         "SQLite creates -wal and -shm files next to the database: check the permissions on %s",
+		"doctor.disk":                     "disk space",
+		"doctor.disk.memory":              "in-memory database",
+		"doctor.disk.error":               "could not check: %v",
+		"doctor.disk.free":                "%s free",
+		"doctor.disk.critical":            "free up space: writes to the database and the log will stop soon",
+		"doctor.disk.low":                 "low on space; shorten retention in settings or run batmon db cleanup --days 30",
+		"doctor.caffeinate":               "sleep prevention",
+		"doctor.caffeinate.unsupported":   ", unavailable on this OS",
+		"doctor.caffeinate.missing":       "%s not found",
+		"doctor.caffeinate.missing.fix":   "the calibration test may be interrupted by sleep: adjust sleep settings manually",
+		"doctor.caffeinate.running":       ", %s is running",
+		"doctor.caffeinate.stopped":       ", not running",
+		"doctor.caffeinate.orphan":        "an orphaned %s (PID %d) from a BatMon that has exited keeps the Mac awake",
+		"doctor.caffeinate.orphan.fix":    "start the BatMon interface – it ends the process, or: kill %d",
+		"doctor.caffeinate.other":         ", %s (PID %d) is held by another BatMon run",
+		"doctor.log":                      "log",
+		"doctor.log.empty":                "empty",
+		"doctor.log.counts":               "last hour: %d errors, %d warnings",
+		"doctor.log.last":                 "; latest: %s",
+		"doctor.log.fix":                  "details – the “Log” screen or %s",
+
+		// batmon check
+		"check.exit_code":         "exit code %d",
+		"check.above_critical":    " ≥ %g (critical)",
+		"check.wear":              "wear %.1f%%",
+		"check.cycles":            "cycles %.0f",
+		"check.anomalies":         "anomalies %.0f",
+		"check.err.data":          "loading data: %w",
+		"check.err.no_history":    "no history and the battery could not be read: %w",
+		"check.err.no_design":     "the source does not report the design capacity",
+		"check.unknown_db":        "UNKNOWN: opening the database: %v",
+		"check.summary":           "%s: wear %.1f%%, cycles %d",
+		"check.live":              " (no history)",
+		"check.summary.anomalies": ", anomalies %d",
+
+		// таблица истории
+		"history.col.time":              "Time",
+		"history.col.charge":            "Charge",
+		"history.col.state":             "State",
+		"history.col.cycles":            "Cycles",
+		"history.col.temp":              "Temp.",
+		"history.col.wear":              "Wear",
+		"history.detail.current_cap":    "Current capacity",
+		"history.detail.full_cap":       "Full capacity",
+		"history.detail.design_cap":     "Design capacity",
+		"history.detail.temperature":    "Temperature",
+		"history.detail.voltage":        "Voltage",
+		"history.detail.voltage.value":  "%d mV",
+		"history.detail.amperage":       "Current",
+		"history.detail.amperage.value": "%d mA",
+		"history.detail.power":          "Power",
+		"history.detail.power.value":    "%.2f W",
+		"history.detail.apple":          "Apple condition",
+		"history.detail.serial":         "Serial number",
+		"history.detail.brightness":     "Screen brightness",
+		"history.detail.lid":            "Lid",
+		"history.detail.cells":          "Cells",
+		"history.detail.cells.value":    "%s mV (spread %d mV)",
+		"history.detail.title":          "Measurement #%d",
+		"history.detail.close":          "Enter/Esc – close",
+
+		// форма экспорта
+		"export.form.to_placeholder": "empty – until now",
+		"export.form.no_format":      "choose at least one format",
+		"export.form.no_name":        "enter a file name",
+		"export.form.preparing":      "preparing data",
+		"export.form.step":           "%s (%d of %d)",
+		"export.form.title":          "📄 Report export\n\n",
+		"export.form.running":        "⏳ Exporting: %s",
+		"export.form.created":        "✅ Files created:\n",
+		"export.form.done_keys":      "\nAny key – back to the form, q – main menu",
+		"export.form.formats":        "Formats (space – select):\n",
+		"export.form.name":           "File (without extension):",
+		"export.form.from":           "From: ",
+		"export.form.to":             "To:   ",
+		"export.form.range_hint":     "  Empty range – the latest measurements, as in the report\n\n",
+		"export.form.run":            "[ Export ]",
+		"export.form.vars":           "Placeholders: {date}, {time}, {host}, {serial}, {format}",
+		"export.form.keys":           "Tab/↑↓ – field • Enter – export • Esc – main menu",
+		"export.form.err.format":     "exporting to %s: %w",
+
+		// сертификат состояния батареи
+		"cert.title":            "Battery health certificate",
+		"cert.issued":           "Issued %s by batmon",
+		"cert.model":            "Scoring model: %s",
+		"cert.serial":           "Battery serial number",
+		"cert.serial.unknown":   "unknown",
+		"cert.full_cap":         "Measured capacity",
+		"cert.mah":              "%d mAh",
+		"cert.design_cap":       "Design capacity",
+		"cert.wear":             "Wear",
+		"cert.cycles":           "Charge cycles",
+		"cert.age":              "Battery age",
+		"cert.condition":        "Condition reported by macOS",
+		"cert.period":           "Observation period",
+		"cert.days":             "%d days",
+		"cert.measurements":     "Measurements",
+		"cert.code":             "Verification code:",
+		"cert.note":             "The checksum covers every measurement of this battery and is stored in the batmon database. The seller can confirm it with",
+		"cert.note.where":       "on this computer, even after old measurements are cleaned up.",
+		"cert.pdf":              "To save as PDF, choose “Print” → “Save as PDF”.",
+		"cert.age.manufactured": "%s, manufactured %s",
+		"cert.age.at_least":     "at least %s (manufacture date unknown)",
+		"age.days":              "%d d",
+		"age.months":            "%d mo",
+		"age.years":             "%d y",
+		"age.years_months":      "%d y %d mo",
+		"cert.cli.saved":        "✅ Certificate saved: %s",
+		"cert.cli.code":         "🔐 Verification code: %s",
+		"cert.cli.verified":     "✅ Certificate confirmed: issued on data as of %s, %d measurements, wear %.1f%%, %d cycles",
+		"cert.err.no_data":      "no data for a certificate",
+		"cert.err.no_capacity":  "the battery capacity has not been measured yet, keep monitoring",
+		"cert.err.mismatch":     "the code does not match this battery's data – the data was changed or the certificate was issued on another computer",
+
+		// ограничение заряда
+		"charge_limit.ceiling": "Charge %d%% (ceiling %d%%) - unplug the adapter so the battery does not stay at a high charge",
+		"charge_limit.floor":   "Charge %d%% (floor %d%%) - plug in the adapter, deep discharge speeds up wear",
+		"charge_limit.alert":   "batmon: charge limit",
+
+		// кэш отчета
+		"report.cache.built_at": "data from %s",
+
+		// вкладка расширенных метрик
+		"metrics.tab.title":              "🔬 Advanced metrics\n",
+		"metrics.tab.no_data":            "Not enough data for analysis.\n",
+		"metrics.tab.stability":          "🔧 Voltage stability",
+		"metrics.tab.stability.meaning":  "How steady the battery voltage is. Below 95% means dips under load, a sign of rising internal resistance.",
+		"metrics.tab.stability.formula":  "100 × (1 − σ/mean) of the voltage over all measurements in the period (coefficient of variation).",
+		"metrics.tab.efficiency":         "⚡ Power efficiency",
+		"metrics.tab.efficiency.meaning": "How economically energy is used: the lower the average power, the higher the value.",
+		"metrics.tab.efficiency.formula": "100 − average |power| in mW / 100; 0 if the average power is above 10 W.",
+		"metrics.tab.health":             "🏆 Health rating",
+		"metrics.tab.health.meaning":     "The overall battery score from wear, cycles, temperature and voltage stability.",
+		"metrics.tab.health.formula":     "100 − wear × 0.5 − cycles / 10 − degrees above 45°C − (95 − voltage stability, if it is below 95%).",
+		"metrics.tab.formula":            "Formula: %s",
+		"metrics.tab.charging":           "🔋 Charging efficiency",
+		"metrics.tab.charging.value":     "%.2f mAh/mW\n",
+		"metrics.tab.charging.none":      "no charging power data\n",
+		"metrics.tab.charging.meaning":   "How much stored capacity there is per milliwatt of charging power. A relative figure: what matters is how it changes over time.\n",
+		"metrics.tab.charging.formula":   "the average ratio of current capacity to power over measurements with positive power (while charging).",
+		"metrics.tab.trend":              "📊 Power trend",
+		"metrics.tab.trend.none":         "not enough data",
+		"metrics.tab.trend.formula":      "the last three power measurements – rising, falling or with no clear direction.",
+		"metrics.tab.apple":              "🍎 Apple status",
+		"metrics.tab.apple.note":         "The condition from the system; if macOS does not report it – an estimate from the health rating (85+ Normal, 70+ Service Recommended).",
+
+		// яркость экрана
+		"brightness.low":        "🔅 brightness up to 33%",
+		"brightness.mid":        "🔆 brightness 34–66%",
+		"brightness.high":       "☀️ brightness from 67%",
+		"brightness.lid_closed": "🌙 lid closed",
+		"brightness.note.lid":   ", lid closed",
+		"brightness.note.high":  ", screen brightness %d%%",
+
+		// аномалии показаний
+		"anomaly.voltage_sag":        "Voltage sag under load: %d → %d mV at %d mA (%s)",
+		"anomaly.capacity_over_full": "Capacity reading glitch: current %d mAh is above full %d mAh (%s)",
+		"anomaly.capacity_spike":     "Capacity reading glitch: full capacity %d → %d → %d mAh (%s)",
+		"anomaly.sleep_drain":        "High drain during sleep: %d%% → %d%% in %s (%.1f%%/h, %s–%s)",
+
+		// замер производительности
+		"bench.usage":        "❌ At least 2 measurements and 1 run are required",
+		"bench.title":        "⏱️ Analysis pipeline benchmark: %d measurements, %d runs\n",
+		"bench.insert":       "Writing the synthetic history: %s\n",
+		"bench.stage.export": "Markdown and HTML export",
+		"bench.stage.tui":    "TUI report, all tabs",
+		"bench.col.stage":    "Stage",
+		"bench.col.best":     "best",
+		"bench.col.mean":     "mean",
+		"bench.col.allocs":   "allocs",
+		"bench.col.bytes":    "memory",
+		"bytes.gb":           "%.1f GB",
+		"bytes.mb":           "%.1f MB",
+		"bytes.kb":           "%.1f KB",
+		"bytes.b":            "%d B",
+
+		// графики в PNG и SVG
+		"chart.metric.percentage":  "Charge",
+		"chart.metric.capacity":    "Full capacity",
+		"chart.metric.temperature": "Temperature",
+		"chart.metric.power":       "Power",
+		"chart.unit.percent":       "%",
+		"chart.unit.mah":           "mAh",
+		"chart.unit.celsius":       "°C",
+		"chart.unit.watts":         "W",
+		"chart.cli.no_out":         "❌ Specify an --out file ending in .png or .svg",
+		"chart.cli.too_small":      "❌ The chart is too small: at least 300×150 is needed",
+		"chart.cli.unknown_metric": "❌ Unknown metric %q, available: %s",
+		"chart.cli.saved":          "✅ Chart saved: %s (%s)",
+
+		// окно графиков
+		"chart.window.minutes": "%dm",
+		"chart.window.hours":   "%dh",
+		"chart.window.days":    "%dd",
+		"chart.window.until":   "%s until %s",
+
+		// графики в терминале
+		"tui.charts.no_data_chart": "No data to display",
+		"chart.title.charge":       "⚡ Battery charge (%)",
+		"chart.title.capacity":     "🔋 Capacity (mAh)",
+		"chart.title.temperature":  "🌡️ Temperature (°C) · %d/%d",
+		"chart.title.power":        "⚡ Power (W)",
+
+		// распределение заряда
+		"histogram.advice.full": "%.0f%% of the time at 100%% – turn on optimized charging or a limit of %d%%",
+		"histogram.advice.low":  "%.0f%% of the time below %d%% – plug in earlier, deep discharge speeds up wear",
+		"histogram.title":       "📊 Time spent at each charge level:",
+		"histogram.full":        "• At 100%%: %s (%.0f%%)\n",
+		"histogram.low":         "• Below %d%%: %s (%.0f%%)\n",
+
+		// графики дашборда
+		"dashboard.chart.capacity":    "📈 Capacity chart",
+		"dashboard.chart.temperature": "🌡️ Temperature chart",
+		"dashboard.chart.power":       "⚡ Power chart",
+		"dashboard.watts.discharge":   "discharge",
+		"dashboard.watts.charge":      "charge",
+		"dashboard.watts.direction":   "W · %s",
+		"dashboard.watts.average":     "\naverage over %s: %.1f W",
+		"dashboard.watts.vi":          "\n%.2f V × %.2f A",
+		"dashboard.cursor.charge":     "charge %d%%",
+		"dashboard.cursor.capacity":   "capacity %s",
+		"dashboard.cursor.watts":      "%.1f W",
+		"dashboard.cursor.keys":       "   ←→ move, x – exit",
+
+		// парк машин
+		"fleet.md.title":       "# 💻 MacBook fleet: battery health\n\n",
+		"fleet.md.generated":   "**Generated:** %s  \n",
+		"fleet.md.machines":    "**Machines:** %d\n\n",
+		"fleet.md.header":      "| | Host | Model | Cycles | Wear | Score | Snapshot | Notes |\n",
+		"fleet.col.host":       "Host",
+		"fleet.col.model":      "Model",
+		"fleet.col.cycles":     "Cycles",
+		"fleet.col.wear":       "Wear",
+		"fleet.col.score":      "Score",
+		"fleet.col.snapshot":   "Snapshot",
+		"fleet.summary":        "\nMachines: %d, need attention: %d (batmon check thresholds: wear %g%%, cycles %d)",
+		"fleet.unknown_action": "❌ Unknown fleet action: %s (import, report, remove)",
+		"fleet.import.usage":   "❌ Specify snapshot files or a folder: batmon fleet import [--host name] <file.json|folder>...",
+		"fleet.imported":       "✅ Files: %d, snapshots added: %d, already present: %d",
+		"fleet.hosts":          "💻 Machines: %s",
+		"fleet.empty":          "No fleet snapshots. On each machine run batmon status --json > name.json and import them: batmon fleet import <folder>",
+		"fleet.saved":          "✅ Fleet report saved: %s",
+		"fleet.remove.usage":   "❌ Specify a machine: batmon fleet remove <host>",
+		"fleet.removed":        "✅ Machine %q removed from the fleet",
+
+		// заметки
+		"note.unknown_action": "❌ Unknown action %q: add, list or delete",
+		"note.delete.usage":   "❌ Specify a note number: batmon note delete <number>",
+		"note.deleted":        "🗑️ Note %d deleted",
+		"note.add.usage":      "❌ Specify the text: batmon note add [--at time | --from time --to time | --session number] <text>",
+		"note.added":          "%s Note %d: %s",
+		"note.empty":          "No notes. Add one: batmon note add \"installed macOS 15.2\"",
+
+		// снимки
+		"snapshot.usage":          "❌ Specify a snapshot name: batmon snapshot %s <name>",
+		"snapshot.saved":          "✅ Snapshot %q saved: wear %.1f%%, cycles %d",
+		"snapshot.empty":          "No snapshots. Save the current state: batmon snapshot save <name>",
+		"snapshot.row":            "%-20s %s  wear %5.1f%%  cycles %4d  runtime per charge %s",
+		"snapshot.deleted":        "🗑️ Snapshot %q deleted",
+		"snapshot.unknown_action": "❌ Unknown action %q: save, list, compare or delete",
+
+		// описание схемы
+		"schema.source.report":      "JSON export (export screen), GET /api/v1/report",
+		"schema.source.status":      "batmon status --json",
+		"schema.source.measurement": "GET /api/v1/latest, items of GET /api/v1/measurements",
+		"schema.source.health":      "GET /api/v1/health",
+		"schema.title":              "📐 Database schema version %d (batmon %s)",
+		"schema.index":              "index",
+		"schema.unique_index":       "unique index",
+		"schema.exports":            "\n📤 Exports:",
+		"schema.export_row":         "   %-12s %d fields – %s",
+		"schema.csv_row":            "   %-12s %d columns – CSV export",
+		"schema.json_hint":          "\nFull description with JSON Schema: batmon schema --json",
+
+		// правила
+		"rules.source.builtin":  "built-in",
+		"rules.source.override": "changed in config.json",
+		"rules.row":             "%s %-22s %s\n   if %s\n   → %s",
+		"rules.error":           "   error: %v",
+		"rules.metrics":         "\nCondition metrics: %s",
+
+		// batmon status
+		"status.remaining": ", remaining ",
+		"status.wear":      ", wear %.1f%%, cycles %d",
+
+		// tmux
+		"tmux.cache_failed": "⚠️ could not write the tmux cache: %v",
+
+		// очистка базы в интерфейсе
+		"clear.title":             "🗑️ Clear the database\n\n",
+		"clear.warning":           "⚠️  WARNING: This deletes ALL saved data!\n\n",
+		"clear.will_delete":       "The following will be deleted:\n",
+		"clear.item.measurements": "• All battery measurements\n",
+		"clear.item.states":       "• State history\n",
+		"clear.item.stats":        "• Usage statistics\n\n",
+		"clear.backup":            "💾 A copy of the database is saved to the backups folder first (restore: batmon db restore <path>)\n\n",
+		"clear.confirm":           "Press Y to confirm\n",
+		"clear.cancel":            "Press q or N to cancel",
+
+		// события питания
+		"power_event.sleep":       "Sleep",
+		"power_event.wake":        "Wake",
+		"power_event.dark_wake":   "Dark wake",
+		"power_event.battery":     "On battery power",
+		"power_event.ac":          "On AC power",
+		"sleep_wakes.summary":     "%s: −%d%% in %s, wakes: %d (dark: %d)",
+		"sleep_wakes.reason":      ", most often – %s",
+		"power_event.legend":      "z sleep  ↑ wake  · dark wake  ϟ power source",
+		"power_event.legend.note": "note",
+
+		// мощность SoC
+		"thermal_pressure.nominal":  "nominal",
+		"thermal_pressure.moderate": "moderate",
+		"thermal_pressure.heavy":    "heavy",
+		"thermal_pressure.critical": "critical",
+		"thermal_pressure.none":     "no data",
+		"soc_power.title":           "⚙️ SoC power (powermetrics)\n",
+		"soc_power.components":      "CPU: %s  GPU: %s  ANE: %s  Total: %s\n",
+		"soc_power.package":         "CPU package: %s\n",
+		"soc_power.battery":         "Battery output: %s, outside the SoC: %s\n",
+		"soc_power.pressure":        "Thermal pressure: %s",
+		"soc_power.watts":           "%.2f W",
+
+		// троттлинг
+		"throttling.period.pressure":     ", pressure %s",
+		"throttling.period.speed":        ", CPU speed up to %d%%",
+		"throttling.period.temp":         ", battery %.1f°C",
+		"throttling.none":                "No throttling (observed %s).",
+		"throttling.summary":             "Throttling %s of %s (%.0f%% of the time), periods: %d.",
+		"throttling.summary.temp":        " Battery at %.1f°C while throttled, %.1f°C otherwise.",
+		"throttling.summary.drain":       " Drain %.1f%%/h while throttled, %.1f%%/h otherwise.",
+		"throttling.verdict.cpu":         "Throttling happened at a normal battery temperature (%.1f°C): the Mac slowed down an overheated CPU, the battery is not to blame.",
+		"throttling.verdict.both":        "The battery is overheated while throttled too: the common cause is load and cooling. Such heat also speeds up battery wear, reduce the load and keep the vents clear.",
+		"throttling.verdict.drain":       "While throttled the battery drains %.1f times faster: the fast drain comes from load, not wear.",
+		"throttling.verdict.hot_battery": "The battery was above %d°C without throttling for %s: the battery or charging is heating up, not the CPU.",
+
+		// тепловая карта
+		"heatmap.empty":       "No battery usage data",
+		"heatmap.legend.rate": "drain rate",
+		"heatmap.legend.time": "time on battery",
+		"heatmap.less":        "\nless ",
+		"heatmap.more":        " more · color: %s (m – switch)",
+
+		// вехи износа
+		"wear_event.reached": "Battery wear reached %d%% (%d cycles)",
+		"wear_event.pace":    ": %d%% → %d%% in %d days and %d cycles",
+		"wear_event.alert":   "batmon: battery wear",
+		"wear_event.initial": "%d%% – start of observation",
+		"wear_event.step":    "%.0f days, %d cycles",
+
+		// нагрев при зарядке
+		"thermal.alert.title": "batmon: hot charging",
+
+		// периоды нагрева
+		"thermal_event.charging": " while charging",
+		"thermal_event.message":  "Prolonged heating%s: above %d°C for %s, peak %d°C (%s–%s)",
+
+		// расход в режиме сна по неделям
+		"standby.week": "%s %s %.1f%%/h\n",
+
+		// напоминание о калибровке
+		"calibration_cycle.test":      "🧪 calibration test",
+		"calibration_cycle.discharge": "🔋 discharge",
+		"calibration_cycle.row":       "%s: %d%% → %d%% in %s (%s)",
+		"calibration_reminder.alert":  "batmon: battery calibration",
+
+		// ошибки
+		"api.err.token_in_config":          "the token is set in %s – change it there",
+		"api.err.token_read":               "reading the API token: %w",
+		"api.err.token_create":             "creating the API token: %w",
+		"api.err.token_save":               "saving the API token: %w",
+		"api.err.auth_header":              "the Authorization: Bearer <token> header is required (batmon serve token)",
+		"api.err.auth_header_ws":           "the Authorization: Bearer <token> header or the access_token parameter is required",
+		"api.err.method":                   "method %s is not supported for %s, allowed: %s",
+		"api.err.not_found":                "unknown path %s %s",
+		"apps.err.no_processes":            "powermetrics: no process data",
+		"err.transaction":                  "transaction: %w",
+		"apps.err.save":                    "saving app samples: %w",
+		"apps.err.read":                    "app samples: %w",
+		"apps.err.measurements":            "measurements: %w",
+		"err.db_init":                      "database initialization: %w",
+		"backup.err.dir":                   "creating the backups folder: %w",
+		"backup.err.snapshot":              "database snapshot: %w",
+		"err.rename":                       "renaming to %s: %w",
+		"err.backup":                       "backup: %w",
+		"backup.err.open":                  "opening the backup: %w",
+		"backup.err.check":                 "checking the backup: %w",
+		"backup.err.corrupt":               "the backup is corrupted: %s",
+		"backup.err.foreign":               "%s does not look like a batmon database: no measurements table",
+		"backup.err.newer":                 "backup schema version %d is newer than supported (%d) - update batmon",
+		"backup.err.before":                "backup before %q: %w",
+		"err.db_connect":                   "database connection: %w",
+		"backup.err.read":                  "reading the backup: %w",
+		"backup.err.write":                 "writing the database: %w",
+		"err.remove":                       "removing %s: %w",
+		"backup.err.replace":               "replacing the database: %w",
+		"baseline.err.read":                "reading the baseline: %w",
+		"baseline.err.first":               "finding the first measurement: %w",
+		"baseline.err.write":               "writing the baseline: %w",
+		"identity.err.replacements":        "finding battery replacements: %w",
+		"bench.err.tmpdir":                 "temporary directory: %w",
+		"bench.err.cpuprofile":             "CPU profile: %w",
+		"bench.err.memprofile":             "memory profile: %w",
+		"caffeinate.err.state":             "caffeinate state file: %w",
+		"caffeinate.err.stop":              "stopping %s (PID %d): %w",
+		"calibration.err.read":             "reading the calibration test: %w",
+		"calibration.err.milestones":       "reading checkpoints: %w",
+		"calibration.err.running":          "a test is already running since %s",
+		"calibration.err.not_full":         "charge %d%%: charge the MacBook to 100%% before starting the test",
+		"calibration.err.create":           "creating the calibration test: %w",
+		"calibration.err.abort":            "aborting the calibration test: %w",
+		"calibration.err.pause":            "pausing the calibration test: %w",
+		"calibration.err.resume":           "resuming the calibration test: %w",
+		"calibration.err.not_paused":       "no paused test",
+		"calibration.err.finish":           "finishing the calibration test: %w",
+		"calibration.err.discharge_start":  "starting the discharge: %w",
+		"calibration.err.discharge_resume": "resuming the discharge: %w",
+		"calibration.err.macos":            "saving the macOS estimate: %w",
+		"calibration.err.milestone":        "checkpoint %d%%: %w",
+		"calibration.err.none_finished":    "no finished tests",
+		"err.data":                         "fetching data: %w",
+		"err.no_measurements_collect":      "no measurements – start data collection (batmon collect)",
+		"calibration.err.export":           "exporting the calibration report: %w",
+		"calibration.err.tests":            "reading calibration tests: %w",
+		"sessions.err.read":                "reading sessions: %w",
+		"err.first_measurement":            "reading the first measurement: %w",
+		"cert.err.save":                    "saving the certificate: %w",
+		"cert.err.read":                    "reading certificates: %w",
+		"cert.err.short_code":              "the checksum is too short",
+		"err.template":                     "parsing the template: %w",
+		"cert.err.export":                  "exporting the certificate: %w",
+		"cert.err.path":                    "could not determine the certificate path: %w",
+		"charger.err.read":                 "reading the adapter: %w",
+		"charger.err.list":                 "reading adapters: %w",
+		"charger.err.write":                "writing the adapter: %w",
+		"charging.err.curves":              "reading measurements for charge curves: %w",
+		"chart.err.not_enough":             "%s: not enough data for a chart",
+		"chart.err.write":                  "writing the chart: %w",
+		"err.export":                       "export: %w",
+		"cli.err.days":                     "--days must be positive",
+		"err.cleanup":                      "cleanup: %w",
+		"err.restore":                      "restore: %w",
+		"err.import":                       "import: %w",
+		"err.details":                      "detailed data: %w",
+		"mac.err.pmset":                    "scanning pmset: %w",
+		"mac.err.no_battery":               "battery data not found",
+		"mac.err.system_profiler":          "scanning system_profiler: %w",
+		"mac.err.ioreg_scan":               "scanning ioreg: %w",
+		"mac.err.ioreg_parse":              "parsing ioreg: %w",
+		"mac.err.ioreg_missing":            "parsing ioreg: AppleSmartBattery not found",
+		"replay.err.read":                  "reading the recording: %w",
+		"replay.err.parse":                 "parsing the recording %s: %w",
+		"replay.err.empty":                 "recording %s contains no samples",
+		"sysfs.err.search":                 "searching for a battery in sysfs: %w",
+		"sysfs.err.not_found":              "battery not found in %s",
+		"sysfs.err.capacity":               "sysfs: no capacity attribute in %s",
+		"sysfs.err.voltage":                "sysfs: no voltage to convert energy_* to mAh",
+		"sysfs.err.charge":                 "sysfs: no charge_now/energy_now attributes in %s",
+		"wmi.err.empty":                    "WMI: empty response, battery not found",
+		"wmi.err.parse":                    "parsing the WMI response: %w",
+		"wmi.err.no_charge":                "WMI: Win32_Battery returned no charge level",
+		"wmi.err.no_status":                "WMI: BatteryStatus is unavailable (no voltage)",
+		"config.err.read":                  "reading the config: %w",
+		"err.parse":                        "parsing %s: %w",
+		"config.err.marshal":               "serializing the config: %w",
+		"config.err.write":                 "writing the config: %w",
+		"network.err.disabled":             "%w: %s (enable network.%s in %s)",
+		"daily.err.read":                   "reading daily summaries: %w",
+		"daily.err.day":                    "parsing day %s: %w",
+		"daily.err.measurements":           "fetching measurements for summaries: %w",
+		"daily.err.transaction":            "summaries transaction: %w",
+		"daily.err.save":                   "saving the summary for %s: %w",
+		"metric.err.abs":                   "abs expects 1 argument",
+		"metric.err.min":                   "min expects at least 2 arguments",
+		"metric.err.max":                   "max expects at least 2 arguments",
+		"metric.err.name":                  "metric %q: the name must consist of lowercase Latin letters, digits and _",
+		"metric.err.field_name":            "metric %q: the name matches a measurement field",
+		"err.metric":                       "metric %q: %w",
+		"metric.err.duplicate":             "metric %q is declared more than once",
+		"metric.err.save":                  "saving metric %s: %w",
+		"metric.err.read":                  "reading derived metrics: %w",
+		"metric.err.empty":                 "empty expression",
+		"metric.err.extra":                 "unexpected character %q at position %d",
+		"metric.err.eof":                   "unexpected end of expression",
+		"metric.err.paren":                 "no closing parenthesis at position %d",
+		"metric.err.number":                "invalid number %q",
+		"metric.err.field":                 "unknown field %q (available: %s)",
+		"metric.err.char":                  "unexpected character %q at position %d",
+		"metric.err.func":                  "unknown function %q (available: abs, min, max)",
+		"metric.err.comma":                 "comma or parenthesis expected at position %d",
+		"email.err.no_email":               "--email is not set",
+		"email.err.no_smtp":                "--smtp host:port is not set",
+		"email.err.no_from":                "no sender: set --from-addr or --smtp-user",
+		"email.err.smtp_addr":              "SMTP address %q: %w",
+		"email.err.tmpdir":                 "temporary folder: %w",
+		"email.err.read_report":            "reading the report: %w",
+		"email.err.compose":                "composing the email: %w",
+		"email.err.send":                   "sending the email: %w",
+		"err.connect":                      "connecting to %s: %w",
+		"email.err.auth":                   "SMTP authentication: %w",
+		"email.err.recipient":              "recipient %s: %w",
+		"email.err.schedule_read":          "reading the report schedule: %w",
+		"email.err.schedule_save":          "saving the report schedule: %w",
+		"atomic.err.tmp":                   "creating a temporary file: %w",
+		"atomic.err.sync":                  "flushing to disk: %w",
+		"atomic.err.close":                 "closing the temporary file: %w",
+		"atomic.err.verify":                "checking the report: %w",
+		"atomic.err.chmod":                 "file permissions: %w",
+		"atomic.err.no_title":              "no report title",
+		"atomic.err.truncated":             "the report is truncated",
+		"atomic.err.no_doctype":            "no document declaration",
+		"atomic.err.doc_truncated":         "the document is truncated",
+		"err.db_open":                      "connecting to the database: %w",
+		"export.err.json":                  "writing JSON: %w",
+		"export.err.json_broken":           "JSON is corrupted",
+		"export.err.csv":                   "writing CSV: %w",
+		"export.err.dir":                   "creating the export folder: %w",
+		"fleet.err.not_status":             "no timestamp field – this is not batmon status --json output",
+		"fleet.err.json":                   "%s: parsing JSON: %w",
+		"fleet.err.save":                   "saving snapshot %s: %w",
+		"fleet.err.read":                   "reading the fleet: %w",
+		"fleet.err.sort":                   "unknown sort order %q (wear, cycles, health, host)",
+		"fleet.err.export":                 "exporting the fleet report: %w",
+		"fleet.err.remove":                 "removing snapshots: %w",
+		"fleet.err.no_host":                "machine %q is not in the fleet",
+		"filter.err.operator":              "condition %q: an operator is required (=, !=, <, <=, >, >=)",
+		"filter.err.use_eq":                "condition %q: use = for %s",
+		"filter.err.time_op":               "condition %q: time is compared with <, <=, >, >=",
+		"filter.err.condition":             "condition %q: %w",
+		"filter.err.field":                 "unknown field %q (available: %s, from, to, time)",
+		"filter.err.text_op":               "condition %q: a text field is compared with = or !=",
+		"filter.err.number":                "condition %q: %q is not a number",
+		"history.err.count":                "counting history: %w",
+		"history.err.read":                 "reading history: %w",
+		"hooks.err.daily":                  "reading the summary for %s: %w",
+		"import.err.current":               "%s is the current database",
+		"import.err.attach":                "attaching %s: %w",
+		"err.read":                         "reading %s: %w",
+		"import.err.transaction":           "import transaction: %w",
+		"import.err.measurements":          "importing measurements: %w",
+		"import.err.reset":                 "resetting derived data: %w",
+		"import.err.columns_current":       "columns of the current database: %w",
+		"import.err.columns_source":        "columns of the imported database: %w",
+		"import.err.no_timestamp":          "the imported database has no timestamp column",
+		"analysis.err.read":                "reading the analysis state: %w",
+		"analysis.err.marshal":             "serializing the analysis state: %w",
+		"analysis.err.save":                "saving the analysis state: %w",
+		"analysis.err.new":                 "reading new measurements: %w",
+		"measurement.err.read":             "reading a measurement: %w",
+		"db.err.optimize":                  "database optimization: %w",
+		"db.err.indexes":                   "creating indexes: %w",
+		"iokit.err.no_internal":            "IOPowerSources: internal battery not found",
+		"iokit.err.no_smart_battery":       "IOKit: AppleSmartBattery not found",
+		"log.err.level":                    "unknown log level %q (debug, info, warn, error)",
+		"log.err.file":                     "log file: %w",
+		"logs.err.no_dir":                  "the data folder is unavailable",
+		"err.home":                         "could not get the home folder: %w",
+		"err.data_dir":                     "could not create the data folder: %w",
+		"db.err.load":                      "loading from the database: %w",
+		"db.err.migrate":                   "schema migration: %w",
+		"report.err.no_data_range":         "no data for the period: %s",
+		"report.err.no_data":               "no data for the report",
+		"err.collect":                      "collecting data %s: %w",
+		"db.err.stats":                     "database statistics: %w",
+		"err.history_data":                 "fetching historical data: %w",
+		"err.status":                       "fetching the status: %w",
+		"report.err.print":                 "printing the report: %w",
+		"report.err.generate":              "generating report data: %w",
+		"export.err.md_path":               "could not determine the Markdown file path: %w",
+		"export.err.md":                    "exporting to Markdown: %w",
+		"export.err.html_path":             "could not determine the HTML file path: %w",
+		"export.err.html":                  "exporting to HTML: %w",
+		"err.db_connect_failed":            "database connection error: %w",
+		"err.generate_failed":              "data generation error: %w",
+		"replay.err.no_clear":              "clearing is unavailable in recording replay mode",
+		"db.err.reinit":                    "could not reinitialize the database: %v",
+		"store.err.transaction":            "write transaction: %w",
+		"store.err.prepare":                "preparing the measurement insert: %w",
+		"store.err.save":                   "saving measurement %s: %w",
+		"store.err.batch":                  "writing a measurement batch: %w",
+		"store.err.cleanup":                "cleaning up old data: %w",
+		"store.err.count":                  "counting records: %w",
+		"migrate.err.table":                "creating the schema version table: %w",
+		"migrate.err.version":              "reading the schema version: %w",
+		"migrate.err.versions":             "reading schema versions: %w",
+		"migrate.err.newer":                "database schema version %d is newer than supported (%d) - update batmon",
+		"migrate.err.unknown":              "unknown schema version %d (available 0-%d)",
+		"migrate.err.step":                 "migration %d (%s): %w",
+		"migrate.err.record":               "migration %d (%s): recording the version: %w",
+		"net.err.disabled":                 "network context is disabled until restart: %w",
+		"net.err.unsupported":              "network counters are not supported on %s",
+		"net.err.save":                     "saving the network context: %w",
+		"net.err.read":                     "reading the network context: %w",
+		"note.err.save":                    "saving the note: %w",
+		"note.err.read":                    "reading notes: %w",
+		"note.err.no_session":              "session %d not found (numbers are on the report's Sessions tab)",
+		"note.err.delete":                  "deleting the note: %w",
+		"note.err.not_found":               "note %d not found",
+		"plist.err.no_value":               "plist: no value",
+		"power_events.err.pmset":           "pmset log: %w",
+		"power_events.err.read":            "reading power events: %w",
+		"power_events.err.transaction":     "power events transaction: %w",
+		"power_events.err.save":            "saving a power event: %w",
+		"powermetrics.err.no_power":        "powermetrics: no power data",
+		"powermetrics.err.save":            "saving a powermetrics sample: %w",
+		"powermetrics.err.read":            "reading a powermetrics sample: %w",
+		"err.open":                         "opening %s: %w",
+		"replay.err.measurements":          "reading measurements from %s: %w",
+		"replay.err.no_measurements":       "%s contains no measurements",
+		"err.tui":                          "starting the interface: %w",
+		"range.err.parse":                  "could not parse time %q (examples: 7d, 24h, 14:00, 2025-01-31, \"2025-01-31 18:00\")",
+		"range.err.order":                  "the period start must be before its end",
+		"rollup.err.hourly":                "hourly rollup of measurements: %w",
+		"rollup.err.monthly":               "capacity by month: %w",
+		"rule.err.empty":                   "rule %q: empty condition",
+		"rule.err.parse":                   "rule %q: could not parse condition %q (expected \"metric operator number\")",
+		"rule.err.metric":                  "rule %q: unknown metric %q (available: %s)",
+		"rule.err.number":                  "rule %q: invalid number %q",
+		"rule.err.template":                "rule %q: message template: %w",
+		"rule.err.invalid":                 "rules with errors: %d – they are not applied",
+		"sampling.err.policy":              "unknown sampling policy %q, using %s",
+		"schema.err.tables":                "reading the table list: %w",
+		"schema.err.columns":               "reading columns of %s: %w",
+		"schema.err.indexes":               "reading indexes of %s: %w",
+		"schema.err.index":                 "reading index %s: %w",
+		"serve.err.addr":                   "address %q: %w",
+		"serve.err.loopback":               "address %q: only 127.0.0.1, ::1 and localhost are allowed",
+		"serve.err.unversioned":            "unversioned paths are only available from this machine via 127.0.0.1 or localhost – use /api/v1 with a token",
+		"serve.err.no_measurements":        "no measurements",
+		"serve.err.limit":                  "limit must be between 1 and %d",
+		"serve.err.streaming":              "streaming is not supported",
+		"serve.err.remote":                 "%w; add --remote to listen on the network",
+		"sessions.err.measurements":        "fetching measurements for sessions: %w",
+		"sessions.err.transaction":         "sessions transaction: %w",
+		"sessions.err.delete_open":         "deleting the open session: %w",
+		"sessions.err.save":                "saving the session: %w",
+		"db.err.clear":                     "database cleanup error: %v",
+		"shutdown.err.timeout":             "did not finish within %v: %w",
+		"snapshot.err.rate":                "drain rate: %w",
+		"snapshot.err.save":                "saving the snapshot: %w",
+		"snapshot.err.not_found_hint":      "snapshot %q not found (list: batmon snapshot list)",
+		"snapshot.err.read":                "reading the snapshot: %w",
+		"snapshot.err.list":                "reading snapshots: %w",
+		"snapshot.err.delete":              "deleting the snapshot: %w",
+		"snapshot.err.not_found":           "snapshot %q not found",
+		"standby.err.measurements":         "reading measurements for sleep analysis: %w",
+		"store.err.checkpoint":             "WAL checkpoint: %w",
+		"store.err.close":                  "closing the database: %w",
+		"sync.err.read_lock":               "reading the lock: %w",
+		"sync.err.in_use":                  "the database is in use on %s (updated %s)",
+		"sync.err.lock":                    "database lock: %w",
+		"theme.err.unknown":                "unknown theme %q (available: %s)",
+		"theme.err.role":                   "unknown color role %q in the theme",
+		"thermal.err.read":                 "reading temperature: %w",
+		"thermal_events.err.read":          "reading thermal events: %w",
+		"thermal_events.err.measurements":  "fetching measurements for thermal events: %w",
+		"thermal_events.err.transaction":   "thermal events transaction: %w",
+		"thermal_events.err.delete_open":   "deleting the open thermal event: %w",
+		"thermal_events.err.save":          "saving a thermal event: %w",
+		"throttling.err.save":              "saving thermal pressure: %w",
+		"throttling.err.read":              "reading thermal pressure: %w",
+		"wear_events.err.read":             "reading wear milestones: %w",
+		"wear_events.err.measurements":     "fetching measurements for wear milestones: %w",
+		"wear_events.err.save":             "saving a wear milestone: %w",
+		"webhook.err.template":             "webhook template: %w",
+		"webhook.err.not_json":             "the webhook template does not produce JSON: %s",
+		"err.webhook":                      "webhook %s: %w",
+		"webhook.err.status":               "webhook %s: %s",
+		"alert_level.err.read":             "reading the alert level: %w",
+		"alert_level.err.save":             "saving the alert level: %w",
+		"queue.err.save":                   "saving to the database: %w",
+
+		// ошибки-метки
+		"cli.err.usage":          "invalid arguments",
+		"cli.err.help_shown":     "help shown",
+		"network.err.off":        "network feature is disabled",
+		"iokit.err.unavailable":  "IOKit is unavailable in this build",
+		"shutdown.err.timed_out": "timed out",
+
+		// расход по приложениям
+		"apps.period.week":  "for the week",
+		"apps.period.range": "for the period",
+		"apps.top_item":     "%s (%.0f mAh)",
+		"apps.title":        "🔌 Battery usage by app: %s – %s",
+		"apps.empty":        "No app samples for this period (they are taken every 5 minutes on battery power)",
+		"apps.col.app":      "App",
+		"apps.col.wh":       "Wh",
+
+		// разбаланс ячеек
+		"anomaly.cell_imbalance": "Cell imbalance: spread up to %d mV (%s mV), %d measurements (%s–%s)",
+
+		// адаптеры питания
+		"charger.default_name": "adapter",
+		"charger.watts":        "%s %d W",
+		"charger.third_party":  " (third-party)",
+
+		// сетевые функции
+		"network.feature.upload":       "upload",
+		"network.feature.mqtt":         "MQTT",
+		"network.feature.webhooks":     "webhooks",
+		"network.feature.update_check": "update check",
+		"network.feature.email":        "email",
+		"network.status.offline":       "network: fully offline",
+		"network.status.allowed":       "network: allowed – %s",
+
+		// производные метрики
+		"metrics.none":    "No derived metrics are set. Add them to %s, for example:",
+		"metrics.example": "  \"metrics\": [{\"name\": \"watts\", \"expr\": \"voltage*amperage/1e6\", \"unit\": \"W\"}]",
+		"metrics.fields":  "Fields: %s",
+		"metrics.no_data": "no data",
+
+		// отчет по почте
+		"email.sent":                 "✅ Report sent: %s",
+		"email.schedule.off":         "📭 Scheduled report is off",
+		"email.schedule.weekly":      "weekly",
+		"email.schedule.daily":       "daily",
+		"email.schedule.on":          "📬 Report %s to %s via %s",
+		"email.schedule.network_off": "⚠️ Sending is off: enable network.%s in %s",
+
+		// сетевой контекст
+		"net_context.traffic":   "coincided with network traffic of %s/s",
+		"net_context.bluetooth": "Bluetooth devices: %d",
+
+		// запрет засыпания
+		"platform.inhibit_why": "Battery measurement",
+
+		// воспроизведение
+		"replay.title": "⏯️ BatMon - replay %s (x%g)",
+
+		// пресеты периода
+		"range.preset.last": "latest measurements",
+		"range.preset.24h":  "24 hours",
+		"range.preset.7d":   "7 days",
+		"range.preset.30d":  "30 days",
+		"range.preset.all":  "all time",
+
+		// HTTP API
+		"serve.token_created": "🔑 API token created (%s): %s",
+
+		// сессии
+		"session.kind.charge":    "🔌 Charging",
+		"session.kind.discharge": "🔋 Discharging",
+
+		// оповещения о состоянии
+		"webhook.health_alert": "batmon: battery health",
+
+		// названия миграций
+		"migration.1":  "measurements",
+		"migration.2":  "voltage, current and power",
+		"migration.3":  "battery serial number",
+		"migration.4":  "usage by app",
+		"migration.5":  "sessions",
+		"migration.6":  "calibration",
+		"migration.7":  "baseline",
+		"migration.8":  "custom metrics",
+		"migration.9":  "powermetrics",
+		"migration.10": "brightness and lid",
+		"migration.11": "calibration pause",
+		"migration.12": "power adapters",
+		"migration.13": "daily summaries",
+		"migration.14": "analysis state",
+		"migration.15": "hourly summaries",
+		"migration.16": "time indexes",
+		"migration.17": "thermal events",
+		"migration.18": "state snapshots",
+		"migration.19": "cell voltages",
+		"migration.20": "wear milestones",
+		"migration.21": "fleet",
+		"migration.22": "power events",
+		"migration.23": "thermal pressure",
+		"migration.24": "network context",
+		"migration.25": "notes",
+		"migration.26": "issued certificates",
+		"migration.27": "manufacture date in certificates",
+	},
+}
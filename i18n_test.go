@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/fatih/color"
+)
+
+// formatVerb – глагол fmt; %% совпадает целиком, чтобы не принять "%% of" за
+// "% o". Флаг-пробел в каталоге не используется: "100% of" – это просто текст.
+var formatVerb = regexp.MustCompile(`%%|%[-+#0]*(\d+|\*)?(\.\d+)?[a-zA-Z]`)
+
+// У каждого русского сообщения есть перевод с теми же глаголами формата
+func TestMessagesParity(t *testing.T) {
+	ru, en := messages[localeRU], messages[localeEN]
+	for id, msg := range ru {
+		tr, ok := en[id]
+		if !ok {
+			t.Errorf("%s: нет перевода", id)
+			continue
+		}
+		if a, b := formatVerbs(msg), formatVerbs(tr); !slices.Equal(a, b) {
+			t.Errorf("%s: глаголы %v, в переводе %v", id, a, b)
+		}
+	}
+	for id, msg := range en {
+		if strings.ContainsFunc(msg, isCyrillic) {
+			t.Errorf("%s: русский текст в переводе: %q", id, msg)
+		}
+	}
+}
+
+// formatVerbs возвращает глаголы формата сообщения по порядку
+func formatVerbs(msg string) []string {
+	var verbs []string
+	for _, v := range formatVerb.FindAllString(msg, -1) {
+		if v != "%%" {
+			verbs = append(verbs, v)
+		}
+	}
+	return verbs
+}
+
+func isCyrillic(r rune) bool { return unicode.Is(unicode.Cyrillic, r) }
+
+// runCLIOutput выполняет команду batmon и возвращает ее stdout и stderr вместе
+func runCLIOutput(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	origStdout, origStderr, origColor, origColorErr := os.Stdout, os.Stderr, color.Output, color.Error
+	os.Stdout, os.Stderr, color.Output, color.Error = w, w, w, w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	_, code := runCLI(args)
+	os.Stdout, os.Stderr, color.Output, color.Error = origStdout, origStderr, origColor, origColorErr
+	w.Close()
+	return string(<-done), code
+}
+
+// Основные команды на английском не выводят русских строк – ни в терминал,
+// ни в файлы экспорта. Журнал остается русским и в проверку не входит.
+func TestCommandsEnglish(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(24*time.Hour))
+	t.Setenv("LANG", "en_US.UTF-8")
+	dir := useTestDataDir(t)
+	useTestLogger(t, 1<<20, 1)
+	dbPath := getDBPath()
+	db, err := initDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insertFixture(t, db, steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 48))
+	db.Close()
+
+	md, html, cert, svg := filepath.Join(dir, "report.md"), filepath.Join(dir, "report.html"),
+		filepath.Join(dir, "certificate.html"), filepath.Join(dir, "chart.svg")
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"version"}, 0},
+		{[]string{"--help"}, 0},
+		{[]string{"report"}, 0},
+		{[]string{"report", "--from", "yesterday"}, 1},
+		{[]string{"check"}, 0},
+		{[]string{"doctor"}, -1}, // утилиты и caffeinate зависят от машины
+		{[]string{"calibration", "status"}, 0},
+		{[]string{"db", "stats"}, 0},
+		{[]string{"db", "version"}, 0},
+		{[]string{"schema"}, 0},
+		{[]string{"rules"}, 0},
+		{[]string{"metrics"}, 0},
+		{[]string{"apps"}, 0},
+		{[]string{"note", "add", "--at", "10:00", "unplugged"}, 0},
+		{[]string{"note", "list"}, 0},
+		{[]string{"note", "delete", "99"}, 1},
+		{[]string{"snapshot", "save", "before"}, 0},
+		{[]string{"snapshot", "list"}, 0},
+		{[]string{"snapshot", "compare", "before"}, 0},
+		{[]string{"fleet", "report"}, 0},
+		{[]string{"export", "--quiet", "--md", md, "--html", html, "--certificate", cert}, 0},
+		{[]string{"chart", "--from", "2025-03-03", "--out", svg}, 0},
+		{[]string{"nonexistent"}, 2},
+	}
+	for _, c := range cases {
+		out, code := runCLIOutput(t, append([]string{"--db", dbPath}, c.args...)...)
+		name := strings.Join(c.args, " ")
+		if c.code >= 0 && code != c.code {
+			t.Errorf("%s: код выхода %d, ожидался %d:\n%s", name, code, c.code, out)
+		}
+		assertNoCyrillic(t, name, out)
+	}
+	for _, path := range []string{md, html, cert, svg} {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("экспорт: %v", err)
+			continue
+		}
+		assertNoCyrillic(t, filepath.Base(path), string(raw))
+	}
+}
+
+func assertNoCyrillic(t *testing.T, name, out string) {
+	t.Helper()
+	for _, line := range strings.Split(out, "\n") {
+		if strings.ContainsFunc(line, isCyrillic) {
+			t.Errorf("%s: русская строка: %q", name, line)
+		}
+	}
+}
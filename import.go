@@ -0,0 +1,172 @@
+// import.go
+//
+// Слияние с другой базой batmon (`batmon db import`): измерения старого
+// ноутбука или резервной копии переносятся в текущую базу, совпадающие по
+// времени пропускаются. Сессии, сводки по дням и анализ истории после
+// импорта пересчитываются – иначе они не увидят добавленное прошлое.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ImportResult – итог импорта
+type ImportResult struct {
+	Total      int    // измерений в источнике
+	Imported   int    // добавлено
+	Duplicates int    // пропущено: такое время уже есть
+	From, To   string // период источника, RFC3339 UTC
+}
+
+// importDatabase добавляет в db измерения из базы src. Источник открывается
+// только для чтения; берутся столбцы, общие для обеих схем, поэтому базы
+// старых версий тоже подходят.
+func importDatabase(db *sqlx.DB, src string) (*ImportResult, error) {
+	if err := verifyBackup(src); err != nil {
+		return nil, err
+	}
+	if a, b := absPath(src), absPath(getDBPath()); a == b {
+		return nil, fmt.Errorf(T("import.err.current"), src)
+	}
+
+	ctx := context.Background()
+	// ATTACH действует на одно соединение и не выполняется внутри транзакции
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(T("err.db_connect"), err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS src", src); err != nil {
+		return nil, fmt.Errorf(T("import.err.attach"), src, err)
+	}
+	defer conn.ExecContext(ctx, "DETACH DATABASE src")
+
+	columns, err := commonMeasurementColumns(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	if err := conn.QueryRowxContext(ctx, `SELECT COUNT(*), COALESCE(MIN(timestamp), ''), COALESCE(MAX(timestamp), '')
+		FROM src.measurements`).Scan(&result.Total, &result.From, &result.To); err != nil {
+		return nil, fmt.Errorf(T("err.read"), src, err)
+	}
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf(T("import.err.transaction"), err)
+	}
+	defer tx.Rollback()
+
+	names := make([]string, len(columns))
+	values := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+		values[i] = c.Value()
+	}
+	res, err := tx.Exec(fmt.Sprintf(`INSERT INTO main.measurements (%s)
+		SELECT %s FROM src.measurements
+		WHERE timestamp NOT IN (SELECT timestamp FROM main.measurements)
+		GROUP BY timestamp ORDER BY timestamp`, strings.Join(names, ", "), strings.Join(values, ", ")))
+	if err != nil {
+		return nil, fmt.Errorf(T("import.err.measurements"), err)
+	}
+	imported, _ := res.RowsAffected()
+	result.Imported = int(imported)
+	result.Duplicates = result.Total - result.Imported
+
+	if result.Imported > 0 {
+		// Производные данные пересчитываются с нуля; базовая точка батареи,
+		// записанная позже первого импортированного измерения, создается заново
+		resets := []string{
+			`DELETE FROM sessions`,
+			`DELETE FROM thermal_events`,
+			`DELETE FROM daily_usage`,
+			`DELETE FROM analysis_state WHERE name = 'history'`,
+			`DELETE FROM battery_baseline WHERE recorded_at > (SELECT MIN(timestamp) FROM main.measurements m
+				WHERE m.battery_serial = battery_baseline.battery_serial AND m.full_charge_capacity > 0)`,
+		}
+		for _, query := range resets {
+			if _, err := tx.Exec(query); err != nil {
+				return nil, fmt.Errorf(T("import.err.reset"), err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf(T("import.err.measurements"), err)
+	}
+	if result.Imported == 0 {
+		return result, nil
+	}
+
+	if err := syncSessions(db); err != nil {
+		return result, err
+	}
+	if err := syncThermalEvents(db); err != nil {
+		return result, err
+	}
+	if err := syncDailyUsage(db); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// importColumn – столбец measurements, общий для обеих баз
+type importColumn struct {
+	Name string `db:"name"`
+	Type string `db:"type"`
+}
+
+// Value возвращает выражение для столбца: в старых схемах столбцы допускали
+// NULL, который не читается в поля Measurement
+func (c importColumn) Value() string {
+	switch {
+	case c.Name == "timestamp":
+		return c.Name
+	case strings.EqualFold(c.Type, "TEXT"):
+		return fmt.Sprintf("COALESCE(%s, '')", c.Name)
+	}
+	return fmt.Sprintf("COALESCE(%s, 0)", c.Name)
+}
+
+// commonMeasurementColumns возвращает столбцы measurements, общие для
+// текущей и подключенной базы, кроме id
+func commonMeasurementColumns(ctx context.Context, conn *sqlx.Conn) ([]importColumn, error) {
+	var mainCols []importColumn
+	var srcCols []string
+	if err := conn.SelectContext(ctx, &mainCols, "SELECT name, type FROM pragma_table_info('measurements', 'main')"); err != nil {
+		return nil, fmt.Errorf(T("import.err.columns_current"), err)
+	}
+	if err := conn.SelectContext(ctx, &srcCols, "SELECT name FROM pragma_table_info('measurements', 'src')"); err != nil {
+		return nil, fmt.Errorf(T("import.err.columns_source"), err)
+	}
+	inSrc := make(map[string]bool, len(srcCols))
+	for _, c := range srcCols {
+		inSrc[c] = true
+	}
+	var columns []importColumn
+	for _, c := range mainCols {
+		if c.Name != "id" && inSrc[c.Name] {
+			columns = append(columns, c)
+		}
+	}
+	if !inSrc["timestamp"] {
+		return nil, errors.New(T("import.err.no_timestamp"))
+	}
+	return columns, nil
+}
+
+// absPath возвращает абсолютный путь или исходный при ошибке
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Импорт пропускает измерения, время которых уже есть в базе, а повторный
+// импорт ничего не добавляет
+func TestImportDatabase(t *testing.T) {
+	const step = 10 * time.Minute
+	freezeEnvironment(t, fixtureStart.Add(48*time.Hour))
+	db, _ := newFileTestDB(t, "batmon.sqlite")
+	live := steadyDischarge(fixtureHealthyBattery, fixtureStart.Add(24*time.Hour), step, 6)
+	insertFixture(t, db, live)
+
+	src, srcPath := newFileTestDB(t, "old.sqlite")
+	old := steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 10)
+	insertFixture(t, src, append(old, live[0], live[1]))
+	src.Close()
+
+	result, err := importDatabase(db, srcPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 12 || result.Imported != 10 || result.Duplicates != 2 ||
+		result.From != old[0].Timestamp || result.To != live[1].Timestamp {
+		t.Errorf("итог импорта: %+v", result)
+	}
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM measurements`); err != nil || count != 16 {
+		t.Errorf("в базе %d измерений, %v", count, err)
+	}
+
+	again, err := importDatabase(db, srcPath)
+	if err != nil || again.Imported != 0 || again.Duplicates != 12 {
+		t.Errorf("повторный импорт: %+v, %v", again, err)
+	}
+}
+
+// Битые и чужие файлы не импортируются, база не меняется
+func TestImportDatabaseMalformed(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(48*time.Hour))
+	db, _ := newFileTestDB(t, "batmon.sqlite")
+	insertFixture(t, db, steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 3))
+	dir := t.TempDir()
+
+	text := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(text, []byte("это не база SQLite\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	foreign := filepath.Join(dir, "foreign.sqlite")
+	noTimestamp := filepath.Join(dir, "no_timestamp.sqlite")
+	for path, schema := range map[string]string{
+		foreign:     `CREATE TABLE readings (id INTEGER, value REAL)`,
+		noTimestamp: `CREATE TABLE measurements (id INTEGER PRIMARY KEY, percentage INTEGER)`,
+	} {
+		other, err := sqlx.Connect("sqlite3", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := other.Exec(schema); err != nil {
+			t.Fatal(err)
+		}
+		other.Close()
+	}
+
+	cases := map[string]string{
+		filepath.Join(dir, "missing.sqlite"): "резервная копия",
+		text:                                 "",
+		foreign:                              "нет таблицы measurements",
+		noTimestamp:                          "нет столбца timestamp",
+	}
+	for path, want := range cases {
+		if _, err := importDatabase(db, path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: ошибка %v, ожидалась %q", filepath.Base(path), err, want)
+		}
+	}
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM measurements`); err != nil || count != 3 {
+		t.Errorf("после неудачного импорта в базе %d измерений, %v", count, err)
+	}
+}
+
+// Потоки SSE и WebSocket не выдают импортированное прошлое за новые измерения
+func TestStreamSkipsImportedHistory(t *testing.T) {
+	const step = 10 * time.Minute
+	freezeEnvironment(t, fixtureStart.Add(48*time.Hour))
+	db, _ := newFileTestDB(t, "batmon.sqlite")
+	live := steadyDischarge(fixtureHealthyBattery, fixtureStart.Add(24*time.Hour), step, 4)
+	insertFixture(t, db, live[:3])
+
+	cursor, err := streamStart(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, err := getMeasurementsAfter(db, cursor, streamMaxBatchSize)
+	if err != nil || len(ms) != 1 || ms[0].Timestamp != live[2].Timestamp {
+		t.Fatalf("при подключении: %+v, %v", ms, err)
+	}
+	cursor.advance(ms[0])
+	resumeID := ms[0].ID
+
+	src, srcPath := newFileTestDB(t, "old.sqlite")
+	insertFixture(t, src, steadyDischarge(fixtureHealthyBattery, fixtureStart, step, 5))
+	src.Close()
+	if result, err := importDatabase(db, srcPath); err != nil || result.Imported != 5 {
+		t.Fatalf("импорт: %+v, %v", result, err)
+	}
+
+	if ms, err := getMeasurementsAfter(db, cursor, streamMaxBatchSize); err != nil || len(ms) != 0 {
+		t.Errorf("импортированные измерения ушли в поток: %+v, %v", ms, err)
+	}
+
+	insertFixture(t, db, live[3:])
+	ms, err = getMeasurementsAfter(db, cursor, streamMaxBatchSize)
+	if err != nil || len(ms) != 1 || ms[0].Timestamp != live[3].Timestamp {
+		t.Errorf("новое измерение: %+v, %v", ms, err)
+	}
+
+	// Переподключение с id последнего полученного измерения
+	resumed, err := streamStart(db, strconv.Itoa(resumeID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, err = getMeasurementsAfter(db, resumed, streamMaxBatchSize)
+	if err != nil || len(ms) != 1 || ms[0].Timestamp != live[3].Timestamp {
+		t.Errorf("после переподключения: %+v, %v", ms, err)
+	}
+}
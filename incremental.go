@@ -0,0 +1,305 @@
+// incremental.go
+//
+// Инкрементальный анализ всей истории: скользящие средние, окно последних
+// интервалов разрядки и потоковая регрессия ёмкости обновляются по одному
+// измерению за раз. Состояние хранится в БД, поэтому отчет дочитывает только
+// измерения, появившиеся после последнего обновления, и не держит в памяти
+// годовую историю.
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	historyRateWindow   = 10               // интервалов в скользящем окне скорости разрядки
+	historySaveInterval = 10 * time.Minute // как часто коллектор сохраняет состояние
+)
+
+// analysisStateSchema – сохраненные состояния инкрементального анализа
+const analysisStateSchema = `
+CREATE TABLE IF NOT EXISTS analysis_state (
+	name TEXT PRIMARY KEY,
+	state TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);`
+
+// RunningStats – среднее, дисперсия и экстремумы без хранения выборки (алгоритм Уэлфорда)
+type RunningStats struct {
+	N    int     `json:"n"`
+	Mean float64 `json:"mean"`
+	M2   float64 `json:"m2"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+}
+
+// Add учитывает новое значение
+func (s *RunningStats) Add(x float64) {
+	s.N++
+	if s.N == 1 {
+		s.Min, s.Max = x, x
+	}
+	s.Min = math.Min(s.Min, x)
+	s.Max = math.Max(s.Max, x)
+	delta := x - s.Mean
+	s.Mean += delta / float64(s.N)
+	s.M2 += delta * (x - s.Mean)
+}
+
+// Std возвращает выборочное стандартное отклонение
+func (s RunningStats) Std() float64 {
+	if s.N < 2 {
+		return 0
+	}
+	return math.Sqrt(s.M2 / float64(s.N-1))
+}
+
+// StreamingRegression – линейная регрессия y = a + b·x по накопленным суммам
+type StreamingRegression struct {
+	N     int     `json:"n"`
+	SumX  float64 `json:"sum_x"`
+	SumY  float64 `json:"sum_y"`
+	SumXX float64 `json:"sum_xx"`
+	SumXY float64 `json:"sum_xy"`
+}
+
+// Add учитывает точку
+func (r *StreamingRegression) Add(x, y float64) {
+	r.N++
+	r.SumX += x
+	r.SumY += y
+	r.SumXX += x * x
+	r.SumXY += x * y
+}
+
+// Slope возвращает наклон; ok=false, пока точек меньше двух или все x совпадают
+func (r StreamingRegression) Slope() (float64, bool) {
+	n := float64(r.N)
+	den := n*r.SumXX - r.SumX*r.SumX
+	if r.N < 2 || den == 0 {
+		return 0, false
+	}
+	return (n*r.SumXY - r.SumX*r.SumY) / den, true
+}
+
+// rateWindow – кольцевой буфер последних интервалов разрядки с текущими суммами
+type rateWindow struct {
+	Drops    []float64 `json:"drops"` // мАч
+	Hours    []float64 `json:"hours"`
+	Next     int       `json:"next"`
+	SumDrop  float64   `json:"sum_drop"`
+	SumHours float64   `json:"sum_hours"`
+}
+
+// Add добавляет интервал, вытесняя самый старый при заполненном окне
+func (w *rateWindow) Add(drop, hours float64) {
+	if len(w.Drops) < historyRateWindow {
+		w.Drops = append(w.Drops, drop)
+		w.Hours = append(w.Hours, hours)
+	} else {
+		w.SumDrop -= w.Drops[w.Next]
+		w.SumHours -= w.Hours[w.Next]
+		w.Drops[w.Next], w.Hours[w.Next] = drop, hours
+		w.Next = (w.Next + 1) % historyRateWindow
+	}
+	w.SumDrop += drop
+	w.SumHours += hours
+}
+
+// Rate возвращает среднюю скорость разрядки по окну, мАч/ч
+func (w rateWindow) Rate() float64 {
+	if w.SumHours <= 0 {
+		return 0
+	}
+	return w.SumDrop / w.SumHours
+}
+
+// HistoryAnalysis – накопленный анализ всей истории текущей батареи.
+// Add обновляет его за O(1) на измерение.
+type HistoryAnalysis struct {
+	Serial         string              `json:"serial"` // батарея; при замене статистика начинается заново
+	FirstTimestamp string              `json:"first_timestamp"`
+	LastTimestamp  string              `json:"last_timestamp"`
+	Count          int                 `json:"count"`
+	Anomalies      int                 `json:"anomalies"`
+	Window         rateWindow          `json:"window"`      // последние интервалы разрядки
+	Discharge      RunningStats        `json:"discharge"`   // скорость разрядки по всем интервалам, мАч/ч
+	Temperature    RunningStats        `json:"temperature"` // °C
+	Capacity       StreamingRegression `json:"capacity"`    // полная ёмкость (мАч) от дней наблюдения
+	Prev           *Measurement        `json:"prev,omitempty"`
+}
+
+// Add учитывает новое измерение. Уже учтенные (не новее последнего) пропускаются,
+// поэтому одно и то же измерение можно передать повторно.
+func (h *HistoryAnalysis) Add(m Measurement) {
+	if m.Timestamp <= h.LastTimestamp {
+		return
+	}
+	if m.BatterySerial != "" && h.Serial != "" && m.BatterySerial != h.Serial {
+		*h = HistoryAnalysis{} // новая батарея – старые тренды к ней не относятся
+	}
+	if m.BatterySerial != "" {
+		h.Serial = m.BatterySerial
+	}
+	if h.FirstTimestamp == "" {
+		h.FirstTimestamp = m.Timestamp
+	}
+
+	if prev := h.Prev; prev != nil {
+		h.Anomalies += len(detectBatteryAnomalies([]Measurement{*prev, m}))
+		// Те же правила отбора интервалов, что и в computeAvgRateRobust
+		drop := float64(prev.CurrentCapacity - m.CurrentCapacity)
+		t1, err1 := time.Parse(time.RFC3339, prev.Timestamp)
+		t2, err2 := time.Parse(time.RFC3339, m.Timestamp)
+		hours := t2.Sub(t1).Hours()
+		if err1 == nil && err2 == nil && drop > 0 && hours > 0 && hours <= 2 &&
+			abs(m.Percentage-prev.Percentage) <= 20 && drop <= 500 {
+			h.Window.Add(drop, hours)
+			h.Discharge.Add(drop / hours)
+		}
+	}
+	if m.Temperature > 0 {
+		h.Temperature.Add(float64(m.Temperature))
+	}
+	if m.FullChargeCap > 0 {
+		first, err1 := time.Parse(time.RFC3339, h.FirstTimestamp)
+		t, err2 := time.Parse(time.RFC3339, m.Timestamp)
+		if err1 == nil && err2 == nil {
+			h.Capacity.Add(t.Sub(first).Hours()/24, float64(m.FullChargeCap))
+		}
+	}
+
+	h.Prev = &m
+	h.LastTimestamp = m.Timestamp
+	h.Count++
+}
+
+// Since возвращает дату первого учтенного измерения
+func (h HistoryAnalysis) Since() string {
+	return parseStoredTime(h.FirstTimestamp).Local().Format("02.01.2006")
+}
+
+// MonthlyDegradation возвращает тренд полной ёмкости в процентах от
+// проектной за месяц; ok=false, пока данных меньше недели
+func (h HistoryAnalysis) MonthlyDegradation() (float64, bool) {
+	if h.Prev == nil || h.Prev.DesignCapacity <= 0 {
+		return 0, false
+	}
+	span := parseStoredTime(h.LastTimestamp).Sub(parseStoredTime(h.FirstTimestamp))
+	slope, ok := h.Capacity.Slope()
+	if !ok || span < 7*24*time.Hour {
+		return 0, false
+	}
+	return slope * 30 / float64(h.Prev.DesignCapacity) * 100, true
+}
+
+// getHistoryAnalysis читает сохраненное состояние; без него возвращает пустое
+func getHistoryAnalysis(db *sqlx.DB) (*HistoryAnalysis, error) {
+	var raw string
+	err := db.Get(&raw, `SELECT state FROM analysis_state WHERE name = 'history'`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &HistoryAnalysis{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("analysis.err.read"), err)
+	}
+	var h HistoryAnalysis
+	if err := json.Unmarshal([]byte(raw), &h); err != nil {
+		// Поврежденное состояние пересчитаем с нуля
+		return &HistoryAnalysis{}, nil
+	}
+	return &h, nil
+}
+
+// saveHistoryAnalysis сохраняет состояние анализа
+func saveHistoryAnalysis(db *sqlx.DB, h *HistoryAnalysis) error {
+	raw, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf(T("analysis.err.marshal"), err)
+	}
+	_, err = db.Exec(`INSERT OR REPLACE INTO analysis_state (name, state, updated_at) VALUES ('history', ?, ?)`,
+		string(raw), timeNow().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf(T("analysis.err.save"), err)
+	}
+	return nil
+}
+
+// loadHistoryAnalysis возвращает актуальный анализ истории: читает состояние
+// и дочитывает построчно только измерения после него
+func loadHistoryAnalysis(db *sqlx.DB) (*HistoryAnalysis, error) {
+	h, err := getHistoryAnalysis(db)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Queryx(`SELECT * FROM measurements WHERE timestamp > ? ORDER BY timestamp`, h.LastTimestamp)
+	if err != nil {
+		return nil, fmt.Errorf(T("analysis.err.new"), err)
+	}
+	defer rows.Close()
+	added := 0
+	for rows.Next() {
+		var m Measurement
+		if err := rows.StructScan(&m); err != nil {
+			return nil, fmt.Errorf(T("measurement.err.read"), err)
+		}
+		h.Add(m)
+		added++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf(T("analysis.err.new"), err)
+	}
+	rows.Close()
+	if added > 0 {
+		if err := saveHistoryAnalysis(db, h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// updateHistory учитывает измерение в анализе истории и периодически сохраняет его
+func (dc *DataCollector) updateHistory(m Measurement) {
+	if dc.history == nil {
+		h, err := loadHistoryAnalysis(dc.db)
+		if err != nil {
+			logWarnf("⚠️ %v", err)
+			return
+		}
+		dc.history = h
+		dc.lastHistorySave = timeNow()
+	}
+	dc.history.Add(m)
+	if timeNow().Sub(dc.lastHistorySave) >= historySaveInterval {
+		if err := saveHistoryAnalysis(dc.db, dc.history); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+		dc.lastHistorySave = timeNow()
+	}
+}
+
+// SummaryLines возвращает строки сводки по всей истории для отчетов
+func (h HistoryAnalysis) SummaryLines() []string {
+	if h.Count == 0 {
+		return nil
+	}
+	lines := []string{T("history.observed", h.Since(), h.Count, h.Anomalies)}
+	if h.Discharge.N > 0 {
+		lines = append(lines, T("history.discharge", h.Discharge.Mean, h.Discharge.Std(), h.Window.Rate()))
+	}
+	if h.Temperature.N > 0 {
+		lines = append(lines, T("history.temperature", h.Temperature.Mean, h.Temperature.Max))
+	}
+	if trend, ok := h.MonthlyDegradation(); ok {
+		lines = append(lines, T("history.trend", signedFloat(trend)))
+	}
+	return lines
+}
@@ -0,0 +1,90 @@
+// indexes.go
+//
+// Индексы и запросы для больших баз: за несколько месяцев измерений раз в
+// 30 секунд выборки по времени без индекса читают всю таблицу. Индексы
+// создаются миграцией, `batmon db optimize` восстанавливает недостающие и
+// обновляет статистику планировщика.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// measurementIndexes – индексы по времени для таблиц, которые читаются периодами
+var measurementIndexes = []struct {
+	Name, Table, Column string
+}{
+	{"idx_measurements_timestamp", "measurements", "timestamp"},
+	{"idx_app_power_samples_timestamp", "app_power_samples", "timestamp"},
+}
+
+// createIndexes создает недостающие индексы
+func createIndexes(tx *sqlx.Tx) error {
+	for _, idx := range measurementIndexes {
+		if _, err := tx.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s(%s)", idx.Name, idx.Table, idx.Column)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropIndexes удаляет индексы
+func dropIndexes(tx *sqlx.Tx) error {
+	for _, idx := range measurementIndexes {
+		if _, err := tx.Exec("DROP INDEX IF EXISTS " + idx.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertMeasurementQuery – вставка измерения; столбцы в порядке measurementArgs
+const insertMeasurementQuery = `INSERT INTO measurements (
+	timestamp, percentage, state, cycle_count,
+	full_charge_capacity, design_capacity, current_capacity, temperature,
+	voltage, amperage, power, apple_condition, battery_serial,
+	brightness, lid_state, cell_voltages)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// measurementArgs возвращает значения для insertMeasurementQuery
+func measurementArgs(m *Measurement) []interface{} {
+	return []interface{}{
+		m.Timestamp, m.Percentage, m.State, m.CycleCount,
+		m.FullChargeCap, m.DesignCapacity, m.CurrentCapacity, m.Temperature,
+		m.Voltage, m.Amperage, m.Power, m.AppleCondition, m.BatterySerial,
+		m.Brightness, m.LidState, m.CellVoltages,
+	}
+}
+
+// getMeasurementsBefore возвращает страницу из не более limit измерений раньше
+// before (пусто – с последнего) в хронологическом порядке. Следующая страница
+// запрашивается с before = timestamp первого измерения: постраничное чтение по
+// индексу не зависит от глубины, в отличие от OFFSET.
+func getMeasurementsBefore(db *sqlx.DB, before string, limit int) ([]Measurement, error) {
+	return newSQLiteMeasurements(db).MeasurementsBefore(before, limit)
+}
+
+// optimizeDatabase создает недостающие индексы и обновляет статистику планировщика
+func optimizeDatabase(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf(T("db.err.optimize"), err)
+	}
+	if err := createIndexes(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf(T("db.err.indexes"), err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf(T("db.err.indexes"), err)
+	}
+	if _, err := db.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("ANALYZE: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA optimize"); err != nil {
+		return fmt.Errorf("PRAGMA optimize: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadRecording(t *testing.T, name string) batteryFixture {
+	t.Helper()
+	fx, err := loadBatteryFixture(filepath.Join("testdata", "recordings", name+".json"))
+	if err != nil {
+		t.Fatalf("запись %s: %v", name, err)
+	}
+	return fx
+}
+
+// replayRecording прогоняет запись через настоящий коллектор:
+// replaySource → DataCollector → SQLite. Возвращает коллектор с открытой БД.
+func replayRecording(t *testing.T, rec batteryFixture) *DataCollector {
+	t.Helper()
+
+	interval, err := time.ParseDuration(rec.Interval)
+	if err != nil {
+		t.Fatalf("интервал записи %q: %v", rec.Interval, err)
+	}
+
+	// Отчеты проверяются по русскому тексту – язык не должен зависеть от окружения
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "ru_RU.UTF-8")
+
+	db, err := initDB(filepath.Join(t.TempDir(), "batmon.sqlite"))
+	if err != nil {
+		t.Fatalf("initDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	clock := time.Now().Add(-time.Duration(len(rec.Samples)) * interval)
+
+	origNow := timeNow
+	t.Cleanup(func() { timeNow = origNow })
+	timeNow = func() time.Time { return clock }
+
+	collector := NewDataCollector(db)
+	collector.source = newReplaySource(rec)
+	collector.profilerInterval = interval
+
+	for i := range rec.Samples {
+		clock = clock.Add(interval)
+		if err := collector.CollectAndStore(); err != nil {
+			t.Fatalf("сэмпл %d: %v", i, err)
+		}
+	}
+	return collector
+}
+
+func TestRecordedPipeline(t *testing.T) {
+	cases := []struct {
+		name            string
+		wearMin         float64
+		wearMax         float64
+		healthPrefix    string
+		wantAnomaly     string
+		wantNoAnomalies bool
+		wantRec         string
+	}{
+		{name: "healthy", wearMin: 1, wearMax: 3, healthPrefix: "Отличное", wantNoAnomalies: true},
+		{name: "degraded", wearMin: 21, wearMax: 24, healthPrefix: "Требует внимания", wantRec: "Рассмотрите замену батареи"},
+		{name: "faulty", wearMin: 34, wearMax: 36, healthPrefix: "Плохое", wantAnomaly: "Резкое падение заряда", wantRec: "Высокая температура"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := loadRecording(t, tc.name)
+			collector := replayRecording(t, rec)
+
+			data, err := generateReportData(collector.db)
+			if err != nil {
+				t.Fatalf("generateReportData: %v", err)
+			}
+
+			if got := len(data.Measurements); got != len(rec.Samples) {
+				t.Errorf("измерений в отчёте: %d, ожидалось %d", got, len(rec.Samples))
+			}
+			if data.Latest.CycleCount == 0 || data.Latest.DesignCapacity == 0 {
+				t.Errorf("данные ioreg не попали в измерение: %+v", data.Latest)
+			}
+			if data.Latest.Amperage >= 0 {
+				t.Errorf("ток разрядки должен быть отрицательным, получено %d", data.Latest.Amperage)
+			}
+			if data.Wear < tc.wearMin || data.Wear > tc.wearMax {
+				t.Errorf("износ %.2f%% вне диапазона [%.0f; %.0f]", data.Wear, tc.wearMin, tc.wearMax)
+			}
+			if data.RobustRate <= 0 || data.ValidIntervals == 0 {
+				t.Errorf("скорость разрядки не вычислена: %.1f мАч/ч, интервалов %d", data.RobustRate, data.ValidIntervals)
+			}
+
+			status := ""
+			if data.HealthAnalysis != nil {
+				status = data.HealthAnalysis.HealthStatus
+			}
+			if !strings.HasPrefix(status, tc.healthPrefix) {
+				t.Errorf("состояние %q, ожидалось %q", status, tc.healthPrefix)
+			}
+			if tc.wantNoAnomalies && len(data.Anomalies) > 0 {
+				t.Errorf("неожиданные аномалии: %v", data.Anomalies)
+			}
+			if tc.wantAnomaly != "" && !containsSubstring(anomalyMessages(data.Anomalies), tc.wantAnomaly) {
+				t.Errorf("аномалия %q не найдена в %v", tc.wantAnomaly, data.Anomalies)
+			}
+			if tc.wantRec != "" && !containsSubstring(data.Recommendations, tc.wantRec) {
+				t.Errorf("рекомендация %q не найдена в %v", tc.wantRec, data.Recommendations)
+			}
+
+			dir := t.TempDir()
+			mdPath := filepath.Join(dir, "report.md")
+			if err := exportToMarkdown(data, mdPath); err != nil {
+				t.Fatalf("exportToMarkdown: %v", err)
+			}
+			htmlPath := filepath.Join(dir, "report.html")
+			if err := exportToHTML(data, htmlPath); err != nil {
+				t.Fatalf("exportToHTML: %v", err)
+			}
+
+			md := readFile(t, mdPath)
+			for _, want := range []string{"# 🔋 Отчет о состоянии батареи", status, fmt.Sprintf("%d", data.Latest.CycleCount)} {
+				if !strings.Contains(md, want) {
+					t.Errorf("в Markdown нет %q", want)
+				}
+			}
+			html := readFile(t, htmlPath)
+			for _, want := range []string{"<html", "</html>", status} {
+				if !strings.Contains(html, want) {
+					t.Errorf("в HTML нет %q", want)
+				}
+			}
+		})
+	}
+}
+
+func containsSubstring(items []string, sub string) bool {
+	for _, item := range items {
+		if strings.Contains(item, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("чтение %s: %v", path, err)
+	}
+	return string(b)
+}
@@ -0,0 +1,91 @@
+// iokit.go
+//
+// Источник данных macOS через IOKit без запуска процессов: заряд и
+// состояние питания – из IOPowerSources, подробные параметры – из свойств
+// AppleSmartBattery в реестре IOKit. Два процесса (pmset и ioreg) на каждое
+// измерение стоят заметного времени и ломаются при смене формата вывода, а
+// числа из реестра приходят уже разобранными. Привязки к IOKit собираются
+// только на macOS с cgo (iokit_darwin.go); без них и при любой ошибке вызова
+// используется разбор вывода pmset и ioreg (collector_mac.go).
+
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// errIOKitUnavailable – сборка без привязок к IOKit (не macOS или без cgo)
+var errIOKitUnavailable = messageError("iokit.err.unavailable")
+
+// iokitPowerSource – внутренняя батарея в IOPowerSources
+type iokitPowerSource struct {
+	Percentage int
+	State      string        // как у pmset: charging, discharging, charged, ac
+	Remaining  time.Duration // оценка macOS; 0 – еще не посчитана или от сети
+}
+
+// iokitSource читает батарею через IOKit; адаптер, крышку и яркость, а
+// также все, что не удалось прочитать напрямую, берет у macSource
+type iokitSource struct {
+	macSource
+}
+
+// newMacSource возвращает источник для macOS: IOKit, если привязки собраны
+func newMacSource() BatterySource {
+	if _, err := readIOPowerSource(); errors.Is(err, errIOKitUnavailable) {
+		return macSource{}
+	}
+	return iokitSource{}
+}
+
+func (iokitSource) Name() string { return "IOKit" }
+
+func (s iokitSource) Status() (int, string, error) {
+	ps, err := readIOPowerSource()
+	if err != nil {
+		return s.macSource.Status()
+	}
+	return ps.Percentage, ps.State, nil
+}
+
+func (s iokitSource) Remaining() (time.Duration, bool, error) {
+	ps, err := readIOPowerSource()
+	if err != nil {
+		return s.macSource.Remaining()
+	}
+	return ps.Remaining, ps.Remaining > 0, nil
+}
+
+// Details читает AppleSmartBattery из реестра IOKit. Состояние батареи
+// («Normal», «Service Recommended») есть только в system_profiler, поэтому
+// он по-прежнему вызывается, но его ошибка не фатальна.
+func (s iokitSource) Details() (BatteryDetails, error) {
+	d, err := readSmartBattery()
+	if err != nil {
+		return s.macSource.Details()
+	}
+	if sp, spErr := runCommand("system_profiler", "SPPowerDataType", "-detailLevel", "full"); spErr == nil {
+		if spCycle, spCondition, parseErr := parseSystemProfilerOutput(sp); parseErr == nil {
+			d.Condition = spCondition
+			if d.CycleCount == 0 {
+				d.CycleCount = spCycle
+			}
+		}
+	}
+	return d, nil
+}
+
+// iokitState переводит флаги IOPowerSources в состояние в формате pmset
+func iokitState(charging, charged, onAC bool, percentage int) string {
+	switch {
+	case !onAC:
+		return "discharging"
+	case charging:
+		return "charging"
+	case charged || percentage >= 100:
+		return "charged"
+	default:
+		return "ac" // как "AC attached; not charging" у pmset
+	}
+}
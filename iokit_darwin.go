@@ -0,0 +1,224 @@
+//go:build darwin && cgo
+
+// iokit_darwin.go
+//
+// Привязки к IOKit через cgo. Словари CoreFoundation разбираются на стороне
+// C, в Go возвращаются плоские структуры, чтобы не держать CF-объекты в
+// памяти Go.
+
+package main
+
+/*
+#cgo LDFLAGS: -framework IOKit -framework CoreFoundation
+#include <string.h>
+#include <CoreFoundation/CoreFoundation.h>
+#include <IOKit/IOKitLib.h>
+#include <IOKit/ps/IOPowerSources.h>
+#include <IOKit/ps/IOPSKeys.h>
+
+typedef struct {
+	int ok;
+	int percent;
+	int charging;
+	int charged;
+	int on_ac;
+	int minutes_left; // -1 – оценки нет
+} bm_power_source;
+
+#define BM_MAX_CELLS 8
+
+typedef struct {
+	int ok;
+	long long cycle_count;
+	long long raw_max_capacity;
+	long long design_capacity;
+	long long raw_current_capacity;
+	long long temperature; // сотые доли °C
+	long long voltage;
+	long long amperage;
+	long long permanent_failure;
+	long long cells[BM_MAX_CELLS]; // мВ
+	int cell_count;
+	char serial[64];
+} bm_smart_battery;
+
+static int bm_dict_int(CFDictionaryRef d, const char *key, long long *out) {
+	CFStringRef k = CFStringCreateWithCString(kCFAllocatorDefault, key, kCFStringEncodingUTF8);
+	CFTypeRef v = CFDictionaryGetValue(d, k);
+	CFRelease(k);
+	if (v == NULL) {
+		return 0;
+	}
+	if (CFGetTypeID(v) == CFNumberGetTypeID()) {
+		return CFNumberGetValue((CFNumberRef)v, kCFNumberLongLongType, out) ? 1 : 0;
+	}
+	if (CFGetTypeID(v) == CFBooleanGetTypeID()) {
+		*out = CFBooleanGetValue((CFBooleanRef)v) ? 1 : 0;
+		return 1;
+	}
+	return 0;
+}
+
+static int bm_dict_string(CFDictionaryRef d, const char *key, char *buf, CFIndex size) {
+	CFStringRef k = CFStringCreateWithCString(kCFAllocatorDefault, key, kCFStringEncodingUTF8);
+	CFTypeRef v = CFDictionaryGetValue(d, k);
+	CFRelease(k);
+	if (v == NULL || CFGetTypeID(v) != CFStringGetTypeID()) {
+		return 0;
+	}
+	return CFStringGetCString((CFStringRef)v, buf, size, kCFStringEncodingUTF8) ? 1 : 0;
+}
+
+static CFTypeRef bm_dict_value(CFDictionaryRef d, const char *key, CFTypeID type) {
+	CFStringRef k = CFStringCreateWithCString(kCFAllocatorDefault, key, kCFStringEncodingUTF8);
+	CFTypeRef v = CFDictionaryGetValue(d, k);
+	CFRelease(k);
+	return v != NULL && CFGetTypeID(v) == type ? v : NULL;
+}
+
+static int bm_dict_int_array(CFDictionaryRef d, const char *key, long long *out, int size) {
+	CFArrayRef arr = (CFArrayRef)bm_dict_value(d, key, CFArrayGetTypeID());
+	if (arr == NULL) {
+		return 0;
+	}
+	int n = 0;
+	for (CFIndex i = 0; i < CFArrayGetCount(arr) && n < size; i++) {
+		CFTypeRef v = CFArrayGetValueAtIndex(arr, i);
+		if (CFGetTypeID(v) == CFNumberGetTypeID() && CFNumberGetValue((CFNumberRef)v, kCFNumberLongLongType, &out[n])) {
+			n++;
+		}
+	}
+	return n;
+}
+
+static bm_power_source bm_read_power_source(void) {
+	bm_power_source s;
+	memset(&s, 0, sizeof s);
+	s.minutes_left = -1;
+
+	CFTypeRef info = IOPSCopyPowerSourcesInfo();
+	if (info == NULL) {
+		return s;
+	}
+	CFArrayRef list = IOPSCopyPowerSourcesList(info);
+	if (list == NULL) {
+		CFRelease(info);
+		return s;
+	}
+	for (CFIndex i = 0; i < CFArrayGetCount(list); i++) {
+		CFDictionaryRef desc = IOPSGetPowerSourceDescription(info, CFArrayGetValueAtIndex(list, i));
+		char buf[64];
+		if (desc == NULL || !bm_dict_string(desc, kIOPSTypeKey, buf, sizeof buf) ||
+			strcmp(buf, kIOPSInternalBatteryType) != 0) {
+			continue;
+		}
+		long long current = 0, max = 0, v = 0;
+		bm_dict_int(desc, kIOPSCurrentCapacityKey, &current);
+		bm_dict_int(desc, kIOPSMaxCapacityKey, &max);
+		s.percent = max > 0 ? (int)(current * 100 / max) : (int)current;
+		if (bm_dict_int(desc, kIOPSIsChargingKey, &v)) {
+			s.charging = (int)v;
+		}
+		if (bm_dict_int(desc, kIOPSIsChargedKey, &v)) {
+			s.charged = (int)v;
+		}
+		if (bm_dict_string(desc, kIOPSPowerSourceStateKey, buf, sizeof buf)) {
+			s.on_ac = strcmp(buf, kIOPSACPowerValue) == 0;
+		}
+		if (bm_dict_int(desc, kIOPSTimeToEmptyKey, &v) && v > 0) {
+			s.minutes_left = (int)v;
+		}
+		s.ok = 1;
+		break;
+	}
+	CFRelease(list);
+	CFRelease(info);
+	return s;
+}
+
+static bm_smart_battery bm_read_smart_battery(void) {
+	bm_smart_battery b;
+	memset(&b, 0, sizeof b);
+
+	// MACH_PORT_NULL – порт по умолчанию (kIOMainPortDefault в новых SDK)
+	io_service_t service = IOServiceGetMatchingService(MACH_PORT_NULL, IOServiceMatching("AppleSmartBattery"));
+	if (service == IO_OBJECT_NULL) {
+		return b;
+	}
+	CFMutableDictionaryRef props = NULL;
+	if (IORegistryEntryCreateCFProperties(service, &props, kCFAllocatorDefault, 0) != KERN_SUCCESS || props == NULL) {
+		IOObjectRelease(service);
+		return b;
+	}
+	bm_dict_int(props, "CycleCount", &b.cycle_count);
+	bm_dict_int(props, "AppleRawMaxCapacity", &b.raw_max_capacity);
+	bm_dict_int(props, "DesignCapacity", &b.design_capacity);
+	bm_dict_int(props, "AppleRawCurrentCapacity", &b.raw_current_capacity);
+	bm_dict_int(props, "Temperature", &b.temperature);
+	bm_dict_int(props, "Voltage", &b.voltage);
+	bm_dict_int(props, "Amperage", &b.amperage);
+	if (!bm_dict_string(props, "Serial", b.serial, sizeof b.serial)) {
+		bm_dict_string(props, "BatterySerialNumber", b.serial, sizeof b.serial);
+	}
+	// Напряжения ячеек и статус отказа лежат во вложенном словаре BatteryData
+	CFDictionaryRef data = (CFDictionaryRef)bm_dict_value(props, "BatteryData", CFDictionaryGetTypeID());
+	if (data != NULL) {
+		b.cell_count = bm_dict_int_array(data, "CellVoltage", b.cells, BM_MAX_CELLS);
+		bm_dict_int(data, "PermanentFailureStatus", &b.permanent_failure);
+	}
+	if (b.cell_count == 0) {
+		b.cell_count = bm_dict_int_array(props, "CellVoltages", b.cells, BM_MAX_CELLS);
+	}
+	bm_dict_int(props, "PermanentFailureStatus", &b.permanent_failure);
+	b.ok = 1;
+	CFRelease(props);
+	IOObjectRelease(service);
+	return b;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// readIOPowerSource читает внутреннюю батарею из IOPowerSources
+func readIOPowerSource() (iokitPowerSource, error) {
+	s := C.bm_read_power_source()
+	if s.ok == 0 {
+		return iokitPowerSource{}, errors.New(T("iokit.err.no_internal"))
+	}
+	ps := iokitPowerSource{
+		Percentage: int(s.percent),
+		State:      iokitState(s.charging != 0, s.charged != 0, s.on_ac != 0, int(s.percent)),
+	}
+	if s.on_ac == 0 && s.minutes_left > 0 {
+		ps.Remaining = time.Duration(s.minutes_left) * time.Minute
+	}
+	return ps, nil
+}
+
+// readSmartBattery читает свойства AppleSmartBattery из реестра IOKit
+func readSmartBattery() (BatteryDetails, error) {
+	b := C.bm_read_smart_battery()
+	if b.ok == 0 {
+		return BatteryDetails{}, errors.New(T("iokit.err.no_smart_battery"))
+	}
+	d := BatteryDetails{
+		CycleCount:       int(b.cycle_count),
+		FullChargeCap:    int(b.raw_max_capacity),
+		DesignCapacity:   int(b.design_capacity),
+		CurrentCapacity:  int(b.raw_current_capacity),
+		Temperature:      int(b.temperature) / 100,
+		Voltage:          int(b.voltage),
+		Amperage:         int(b.amperage),
+		Serial:           C.GoString(&b.serial[0]),
+		PermanentFailure: int(b.permanent_failure),
+	}
+	for i := 0; i < int(b.cell_count); i++ {
+		d.CellVoltages = append(d.CellVoltages, int(b.cells[i]))
+	}
+	return d, nil
+}
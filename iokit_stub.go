@@ -0,0 +1,16 @@
+//go:build !darwin || !cgo
+
+// iokit_stub.go
+//
+// Заглушка IOKit для сборок без macOS или без cgo: источник macOS остается
+// на разборе вывода pmset и ioreg.
+
+package main
+
+func readIOPowerSource() (iokitPowerSource, error) {
+	return iokitPowerSource{}, errIOKitUnavailable
+}
+
+func readSmartBattery() (BatteryDetails, error) {
+	return BatteryDetails{}, errIOKitUnavailable
+}
@@ -0,0 +1,301 @@
+// logger.go
+//
+// Журнал batmon: сообщения с уровнем (DEBUG, INFO, WARN, ERROR) пишутся в
+// batmon.log в папке данных, файл ротируется по размеру. В командной строке
+// сообщения, как и раньше, дублируются в stderr; в TUI вывод в терминал
+// отключается – log.Printf посреди альтернативного экрана Bubble Tea ломал
+// отрисовку, а ошибки сбора было видно, только запустив batmon без
+// интерфейса. Журнал читается на экране «Журнал» главного меню.
+//
+// Уровень задается в config.json (log.level), --verbose включает DEBUG.
+// Вызовы стандартного log (в том числе из библиотек) тоже попадают в журнал:
+// уровень определяется по началу сообщения.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLogMaxSizeKB = 1024 // размер batmon.log до ротации
+	defaultLogBackups   = 3    // batmon.log.1 … batmon.log.3
+	logFileName         = "batmon.log"
+	logTimeLayout       = time.RFC3339
+)
+
+// LogConfig – журнал в config.json
+type LogConfig struct {
+	Level     string `json:"level,omitempty"`       // debug, info, warn или error; пусто – info
+	MaxSizeKB int    `json:"max_size_kb,omitempty"` // размер файла до ротации, КБ; 0 – по умолчанию
+	Backups   int    `json:"backups,omitempty"`     // сколько старых файлов хранить; 0 – по умолчанию
+}
+
+// logLevel – уровень сообщения журнала
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+// logLevels – уровни по порядку, для разбора и переключения фильтра
+var logLevels = []logLevel{levelDebug, levelInfo, levelWarn, levelError}
+
+func (l logLevel) String() string {
+	switch l {
+	case levelDebug:
+		return "DEBUG"
+	case levelWarn:
+		return "WARN"
+	case levelError:
+		return "ERROR"
+	}
+	return "INFO"
+}
+
+// parseLogLevel разбирает уровень из config.json; пусто – INFO
+func parseLogLevel(s string) (logLevel, error) {
+	if strings.TrimSpace(s) == "" {
+		return levelInfo, nil
+	}
+	for _, l := range logLevels {
+		if strings.EqualFold(s, l.String()) || (l == levelWarn && strings.EqualFold(s, "warning")) {
+			return l, nil
+		}
+	}
+	return levelInfo, fmt.Errorf(T("log.err.level"), s)
+}
+
+// appLogger пишет журнал в файл с ротацией и, если задан console, в терминал
+type appLogger struct {
+	mu      sync.Mutex
+	level   logLevel
+	path    string
+	file    *os.File
+	size    int64
+	maxSize int64
+	backups int
+	console io.Writer // stderr в командной строке, nil в TUI
+}
+
+// logger – журнал приложения; до initLogging пишет только в stderr
+var logger = &appLogger{level: levelInfo, console: os.Stderr}
+
+// logPath возвращает путь к batmon.log; пусто – папки данных нет
+func logPath() string {
+	dir, err := getDataDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, logFileName)
+}
+
+// initLogging открывает файл журнала и направляет в него стандартный log.
+// verbose включает DEBUG независимо от config.json.
+func initLogging(cfg LogConfig, verbose bool) error {
+	level, levelErr := parseLogLevel(cfg.Level)
+	if verbose {
+		level = levelDebug
+	}
+	maxSize := int64(cfg.MaxSizeKB) * 1024
+	if maxSize <= 0 {
+		maxSize = defaultLogMaxSizeKB * 1024
+	}
+	backups := cfg.Backups
+	if backups <= 0 {
+		backups = defaultLogBackups
+	}
+
+	logger.mu.Lock()
+	logger.level, logger.maxSize, logger.backups = level, maxSize, backups
+	logger.path = logPath()
+	err := logger.openLocked()
+	logger.mu.Unlock()
+
+	log.SetFlags(0)
+	log.SetOutput(stdLogBridge{})
+	if err != nil {
+		return err
+	}
+	return levelErr
+}
+
+// openLocked открывает файл журнала для дописывания
+func (l *appLogger) openLocked() error {
+	if l.path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		l.path = ""
+		return fmt.Errorf(T("log.err.file"), err)
+	}
+	l.file = f
+	l.size = 0
+	if info, err := f.Stat(); err == nil {
+		l.size = info.Size()
+	}
+	return nil
+}
+
+// rotateLocked сдвигает batmon.log → .1 → .2 …, самый старый удаляется
+func (l *appLogger) rotateLocked() error {
+	l.file.Close()
+	l.file = nil
+	os.Remove(fmt.Sprintf("%s.%d", l.path, l.backups))
+	for i := l.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return l.openLocked()
+}
+
+// write записывает сообщение, если его уровень не ниже заданного
+func (l *appLogger) write(level logLevel, msg string) {
+	msg = strings.TrimRight(msg, "\n")
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < l.level {
+		return
+	}
+	if l.console != nil {
+		fmt.Fprintf(l.console, "%s %s\n", now.Format("2006/01/02 15:04:05"), msg)
+	}
+	if l.file == nil {
+		return
+	}
+	line := fmt.Sprintf("%s %-5s %s\n", now.Format(logTimeLayout), level, strings.ReplaceAll(msg, "\n", " ⏎ "))
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotateLocked(); err != nil || l.file == nil {
+			return
+		}
+	}
+	n, _ := l.file.WriteString(line)
+	l.size += int64(n)
+}
+
+// setLogConsole меняет вывод журнала в терминал (nil – только файл) и
+// возвращает функцию, восстанавливающую прежний
+func setLogConsole(w io.Writer) (restore func()) {
+	logger.mu.Lock()
+	prev := logger.console
+	logger.console = w
+	logger.mu.Unlock()
+	return func() {
+		logger.mu.Lock()
+		logger.console = prev
+		logger.mu.Unlock()
+	}
+}
+
+// logDebugf пишет подробности для диагностики (видны с --verbose)
+func logDebugf(format string, args ...interface{}) {
+	logger.write(levelDebug, fmt.Sprintf(format, args...))
+}
+
+// logInfof пишет обычное сообщение
+func logInfof(format string, args ...interface{}) {
+	logger.write(levelInfo, fmt.Sprintf(format, args...))
+}
+
+// logWarnf пишет предупреждение: работа продолжается, но что-то не так
+func logWarnf(format string, args ...interface{}) {
+	logger.write(levelWarn, fmt.Sprintf(format, args...))
+}
+
+// logErrorf пишет ошибку
+func logErrorf(format string, args ...interface{}) {
+	logger.write(levelError, fmt.Sprintf(format, args...))
+}
+
+// stdLogBridge направляет стандартный log в журнал. Уровень угадывается по
+// началу сообщения – так пишут предупреждения и ошибки во всем batmon.
+type stdLogBridge struct{}
+
+func (stdLogBridge) Write(p []byte) (int, error) {
+	logger.write(guessLogLevel(string(p)), string(p))
+	return len(p), nil
+}
+
+// guessLogLevel определяет уровень сообщения без явного уровня
+func guessLogLevel(msg string) logLevel {
+	msg = strings.TrimSpace(msg)
+	switch {
+	case strings.HasPrefix(msg, "⚠️"), strings.HasPrefix(msg, "Предупреждение"):
+		return levelWarn
+	case strings.HasPrefix(msg, "❌"), strings.HasPrefix(msg, "Ошибка"):
+		return levelError
+	}
+	return levelInfo
+}
+
+// logEntry – запись журнала, прочитанная из файла
+type logEntry struct {
+	Time    time.Time
+	Level   logLevel
+	Message string
+}
+
+// parseLogLine разбирает строку batmon.log; ok=false – строка не из журнала
+func parseLogLine(line string) (logEntry, bool) {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 3 {
+		return logEntry{}, false
+	}
+	t, err := time.Parse(logTimeLayout, fields[0])
+	if err != nil {
+		return logEntry{}, false
+	}
+	level, err := parseLogLevel(fields[1])
+	if err != nil {
+		return logEntry{}, false
+	}
+	return logEntry{Time: t, Level: level, Message: strings.TrimLeft(fields[2], " ")}, true
+}
+
+// readLogTail возвращает не более n последних записей журнала, старые первыми.
+// Читается только хвост файла: журнал может быть до нескольких мегабайт.
+func readLogTail(path string, n int) ([]logEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	const tailBytes = 256 * 1024
+	if info, err := f.Stat(); err == nil && info.Size() > tailBytes {
+		if _, err := f.Seek(-tailBytes, io.SeekEnd); err != nil {
+			return nil, err
+		}
+	}
+	raw, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	var entries []logEntry
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 64*1024), tailBytes)
+	for scanner.Scan() {
+		if e, ok := parseLogLine(scanner.Text()); ok {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTestLogger направляет журнал в файл во временной папке
+func useTestLogger(t *testing.T, maxSize int64, backups int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), logFileName)
+	orig := logger
+	t.Cleanup(func() {
+		if logger.file != nil {
+			logger.file.Close()
+		}
+		logger = orig
+	})
+	logger = &appLogger{level: levelInfo, path: path, maxSize: maxSize, backups: backups}
+	if err := logger.openLocked(); err != nil {
+		t.Fatalf("открытие журнала: %v", err)
+	}
+	return path
+}
+
+func TestLoggerLevelsAndTail(t *testing.T) {
+	path := useTestLogger(t, 1<<20, 3)
+	logDebugf("не попадет в журнал: уровень ниже INFO")
+	logInfof("измерение %d%%", 80)
+	logWarnf("⚠️ ioreg недоступен")
+	stdLogBridge{}.Write([]byte("❌ Ошибка сбора данных\n"))
+
+	entries, err := readLogTail(path, 10)
+	if err != nil {
+		t.Fatalf("чтение журнала: %v", err)
+	}
+	want := []struct {
+		level logLevel
+		msg   string
+	}{{levelInfo, "измерение 80%"}, {levelWarn, "⚠️ ioreg недоступен"}, {levelError, "❌ Ошибка сбора данных"}}
+	if len(entries) != len(want) {
+		t.Fatalf("записей %d, ожидалось %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		if entries[i].Level != w.level || entries[i].Message != w.msg {
+			t.Errorf("запись %d: %s %q, ожидалось %s %q", i, entries[i].Level, entries[i].Message, w.level, w.msg)
+		}
+	}
+}
+
+func TestLoggerRotation(t *testing.T) {
+	path := useTestLogger(t, 200, 2)
+	for i := 0; i < 20; i++ {
+		logInfof("сообщение %02d %s", i, strings.Repeat("x", 40))
+	}
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("нет файла %s: %v", filepath.Base(name), err)
+		}
+		if info.Size() > 200 {
+			t.Errorf("%s: %d байт больше предела", filepath.Base(name), info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("хранится больше двух старых файлов")
+	}
+	entries, _ := readLogTail(path, 10)
+	if len(entries) == 0 || !strings.HasPrefix(entries[len(entries)-1].Message, "сообщение 19") {
+		t.Errorf("последняя запись потеряна: %+v", entries)
+	}
+}
@@ -0,0 +1,131 @@
+// logs_view.go
+//
+// Экран «Журнал»: последние записи batmon.log с фильтром по уровню. Ошибки
+// сбора данных в TUI больше не выводятся в терминал, и этот экран – способ
+// увидеть их, не выходя из интерфейса. Журнал перечитывается при открытии
+// экрана и по клавише r.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const logsViewEntries = 1000 // последних записей на экране
+
+// LogsModel – состояние экрана журнала
+type LogsModel struct {
+	entries  []logEntry
+	minLevel logLevel // показываются записи не ниже этого уровня
+	scroll   int      // строк от конца журнала
+	err      error
+}
+
+// initLogs открывает экран журнала
+func (a *App) initLogs() {
+	a.logs = LogsModel{minLevel: levelInfo}
+	a.reloadLogs()
+}
+
+// reloadLogs перечитывает хвост batmon.log
+func (a *App) reloadLogs() {
+	a.logs.entries, a.logs.err = nil, nil
+	path := logPath()
+	if path == "" {
+		a.logs.err = errors.New(T("logs.err.no_dir"))
+		return
+	}
+	a.logs.entries, a.logs.err = readLogTail(path, logsViewEntries)
+	if errors.Is(a.logs.err, os.ErrNotExist) {
+		a.logs.err = nil // журнал еще не создан
+	}
+}
+
+// visible возвращает записи, прошедшие фильтр по уровню
+func (m LogsModel) visible() []logEntry {
+	var out []logEntry
+	for _, e := range m.entries {
+		if e.Level >= m.minLevel {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// updateLogs обрабатывает нажатия на экране журнала
+func (a *App) updateLogs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := max(a.logsPageSize()-1, 1)
+	switch msg.String() {
+	case "ctrl+c", "q", "й", "esc":
+		a.state = StateMenu
+	case "up", "k":
+		a.logs.scroll++
+	case "down", "j":
+		a.logs.scroll--
+	case "pgup":
+		a.logs.scroll += page
+	case "pgdown":
+		a.logs.scroll -= page
+	case "end":
+		a.logs.scroll = 0
+	case "l", "д":
+		a.logs.minLevel = logLevels[(int(a.logs.minLevel)+1)%len(logLevels)]
+		a.logs.scroll = 0
+	case "r", "к":
+		a.reloadLogs()
+		a.logs.scroll = 0
+	}
+	a.logs.scroll = max(min(a.logs.scroll, len(a.logs.visible())-a.logsPageSize()), 0)
+	return a, nil
+}
+
+// logsPageSize возвращает число строк журнала, помещающихся на экране
+func (a *App) logsPageSize() int {
+	return max(a.windowHeight-8, 5)
+}
+
+// logLevelColor возвращает цвет уровня журнала
+func logLevelColor(l logLevel) lipgloss.Color {
+	switch l {
+	case levelDebug:
+		return theme.Muted
+	case levelWarn:
+		return theme.Warning
+	case levelError:
+		return theme.Critical
+	}
+	return theme.Info
+}
+
+// renderLogs рендерит экран журнала
+func (a *App) renderLogs() string {
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).Render(T("logs.title")) + "\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(theme.Muted).Render(T("logs.filter", logPath(), a.logs.minLevel)) + "\n\n")
+
+	entries := a.logs.visible()
+	switch {
+	case a.logs.err != nil:
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Critical).Render(T("logs.error", a.logs.err)) + "\n")
+	case len(entries) == 0:
+		content.WriteString(T("logs.empty") + "\n")
+	default:
+		end := len(entries) - a.logs.scroll
+		start := max(end-a.logsPageSize(), 0)
+		width := max(a.windowWidth-4, 40)
+		for _, e := range entries[start:end] {
+			level := lipgloss.NewStyle().Foreground(logLevelColor(e.Level)).Render(fmt.Sprintf("%-5s", e.Level))
+			line := fmt.Sprintf("%s %s %s", e.Time.Local().Format("02.01 15:04:05"), level, e.Message)
+			content.WriteString(lipgloss.NewStyle().MaxWidth(width).Render(line) + "\n")
+		}
+	}
+
+	content.WriteString("\n" + lipgloss.NewStyle().Foreground(theme.Muted).Render(T("logs.help")))
+	return lipgloss.NewStyle().Padding(1, 2).Render(content.String())
+}
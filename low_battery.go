@@ -0,0 +1,46 @@
+// low_battery.go
+//
+// Щадящий режим при низком заряде: чтобы batmon сам не ускорял последние
+// минуты разрядки, измерения пишутся в БД реже, а обслуживание базы
+// (очистка, VACUUM, выборка приложений, пересчет сессий) откладывается
+// до подключения зарядки.
+
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	defaultLowBatteryThreshold = 10              // порог по умолчанию, %
+	lowBatteryWriteInterval    = 2 * time.Minute // период записи в БД в щадящем режиме
+)
+
+// isLowBattery сообщает, нужен ли щадящий режим для измерения
+func isLowBattery(m Measurement, threshold int) bool {
+	return threshold > 0 &&
+		strings.ToLower(m.State) == "discharging" &&
+		m.Percentage < threshold
+}
+
+// updateLowBattery переключает щадящий режим коллектора по новому измерению
+func (dc *DataCollector) updateLowBattery(m Measurement) bool {
+	threshold := getConfig().Power.LowBatteryThreshold
+	low := isLowBattery(m, threshold)
+	if low != dc.lowBattery {
+		if low {
+			logInfof("🪫 Заряд ниже %d%%: запись в БД раз в %v, обслуживание базы отложено", threshold, lowBatteryWriteInterval)
+		} else {
+			logInfof("🔌 Щадящий режим выключен, обычная запись возобновлена")
+		}
+		dc.lowBattery = low
+	}
+	return low
+}
+
+// skipWrite сообщает, можно ли не записывать измерение в БД: в щадящем
+// режиме пишем не чаще lowBatteryWriteInterval
+func (dc *DataCollector) skipWrite() bool {
+	return dc.lowBattery && timeNow().Sub(dc.lastWrite) < lowBatteryWriteInterval
+}
@@ -0,0 +1,191 @@
+// mac_models.go
+//
+// Справочник распространенных MacBook по идентификатору модели из
+// `sysctl hw.model`: паспортная ёмкость, ресурс циклов и заявленное время
+// работы. С ним отчет сравнивает батарею не с абстрактным «эталоном», а с
+// типичными показателями именно этой модели.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// appleRetainedCapacity – ёмкость, которую Apple обещает сохранить к концу
+// ресурса циклов, %. Отсюда типичный износ: 20% к последнему циклу.
+const appleRetainedCapacity = 80
+
+// MacModel – типичные показатели батареи модели
+type MacModel struct {
+	Name           string
+	DesignCapacity int     // паспортная ёмкость, мАч
+	CycleLimit     int     // ресурс циклов по данным Apple
+	RuntimeHours   float64 // заявленное время работы (веб-серфинг по Wi-Fi), ч
+}
+
+// genericMacModel – показатели для неизвестной модели и других ОС
+var genericMacModel = MacModel{Name: "MacBook", CycleLimit: 1000}
+
+// macModels – модели по идентификатору hw.model
+var macModels = map[string]MacModel{
+	"MacBookAir8,1":  {"MacBook Air (13\", 2018)", 4380, 1000, 12},
+	"MacBookAir8,2":  {"MacBook Air (13\", 2019)", 4380, 1000, 12},
+	"MacBookAir9,1":  {"MacBook Air (13\", 2020, Intel)", 4380, 1000, 11},
+	"MacBookAir10,1": {"MacBook Air (M1, 2020)", 4380, 1000, 15},
+	"Mac14,2":        {"MacBook Air (M2, 13\", 2022)", 4600, 1000, 15},
+	"Mac14,15":       {"MacBook Air (M2, 15\", 2023)", 5800, 1000, 15},
+	"Mac15,12":       {"MacBook Air (M3, 13\", 2024)", 4600, 1000, 15},
+	"Mac15,13":       {"MacBook Air (M3, 15\", 2024)", 5800, 1000, 15},
+	"MacBookPro14,1": {"MacBook Pro (13\", 2017)", 4780, 1000, 10},
+	"MacBookPro15,1": {"MacBook Pro (15\", 2018)", 7340, 1000, 10},
+	"MacBookPro15,2": {"MacBook Pro (13\", 2018)", 5090, 1000, 10},
+	"MacBookPro16,1": {"MacBook Pro (16\", 2019)", 8790, 1000, 11},
+	"MacBookPro16,2": {"MacBook Pro (13\", 2020, Intel)", 5100, 1000, 10},
+	"MacBookPro17,1": {"MacBook Pro (M1, 13\", 2020)", 5100, 1000, 17},
+	"MacBookPro18,1": {"MacBook Pro (M1 Pro/Max, 16\", 2021)", 8690, 1000, 14},
+	"MacBookPro18,2": {"MacBook Pro (M1 Max, 16\", 2021)", 8690, 1000, 14},
+	"MacBookPro18,3": {"MacBook Pro (M1 Pro, 14\", 2021)", 6070, 1000, 11},
+	"MacBookPro18,4": {"MacBook Pro (M1 Max, 14\", 2021)", 6070, 1000, 11},
+	"Mac14,7":        {"MacBook Pro (M2, 13\", 2022)", 5100, 1000, 17},
+	"Mac14,5":        {"MacBook Pro (M2 Max, 14\", 2023)", 6070, 1000, 12},
+	"Mac14,9":        {"MacBook Pro (M2 Pro, 14\", 2023)", 6070, 1000, 12},
+	"Mac14,6":        {"MacBook Pro (M2 Max, 16\", 2023)", 8690, 1000, 15},
+	"Mac14,10":       {"MacBook Pro (M2 Pro, 16\", 2023)", 8690, 1000, 15},
+	"Mac15,3":        {"MacBook Pro (M3, 14\", 2023)", 6070, 1000, 15},
+	"Mac15,6":        {"MacBook Pro (M3 Pro, 14\", 2023)", 6070, 1000, 12},
+	"Mac15,7":        {"MacBook Pro (M3 Pro, 16\", 2023)", 8690, 1000, 15},
+	"Mac15,8":        {"MacBook Pro (M3 Max, 14\", 2023)", 6070, 1000, 12},
+	"Mac15,9":        {"MacBook Pro (M3 Max, 16\", 2023)", 8690, 1000, 15},
+}
+
+var (
+	hwModelOnce sync.Once
+	hwModel     string
+)
+
+// hardwareModel возвращает идентификатор модели (sysctl hw.model) или
+// пустую строку на других ОС. Значение читается один раз за запуск.
+func hardwareModel() string {
+	hwModelOnce.Do(func() {
+		if runtime.GOOS != "darwin" {
+			return
+		}
+		if out, err := runCommand("sysctl", "-n", "hw.model"); err == nil {
+			hwModel = strings.TrimSpace(string(out))
+		}
+	})
+	return hwModel
+}
+
+// ModelBenchmark – сравнение батареи с типичными показателями ее модели
+type ModelBenchmark struct {
+	Identifier string // hw.model; пусто – модель не определена
+	Model      MacModel
+	Known      bool // модель есть в справочнике
+	Cycles     int
+	Wear       float64
+	Design     int           // паспортная ёмкость по данным батареи, мАч
+	Runtime    time.Duration // измеренное время работы от полного заряда; 0 – нет данных
+}
+
+// newModelBenchmark сравнивает последнее измерение с показателями модели.
+// drainRate – средняя скорость разрядки, %/ч (0 – не измерена).
+func newModelBenchmark(latest Measurement, drainRate float64) ModelBenchmark {
+	id := hardwareModel()
+	model, known := macModels[id]
+	if !known {
+		model = genericMacModel
+	}
+	b := ModelBenchmark{
+		Identifier: id,
+		Model:      model,
+		Known:      known,
+		Cycles:     latest.CycleCount,
+		Wear:       computeWear(latest.DesignCapacity, latest.FullChargeCap),
+		Design:     latest.DesignCapacity,
+	}
+	if drainRate > 0 {
+		b.Runtime = time.Duration(100 / drainRate * float64(time.Hour))
+	}
+	return b
+}
+
+// TypicalWear возвращает типичный износ модели при текущем числе циклов, %
+func (b ModelBenchmark) TypicalWear() float64 {
+	return float64(100-appleRetainedCapacity) * float64(b.Cycles) / float64(b.Model.CycleLimit)
+}
+
+// CycleResource возвращает оставшийся ресурс циклов, %
+func (b ModelBenchmark) CycleResource() float64 {
+	if b.Cycles >= b.Model.CycleLimit {
+		return 0
+	}
+	return float64(b.Model.CycleLimit-b.Cycles) / float64(b.Model.CycleLimit) * 100
+}
+
+// WearSummary сравнивает износ с типичным для модели
+func (b ModelBenchmark) WearSummary() string {
+	return T("report.model.wear", b.Model.Name, b.Cycles, b.TypicalWear(), b.Wear)
+}
+
+// RuntimeSummary сравнивает измеренное время работы с заявленным;
+// пустая строка – нет данных о модели или о разрядке
+func (b ModelBenchmark) RuntimeSummary() string {
+	if b.Model.RuntimeHours <= 0 || b.Runtime <= 0 {
+		return ""
+	}
+	typical := time.Duration(b.Model.RuntimeHours * float64(time.Hour))
+	return T("report.model.runtime", formatDuration(typical), formatDuration(b.Runtime))
+}
+
+// DesignSummary предупреждает, если паспортная ёмкость батареи заметно
+// отличается от ёмкости модели – признак неоригинальной батареи
+func (b ModelBenchmark) DesignSummary() string {
+	if b.Model.DesignCapacity <= 0 || b.Design <= 0 {
+		return ""
+	}
+	diff := float64(b.Design-b.Model.DesignCapacity) / float64(b.Model.DesignCapacity) * 100
+	if diff > -10 && diff < 10 {
+		return ""
+	}
+	return T("report.model.design", b.Design, b.Model.DesignCapacity)
+}
+
+// Lines возвращает строки сравнения для отчетов
+func (b ModelBenchmark) Lines() []string {
+	lines := []string{b.WearSummary(), T("report.model.cycles", b.CycleResource(), b.Model.CycleLimit)}
+	for _, line := range []string{b.RuntimeSummary(), b.DesignSummary()} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// sessionsDrainRate возвращает среднюю скорость разрядки (%/ч) по сессиям
+// разрядки, взвешенную по длительности
+func sessionsDrainRate(sessions []SessionRecord) float64 {
+	var weighted, seconds float64
+	for _, s := range sessions {
+		if s.Kind == sessionDischarge {
+			weighted += s.AvgRate * float64(s.DurationSeconds)
+			seconds += float64(s.DurationSeconds)
+		}
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return weighted / seconds
+}
+
+// Label возвращает название модели с идентификатором hw.model
+func (b ModelBenchmark) Label() string {
+	if b.Identifier == "" {
+		return b.Model.Name
+	}
+	return fmt.Sprintf("%s (%s)", b.Model.Name, b.Identifier)
+}
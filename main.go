@@ -6,22 +6,22 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"math"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -33,6 +33,7 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -56,7 +57,7 @@ func getDataDir() (string, error) {
 		} else {
 			homeDir, err := os.UserHomeDir()
 			if err != nil {
-				return "", fmt.Errorf("не удалось получить домашнюю папку: %w", err)
+				return "", fmt.Errorf(T("err.home"), err)
 			}
 			dataDir = filepath.Join(homeDir, "AppData", "Local", "batmon")
 		}
@@ -65,7 +66,7 @@ func getDataDir() (string, error) {
 		// macOS: ~/.local/share/batmon (XDG-совместимо, как на Linux)
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return "", fmt.Errorf("не удалось получить домашнюю папку: %w", err)
+			return "", fmt.Errorf(T("err.home"), err)
 		}
 		// Используем XDG_DATA_HOME или ~/.local/share
 		if xdgDataHome := os.Getenv("XDG_DATA_HOME"); xdgDataHome != "" {
@@ -78,7 +79,7 @@ func getDataDir() (string, error) {
 		// Linux и другие Unix: ~/.local/share/batmon (XDG Base Directory)
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return "", fmt.Errorf("не удалось получить домашнюю папку: %w", err)
+			return "", fmt.Errorf(T("err.home"), err)
 		}
 		
 		// Используем XDG_DATA_HOME если установлена, иначе ~/.local/share
@@ -91,7 +92,7 @@ func getDataDir() (string, error) {
 	
 	// Создаем папку если её нет
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
-		return "", fmt.Errorf("не удалось создать папку для данных: %w", err)
+		return "", fmt.Errorf(T("err.data_dir"), err)
 	}
 	
 	return dataDir, nil
@@ -99,10 +100,20 @@ func getDataDir() (string, error) {
 
 // getDBPath возвращает путь к файлу базы данных
 func getDBPath() string {
+	if dbPathOverride != "" {
+		return dbPathOverride
+	}
+	if dir := syncDir(); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logInfof("Не удалось создать папку синхронизации %s: %v", dir, err)
+		}
+		return filepath.Join(dir, "batmon.sqlite")
+	}
+
 	dataDir, err := getDataDir()
 	if err != nil {
 		// Fallback на текущую директорию если не можем создать папку данных
-		log.Printf("Не удалось создать папку данных, используем текущую папку: %v", err)
+		logInfof("Не удалось создать папку данных, используем текущую папку: %v", err)
 		return "batmon.sqlite"
 	}
 	
@@ -113,7 +124,7 @@ func getDBPath() string {
 func getDocumentsDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("не удалось получить домашнюю папку: %w", err)
+		return "", fmt.Errorf(T("err.home"), err)
 	}
 	
 	documentsDir := filepath.Join(homeDir, "Documents")
@@ -121,25 +132,9 @@ func getDocumentsDir() (string, error) {
 }
 
 // getExportPath возвращает полный путь для экспортируемого файла
+// с подстановками шаблона (см. resolveExportPath)
 func getExportPath(filename string) (string, error) {
-	// Если путь уже абсолютный, используем как есть
-	if filepath.IsAbs(filename) {
-		return filename, nil
-	}
-	
-	// Если содержит разделители пути, используем как есть (относительный путь)
-	if strings.Contains(filename, string(filepath.Separator)) {
-		return filename, nil
-	}
-	
-	// Иначе сохраняем в Documents
-	documentsDir, err := getDocumentsDir()
-	if err != nil {
-		// Fallback на текущую директорию
-		return filename, nil
-	}
-	
-	return filepath.Join(documentsDir, filename), nil
+	return resolveExportPath(filename, exportVars{At: timeNow()})
 }
 
 // TrendAnalysis содержит результат анализа тренда
@@ -159,14 +154,55 @@ type ChargeCycle struct {
 	CapacityLoss int    // потеря емкости за цикл
 }
 
+// HealthAnalysis содержит результат анализа здоровья батареи
+type HealthAnalysis struct {
+	WearPercentage      float64              // износ, %
+	CycleCount          int                  // циклы по данным контроллера
+	Anomalies           []Anomaly            // аномалии текущей батареи
+	DischargeRate       float64              // робастная скорость разрядки, мАч/ч
+	ValidIntervals      int                  // интервалов в расчете скорости
+	Trend               TrendAnalysis        // тренд ёмкости
+	ChargeCycles        []ChargeCycle        // циклы заряда-разряда
+	BatteryReplacements []BatteryReplacement // замены батареи в истории
+	HealthStatus        string               // словесная оценка
+	HealthScore         int                  // рейтинг 0-100
+	ScoreModel          HealthModel          // модель, посчитавшая рейтинг
+	ScoreBreakdown      []ScoreFactor        // из чего сложился рейтинг
+	Recommendations     []Recommendation
+}
+
 // DataCollector управляет оптимизированным сбором данных
 type DataCollector struct {
 	db               *sqlx.DB
+	writes           *writeQueue // измерения, ожидающие пакетной записи в БД
+	storage          *syncStorage // блокировка базы в синхронизируемой папке (nil – база локальная)
+	source           BatterySource // источник данных о батарее
 	buffer           *MemoryBuffer
 	retention        *DataRetention
 	lastProfilerCall time.Time
+	lastAppSample    time.Time // последняя выборка энергопотребления приложений
+	lastPowerLog     time.Time // последнее чтение журнала pmset
+	lastThermal      time.Time // последняя выборка теплового давления
+	lastSessionSync  time.Time // последнее обновление таблицы сессий
+	lastWrite        time.Time // последняя запись измерения в БД
+	health           collectorHealth // итоги попыток сбора для диагностики
+	changes          changeFilter // режим записи только изменений
+	lowBattery       bool      // щадящий режим при низком заряде
+	baselineSerial   *string   // батарея, для которой базовая точка уже записана
+	appSampler       appPowerSampler
+	powerSampler     powerSampler // подробный режим (powermetrics)
+	netSampler       netContextSampler // трафик сети и Bluetooth (collector.net_context)
+	chargers         chargerTracker
+	thermalAlarm     bool // температура выше порога тревоги (уведомление уже отправлено)
+	chargeLimitZone  string // зона советника по заряду: потолок, пол или пусто (сообщение уже отправлено)
+	calibrationReminder atomic.Pointer[CalibrationReminder] // последняя проверка напоминания о калибровке (читает дашборд)
+	healthCode       *int   // уровень последнего оповещения о состоянии батареи (nil – еще не загружен)
+	reportSending    atomic.Bool // отчет по расписанию отправляется в фоне
+	history          *HistoryAnalysis // анализ всей истории, обновляется по каждому измерению
+	lastHistorySave  time.Time
 	pmsetInterval    time.Duration
 	profilerInterval time.Duration
+	events           measurementSubscribers // подписчики на новые измерения (дашборд)
 }
 
 // ReportData содержит все данные для генерации отчета
@@ -174,14 +210,48 @@ type ReportData struct {
 	GeneratedAt     time.Time
 	Latest          Measurement
 	Measurements    []Measurement
-	HealthAnalysis  map[string]interface{}
+	HealthAnalysis  *HealthAnalysis      // nil – нет измерений
 	Wear            float64
 	AvgRate         float64
 	RobustRate      float64
 	ValidIntervals  int
+	DischargePower  float64              // средняя мощность разрядки по энергии, Вт; 0 – напряжение не записывалось
+	PowerIntervals  int                  // интервалов в расчете мощности
+	NominalVoltage  int                  // номинальное напряжение батареи для пересчета в Вт·ч, мВ; 0 – неизвестно
+	Samples         int                  // измерений в анализе (Measurements прорежены для графиков)
 	RemainingTime   time.Duration
-	Anomalies       []string
+	Remaining       RemainingEstimate    // прогноз с доверительным интервалом; Expected совпадает с RemainingTime
+	Anomalies       []Anomaly
 	Recommendations []string
+	Replacements    []BatteryReplacement // замены батареи за всю историю
+	TopApps         []AppEnergyUsage     // топ приложений по расходу батареи за период отчета (по умолчанию – неделя)
+	Range           ReportRange          // период отчета
+	Daily           []DailySummary       // использование по дням (местное время)
+	Sessions        []SessionRecord      // сессии разрядки и зарядки, новые первыми
+	Baseline        *BatteryBaseline     // первая запись о ёмкости батареи (nil – еще нет)
+	DerivedMetrics  []DerivedSeries      // пользовательские метрики из config.json
+	Brightness      []BrightnessDrain    // расход по яркости экрана (пусто – яркость не записывалась)
+	Chargers        []ChargerStats       // адаптеры питания и скорость зарядки с ними
+	History         *HistoryAnalysis     // анализ всей истории текущей батареи (nil – недоступен)
+	HotCharging     []HotChargingWeek    // минуты горячей зарядки по неделям, последняя – текущая
+	Monthly         []MonthlyCapacity    // средняя полная ёмкость по месяцам, включая свернутые измерения
+	ThermalEvents   []ThermalEventRecord // периоды длительного перегрева, новые первыми
+	FullChargeTime  time.Duration        // время на 100% от сети за период отчета
+	FullChargeShare float64              // доля этого времени от наблюдаемого, %
+	ChargeHistogram ChargeHistogram      // время по диапазонам заряда за период отчета
+	Heatmap         UsageHeatmap         // разряд по дням и часам (местное время)
+	Comparison      *SnapshotComparison  // сравнение со снимком (--compare), nil – без сравнения
+	Model           ModelBenchmark       // сравнение с типичными показателями модели Mac
+	Sleep           SleepSummary         // разряд во сне от батареи за период
+	SleepWakes      []SleepWakes         // периоды сна с наибольшей потерей заряда и пробуждения в них
+	WakeReasons     []WakeReason         // самые частые причины пробуждений во сне от батареи
+	Throttling      ThrottlingAnalysis   // троттлинг за период и его связь с температурой и разрядкой
+	Notes           []Note               // заметки пользователя за период по возрастанию
+	Standby         StandbyAnalysis      // саморазряд во сне по неделям, последняя – текущая
+	Charging        ChargingAnalysis     // кривые зарядки за период, новые первыми
+	Calibration     CalibrationReminder  // полные разрядки за всю историю и напоминание о калибровке
+	WearTimeline    []WearStep           // вехи износа текущей батареи по возрастанию
+	Advanced        AdvancedMetrics      // расширенные метрики за период
 }
 
 // MemoryBuffer - буфер в памяти для быстрого доступа к последним измерениям
@@ -267,7 +337,7 @@ func (mb *MemoryBuffer) Size() int {
 func (mb *MemoryBuffer) LoadFromDB(db *sqlx.DB, count int) error {
 	measurements, err := getLastNMeasurements(db, count)
 	if err != nil {
-		return fmt.Errorf("загрузка из БД: %w", err)
+		return fmt.Errorf(T("db.err.load"), err)
 	}
 
 	mb.mu.Lock()
@@ -280,47 +350,52 @@ func (mb *MemoryBuffer) LoadFromDB(db *sqlx.DB, count int) error {
 // shouldCleanup проверяет, нужна ли очистка старых данных
 // DataRetention управляет ретенцией данных в БД
 type DataRetention struct {
-	db              *sqlx.DB
+	mu              sync.Mutex
+	store           MeasurementStore
 	retentionPeriod time.Duration
 	lastCleanup     time.Time
 	cleanupInterval time.Duration
 }
 
 // NewDataRetention создает новый менеджер ретенции данных
-func NewDataRetention(db *sqlx.DB, retentionPeriod time.Duration) *DataRetention {
+func NewDataRetention(store MeasurementStore, retentionPeriod time.Duration) *DataRetention {
 	return &DataRetention{
-		db:              db,
+		store:           store,
 		retentionPeriod: retentionPeriod,
 		lastCleanup:     time.Now(),
 		cleanupInterval: 6 * time.Hour, // Проверка каждые 6 часов
 	}
 }
 
+// SetPeriod меняет срок хранения данных
+func (dr *DataRetention) SetPeriod(period time.Duration) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.retentionPeriod = period
+}
+
 // Cleanup удаляет старые данные из БД
 func (dr *DataRetention) Cleanup() error {
 	if time.Since(dr.lastCleanup) < dr.cleanupInterval {
 		return nil // Еще рано для очистки
 	}
 
-	cutoffTime := time.Now().Add(-dr.retentionPeriod)
-
-	result, err := dr.db.Exec(`
-		DELETE FROM measurements 
-		WHERE timestamp < ?
-	`, cutoffTime.Format(time.RFC3339))
+	dr.mu.Lock()
+	retentionPeriod := dr.retentionPeriod
+	dr.mu.Unlock()
+	cutoffTime := time.Now().Add(-retentionPeriod)
 
+	// Хранилище сворачивает старые измерения в почасовые сводки перед удалением
+	rowsAffected, rolledUp, err := dr.store.Prune(cutoffTime)
 	if err != nil {
-		return fmt.Errorf("очистка старых данных: %w", err)
+		return err
 	}
-
-	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected > 0 {
-		log.Printf("🗑️ Удалено %d старых записей (старше %v)", rowsAffected, dr.retentionPeriod)
+		logInfof("🗑️ Удалено %d старых записей (старше %v), почасовых сводок обновлено: %d", rowsAffected, retentionPeriod, rolledUp)
 
-		// Выполняем VACUUM для освобождения места
-		_, err = dr.db.Exec("VACUUM")
-		if err != nil {
-			log.Printf("⚠️ Ошибка VACUUM: %v", err)
+		// Освобождаем место
+		if err := dr.store.Compact(); err != nil {
+			logWarnf("⚠️ Ошибка %v", err)
 		}
 	}
 
@@ -332,24 +407,15 @@ func (dr *DataRetention) Cleanup() error {
 func (dr *DataRetention) GetStats() (map[string]interface{}, error) {
 	var stats map[string]interface{} = make(map[string]interface{})
 
-	// Общее количество записей
-	var totalCount int
-	err := dr.db.Get(&totalCount, "SELECT COUNT(*) FROM measurements")
+	// Количество записей и диапазон дат
+	span, err := dr.store.Span()
 	if err != nil {
-		return nil, fmt.Errorf("подсчет записей: %w", err)
-	}
-	stats["total_records"] = totalCount
-
-	// Диапазон дат
-	var oldestDate, newestDate string
-	err = dr.db.Get(&oldestDate, "SELECT MIN(timestamp) FROM measurements")
-	if err == nil {
-		stats["oldest_record"] = oldestDate
+		return nil, err
 	}
-
-	err = dr.db.Get(&newestDate, "SELECT MAX(timestamp) FROM measurements")
-	if err == nil {
-		stats["newest_record"] = newestDate
+	stats["total_records"] = span.Count
+	if span.Count > 0 {
+		stats["oldest_record"] = span.Oldest
+		stats["newest_record"] = span.Newest
 	}
 
 	// Размер БД файла
@@ -435,42 +501,42 @@ func analyzeAdvancedMetrics(measurements []Measurement) AdvancedMetrics {
 	// Тренд энергопотребления
 	if len(powers) >= 3 {
 		recent := powers[len(powers)-3:]
-		trend := "стабильное"
+		trend := T("metrics.trend.stable")
 
 		if len(recent) == 3 {
 			if recent[2] > recent[1] && recent[1] > recent[0] {
-				trend = "растущее потребление"
+				trend = T("metrics.trend.rising")
 			} else if recent[2] < recent[1] && recent[1] < recent[0] {
-				trend = "снижающееся потребление"
+				trend = T("metrics.trend.falling")
 			}
 		}
 		metrics.PowerTrend = trend
 	}
 
-	// Общий рейтинг здоровья
-	healthScore := 100
+	// Общий рейтинг здоровья: 100 минус штрафы, каждый штраф сохраняется
+	// отдельным фактором для разбора рейтинга
+	var factors []ScoreFactor
 
 	// Снижаем за износ
 	if latest.DesignCapacity > 0 {
 		wear := float64(latest.DesignCapacity-latest.FullChargeCap) / float64(latest.DesignCapacity) * 100
-		healthScore -= int(wear * 0.5) // Износ влияет на 50%
+		factors = append(factors, ScoreFactor{T("score.wear"), fmt.Sprintf("%.1f%%", wear), -int(wear * 0.5)}) // Износ влияет на 50%
 	}
 
-	// Снижаем за циклы
-	cycleImpact := latest.CycleCount / 10 // Каждые 10 циклов = -1 балл
-	healthScore -= cycleImpact
+	// Снижаем за циклы: каждые 10 циклов = -1 балл
+	factors = append(factors, ScoreFactor{T("score.cycles"), fmt.Sprint(latest.CycleCount), -(latest.CycleCount / 10)})
 
-	// Снижаем за температуру
-	if latest.Temperature > 45 {
-		healthScore -= (latest.Temperature - 45) // Каждый градус свыше 45°C = -1 балл
-	}
+	// Снижаем за температуру: каждый градус свыше 45°C = -1 балл
+	factors = append(factors, ScoreFactor{T("score.temperature"), fmt.Sprintf("%d°C", latest.Temperature), -max(latest.Temperature-45, 0)})
 
 	// Учитываем стабильность напряжения
-	if metrics.VoltageStability < 95 {
-		healthScore -= int(95 - metrics.VoltageStability)
+	if metrics.VoltageStability > 0 {
+		factors = append(factors, ScoreFactor{T("score.voltage_stability"), fmt.Sprintf("%.1f%%", metrics.VoltageStability),
+			-int(math.Max(0, 95-metrics.VoltageStability))})
 	}
 
-	metrics.HealthRating = int(math.Max(0, float64(healthScore)))
+	metrics.HealthBreakdown = factors
+	metrics.HealthRating = scoreFromFactors(factors)
 
 	// Статус от Apple
 	metrics.AppleStatus = latest.AppleCondition
@@ -489,20 +555,26 @@ func analyzeAdvancedMetrics(measurements []Measurement) AdvancedMetrics {
 
 // Measurement – запись о состоянии батареи.
 type Measurement struct {
-	ID              int    `db:"id"`
-	Timestamp       string `db:"timestamp"`   // ISO‑8601 UTC
-	Percentage      int    `db:"percentage"`  // % заряда
-	State           string `db:"state"`       // charging / discharging
-	CycleCount      int    `db:"cycle_count"` // кол-во циклов
-	FullChargeCap   int    `db:"full_charge_capacity"`
-	DesignCapacity  int    `db:"design_capacity"`
-	CurrentCapacity int    `db:"current_capacity"`
-	Temperature     int    `db:"temperature"` // температура батареи в °C
+	ID              int    `db:"id" json:"id"`
+	Timestamp       string `db:"timestamp" json:"timestamp"`     // ISO‑8601 UTC
+	Percentage      int    `db:"percentage" json:"percentage"`   // % заряда
+	State           string `db:"state" json:"state"`             // charging / discharging
+	CycleCount      int    `db:"cycle_count" json:"cycle_count"` // кол-во циклов
+	FullChargeCap   int    `db:"full_charge_capacity" json:"full_charge_capacity"`
+	DesignCapacity  int    `db:"design_capacity" json:"design_capacity"`
+	CurrentCapacity int    `db:"current_capacity" json:"current_capacity"`
+	Temperature     int    `db:"temperature" json:"temperature"` // температура батареи в °C
 	// Расширенные метрики (Этап 6)
-	Voltage        int    `db:"voltage"`         // Напряжение в мВ
-	Amperage       int    `db:"amperage"`        // Ток в мА (+ заряд, - разряд)
-	Power          int    `db:"power"`           // Мощность в мВт
-	AppleCondition string `db:"apple_condition"` // Статус от Apple
+	Voltage        int    `db:"voltage" json:"voltage"`                 // Напряжение в мВ
+	Amperage       int    `db:"amperage" json:"amperage"`               // Ток в мА (+ заряд, - разряд)
+	Power          int    `db:"power" json:"power"`                     // Мощность в мВт
+	AppleCondition string `db:"apple_condition" json:"apple_condition"` // Статус от Apple
+	BatterySerial  string `db:"battery_serial" json:"battery_serial"`   // Серийный номер батареи
+	// Контекст использования
+	Brightness int    `db:"brightness" json:"brightness"` // яркость встроенного экрана в %, 0 – нет данных
+	LidState   string `db:"lid_state" json:"lid_state"`   // open / closed, пусто – нет данных
+	// Напряжения ячеек в мВ через запятую, пусто – контроллер не сообщает
+	CellVoltages string `db:"cell_voltages" json:"cell_voltages,omitempty"`
 }
 
 // AdvancedMetrics содержит расширенные метрики анализа
@@ -512,6 +584,7 @@ type AdvancedMetrics struct {
 	ChargingEfficiency float64 `json:"charging_efficiency"` // Эффективность зарядки
 	PowerTrend         string  `json:"power_trend"`         // Тренд энергопотребления
 	HealthRating       int     `json:"health_rating"`       // Общий рейтинг здоровья (0-100)
+	HealthBreakdown    []ScoreFactor `json:"health_breakdown,omitempty"` // из чего сложился рейтинг
 	AppleStatus        string  `json:"apple_status"`        // Статус от Apple (Normal, Replace Soon, etc.)
 }
 
@@ -527,6 +600,9 @@ const (
 	StateExport
 	StateSettings
 	StateHelp
+	StateCalibration
+	StateLogs
+	StateDoctor
 )
 
 // App - основная модель приложения Bubble Tea
@@ -539,6 +615,11 @@ type App struct {
 	menu       MenuModel
 	dashboard  DashboardModel
 	report     ReportModel
+	settings   SettingsModel
+	calibration CalibrationModel
+	logs       LogsModel
+	doctor     DoctorModel
+	calibrationPauseSeen int // тест, о паузе которого интерфейс уже сообщил
 	
 	// Сервисы
 	dataService *DataService
@@ -546,9 +627,17 @@ type App struct {
 	// Общие данные
 	measurements []Measurement
 	latest       *Measurement
+	chartData    []Measurement // измерения за окно графиков дашборда
+	powerEvents  []PowerEvent  // события питания за окно графиков (отметки под графиком заряда)
+	notes        []Note        // заметки за окно графиков
+	power        *PowerSample  // последняя выборка powermetrics для панели SoC
+	assertions   PowerAssertions // системные запреты сна для дашборда
+	assertionsOK bool
 	
 	// Экспорт
-	exportStatus string
+	export ExportForm
+
+	refreshInterval time.Duration // период обновления дашборда
 	
 	// Скроллинг отчета
 	reportScrollY int
@@ -575,8 +664,12 @@ type DashboardModel struct {
 	wearGauge     progress.Model
 	measureTable  table.Model
 	
-	lastUpdate time.Time
-	updating   bool
+	lastUpdate  time.Time
+	updating    bool
+	chartView   ChartView     // видимое окно истории на графиках (клавиши w, +/-, h/l)
+	secondaryChart int        // правый график: ёмкость, температура или мощность (клавиша t)
+	cursorOn    bool          // режим перекрестья на графиках (клавиша x)
+	cursorCol   int           // колонка перекрестья в области данных графика
 }
 
 // ReportModel - модель детального отчета
@@ -589,10 +682,21 @@ type ReportModel struct {
 	widgets       []ReportWidget    // Виджеты для отображения
 	historyTable  table.Model       // Таблица истории
 	filterState   string            // Фильтр для истории
-	sortColumn    int               // Колонка для сортировки
+	sortColumn    int               // Колонка для сортировки (индекс в historyColumns)
 	sortDesc      bool              // Направление сортировки
+	historyPage   int               // Текущая страница истории
+	historyTotal  int               // Число измерений под фильтром
+	historyRows   []Measurement     // Измерения текущей страницы
+	historyDetails bool             // Открыта карточка выбранного измерения
+	heatmapByTime  bool             // Тепловая карта окрашена по времени от батареи, а не по скорости разряда
+	filterInput   textinput.Model   // Поле ввода выражения фильтра истории
+	filterExpr    string            // Примененное выражение фильтра
+	filter        historyFilter     // Условия SQL из filterExpr
+	filterErr     error             // Ошибка разбора выражения
 	lastUpdate    time.Time         // Время последнего обновления
 	animationTick int               // Счетчик для анимаций
+	rangePreset   int               // Выбранный период (индекс в reportRangePresets)
+	cache         ReportCache       // Собранные данные отчета
 }
 
 // ReportWidget - виджет для отображения в отчете
@@ -626,12 +730,20 @@ type InfoItem struct {
 // DataService - сервис для работы с данными батареи
 type DataService struct {
 	collector        *DataCollector
+	store            *Store // общее соединение с БД
 	db               *sqlx.DB
 	buffer           *MemoryBuffer
 	ctx              context.Context
 	cancel           context.CancelFunc
 	caffeinate       *exec.Cmd
+	caffeinateDone   chan struct{} // закрывается, когда caffeinate завершился
 	caffeineActive   bool
+	replay           *replayFeed // воспроизведение записи вместо сбора данных
+	interval         chan time.Duration // новый интервал опроса из настроек
+	events           <-chan Measurement // новые измерения коллектора
+	unsubscribe      func()
+	nextSample       atomic.Int64 // время следующего измерения (UnixNano), 0 – неизвестно
+	shutdownOnce     sync.Once
 }
 
 // menuItem реализует list.Item интерфейс
@@ -648,192 +760,51 @@ func (i menuItem) Description() string { return i.desc }
 func initDB(path string) (*sqlx.DB, error) {
 	db, err := sqlx.Connect("sqlite3", path)
 	if err != nil {
-		return nil, fmt.Errorf("соединение с БД: %w", err)
+		return nil, fmt.Errorf(T("err.db_connect"), err)
+	}
+	if path == ":memory:" {
+		// У каждого соединения своя БД в памяти – держим ровно одно
+		db.SetMaxOpenConns(1)
 	}
 
 	// Включаем WAL режим для устранения блокировок при одновременном чтении/записи
 	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		log.Printf("предупреждение: не удалось включить WAL режим: %v", err)
-	}
-
-	schema := `CREATE TABLE IF NOT EXISTS measurements (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		timestamp TEXT NOT NULL,
-		percentage INTEGER,
-		state TEXT,
-		cycle_count INTEGER,
-		full_charge_capacity INTEGER,
-		design_capacity INTEGER,
-		current_capacity INTEGER,
-		temperature INTEGER DEFAULT 0,
-		voltage INTEGER DEFAULT 0,
-		amperage INTEGER DEFAULT 0,
-		power INTEGER DEFAULT 0,
-		apple_condition TEXT DEFAULT ''
-	);`
-	if _, err = db.Exec(schema); err != nil {
-		return nil, fmt.Errorf("создание таблицы: %w", err)
+		logInfof("предупреждение: не удалось включить WAL режим: %v", err)
 	}
 
-	// Добавляем новые столбцы к существующей таблице (для обновления схемы)
-	alterQueries := []string{
-		"ALTER TABLE measurements ADD COLUMN voltage INTEGER DEFAULT 0",
-		"ALTER TABLE measurements ADD COLUMN amperage INTEGER DEFAULT 0",
-		"ALTER TABLE measurements ADD COLUMN power INTEGER DEFAULT 0",
-		"ALTER TABLE measurements ADD COLUMN apple_condition TEXT DEFAULT ''",
-	}
-
-	for _, query := range alterQueries {
-		db.Exec(query) // Игнорируем ошибки - столбцы могут уже существовать
+	if err := migrateUp(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf(T("db.err.migrate"), err)
 	}
 
 	return db, nil
 }
 
-// parsePMSet получает процент заряда и состояние питания из pmset.
-func parsePMSet() (int, string, error) {
-	cmd := exec.Command("pmset", "-g", "batt")
-	out, err := cmd.Output()
-	if err != nil {
-		return 0, "", fmt.Errorf("pmset: %w", err)
-	}
-	scanner := bufio.NewScanner(bytes.NewReader(out))
-	re := regexp.MustCompile(`(\d+)%\s*;\s*(\w+)`)
-	for scanner.Scan() {
-		line := scanner.Text()
-		m := re.FindStringSubmatch(line)
-		if len(m) == 3 {
-			pct, _ := strconv.Atoi(m[1])
-			state := strings.ToLower(m[2])
-			return pct, state, nil
-		}
-	}
-	if err = scanner.Err(); err != nil {
-		return 0, "", fmt.Errorf("сканирование pmset: %w", err)
-	}
-	return 0, "", fmt.Errorf("данные о батарее не найдены")
-}
-
-// parseSystemProfiler получает данные из system_profiler.
-// На Apple Silicon многие параметры недоступны, используем то, что есть
-func parseSystemProfiler() (cycle, fullCap, designCap, currCap, temperature, voltage, amperage int, condition string, err error) {
-	cmd := exec.Command("system_profiler", "SPPowerDataType", "-detailLevel", "full")
-	out, cmdErr := cmd.Output()
-	if cmdErr != nil {
-		return 0, 0, 0, 0, 0, 0, 0, "", fmt.Errorf("system_profiler: %w", cmdErr)
-	}
-	scanner := bufio.NewScanner(bytes.NewReader(out))
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		switch {
-		case strings.HasPrefix(line, "Cycle Count:"):
-			val := strings.TrimSpace(strings.TrimPrefix(line, "Cycle Count:"))
-			cycle, _ = strconv.Atoi(val)
-		case strings.HasPrefix(line, "Condition:"):
-			condition = strings.TrimSpace(strings.TrimPrefix(line, "Condition:"))
-		}
-	}
-	if scanErr := scanner.Err(); scanErr != nil {
-		return 0, 0, 0, 0, 0, 0, 0, "", fmt.Errorf("сканирование system_profiler: %w", scanErr)
-	}
-	return cycle, fullCap, designCap, currCap, temperature, voltage, amperage, condition, nil
+// runCommand выполняет системную утилиту и возвращает её stdout.
+// Вынесено в переменную, чтобы тесты могли подставлять записанный вывод pmset/ioreg.
+var runCommand = func(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
 }
 
-// parseIORegistry получает подробные данные о батарее из ioreg
-func parseIORegistry() (cycle, fullCap, designCap, currCap, temperature, voltage, amperage int, condition string, err error) {
-	cmd := exec.Command("ioreg", "-rn", "AppleSmartBattery")
-	out, cmdErr := cmd.Output()
-	if cmdErr != nil {
-		return 0, 0, 0, 0, 0, 0, 0, "", fmt.Errorf("ioreg: %w", cmdErr)
-	}
-
-	scanner := bufio.NewScanner(bytes.NewReader(out))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Парсим параметры в формате "ParameterName" = Value
-		if strings.Contains(line, " = ") {
-			parts := strings.SplitN(line, " = ", 2)
-			if len(parts) != 2 {
-				continue
-			}
-
-			key := strings.Trim(parts[0], `"`)
-			value := strings.TrimSpace(parts[1])
-
-			switch key {
-			case "CycleCount":
-				cycle, _ = strconv.Atoi(value)
-			case "AppleRawMaxCapacity":
-				fullCap, _ = strconv.Atoi(value)
-			case "DesignCapacity":
-				designCap, _ = strconv.Atoi(value)
-			case "AppleRawCurrentCapacity":
-				currCap, _ = strconv.Atoi(value)
-			case "Temperature":
-				// Температура в сотых долях градуса
-				if temp, err := strconv.Atoi(value); err == nil {
-					temperature = temp / 100
-				}
-			case "Voltage":
-				voltage, _ = strconv.Atoi(value)
-			case "Amperage":
-				// Amperage может быть большим uint64, которое представляет отрицательное число
-				if amp, err := strconv.ParseUint(value, 10, 64); err == nil {
-					if amp > 9223372036854775807 { // больше максимального int64
-						// Это отрицательное число, представленное как uint64
-						amperage = int(int64(amp))
-					} else {
-						amperage = int(amp)
-					}
-				}
-			}
-		}
-	}
-
-	if scanErr := scanner.Err(); scanErr != nil {
-		return 0, 0, 0, 0, 0, 0, 0, "", fmt.Errorf("сканирование ioreg: %w", scanErr)
-	}
-
-	// Получаем состояние батареи из system_profiler
-	spCycle, _, _, _, _, _, _, spCondition, spErr := parseSystemProfiler()
-	if spErr == nil {
-		condition = spCondition
-		if cycle == 0 {
-			cycle = spCycle
-		}
-	}
-
-	return cycle, fullCap, designCap, currCap, temperature, voltage, amperage, condition, nil
-}
+// timeNow возвращает текущее время для отметок измерений (подменяется в тестах).
+var timeNow = time.Now
 
 // insertMeasurement сохраняет Measurement в БД.
 func insertMeasurement(db *sqlx.DB, m *Measurement) error {
-	query := `INSERT INTO measurements (
-		timestamp, percentage, state, cycle_count,
-		full_charge_capacity, design_capacity, current_capacity, temperature,
-		voltage, amperage, power, apple_condition)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err := db.Exec(query,
-		m.Timestamp, m.Percentage, m.State, m.CycleCount,
-		m.FullChargeCap, m.DesignCapacity, m.CurrentCapacity, m.Temperature,
-		m.Voltage, m.Amperage, m.Power, m.AppleCondition)
-	return err
+	return newSQLiteMeasurements(db).InsertMeasurements([]Measurement{*m}, nil)
 }
 
 // getLastNMeasurements возвращает последние n измерений в хронологическом порядке.
 func getLastNMeasurements(db *sqlx.DB, n int) ([]Measurement, error) {
-	var ms []Measurement
-	query := `SELECT * FROM measurements ORDER BY timestamp DESC LIMIT ?`
-	if err := db.Select(&ms, query, n); err != nil {
-		return nil, err
-	}
-	// Переворачиваем в возрастающий порядок по времени.
-	for i, j := 0, len(ms)-1; i < j; i, j = i+1, j-1 {
-		ms[i], ms[j] = ms[j], ms[i]
+	return newSQLiteMeasurements(db).LastMeasurements(n)
+}
+
+// lastMeasurements возвращает не более n последних измерений из среза
+func lastMeasurements(ms []Measurement, n int) []Measurement {
+	if len(ms) > n {
+		return ms[len(ms)-n:]
 	}
-	return ms, nil
+	return ms
 }
 
 // computeAvgRate вычисляет среднюю скорость разрядки (мАч/час) за последние n интервалов.
@@ -857,6 +828,9 @@ func computeAvgRate(ms []Measurement, intervals int) float64 {
 		if err1 != nil || err2 != nil {
 			continue
 		}
+		if isSleepGap(t1, t2) { // разряд во сне считается отдельно
+			continue
+		}
 		timeH := t2.Sub(t1).Hours()
 		totalDiff += diff
 		totalTime += timeH
@@ -885,12 +859,12 @@ func computeWear(designCap, fullCap int) float64 {
 }
 
 // detectBatteryAnomalies анализирует аномальные изменения заряда с нормализованными порогами
-func detectBatteryAnomalies(ms []Measurement) []string {
+func detectBatteryAnomalies(ms []Measurement) []Anomaly {
 	if len(ms) < 2 {
 		return nil
 	}
 
-	var anomalies []string
+	var anomalies []Anomaly
 
 	for i := 0; i < len(ms)-1; i++ {
 		prev := ms[i]
@@ -903,6 +877,9 @@ func detectBatteryAnomalies(ms []Measurement) []string {
 		if err1 == nil && err2 == nil {
 			interval = currTime.Sub(prevTime)
 		}
+		anomaly := func(kind, severity, message string, metrics map[string]float64) Anomaly {
+			return Anomaly{Type: kind, Severity: severity, Start: prevTime, End: currTime, Message: message, Metrics: metrics}
+		}
 
 		// Получаем нормализованные пороги
 		chargeThreshold, capacityThreshold := normalizeAnomalyThresholds(interval)
@@ -910,46 +887,84 @@ func detectBatteryAnomalies(ms []Measurement) []string {
 		// Резкий скачок заряда
 		chargeDiff := curr.Percentage - prev.Percentage
 		if chargeDiff > chargeThreshold {
-			anomalies = append(anomalies, fmt.Sprintf("Резкий рост заряда: %d%% → %d%% за %.1f мин (%s)",
-				prev.Percentage, curr.Percentage, interval.Minutes(), curr.Timestamp[11:19]))
+			anomalies = append(anomalies, anomaly(anomalyChargeJump, alertInfo,
+				T("anomaly.charge_jump",
+					prev.Percentage, curr.Percentage, interval.Minutes(), curr.Timestamp[11:19]),
+				map[string]float64{"delta_pct": float64(chargeDiff), "minutes": interval.Minutes()}))
 		}
 
 		// Резкое падение заряда
 		if chargeDiff < -chargeThreshold {
-			anomalies = append(anomalies, fmt.Sprintf("Резкое падение заряда: %d%% → %d%% за %.1f мин (%s)",
-				prev.Percentage, curr.Percentage, interval.Minutes(), curr.Timestamp[11:19]))
+			anomalies = append(anomalies, anomaly(anomalyChargeDrop, alertWarning,
+				T("anomaly.charge_drop",
+					prev.Percentage, curr.Percentage, interval.Minutes(), curr.Timestamp[11:19], displayContextNote(prev)),
+				map[string]float64{"delta_pct": float64(chargeDiff), "minutes": interval.Minutes()}))
 		}
 
 		// Неожиданное изменение состояния
 		if prev.State != curr.State {
-			anomalies = append(anomalies, fmt.Sprintf("Смена состояния: %s → %s (%s)",
-				prev.State, curr.State, curr.Timestamp[11:19]))
+			anomalies = append(anomalies, anomaly(anomalyStateChange, alertInfo,
+				T("anomaly.state_change", prev.State, curr.State, curr.Timestamp[11:19]), nil))
 		}
 
 		// Резкое изменение емкости
 		capacityDiff := abs(curr.CurrentCapacity - prev.CurrentCapacity)
 		if capacityDiff > capacityThreshold {
-			anomalies = append(anomalies, fmt.Sprintf("Резкое изменение емкости: %d → %d мАч за %.1f мин (%s)",
-				prev.CurrentCapacity, curr.CurrentCapacity, interval.Minutes(), curr.Timestamp[11:19]))
+			anomalies = append(anomalies, anomaly(anomalyCapacityJump, alertWarning,
+				T("anomaly.capacity_jump",
+					prev.CurrentCapacity, curr.CurrentCapacity, interval.Minutes(), curr.Timestamp[11:19]),
+				map[string]float64{"delta_mah": float64(curr.CurrentCapacity - prev.CurrentCapacity), "minutes": interval.Minutes()}))
+		}
+
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if a, ok := detectVoltageSag(prev, curr, prevTime, currTime); ok {
+			anomalies = append(anomalies, a)
+		}
+		if a, ok := detectSleepDrain(prev, curr, prevTime, currTime); ok {
+			anomalies = append(anomalies, a)
 		}
 	}
 
+	anomalies = append(anomalies, detectCapacityGlitches(ms)...)
+
+	// Длительный перегрев
+	anomalies = append(anomalies, thermalEventAnomalies(ms)...)
+
+	// Разбаланс ячеек
+	anomalies = append(anomalies, cellImbalanceAnomalies(ms)...)
+
 	return anomalies
 }
 
 // computeAvgRateRobust вычисляет среднюю скорость с исключением аномалий
 func computeAvgRateRobust(ms []Measurement, intervals int) (float64, int) {
+	var totalDiff, totalTime float64
+	validIntervals := 0
+	eachRobustInterval(ms, intervals, func(prev, curr Measurement, hours float64) {
+		totalDiff += float64(prev.CurrentCapacity - curr.CurrentCapacity)
+		totalTime += hours
+		validIntervals++
+	})
+
+	if totalTime == 0 {
+		return 0, validIntervals
+	}
+	return totalDiff / totalTime, validIntervals
+}
+
+// eachRobustInterval вызывает fn для интервалов разрядки среди последних
+// intervals, пропуская аномальные скачки, зарядку и сон
+func eachRobustInterval(ms []Measurement, intervals int, fn func(prev, curr Measurement, hours float64)) {
 	if len(ms) < 2 {
-		return 0, 0
+		return
 	}
 	start := len(ms) - intervals - 1
 	if start < 0 {
 		start = 0
 	}
 
-	var totalDiff, totalTime float64
-	validIntervals := 0
-
 	for i := start; i < len(ms)-1; i++ {
 		prev := ms[i]
 		curr := ms[i+1]
@@ -963,8 +978,7 @@ func computeAvgRateRobust(ms []Measurement, intervals int) (float64, int) {
 			continue
 		}
 
-		diff := float64(prev.CurrentCapacity - curr.CurrentCapacity)
-		if diff <= 0 { // зарядка или отсутствие изменения
+		if prev.CurrentCapacity-curr.CurrentCapacity <= 0 { // зарядка или отсутствие изменения
 			continue
 		}
 
@@ -974,20 +988,12 @@ func computeAvgRateRobust(ms []Measurement, intervals int) (float64, int) {
 			continue
 		}
 
-		timeH := t2.Sub(t1).Hours()
-		if timeH <= 0 || timeH > 2 { // Пропускаем слишком короткие или длинные интервалы
+		// Пропускаем пустые интервалы и сон: разряд во сне считается отдельно
+		if !t2.After(t1) || isSleepGap(t1, t2) {
 			continue
 		}
-
-		totalDiff += diff
-		totalTime += timeH
-		validIntervals++
-	}
-
-	if totalTime == 0 {
-		return 0, validIntervals
+		fn(prev, curr, t2.Sub(t1).Hours())
 	}
-	return totalDiff / totalTime, validIntervals
 }
 
 // abs возвращает абсолютное значение
@@ -1021,7 +1027,7 @@ func analyzeCapacityTrend(measurements []Measurement) TrendAnalysis {
 	}
 
 	// Ищем измерения за последние 30 дней с system_profiler данными
-	now := time.Now()
+	now := timeNow()
 	thirtyDaysAgo := now.AddDate(0, 0, -30)
 
 	var validMeasurements []Measurement
@@ -1194,7 +1200,7 @@ func getStatusLevel(wear float64, percentage int, temperature int, healthScore i
 // formatStateWithEmoji добавляет эмодзи к состоянию батареи
 func formatStateWithEmoji(state string, percentage int) string {
 	if state == "" {
-		return "Неизвестно"
+		return T("state.unknown")
 	}
 
 	stateLower := strings.ToLower(state)
@@ -1203,12 +1209,12 @@ func formatStateWithEmoji(state string, percentage int) string {
 	switch stateLower {
 	case "charging":
 		if percentage >= 90 {
-			return "🔋 " + stateFormatted + " (почти полная)"
+			return "🔋 " + stateFormatted + T("state.almost_full")
 		}
 		return "⚡ " + stateFormatted
 	case "discharging":
 		if percentage < 20 {
-			return "🪫 " + stateFormatted + " (низкий заряд)"
+			return "🪫 " + stateFormatted + T("state.low")
 		} else if percentage < 50 {
 			return "🔋 " + stateFormatted
 		}
@@ -1223,223 +1229,375 @@ func formatStateWithEmoji(state string, percentage int) string {
 }
 
 // analyzeBatteryHealth анализирует общее состояние батареи
-func analyzeBatteryHealth(ms []Measurement) map[string]interface{} {
+func analyzeBatteryHealth(ms []Measurement) *HealthAnalysis {
 	if len(ms) == 0 {
 		return nil
 	}
 
+	// Тренды и аномалии считаем только по текущей батарее: после замены
+	// ёмкость и циклы начинаются заново и иначе дают ложные скачки износа.
+	replacements := detectBatteryReplacements(ms)
+	ms = currentBatterySegment(ms)
+
 	latest := ms[len(ms)-1]
-	analysis := make(map[string]interface{})
+	analysis := &HealthAnalysis{BatteryReplacements: replacements}
 
 	// Основные метрики
 	wear := computeWear(latest.DesignCapacity, latest.FullChargeCap)
-	analysis["wear_percentage"] = wear
-	analysis["cycle_count"] = latest.CycleCount
+	analysis.WearPercentage = wear
+	analysis.CycleCount = latest.CycleCount
 
 	// Анализ аномалий
 	anomalies := detectBatteryAnomalies(ms)
-	analysis["anomalies"] = anomalies
-	analysis["anomaly_count"] = len(anomalies)
+	analysis.Anomalies = anomalies
 
 	// Робастная скорость разрядки
 	avgRate, validIntervals := computeAvgRateRobust(ms, 10)
-	analysis["discharge_rate"] = avgRate
-	analysis["valid_intervals"] = validIntervals
+	analysis.DischargeRate = avgRate
+	analysis.ValidIntervals = validIntervals
 
 	// Анализ трендов
 	trendAnalysis := analyzeCapacityTrend(ms)
-	analysis["trend_analysis"] = trendAnalysis
+	analysis.Trend = trendAnalysis
 
 	// Анализ циклов заряда-разряда
-	chargeCycles := detectChargeCycles(ms)
-	analysis["charge_cycles"] = chargeCycles
+	analysis.ChargeCycles = detectChargeCycles(ms)
+
+	// Оценка здоровья батареи – моделью из config.json
+	health := getConfig().Health
+	score := scoreHealth(HealthModel(health.ScoreModel), healthScoreInput{
+		Wear:      wear,
+		Cycles:    latest.CycleCount,
+		Condition: latest.AppleCondition,
+		Anomalies: len(anomalies),
+		Trend:     trendAnalysis,
+		CycleLife: health.CyclesCritical,
+	})
+	analysis.HealthStatus = score.Status
+	analysis.HealthScore = score.Score
+	analysis.ScoreModel = score.Model
+	analysis.ScoreBreakdown = score.Breakdown
+
+	// Рекомендации – по правилам, пороги и тексты которых настраиваются в config.json
+	ctx := RuleContext{
+		Wear:          wear,
+		Cycles:        latest.CycleCount,
+		Anomalies:     len(anomalies),
+		DischargeRate: avgRate,
+		Percentage:    latest.Percentage,
+		Charging:      latest.State == "charging",
+		Temperature:   latest.Temperature,
+		HealthScore:   score.Score,
+		ThermalLevel:  thermalLevel(latest),
+		ThermalAlert:  thermalAlert(latest),
+	}
+	if brightness, ok := avgDischargeBrightness(ms); ok {
+		ctx.Brightness = brightness
+	}
+	if !trendAnalysis.IsHealthy {
+		ctx.Degradation = -trendAnalysis.DegradationRate
+	}
+	analysis.Recommendations = evaluateRules(effectiveRules(getConfig().Rules), ctx)
 
-	// Оценка здоровья батареи
-	var healthStatus string
-	var healthScore int
+	return analysis
+}
 
-	switch {
-	case wear < 5 && latest.CycleCount < 300:
-		healthStatus = "Отличное"
-		healthScore = 95
-	case wear < 10 && latest.CycleCount < 500:
-		healthStatus = "Хорошее"
-		healthScore = 85
-	case wear < 20 && latest.CycleCount < 800:
-		healthStatus = "Удовлетворительное"
-		healthScore = 70
-	case wear < 30 && latest.CycleCount < 1200:
-		healthStatus = "Требует внимания"
-		healthScore = 50
-	default:
-		healthStatus = "Плохое"
-		healthScore = 30
-	}
+// exportToMarkdown экспортирует отчет в формате Markdown
+func exportToMarkdown(data ReportData, filename string) error {
+	mah := T("unit.mah")
+	content := fmt.Sprintf("# %s\n\n**%s:** %s\n**%s:** %s\n\n## %s\n\n",
+		T("report.title"), T("report.created"), data.GeneratedAt.Format("02.01.2006 15:04:05"),
+		T("report.period"), data.Range.Label(), T("report.summary"))
 
-	// Корректировка на основе аномалий
-	if len(anomalies) > 5 {
-		healthScore -= 10
-		healthStatus += " (нестабильная работа)"
+	if data.HealthAnalysis != nil {
+		content += fmt.Sprintf("- **%s:** %s\n", T("report.health"), T("report.rating", data.HealthAnalysis.HealthStatus, data.HealthAnalysis.HealthScore, data.HealthAnalysis.ScoreModel.Label()))
+	}
+	content += fmt.Sprintf("- **%s:** %d\n", T("report.cycles"), data.Latest.CycleCount)
+	content += fmt.Sprintf("- **%s:** %.1f%%\n", T("report.wear"), data.Wear)
+	if data.RemainingTime > 0 {
+		content += fmt.Sprintf("- **%s:** %s\n", T("report.remaining"), data.Remaining)
+	}
+	if data.Baseline != nil {
+		content += fmt.Sprintf("- **%s** %s\n", T("report.baseline", data.Baseline.Summary(data.Latest)),
+			T("report.baseline.detail", data.Baseline.FullChargeCap, data.Baseline.Date()))
+	}
+	for _, r := range data.Replacements {
+		content += fmt.Sprintf("- **%s** %s\n", r.Marker(), T("report.replacement", r.OldSerial, r.NewSerial))
 	}
 
-	// Корректировка на основе тренда
-	if !trendAnalysis.IsHealthy && trendAnalysis.DegradationRate < -1.0 {
-		healthScore -= 15
-		healthStatus += " (быстрая деградация)"
+	if c := data.Comparison; c != nil {
+		content += "\n## " + c.Title() + "\n\n"
+		if c.BatteryReplaced() {
+			content += "🔁 " + T("report.compare.replaced") + "\n\n"
+		}
+		content += fmt.Sprintf("| %s | %s | %s | %s |\n|----------|------|-------|-----------|\n",
+			T("report.param"), T("report.compare.then"), T("report.compare.now"), T("report.compare.delta"))
+		for _, row := range c.Rows() {
+			content += fmt.Sprintf("| %s | %s | %s | %s |\n", row.Label, row.Then, row.Now, row.Delta)
+		}
 	}
 
-	analysis["health_status"] = healthStatus
-	analysis["health_score"] = healthScore
+	content += "\n## " + T("report.model", data.Model.Label()) + "\n\n"
+	for _, line := range data.Model.Lines() {
+		content += "- " + line + "\n"
+	}
 
-	// Расширенные рекомендации
-	var recommendations []string
+	content += fmt.Sprintf("\n## %s\n\n| %s | %s |\n|----------|----------|\n", T("report.current"), T("report.param"), T("report.value"))
+	content += fmt.Sprintf("| %s | %s |\n", T("report.measured_at"), data.Latest.Timestamp)
+	content += fmt.Sprintf("| %s | %d%% |\n", T("report.charge"), data.Latest.Percentage)
+	content += fmt.Sprintf("| %s | %s |\n", T("report.state"), formatStateForExport(data.Latest.State, data.Latest.Percentage))
+	content += fmt.Sprintf("| %s | %d |\n", T("report.charge_cycles"), data.Latest.CycleCount)
+	content += fmt.Sprintf("| %s | %s |\n", T("report.full_cap"), data.Capacity(data.Latest.FullChargeCap))
+	content += fmt.Sprintf("| %s | %s |\n", T("report.design_cap"), data.Capacity(data.Latest.DesignCapacity))
+	content += fmt.Sprintf("| %s | %s |\n", T("report.current_cap"), data.Capacity(data.Latest.CurrentCapacity))
 
-	// Рекомендации по замене
-	if wear > 20 {
-		recommendations = append(recommendations, "Рассмотрите замену батареи")
+	if data.Latest.Temperature > 0 {
+		content += fmt.Sprintf("| %s | %d°C |\n", T("report.temperature"), data.Latest.Temperature)
 	}
 
-	// Рекомендации по аномалиям
-	if len(anomalies) > 3 {
-		recommendations = append(recommendations, "Проверьте настройки энергосбережения")
-	}
+	content += fmt.Sprintf("\n## %s\n\n", T("report.health_analysis"))
+	if data.HealthAnalysis != nil {
+		content += fmt.Sprintf("**%s:** %s\n\n", T("report.overall"), T("report.overall.value", data.HealthAnalysis.HealthStatus, data.HealthAnalysis.HealthScore))
+		content += fmt.Sprintf("**%s:** %.1f%%\n\n", T("report.wear_battery"), data.Wear)
 
-	// Рекомендации по циклам
-	if latest.CycleCount > 1000 {
-		recommendations = append(recommendations, "Батарея приближается к концу жизненного цикла")
-	}
+		// Анализ трендов
+		trendAnalysis := data.HealthAnalysis.Trend
+		if trendAnalysis.DegradationRate != 0 {
+			content += fmt.Sprintf("**%s:** %s\n\n", T("report.trend"), T("report.trend.value", trendAnalysis.DegradationRate))
+			if trendAnalysis.ProjectedLifetime > 0 {
+				content += fmt.Sprintf("**%s:** %s\n\n", T("report.projection"), T("report.projection.value", trendAnalysis.ProjectedLifetime))
+			}
+		}
+
+		if len(data.Anomalies) > 0 {
+			content += "### " + T("report.anomalies", len(data.Anomalies)) + "\n\n"
+			for i, anomaly := range data.Anomalies {
+				if i >= 10 { // Показываем максимум 10 аномалий в экспорте
+					content += T("report.anomalies.more", len(data.Anomalies)-i) + "\n\n"
+					break
+				}
+				content += fmt.Sprintf("- %s %s\n", anomaly.Icon(), anomaly)
+			}
+			content += "\n"
+		}
 
-	// Рекомендации по энергопотреблению
-	if avgRate > 1000 {
-		recommendations = append(recommendations, "Высокое энергопотребление - закройте ресурсоемкие приложения")
+		if len(data.Recommendations) > 0 {
+			content += "### " + T("report.recommendations") + "\n\n"
+			for _, rec := range data.Recommendations {
+				content += fmt.Sprintf("- %s\n", rec)
+			}
+			content += "\n"
+		}
 	}
 
-	// Рекомендации по температуре
-	if latest.Temperature > 40 {
-		recommendations = append(recommendations, "Высокая температура батареи ("+strconv.Itoa(latest.Temperature)+"°C) - избегайте нагрузки")
-	} else if latest.Temperature > 35 {
-		recommendations = append(recommendations, "Повышенная температура батареи - рассмотрите улучшение охлаждения")
+	if len(data.TopApps) > 0 {
+		content += "## " + T("report.top_apps", appsPeriodLabel(data.Range)) + "\n\n"
+		content += fmt.Sprintf("| # | %s | %s | %s | %s |\n", T("report.app"), T("report.drain_mah"), T("report.drain_wh"), T("report.energy_impact"))
+		content += "|---|------------|-------------|--------------|---------------------------|\n"
+		for i, app := range data.TopApps {
+			content += fmt.Sprintf("| %d | %s | %.0f | %.2f | %.1f / %.1f |\n",
+				i+1, app.App, app.MAh, app.Wh, app.AvgPower, app.MaxPower)
+		}
+		content += "\n"
 	}
 
-	// Рекомендации по трендам
-	if !trendAnalysis.IsHealthy && trendAnalysis.DegradationRate < -0.5 {
-		recommendations = append(recommendations, fmt.Sprintf("Быстрая деградация батареи (%.2f%% в месяц) - проверьте условия эксплуатации", -trendAnalysis.DegradationRate))
+	if data.History != nil && data.History.Count > 0 {
+		content += "## " + T("report.history") + "\n\n"
+		for _, line := range data.History.SummaryLines() {
+			content += fmt.Sprintf("- %s\n", line)
+		}
+		content += "\n"
 	}
 
-	// Рекомендации по заряду
-	if latest.State == "charging" && latest.Percentage == 100 {
-		recommendations = append(recommendations, "Не держите батарею постоянно на 100% заряда")
+	if len(data.Monthly) > 1 {
+		content += "## " + T("report.monthly") + "\n\n"
+		content += fmt.Sprintf("| %s | %s | %s |\n", T("report.month"), T("report.full_cap"), T("report.wear"))
+		content += "|-------|----------------|-------|\n"
+		for _, m := range data.Monthly {
+			content += fmt.Sprintf("| %s | %.0f %s | %.1f%% |\n", m.Month, m.FullChargeCap, mah, m.Wear())
+		}
+		content += "\n"
 	}
 
-	// Рекомендации по калибровке
-	if wear > 15 && latest.CycleCount > 500 {
-		recommendations = append(recommendations, "Рассмотрите калибровку батареи (полный разряд и заряд)")
+	if len(data.WearTimeline) > 1 {
+		content += "## " + T("report.wear_timeline") + "\n\n"
+		content += T("report.wear_timeline.note") + "\n\n"
+		content += fmt.Sprintf("| %s | %s | %s | %s | |\n", T("report.wear"), T("report.date"), T("report.cycles"), T("report.wear_pace"))
+		content += "|------|------|-------|------|---|\n"
+		for _, step := range data.WearTimeline {
+			content += fmt.Sprintf("| %s | %s | %d | %s | %s |\n", step.Label(), step.Event.Time().Format("02.01.2006"),
+				step.Event.CycleCount, step.Pace(), step.Bar())
+		}
+		content += "\n"
 	}
 
-	analysis["recommendations"] = recommendations
+	if data.FullChargeTime > 0 {
+		content += "## " + T("report.full_zone") + "\n\n"
+		content += T("report.full_zone.note") + "\n\n"
+		content += fmt.Sprintf("%s (%.0f%%)\n\n", formatDuration(data.FullChargeTime), data.FullChargeShare)
+	}
 
-	return analysis
-}
+	if hotChargingTotal(data.HotCharging) > 0 {
+		content += "## " + T("report.hot") + "\n\n"
+		content += T("report.hot.note") + "\n\n"
+		content += fmt.Sprintf("| %s | %s |\n", T("report.week"), T("report.minutes"))
+		content += "|--------|-------|\n"
+		for _, w := range data.HotCharging {
+			content += fmt.Sprintf("| %s | %.0f |\n", w.Label(), w.Minutes)
+		}
+		content += "\n"
+	}
 
-// exportToMarkdown экспортирует отчет в формате Markdown
-func exportToMarkdown(data ReportData, filename string) error {
-	content := fmt.Sprintf(`# 🔋 Отчет о состоянии батареи MacBook
+	if summary := data.Throttling.Summary(); summary != "" {
+		content += "## " + T("report.throttling") + "\n\n"
+		content += summary + "\n\n"
+		for _, p := range data.Throttling.Periods {
+			content += "- " + p.String() + "\n"
+		}
+		if len(data.Throttling.Periods) > 0 {
+			content += "\n"
+		}
+		if verdict := data.Throttling.Verdict(); verdict != "" {
+			content += verdict + "\n\n"
+		}
+	}
 
-**Дата создания:** %s
+	if len(data.Chargers) > 0 {
+		content += "## " + T("report.chargers") + "\n\n"
+		content += fmt.Sprintf("| %s | %s | %s | %s |\n", T("report.adapter"), T("report.power"), T("report.last_connected"), T("report.charge_rate"))
+		content += "|---------|----------|-----------------------|---------------------|\n"
+		for _, c := range data.Chargers {
+			rate := "—"
+			if c.Hours > 0 {
+				rate = fmt.Sprintf("%.0f", c.Rate)
+			}
+			content += fmt.Sprintf("| %s | %s | %s | %s |\n",
+				c.Adapter.Label(), T("report.watts", c.Adapter.Watts), parseStoredTime(c.Adapter.ConnectedAt).Local().Format("02.01.2006 15:04"), rate)
+		}
+		content += "\n"
+	}
 
-## 💼 Краткое резюме
+	if summary := data.Charging.Summary(); summary != "" {
+		content += "## " + T("report.charging_curve") + "\n\n"
+		content += summary + "\n\n"
+		content += fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n", T("report.charging.start"), T("report.charging.range"),
+			T("report.charging.fast"), T("report.charging.trickle"), T("report.charging.watts"), T("report.charging.notes"))
+		content += "|--------|----------|---------|----------|----|-----------|\n"
+		for _, c := range data.Charging.Curves {
+			content += fmt.Sprintf("| %s | %d%% → %d%% | %s | %s | %s | %s |\n", c.Start.Local().Format("02.01 15:04"), c.StartPercent, c.EndPercent,
+				formatOptionalDuration(c.Fast), formatOptionalDuration(c.Trickle), formatOptionalWatts(c.AvgWatts), c.Notes())
+		}
+		content += "\n"
+	}
 
-`, data.GeneratedAt.Format("02.01.2006 15:04:05"))
+	if len(data.Brightness) > 0 {
+		content += "## " + T("report.brightness") + "\n\n"
+		content += fmt.Sprintf("| %s | %s | %s |\n", T("report.mode"), T("report.drain_rate"), T("report.battery_hours"))
+		content += "|-------|---------------|------------------|\n"
+		for _, b := range data.Brightness {
+			content += fmt.Sprintf("| %s | %s | %.1f |\n", b.Label, b.Drain(), b.Hours)
+		}
+		content += "\n"
+	}
 
-	if data.HealthAnalysis != nil {
-		if status, ok := data.HealthAnalysis["health_status"].(string); ok {
-			score, _ := data.HealthAnalysis["health_score"].(int)
-			content += fmt.Sprintf("- **Здоровье батареи:** %s (рейтинг %d/100)\n", status, score)
+	if len(data.Calibration.History) > 0 || data.Calibration.Due {
+		content += "## " + T("report.calibration") + "\n\n"
+		if data.Calibration.Due {
+			content += "**" + data.Calibration.Message() + "**\n\n"
+		}
+		for _, c := range data.Calibration.Recent() {
+			content += "- " + c.String() + "\n"
 		}
+		content += "\n"
 	}
-	content += fmt.Sprintf("- **Циклы:** %d\n", data.Latest.CycleCount)
-	content += fmt.Sprintf("- **Износ:** %.1f%%\n", data.Wear)
-	if data.RemainingTime > 0 {
-		content += fmt.Sprintf("- **Оставшееся время:** %s\n", data.RemainingTime.Truncate(time.Minute))
-	}
-
-	content += fmt.Sprintf(`
-## 🔋 Текущее состояние батареи
-
-| Параметр | Значение |
-|----------|----------|
-| Время измерения | %s |
-| Заряд | %d%% |
-| Состояние | %s |
-| Циклы зарядки | %d |
-| Полная ёмкость | %d мАч |
-| Проектная ёмкость | %d мАч |
-| Текущая ёмкость | %d мАч |
-`,
-		data.Latest.Timestamp,
-		data.Latest.Percentage,
-		formatStateForExport(data.Latest.State, data.Latest.Percentage),
-		data.Latest.CycleCount,
-		data.Latest.FullChargeCap,
-		data.Latest.DesignCapacity,
-		data.Latest.CurrentCapacity)
 
-	if data.Latest.Temperature > 0 {
-		content += fmt.Sprintf("| Температура | %d°C |\n", data.Latest.Temperature)
+	if sleep := data.Sleep.String(); sleep != "" {
+		content += sleep + "\n\n"
+	}
+	if len(data.SleepWakes) > 0 {
+		content += "## " + T("report.sleep_wakes") + "\n\n"
+		for _, s := range data.SleepWakes {
+			content += "- " + s.String() + "\n"
+		}
+		content += "\n"
+		if len(data.WakeReasons) > 0 {
+			content += T("report.wake_reasons") + "\n\n"
+			for _, r := range data.WakeReasons {
+				content += "- " + r.String() + "\n"
+			}
+			content += "\n"
+		}
 	}
 
-	content += "\n## 📊 Анализ здоровья батареи\n\n"
-	if data.HealthAnalysis != nil {
-		if status, ok := data.HealthAnalysis["health_status"].(string); ok {
-			score, _ := data.HealthAnalysis["health_score"].(int)
-			content += fmt.Sprintf("**Общее состояние:** %s (оценка: %d/100)\n\n", status, score)
+	if len(data.Notes) > 0 {
+		content += "## " + T("report.notes") + "\n\n"
+		for _, n := range data.Notes {
+			content += "- " + n.String() + "\n"
 		}
-		content += fmt.Sprintf("**Износ батареи:** %.1f%%\n\n", data.Wear)
+		content += "\n"
+	}
 
-		// Анализ трендов
-		if trendAnalysis, ok := data.HealthAnalysis["trend_analysis"].(TrendAnalysis); ok {
-			if trendAnalysis.DegradationRate != 0 {
-				content += fmt.Sprintf("**Тренд деградации:** %.2f%% в месяц\n\n", trendAnalysis.DegradationRate)
-				if trendAnalysis.ProjectedLifetime > 0 {
-					content += fmt.Sprintf("**Прогноз до 80%% емкости:** ~%d дней\n\n", trendAnalysis.ProjectedLifetime)
-				}
+	if summary := data.Standby.Summary(); summary != "" {
+		content += "## " + T("report.standby") + "\n\n"
+		content += summary + "\n\n"
+		content += fmt.Sprintf("| %s | %s | %s |\n", T("report.week"), T("report.standby.hours"), T("report.standby.rate"))
+		content += "|--------|-----------|------|\n"
+		for _, w := range data.Standby.Weeks {
+			if w.Rate() > 0 {
+				content += fmt.Sprintf("| %s | %.1f | %.1f |\n", w.Label(), w.Hours, w.Rate())
 			}
 		}
+		content += "\n"
+	}
 
-		if len(data.Anomalies) > 0 {
-			content += fmt.Sprintf("### ⚠️ Обнаруженные аномалии (%d)\n\n", len(data.Anomalies))
-			for i, anomaly := range data.Anomalies {
-				if i >= 10 { // Показываем максимум 10 аномалий в экспорте
-					content += fmt.Sprintf("... и еще %d аномалий\n\n", len(data.Anomalies)-i)
-					break
-				}
-				content += fmt.Sprintf("- %s\n", anomaly)
-			}
-			content += "\n"
+	if len(data.DerivedMetrics) > 0 {
+		content += "## " + T("report.derived") + "\n\n"
+		content += fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+			T("report.metric"), T("report.expr"), T("report.latest"), T("report.min"), T("report.avg"), T("report.max"))
+		content += "|---------|-----------|-----------|------|-------|-------|\n"
+		for _, d := range data.DerivedMetrics {
+			st := d.Stats()
+			content += fmt.Sprintf("| %s | `%s` | %.3g | %.3g | %.3g | %.3g |\n",
+				d.Label(), d.Expr, d.Latest(), st.Min, st.Avg, st.Max)
 		}
+		content += "\n"
+	}
 
-		if len(data.Recommendations) > 0 {
-			content += "### 💡 Рекомендации\n\n"
-			for _, rec := range data.Recommendations {
-				content += fmt.Sprintf("- %s\n", rec)
-			}
-			content += "\n"
+	if len(data.Daily) > 0 {
+		totals := dailyTotals(data.Daily)
+		content += "## " + T("report.daily") + "\n\n"
+		content += fmt.Sprintf("**%s:** %s\n\n", T("report.total"),
+			T("report.total.value", formatDuration(totals.BatteryTime), formatDuration(totals.ChargeTime), totals.FullCycles))
+		content += fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			T("report.day"), T("report.on_battery"), T("report.charging"), T("report.on_ac"), T("report.screen"),
+			T("report.drain"), T("report.full_charges"), T("report.sessions"), T("report.battery_hours_bar"))
+		content += "|------|------------|------------|---------|-------|---------------|----------------|--------|-----------------|\n"
+		for _, d := range data.Daily {
+			content += fmt.Sprintf("| %s | %s | %s | %s | %s | %.0f%% | %.2f | %d | %s |\n",
+				d.Date.Format("02.01.2006"), formatDuration(d.BatteryTime), formatDuration(d.ChargeTime), formatDuration(d.ACTime),
+				screenLabel(d.ScreenTime), d.Drain, d.FullCycles(), d.Sessions, dailyBar(d))
 		}
+		content += "\n"
 	}
 
-	content += "## 📈 Статистика разрядки\n\n"
+	content += "## " + T("report.drain_stats") + "\n\n"
+	if data.DischargePower > 0 {
+		content += fmt.Sprintf("- **%s:** %s\n", T("report.discharge_power"), T("report.power.value", data.DischargePower, data.PowerIntervals))
+	}
 	if data.AvgRate > 0 {
-		content += fmt.Sprintf("- **Простая скорость разрядки:** %.2f мАч/час\n", data.AvgRate)
+		content += fmt.Sprintf("- **%s:** %s\n", T("report.simple_rate"), T("report.rate.value", data.AvgRate))
 	}
 	if data.RobustRate > 0 {
-		content += fmt.Sprintf("- **Робастная скорость разрядки:** %.2f мАч/час (на основе %d валидных интервалов)\n", data.RobustRate, data.ValidIntervals)
+		content += fmt.Sprintf("- **%s:** %s\n", T("report.robust_rate"), T("report.robust.value", data.RobustRate, data.ValidIntervals))
 	}
 	if data.RemainingTime > 0 {
-		content += fmt.Sprintf("- **Оставшееся время работы:** %s\n", data.RemainingTime.Truncate(time.Minute))
+		content += fmt.Sprintf("- **%s:** %s\n", T("report.remaining_work"), data.Remaining)
 	}
 
-	content += "\n## 📋 Последние измерения\n\n"
-	content += "| Время | Заряд | Состояние | Цикл | Полная емк. | Проект. емк. | Текущ. емк. | Темп. |\n"
+	content += "\n## " + T("report.recent") + "\n\n"
+	content += fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s |\n",
+		T("report.time"), T("report.charge"), T("report.state"), T("report.cycle"),
+		T("report.full_short"), T("report.design_short"), T("report.current_short"), T("report.temp_short"))
 	content += "|-------|-------|-----------|------|-------------|--------------|-------------|-------|\n"
 
 	startIdx := 0
@@ -1463,29 +1621,32 @@ func exportToMarkdown(data ReportData, filename string) error {
 			m.CycleCount, m.FullChargeCap, m.DesignCapacity, m.CurrentCapacity, tempStr)
 	}
 
-	content += "\n---\n*Отчет сгенерирован утилитой batmon v2.0*\n"
+	content += "\n---\n*" + T("report.footer") + "*\n"
 
-	return os.WriteFile(filename, []byte(content), 0644)
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	}, verifyMarkdownReport)
 }
 
 // exportToHTML экспортирует отчет в формате HTML с графиками
 func exportToHTML(data ReportData, filename string) error {
 	tmpl := `<!DOCTYPE html>
-<html lang="ru">
+<html lang="{{t "lang"}}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>🔋 Отчет о состоянии батареи MacBook</title>
+    <title>{{t "report.title"}}</title>
     <script src="https://cdnjs.cloudflare.com/ajax/libs/Chart.js/3.9.1/chart.min.js" integrity="sha512-ElRFoEQdI5Ht6kZvyzXhYG9NqjtkmlkfYk0wr6wHxU9JEHakS7UJZNeml5ALk+8IKlU6jDgMabC3vkumRokgJA==" crossorigin="anonymous" referrerpolicy="no-referrer"></script>
     <script>
-        // Fallback: встроенная минимальная версия Chart.js если CDN недоступен
+        // Fallback: a minimal built-in Chart.js when the CDN is unavailable
         if (typeof Chart === 'undefined') {
-            // Простая замена Chart.js для автономной работы
+            // Simple Chart.js stand-in for offline viewing
             window.Chart = function(ctx, config) {
                 var canvas = ctx.canvas || ctx;
                 var context = canvas.getContext('2d');
                 
-                // Очищаем canvas
+                // Clear the canvas
                 context.clearRect(0, 0, canvas.width, canvas.height);
                 
                 if (config.type === 'line' && config.data && config.data.datasets) {
@@ -1493,18 +1654,18 @@ func exportToHTML(data ReportData, filename string) error {
                     var labels = config.data.labels;
                     
                     if (data && data.length > 0) {
-                        // Настройки графика
+                        // Chart layout
                         var padding = 40;
                         var width = canvas.width - 2 * padding;
                         var height = canvas.height - 2 * padding;
                         
-                        // Найдем min и max значения
+                        // Find the min and max values
                         var minVal = Math.min(...data);
                         var maxVal = Math.max(...data);
                         var range = maxVal - minVal;
                         if (range === 0) range = 1;
                         
-                        // Рисуем оси
+                        // Draw the axes
                         context.strokeStyle = '#666';
                         context.lineWidth = 1;
                         context.beginPath();
@@ -1513,7 +1674,7 @@ func exportToHTML(data ReportData, filename string) error {
                         context.lineTo(width + padding, height + padding);
                         context.stroke();
                         
-                        // Рисуем данные
+                        // Draw the data
                         if (data.length > 1) {
                             context.strokeStyle = config.data.datasets[0].borderColor || '#007AFF';
                             context.lineWidth = 2;
@@ -1532,7 +1693,7 @@ func exportToHTML(data ReportData, filename string) error {
                             context.stroke();
                         }
                         
-                        // Подписи осей
+                        // Axis labels
                         context.fillStyle = '#333';
                         context.font = '12px Arial';
                         context.textAlign = 'center';
@@ -1542,7 +1703,7 @@ func exportToHTML(data ReportData, filename string) error {
                         context.fillText(maxVal.toFixed(0), padding - 10, padding + 5);
                         context.fillText(minVal.toFixed(0), padding - 10, height + padding + 5);
                         
-                        // Заголовок
+                        // Title
                         if (config.options && config.options.plugins && config.options.plugins.title && config.options.plugins.title.text) {
                             context.textAlign = 'center';
                             context.font = 'bold 16px Arial';
@@ -1641,51 +1802,89 @@ func exportToHTML(data ReportData, filename string) error {
             border-top: 1px solid #e5e5e7; 
             color: #86868b; 
         }
+        .heatmap { border-collapse: separate; border-spacing: 2px; width: auto; }
+        .heatmap th, .heatmap td { padding: 0; border: none; font-size: 11px; font-weight: normal; background: none; }
+        .heatmap td.cell { width: 18px; height: 18px; border-radius: 3px; }
+        .heatmap th.day { padding-right: 8px; white-space: nowrap; text-align: right; }
+        .heat0 { background: #ebedf0 !important; }
+        .heat1 { background: #c6e48b !important; }
+        .heat2 { background: #7bc96f !important; }
+        .heat3 { background: #ffd33d !important; }
+        .heat4 { background: #f66a0a !important; }
+        .heat5 { background: #d73a49 !important; }
     </style>
 </head>
 <body>
     <div class="container">
         <div class="header">
-            <h1>🔋 Отчет о состоянии батареи MacBook</h1>
-            <p>Дата создания: {{.GeneratedAt.Format "02.01.2006 15:04:05"}}</p>
+            <h1>{{t "report.title"}}</h1>
+            <p>{{t "report.created"}}: {{.GeneratedAt.Format "02.01.2006 15:04:05"}}</p>
+            <p>{{t "report.period"}}: {{.Range.Label}}</p>
         </div>
 
         <div class="summary">
-            <h2>💼 Краткое резюме</h2>
-            {{if .HealthAnalysis}}
-                {{if index .HealthAnalysis "health_status"}}
-                    <p>🏥 <strong>Здоровье батареи:</strong> {{index .HealthAnalysis "health_status"}} (рейтинг {{index .HealthAnalysis "health_score"}}/100)</p>
-                {{end}}
+            <h2>{{t "report.summary"}}</h2>
+            {{with .HealthAnalysis}}
+                <p>🏥 <strong>{{t "report.health"}}:</strong> {{t "report.rating" .HealthStatus .HealthScore .ScoreModel.Label}}</p>
+            {{end}}
+            <p>🔄 <strong>{{t "report.cycles"}}:</strong> {{.Latest.CycleCount}}</p>
+            <p>📉 <strong>{{t "report.wear"}}:</strong> {{printf "%.1f" .Wear}}%</p>
+            {{if .Baseline}}
+                <p>📌 <strong>{{t "report.baseline" (.Baseline.Summary .Latest)}}</strong> {{t "report.baseline.detail" .Baseline.FullChargeCap .Baseline.Date}}</p>
             {{end}}
-            <p>🔄 <strong>Циклы:</strong> {{.Latest.CycleCount}}</p>
-            <p>📉 <strong>Износ:</strong> {{printf "%.1f" .Wear}}%</p>
             {{if gt .RemainingTime 0}}
-                <p>⏰ <strong>Оставшееся время:</strong> {{.RemainingTime.Truncate 1000000000}}</p>
+                <p>⏰ <strong>{{t "report.remaining"}}:</strong> {{.Remaining}}</p>
+            {{end}}
+            {{range .Replacements}}
+                <p><strong>{{.Marker}}</strong> {{t "report.replacement" .OldSerial .NewSerial}}</p>
             {{end}}
         </div>
 
+        {{with .Comparison}}
+        <div class="section">
+            <h3>{{.Title}}</h3>
+            {{if .BatteryReplaced}}<p>🔁 {{t "report.compare.replaced"}}</p>{{end}}
+            <table>
+                <tr><th>{{t "report.param"}}</th><th>{{t "report.compare.then"}}</th><th>{{t "report.compare.now"}}</th><th>{{t "report.compare.delta"}}</th></tr>
+                {{range .Rows}}
+                <tr><td>{{.Label}}</td><td>{{.Then}}</td><td>{{.Now}}</td><td>{{.Delta}}</td></tr>
+                {{end}}
+            </table>
+        </div>
+        {{end}}
+
+        <div class="section">
+            <h3>{{t "report.model" .Model.Label}}</h3>
+            <ul>
+                {{range .Model.Lines}}<li>{{.}}</li>{{end}}
+            </ul>
+        </div>
+
         <div class="grid">
             <div class="card">
-                <h3>📊 Графики</h3>
+                <h3>{{t "report.charts"}}</h3>
                 <div class="chart-container">
                     <canvas id="batteryChart"></canvas>
                 </div>
                 <div class="chart-container">
                     <canvas id="capacityChart"></canvas>
                 </div>
+                {{range .Replacements}}
+                    <div class="anomaly">{{.Marker}}</div>
+                {{end}}
             </div>
 
             <div class="card">
-                <h3>🔋 Текущее состояние</h3>
+                <h3>{{t "report.current.short"}}</h3>
                 <table>
-                    <tr><td><strong>Заряд</strong></td><td>{{.Latest.Percentage}}%</td></tr>
-                    <tr><td><strong>Состояние</strong></td><td>{{.Latest.State}}</td></tr>
-                    <tr><td><strong>Циклы</strong></td><td>{{.Latest.CycleCount}}</td></tr>
-                    <tr><td><strong>Полная ёмкость</strong></td><td>{{.Latest.FullChargeCap}} мАч</td></tr>
-                    <tr><td><strong>Проектная ёмкость</strong></td><td>{{.Latest.DesignCapacity}} мАч</td></tr>
-                    <tr><td><strong>Текущая ёмкость</strong></td><td>{{.Latest.CurrentCapacity}} мАч</td></tr>
+                    <tr><td><strong>{{t "report.charge"}}</strong></td><td>{{.Latest.Percentage}}%</td></tr>
+                    <tr><td><strong>{{t "report.state"}}</strong></td><td>{{.Latest.State}}</td></tr>
+                    <tr><td><strong>{{t "report.cycles"}}</strong></td><td>{{.Latest.CycleCount}}</td></tr>
+                    <tr><td><strong>{{t "report.full_cap"}}</strong></td><td>{{.Capacity .Latest.FullChargeCap}}</td></tr>
+                    <tr><td><strong>{{t "report.design_cap"}}</strong></td><td>{{.Capacity .Latest.DesignCapacity}}</td></tr>
+                    <tr><td><strong>{{t "report.current_cap"}}</strong></td><td>{{.Capacity .Latest.CurrentCapacity}}</td></tr>
                     {{if gt .Latest.Temperature 0}}
-                        <tr><td><strong>Температура</strong></td><td>{{.Latest.Temperature}}°C</td></tr>
+                        <tr><td><strong>{{t "report.temperature"}}</strong></td><td>{{.Latest.Temperature}}°C</td></tr>
                     {{end}}
                 </table>
             </div>
@@ -1693,88 +1892,356 @@ func exportToHTML(data ReportData, filename string) error {
 
         {{if .Anomalies}}
         <div class="card">
-            <h3>⚠️ Обнаруженные аномалии ({{len .Anomalies}})</h3>
+            <h3>{{t "report.anomalies" (len .Anomalies)}}</h3>
             {{range $index, $anomaly := .Anomalies}}
                 {{if lt $index 10}}
-                    <div class="anomaly">{{$anomaly}}</div>
+                    <div class="anomaly">{{$anomaly.Icon}} {{$anomaly.Message}}</div>
                 {{end}}
             {{end}}
             {{if gt (len .Anomalies) 10}}
-                <p>... и еще {{sub (len .Anomalies) 10}} аномалий</p>
+                <p>{{t "report.anomalies.more" (sub (len .Anomalies) 10)}}</p>
             {{end}}
         </div>
         {{end}}
 
         {{if .Recommendations}}
         <div class="card">
-            <h3>💡 Рекомендации</h3>
+            <h3>{{t "report.recommendations"}}</h3>
             {{range .Recommendations}}
                 <div class="recommendation">{{.}}</div>
             {{end}}
         </div>
         {{end}}
 
+        {{if .TopApps}}
         <div class="card">
-            <h3>📋 Последние измерения</h3>
+            <h3>{{t "report.top_apps" (appsPeriod .Range)}}</h3>
             <table>
                 <thead>
-                    <tr>
-                        <th>Время</th>
-                        <th>Заряд</th>
-                        <th>Состояние</th>
-                        <th>Цикл</th>
-                        <th>Полная емк.</th>
-                        <th>Текущ. емк.</th>
-                        <th>Темп.</th>
-                    </tr>
+                    <tr><th>#</th><th>{{t "report.app"}}</th><th>{{t "report.drain_mah"}}</th><th>{{t "report.drain_wh"}}</th><th>{{t "report.energy_impact"}}</th></tr>
                 </thead>
                 <tbody>
-                    {{$len := len .Measurements}}
-                    {{$start := 0}}
-                    {{if gt $len 15}}
-                        {{$start = sub $len 15}}
+                    {{range $i, $app := .TopApps}}
+                        <tr>
+                            <td>{{add $i 1}}</td>
+                            <td>{{$app.App}}</td>
+                            <td>{{printf "%.0f" $app.MAh}}</td>
+                            <td>{{printf "%.2f" $app.Wh}}</td>
+                            <td>{{printf "%.1f" $app.AvgPower}} / {{printf "%.1f" $app.MaxPower}}</td>
+                        </tr>
                     {{end}}
-                    {{range $index, $m := .Measurements}}
-                        {{if ge $index $start}}
-                            <tr>
-                                <td>{{slice $m.Timestamp 11 19}}</td>
-                                <td>{{$m.Percentage}}%</td>
-                                <td>{{$m.State}}</td>
-                                <td>{{$m.CycleCount}}</td>
-                                <td>{{$m.FullChargeCap}} мАч</td>
-                                <td>{{$m.CurrentCapacity}} мАч</td>
-                                <td>{{if gt $m.Temperature 0}}{{$m.Temperature}}°C{{else}}-{{end}}</td>
-                            </tr>
-                        {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .Daily}}
+        <div class="card">
+            <h3>{{t "report.daily"}}</h3>
+            {{$totals := dailyTotals .Daily}}
+            <p><strong>{{t "report.total"}}:</strong> {{t "report.total.value" (duration $totals.BatteryTime) (duration $totals.ChargeTime) $totals.FullCycles}}</p>
+            <div class="chart-container">
+                <canvas id="dailyChart"></canvas>
+            </div>
+            <table>
+                <thead>
+                    <tr><th>{{t "report.day"}}</th><th>{{t "report.on_battery"}}</th><th>{{t "report.charging"}}</th><th>{{t "report.on_ac"}}</th><th>{{t "report.screen"}}</th><th>{{t "report.drain"}}</th><th>{{t "report.full_charges"}}</th><th>{{t "report.sessions"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Daily}}
+                        <tr>
+                            <td>{{.Date.Format "02.01.2006"}}</td>
+                            <td>{{duration .BatteryTime}}</td>
+                            <td>{{duration .ChargeTime}}</td>
+                            <td>{{duration .ACTime}}</td>
+                            <td>{{screenLabel .ScreenTime}}</td>
+                            <td>{{printf "%.0f" .Drain}}%</td>
+                            <td>{{printf "%.2f" .FullCycles}}</td>
+                            <td>{{.Sessions}}</td>
+                        </tr>
                     {{end}}
                 </tbody>
             </table>
         </div>
+        {{end}}
 
-        <div class="footer">
-            <p><em>Отчет сгенерирован утилитой batmon v2.0</em></p>
+        {{if .Heatmap.Days}}
+        <div class="card">
+            <h3>{{t "report.heatmap"}}</h3>
+            <p>{{t "report.heatmap.note"}}</p>
+            <table class="heatmap">
+                <tr><th></th>{{range $h := hours}}<th>{{if eq (mod $h 3) 0}}{{$h}}{{end}}</th>{{end}}</tr>
+                {{range .Heatmap.Rows false}}
+                <tr><th class="day">{{.Label}}</th>{{range .Cells}}<td class="cell heat{{.Level}}" title="{{.Title}}"></td>{{end}}</tr>
+                {{end}}
+            </table>
         </div>
-    </div>
+        {{end}}
 
-    <script>
-        // График заряда батареи
-        const batteryCtx = document.getElementById('batteryChart').getContext('2d');
-        const batteryData = [
-            {{range $index, $m := .Measurements}}
-                {{if lt $index 20}}{{$m.Percentage}},{{end}}
-            {{end}}
-        ];
-        
-        new Chart(batteryCtx, {
-            type: 'line',
-            data: {
-                labels: [
-                    {{range $index, $m := .Measurements}}
+        {{if .History}}{{if .History.Count}}
+        <div class="card">
+            <h3>{{t "report.history"}}</h3>
+            <ul>
+                {{range .History.SummaryLines}}<li>{{.}}</li>{{end}}
+            </ul>
+        </div>
+        {{end}}{{end}}
+
+        {{if gt (len .Monthly) 1}}
+        <div class="card">
+            <h3>{{t "report.monthly"}}</h3>
+            <table>
+                <tr><th>{{t "report.month"}}</th><th>{{t "report.full_cap"}}</th><th>{{t "report.wear"}}</th></tr>
+                {{range .Monthly}}<tr><td>{{.Month}}</td><td>{{printf "%.0f" .FullChargeCap}} {{t "unit.mah"}}</td><td>{{printf "%.1f" .Wear}}%</td></tr>{{end}}
+            </table>
+        </div>
+        {{end}}
+
+        {{if gt (len .WearTimeline) 1}}
+        <div class="card">
+            <h3>{{t "report.wear_timeline"}}</h3>
+            <p>{{t "report.wear_timeline.note"}}</p>
+            <table>
+                <tr><th>{{t "report.wear"}}</th><th>{{t "report.date"}}</th><th>{{t "report.cycles"}}</th><th>{{t "report.wear_pace"}}</th><th></th></tr>
+                {{range .WearTimeline}}<tr><td>{{.Label}}</td><td>{{.Event.Time.Format "02.01.2006"}}</td><td>{{.Event.CycleCount}}</td><td>{{.Pace}}</td><td>{{.Bar}}</td></tr>{{end}}
+            </table>
+        </div>
+        {{end}}
+
+        {{if hotChargingTotal .HotCharging}}
+        <div class="card">
+            <h3>{{t "report.hot"}}</h3>
+            <p>{{t "report.hot.note"}}</p>
+            <table>
+                <thead>
+                    <tr><th>{{t "report.week"}}</th><th>{{t "report.minutes"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .HotCharging}}
+                        <tr><td>{{.Label}}</td><td>{{printf "%.0f" .Minutes}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{with .Throttling.Summary}}
+        <div class="card">
+            <h3>{{t "report.throttling"}}</h3>
+            <p>{{.}}</p>
+            {{if $.Throttling.Periods}}
+            <ul>
+                {{range $.Throttling.Periods}}<li>{{.String}}</li>{{end}}
+            </ul>
+            {{end}}
+            {{with $.Throttling.Verdict}}<p><strong>{{.}}</strong></p>{{end}}
+        </div>
+        {{end}}
+
+        {{if .Chargers}}
+        <div class="card">
+            <h3>{{t "report.chargers"}}</h3>
+            <table>
+                <thead>
+                    <tr><th>{{t "report.adapter"}}</th><th>{{t "report.power"}}</th><th>{{t "report.charge_rate"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Chargers}}
+                        <tr>
+                            <td>{{.Adapter.Label}}</td>
+                            <td>{{t "report.watts" .Adapter.Watts}}</td>
+                            <td>{{if .Hours}}{{printf "%.0f" .Rate}}{{else}}—{{end}}</td>
+                        </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{with .Charging.Summary}}
+        <div class="card">
+            <h3>{{t "report.charging_curve"}}</h3>
+            <p>{{.}}</p>
+            <table>
+                <thead>
+                    <tr><th>{{t "report.charging.start"}}</th><th>{{t "report.charging.range"}}</th><th>{{t "report.charging.fast"}}</th><th>{{t "report.charging.trickle"}}</th><th>{{t "report.charging.watts"}}</th><th>{{t "report.charging.notes"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range $.Charging.Curves}}
+                        <tr><td>{{.Start.Local.Format "02.01 15:04"}}</td><td>{{.StartPercent}}% → {{.EndPercent}}%</td><td>{{optDuration .Fast}}</td><td>{{optDuration .Trickle}}</td><td>{{optWatts .AvgWatts}}</td><td>{{.Notes}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .Brightness}}
+        <div class="card">
+            <h3>{{t "report.brightness"}}</h3>
+            <table>
+                <thead>
+                    <tr><th>{{t "report.mode"}}</th><th>{{t "report.drain_rate"}}</th><th>{{t "report.battery_hours"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Brightness}}
+                        <tr>
+                            <td>{{.Label}}</td>
+                            <td>{{.Drain}}</td>
+                            <td>{{printf "%.1f" .Hours}}</td>
+                        </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if or .Calibration.History .Calibration.Due}}
+        <div class="card">
+            <h3>{{t "report.calibration"}}</h3>
+            {{if .Calibration.Due}}<p><strong>{{.Calibration.Message}}</strong></p>{{end}}
+            <ul>
+                {{range .Calibration.Recent}}<li>{{.String}}</li>{{end}}
+            </ul>
+        </div>
+        {{end}}
+
+        {{with .Sleep.String}}
+        <div class="card">
+            <p>{{.}}</p>
+        </div>
+        {{end}}
+
+        {{if .SleepWakes}}
+        <div class="card">
+            <h3>{{t "report.sleep_wakes"}}</h3>
+            <ul>
+                {{range .SleepWakes}}<li>{{.String}}</li>{{end}}
+            </ul>
+            {{if .WakeReasons}}
+            <p>{{t "report.wake_reasons"}}</p>
+            <ul>
+                {{range .WakeReasons}}<li>{{.String}}</li>{{end}}
+            </ul>
+            {{end}}
+        </div>
+        {{end}}
+
+        {{if .Notes}}
+        <div class="card">
+            <h3>{{t "report.notes"}}</h3>
+            <ul>
+                {{range .Notes}}<li>{{.String}}</li>{{end}}
+            </ul>
+        </div>
+        {{end}}
+
+        {{with .Standby.Summary}}
+        <div class="card">
+            <h3>{{t "report.standby"}}</h3>
+            <p>{{.}}</p>
+            <table>
+                <thead>
+                    <tr><th>{{t "report.week"}}</th><th>{{t "report.standby.hours"}}</th><th>{{t "report.standby.rate"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range $.Standby.Weeks}}{{if gt .Rate 0.0}}
+                        <tr><td>{{.Label}}</td><td>{{printf "%.1f" .Hours}}</td><td>{{printf "%.1f" .Rate}}</td></tr>
+                    {{end}}{{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .DerivedMetrics}}
+        <div class="card">
+            <h3>{{t "report.derived"}}</h3>
+            <table>
+                <thead>
+                    <tr><th>{{t "report.metric"}}</th><th>{{t "report.expr"}}</th><th>{{t "report.latest"}}</th><th>{{t "report.min"}}</th><th>{{t "report.avg"}}</th><th>{{t "report.max"}}</th></tr>
+                </thead>
+                <tbody>
+                    {{range .DerivedMetrics}}
+                        {{$st := .Stats}}
+                        <tr>
+                            <td>{{.Label}}</td>
+                            <td><code>{{.Expr}}</code></td>
+                            <td>{{printf "%.3g" .Latest}}</td>
+                            <td>{{printf "%.3g" $st.Min}}</td>
+                            <td>{{printf "%.3g" $st.Avg}}</td>
+                            <td>{{printf "%.3g" $st.Max}}</td>
+                        </tr>
+                    {{end}}
+                </tbody>
+            </table>
+            {{range $i, $s := .DerivedMetrics}}
+                <div class="chart-container">
+                    <canvas id="derivedChart{{$i}}"></canvas>
+                </div>
+            {{end}}
+        </div>
+        {{end}}
+
+        <div class="card">
+            <h3>{{t "report.recent"}}</h3>
+            <table>
+                <thead>
+                    <tr>
+                        <th>{{t "report.time"}}</th>
+                        <th>{{t "report.charge"}}</th>
+                        <th>{{t "report.state"}}</th>
+                        <th>{{t "report.cycle"}}</th>
+                        <th>{{t "report.full_short"}}</th>
+                        <th>{{t "report.current_short"}}</th>
+                        <th>{{t "report.temp_short"}}</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{$len := len .Measurements}}
+                    {{$start := 0}}
+                    {{if gt $len 15}}
+                        {{$start = sub $len 15}}
+                    {{end}}
+                    {{range $index, $m := .Measurements}}
+                        {{if ge $index $start}}
+                            <tr>
+                                <td>{{slice $m.Timestamp 11 19}}</td>
+                                <td>{{$m.Percentage}}%</td>
+                                <td>{{$m.State}}</td>
+                                <td>{{$m.CycleCount}}</td>
+                                <td>{{$m.FullChargeCap}} {{t "unit.mah"}}</td>
+                                <td>{{$m.CurrentCapacity}} {{t "unit.mah"}}</td>
+                                <td>{{if gt $m.Temperature 0}}{{$m.Temperature}}°C{{else}}-{{end}}</td>
+                            </tr>
+                        {{end}}
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        <div class="footer">
+            <p><em>{{t "report.footer"}}</em></p>
+        </div>
+    </div>
+
+    <script>
+        // Battery charge chart
+        const batteryCtx = document.getElementById('batteryChart').getContext('2d');
+        const batteryData = [
+            {{range $index, $m := .Measurements}}
+                {{if lt $index 20}}{{$m.Percentage}},{{end}}
+            {{end}}
+        ];
+        
+        new Chart(batteryCtx, {
+            type: 'line',
+            data: {
+                labels: [
+                    {{range $index, $m := .Measurements}}
                         {{if lt $index 20}}'{{slice $m.Timestamp 11 19}}',{{end}}
                     {{end}}
                 ],
                 datasets: [{
-                    label: 'Заряд (%)',
+                    label: '{{t "report.js.charge"}}',
                     data: batteryData,
                     borderColor: '#28a745',
                     backgroundColor: 'rgba(40, 167, 69, 0.1)',
@@ -1788,7 +2255,7 @@ func exportToHTML(data ReportData, filename string) error {
                 plugins: {
                     title: {
                         display: true,
-                        text: 'Заряд батареи (%)'
+                        text: '{{t "report.js.charge_title"}}'
                     }
                 },
                 scales: {
@@ -1800,7 +2267,7 @@ func exportToHTML(data ReportData, filename string) error {
             }
         });
 
-        // График емкости
+        // Capacity chart
         const capacityCtx = document.getElementById('capacityChart').getContext('2d');
         const capacityData = [
             {{range $index, $m := .Measurements}}
@@ -1817,7 +2284,7 @@ func exportToHTML(data ReportData, filename string) error {
                     {{end}}
                 ],
                 datasets: [{
-                    label: 'Емкость (мАч)',
+                    label: '{{t "report.js.capacity"}}',
                     data: capacityData,
                     borderColor: '#007bff',
                     backgroundColor: 'rgba(0, 123, 255, 0.1)',
@@ -1831,11 +2298,71 @@ func exportToHTML(data ReportData, filename string) error {
                 plugins: {
                     title: {
                         display: true,
-                        text: 'Текущая емкость (мАч)'
+                        text: '{{t "report.js.capacity_title"}}'
+                    }
+                }
+            }
+        });
+
+        // Derived metrics from config.json
+        {{if .Daily}}
+        new Chart(document.getElementById('dailyChart').getContext('2d'), {
+            type: 'bar',
+            data: {
+                labels: [{{range .Daily}}{{.Date.Format "02.01"}},{{end}}],
+                datasets: [{
+                    label: '{{t "report.js.on_battery"}}',
+                    data: [{{range .Daily}}{{.BatteryHours}},{{end}}],
+                    backgroundColor: '#28a745'
+                }, {
+                    label: '{{t "report.js.charging"}}',
+                    data: [{{range .Daily}}{{.ChargeHours}},{{end}}],
+                    backgroundColor: '#ffc107'
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                scales: {
+                    x: { stacked: true },
+                    y: { stacked: true, title: { display: true, text: '{{t "report.js.hours"}}' } }
+                },
+                plugins: {
+                    title: {
+                        display: true,
+                        text: '{{t "report.js.daily"}}'
+                    }
+                }
+            }
+        });
+        {{end}}
+
+        {{range $i, $s := .DerivedMetrics}}
+        new Chart(document.getElementById('derivedChart{{$i}}').getContext('2d'), {
+            type: 'line',
+            data: {
+                labels: [{{range $j, $v := $s.Values}}{{$s.Clock $j}},{{end}}],
+                datasets: [{
+                    label: {{$s.Label}},
+                    data: [{{range $s.Values}}{{.}},{{end}}],
+                    borderColor: '#6f42c1',
+                    backgroundColor: 'rgba(111, 66, 193, 0.1)',
+                    fill: true,
+                    tension: 0.4
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                plugins: {
+                    title: {
+                        display: true,
+                        text: {{$s.Label}}
                     }
                 }
             }
         });
+        {{end}}
     </script>
 </body>
 </html>`
@@ -1845,26 +2372,43 @@ func exportToHTML(data ReportData, filename string) error {
 		"sub": func(a, b int) int {
 			return a - b
 		},
+		"add": func(a, b int) int {
+			return a + b
+		},
+		"duration":         formatDuration,
+		"appsPeriod":       appsPeriodLabel,
+		"t":                T,
+		"hotChargingTotal": hotChargingTotal,
+		"optDuration":      formatOptionalDuration,
+		"optWatts":         formatOptionalWatts,
+		"dailyTotals":      dailyTotals,
+		"screenLabel":      screenLabel,
+		"mod": func(a, b int) int {
+			return a % b
+		},
+		"hours": func() []int {
+			hours := make([]int, 24)
+			for i := range hours {
+				hours[i] = i
+			}
+			return hours
+		},
 	}
 
 	t, err := template.New("report").Funcs(funcMap).Parse(tmpl)
 	if err != nil {
-		return fmt.Errorf("парсинг шаблона: %w", err)
-	}
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("создание файла: %w", err)
+		return fmt.Errorf(T("err.template"), err)
 	}
-	defer file.Close()
 
-	return t.Execute(file, data)
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		return t.Execute(w, data)
+	}, verifyHTMLReport)
 }
 
 // formatStateForExport форматирует состояние батареи для экспорта (без эмодзи)
 func formatStateForExport(state string, percentage int) string {
 	if state == "" {
-		return "Неизвестно"
+		return T("state.unknown")
 	}
 
 	stateLower := strings.ToLower(state)
@@ -1873,12 +2417,12 @@ func formatStateForExport(state string, percentage int) string {
 	switch stateLower {
 	case "charging":
 		if percentage >= 90 {
-			return stateFormatted + " (почти полная)"
+			return stateFormatted + T("state.almost_full")
 		}
 		return stateFormatted
 	case "discharging":
 		if percentage < 20 {
-			return stateFormatted + " (низкий заряд)"
+			return stateFormatted + T("state.low")
 		}
 		return stateFormatted
 	case "charged":
@@ -1890,53 +2434,219 @@ func formatStateForExport(state string, percentage int) string {
 	}
 }
 
-// generateReportData собирает данные для отчета
+// generateReportData собирает данные для отчета по последним измерениям
 func generateReportData(db *sqlx.DB) (ReportData, error) {
-	ms, err := getLastNMeasurements(db, 50)
+	return generateReportDataRange(db, ReportRange{})
+}
+
+// generateReportDataRange собирает данные для отчета за период.
+// Анализ идет по всем измерениям периода, а в графики и таблицы
+// попадает не больше reportMaxPoints точек.
+func generateReportDataRange(db *sqlx.DB, rng ReportRange) (ReportData, error) {
+	ms, err := loadReportMeasurements(db, rng, reportLastN)
 	if err != nil {
-		return ReportData{}, fmt.Errorf("получение данных: %w", err)
+		return ReportData{}, fmt.Errorf(T("err.data"), err)
 	}
 	if len(ms) == 0 {
-		return ReportData{}, fmt.Errorf("нет данных для отчета")
+		if !rng.IsZero() {
+			return ReportData{}, fmt.Errorf(T("report.err.no_data_range"), rng.Label())
+		}
+		return ReportData{}, errors.New(T("report.err.no_data"))
 	}
 
 	latest := ms[len(ms)-1]
-	avgRate := computeAvgRate(ms, 5)
-	robustRate, validIntervals := computeAvgRateRobust(ms, 10)
-	remaining := computeRemainingTime(latest.CurrentCapacity, robustRate)
+	segment := currentBatterySegment(ms)
+	avgRate := computeAvgRate(segment, 5)
+	robustRate, validIntervals := computeAvgRateRobust(segment, 10)
+	dischargePower, powerIntervals := computeDischargePower(segment, 10)
+	remaining := forecastRemaining(segment, robustRate)
 	wear := computeWear(latest.DesignCapacity, latest.FullChargeCap)
 	healthAnalysis := analyzeBatteryHealth(ms)
 
-	var anomalies []string
-	var recommendations []string
+	replacements, err := getBatteryReplacements(db)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	baseline := currentBaseline(db, latest)
 
-	if healthAnalysis != nil {
-		if anomaliesList, ok := healthAnalysis["anomalies"].([]string); ok {
-			anomalies = anomaliesList
+	chartMs := downsampleMeasurements(ms, reportMaxPoints)
+	metrics, metricErrs := configuredMetrics()
+	for _, err := range metricErrs {
+		logWarnf("⚠️ %v", err)
+	}
+	derived, err := buildDerivedSeries(db, chartMs, metrics)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+
+	topApps, err := computeTopAppsEnergy(db, appsReportRange(rng, timeNow()), 10)
+	if err != nil {
+		logWarnf("⚠️ Расход по приложениям: %v", err)
+	}
+
+	// Сводка по дням: за период отчета или сохраненная за последнюю неделю
+	var daily []DailySummary
+	if rng.IsZero() {
+		if err := syncDailyUsage(db); err != nil {
+			logWarnf("⚠️ Сводка по дням: %v", err)
 		}
-		if recsList, ok := healthAnalysis["recommendations"].([]string); ok {
-			recommendations = recsList
+		if daily, err = getDailyUsage(db, dailyUsageDays, timeNow()); err != nil {
+			logWarnf("⚠️ %v", err)
 		}
+	} else {
+		daily = summarizeByDay(detectSessions(ms), time.Local)
+	}
+
+	sessions, err := getSessions(db, rng, 30)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+
+	thermalEvents, err := getThermalEvents(db, rng, thermalEventsShown)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+
+	var anomalies []Anomaly
+	var recommendations []string
+
+	if healthAnalysis != nil {
+		anomalies = healthAnalysis.Anomalies
+		// Вместо общего совета о расходе называем конкретные приложения
+		recommendations = recommendationTexts(withTopApps(healthAnalysis.Recommendations, topApps))
+	}
+	netSamples, err := getNetSamples(db, ms)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	anomalies = explainNetContext(anomalies, netSamples)
+
+	history, err := loadHistoryAnalysis(db)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+
+	hotCharging, err := hotChargingByWeek(db, hotChargeWeeks, timeNow())
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+
+	monthly, err := getMonthlyCapacity(db)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	if rec := hotChargingRecommendation(hotCharging); rec != "" {
+		recommendations = append(recommendations, rec)
+	}
+	standby, err := standbyDrainByWeek(db, standbyWeeks, timeNow())
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	if rec := standby.Recommendation(); rec != "" {
+		recommendations = append(recommendations, rec)
+	}
+	fullTime, fullShare := fullChargeTime(ms)
+	if rec := fullChargeRecommendation(fullShare); rec != "" {
+		recommendations = append(recommendations, rec)
+	}
+
+	chargers, err := getChargers(db)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	chargerSummary := chargerStats(ms, chargers)
+	recommendations = append(recommendations, chargerRecommendations(chargerSummary)...)
+	charging, err := loadChargingAnalysis(db, rng, segment, timeNow())
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	recommendations = append(recommendations, charging.Recommendations()...)
+
+	// Общий совет о калибровке заменяем напоминанием по истории полных разрядок
+	calibration, err := checkCalibrationReminder(db, getConfig().Calibration)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	recommendations = calibrationRecommendations(recommendations, calibration)
+
+	wearEvents, err := getWearEvents(db, latest.BatterySerial)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+
+	sleepPeriods := detectSleepPeriods(segment)
+	powerEvents, err := getPowerEvents(db, rng)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+	sleepWakes, wakeReasons := sleepWakeStats(sleepPeriods, powerEvents)
+
+	thermalSamples, err := getThermalSamples(db, ms)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+
+	// Без явного периода – заметки с первого измерения отчета до текущего момента
+	notesRange := rng
+	if notesRange.IsZero() {
+		notesRange.From = parseStoredTime(ms[0].Timestamp)
+	}
+	notes, err := getNotes(db, notesRange)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
 	}
 
 	return ReportData{
-		GeneratedAt:     time.Now(),
+		GeneratedAt:     timeNow(),
 		Latest:          latest,
-		Measurements:    ms,
+		Measurements:    chartMs,
 		HealthAnalysis:  healthAnalysis,
 		Wear:            wear,
 		AvgRate:         avgRate,
 		RobustRate:      robustRate,
 		ValidIntervals:  validIntervals,
-		RemainingTime:   remaining,
+		DischargePower:  dischargePower,
+		PowerIntervals:  powerIntervals,
+		NominalVoltage:  latestNominalVoltage(segment),
+		Samples:         len(ms),
+		RemainingTime:   remaining.Expected,
+		Remaining:       remaining,
 		Anomalies:       anomalies,
 		Recommendations: recommendations,
+		Replacements:    replacements,
+		TopApps:         topApps,
+		Range:           rng,
+		Daily:           daily,
+		Sessions:        sessions,
+		Baseline:        baseline,
+		DerivedMetrics:  derived,
+		Brightness:      drainByBrightness(ms),
+		Chargers:        chargerSummary,
+		History:         history,
+		HotCharging:     hotCharging,
+		Monthly:         monthly,
+		ThermalEvents:   thermalEvents,
+		FullChargeTime:  fullTime,
+		FullChargeShare: fullShare,
+		ChargeHistogram: chargeHistogram(ms),
+		Heatmap:         usageHeatmap(ms, time.Local, heatmapDays),
+		Model:           newModelBenchmark(latest, sessionsDrainRate(sessions)),
+		Sleep:           summarizeSleep(sleepPeriods),
+		SleepWakes:      sleepWakes,
+		WakeReasons:     wakeReasons,
+		Throttling:      analyzeThrottling(thermalSamples, ms),
+		Notes:           notes,
+		Standby:         standby,
+		Charging:        charging,
+		Calibration:     calibration,
+		WearTimeline:    wearTimeline(wearEvents),
+		Advanced:        analyzeAdvancedMetrics(ms),
 	}, nil
 }
 
 // isOnBattery проверяет, работает ли система от батареи
 func isOnBattery() (bool, string, int, error) {
-	pct, state, err := parsePMSet()
+	pct, state, err := newBatterySource().Status()
 	if err != nil {
 		return false, "", 0, err
 	}
@@ -1951,39 +2661,63 @@ func isOnBattery() (bool, string, int, error) {
 // backgroundDataCollection запускает сбор данных в фоне с оптимизацией частоты
 // NewDataCollector создает новый коллектор данных с буферизацией
 func NewDataCollector(db *sqlx.DB) *DataCollector {
-	buffer := NewMemoryBuffer(100)                     // Буфер на последние 100 измерений
-	retention := NewDataRetention(db, 90*24*time.Hour) // Хранение 3 месяца
+	buffer := NewMemoryBuffer(100) // Буфер на последние 100 измерений
+	collectorConfig := getConfig().Collector
+	measurements := newSQLiteMeasurements(db)
+	retention := NewDataRetention(measurements, collectorConfig.Retention())
 
 	collector := &DataCollector{
 		db:               db,
+		writes:           newWriteQueue(measurements),
+		storage:          newSyncStorage(getDBPath()),
+		source:           newBatterySource(),
 		buffer:           buffer,
 		retention:        retention,
 		lastProfilerCall: time.Time{},
-		pmsetInterval:    30 * time.Second,
-		profilerInterval: 2 * time.Minute,
+		pmsetInterval:    collectorConfig.PollInterval(),
+		profilerInterval: profilerInterval,
+	}
+
+	if _, err := newSamplingPolicy(collectorConfig.Sampling); err != nil {
+		logWarnf("⚠️ %v", err)
 	}
 
 	// Загружаем существующие данные в буфер
 	if err := buffer.LoadFromDB(db, 100); err != nil {
-		log.Printf("⚠️ Ошибка загрузки данных в буфер: %v", err)
+		logWarnf("⚠️ Ошибка загрузки данных в буфер: %v", err)
 	} else {
-		log.Printf("📦 Загружено %d измерений в буфер памяти", buffer.Size())
+		logInfof("📦 Загружено %d измерений в буфер памяти", buffer.Size())
 	}
 
 	return collector
 }
 
+// store ставит измерение в очередь записи. Очередь пишется пакетом; в
+// щадящем режиме – сразу, чтобы не потерять данные при отключении Mac.
+// В режиме записи только изменений неизменившиеся измерения пропускаются.
+func (dc *DataCollector) store(m *Measurement) error {
+	if dc.changes.Skip(*m, getConfig().Storage.ChangeOnly) {
+		return nil // ничего не изменилось, контрольное измерение еще не нужно
+	}
+	dc.writes.Add(*m)
+	dc.lastWrite = timeNow()
+	if dc.lowBattery || dc.writes.Due(writeBatchSize()) {
+		return dc.flushWrites()
+	}
+	return nil
+}
+
 // collectAndStore собирает данные и сохраняет их в БД и буфер
 func (dc *DataCollector) collectAndStore() error {
-	// Получаем базовые данные от pmset
-	pct, state, pmErr := parsePMSet()
+	// Получаем базовые данные (pmset на macOS, sysfs на Linux, WMI на Windows)
+	pct, state, pmErr := dc.source.Status()
 	if pmErr != nil {
-		return fmt.Errorf("сбор данных pmset: %w", pmErr)
+		return fmt.Errorf(T("err.collect"), dc.source.Name(), pmErr)
 	}
 
 	// Создаем базовое измерение
 	m := &Measurement{
-		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		Timestamp:       timeNow().UTC().Format(time.RFC3339),
 		Percentage:      pct,
 		State:           state,
 		CycleCount:      0, // Будет обновлено ниже
@@ -1993,25 +2727,29 @@ func (dc *DataCollector) collectAndStore() error {
 		Temperature:     0,
 	}
 
-	// Добавляем подробные данные от ioreg, если пора
-	if time.Since(dc.lastProfilerCall) >= dc.profilerInterval {
-		cycle, fullCap, designCap, currCap, temperature, voltage, amperage, condition, ioErr := parseIORegistry()
+	// Добавляем подробные данные (ioreg/sysfs/WMI), если пора
+	if timeNow().Sub(dc.lastProfilerCall) >= dc.profilerInterval {
+		details, ioErr := dc.source.Details()
 		if ioErr == nil {
-			m.CycleCount = cycle
-			m.FullChargeCap = fullCap
-			m.DesignCapacity = designCap
-			m.CurrentCapacity = currCap
-			m.Temperature = temperature
-			m.Voltage = voltage
-			m.Amperage = amperage
-			m.AppleCondition = condition
+			m.CycleCount = details.CycleCount
+			m.FullChargeCap = details.FullChargeCap
+			m.DesignCapacity = details.DesignCapacity
+			m.CurrentCapacity = details.CurrentCapacity
+			m.Temperature = details.Temperature
+			m.Voltage = details.Voltage
+			m.Amperage = details.Amperage
+			m.AppleCondition = details.Condition
+			m.BatterySerial = details.Serial
+			m.CellVoltages = formatCellVoltages(details.CellVoltages)
+			logDebugf("🔍 %s: %d циклов, %d/%d мАч, %d°C", dc.source.Name(),
+				details.CycleCount, details.CurrentCapacity, details.FullChargeCap, details.Temperature)
 
 			// Вычисляем мощность
-			if voltage > 0 && amperage != 0 {
-				m.Power = (voltage * amperage) / 1000
+			if details.Voltage > 0 && details.Amperage != 0 {
+				m.Power = (details.Voltage * details.Amperage) / 1000
 			}
 
-			dc.lastProfilerCall = time.Now()
+			dc.lastProfilerCall = timeNow()
 		} else {
 			// Если ioreg не работает, используем предыдущие значения
 			if latest := dc.buffer.GetLatest(); latest != nil {
@@ -2024,8 +2762,10 @@ func (dc *DataCollector) collectAndStore() error {
 				m.Amperage = latest.Amperage
 				m.Power = latest.Power
 				m.AppleCondition = latest.AppleCondition
+				m.BatterySerial = latest.BatterySerial
+				m.CellVoltages = latest.CellVoltages
 			}
-			log.Printf("⚠️ ioreg недоступен, используем кэшированные значения: %v", ioErr)
+			logWarnf("⚠️ %s недоступен, используем кэшированные значения: %v", dc.source.Name(), ioErr)
 		}
 	} else {
 		// Используем последние известные значения
@@ -2040,20 +2780,154 @@ func (dc *DataCollector) collectAndStore() error {
 			m.Amperage = latest.Amperage
 			m.Power = latest.Power
 			m.AppleCondition = latest.AppleCondition
+			m.BatterySerial = latest.BatterySerial
+			m.CellVoltages = latest.CellVoltages
+		}
+	}
+
+	// Яркость экрана и положение крышки – чтобы отличать расход на яркий
+	// экран от проблем с батареей
+	if reader, ok := dc.source.(DisplayContextReader); ok {
+		if ctx, err := reader.DisplayContext(); err == nil {
+			m.Brightness = ctx.Brightness
+			m.LidState = ctx.LidState
 		}
 	}
 
+	dc.updateThermal(*m)
+	dc.updateChargeLimit(*m)
+
+	// При низком заряде пишем реже; интерфейс получает все измерения из буфера
+	if dc.updateLowBattery(*m) {
+		dc.buffer.Add(*m)
+		dc.publish(*m)
+		dc.runEventHooks()
+		if !dc.skipWrite() {
+			if err := dc.store(m); err != nil {
+				return err
+			}
+		}
+		// Тест полной разрядки должен заметить завершение без задержки
+		if err := advanceCalibration(dc.db, *m, dc.source); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+		return nil
+	}
+
 	// Сохраняем в БД
-	if err := insertMeasurement(dc.db, m); err != nil {
-		return fmt.Errorf("сохранение в БД: %w", err)
+	if err := dc.store(m); err != nil {
+		return err
+	}
+	dc.updateHistory(*m)
+	dc.updateHealthAlert(*m)
+
+	// Подробный режим: мощность компонентов SoC
+	pressure := ""
+	if dc.powerSampler.enabled() {
+		if s, err := dc.powerSampler.sample(m.Timestamp); err == nil {
+			pressure = s.ThermalPressure
+			if err := insertPowerSample(dc.db, *s); err != nil {
+				logWarnf("⚠️ %v", err)
+			}
+		}
+	}
+
+	// Сетевой контекст для пояснения аномалий расхода – только по согласию
+	if dc.netSampler.enabled() {
+		if s, err := dc.netSampler.sample(m.Timestamp); err != nil {
+			logWarnf("⚠️ %v", err)
+		} else if s != nil {
+			if err := insertNetSample(dc.db, *s); err != nil {
+				logWarnf("⚠️ %v", err)
+			}
+		}
+	}
+
+	// Тепловое давление – чтобы отличать троттлинг от проблем батареи
+	if timeNow().Sub(dc.lastThermal) >= thermalSampleInterval {
+		dc.lastThermal = timeNow()
+		if s, err := readThermalSample(m.Timestamp, pressure); err != nil {
+			logWarnf("⚠️ %v", err)
+		} else if s != nil {
+			if err := insertThermalSample(dc.db, *s); err != nil {
+				logWarnf("⚠️ %v", err)
+			}
+		}
 	}
 
 	// Добавляем в буфер памяти
 	dc.buffer.Add(*m)
+	dc.publish(*m)
+	dc.runEventHooks()
+
+	// Базовую точку износа записываем один раз для каждой батареи
+	if m.FullChargeCap > 0 && (dc.baselineSerial == nil || *dc.baselineSerial != m.BatterySerial) {
+		if err := ensureBaseline(dc.db, *m); err != nil {
+			logWarnf("⚠️ %v", err)
+		} else {
+			serial := m.BatterySerial
+			dc.baselineSerial = &serial
+		}
+	}
+
+	// Продвигаем идущий тест полной разрядки
+	if err := advanceCalibration(dc.db, *m, dc.source); err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+
+	// Запоминаем подключенный адаптер питания
+	if reader, ok := dc.source.(AdapterReader); ok {
+		var adapter *AdapterInfo
+		var err error
+		if m.State != "discharging" {
+			adapter, err = reader.Adapter()
+		}
+		if err != nil {
+			logWarnf("⚠️ Адаптер питания: %v", err)
+		} else if err := dc.chargers.track(dc.db, adapter, m.Timestamp); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+	}
+
+	// Раз в несколько минут при работе от батареи запоминаем самые прожорливые приложения
+	if m.State == "discharging" && timeNow().Sub(dc.lastAppSample) >= appSampleInterval {
+		dc.lastAppSample = timeNow()
+		if samples, err := dc.appSampler.sample(); err != nil {
+			logWarnf("⚠️ Выборка приложений: %v", err)
+		} else if err := insertAppPowerSamples(dc.db, samples); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+	}
+
+	// Сессии разрядки и зарядки обновляем раз в несколько минут и при смене режима питания
+	if prev := dc.buffer.GetLast(2); timeNow().Sub(dc.lastSessionSync) >= sessionSyncInterval ||
+		(len(prev) == 2 && prev[0].State != prev[1].State) {
+		dc.lastSessionSync = timeNow()
+		if err := syncSessions(dc.db); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+		if err := syncThermalEvents(dc.db); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+		if err := syncDailyUsage(dc.db); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+		dc.updateCalibrationReminder()
+		dc.updateWearEvents()
+		dc.updateReportSchedule()
+	}
+
+	// Журнал pmset большой, поэтому события сна и пробуждений дочитываем раз в час
+	if timeNow().Sub(dc.lastPowerLog) >= powerLogInterval {
+		dc.lastPowerLog = timeNow()
+		if err := syncPowerEvents(dc.db); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+	}
 
 	// Периодическая очистка старых данных
 	if err := dc.retention.Cleanup(); err != nil {
-		log.Printf("⚠️ Ошибка очистки данных: %v", err)
+		logWarnf("⚠️ Ошибка очистки данных: %v", err)
 	}
 
 	return nil
@@ -2073,7 +2947,7 @@ func (dc *DataCollector) GetLastNFromBuffer(n int) []Measurement {
 func (dc *DataCollector) GetStats() (map[string]interface{}, error) {
 	dbStats, err := dc.retention.GetStats()
 	if err != nil {
-		return nil, fmt.Errorf("статистика БД: %w", err)
+		return nil, fmt.Errorf(T("db.err.stats"), err)
 	}
 
 	dbStats["buffer_size"] = dc.buffer.Size()
@@ -2084,7 +2958,9 @@ func (dc *DataCollector) GetStats() (map[string]interface{}, error) {
 
 // CollectAndStore - публичная обертка для collectAndStore
 func (dc *DataCollector) CollectAndStore() error {
-	return dc.collectAndStore()
+	err := dc.collectAndStore()
+	dc.health.record(err)
+	return err
 }
 
 // backgroundDataCollection запускает фоновый сбор данных с оптимизацией
@@ -2096,23 +2972,27 @@ func backgroundDataCollection(db *sqlx.DB, ctx context.Context, wg *sync.WaitGro
 
 	// Делаем первое измерение
 	if err := collector.collectAndStore(); err != nil {
-		log.Printf("⚠️ Первичное измерение: %v", err)
+		logWarnf("⚠️ Первичное измерение: %v", err)
 	}
 
-	ticker := time.NewTicker(collector.pmsetInterval)
+	interval := collector.pmsetInterval
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	log.Printf("🔄 Фоновый сбор данных запущен (pmset: %v, system_profiler: %v)",
+	logInfof("🔄 Фоновый сбор данных запущен (pmset: %v, system_profiler: %v)",
 		collector.pmsetInterval, collector.profilerInterval)
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("🛑 Остановка фонового сбора данных")
+			logInfof("🛑 Остановка фонового сбора данных")
+			if err := collector.Close(); err != nil {
+				logWarnf("⚠️ %v", err)
+			}
 			return
 		case <-ticker.C:
 			if err := collector.collectAndStore(); err != nil {
-				log.Printf("⚠️ Ошибка сбора данных: %v", err)
+				logWarnf("⚠️ Ошибка сбора данных: %v", err)
 				continue
 			}
 
@@ -2120,21 +3000,16 @@ func backgroundDataCollection(db *sqlx.DB, ctx context.Context, wg *sync.WaitGro
 			if collector.buffer.Size()%50 == 0 && collector.buffer.Size() > 0 {
 				stats, err := collector.GetStats()
 				if err == nil {
-					log.Printf("📊 Статистика: буфер %d/%d, БД %v записей",
+					logInfof("📊 Статистика: буфер %d/%d, БД %v записей",
 						stats["buffer_size"], stats["buffer_max_size"], stats["total_records"])
 				}
 			}
 
-			// Адаптивная частота сбора данных
-			latest := collector.GetLatestFromBuffer()
-			if latest != nil {
-				if strings.ToLower(latest.State) == "charging" && latest.Percentage >= 100 {
-					log.Println("🔋 Батарея полностью заряжена, замедляем сбор данных")
-					ticker.Reset(5 * time.Minute)
-				} else if strings.ToLower(latest.State) == "discharging" {
-					// Возвращаем нормальный интервал при разрядке
-					ticker.Reset(collector.pmsetInterval)
-				}
+			// Адаптивная частота сбора данных (см. sampling.go)
+			if next := collector.NextInterval(collector.pmsetInterval); next != interval {
+				logInfof("⏱️ Интервал опроса: %v → %v", interval, next)
+				interval = next
+				ticker.Reset(interval)
 			}
 		}
 	}
@@ -2189,147 +3064,240 @@ func normalizeKeyInput(keyID string) string {
 
 
 // printReport выводит отчёт о последнем измерении и статистике с цветным оформлением.
-func printReport(db *sqlx.DB) error {
-	ms, err := getLastNMeasurements(db, 20) // Увеличиваем количество для лучшего анализа
+// Данные те же, что у экрана отчета и экспорта, – из generateReportDataRange.
+func printReport(db *sqlx.DB, rng ReportRange, compare string) error {
+	recent, err := loadReportMeasurements(db, rng, 10)
 	if err != nil {
-		return fmt.Errorf("получение исторических данных: %w", err)
+		return fmt.Errorf(T("err.history_data"), err)
 	}
-	if len(ms) == 0 {
-		color.Yellow("Нет записей для отчёта.")
+	if len(recent) == 0 {
+		color.Yellow(T("report.no_data"))
 		return nil
 	}
+	data, err := generateReportDataRange(db, rng)
+	if err != nil {
+		return err
+	}
+	if compare != "" {
+		if data.Comparison, err = loadComparison(db, compare); err != nil {
+			return err
+		}
+	}
+	if !rng.IsZero() {
+		color.New(color.FgCyan).Println(T("report.period.samples", rng.Label(), data.Samples))
+	}
 
-	latest := ms[len(ms)-1]
-	avgRate := computeAvgRate(ms, 5)
-	robustRate, validIntervals := computeAvgRateRobust(ms, 10)
-	remaining := computeRemainingTime(latest.CurrentCapacity, robustRate)
-	wear := computeWear(latest.DesignCapacity, latest.FullChargeCap)
+	latest := data.Latest
+	wear := data.Wear
+	remaining := data.Remaining
 
 	// Анализ здоровья батареи
-	healthAnalysis := analyzeBatteryHealth(ms)
+	healthAnalysis := data.HealthAnalysis
 
 	// Определяем уровень для цветового оформления
 	healthScore := 70
 	if healthAnalysis != nil {
-		if score, ok := healthAnalysis["health_score"].(int); ok {
-			healthScore = score
-		}
+		healthScore = healthAnalysis.HealthScore
 	}
 	statusLevel := getStatusLevel(wear, latest.Percentage, latest.Temperature, healthScore)
 
 	// Краткое резюме
-	color.Cyan("💼 === КРАТКОЕ РЕЗЮМЕ ===")
+	color.Cyan(T("report.cli.summary"))
 	if healthAnalysis != nil {
-		if status, ok := healthAnalysis["health_status"].(string); ok {
-			score, _ := healthAnalysis["health_score"].(int)
-			printColoredStatus("Здоровье батареи", fmt.Sprintf("%s (рейтинг %d/100)", status, score), getStatusLevel(wear, 100, 25, score))
+		score := healthAnalysis.HealthScore
+		printColoredStatus(T("report.health"), T("report.rating", healthAnalysis.HealthStatus, score, healthAnalysis.ScoreModel.Label()), getStatusLevel(wear, 100, 25, score))
+	}
+	printColoredStatus(T("report.cycles"), fmt.Sprintf("%d", latest.CycleCount), statusLevel)
+	printColoredStatus(T("report.wear"), fmt.Sprintf("%.1f%%", wear), getStatusLevel(wear, 100, 25, 100))
+	if remaining.Expected > 0 {
+		printColoredStatus(T("report.remaining"), remaining.String(), statusLevel)
+	}
+	if baseline := data.Baseline; baseline != nil {
+		fmt.Printf("📌 %s %s\n", T("report.baseline", baseline.Summary(latest)), T("report.baseline.detail", baseline.FullChargeCap, baseline.Date()))
+	}
+	for _, line := range data.Model.Lines() {
+		fmt.Println("💻 " + line)
+	}
+	if data.Comparison != nil {
+		fmt.Println()
+		printComparison(*data.Comparison)
+		fmt.Println()
+	}
+	for _, b := range data.Brightness {
+		fmt.Println(T("report.cli.brightness", b.Label, b.Drain(), b.Hours))
+	}
+	if len(data.Daily) > 0 {
+		totals := dailyTotals(data.Daily)
+		fmt.Println(T("report.cli.daily", len(data.Daily), formatDuration(totals.BatteryTime), formatDuration(totals.ChargeTime), totals.FullCycles))
+	}
+	if data.History != nil {
+		for _, line := range data.History.SummaryLines() {
+			fmt.Printf("📈 %s\n", line)
 		}
 	}
-	printColoredStatus("Циклы", fmt.Sprintf("%d", latest.CycleCount), statusLevel)
-	printColoredStatus("Износ", fmt.Sprintf("%.1f%%", wear), getStatusLevel(wear, 100, 25, 100))
-	if remaining > 0 {
-		printColoredStatus("Оставшееся время", remaining.Truncate(time.Minute).String(), statusLevel)
+	if monthly := data.Monthly; len(monthly) > 1 {
+		first, last := monthly[0], monthly[len(monthly)-1]
+		fmt.Println(T("report.cli.monthly", first.Month, first.FullChargeCap, last.Month, last.FullChargeCap, len(monthly)))
+	}
+	if steps := data.WearTimeline; len(steps) > 1 {
+		fmt.Println(T("report.wear_timeline") + ":")
+		for _, step := range steps {
+			fmt.Printf("   %-24s %s  %-20s %s\n", step.Label(), step.Event.Time().Format("02.01.2006"), step.Pace(), step.Bar())
+		}
+	}
+	if len(data.Chargers) > 0 {
+		last := data.Chargers[len(data.Chargers)-1].Adapter
+		fmt.Println(T("report.cli.adapter", last.Label(), parseStoredTime(last.ConnectedAt).Local().Format("02.01 15:04")))
+	}
+	if summary := data.Charging.Summary(); summary != "" {
+		fmt.Println("⚡ " + summary)
+	}
+	if last := data.Calibration.Last; last != nil {
+		fmt.Println(T("report.cli.calibration_last", last.String()))
+	}
+	if data.Calibration.Due {
+		color.Yellow("🎯 %s", data.Calibration.Message())
+	}
+	for _, r := range data.Replacements {
+		color.Magenta(T("report.cli.replacement", r.Marker(), r.OldSerial, r.NewSerial))
 	}
 	fmt.Println()
 
-	color.Cyan("=== Текущее состояние батареи ===")
+	color.Cyan(T("report.cli.current"))
 	localTime, _ := time.Parse(time.RFC3339, latest.Timestamp)
 	fmt.Printf("📅 %s | ", localTime.Format("15:04:05 02.01.2006"))
-	printColoredStatus("Заряд", fmt.Sprintf("%d%%", latest.Percentage), getStatusLevel(0, latest.Percentage, 25, 100))
+	printColoredStatus(T("report.charge"), fmt.Sprintf("%d%%", latest.Percentage), getStatusLevel(0, latest.Percentage, 25, 100))
 	fmt.Printf("⚡ %s\n", formatStateWithEmoji(latest.State, latest.Percentage))
-	fmt.Printf("🔄 Кол-во циклов: %d\n", latest.CycleCount)
-	fmt.Printf("⚡ Полная ёмкость: %d мАч\n", latest.FullChargeCap)
-	fmt.Printf("📐 Проектная ёмкость: %d мАч\n", latest.DesignCapacity)
-	fmt.Printf("🔋 Текущая ёмкость: %d мАч\n", latest.CurrentCapacity)
+	fmt.Println(T("report.cli.cycles", latest.CycleCount))
+	fmt.Printf("⚡ %s: %s\n", T("report.full_cap"), data.Capacity(latest.FullChargeCap))
+	fmt.Printf("📐 %s: %s\n", T("report.design_cap"), data.Capacity(latest.DesignCapacity))
+	fmt.Printf("🔋 %s: %s\n", T("report.current_cap"), data.Capacity(latest.CurrentCapacity))
 
 	// Выводим температуру если доступна
 	if latest.Temperature > 0 {
-		tempLevel := "info"
-		if latest.Temperature > 40 {
-			tempLevel = "critical"
-		} else if latest.Temperature > 35 {
-			tempLevel = "warning"
+		printColoredStatus("🌡️ "+T("report.temperature"), fmt.Sprintf("%d°C", latest.Temperature), thermalStatusLevel(latest))
+	}
+	if weeks := data.HotCharging; len(weeks) > 0 {
+		if last := weeks[len(weeks)-1]; last.Minutes > 0 {
+			fmt.Println(T("report.cli.hot_week", last.Minutes))
+		}
+	}
+	if summary := data.Throttling.Summary(); summary != "" {
+		fmt.Println(T("report.throttling") + ": " + summary)
+		for _, p := range data.Throttling.Periods {
+			fmt.Println("   " + p.String())
+		}
+		if verdict := data.Throttling.Verdict(); verdict != "" {
+			fmt.Println("   " + verdict)
+		}
+	}
+	if data.FullChargeTime > 0 {
+		fmt.Println(T("report.cli.full_zone", formatDuration(data.FullChargeTime), data.FullChargeShare))
+	}
+	if sleep := data.Sleep.String(); sleep != "" {
+		fmt.Println(sleep)
+	}
+	if len(data.SleepWakes) > 0 {
+		fmt.Println(T("report.sleep_wakes") + ":")
+		for _, s := range data.SleepWakes {
+			fmt.Println("   " + s.String())
+		}
+		if len(data.WakeReasons) > 0 {
+			fmt.Println("   " + T("report.wake_reasons"))
+			for _, r := range data.WakeReasons {
+				fmt.Println("      " + r.String())
+			}
+		}
+	}
+	if summary := data.Standby.Summary(); summary != "" {
+		fmt.Println("🌙 " + summary)
+	}
+	if len(data.Notes) > 0 {
+		fmt.Println(T("report.notes") + ":")
+		for _, n := range data.Notes {
+			fmt.Println("   " + n.String())
 		}
-		printColoredStatus("🌡️ Температура", fmt.Sprintf("%d°C", latest.Temperature), tempLevel)
 	}
 
 	fmt.Println()
-	color.Cyan("=== Анализ здоровья батареи ===")
+	color.Cyan(T("report.cli.health"))
 	if healthAnalysis != nil {
-		if status, ok := healthAnalysis["health_status"].(string); ok {
-			score, _ := healthAnalysis["health_score"].(int)
-			printColoredStatus("Общее состояние", fmt.Sprintf("%s (оценка: %d/100)", status, score), getStatusLevel(wear, 100, 25, score))
-		}
-		printColoredStatus("Износ батареи", fmt.Sprintf("%.1f%%", wear), getStatusLevel(wear, 100, 25, 100))
+		score := healthAnalysis.HealthScore
+		printColoredStatus(T("report.overall"), T("report.overall.model", healthAnalysis.HealthStatus, score, healthAnalysis.ScoreModel.Label()), getStatusLevel(wear, 100, 25, score))
+		printColoredStatus(T("report.wear_battery"), fmt.Sprintf("%.1f%%", wear), getStatusLevel(wear, 100, 25, 100))
 
 		// Анализ трендов
-		if trendAnalysis, ok := healthAnalysis["trend_analysis"].(TrendAnalysis); ok {
-			if trendAnalysis.DegradationRate != 0 {
-				trendLevel := "good"
-				if !trendAnalysis.IsHealthy {
-					trendLevel = "warning"
-				}
-				if trendAnalysis.DegradationRate < -1.0 {
-					trendLevel = "critical"
-				}
-				printColoredStatus("📈 Тренд деградации", fmt.Sprintf("%.2f%% в месяц", trendAnalysis.DegradationRate), trendLevel)
+		trendAnalysis := healthAnalysis.Trend
+		if trendAnalysis.DegradationRate != 0 {
+			trendLevel := "good"
+			if !trendAnalysis.IsHealthy {
+				trendLevel = "warning"
+			}
+			if trendAnalysis.DegradationRate < -1.0 {
+				trendLevel = "critical"
+			}
+			printColoredStatus("📈 "+T("report.trend"), T("report.trend.value", trendAnalysis.DegradationRate), trendLevel)
 
-				if trendAnalysis.ProjectedLifetime > 0 {
-					fmt.Printf("🔮 Прогноз до 80%% емкости: ~%d дней\n", trendAnalysis.ProjectedLifetime)
-				}
+			if trendAnalysis.ProjectedLifetime > 0 {
+				fmt.Printf("🔮 %s: %s\n", T("report.projection"), T("report.projection.value", trendAnalysis.ProjectedLifetime))
 			}
 		}
+	}
 
-		if anomalies, ok := healthAnalysis["anomalies"].([]string); ok && len(anomalies) > 0 {
-			color.Yellow("\n⚠️  Обнаружено аномалий за последние измерения: %d", len(anomalies))
-			for i, anomaly := range anomalies {
-				if i >= 5 { // Показываем максимум 5 последних аномалий
-					color.Yellow("... и еще %d", len(anomalies)-i)
-					break
-				}
-				color.Red("  • %s", anomaly)
+	if anomalies := data.Anomalies; len(anomalies) > 0 {
+		color.Yellow(T("report.cli.anomalies", len(anomalies)))
+		for i, anomaly := range anomalies {
+			if i >= 5 { // Показываем максимум 5 последних аномалий
+				color.Yellow(T("report.cli.anomalies.more", len(anomalies)-i))
+				break
 			}
+			color.Red("  • %s", anomaly)
 		}
+	}
 
-		if recs, ok := healthAnalysis["recommendations"].([]string); ok && len(recs) > 0 {
-			color.Green("\n💡 Рекомендации:")
-			for _, rec := range recs {
-				color.Green("  • %s", rec)
-			}
+	if recs := data.Recommendations; len(recs) > 0 {
+		color.Green("\n" + T("report.recommendations") + ":")
+		for _, rec := range recs {
+			color.Green("  • %s", rec)
 		}
 	}
 
 	fmt.Println()
-	color.Cyan("=== Статистика разрядки ===")
-	if avgRate > 0 {
-		fmt.Printf("📊 Простая скорость разрядки: %.2f мАч/час\n", avgRate)
+	color.Cyan(T("report.cli.drain"))
+	if data.DischargePower > 0 {
+		fmt.Printf("🔌 %s: %s\n", T("report.discharge_power"), T("report.power.value", data.DischargePower, data.PowerIntervals))
 	}
-	if robustRate > 0 {
+	if data.AvgRate > 0 {
+		fmt.Printf("📊 %s: %s\n", T("report.simple_rate"), T("report.rate.value", data.AvgRate))
+	}
+	if robustRate := data.RobustRate; robustRate > 0 {
 		rateLevel := "good"
 		if robustRate > 1000 {
 			rateLevel = "warning"
 		} else if robustRate > 1500 {
 			rateLevel = "critical"
 		}
-		printColoredStatus("📈 Робастная скорость разрядки", fmt.Sprintf("%.2f мАч/час (на основе %d валидных интервалов)", robustRate, validIntervals), rateLevel)
+		printColoredStatus("📈 "+T("report.robust_rate"), T("report.robust.value", robustRate, data.ValidIntervals), rateLevel)
 	} else {
-		color.Yellow("📈 Робастная скорость разрядки: недостаточно данных")
+		color.Yellow("📈 %s: %s", T("report.robust_rate"), T("report.cli.not_enough"))
 	}
-	if remaining > 0 {
-		printColoredStatus("⏰ Оставшееся время работы", remaining.Truncate(time.Minute).String(), statusLevel)
+	if remaining.Expected > 0 {
+		printColoredStatus("⏰ "+T("report.remaining_work"), remaining.String(), statusLevel)
 	} else {
-		color.Yellow("⏰ Оставшееся время работы: неизвестно")
+		color.Yellow("⏰ %s: %s", T("report.remaining_work"), T("report.cli.unknown"))
 	}
 
 	fmt.Println()
-	color.Cyan("=== Последние измерения (от старых к новым) ===")
+	color.Cyan(T("report.cli.recent"))
+	ms := recent
 	startIdx := 0
 	if len(ms) > 10 {
 		startIdx = len(ms) - 10 // Показываем последние 10
 	}
 
 	fmt.Printf("%-10s | %-5s | %-12s | %-4s | %-4s | %-4s | %-6s | %-4s\n",
-		"Время", "Заряд", "Состояние", "Цикл", "ПЕ", "ПроЕ", "ТекЕ", "Темп")
+		T("report.time"), T("report.charge"), T("report.state"), T("report.cycle"),
+		T("report.cli.full_short"), T("report.cli.design_short"), T("report.cli.current_short"), T("report.cli.temp_short"))
 	fmt.Println(strings.Repeat("-", 80))
 
 	for i := startIdx; i < len(ms); i++ {
@@ -2370,52 +3338,49 @@ func printReport(db *sqlx.DB) error {
 
 // main – точка входа программы.
 func main() {
-	// Проверяем аргументы командной строки для экспорта и справки
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
-		case "-version", "--version", "version", "-v":
-			showVersion()
-			return
-		case "-help", "--help", "help":
-			showHelp()
-			return
-		case "-export-md", "--export-md":
-			if len(os.Args) < 3 {
-				color.New(color.FgRed).Println("❌ Укажите имя файла для экспорта")
-				return
-			}
-			if err := runExportMode(os.Args[2], "", true); err != nil {
-				log.Fatalf("❌ Ошибка экспорта: %v", err)
-			}
-			return
-		case "-export-html", "--export-html":
-			if len(os.Args) < 3 {
-				color.New(color.FgRed).Println("❌ Укажите имя файла для экспорта")
-				return
-			}
-			if err := runExportMode("", os.Args[2], true); err != nil {
-				log.Fatalf("❌ Ошибка экспорта: %v", err)
-			}
-			return
-		}
+	// Загружаем настройки (отсутствие config.json – не ошибка)
+	if err := initConfig(); err != nil {
+		logWarnf("⚠️ Конфиг не загружен, используются настройки по умолчанию: %v", err)
+	}
+	if err := applyThemeConfig(getConfig().Theme); err != nil {
+		logWarnf("⚠️ Тема оформления: %v", err)
+	}
+	cleanupStaleExportTemps(exportTempDirs()...)
+
+	// Подкоманды и флаги командной строки (см. cli.go)
+	if handled, code := runCLI(os.Args[1:]); handled {
+		os.Exit(code)
 	}
 
 	// Запуск интерфейса Bubble Tea
 	app := NewApp()
 	
-	// Обработка сигналов для корректного завершения caffeinate
+	p := tea.NewProgram(app, tea.WithAltScreen())
+	// Вывод журнала в терминал испортил бы экран: пока открыт TUI, только batmon.log
+	restoreConsole := setLogConsole(nil)
+	
+	// По сигналу выходим из интерфейса как по q: штатное завершение ниже
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	// Причину выхода горутина сигнала передает через канал: до p.Quit()
+	signaled := make(chan string, 1)
 	go func() {
-		<-c
-		if app.dataService != nil {
-			app.dataService.Stop()
-		}
-		os.Exit(0)
+		sig := <-c
+		signaled <- "сигнал " + sig.String()
+		p.Quit()
 	}()
 	
-	p := tea.NewProgram(app, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	_, err := p.Run()
+	reason := "выход"
+	select {
+	case reason = <-signaled:
+	default:
+	}
+	if app.dataService != nil {
+		app.dataService.Shutdown(reason)
+	}
+	restoreConsole()
+	if err != nil {
 		log.Fatalf("❌ Ошибка запуска приложения: %v", err)
 	}
 }
@@ -2426,27 +3391,27 @@ func showMainMenu() error {
 		// Очищаем экран и показываем заголовок
 		fmt.Print("\033[2J\033[H") // Очистка экрана
 
-		color.New(color.FgCyan, color.Bold).Println("🔋 BatMon v2.0 - Мониторинг батареи MacBook")
+		color.New(color.FgCyan, color.Bold).Println(T("console.title"))
 		color.New(color.FgWhite).Println("═══════════════════════════════════════════════════════")
 		fmt.Println()
 
 		// Показываем текущее состояние батареи
 		if err := showQuickStatus(); err != nil {
-			color.New(color.FgYellow).Printf("⚠️ Не удалось получить текущий статус: %v\n\n", err)
+			color.New(color.FgYellow).Println(T("console.status_failed", err))
 		}
 
 		// Главное меню
-		color.New(color.FgGreen, color.Bold).Println("📋 Выберите действие:")
+		color.New(color.FgGreen, color.Bold).Println(T("console.choose"))
 		fmt.Println()
-		fmt.Println("  1️⃣  Запустить интерактивный мониторинг")
-		fmt.Println("  2️⃣  Показать детальный отчет")
-		fmt.Println("  3️⃣  Экспортировать отчеты")
-		fmt.Println("  4️⃣  Статистика и настройки")
-		fmt.Println("  5️⃣  Справка")
-		fmt.Println("  0️⃣  Выход")
+		fmt.Println(T("console.menu.1"))
+		fmt.Println(T("console.menu.2"))
+		fmt.Println(T("console.menu.3"))
+		fmt.Println(T("console.menu.4"))
+		fmt.Println(T("console.menu.5"))
+		fmt.Println(T("console.menu.0"))
 		fmt.Println()
 
-		color.New(color.FgWhite).Print("Ваш выбор (0-5): ")
+		color.New(color.FgWhite).Print(T("console.prompt"))
 
 		var choice string
 		fmt.Scanln(&choice)
@@ -2463,10 +3428,10 @@ func showMainMenu() error {
 		case "5":
 			showHelp()
 		case "0", "q", "exit":
-			color.New(color.FgGreen).Println("\n👋 До свидания!")
+			color.New(color.FgGreen).Println(T("console.bye"))
 			return nil
 		default:
-			color.New(color.FgRed).Println("\n❌ Неверный выбор. Нажмите Enter для продолжения...")
+			color.New(color.FgRed).Println(T("console.invalid"))
 			fmt.Scanln()
 		}
 	}
@@ -2474,9 +3439,9 @@ func showMainMenu() error {
 
 // showQuickStatus показывает краткий статус батареи
 func showQuickStatus() error {
-	pct, state, err := parsePMSet()
+	pct, state, err := newBatterySource().Status()
 	if err != nil {
-		return fmt.Errorf("получение статуса: %w", err)
+		return fmt.Errorf(T("err.status"), err)
 	}
 
 	// Определяем цвет для процента заряда
@@ -2492,17 +3457,17 @@ func showQuickStatus() error {
 	// Форматируем статус
 	stateFormatted := formatStateWithEmoji(state, pct)
 
-	color.New(color.FgWhite).Print("💡 Текущий статус: ")
+	color.New(color.FgWhite).Print(T("console.current"))
 	percentColor.Printf("%d%% ", pct)
 	color.New(color.FgCyan).Printf("(%s)", stateFormatted)
 
 	// Добавляем информацию о режиме питания
 	if strings.ToLower(state) == "charging" {
-		color.New(color.FgBlue).Print(" 🔌 На зарядке")
+		color.New(color.FgBlue).Print(T("console.charging"))
 	} else if strings.ToLower(state) == "discharging" {
-		color.New(color.FgMagenta).Print(" 🔋 От батареи")
+		color.New(color.FgMagenta).Print(T("console.on_battery"))
 	} else {
-		color.New(color.FgGreen).Print(" ✅ Заряжена")
+		color.New(color.FgGreen).Print(T("console.charged"))
 	}
 
 	fmt.Println()
@@ -2513,14 +3478,14 @@ func showQuickStatus() error {
 
 // runMonitoringMode запускает интерактивный мониторинг
 func runMonitoringMode() error {
-	color.New(color.FgGreen).Println("🔄 Запуск интерактивного мониторинга...")
-	fmt.Println("💡 Программа определит режим работы автоматически")
+	color.New(color.FgGreen).Println(T("console.monitor.start"))
+	fmt.Println(T("console.monitor.auto"))
 	fmt.Println()
 
 	// Инициализируем БД
 	db, err := initDB(getDBPath())
 	if err != nil {
-		return fmt.Errorf("инициализация БД: %w", err)
+		return fmt.Errorf(T("err.db_init"), err)
 	}
 	defer db.Close()
 
@@ -2532,22 +3497,22 @@ func runMonitoringMode() error {
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigs
-		color.New(color.FgYellow).Println("\n⏹️ Получен сигнал завершения...")
+		color.New(color.FgYellow).Println(T("console.monitor.signal"))
 		cancel()
 	}()
 
 	// Проверяем состояние питания
 	onBattery, state, percentage, err := isOnBattery()
 	if err != nil {
-		color.New(color.FgYellow).Printf("⚠️ Ошибка определения питания: %v\n", err)
+		color.New(color.FgYellow).Println(T("console.monitor.power_failed", err))
 		return runReportMode() // Показываем отчет по имеющимся данным
 	}
 
-	color.New(color.FgCyan).Printf("⚡ Состояние питания: %s (%d%%)\n",
-		formatStateWithEmoji(state, percentage), percentage)
+	color.New(color.FgCyan).Println(T("console.monitor.power",
+		formatStateWithEmoji(state, percentage), percentage))
 
 	if onBattery {
-		color.New(color.FgBlue).Println("🔋 Работа от батареи - запуск мониторинга и дашборда...")
+		color.New(color.FgBlue).Println(T("console.monitor.battery"))
 
 		// Запускаем сбор данных в фоне
 		var wg sync.WaitGroup
@@ -2558,32 +3523,32 @@ func runMonitoringMode() error {
 		time.Sleep(2 * time.Second)
 
 		// Возвращаемся в меню для работы с Bubble Tea
-		color.New(color.FgBlue).Println("🔋 Данные собираются в фоне. Используйте главное меню для мониторинга.")
+		color.New(color.FgBlue).Println(T("console.monitor.background"))
 		
 		cancel()
 		wg.Wait()
 		return nil
 	} else {
-		color.New(color.FgGreen).Println("🔌 Работа от сети - показ сохраненных данных...")
+		color.New(color.FgGreen).Println(T("console.monitor.ac"))
 		return runReportMode()
 	}
 }
 
 // runReportMode показывает детальный отчет
 func runReportMode() error {
-	color.New(color.FgBlue).Println("📊 Загрузка детального отчета...")
+	color.New(color.FgBlue).Println(T("console.report.loading"))
 
 	db, err := initDB(getDBPath())
 	if err != nil {
-		return fmt.Errorf("инициализация БД: %w", err)
+		return fmt.Errorf(T("err.db_init"), err)
 	}
 	defer db.Close()
 
-	if err := printReport(db); err != nil {
-		return fmt.Errorf("вывод отчёта: %w", err)
+	if err := printReport(db, ReportRange{}, ""); err != nil {
+		return fmt.Errorf(T("report.err.print"), err)
 	}
 
-	color.New(color.FgWhite).Print("\nНажмите Enter для возврата в меню...")
+	color.New(color.FgWhite).Print(T("console.back"))
 	fmt.Scanln()
 
 	return nil
@@ -2594,17 +3559,17 @@ func runExportMenu() error {
 	for {
 		fmt.Print("\033[2J\033[H") // Очистка экрана
 
-		color.New(color.FgCyan, color.Bold).Println("📄 Экспорт отчетов")
+		color.New(color.FgCyan, color.Bold).Println(T("console.export.title"))
 		color.New(color.FgWhite).Println("═══════════════════════════════")
 		fmt.Println()
 
-		fmt.Println("  1️⃣  Экспорт в Markdown (.md)")
-		fmt.Println("  2️⃣  Экспорт в HTML (.html)")
-		fmt.Println("  3️⃣  Экспорт в оба формата")
-		fmt.Println("  0️⃣  Назад в главное меню")
+		fmt.Println(T("console.export.1"))
+		fmt.Println(T("console.export.2"))
+		fmt.Println(T("console.export.3"))
+		fmt.Println(T("console.export.0"))
 		fmt.Println()
 
-		color.New(color.FgWhite).Print("Выберите формат (0-3): ")
+		color.New(color.FgWhite).Print(T("console.export.prompt"))
 
 		var choice string
 		fmt.Scanln(&choice)
@@ -2619,7 +3584,7 @@ func runExportMenu() error {
 		case "0", "back":
 			return nil
 		default:
-			color.New(color.FgRed).Println("\n❌ Неверный выбор. Нажмите Enter для продолжения...")
+			color.New(color.FgRed).Println(T("console.invalid"))
 			fmt.Scanln()
 		}
 	}
@@ -2627,13 +3592,13 @@ func runExportMenu() error {
 
 // handleExport обрабатывает экспорт в выбранном формате
 func handleExport(format string) error {
-	color.New(color.FgWhite).Print("📝 Введите имя файла (без расширения): ")
+	color.New(color.FgWhite).Print(T("console.export.filename"))
 	var filename string
 	fmt.Scanln(&filename)
 
 	if filename == "" {
 		filename = fmt.Sprintf("battery_report_%s", time.Now().Format("20060102_150405"))
-		color.New(color.FgCyan).Printf("💡 Используется имя по умолчанию: %s\n", filename)
+		color.New(color.FgCyan).Println(T("console.export.default_name", filename))
 	}
 
 	var markdownFile, htmlFile string
@@ -2649,16 +3614,16 @@ func handleExport(format string) error {
 	}
 
 	fmt.Println()
-	color.New(color.FgBlue).Println("📊 Генерация отчета...")
+	color.New(color.FgBlue).Println(T("console.export.generating"))
 
-	err := runExportMode(markdownFile, htmlFile, false)
+	err := runExportMode(markdownFile, htmlFile, ReportRange{}, "", false)
 	if err != nil {
-		color.New(color.FgRed).Printf("❌ Ошибка экспорта: %v\n", err)
+		color.New(color.FgRed).Println(T("console.export.failed", err))
 	} else {
-		color.New(color.FgGreen).Println("✅ Экспорт выполнен успешно!")
+		color.New(color.FgGreen).Println(T("console.export.done"))
 	}
 
-	color.New(color.FgWhite).Print("\nНажмите Enter для продолжения...")
+	color.New(color.FgWhite).Print(T("console.continue_nl"))
 	fmt.Scanln()
 
 	return err
@@ -2668,26 +3633,38 @@ func handleExport(format string) error {
 func runSettingsMenu() error {
 	fmt.Print("\033[2J\033[H") // Очистка экрана
 
-	color.New(color.FgRed, color.Bold).Println("🗑️  Очистка базы данных")
+	color.New(color.FgRed, color.Bold).Println(T("console.clear.title"))
 	color.New(color.FgWhite).Println("═══════════════════════════════")
 	fmt.Println()
 	
-	color.New(color.FgYellow, color.Bold).Println("⚠️  ВНИМАНИЕ: Эта операция удалит ВСЕ сохраненные данные!")
+	color.New(color.FgYellow, color.Bold).Println(T("console.clear.warning"))
 	fmt.Println()
-	fmt.Println("Будут удалены:")
-	fmt.Println("  • Все измерения батареи")
-	fmt.Println("  • История состояний")
-	fmt.Println("  • Статистика использования")
+	fmt.Println(T("console.clear.list"))
+	fmt.Println(T("console.clear.list.1"))
+	fmt.Println(T("console.clear.list.2"))
+	fmt.Println(T("console.clear.list.3"))
 	fmt.Println()
 	
-	color.New(color.FgWhite).Print("Вы уверены? (y/н): ")
+	color.New(color.FgWhite).Print(T("console.clear.confirm"))
 	
 	var choice string
 	fmt.Scanln(&choice)
 	
 	if choice == "y" || choice == "Y" || choice == "н" || choice == "Н" {
-		// Удаляем файлы базы данных
+		// Без резервной копии не удаляем: очистку можно будет отменить через db restore
 		dbPath := getDBPath()
+		backup, err := autoBackupFile(dbPath, "clear")
+		if err != nil {
+			color.New(color.FgRed).Println(T("console.clear.aborted", err))
+			fmt.Println(T("console.continue_nl"))
+			fmt.Scanln()
+			return nil
+		}
+		if backup != "" {
+			fmt.Println(T("console.clear.backup", backup))
+		}
+
+		// Удаляем файлы базы данных
 		dbFiles := []string{
 			dbPath,                // .batmon.sqlite
 			dbPath + "-shm",       // .batmon.sqlite-shm
@@ -2697,16 +3674,16 @@ func runSettingsMenu() error {
 		for _, file := range dbFiles {
 			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
 				// Не возвращаем ошибку, если файл не существует
-				color.New(color.FgYellow).Printf("⚠️  Не удалось удалить %s: %v\n", file, err)
+				color.New(color.FgYellow).Println(T("console.clear.remove_failed", file, err))
 			}
 		}
 		
-		color.New(color.FgGreen).Println("✅ База данных успешно очищена!")
-		fmt.Println("\nНажмите Enter для продолжения...")
+		color.New(color.FgGreen).Println(T("console.clear.done"))
+		fmt.Println(T("console.continue_nl"))
 		fmt.Scanln()
 	} else {
-		color.New(color.FgYellow).Println("❌ Операция отменена")
-		fmt.Println("\nНажмите Enter для продолжения...")
+		color.New(color.FgYellow).Println(T("console.clear.cancelled"))
+		fmt.Println(T("console.continue_nl"))
 		fmt.Scanln()
 	}
 	
@@ -2727,16 +3704,16 @@ func showDatabaseStats() error {
 		return err
 	}
 
-	color.New(color.FgGreen).Println("📊 Статистика данных:")
-	fmt.Printf("   📦 Записей в БД: %v\n", stats["total_records"])
-	fmt.Printf("   💾 Размер БД: %.1f МБ\n", stats["db_size_mb"])
-	fmt.Printf("   🗄️ Буфер памяти: %v/%v записей\n", stats["buffer_size"], stats["buffer_max_size"])
+	color.New(color.FgGreen).Println(T("console.stats.title"))
+	fmt.Println(T("console.stats.records", stats["total_records"]))
+	fmt.Println(T("console.stats.size", stats["db_size_mb"]))
+	fmt.Println(T("console.stats.buffer", stats["buffer_size"], stats["buffer_max_size"]))
 
 	if oldest, ok := stats["oldest_record"].(string); ok && oldest != "" {
-		color.New(color.FgCyan).Printf("   📅 Самая старая запись: %s\n", oldest)
+		color.New(color.FgCyan).Println(T("console.stats.oldest", oldest))
 	}
 	if newest, ok := stats["newest_record"].(string); ok && newest != "" {
-		color.New(color.FgCyan).Printf("   📅 Самая новая запись: %s\n", newest)
+		color.New(color.FgCyan).Println(T("console.stats.newest", newest))
 	}
 
 	return nil
@@ -2744,7 +3721,7 @@ func showDatabaseStats() error {
 
 // showAdvancedMetrics показывает расширенные метрики
 func showAdvancedMetrics() error {
-	color.New(color.FgBlue).Println("🔬 Загрузка расширенных метрик...")
+	color.New(color.FgBlue).Println(T("console.metrics.loading"))
 
 	db, err := initDB(getDBPath())
 	if err != nil {
@@ -2754,12 +3731,12 @@ func showAdvancedMetrics() error {
 
 	measurements, err := getLastNMeasurements(db, 50)
 	if err != nil {
-		return fmt.Errorf("получение данных: %w", err)
+		return fmt.Errorf(T("err.data"), err)
 	}
 
 	if len(measurements) == 0 {
-		color.New(color.FgYellow).Println("⚠️ Недостаточно данных для анализа")
-		color.New(color.FgWhite).Print("Нажмите Enter для продолжения...")
+		color.New(color.FgYellow).Println(T("console.metrics.not_enough"))
+		color.New(color.FgWhite).Print(T("console.continue"))
 		fmt.Scanln()
 		return nil
 	}
@@ -2767,18 +3744,25 @@ func showAdvancedMetrics() error {
 	metrics := analyzeAdvancedMetrics(measurements)
 
 	fmt.Println()
-	color.New(color.FgGreen, color.Bold).Println("🔬 Расширенные метрики:")
+	color.New(color.FgGreen, color.Bold).Println(T("console.metrics.title"))
 	color.New(color.FgWhite).Println("═══════════════════════════════")
 
-	fmt.Printf("⚡ Энергоэффективность: %.1f%%\n", metrics.PowerEfficiency)
-	fmt.Printf("🔧 Стабильность напряжения: %.1f%%\n", metrics.VoltageStability)
-	fmt.Printf("🔋 Эффективность зарядки: %.2f\n", metrics.ChargingEfficiency)
-	fmt.Printf("📊 Тренд мощности: %s\n", metrics.PowerTrend)
-	fmt.Printf("🏆 Рейтинг здоровья: %d/100\n", metrics.HealthRating)
-	fmt.Printf("🍎 Статус Apple: %s\n", metrics.AppleStatus)
+	fmt.Println(T("console.metrics.efficiency", metrics.PowerEfficiency))
+	fmt.Println(T("console.metrics.voltage", metrics.VoltageStability))
+	fmt.Println(T("console.metrics.charging", metrics.ChargingEfficiency))
+	fmt.Println(T("console.metrics.trend", metrics.PowerTrend))
+	fmt.Println(T("console.metrics.rating", metrics.HealthRating))
+	for _, f := range metrics.HealthBreakdown {
+		fmt.Printf("   %s", f.Name)
+		if f.Detail != "" {
+			fmt.Printf(" (%s)", f.Detail)
+		}
+		fmt.Printf(": %s\n", formatScorePoints(f.Points))
+	}
+	fmt.Println(T("console.metrics.apple", metrics.AppleStatus))
 
 	fmt.Println()
-	color.New(color.FgWhite).Print("Нажмите Enter для продолжения...")
+	color.New(color.FgWhite).Print(T("console.continue"))
 	fmt.Scanln()
 
 	return nil
@@ -2786,7 +3770,7 @@ func showAdvancedMetrics() error {
 
 // cleanupOldData выполняет очистку старых данных
 func cleanupOldData() error {
-	color.New(color.FgYellow).Println("🧹 Очистка старых данных...")
+	color.New(color.FgYellow).Println(T("console.cleanup.start"))
 
 	db, err := initDB(getDBPath())
 	if err != nil {
@@ -2794,15 +3778,15 @@ func cleanupOldData() error {
 	}
 	defer db.Close()
 
-	retention := NewDataRetention(db, 90*24*time.Hour) // 3 месяца
+	retention := NewDataRetention(newSQLiteMeasurements(db), getConfig().Collector.Retention())
 
 	if err := retention.Cleanup(); err != nil {
-		color.New(color.FgRed).Printf("❌ Ошибка очистки: %v\n", err)
+		color.New(color.FgRed).Println(T("console.cleanup.failed", err))
 	} else {
-		color.New(color.FgGreen).Println("✅ Очистка выполнена успешно")
+		color.New(color.FgGreen).Println(T("console.cleanup.done"))
 	}
 
-	color.New(color.FgWhite).Print("Нажмите Enter для продолжения...")
+	color.New(color.FgWhite).Print(T("console.continue"))
 	fmt.Scanln()
 
 	return nil
@@ -2810,32 +3794,42 @@ func cleanupOldData() error {
 
 // showSystemInfo показывает информацию о системе
 func showSystemInfo() error {
-	color.New(color.FgGreen, color.Bold).Println("💻 Информация о системе:")
+	printSystemInfo()
+
+	fmt.Println()
+	color.New(color.FgWhite).Print(T("console.continue"))
+	fmt.Scanln()
+
+	return nil
+}
+
+// printSystemInfo выводит версию, путь к БД и доступность системных утилит
+func printSystemInfo() {
+	color.New(color.FgGreen, color.Bold).Println(T("sysinfo.title"))
 	color.New(color.FgWhite).Println("═══════════════════════════════")
 
 	// Информация о версии Go
-	fmt.Printf("🔧 Версия Go: %s\n", "1.24+")
-	fmt.Printf("💾 База данных: SQLite с WAL режимом\n")
-	fmt.Printf("📁 Файл БД: %s\n", getDBPath())
+	fmt.Println(T("sysinfo.go", "1.24+"))
+	fmt.Println(T("sysinfo.db"))
+	fmt.Println(T("sysinfo.db_file", getDBPath()))
 
-	// Проверяем доступность команд
-	if _, err := exec.LookPath("pmset"); err == nil {
-		color.New(color.FgGreen).Println("✅ pmset доступен")
-	} else {
-		color.New(color.FgRed).Println("❌ pmset недоступен")
+	fmt.Println(T("sysinfo.source", newBatterySource().Name()))
+	if id := hardwareModel(); id != "" {
+		model, known := macModels[id]
+		if !known {
+			model.Name = T("sysinfo.unknown_model")
+		}
+		fmt.Println(T("sysinfo.model", id, model.Name))
 	}
 
-	if _, err := exec.LookPath("system_profiler"); err == nil {
-		color.New(color.FgGreen).Println("✅ system_profiler доступен")
-	} else {
-		color.New(color.FgRed).Println("❌ system_profiler недоступен")
+	// Проверяем доступность команд
+	for _, tool := range platformTools() {
+		if _, err := exec.LookPath(tool); err == nil {
+			color.New(color.FgGreen).Println(T("sysinfo.tool_ok", tool))
+		} else {
+			color.New(color.FgRed).Println(T("sysinfo.tool_missing", tool))
+		}
 	}
-
-	fmt.Println()
-	color.New(color.FgWhite).Print("Нажмите Enter для продолжения...")
-	fmt.Scanln()
-
-	return nil
 }
 
 // getVersion возвращает версию приложения из git тега
@@ -2859,79 +3853,98 @@ func getVersion() string {
 func showVersion() {
 	version := getVersion()
 	color.New(color.FgCyan, color.Bold).Printf("BatMon %s\n", version)
-	color.New(color.FgWhite).Println("Мониторинг батареи MacBook (Apple Silicon)")
+	color.New(color.FgWhite).Println(T("cli.version.tagline"))
 }
 
 // showHelp показывает справочную информацию
 func showHelp() {
 	fmt.Print("\033[2J\033[H") // Очистка экрана
 
-	color.New(color.FgCyan, color.Bold).Println("❓ Справка BatMon v2.0")
+	color.New(color.FgCyan, color.Bold).Println(T("cli.help.title"))
 	color.New(color.FgWhite).Println("═══════════════════════════════")
 	fmt.Println()
 
-	color.New(color.FgGreen).Println("🔋 О программе:")
-	fmt.Println("BatMon - это продвинутая утилита для мониторинга состояния батареи MacBook.")
-	fmt.Println("Поддерживает интерактивный мониторинг, детальную аналитику и экспорт отчетов.")
+	color.New(color.FgGreen).Println(T("cli.help.about"))
+	fmt.Println(T("cli.help.about.1"))
+	fmt.Println(T("cli.help.about.2"))
+	fmt.Println()
+
+	color.New(color.FgYellow).Println(T("cli.help.features"))
+	fmt.Println(T("cli.help.features.1"))
+	fmt.Println(T("cli.help.features.2")) 
+	fmt.Println(T("cli.help.features.3"))
+	fmt.Println(T("cli.help.features.4"))
+	fmt.Println(T("cli.help.features.5"))
+	fmt.Println(T("cli.help.features.6"))
 	fmt.Println()
 
-	color.New(color.FgYellow).Println("📊 Возможности:")
-	fmt.Println("• Интерактивный дашборд с графиками")
-	fmt.Println("• Анализ трендов и прогноз деградации") 
-	fmt.Println("• Мониторинг температуры и расширенных метрик")
-	fmt.Println("• Экспорт в Markdown и HTML форматы")
-	fmt.Println("• Автоматическая ретенция данных")
-	fmt.Println("• Цветной вывод и эмодзи индикаторы")
+	color.New(color.FgMagenta).Println(T("cli.help.tui"))
+	fmt.Println(T("cli.help.tui.intro"))
+	fmt.Println(T("cli.help.tui.1"))
+	fmt.Println(T("cli.help.tui.2"))
+	fmt.Println(T("cli.help.tui.3"))
+	fmt.Println(T("cli.help.tui.4"))
+	fmt.Println()
+	color.New(color.FgCyan).Println(T("cli.help.tui.run"))
 	fmt.Println()
 
-	color.New(color.FgMagenta).Println("🫧 Интерфейс Bubble Tea (по умолчанию):")
-	fmt.Println("Современный интерфейс с:")
-	fmt.Println("• Интерактивными компонентами и анимациями")
-	fmt.Println("• Отличной отзывчивостью и производительностью")
-	fmt.Println("• Адаптивными макетами")
-	fmt.Println("• Красивой стилизацией")
+	color.New(color.FgGreen).Println(T("cli.help.commands"))
+	printCLIUsage(os.Stdout)
+	fmt.Println()
+	fmt.Println(T("cli.help.examples"))
+	fmt.Println("  batmon export --md report.md --html report.html")
+	fmt.Println("  batmon --db ~/backup/batmon.sqlite report")
+	fmt.Println(T("cli.help.example.tmux"))
+	fmt.Println(T("cli.help.example.certificate"))
 	fmt.Println()
-	color.New(color.FgCyan).Println("Запуск: ./batmon")
+
+	color.New(color.FgGreen).Println(T("cli.help.no_battery"))
+	fmt.Println(T("cli.help.no_battery.replay"))
 	fmt.Println()
 
-	color.New(color.FgBlue).Println("🎯 Режимы работы:")
-	fmt.Println("1. Интерактивный мониторинг - при работе от батареи")
-	fmt.Println("2. Детальный отчет - анализ сохраненных данных")
-	fmt.Println("3. Экспорт отчетов - сохранение в файлы")
-	fmt.Println("4. Статистика - информация о данных и системе")
+	color.New(color.FgBlue).Println(T("cli.help.modes"))
+	fmt.Println(T("cli.help.modes.1"))
+	fmt.Println(T("cli.help.modes.2"))
+	fmt.Println(T("cli.help.modes.3"))
+	fmt.Println(T("cli.help.modes.4"))
 	fmt.Println()
 
-	color.New(color.FgMagenta).Println("🔧 Требования:")
-	fmt.Println("• macOS (протестировано на Apple Silicon)")
-	fmt.Println("• Go 1.24+ для сборки из исходников")
-	fmt.Println("• MacBook с батареей")
+	color.New(color.FgMagenta).Println(T("cli.help.requirements"))
+	fmt.Println(T("cli.help.requirements.1"))
+	fmt.Println(T("cli.help.requirements.2"))
+	fmt.Println(T("cli.help.requirements.3"))
 	fmt.Println()
 
-	color.New(color.FgRed).Println("🆘 Поддержка:")
+	color.New(color.FgRed).Println(T("cli.help.support"))
 	fmt.Println("• GitHub: https://github.com/region23/batmon")
-	fmt.Println("• Issues: сообщайте о проблемах через GitHub Issues")
+	fmt.Println(T("cli.help.support.issues"))
 	fmt.Println()
 
-	color.New(color.FgWhite).Print("Нажмите Enter для возврата в меню...")
+	color.New(color.FgWhite).Print(T("cli.help.back"))
 	fmt.Scanln()
 }
 
 // runExportMode выполняет экспорт отчетов
-func runExportMode(markdownFile, htmlFile string, quiet bool) error {
+func runExportMode(markdownFile, htmlFile string, rng ReportRange, compare string, quiet bool) error {
 	if !quiet {
-		fmt.Println("🔋 Batmon - Экспорт отчетов")
+		fmt.Println(T("export.title"))
 	}
 
 	db, err := initDB(getDBPath())
 	if err != nil {
-		return fmt.Errorf("инициализация БД: %w", err)
+		return fmt.Errorf(T("err.db_init"), err)
 	}
 	defer db.Close()
 
 	// Генерируем данные для отчета
-	data, err := generateReportData(db)
+	data, err := generateReportDataRange(db, rng)
 	if err != nil {
-		return fmt.Errorf("генерация данных отчета: %w", err)
+		return fmt.Errorf(T("report.err.generate"), err)
+	}
+	if compare != "" {
+		if data.Comparison, err = loadComparison(db, compare); err != nil {
+			return err
+		}
 	}
 
 	var exported []string
@@ -2943,17 +3956,17 @@ func runExportMode(markdownFile, htmlFile string, quiet bool) error {
 		}
 		
 		// Получаем правильный путь для экспорта
-		fullMarkdownPath, err := getExportPath(markdownFile)
+		fullMarkdownPath, err := resolveExportPath(markdownFile, exportVars{At: data.GeneratedAt, Serial: data.Latest.BatterySerial})
 		if err != nil {
-			return fmt.Errorf("не удалось определить путь для Markdown файла: %w", err)
+			return fmt.Errorf(T("export.err.md_path"), err)
 		}
 
 		if !quiet {
-			fmt.Printf("📝 Экспортирую отчет в Markdown: %s\n", fullMarkdownPath)
+			fmt.Println(T("export.markdown", fullMarkdownPath))
 		}
 
 		if err := exportToMarkdown(data, fullMarkdownPath); err != nil {
-			return fmt.Errorf("экспорт в Markdown: %w", err)
+			return fmt.Errorf(T("export.err.md"), err)
 		}
 		exported = append(exported, fullMarkdownPath)
 	}
@@ -2965,23 +3978,23 @@ func runExportMode(markdownFile, htmlFile string, quiet bool) error {
 		}
 		
 		// Получаем правильный путь для экспорта
-		fullHTMLPath, err := getExportPath(htmlFile)
+		fullHTMLPath, err := resolveExportPath(htmlFile, exportVars{At: data.GeneratedAt, Serial: data.Latest.BatterySerial})
 		if err != nil {
-			return fmt.Errorf("не удалось определить путь для HTML файла: %w", err)
+			return fmt.Errorf(T("export.err.html_path"), err)
 		}
 
 		if !quiet {
-			fmt.Printf("🌐 Экспортирую отчет в HTML: %s\n", fullHTMLPath)
+			fmt.Println(T("export.html", fullHTMLPath))
 		}
 
 		if err := exportToHTML(data, fullHTMLPath); err != nil {
-			return fmt.Errorf("экспорт в HTML: %w", err)
+			return fmt.Errorf(T("export.err.html"), err)
 		}
 		exported = append(exported, fullHTMLPath)
 	}
 
 	if !quiet && len(exported) > 0 {
-		fmt.Printf("✅ Экспорт завершен! Созданы файлы:\n")
+		fmt.Println(T("export.done"))
 		for _, file := range exported {
 			absPath, _ := filepath.Abs(file)
 			fmt.Printf("   - %s\n", absPath)
@@ -2994,26 +4007,33 @@ func runExportMode(markdownFile, htmlFile string, quiet bool) error {
 // Bubble Tea функции
 
 // NewDataService создает новый сервис данных
-func NewDataService(db *sqlx.DB, buffer *MemoryBuffer) *DataService {
+func NewDataService(store *Store, buffer *MemoryBuffer) *DataService {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	// Используем существующую функцию NewDataCollector для правильной инициализации
-	collector := NewDataCollector(db)
+	collector := NewDataCollector(store.DB())
 	// Заменяем буфер на наш
 	collector.buffer = buffer
 	
 	return &DataService{
 		collector: collector,
-		db:        db,
+		store:     store,
+		db:        store.DB(),
 		buffer:    buffer,
 		ctx:       ctx,
 		cancel:    cancel,
+		interval:  make(chan time.Duration, 1),
 	}
 }
 
 // Start запускает фоновый сбор данных
 func (ds *DataService) Start() {
-	ds.startCaffeinate()
+	ds.events, ds.unsubscribe = ds.collector.Subscribe()
+	if ds.replay != nil {
+		go ds.replay.run(ds)
+		return
+	}
+	ds.syncCaffeinate()
 	go ds.collectData()
 }
 
@@ -3021,6 +4041,12 @@ func (ds *DataService) Start() {
 func (ds *DataService) Stop() {
 	ds.stopCaffeinate()
 	ds.cancel()
+	if ds.unsubscribe != nil {
+		ds.unsubscribe()
+	}
+	if err := ds.collector.Close(); err != nil {
+		logWarnf("⚠️ %v", err)
+	}
 }
 
 // startCaffeinate запускает caffeinate для предотвращения засыпания
@@ -3029,23 +4055,37 @@ func (ds *DataService) startCaffeinate() {
 		return
 	}
 	
-	// Используем -i флаг для предотвращения idle засыпания
-	// Это не мешает засыпанию при закрытии крышки
-	ds.caffeinate = exec.CommandContext(ds.ctx, "caffeinate", "-i")
+	// caffeinate -i на macOS, systemd-inhibit на Linux, SetThreadExecutionState на Windows.
+	// Засыпанию при закрытии крышки это не мешает
+	name, args, ok := sleepInhibitorCommand()
+	if !ok {
+		return
+	}
+	ds.caffeinate = exec.CommandContext(ds.ctx, name, args...)
 	
 	err := ds.caffeinate.Start()
 	if err != nil {
-		log.Printf("Предупреждение: не удалось запустить caffeinate: %v", err)
+		logWarnf("Предупреждение: не удалось запустить caffeinate: %v", err)
 		return
 	}
 	
 	ds.caffeineActive = true
-	log.Println("✅ Предотвращение засыпания MacBook активировано")
+	logInfof("✅ Предотвращение засыпания MacBook активировано")
+	
+	// PID в файле состояния: после аварийного выхода процесс найдет следующий запуск
+	statePath := caffeinateStatePath()
+	if err := saveCaffeinateState(statePath, ds.caffeinate); err != nil {
+		logWarnf("⚠️ %v", err)
+	}
 	
 	// Запускаем горутину для отслеживания завершения процесса
+	cmd, done := ds.caffeinate, make(chan struct{})
+	ds.caffeinateDone = done
 	go func() {
-		ds.caffeinate.Wait()
+		cmd.Wait()
 		ds.caffeineActive = false
+		clearCaffeinateState(statePath, cmd.Process.Pid)
+		close(done)
 	}()
 }
 
@@ -3057,9 +4097,15 @@ func (ds *DataService) stopCaffeinate() {
 	
 	err := ds.caffeinate.Process.Kill()
 	if err != nil {
-		log.Printf("Предупреждение: не удалось остановить caffeinate: %v", err)
+		logWarnf("Предупреждение: не удалось остановить caffeinate: %v", err)
 	} else {
-		log.Println("🛌 Предотвращение засыпания MacBook отключено")
+		// Ждем выхода, чтобы не оставить процесс, запрещающий сон
+		select {
+		case <-ds.caffeinateDone:
+			logInfof("🛌 Предотвращение засыпания MacBook отключено")
+		case <-time.After(caffeinateStopTimeout):
+			logWarnf("Предупреждение: caffeinate не завершился за %v", caffeinateStopTimeout)
+		}
 	}
 	
 	ds.caffeineActive = false
@@ -3068,18 +4114,35 @@ func (ds *DataService) stopCaffeinate() {
 
 // collectData выполняет фоновый сбор данных
 func (ds *DataService) collectData() {
-	ticker := time.NewTicker(30 * time.Second)
+	base := ds.collector.pmsetInterval // интервал из настроек
+	interval := base                   // текущий интервал по политике опроса
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	ds.nextSample.Store(time.Now().Add(interval).UnixNano())
 	
 	for {
 		select {
 		case <-ds.ctx.Done():
 			return
+		case d := <-ds.interval:
+			base, interval = d, d
+			ticker.Reset(d)
+			ds.nextSample.Store(time.Now().Add(interval).UnixNano())
 		case <-ticker.C:
+			// Интервал выбирается по предыдущему измерению: текущее собирается асинхронно
+			if next := ds.collector.NextInterval(base); next != interval {
+				logDebugf("⏱️ Интервал опроса: %v → %v", interval, next)
+				interval = next
+				ticker.Reset(interval)
+			}
+			ds.nextSample.Store(time.Now().Add(interval).UnixNano())
 			// Собираем данные асинхронно
 			go func() {
 				if err := ds.collector.CollectAndStore(); err != nil {
-					log.Printf("Ошибка сбора данных: %v", err)
+					logErrorf("Ошибка сбора данных: %v", err)
+				}
+				if err := ds.store.CheckpointIfDue(); err != nil {
+					logWarnf("⚠️ %v", err)
 				}
 			}()
 		}
@@ -3096,79 +4159,134 @@ func (ds *DataService) GetLast(n int) []Measurement {
 	return ds.buffer.GetLast(n)
 }
 
+// GetLatestPower возвращает свежую выборку powermetrics или nil,
+// если подробный режим выключен или выборка устарела
+func (ds *DataService) GetLatestPower() *PowerSample {
+	if ds.replay != nil || !getConfig().Power.Powermetrics {
+		return nil
+	}
+	s, err := getLatestPowerSample(ds.db)
+	if err != nil || s == nil {
+		return nil
+	}
+	if t, err := time.Parse(time.RFC3339, s.Timestamp); err != nil || time.Since(t) > 5*time.Minute {
+		return nil
+	}
+	return s
+}
+
+// Now возвращает текущее время сервиса: при воспроизведении – время записи
+func (ds *DataService) Now() time.Time {
+	if ds.replay != nil {
+		return ds.replay.Now()
+	}
+	return time.Now()
+}
+
 // Сообщения Bubble Tea
 type tickMsg time.Time
 type dataUpdateMsg struct {
 	measurements []Measurement
+	chartData    []Measurement
 	latest       *Measurement
+	power        *PowerSample // последняя выборка powermetrics (nil – режим выключен)
+	powerEvents  []PowerEvent // события питания за окно графиков
+	notes        []Note       // заметки за окно графиков
+	live         bool         // пришло от коллектора сразу после измерения
 }
 
 type errorMsg struct{ err error }
 
 // Команды Bubble Tea
-func tickEvery() tea.Cmd {
-	return tea.Every(time.Second*10, func(t time.Time) tea.Msg {
+func tickEvery(interval time.Duration) tea.Cmd {
+	return tea.Every(interval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
-func updateData(ds *DataService) tea.Cmd {
+func updateData(ds *DataService, view ChartView) tea.Cmd {
 	return func() tea.Msg {
 		latest := ds.GetLatest()
 		measurements := ds.GetLast(50)
 		return dataUpdateMsg{
 			measurements: measurements,
+			chartData:    ds.GetView(view),
 			latest:       latest,
+			power:        ds.GetLatestPower(),
+			powerEvents:  ds.GetPowerEvents(view),
+			notes:        ds.GetNotes(view),
 		}
 	}
 }
 
 // NewApp создает новое приложение
 func NewApp() *App {
-	// Инициализация базы данных и буфера
-	db, err := initDB(getDBPath())
+	// Инициализация базы данных и буфера: одно соединение на всё приложение
+	store, err := openStore(getDBPath())
 	if err != nil {
 		log.Fatal(err)
 	}
 	
 	buffer := NewMemoryBuffer(100)
-	if err := buffer.LoadFromDB(db, 100); err != nil {
-		log.Printf("Предупреждение: не удалось загрузить данные из БД: %v", err)
+	if err := buffer.LoadFromDB(store.DB(), 100); err != nil {
+		logWarnf("Предупреждение: не удалось загрузить данные из БД: %v", err)
+	}
+	
+	// caffeinate, переживший аварийно завершенный batmon, не дает Mac уснуть
+	if pid, err := reapOrphanedCaffeinate(caffeinateStatePath()); err != nil {
+		logWarnf("⚠️ %v", err)
+	} else if pid != 0 {
+		logInfof("🧹 Остановлен caffeinate (PID %d), оставшийся от прошлого запуска", pid)
 	}
 	
 	// Создание сервиса данных
-	dataService := NewDataService(db, buffer)
+	dataService := NewDataService(store, buffer)
 	dataService.Start()
-	
-	// Создание главного меню
-	menuItems := []list.Item{
-		menuItem{title: "🔋 Полный анализ батареи (100% → 0%)", desc: "Запустите при 100% заряде, разрядите до 0% для полной диагностики"},
-		menuItem{title: "⚡ Быстрая диагностика", desc: "Проверить текущее состояние батареи и показать рекомендации"},
-		menuItem{title: "📊 Детальный отчет", desc: "Анализ всех сохраненных данных с графиками и прогнозами"},
-		menuItem{title: "📄 Экспорт отчетов", desc: "Сохранить результаты в Markdown или HTML с графиками"},
-		menuItem{title: "🗑️  Очистить данные", desc: "Удалить все сохраненные измерения (начать заново)"},
-		menuItem{title: "❓ Справка", desc: "Как правильно использовать программу для анализа батареи"},
-		menuItem{title: "❌ Выход", desc: "Завершить работу программы"},
+
+	return newApp(dataService)
+}
+
+// mainMenuItems возвращает пункты главного меню на текущем языке
+func mainMenuItems() []list.Item {
+	return []list.Item{
+		menuItem{title: T("menu.full"), desc: T("menu.full.desc")},
+		menuItem{title: T("menu.quick"), desc: T("menu.quick.desc")},
+		menuItem{title: T("menu.report"), desc: T("menu.report.desc")},
+		menuItem{title: T("menu.export"), desc: T("menu.export.desc")},
+		menuItem{title: T("menu.settings"), desc: T("menu.settings.desc")},
+		menuItem{title: T("menu.logs"), desc: T("menu.logs.desc")},
+		menuItem{title: T("menu.help"), desc: T("menu.help.desc")},
+		menuItem{title: T("menu.quit"), desc: T("menu.quit.desc")},
 	}
-	
-	menuList := list.New(menuItems, list.NewDefaultDelegate(), 0, 0)
-	menuList.Title = "🔋 BatMon - Мониторинг батареи MacBook"
+}
+
+// newApp создает приложение поверх готового сервиса данных
+func newApp(dataService *DataService) *App {
+	// Создание главного меню
+	menuList := list.New(mainMenuItems(), list.NewDefaultDelegate(), 0, 0)
+	menuList.Title = T("menu.title")
 	
 	return &App{
 		state: StateWelcome,
 		menu: MenuModel{
 			list: menuList,
 		},
-		dataService: dataService,
+		dataService:     dataService,
+		refreshInterval: 10 * time.Second,
 	}
 }
 
 // Init инициализирует модель
 func (a *App) Init() tea.Cmd {
-	return tea.Batch(
-		tickEvery(),
-		updateData(a.dataService),
-	)
+	cmds := []tea.Cmd{
+		tickEvery(a.refreshInterval),
+		updateData(a.dataService, a.dashboard.chartView),
+		liveTick(),
+	}
+	if a.dataService != nil && a.dataService.events != nil {
+		cmds = append(cmds, waitForMeasurement(a.dataService, a.dataService.events, a.dashboard.chartView))
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update обрабатывает сообщения
@@ -3182,6 +4300,11 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.updateComponentSizes()
 		
 	case tea.KeyMsg:
+		if msg.String() == "ctrl+t" {
+			// Переключение цветовой темы на любом экране
+			nextTheme()
+			return a, nil
+		}
 		switch a.state {
 		case StateWelcome:
 			return a.updateWelcome(msg)
@@ -3199,20 +4322,63 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a.updateSettings(msg)
 		case StateHelp:
 			return a.updateHelp(msg)
+		case StateCalibration:
+			return a.updateCalibration(msg)
+		case StateLogs:
+			return a.updateLogs(msg)
+		case StateDoctor:
+			return a.updateDoctor(msg)
 		}
 		
 	case tickMsg:
-		cmds = append(cmds, tickEvery())
+		cmds = append(cmds, tickEvery(a.refreshInterval))
 		if a.state == StateDashboard {
-			cmds = append(cmds, updateData(a.dataService))
+			a.refreshPowerStatus()
+		} else if a.dataService != nil {
+			a.dataService.syncCaffeinate() // тест мог завершиться сам
+		}
+		if a.state == StateDashboard || a.state == StateCalibration {
+			cmds = append(cmds, updateData(a.dataService, a.dashboard.chartView))
 		}
 		
+	case exportProgressMsg, exportStepMsg:
+		cmds = append(cmds, a.handleExportProgress(msg))
+		
+	case liveTickMsg:
+		// Отсчет до следующего измерения перерисовывается раз в секунду
+		cmds = append(cmds, liveTick())
+		
+	case doctorResultMsg:
+		a.doctor.checks = msg
+		a.doctor.running = false
+		a.doctor.ranAt = time.Now()
+		
 	case dataUpdateMsg:
+		if msg.live {
+			cmds = append(cmds, waitForMeasurement(a.dataService, a.dataService.events, a.dashboard.chartView))
+		}
 		a.measurements = msg.measurements
+		a.chartData = msg.chartData
 		a.latest = msg.latest
+		a.power = msg.power
+		a.powerEvents = msg.powerEvents
+		a.notes = msg.notes
 		if a.state == StateDashboard {
 			a.updateDashboardData()
 		}
+		if a.state == StateCalibration {
+			a.loadCalibration()
+		}
+		// Тест полной разрядки встал на паузу – показываем экран с выбором действия
+		if a.state == StateDashboard {
+			if t, err := getActiveCalibration(a.dataService.db); err == nil && t != nil &&
+				t.Status == calibrationPaused && a.calibrationPauseSeen != t.ID {
+				a.calibrationPauseSeen = t.ID
+				a.state = StateCalibration
+				a.initCalibration()
+				a.calibration.message = T("calibration.paused_by_charger")
+			}
+		}
 	}
 	
 	return a, tea.Batch(cmds...)
@@ -3222,30 +4388,34 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (a *App) updateMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q", "й":
-		a.dataService.Stop()
 		return a, tea.Quit
 		
 	case "enter":
 		selected := a.menu.list.SelectedItem()
 		if item, ok := selected.(menuItem); ok {
 			switch item.title {
-			case "🔋 Полный анализ батареи (100% → 0%)":
-				a.state = StateDashboard
-				a.initDashboard()
-			case "⚡ Быстрая диагностика":
+			case T("menu.full"):
+				a.state = StateCalibration
+				a.initCalibration()
+				return a, updateData(a.dataService, a.dashboard.chartView)
+			case T("menu.quick"):
 				a.state = StateQuickDiag
 				a.initQuickDiag()
-			case "📊 Детальный отчет":
+			case T("menu.report"):
 				a.state = StateReport
 				a.initReport()
-			case "📄 Экспорт отчетов":
+			case T("menu.export"):
 				a.state = StateExport
-			case "🗑️  Очистить данные":
+				a.initExport()
+			case T("menu.settings"):
 				a.state = StateSettings
-			case "❓ Справка":
+				a.settings = SettingsModel{}
+			case T("menu.logs"):
+				a.state = StateLogs
+				a.initLogs()
+			case T("menu.help"):
 				a.state = StateHelp
-			case "❌ Выход":
-				a.dataService.Stop()
+			case T("menu.quit"):
 				return a, tea.Quit
 			}
 		}
@@ -3264,10 +4434,71 @@ func (a *App) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		a.dashboardScrollY = 0 // Сбрасываем скролл при выходе
 		return a, nil
 	case "r", "к":
-		return a, updateData(a.dataService)
+		return a, updateData(a.dataService, a.dashboard.chartView)
+	case "w", "ц":
+		// Переключаем окно истории на графиках и запоминаем выбор в конфиге
+		a.dashboard.chartView = ChartView{Span: nextChartWindow(a.dashboard.chartView.Span)}
+		cfg := getConfig()
+		cfg.Dashboard.ChartWindow = chartWindowConfigValue(a.dashboard.chartView.Span)
+		setConfig(cfg)
+		if err := saveConfig(getConfigPath(), cfg); err != nil {
+			a.lastError = err
+		}
+		return a, updateData(a.dataService, a.dashboard.chartView)
+	case "+", "=":
+		// Приближение: окно вдвое короче
+		a.dashboard.chartView = a.dashboard.chartView.Zoom(0.5)
+		return a, updateData(a.dataService, a.dashboard.chartView)
+	case "-", "_":
+		// Отдаление: окно вдвое длиннее
+		a.dashboard.chartView = a.dashboard.chartView.Zoom(2)
+		return a, updateData(a.dataService, a.dashboard.chartView)
 	case "h", "р":
-		// Показать краткую справку (можно расширить позже)
+		// Сдвиг окна в прошлое
+		a.dashboard.chartView = a.dashboard.chartView.Pan(-1, a.dataService.Now())
+		return a, updateData(a.dataService, a.dashboard.chartView)
+	case "l", "д":
+		// Сдвиг окна к настоящему
+		a.dashboard.chartView = a.dashboard.chartView.Pan(1, a.dataService.Now())
+		return a, updateData(a.dataService, a.dashboard.chartView)
+	case "x", "ч":
+		// Режим перекрестья: стрелки влево/вправо двигают курсор по графикам
+		a.dashboard.cursorOn = !a.dashboard.cursorOn
 		return a, nil
+	case "esc":
+		a.dashboard.cursorOn = false
+		return a, nil
+	case "left":
+		if a.dashboard.cursorOn {
+			a.moveChartCursor(-1)
+		}
+		return a, nil
+	case "right":
+		if a.dashboard.cursorOn {
+			a.moveChartCursor(1)
+		}
+		return a, nil
+	case "shift+left":
+		if a.dashboard.cursorOn {
+			a.moveChartCursor(-5)
+		}
+		return a, nil
+	case "shift+right":
+		if a.dashboard.cursorOn {
+			a.moveChartCursor(5)
+		}
+		return a, nil
+	case "t", "е":
+		// Переключаем правый график: ёмкость → температура → мощность
+		a.dashboard.secondaryChart = nextDashboardChart(a.dashboard.secondaryChart)
+		return a, nil
+	case "c", "с":
+		// Переключаем режим запрета сна: выкл → только калибровка → всегда
+		a.toggleCaffeinate()
+		return a, nil
+	case "d", "в":
+		// Панель состояния сборщика
+		return a, a.openDoctor()
 	case "up", "k", "л":
 		// Скролл вверх
 		if a.dashboardScrollY > 0 {
@@ -3287,6 +4518,20 @@ func (a *App) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // updateReport обрабатывает обновления отчета
 func (a *App) updateReport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.report.activeTab == 3 && a.report.filterInput.Focused() {
+		return a.updateHistoryFilterInput(msg)
+	}
+	if a.report.activeTab == 3 && a.report.historyDetails {
+		// Карточка измерения закрывается раньше выхода из отчета
+		switch msg.String() {
+		case "enter", "esc", "q", "й":
+			a.report.historyDetails = false
+		case "ctrl+c":
+			a.state = StateMenu
+		}
+		return a, nil
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "q", "й":
 		a.state = StateMenu
@@ -3294,11 +4539,7 @@ func (a *App) updateReport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	case "up":
 		if a.report.activeTab == 3 { // В табе История
-			// Навигация по таблице
-			a.reportScrollY--
-			if a.reportScrollY < 0 {
-				a.reportScrollY = 0
-			}
+			a.moveHistoryCursor(-1)
 		} else {
 			if a.reportScrollY > 0 {
 				a.reportScrollY--
@@ -3306,11 +4547,22 @@ func (a *App) updateReport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "down":
 		if a.report.activeTab == 3 { // В табе История
-			// Навигация по таблице
-			a.reportScrollY++
+			a.moveHistoryCursor(1)
 		} else {
 			a.reportScrollY++
 		}
+	case "pgup":
+		if a.report.activeTab == 3 {
+			a.turnHistoryPage(-1)
+		}
+	case "pgdown":
+		if a.report.activeTab == 3 {
+			a.turnHistoryPage(1)
+		}
+	case "enter":
+		if a.report.activeTab == 3 && a.selectedHistoryMeasurement() != nil {
+			a.report.historyDetails = true
+		}
 	case "left", "a", "ф":
 		// Переключение на предыдущую вкладку
 		if a.report.activeTab > 0 {
@@ -3323,14 +4575,14 @@ func (a *App) updateReport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.report.activeTab++
 			a.reportScrollY = 0
 		}
-	case "1", "2", "3", "4", "5":
+	case "1", "2", "3", "4", "5", "6", "7", "8":
 		// Быстрый переход к вкладке
 		tabNum, _ := strconv.Atoi(msg.String())
 		if tabNum > 0 && tabNum <= len(a.report.tabs) {
 			a.report.activeTab = tabNum - 1
 			a.reportScrollY = 0
 		}
-	case "f":
+	case "f", "а":
 		// Переключение фильтра в истории
 		if a.report.activeTab == 3 {
 			switch a.report.filterState {
@@ -3341,16 +4593,51 @@ func (a *App) updateReport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			case "discharging":
 				a.report.filterState = "all"
 			}
+			a.resetHistoryPage()
+		}
+	case "s", "ы":
+		// Следующая колонка сортировки в истории
+		if a.report.activeTab == 3 {
+			a.report.sortColumn = (a.report.sortColumn + 1) % len(historyColumns)
+			a.resetHistoryPage()
 		}
-	case "s":
-		// Переключение сортировки в истории
+	case "S", "Ы":
+		// Направление сортировки в истории
 		if a.report.activeTab == 3 {
 			a.report.sortDesc = !a.report.sortDesc
+			a.resetHistoryPage()
 		}
+	case "/", ".":
+		// Ввод выражения фильтра истории
+		if a.report.activeTab == 3 {
+			a.report.filterInput.SetValue(a.report.filterExpr)
+			a.report.filterInput.CursorEnd()
+			return a, a.report.filterInput.Focus()
+		}
+	case "x", "ч":
+		// Сброс выражения фильтра истории
+		if a.report.activeTab == 3 && a.report.filterExpr != "" {
+			a.report.filterExpr = ""
+			a.report.filter = historyFilter{}
+			a.report.filterInput.SetValue("")
+			a.resetHistoryPage()
+		}
+	case "m", "ь":
+		// Окраска тепловой карты: скорость разряда или время от батареи
+		if a.report.activeTab == 1 {
+			a.report.heatmapByTime = !a.report.heatmapByTime
+		}
+	case "p", "з":
+		// Переключение периода отчета
+		a.report.rangePreset = (a.report.rangePreset + 1) % len(reportRangePresets)
+		a.reportScrollY = 0
+		a.resetHistoryPage()
+		return a, nil
 	case "r", "к":
 		// Обновляем данные отчета
 		a.reportScrollY = 0 // Сбрасываем скролл при обновлении
 		a.report.lastUpdate = time.Now()
+		a.report.cache.Invalidate()
 		return a, nil
 	}
 	
@@ -3360,98 +4647,41 @@ func (a *App) updateReport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
-// updateExport обрабатывает обновления экспорта
-func (a *App) updateExport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q", "й":
-		a.state = StateMenu
-		a.exportStatus = "" // Очищаем статус при выходе
-		return a, nil
-	case "enter":
-		// Генерируем имя файла с текущей датой в Documents
-		documentsDir, err := getDocumentsDir()
-		if err != nil {
-			// Fallback на текущую директорию
-			documentsDir = "."
-		}
-		filename := filepath.Join(documentsDir, fmt.Sprintf("batmon_report_%s.html", time.Now().Format("2006-01-02")))
-		a.exportStatus = "Экспорт в процессе..."
-		a.exportToHTMLAsync(filename)
-		return a, nil
+// openReportDB возвращает БД для отчетов и функцию её освобождения.
+// В интерфейсе это общее соединение сервиса данных (при воспроизведении –
+// БД в памяти); отдельное соединение открывается, только если сервиса нет.
+func (a *App) openReportDB() (*sqlx.DB, func(), error) {
+	if a.dataService != nil && a.dataService.store != nil {
+		return a.dataService.store.DB(), func() {}, nil
 	}
-	return a, nil
-}
-
-// exportToHTMLAsync выполняет экспорт в HTML асинхронно
-func (a *App) exportToHTMLAsync(filename string) {
-	go func() {
-		// Создаем временное соединение с базой данных для экспорта
-		db, err := initDB(getDBPath())
-		if err != nil {
-			a.exportStatus = "Ошибка подключения к БД"
-			return
-		}
-		defer db.Close()
-		
-		// Генерируем данные для отчета
-		data, err := generateReportData(db)
-		if err != nil {
-			a.exportStatus = "Ошибка генерации данных"
-			return
-		}
-		
-		// Экспортируем в HTML
-		err = exportToHTML(data, filename)
-		if err != nil {
-			a.exportStatus = "Ошибка экспорта"
-			return
-		}
-		
-		a.exportStatus = fmt.Sprintf("Успешно экспортировано в %s", filename)
-	}()
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() { db.Close() }, nil
 }
 
 // generateUIReportData генерирует данные для UI отчета
 func (a *App) generateUIReportData() (*ReportData, error) {
 	// Создаем соединение с базой данных как в экспорте
-	db, err := initDB(getDBPath())
+	db, release, err := a.openReportDB()
 	if err != nil {
-		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
+		return nil, fmt.Errorf(T("err.db_connect_failed"), err)
 	}
-	defer db.Close()
+	defer release()
 	
-	data, err := generateReportData(db)
+	data, err := generateReportDataRange(db, reportRangePreset(a.report.rangePreset, time.Now()))
 	if err != nil {
-		return nil, fmt.Errorf("ошибка генерации данных: %w", err)
+		return nil, fmt.Errorf(T("err.generate_failed"), err)
 	}
 	
 	return &data, nil
 }
 
-// updateSettings обрабатывает обновления настроек
-func (a *App) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q", "й", "n", "N", "н", "Н":
-		a.state = StateMenu
-		return a, nil
-	case "y", "Y", "д", "Д":
-		err := a.clearDatabase()
-		if err != nil {
-			a.lastError = fmt.Errorf("ошибка очистки БД: %v", err)
-		} else {
-			a.lastError = nil
-		}
-		a.state = StateMenu
-		return a, nil
-	}
-	return a, nil
-}
-
 // updateWelcome обрабатывает нажатия в экране приветствия
 func (a *App) updateWelcome(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q", "й":
-		a.dataService.Stop()
 		return a, tea.Quit
 	case "enter", " ":
 		a.state = StateMenu
@@ -3508,10 +4738,10 @@ func (a *App) updateComponentSizes() {
 		
 		// Обновляем размеры таблицы измерений с фиксированными колонками
 		columns := []table.Column{
-			{Title: "Время", Width: 5},
-			{Title: "Заряд", Width: 5},
-			{Title: "Состояние", Width: 10},
-			{Title: "Темп.", Width: 5},
+			{Title: T("dashboard.col.time"), Width: 5},
+			{Title: T("dashboard.col.charge"), Width: 5},
+			{Title: T("dashboard.col.state"), Width: 10},
+			{Title: T("dashboard.col.temp"), Width: 5},
 		}
 		
 		a.dashboard.measureTable = table.New(
@@ -3532,14 +4762,7 @@ func (a *App) updateComponentSizes() {
 		tableWidth := a.windowWidth - 10
 		columnWidths := a.calculateReportTableColumnWidths(tableWidth)
 		
-		columns := []table.Column{
-			{Title: "Время", Width: columnWidths[0]},
-			{Title: "Заряд", Width: columnWidths[1]},
-			{Title: "Состояние", Width: columnWidths[2]},
-			{Title: "Циклы", Width: columnWidths[3]},
-			{Title: "Темп.", Width: columnWidths[4]},
-			{Title: "Износ", Width: columnWidths[5]},
-		}
+		columns := historyTableColumns(columnWidths, a.report.sortColumn, a.report.sortDesc)
 		
 		tableHeight := min(20, a.windowHeight-10)
 		a.report.historyTable = table.New(
@@ -3609,8 +4832,14 @@ func (a *App) View() string {
 		return a.renderSettings()
 	case StateHelp:
 		return a.renderHelp()
+	case StateCalibration:
+		return a.renderCalibration()
+	case StateLogs:
+		return a.renderLogs()
+	case StateDoctor:
+		return a.renderDoctor()
 	default:
-		return "Неизвестное состояние приложения"
+		return T("app.unknown_state")
 	}
 }
 
@@ -3657,8 +4886,8 @@ func (a *App) renderDashboard() string {
 		// Добавляем индикатор скролла
 		scrollInfo := ""
 		if a.dashboardScrollY > 0 || end < len(contentLines) {
-			scrollInfo = fmt.Sprintf("   ↕ Скролл: %d/%d (↑↓/kj)", a.dashboardScrollY+1, len(contentLines)-contentHeight+1)
-			scrolledContent += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(scrollInfo)
+			scrollInfo = T("dashboard.scroll", a.dashboardScrollY+1, len(contentLines)-contentHeight+1)
+			scrolledContent += "\n" + lipgloss.NewStyle().Foreground(theme.Border).Render(scrollInfo)
 		}
 		
 		return scrolledContent
@@ -3695,46 +4924,46 @@ func (a *App) calculateMaxDashboardScroll() int {
 // renderLoadingScreen показывает экран загрузки
 func (a *App) renderLoadingScreen() string {
 	title := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("39")).
+		Foreground(theme.Accent).
 		Bold(true).
-		Render("🔋 ПОЛНЫЙ АНАЛИЗ БАТАРЕИ") + "\n\n"
+		Render(T("loading.title")) + "\n\n"
 		
-	loading := "🔄 Собираем данные о батарее...\n\n"
+	loading := T("loading.collecting")
 	
 	instructions := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10")).
+		Foreground(theme.Good).
 		Bold(true).
-		Render("📋 ЧТО НУЖНО ДЕЛАТЬ:") + "\n"
-	instructions += "1. Оставьте программу работать\n"
-	instructions += "2. Используйте MacBook как обычно\n"
-	instructions += "3. Разрядите батарею до 10-0%\n"
-	instructions += "4. После разрядки получите отчет\n\n"
+		Render(T("loading.todo")) + "\n"
+	instructions += T("loading.todo.1")
+	instructions += T("loading.todo.2")
+	instructions += T("loading.todo.3")
+	instructions += T("loading.todo.4")
 	
 	tips := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("11")).
+		Foreground(theme.Warning).
 		Bold(true).
-		Render("💡 СОВЕТЫ:") + "\n"
-	tips += "• Минимум 2-3 часа для качественного анализа\n"
-	tips += "• Не закрывайте программу\n"
-	tips += "• При низком заряде сохраните работу\n\n"
+		Render(T("loading.tips")) + "\n"
+	tips += T("loading.tips.1")
+	tips += T("loading.tips.2")
+	tips += T("loading.tips.3")
 	
 	// Статус caffeinate
 	var caffeineStatus string
 	if a.dataService != nil && a.dataService.caffeineActive {
 		caffeineStatus = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("10")).
-			Render("☕ Предотвращение засыпания активно") + "\n\n"
+			Foreground(theme.Good).
+			Render(T("loading.caffeinate")) + "\n\n"
 	}
 	
 	controls := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
-		Render("Нажмите 'q' для выхода в главное меню")
+		Foreground(theme.Border).
+		Render(T("tui.back_to_menu"))
 	
 	content := title + loading + instructions + tips + caffeineStatus + controls
 	
 	return lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("39")).
+		BorderForeground(theme.Accent).
 		Padding(2).
 		Width(60).
 		Render(content)
@@ -3764,14 +4993,7 @@ func (a *App) renderCompactDashboard() string {
 		}
 	}
 	
-	content := fmt.Sprintf(`🔋 Мониторинг батареи
-
-Заряд: %d%% │ %s
-Состояние: %s
-Циклы: %d │ Износ: %.1f%%
-Температура: %d°C
-
-⌨️  'q'/'й' - выход │ 'r'/'к' - обновить`,
+	content := T("dashboard.compact",
 		a.latest.Percentage,
 		sparklineStr,
 		a.latest.State,
@@ -3789,13 +5011,18 @@ func (a *App) renderCompactDashboard() string {
 
 // renderFullDashboard рендерит полную версию dashboard
 func (a *App) renderFullDashboard(width, height int) string {
-	// Данные для графиков
-	batteryData := make([]float64, 0, len(a.measurements))
-	capacityData := make([]float64, 0, len(a.measurements))
+	// Данные для графиков: измерения за выбранное окно (до первой загрузки – из буфера)
+	chartSource := a.chartData
+	if len(chartSource) == 0 {
+		chartSource = a.measurements
+	}
+	batteryData := make([]float64, 0, len(chartSource))
+	batteryTimes := make([]time.Time, 0, len(chartSource))
+	windowLabel := " · " + a.dashboard.chartView.Label()
 	
-	for _, m := range a.measurements {
+	for _, m := range chartSource {
 		batteryData = append(batteryData, float64(m.Percentage))
-		capacityData = append(capacityData, float64(m.CurrentCapacity))
+		batteryTimes = append(batteryTimes, parseStoredTime(m.Timestamp))
 	}
 	
 	// Адаптивные размеры для графиков
@@ -3819,35 +5046,38 @@ func (a *App) renderFullDashboard(width, height int) string {
 		chartHeight = 30
 	}
 	
-	var batteryChartContent, capacityChartContent string
+	var batteryChartContent, cursorLine, eventsLegend string
 	
 	if len(batteryData) > 0 {
 		batteryChart := NewBatteryChart(chartWidth, chartHeight)
-		batteryChart.SetData(batteryData)
+		batteryChart.Title += windowLabel
+		batteryChart.From, batteryChart.To = a.dashboard.chartView.Range(a.dataService.Now())
+		batteryChart.SetSeries(batteryTimes, batteryData)
+		// Заметки первыми: в общей колонке отметка заметки важнее события питания
+		batteryChart.Events = append(noteChartEvents(a.notes, batteryTimes[0]), chartEvents(a.powerEvents)...)
+		if batteryChart.hasEventRow() {
+			eventsLegend = powerEventsLegend(len(a.notes) > 0)
+		}
+		if col := a.chartCursorColumn(batteryChart); col >= 0 {
+			batteryChart.Cursor = col
+			point := chartSource[batteryChart.PointAt(col)]
+			cursorLine = chartCursorLine(point)
+			if line := notesCursorLine(a.notes, parseStoredTime(point.Timestamp)); line != "" {
+				cursorLine += "\n" + line
+			}
+		}
 		batteryChartContent = batteryChart.Render()
 	} else {
 		emptyStyle := lipgloss.NewStyle().
 			Width(chartWidth).
 			Height(chartHeight).
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")).
+			BorderForeground(theme.Border).
 			Align(lipgloss.Center, lipgloss.Center)
-		batteryChartContent = emptyStyle.Render("📊 График заряда\n\nНет данных для отображения")
+		batteryChartContent = emptyStyle.Render(T("dashboard.chart.empty"))
 	}
 	
-	if len(capacityData) > 0 {
-		capacityChart := NewCapacityChart(chartWidth, chartHeight)  
-		capacityChart.SetData(capacityData)
-		capacityChartContent = capacityChart.Render()
-	} else {
-		emptyStyle := lipgloss.NewStyle().
-			Width(chartWidth).
-			Height(chartHeight).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")).
-			Align(lipgloss.Center, lipgloss.Center)
-		capacityChartContent = emptyStyle.Render("📈 График емкости\n\nНет данных для отображения")
-	}
+	secondaryChartContent := a.renderSecondaryChart(chartSource, chartWidth, chartHeight, windowLabel)
 	
 	// Информационная панель с адаптивными размерами
 	infoPanelWidth := (width - 4) / 2
@@ -3863,7 +5093,7 @@ func (a *App) renderFullDashboard(width, height int) string {
 	topRow := lipgloss.JoinHorizontal(lipgloss.Top,
 		batteryChartContent,
 		" ",
-		capacityChartContent,
+		secondaryChartContent,
 	)
 	
 	bottomRow := lipgloss.JoinHorizontal(lipgloss.Top,
@@ -3872,12 +5102,30 @@ func (a *App) renderFullDashboard(width, height int) string {
 		statsPanel,
 	)
 	
+	rows := []string{topRow, ""}
+	if eventsLegend != "" {
+		rows = append(rows, eventsLegend, "")
+	}
+	if cursorLine != "" {
+		rows = append(rows, cursorLine, "")
+	}
+	if readout := a.renderWattReadout(chartSource, width-4); readout != "" {
+		rows = append(rows, readout, "")
+	}
+	rows = append(rows, bottomRow)
+
+	// Панель мощности SoC в подробном режиме
+	if a.power != nil {
+		rows = append(rows, "", lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(theme.Secondary).
+			Padding(0, 1).
+			Width(width-4).
+			Render(renderPowerPanel(*a.power, *a.latest)))
+	}
+	
 	// Вертикальная компоновка с разделителем
-	return lipgloss.JoinVertical(lipgloss.Left,
-		topRow,
-		"",
-		bottomRow,
-	)
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
 }
 
 // renderInfoPanel рендерит информационную панель
@@ -3906,34 +5154,17 @@ func (a *App) renderInfoPanel(width, height int) string {
 	} else {
 		dataHours = 0
 	}
-	dataQuality := "Недостаточно"
-	dataColor := "9" // красный
+	dataQuality := T("dashboard.quality.poor")
+	dataColor := theme.Critical
 	if dataHours >= 2.0 {
-		dataQuality = "Отлично"
-		dataColor = "10" // зеленый
+		dataQuality = T("dashboard.quality.excellent")
+		dataColor = theme.Good
 	} else if dataHours >= 1.0 {
-		dataQuality = "Хорошо"
-		dataColor = "11" // желтый
+		dataQuality = T("dashboard.quality.good")
+		dataColor = theme.Warning
 	}
 	
-	content := fmt.Sprintf(`🔋 Текущее состояние
-
-⚡ Заряд: %d%%
-%s
-
-📉 Износ: %.1f%%
-%s
-
-🔄 Состояние: %s
-🔁 Циклы: %d
-🌡️  Температура: %d°C
-⚡ Напряжение: %d мВ
-🔌 Ток: %d мА
-
-💚 Здоровье: %s
-
-📊 Качество данных: %s
-⏱️  Собрано: %.1fч (%d точек)`,
+	content := T("dashboard.panel",
 		a.latest.Percentage,
 		batteryBar,
 		wear,
@@ -3944,7 +5175,7 @@ func (a *App) renderInfoPanel(width, height int) string {
 		a.latest.Voltage,
 		a.latest.Amperage,
 		getBatteryHealthStatus(wear, a.latest.CycleCount),
-		lipgloss.NewStyle().Foreground(lipgloss.Color(dataColor)).Render(dataQuality),
+		lipgloss.NewStyle().Foreground(dataColor).Render(dataQuality),
 		dataHours,
 		dataPoints,
 	)
@@ -3968,17 +5199,33 @@ func (a *App) renderStatsPanel(width, height int) string {
 	
 	// Создаем контент с правильным форматированием
 	var contentBuilder strings.Builder
-	contentBuilder.WriteString("Последние измерения\n")
+	contentBuilder.WriteString(T("dashboard.recent"))
 	contentBuilder.WriteString(tableView)
 	contentBuilder.WriteString("\n\n")
-	contentBuilder.WriteString("Управление:\n")
-	contentBuilder.WriteString("  'q'/'й' - выход\n")
-	contentBuilder.WriteString("  'r'/'к' - обновить\n")
-	contentBuilder.WriteString("  ↑↓/jk - скролл")
+	contentBuilder.WriteString(T("dashboard.keys"))
+	contentBuilder.WriteString(T("dashboard.keys.quit"))
+	contentBuilder.WriteString(T("dashboard.keys.refresh"))
+	contentBuilder.WriteString(T("dashboard.keys.window", a.dashboard.chartView.Label()))
+	contentBuilder.WriteString(T("dashboard.keys.zoom"))
+	contentBuilder.WriteString(T("dashboard.keys.crosshair"))
+	contentBuilder.WriteString(T("dashboard.keys.metric"))
+	contentBuilder.WriteString(T("dashboard.keys.caffeinate", getConfig().Collector.Caffeinate.Label()))
+	contentBuilder.WriteString(T("dashboard.keys.doctor"))
+	contentBuilder.WriteString(T("dashboard.keys.scroll"))
+	contentBuilder.WriteString(a.caffeinateStatusLine())
+	if live := a.liveStatusLine(); live != "" {
+		contentBuilder.WriteString("\n" + live)
+	}
+	if line := a.calibrationReminderLine(); line != "" {
+		contentBuilder.WriteString("\n" + line)
+	}
+	if a.collectorStalled() {
+		contentBuilder.WriteString("\n" + lipgloss.NewStyle().Foreground(theme.Warning).Render(T("live.stalled")))
+	}
 	
 	return lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(theme.Border).
 		Padding(1).
 		Width(width-2).
 		Height(height).
@@ -4042,65 +5289,65 @@ func (a *App) updateMeasureTable() {
 func getBatteryColor(percentage int) lipgloss.Color {
 	switch {
 	case percentage >= 50:
-		return lipgloss.Color("46") // Зеленый
+		return theme.Good
 	case percentage >= 20:
-		return lipgloss.Color("226") // Желтый
+		return theme.Warning
 	default:
-		return lipgloss.Color("196") // Красный
+		return theme.Critical
 	}
 }
 
 func getTemperatureColor(temp int) lipgloss.Color {
 	switch {
 	case temp <= 30:
-		return lipgloss.Color("46") // Зеленый
+		return theme.Good
 	case temp <= 40:
-		return lipgloss.Color("226") // Желтый  
+		return theme.Warning
 	default:
-		return lipgloss.Color("196") // Красный
+		return theme.Critical
 	}
 }
 
 func getWearColor(wear float64) lipgloss.Color {
 	switch {
 	case wear < 10:
-		return lipgloss.Color("46") // Зеленый
+		return theme.Good
 	case wear < 20:
-		return lipgloss.Color("226") // Желтый
+		return theme.Warning
 	default:
-		return lipgloss.Color("196") // Красный
+		return theme.Critical
 	}
 }
 
 func getCycleColor(cycles int) lipgloss.Color {
 	switch {
 	case cycles < 300:
-		return lipgloss.Color("46") // Зеленый
+		return theme.Good
 	case cycles < 1000:
-		return lipgloss.Color("226") // Желтый
+		return theme.Warning
 	default:
-		return lipgloss.Color("196") // Красный
+		return theme.Critical
 	}
 }
 
-func getBatteryHealthColor(wear float64, cycles int) string {
+func getBatteryHealthColor(wear float64, cycles int) lipgloss.Color {
 	if wear < 20 && cycles < 1000 {
-		return "10" // Зеленый
+		return theme.Good
 	} else if wear < 30 && cycles < 1500 {
-		return "11" // Желтый
+		return theme.Warning
 	} else {
-		return "9" // Красный
+		return theme.Critical
 	}
 }
 
 func formatBatteryState(state string) string {
 	switch state {
 	case "charging":
-		return "🔌 Зарядка"
+		return "🔌 " + T("state.charging")
 	case "discharging":
-		return "🔋 Разрядка"
+		return "🔋 " + T("state.discharging")
 	case "charged":
-		return "✅ Заряжена"
+		return "✅ " + T("state.charged")
 	default:
 		return state
 	}
@@ -4109,22 +5356,22 @@ func formatBatteryState(state string) string {
 func getBatteryHealthStatus(wear float64, cycles int) string {
 	switch {
 	case wear < 5 && cycles < 300:
-		return "Отличное"
+		return T("health.excellent")
 	case wear < 10 && cycles < 500:
-		return "Хорошее"  
+		return T("health.good")  
 	case wear < 20 && cycles < 800:
-		return "Удовлетворительное"
+		return T("health.fair")
 	default:
-		return "Требует внимания"
+		return T("health.attention")
 	}
 }
 
 // renderReport рендерит детальный отчет с полной аналитикой
 func (a *App) renderReport() string {
-	// Получаем полные данные аналитики
-	reportData, err := a.generateUIReportData()
+	// Получаем полные данные аналитики (из кэша, если не было новых измерений)
+	reportData, err := a.cachedReportData()
 	if err != nil {
-		return fmt.Sprintf("❌ Ошибка загрузки отчета: %v\nНажмите 'q' для выхода в меню", err)
+		return T("tui.report.failed", err)
 	}
 
 	// Создаем контент в зависимости от активной вкладки
@@ -4140,6 +5387,12 @@ func (a *App) renderReport() string {
 		tabContent = a.renderReportHistory(reportData)
 	case 4: // Прогнозы
 		tabContent = a.renderReportPredictions(reportData)
+	case 5: // Сессии
+		tabContent = a.renderReportSessions(reportData)
+	case 6: // Зарядка
+		tabContent = a.renderReportCharging(reportData)
+	case 7: // Метрики
+		tabContent = a.renderReportMetrics(reportData)
 	default:
 		tabContent = a.renderReportOverview(reportData)
 	}
@@ -4201,7 +5454,7 @@ func (a *App) applyReportScroll(content string, maxHeight int) string {
 	// Добавляем индикатор скролла
 	if start > 0 || end < len(contentLines) {
 		scrollInfo := fmt.Sprintf("   ↕ %d/%d", start+1, len(contentLines)-maxHeight+1)
-		scrolledContent += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(scrollInfo)
+		scrolledContent += "\n" + lipgloss.NewStyle().Foreground(theme.Border).Render(scrollInfo)
 	}
 	
 	return scrolledContent
@@ -4212,77 +5465,85 @@ func (a *App) buildReportContent(data *ReportData) string {
 	var content strings.Builder
 	
 	// Заголовок
-	content.WriteString("📊 Детальный отчет о состоянии батареи\n")
+	content.WriteString(T("tui.report.title"))
 	content.WriteString(strings.Repeat("═", 50) + "\n\n")
 	
 	// 1. Заголовочная панель с ключевыми метриками
-	content.WriteString("🔋 ОБЩЕЕ СОСТОЯНИЕ\n")
+	content.WriteString(T("tui.report.overall"))
 	content.WriteString("┌─────────────────────────────────────────────────┐\n")
 	
 	healthStatus := getBatteryHealthStatus(data.Wear, data.Latest.CycleCount)
 	healthEmoji := getHealthEmoji(data.Wear)
-	content.WriteString(fmt.Sprintf("│ Состояние: %s %s\n", healthEmoji, healthStatus))
+	content.WriteString(T("tui.report.health", healthEmoji, healthStatus))
 	
 	// Рейтинг здоровья с прогресс-баром
-	if healthAnalysis, ok := data.HealthAnalysis["health_score"].(float64); ok {
-		healthScore := int(healthAnalysis)
+	if data.HealthAnalysis != nil {
+		healthScore := data.HealthAnalysis.HealthScore
 		progressBar := createProgressBar(healthScore, 100, 20)
-		content.WriteString(fmt.Sprintf("│ Рейтинг:   %s %d/100\n", progressBar, healthScore))
+		content.WriteString(T("tui.report.rating", progressBar, healthScore))
+		content.WriteString(T("tui.report.model", data.HealthAnalysis.ScoreModel.Label()))
+		content.WriteString(renderScoreBreakdown(data.HealthAnalysis.ScoreBreakdown, "│          "))
 	}
 	
-	content.WriteString(fmt.Sprintf("│ Износ:     %.1f%%\n", data.Wear))
-	content.WriteString(fmt.Sprintf("│ Циклы:     %d\n", data.Latest.CycleCount))
+	content.WriteString(T("tui.report.wear", data.Wear))
+	content.WriteString(T("tui.report.cycles", data.Latest.CycleCount))
+	for _, r := range data.Replacements {
+		content.WriteString(fmt.Sprintf("│ %s\n", r.Marker()))
+	}
 	content.WriteString("└─────────────────────────────────────────────────┘\n\n")
 	
 	// 2. Текущее состояние
-	content.WriteString("⚡ ТЕКУЩЕЕ СОСТОЯНИЕ\n")
+	content.WriteString(T("tui.report.current"))
 	content.WriteString("┌─────────────────────────────────────────────────┐\n")
 	
 	// Заряд с прогресс-баром
 	chargeBar := createProgressBar(data.Latest.Percentage, 100, 25)
-	content.WriteString(fmt.Sprintf("│ Заряд:     %s %d%%\n", chargeBar, data.Latest.Percentage))
+	content.WriteString(T("tui.report.charge", chargeBar, data.Latest.Percentage))
 	
 	stateEmoji := getStateEmoji(data.Latest.State)
-	content.WriteString(fmt.Sprintf("│ Статус:    %s %s\n", stateEmoji, formatBatteryState(data.Latest.State)))
+	content.WriteString(T("tui.report.state", stateEmoji, formatBatteryState(data.Latest.State)))
 	
 	// Прогнозируемое время
 	if data.RemainingTime > 0 {
-		content.WriteString(fmt.Sprintf("│ Осталось:  %s\n", formatDuration(data.RemainingTime)))
+		content.WriteString(T("tui.report.remaining", data.Remaining))
 	}
 	
 	tempEmoji := getTempEmoji(data.Latest.Temperature)
-	content.WriteString(fmt.Sprintf("│ Темп-ра:   %s %d°C\n", tempEmoji, data.Latest.Temperature))
+	content.WriteString(T("tui.report.temp", tempEmoji, data.Latest.Temperature))
 	content.WriteString("└─────────────────────────────────────────────────┘\n\n")
 	
 	// 3. Анализ производительности
-	content.WriteString("📈 АНАЛИЗ ПРОИЗВОДИТЕЛЬНОСТИ\n")
+	content.WriteString(T("tui.report.performance"))
 	content.WriteString("┌─────────────────────────────────────────────────┐\n")
-	content.WriteString(fmt.Sprintf("│ Скорость разряда:   %.1f мА/ч\n", data.RobustRate))
+	if data.DischargePower > 0 {
+		content.WriteString(T("tui.report.power", data.DischargePower))
+	}
+	content.WriteString(T("tui.report.rate", data.RobustRate))
 	if data.Latest.Power != 0 {
-		content.WriteString(fmt.Sprintf("│ Потребление:        %d мВт\n", abs(data.Latest.Power)))
+		content.WriteString(T("tui.report.consumption", abs(data.Latest.Power)))
 	}
 	if data.Latest.Voltage != 0 {
-		content.WriteString(fmt.Sprintf("│ Напряжение:         %.2f В\n", float64(data.Latest.Voltage)/1000))
+		content.WriteString(T("tui.report.voltage", float64(data.Latest.Voltage)/1000))
 	}
-	content.WriteString(fmt.Sprintf("│ Валидных интервалов: %d\n", data.ValidIntervals))
+	content.WriteString(T("tui.report.intervals", data.ValidIntervals))
 	content.WriteString("└─────────────────────────────────────────────────┘\n\n")
 	
 	// 4. Здоровье батареи
-	content.WriteString("💊 ЗДОРОВЬЕ БАТАРЕИ\n")
+	content.WriteString(T("tui.report.battery"))
 	content.WriteString("┌─────────────────────────────────────────────────┐\n")
-	content.WriteString(fmt.Sprintf("│ Текущая емкость:    %d мАч\n", data.Latest.CurrentCapacity))
-	content.WriteString(fmt.Sprintf("│ Полная емкость:     %d мАч\n", data.Latest.FullChargeCap))
-	content.WriteString(fmt.Sprintf("│ Проектная емкость:  %d мАч\n", data.Latest.DesignCapacity))
+	content.WriteString(T("tui.report.current_cap", data.Capacity(data.Latest.CurrentCapacity)))
+	content.WriteString(T("tui.report.full_cap", data.Capacity(data.Latest.FullChargeCap)))
+	content.WriteString(T("tui.report.design_cap", data.Capacity(data.Latest.DesignCapacity)))
 	
 	if data.Latest.AppleCondition != "" {
-		content.WriteString(fmt.Sprintf("│ Статус Apple:       %s\n", data.Latest.AppleCondition))
+		content.WriteString(T("tui.report.apple", data.Latest.AppleCondition))
 	}
 	
 	content.WriteString("└─────────────────────────────────────────────────┘\n\n")
 	
 	// 5. Обнаруженные проблемы и рекомендации
 	if len(data.Anomalies) > 0 {
-		content.WriteString("⚠️  ОБНАРУЖЕННЫЕ ПРОБЛЕМЫ\n")
+		content.WriteString(T("tui.report.problems"))
 		content.WriteString("┌─────────────────────────────────────────────────┐\n")
 		for _, anomaly := range data.Anomalies {
 			content.WriteString(fmt.Sprintf("│ • %s\n", anomaly))
@@ -4291,7 +5552,7 @@ func (a *App) buildReportContent(data *ReportData) string {
 	}
 	
 	if len(data.Recommendations) > 0 {
-		content.WriteString("💡 РЕКОМЕНДАЦИИ\n")
+		content.WriteString(T("tui.report.recommendations"))
 		content.WriteString("┌─────────────────────────────────────────────────┐\n")
 		for _, rec := range data.Recommendations {
 			content.WriteString(fmt.Sprintf("│ • %s\n", rec))
@@ -4299,10 +5560,19 @@ func (a *App) buildReportContent(data *ReportData) string {
 		content.WriteString("└─────────────────────────────────────────────────┘\n\n")
 	}
 	
+	if len(data.TopApps) > 0 {
+		content.WriteString(T("tui.report.top_apps", strings.ToUpper(appsPeriodLabel(data.Range))))
+		content.WriteString("┌─────────────────────────────────────────────────┐\n")
+		for i, app := range data.TopApps {
+			content.WriteString(T("tui.report.top_app", i+1, truncateString(app.App, 22), app.MAh, app.Wh))
+		}
+		content.WriteString("└─────────────────────────────────────────────────┘\n\n")
+	}
+
 	// 6. История измерений (компактная)
-	content.WriteString("📋 ПОСЛЕДНИЕ ИЗМЕРЕНИЯ\n")
+	content.WriteString(T("tui.report.recent"))
 	content.WriteString("┌──────────┬─────────┬─────────────────┬──────────┐\n")
-	content.WriteString("│   Время  │ Заряд % │    Состояние    │ Темп °C  │\n")
+	content.WriteString(T("tui.report.recent.header"))
 	content.WriteString("├──────────┼─────────┼─────────────────┼──────────┤\n")
 	
 	recentCount := 10
@@ -4391,27 +5661,39 @@ func createProgressBar(current, max, width int) string {
 func formatBatteryStateShort(state string) string {
 	switch state {
 	case "charging":
-		return "Зарядка"
+		return T("state.charging")
 	case "discharging":
-		return "Разрядка"
+		return T("state.discharging")
 	case "charged":
-		return "Заряжена"
+		return T("state.charged")
 	case "AC":
-		return "От сети"
+		return T("state.ac")
 	default:
 		return state
 	}
 }
 
+// truncateString обрезает строку до n символов (рун), добавляя многоточие
+func truncateString(str string, n int) string {
+	runes := []rune(str)
+	if len(runes) <= n {
+		return str
+	}
+	if n <= 1 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-1]) + "…"
+}
+
 // formatDuration форматирует время в читаемый вид
 func formatDuration(d time.Duration) string {
 	hours := int(d.Hours())
 	minutes := int(d.Minutes()) % 60
 	
 	if hours > 0 {
-		return fmt.Sprintf("%d ч %d мин", hours, minutes)
+		return T("fmt.hours_minutes", hours, minutes)
 	}
-	return fmt.Sprintf("%d мин", minutes)
+	return T("fmt.minutes", minutes)
 }
 
 // renderTabBar рендерит компактную панель вкладок
@@ -4419,7 +5701,7 @@ func (a *App) renderTabBar() string {
 	var tabs []string
 	
 	// Компактные названия вкладок
-	compactTabs := []string{"Обзор", "Графики", "Аномалии", "История", "Прогноз"}
+	compactTabs := []string{T("tab.overview"), T("tab.charts"), T("tab.anomalies"), T("tab.history"), T("tab.forecast"), T("tab.sessions"), T("tab.charging"), T("tab.metrics")}
 	
 	for i, tab := range compactTabs {
 		if i >= len(a.report.tabs) {
@@ -4433,12 +5715,12 @@ func (a *App) renderTabBar() string {
 			// Активная вкладка
 			style = style.
 				Background(a.getTabColor()).
-				Foreground(lipgloss.Color("230")).
+				Foreground(theme.OnAccent).
 				Bold(true)
 		} else {
 			// Неактивная вкладка
 			style = style.
-				Foreground(lipgloss.Color("241"))
+				Foreground(theme.Muted)
 		}
 		
 		// Компактный формат
@@ -4447,48 +5729,55 @@ func (a *App) renderTabBar() string {
 	}
 	
 	// Разделители между вкладками
-	separator := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("│")
+	separator := lipgloss.NewStyle().Foreground(theme.Border).Render("│")
 	return strings.Join(tabs, separator)
 }
 
 // getTabColor возвращает цвет для активной вкладки
 func (a *App) getTabColor() lipgloss.Color {
 	colors := []lipgloss.Color{
-		lipgloss.Color("62"),  // Обзор - синий
-		lipgloss.Color("214"), // Графики - оранжевый
-		lipgloss.Color("196"), // Аномалии - красный
-		lipgloss.Color("82"),  // История - зеленый
-		lipgloss.Color("99"),  // Прогнозы - фиолетовый
+		theme.Accent,    // Обзор
+		theme.Caution,   // Графики
+		theme.Critical,  // Аномалии
+		theme.Good,      // История
+		theme.Secondary, // Прогнозы
 	}
 	
 	if a.report.activeTab < len(colors) {
 		return colors[a.report.activeTab]
 	}
-	return lipgloss.Color("240")
+	return theme.Border
 }
 
 // renderReportHelpBar рендерит компактную панель помощи
 func (a *App) renderReportHelpBar() string {
 	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
+		Foreground(theme.Muted).
 		Padding(0, 1)
 	
 	// Базовые команды
 	help := []string{
 		"←→",  // Переключение вкладок
-		"1-5", // Быстрый переход
+		"1-8", // Быстрый переход
 		"↑↓",  // Скролл
 		"r",   // Обновить
+		"p " + T(reportRangePresets[a.report.rangePreset].label), // Период
 		"q",   // Выход
 	}
 	
 	// Специфичные для вкладки команды
 	if a.report.activeTab == 3 { // История
-		help = append([]string{"f", "s"}, help...)
+		help = append([]string{T("tui.help.filter"), "f", "s/S", "PgUp/PgDn", "Enter"}, help...)
+	}
+	if a.report.activeTab == 1 { // Графики
+		help = append([]string{T("tui.help.map")}, help...)
+	}
+	if status := a.reportCacheStatus(); status != "" {
+		help = append(help, status)
 	}
 	
 	// Компактное отображение с минимальными разделителями
-	separator := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("·")
+	separator := lipgloss.NewStyle().Foreground(theme.Border).Render("·")
 	return helpStyle.Render(strings.Join(help, separator))
 }
 
@@ -4513,12 +5802,12 @@ func (a *App) createOverviewWidgets(data *ReportData) []ReportWidget {
 	
 	// Виджет здоровья батареи
 	healthScore := 70.0
-	if score, ok := data.HealthAnalysis["health_score"].(float64); ok {
-		healthScore = score
+	if data.HealthAnalysis != nil {
+		healthScore = float64(data.HealthAnalysis.HealthScore)
 	}
 	
 	widgets = append(widgets, ReportWidget{
-		title:      "💚 Здоровье батареи",
+		title:      T("widget.health"),
 		widgetType: "gauge",
 		value:      healthScore,
 		maxValue:   100,
@@ -4528,7 +5817,7 @@ func (a *App) createOverviewWidgets(data *ReportData) []ReportWidget {
 	
 	// Виджет текущего заряда
 	widgets = append(widgets, ReportWidget{
-		title:      "🔋 Текущий заряд",
+		title:      T("widget.charge"),
 		widgetType: "gauge",
 		value:      float64(data.Latest.Percentage),
 		maxValue:   100,
@@ -4538,7 +5827,7 @@ func (a *App) createOverviewWidgets(data *ReportData) []ReportWidget {
 	
 	// Виджет износа
 	widgets = append(widgets, ReportWidget{
-		title:      "⚙️ Износ батареи",
+		title:      T("widget.wear"),
 		widgetType: "gauge",
 		value:      data.Wear,
 		maxValue:   30, // Максимально допустимый износ
@@ -4546,10 +5835,22 @@ func (a *App) createOverviewWidgets(data *ReportData) []ReportWidget {
 		icon:       "📉",
 	})
 	
+	// Виджет изменения ёмкости с момента установки batmon
+	if data.Baseline != nil {
+		delta, pct := data.Baseline.CapacityDelta(data.Latest)
+		widgets = append(widgets, ReportWidget{
+			title:      T("widget.baseline"),
+			widgetType: "info",
+			content:    T("widget.baseline.value", signedInt(delta), signedFloat(pct)),
+			color:      a.getWearColor(-pct),
+			icon:       "📌",
+		})
+	}
+
 	// Виджет циклов
 	cyclePercent := float64(data.Latest.CycleCount) / 1000.0 * 100
 	widgets = append(widgets, ReportWidget{
-		title:      "🔄 Циклы зарядки",
+		title:      T("widget.cycles"),
 		widgetType: "info",
 		content:    fmt.Sprintf("%d / 1000", data.Latest.CycleCount),
 		value:      cyclePercent,
@@ -4558,23 +5859,58 @@ func (a *App) createOverviewWidgets(data *ReportData) []ReportWidget {
 		icon:       "♻️",
 	})
 	
+	// Виджет полной ёмкости: мАч и Вт·ч
+	widgets = append(widgets, ReportWidget{
+		title:      T("widget.full_cap"),
+		widgetType: "info",
+		content:    data.Capacity(data.Latest.FullChargeCap),
+		color:      a.getWearColor(data.Wear),
+		icon:       "🔋",
+	})
+	
+	// Виджет мощности разряда по энергии
+	if data.DischargePower > 0 {
+		widgets = append(widgets, ReportWidget{
+			title:      T("widget.power"),
+			widgetType: "info",
+			content:    T("widget.power.value", data.DischargePower),
+			color:      theme.Info,
+			icon:       "⚡",
+		})
+	}
+	
 	// Виджет времени работы
 	if data.RemainingTime > 0 {
 		widgets = append(widgets, ReportWidget{
-			title:      "⏱️ Осталось времени",
+			title:      T("widget.remaining"),
 			widgetType: "info",
-			content:    formatDuration(data.RemainingTime),
-			color:      lipgloss.Color("82"),
+			content:    data.Remaining.String(),
+			color:      theme.Good,
 			icon:       "⏰",
 		})
 	}
 	
+	// Виджет времени на 100% от сети
+	if data.FullChargeTime > 0 {
+		color := theme.Good
+		if data.FullChargeShare >= fullZoneAdviceShare {
+			color = theme.Caution
+		}
+		widgets = append(widgets, ReportWidget{
+			title:      T("widget.full_zone"),
+			widgetType: "info",
+			content:    fmt.Sprintf("%s (%.0f%%)", formatDuration(data.FullChargeTime), data.FullChargeShare),
+			color:      color,
+			icon:       "🔌",
+		})
+	}
+
 	// Виджет температуры
 	widgets = append(widgets, ReportWidget{
-		title:      "🌡️ Температура",
+		title:      T("widget.temperature"),
 		widgetType: "info",
 		content:    fmt.Sprintf("%d°C", data.Latest.Temperature),
-		color:      a.getTempColor(data.Latest.Temperature),
+		color:      a.getThermalColor(data.Latest),
 		icon:       getTempEmoji(data.Latest.Temperature),
 	})
 	
@@ -4701,7 +6037,7 @@ func (a *App) renderCompactWidget(widget ReportWidget, width, height int) string
 		// Компактное предупреждение
 		alertStyle := lipgloss.NewStyle().
 			Foreground(widget.color).
-			Background(lipgloss.Color("52")).
+			Background(theme.AlertBg).
 			Padding(0, 1)
 		
 		alertText := widget.content
@@ -4741,11 +6077,11 @@ func (a *App) renderCompactProgressBar(value, maxValue float64, width int) strin
 	// Цветовая градация
 	barStyle := lipgloss.NewStyle()
 	if percentage > 0.7 {
-		barStyle = barStyle.Foreground(lipgloss.Color("46")) // Зеленый
+		barStyle = barStyle.Foreground(theme.Good)
 	} else if percentage > 0.4 {
-		barStyle = barStyle.Foreground(lipgloss.Color("226")) // Желтый
+		barStyle = barStyle.Foreground(theme.Warning)
 	} else {
-		barStyle = barStyle.Foreground(lipgloss.Color("196")) // Красный
+		barStyle = barStyle.Foreground(theme.Critical)
 	}
 	
 	return barStyle.Render(bar)
@@ -4832,7 +6168,7 @@ func (a *App) renderWidget(widget ReportWidget, width int) string {
 		// Предупреждение с адаптивным размером
 		alertStyle := lipgloss.NewStyle().
 			Foreground(widget.color).
-			Background(lipgloss.Color("52")).
+			Background(theme.AlertBg).
 			Padding(0, min(1, contentWidth/20)). // Адаптивные отступы
 			MaxWidth(contentWidth)
 		content.WriteString(alertStyle.Render(widget.content))
@@ -4869,11 +6205,11 @@ func (a *App) renderAnimatedProgressBar(value, maxValue float64, width int) stri
 	// Добавляем цветовую градацию
 	barStyle := lipgloss.NewStyle()
 	if percentage > 0.7 {
-		barStyle = barStyle.Foreground(lipgloss.Color("82"))
+		barStyle = barStyle.Foreground(theme.Good)
 	} else if percentage > 0.4 {
-		barStyle = barStyle.Foreground(lipgloss.Color("226"))
+		barStyle = barStyle.Foreground(theme.Warning)
 	} else {
-		barStyle = barStyle.Foreground(lipgloss.Color("196"))
+		barStyle = barStyle.Foreground(theme.Critical)
 	}
 	
 	return fmt.Sprintf("[%s]", barStyle.Render(bar))
@@ -4882,13 +6218,13 @@ func (a *App) renderAnimatedProgressBar(value, maxValue float64, width int) stri
 // Вспомогательные функции для определения цветов
 func (a *App) getHealthColor(score float64) lipgloss.Color {
 	if score >= 80 {
-		return lipgloss.Color("82")
+		return theme.Good
 	} else if score >= 60 {
-		return lipgloss.Color("226")
+		return theme.Warning
 	} else if score >= 40 {
-		return lipgloss.Color("214")
+		return theme.Caution
 	}
-	return lipgloss.Color("196")
+	return theme.Critical
 }
 
 func (a *App) getHealthIcon(score float64) string {
@@ -4904,55 +6240,87 @@ func (a *App) getHealthIcon(score float64) string {
 
 func (a *App) getWearColor(wear float64) lipgloss.Color {
 	if wear < 10 {
-		return lipgloss.Color("82")
+		return theme.Good
 	} else if wear < 20 {
-		return lipgloss.Color("226")
+		return theme.Warning
 	}
-	return lipgloss.Color("196")
+	return theme.Critical
 }
 
 func (a *App) getCycleColor(cycles int) lipgloss.Color {
 	if cycles < 300 {
-		return lipgloss.Color("82")
+		return theme.Good
 	} else if cycles < 600 {
-		return lipgloss.Color("226")
+		return theme.Warning
 	} else if cycles < 900 {
-		return lipgloss.Color("214")
+		return theme.Caution
 	}
-	return lipgloss.Color("196")
+	return theme.Critical
 }
 
 func (a *App) getTempColor(temp int) lipgloss.Color {
 	if temp < 30 {
-		return lipgloss.Color("82")
+		return theme.Good
 	} else if temp < 40 {
-		return lipgloss.Color("226")
+		return theme.Warning
 	} else if temp < 50 {
-		return lipgloss.Color("214")
+		return theme.Caution
+	}
+	return theme.Critical
+}
+
+// getThermalColor возвращает цвет температуры с учетом порогов на зарядке
+func (a *App) getThermalColor(m Measurement) lipgloss.Color {
+	switch thermalLevel(m) {
+	case thermalAlarm:
+		return theme.Critical
+	case thermalWarning:
+		return theme.Caution
 	}
-	return lipgloss.Color("196")
+	return a.getTempColor(m.Temperature)
 }
 
 // renderReportCharts рендерит вкладку с графиками
 func (a *App) renderReportCharts(data *ReportData) string {
 	var content strings.Builder
 	
-	content.WriteString("📈 Графики производительности батареи\n")
+	content.WriteString(T("tui.charts.title"))
 	content.WriteString(strings.Repeat("─", 50) + "\n\n")
 	
 	// График заряда за последние измерения
-	content.WriteString("🔋 История заряда (последние 24 часа)\n")
+	content.WriteString(T("tui.charts.charge"))
 	content.WriteString(a.renderChargeChart(data.Measurements))
+	if marker := replacementChartMarker(lastMeasurements(data.Measurements, 20), data.Replacements, 50); marker != "" {
+		content.WriteString("\n" + marker)
+	}
+	if markers := noteChartMarkers(lastMeasurements(data.Measurements, 20), data.Notes, 50); markers != "" {
+		content.WriteString("\n" + markers)
+	}
 	content.WriteString("\n\n")
 	
 	// График скорости разряда
-	content.WriteString("⚡ Скорость разряда\n")
+	content.WriteString(T("tui.charts.rate"))
 	content.WriteString(a.renderDischargeRateChart(data.Measurements))
 	content.WriteString("\n\n")
 	
 	// График температуры
-	content.WriteString("🌡️ Температурный профиль\n")
+	content.WriteString(T("tui.charts.temperature"))
 	content.WriteString(a.renderTemperatureChart(data.Measurements))
+
+	// Тепловая карта использования по дням и часам
+	content.WriteString("\n\n" + T("report.heatmap") + "\n")
+	content.WriteString(renderUsageHeatmap(data.Heatmap, a.report.heatmapByTime))
+
+	// Пользовательские метрики из config.json
+	for _, d := range data.DerivedMetrics {
+		if len(d.Values) == 0 {
+			continue
+		}
+		chart := NewChart("📐 "+d.Label(), 50, 8)
+		chart.Color = theme.Secondary
+		chart.SetData(d.Values)
+		content.WriteString("\n\n" + chart.Render())
+	}
 	
 	return content.String()
 }
@@ -4960,7 +6328,7 @@ func (a *App) renderReportCharts(data *ReportData) string {
 // renderChargeChart рендерит ASCII график заряда
 func (a *App) renderChargeChart(measurements []Measurement) string {
 	if len(measurements) == 0 {
-		return "Нет данных для отображения"
+		return T("tui.charts.no_data")
 	}
 	
 	// Берем последние 20 измерений для графика
@@ -5076,7 +6444,7 @@ func (a *App) renderChargeChart(measurements []Measurement) string {
 func (a *App) renderDischargeRateChart(measurements []Measurement) string {
 	// Упрощенная версия sparkline графика
 	if len(measurements) < 2 {
-		return "Недостаточно данных"
+		return T("tui.charts.not_enough")
 	}
 	
 	sparkline := "▁▂▃▄▅▆▇█"
@@ -5093,7 +6461,7 @@ func (a *App) renderDischargeRateChart(measurements []Measurement) string {
 	}
 	
 	if len(rates) == 0 {
-		return "Нет данных о разряде"
+		return T("tui.charts.no_discharge")
 	}
 	
 	// Находим min и max
@@ -5119,7 +6487,7 @@ func (a *App) renderDischargeRateChart(measurements []Measurement) string {
 		result.WriteString(string(sparkline[idx]))
 	}
 	
-	result.WriteString(fmt.Sprintf("\nМин: %.1f%%/ч  Макс: %.1f%%/ч", minRate, maxRate))
+	result.WriteString(T("tui.charts.rate_range", minRate, maxRate))
 	
 	return result.String()
 }
@@ -5127,7 +6495,7 @@ func (a *App) renderDischargeRateChart(measurements []Measurement) string {
 // renderTemperatureChart рендерит тепловую карту температуры
 func (a *App) renderTemperatureChart(measurements []Measurement) string {
 	if len(measurements) == 0 {
-		return "Нет данных"
+		return T("tui.charts.no_temperature")
 	}
 	
 	// Берем последние измерения
@@ -5144,13 +6512,13 @@ func (a *App) renderTemperatureChart(measurements []Measurement) string {
 		style := lipgloss.NewStyle()
 		
 		if m.Temperature < 25 {
-			style = style.Foreground(lipgloss.Color("51")) // Холодный - голубой
+			style = style.Foreground(theme.Info) // Холодный
 		} else if m.Temperature < 35 {
-			style = style.Foreground(lipgloss.Color("82")) // Нормальный - зеленый
+			style = style.Foreground(theme.Good) // Нормальный
 		} else if m.Temperature < 45 {
-			style = style.Foreground(lipgloss.Color("226")) // Теплый - желтый
+			style = style.Foreground(theme.Warning) // Теплый
 		} else {
-			style = style.Foreground(lipgloss.Color("196")) // Горячий - красный
+			style = style.Foreground(theme.Critical) // Горячий
 		}
 		
 		result.WriteString(style.Render(tempChar))
@@ -5169,37 +6537,28 @@ func (a *App) renderTemperatureChart(measurements []Measurement) string {
 func (a *App) renderReportAnomalies(data *ReportData) string {
 	var content strings.Builder
 	
-	content.WriteString("⚠️ Анализ аномалий и проблем\n")
+	content.WriteString(T("tui.anomalies.title"))
 	content.WriteString(strings.Repeat("─", 50) + "\n\n")
 	
 	if len(data.Anomalies) == 0 {
 		successStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("82")).
+			Foreground(theme.Good).
 			Bold(true)
-		content.WriteString(successStyle.Render("✅ Аномалий не обнаружено!\n\n"))
-		content.WriteString("Батарея работает в штатном режиме.\n")
+		content.WriteString(successStyle.Render(T("tui.anomalies.none")))
+		content.WriteString(T("tui.anomalies.normal"))
 	} else {
 		// Группируем аномалии по критичности
-		critical := []string{}
-		warning := []string{}
-		info := []string{}
-		
-		for _, anomaly := range data.Anomalies {
-			if strings.Contains(anomaly, "критич") || strings.Contains(anomaly, "опасн") {
-				critical = append(critical, anomaly)
-			} else if strings.Contains(anomaly, "вниман") || strings.Contains(anomaly, "высок") {
-				warning = append(warning, anomaly)
-			} else {
-				info = append(info, anomaly)
-			}
-		}
+		groups := groupAnomalies(data.Anomalies)
+		critical := groups[alertCritical]
+		warning := groups[alertWarning]
+		info := groups[alertInfo]
 		
 		// Критические проблемы
 		if len(critical) > 0 {
 			criticalStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("196")).
+				Foreground(theme.Critical).
 				Bold(true)
-			content.WriteString(criticalStyle.Render("🚨 Критические проблемы:\n"))
+			content.WriteString(criticalStyle.Render(T("tui.anomalies.critical")))
 			for _, item := range critical {
 				content.WriteString(fmt.Sprintf("  • %s\n", item))
 			}
@@ -5209,9 +6568,9 @@ func (a *App) renderReportAnomalies(data *ReportData) string {
 		// Предупреждения
 		if len(warning) > 0 {
 			warningStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("214")).
+				Foreground(theme.Caution).
 				Bold(true)
-			content.WriteString(warningStyle.Render("⚡ Требуют внимания:\n"))
+			content.WriteString(warningStyle.Render(T("tui.anomalies.warning")))
 			for _, item := range warning {
 				content.WriteString(fmt.Sprintf("  • %s\n", item))
 			}
@@ -5221,8 +6580,8 @@ func (a *App) renderReportAnomalies(data *ReportData) string {
 		// Информационные
 		if len(info) > 0 {
 			infoStyle := lipgloss.NewStyle().
-				Foreground(lipgloss.Color("226"))
-			content.WriteString(infoStyle.Render("ℹ️ Информация:\n"))
+				Foreground(theme.Warning)
+			content.WriteString(infoStyle.Render(T("tui.anomalies.info")))
 			for _, item := range info {
 				content.WriteString(fmt.Sprintf("  • %s\n", item))
 			}
@@ -5230,9 +6589,36 @@ func (a *App) renderReportAnomalies(data *ReportData) string {
 		}
 	}
 	
+	// Периоды длительного перегрева
+	if len(data.ThermalEvents) > 0 {
+		content.WriteString(T("tui.anomalies.thermal", thermalEventTemp, formatDuration(thermalEventMinDuration)))
+		content.WriteString(strings.Repeat("─", 40) + "\n")
+		for _, e := range data.ThermalEvents {
+			charging := ""
+			if e.Charging {
+				charging = T("tui.anomalies.thermal.charging")
+			}
+			content.WriteString(T("tui.anomalies.thermal.event",
+				e.Start().Format("02.01 15:04"), formatDuration(e.Duration()), e.PeakTemperature, e.AvgTemperature, charging))
+		}
+	}
+
+	// Троттлинг: процессор или батарея
+	if summary := data.Throttling.Summary(); summary != "" {
+		content.WriteString("\n" + T("report.throttling") + ":\n")
+		content.WriteString(strings.Repeat("─", 40) + "\n")
+		content.WriteString(summary + "\n")
+		for _, p := range data.Throttling.Periods {
+			content.WriteString("  • " + p.String() + "\n")
+		}
+		if verdict := data.Throttling.Verdict(); verdict != "" {
+			content.WriteString(lipgloss.NewStyle().Foreground(theme.Info).Render(verdict) + "\n")
+		}
+	}
+
 	// Рекомендации
 	if len(data.Recommendations) > 0 {
-		content.WriteString("\n💡 Рекомендации по улучшению:\n")
+		content.WriteString(T("tui.anomalies.recommendations"))
 		content.WriteString(strings.Repeat("─", 40) + "\n")
 		
 		for i, rec := range data.Recommendations {
@@ -5241,37 +6627,51 @@ func (a *App) renderReportAnomalies(data *ReportData) string {
 	}
 	
 	// Добавляем инсайты на основе данных
-	content.WriteString("\n\n📊 Статистика аномалий:\n")
-	content.WriteString(fmt.Sprintf("• Обнаружено проблем: %d\n", len(data.Anomalies)))
-	content.WriteString(fmt.Sprintf("• Рекомендаций: %d\n", len(data.Recommendations)))
-	content.WriteString(fmt.Sprintf("• Валидных интервалов: %d\n", data.ValidIntervals))
+	content.WriteString(T("tui.anomalies.stats"))
+	content.WriteString(T("tui.anomalies.stats.found", len(data.Anomalies)))
+	content.WriteString(T("tui.anomalies.stats.recommendations", len(data.Recommendations)))
+	content.WriteString(T("tui.anomalies.stats.intervals", data.ValidIntervals))
 	
 	return content.String()
 }
 
-// renderReportHistory рендерит вкладку с историей
+// renderReportHistory рендерит вкладку с историей: страницу измерений из БД
 func (a *App) renderReportHistory(data *ReportData) string {
 	var content strings.Builder
 	
-	content.WriteString("📜 История измерений\n")
+	content.WriteString(T("tui.history.title"))
 	content.WriteString(strings.Repeat("─", 50) + "\n")
 	
 	// Показываем текущий фильтр
 	filterStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("226")).
+		Foreground(theme.Warning).
 		Bold(true)
-	content.WriteString(filterStyle.Render(fmt.Sprintf("Фильтр: %s | Сортировка: %s\n", 
+	content.WriteString(filterStyle.Render(T("tui.history.filter", 
 		a.getFilterLabel(), a.getSortLabel())))
+	switch {
+	case a.report.filterInput.Focused():
+		content.WriteString(a.report.filterInput.View() + "\n")
+		if a.report.filterErr != nil {
+			content.WriteString(lipgloss.NewStyle().Foreground(theme.Critical).Render("❌ "+a.report.filterErr.Error()) + "\n")
+		}
+	case a.report.filterExpr != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Muted).Render(T("tui.history.expr", a.report.filterExpr)) + "\n")
+	}
 	content.WriteString("\n")
 	
-	// Фильтруем данные
-	filtered := a.filterMeasurements(data.Measurements)
-	
-	// Сортируем данные
-	sorted := a.sortMeasurements(filtered)
+	if err := a.loadHistoryPage(); err != nil {
+		content.WriteString(fmt.Sprintf("❌ %v\n", err))
+		return content.String()
+	}
+	a.updateHistoryTable(a.report.historyRows)
 	
-	// Обновляем таблицу
-	a.updateHistoryTable(sorted)
+	if a.report.historyDetails {
+		if m := a.selectedHistoryMeasurement(); m != nil {
+			content.WriteString(renderHistoryDetails(*m))
+			return content.String()
+		}
+		a.report.historyDetails = false
+	}
 	
 	// Рендерим таблицу
 	content.WriteString(a.report.historyTable.View())
@@ -5279,97 +6679,26 @@ func (a *App) renderReportHistory(data *ReportData) string {
 	// Статистика
 	content.WriteString("\n")
 	statsStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241"))
+		Foreground(theme.Muted)
 	content.WriteString(statsStyle.Render(fmt.Sprintf(
-		"Показано: %d из %d записей", 
-		len(filtered), 
-		len(data.Measurements),
+		T("tui.history.page"), 
+		a.report.historyPage+1,
+		a.historyPageCount(),
+		a.report.historyTotal,
 	)))
 	
 	return content.String()
 }
 
-// filterMeasurements фильтрует измерения по состоянию
-func (a *App) filterMeasurements(measurements []Measurement) []Measurement {
-	if a.report.filterState == "all" {
-		return measurements
-	}
-	
-	var filtered []Measurement
-	for _, m := range measurements {
-		if m.State == a.report.filterState {
-			filtered = append(filtered, m)
-		}
-	}
-	
-	return filtered
-}
-
-// sortMeasurements сортирует измерения
-func (a *App) sortMeasurements(measurements []Measurement) []Measurement {
-	// Создаем копию для сортировки
-	sorted := make([]Measurement, len(measurements))
-	copy(sorted, measurements)
-	
-	// Простая сортировка по времени
-	if !a.report.sortDesc {
-		// Обратный порядок (старые первые)
-		for i := 0; i < len(sorted)/2; i++ {
-			sorted[i], sorted[len(sorted)-1-i] = sorted[len(sorted)-1-i], sorted[i]
-		}
-	}
-	
-	return sorted
-}
-
-// updateHistoryTable обновляет данные в таблице истории
-func (a *App) updateHistoryTable(measurements []Measurement) {
-	var rows []table.Row
-	
-	count := 20 // Показываем последние 20 записей
-	if len(measurements) < count {
-		count = len(measurements)
-	}
-	
-	for i := 0; i < count; i++ {
-		m := measurements[i]
-		
-		// Форматируем данные для таблицы
-		timeStr := m.Timestamp[11:19] // HH:MM:SS
-		chargeStr := fmt.Sprintf("%d%%", m.Percentage)
-		stateStr := formatBatteryStateShort(m.State)
-		tempStr := fmt.Sprintf("%d°C", m.Temperature)
-		
-		// Вычисляем скорость разряда
-		rateStr := "-"
-		if i > 0 && measurements[i-1].State == "discharging" && m.State == "discharging" {
-			rate := measurements[i-1].Percentage - m.Percentage
-			if rate > 0 {
-				rateStr = fmt.Sprintf("-%d%%/ч", rate)
-			}
-		}
-		
-		rows = append(rows, table.Row{
-			timeStr,
-			chargeStr,
-			stateStr,
-			tempStr,
-			rateStr,
-		})
-	}
-	
-	a.report.historyTable.SetRows(rows)
-}
-
 // getFilterLabel возвращает метку текущего фильтра
 func (a *App) getFilterLabel() string {
 	switch a.report.filterState {
 	case "all":
-		return "Все"
+		return T("history.filter.all")
 	case "charging":
-		return "Зарядка"
+		return T("state.charging")
 	case "discharging":
-		return "Разрядка"
+		return T("state.discharging")
 	default:
 		return a.report.filterState
 	}
@@ -5377,38 +6706,138 @@ func (a *App) getFilterLabel() string {
 
 // getSortLabel возвращает метку сортировки
 func (a *App) getSortLabel() string {
+	title := T(historyColumns[a.report.sortColumn].title)
 	if a.report.sortDesc {
-		return "Новые первые ↓"
+		return title + " ↓"
+	}
+	return title + " ↑"
+}
+
+// renderReportSessions рендерит вкладку со списком сессий разрядки и зарядки
+func (a *App) renderReportSessions(data *ReportData) string {
+	var content strings.Builder
+
+	content.WriteString(T("tui.sessions.title"))
+	content.WriteString(strings.Repeat("─", 50) + "\n\n")
+
+	if len(data.Sessions) == 0 {
+		content.WriteString(T("tui.sessions.none"))
+		return content.String()
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+	content.WriteString(headerStyle.Render(fmt.Sprintf("%5s %-12s %-17s %-15s %-12s %s",
+		"№", T("tui.sessions.col.kind"), T("tui.sessions.col.start"), T("tui.sessions.col.duration"), T("tui.sessions.col.charge"), T("tui.sessions.col.rate"))) + "\n")
+
+	dischargeStyle := lipgloss.NewStyle().Foreground(theme.Caution)
+	chargeStyle := lipgloss.NewStyle().Foreground(theme.Good)
+	noteStyle := lipgloss.NewStyle().Foreground(theme.Highlight)
+	for _, s := range data.Sessions {
+		style := dischargeStyle
+		if s.Kind == sessionCharge {
+			style = chargeStyle
+		}
+		content.WriteString(style.Render(T("tui.sessions.row",
+			s.ID, s.KindLabel(), s.Start().Format("02.01 15:04"), formatDuration(s.Duration()),
+			s.StartPercent, s.EndPercent, s.AvgRate)) + "\n")
+		for _, n := range notesDuring(data.Notes, s.Start(), parseStoredTime(s.EndTime)) {
+			content.WriteString(noteStyle.Render("      "+noteGlyph+" "+n.String()) + "\n")
+		}
+	}
+
+	content.WriteString("\n")
+	footer := T("tui.sessions.recent", len(data.Sessions))
+	if !data.Range.IsZero() {
+		footer = T("tui.sessions.range", data.Range.Label(), len(data.Sessions))
+	}
+	content.WriteString(lipgloss.NewStyle().Foreground(theme.Muted).Render(footer))
+	content.WriteString("\n" + lipgloss.NewStyle().Foreground(theme.Muted).Render(T("tui.sessions.note_hint")))
+
+	return content.String()
+}
+
+// renderReportCharging рендерит вкладку с кривыми зарядки
+func (a *App) renderReportCharging(data *ReportData) string {
+	var content strings.Builder
+
+	content.WriteString(T("report.charging_curve") + "\n")
+	content.WriteString(strings.Repeat("─", 50) + "\n\n")
+
+	summary := data.Charging.Summary()
+	if summary == "" {
+		content.WriteString(T("tui.charging.none"))
+		return content.String()
 	}
-	return "Старые первые ↑"
+	content.WriteString(summary + "\n\n")
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+	content.WriteString(headerStyle.Render(fmt.Sprintf("%-12s %-11s %-12s %-12s %-5s %s",
+		T("tui.sessions.col.start"), T("tui.sessions.col.charge"), "20→80%", "80→100%", T("tui.charging.col.watts"), T("tui.charging.col.notes"))) + "\n")
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.Good)
+	warnStyle := lipgloss.NewStyle().Foreground(theme.Caution)
+	for _, c := range data.Charging.Curves {
+		style := normalStyle
+		if c.Notes() != "" {
+			style = warnStyle
+		}
+		content.WriteString(style.Render(fmt.Sprintf("%-12s %3d%% → %3d%% %-12s %-12s %-5s %s",
+			c.Start.Local().Format("02.01 15:04"), c.StartPercent, c.EndPercent,
+			formatOptionalDuration(c.Fast), formatOptionalDuration(c.Trickle), formatOptionalWatts(c.AvgWatts), c.Notes())) + "\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(theme.Muted).Render(
+		T("tui.charging.legend",
+			formatDuration(chargeFastSlow), formatDuration(chargeTrickleMax))))
+
+	return content.String()
 }
 
 // renderReportPredictions рендерит вкладку с прогнозами
 func (a *App) renderReportPredictions(data *ReportData) string {
 	var content strings.Builder
 	
-	content.WriteString("🔮 Прогнозы и аналитика\n")
+	content.WriteString(T("tui.forecast.title"))
 	content.WriteString(strings.Repeat("─", 50) + "\n\n")
 	
 	// Прогноз времени работы
 	if data.RemainingTime > 0 {
 		timeStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("82")).
+			Foreground(theme.Good).
 			Bold(true)
-		content.WriteString(timeStyle.Render("⏱️ Прогноз времени работы:\n"))
-		content.WriteString(fmt.Sprintf("• При текущей нагрузке: %s\n", formatDuration(data.RemainingTime)))
+		content.WriteString(timeStyle.Render(T("tui.forecast.runtime")))
+		content.WriteString(T("tui.forecast.current", data.Remaining))
 		
 		// Дополнительные прогнозы
 		lightUsage := time.Duration(float64(data.RemainingTime) * 1.5)
 		heavyUsage := time.Duration(float64(data.RemainingTime) * 0.6)
 		
-		content.WriteString(fmt.Sprintf("• При легкой нагрузке: %s\n", formatDuration(lightUsage)))
-		content.WriteString(fmt.Sprintf("• При тяжелой нагрузке: %s\n", formatDuration(heavyUsage)))
+		content.WriteString(T("tui.forecast.light", formatDuration(lightUsage)))
+		content.WriteString(T("tui.forecast.heavy", formatDuration(heavyUsage)))
 		content.WriteString("\n")
 	}
+	if sleep := data.Sleep.String(); sleep != "" {
+		content.WriteString(sleep + "\n\n")
+	}
+	if len(data.SleepWakes) > 0 {
+		content.WriteString(T("report.sleep_wakes") + ":\n")
+		for _, s := range data.SleepWakes {
+			content.WriteString("• " + s.String() + "\n")
+		}
+		if len(data.WakeReasons) > 0 {
+			content.WriteString(T("report.wake_reasons") + "\n")
+			for _, r := range data.WakeReasons {
+				content.WriteString("  " + r.String() + "\n")
+			}
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString(renderStandbyWidget(data.Standby))
+	content.WriteString(renderChargeHistogram(data.ChargeHistogram))
 	
 	// Прогноз деградации
-	content.WriteString("📉 Прогноз износа батареи:\n")
+	content.WriteString(T("tui.forecast.wear"))
 	
 	// Рассчитываем прогноз на основе текущего износа и циклов
 	currentWear := data.Wear
@@ -5425,113 +6854,68 @@ func (a *App) renderReportPredictions(data *ReportData) string {
 		
 		wearStyle := lipgloss.NewStyle()
 		if futureWear < 20 {
-			wearStyle = wearStyle.Foreground(lipgloss.Color("82"))
+			wearStyle = wearStyle.Foreground(theme.Good)
 		} else if futureWear < 30 {
-			wearStyle = wearStyle.Foreground(lipgloss.Color("226"))
+			wearStyle = wearStyle.Foreground(theme.Warning)
 		} else {
-			wearStyle = wearStyle.Foreground(lipgloss.Color("196"))
+			wearStyle = wearStyle.Foreground(theme.Critical)
 		}
 		
 		content.WriteString(fmt.Sprintf("• %s\n", 
-			wearStyle.Render(fmt.Sprintf("Через %d мес: %.1f%% износа (%d циклов)", 
+			wearStyle.Render(T("tui.forecast.wear.month", 
 				m, futureWear, futureCycles))))
 	}
 	
 	content.WriteString("\n")
 	
 	// Рекомендации по продлению срока службы
-	content.WriteString("💡 Советы по продлению срока службы:\n")
+	content.WriteString(T("tui.forecast.tips"))
 	
 	tips := []string{
-		"Держите заряд в диапазоне 20-80% для минимального износа",
-		"Избегайте полной разрядки батареи",
-		"Используйте оригинальное зарядное устройство",
-		"Избегайте перегрева (>45°C) и переохлаждения (<10°C)",
-		"При длительной работе от сети извлекайте батарею (если возможно)",
+		T("tui.forecast.tip.1"),
+		T("tui.forecast.tip.2"),
+		T("tui.forecast.tip.3"),
+		T("tui.forecast.tip.4"),
+		T("tui.forecast.tip.5"),
 	}
 	
 	for _, tip := range tips {
 		content.WriteString(fmt.Sprintf("• %s\n", tip))
 	}
 	
-	// Сравнение с эталонными показателями
-	content.WriteString("\n📊 Сравнение с эталоном MacBook:\n")
-	
-	// Эталонные значения для MacBook
-	benchmarkCycles := 1000
-	benchmarkWear := 20.0
-	
-	cycleHealth := float64(benchmarkCycles-currentCycles) / float64(benchmarkCycles) * 100
-	wearHealth := (benchmarkWear - currentWear) / benchmarkWear * 100
-	
-	if cycleHealth < 0 {
-		cycleHealth = 0
+	// Сравнение с типичными показателями модели
+	content.WriteString("\n" + T("report.model", data.Model.Label()) + ":\n")
+	for _, line := range data.Model.Lines() {
+		content.WriteString(fmt.Sprintf("• %s\n", line))
 	}
+
+	// Износ к концу ресурса циклов по данным Apple
+	benchmarkWear := float64(100 - appleRetainedCapacity)
+	cycleHealth := data.Model.CycleResource()
+	wearHealth := (benchmarkWear - currentWear) / benchmarkWear * 100
 	if wearHealth < 0 {
 		wearHealth = 0
 	}
 	
-	content.WriteString(fmt.Sprintf("• Ресурс по циклам: %.0f%%\n", cycleHealth))
-	content.WriteString(fmt.Sprintf("• Состояние по износу: %.0f%%\n", wearHealth))
-	
 	// Общая оценка
 	overallHealth := (cycleHealth + wearHealth) / 2
 	healthStyle := lipgloss.NewStyle().Bold(true)
 	
 	if overallHealth > 70 {
-		healthStyle = healthStyle.Foreground(lipgloss.Color("82"))
-		content.WriteString(healthStyle.Render("\n✅ Батарея в отличном состоянии!"))
+		healthStyle = healthStyle.Foreground(theme.Good)
+		content.WriteString(healthStyle.Render(T("tui.forecast.excellent")))
 	} else if overallHealth > 40 {
-		healthStyle = healthStyle.Foreground(lipgloss.Color("226"))
-		content.WriteString(healthStyle.Render("\n⚡ Батарея в хорошем состоянии"))
+		healthStyle = healthStyle.Foreground(theme.Warning)
+		content.WriteString(healthStyle.Render(T("tui.forecast.good")))
 	} else {
-		healthStyle = healthStyle.Foreground(lipgloss.Color("196"))
-		content.WriteString(healthStyle.Render("\n⚠️ Рекомендуется замена батареи"))
+		healthStyle = healthStyle.Foreground(theme.Critical)
+		content.WriteString(healthStyle.Render(T("tui.forecast.replace")))
 	}
 	
 	return content.String()
 }
 
 
-// renderExport рендерит экран экспорта
-func (a *App) renderExport() string {
-	content := "📄 Экспорт отчетов\n\n"
-	content += "Экспорт в HTML с автогенерацией имени файла\n\n"
-	content += "Нажмите Enter для экспорта в HTML\n"
-	content += "Файл будет сохранен в ~/Documents/ как batmon_report_YYYY-MM-DD.html\n\n"
-	
-	// Показываем статус экспорта если есть
-	if a.exportStatus != "" {
-		content += fmt.Sprintf("Статус: %s\n\n", a.exportStatus)
-	}
-	
-	content += "Нажмите q для возврата в главное меню"
-	
-	return lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
-		Padding(1).
-		Render(content)
-}
-
-// renderSettings рендерит экран очистки БД
-func (a *App) renderSettings() string {
-	content := "🗑️ Очистка базы данных\n\n"
-	content += "⚠️  ВНИМАНИЕ: Эта операция удалит ВСЕ сохраненные данные!\n\n"
-	content += "Будут удалены:\n"
-	content += "• Все измерения батареи\n"
-	content += "• История состояний\n"
-	content += "• Статистика использования\n\n"
-	content += "Нажмите Y для подтверждения очистки\n"
-	content += "Нажмите q или N для отмены"
-	
-	return lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
-		Padding(1).
-		Render(content)
-}
-
 // renderHelp рендерит экран справки
 func (a *App) renderHelp() string {
 	// Адаптируем размер к размеру терминала
@@ -5541,67 +6925,68 @@ func (a *App) renderHelp() string {
 	}
 	
 	title := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("39")).
+		Foreground(theme.Accent).
 		Bold(true).
 		Align(lipgloss.Center).
-		Render("🔋 Справка по BatMon") + "\n\n"
+		Render(T("help.title")) + "\n\n"
 		
 	// Основная цель
 	purpose := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10")).
+		Foreground(theme.Good).
 		Bold(true).
-		Render("🎯 ГЛАВНАЯ ЦЕЛЬ") + "\n"
-	purpose += "Понять, нужно ли менять батарею MacBook\n\n"
+		Render(T("help.purpose")) + "\n"
+	purpose += T("help.purpose.text") + "\n\n"
 	
 	// Краткая инструкция
 	howTo := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("12")).
+		Foreground(theme.Accent).
 		Bold(true).
-		Render("🚀 КАК ПОЛЬЗОВАТЬСЯ") + "\n"
-	howTo += "1. Зарядите до 100%\n"
-	howTo += "2. Выберите '🔋 Полный анализ батареи'\n"
-	howTo += "3. Разрядите до 0-10% (2-3 часа)\n"
-	howTo += "4. Получите рекомендацию\n\n"
+		Render(T("help.howto")) + "\n"
+	howTo += T("help.howto.1") + "\n"
+	howTo += T("help.howto.2") + "\n"
+	howTo += T("help.howto.3") + "\n"
+	howTo += T("help.howto.4") + "\n\n"
 	
 	// Режимы
 	modes := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("11")).
+		Foreground(theme.Warning).
 		Bold(true).
-		Render("📋 РЕЖИМЫ РАБОТЫ") + "\n"
-	modes += "⚡ Быстрая диагностика - моментальная проверка\n"
-	modes += "🔋 Полный анализ - основной тест (100%→0%)\n"
-	modes += "📊 Детальный отчет - графики и тренды\n\n"
+		Render(T("help.modes")) + "\n"
+	modes += T("help.modes.quick") + "\n"
+	modes += T("help.modes.full") + "\n"
+	modes += T("help.modes.report") + "\n\n"
 	
 	// Критерии оценки
 	criteria := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("9")).
+		Foreground(theme.Critical).
 		Bold(true).
-		Render("🔍 ОЦЕНКА СОСТОЯНИЯ") + "\n"
-	criteria += lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("✅ Хорошо: ") + "износ <20%, циклы <1000\n"
-	criteria += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("⚠️  Внимание: ") + "износ 20-30%, циклы 1000+\n"
-	criteria += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("🔴 Замена: ") + "износ >30%, циклы >1500\n\n"
+		Render(T("help.criteria")) + "\n"
+	criteria += lipgloss.NewStyle().Foreground(theme.Good).Render(T("help.criteria.good")) + T("help.criteria.good.v") + "\n"
+	criteria += lipgloss.NewStyle().Foreground(theme.Warning).Render(T("help.criteria.warn")) + T("help.criteria.warn.v") + "\n"
+	criteria += lipgloss.NewStyle().Foreground(theme.Critical).Render(T("help.criteria.bad")) + T("help.criteria.bad.v") + "\n\n"
 	
 	// Советы
 	tips := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("14")).
+		Foreground(theme.Info).
 		Bold(true).
-		Render("💡 СОВЕТЫ") + "\n"
-	tips += "• Минимум 2-3 часа для точного анализа\n"
-	tips += "• Не закрывайте программу во время теста\n"
-	tips += "• MacBook не будет засыпать (кроме закрытия крышки)\n"
-	tips += "• Сохраняйте отчеты для отслеживания\n\n"
+		Render(T("help.tips")) + "\n"
+	tips += T("help.tips.1") + "\n"
+	tips += T("help.tips.2") + "\n"
+	tips += T("help.tips.3") + "\n"
+	tips += T("help.tips.4") + "\n"
+	tips += T("help.tips.5") + "\n\n"
 	
 	// Управление
 	controls := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(theme.Border).
 		Align(lipgloss.Center).
-		Render("Нажмите 'q' для выхода в главное меню")
+		Render(T("help.back"))
 	
 	content := title + purpose + howTo + modes + criteria + tips + controls
 	
 	return lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(theme.Border).
 		Padding(1).
 		Width(maxWidth).
 		Render(content)
@@ -5610,72 +6995,72 @@ func (a *App) renderHelp() string {
 // renderWelcome рендерит экран приветствия
 func (a *App) renderWelcome() string {
 	title := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("39")).
+		Foreground(theme.Accent).
 		Bold(true).
 		Align(lipgloss.Center).
 		Render("🔋 BatMon v2.0") + "\n"
 	
 	subtitle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("12")).
+		Foreground(theme.Accent).
 		Bold(true).
 		Align(lipgloss.Center).
-		Render("Интеллектуальный анализ батареи MacBook") + "\n\n"
+		Render(T("welcome.subtitle")) + "\n\n"
 		
 	purpose := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10")).
+		Foreground(theme.Good).
 		Bold(true).
-		Render("🎯 ЦЕЛЬ ПРОГРАММЫ") + "\n"
-	purpose += "Помочь вам принять обоснованное решение:\n"
+		Render(T("welcome.purpose")) + "\n"
+	purpose += T("welcome.purpose.text")
 	purpose += lipgloss.NewStyle().
-		Foreground(lipgloss.Color("11")).
+		Foreground(theme.Warning).
 		Bold(true).
-		Render("НУЖНО ЛИ МЕНЯТЬ БАТАРЕЮ В ВАШЕМ MacBook?") + "\n\n"
+		Render(T("welcome.question")) + "\n\n"
 	
 	how := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("14")).
+		Foreground(theme.Info).
 		Bold(true).
-		Render("🔍 КАК ЭТО РАБОТАЕТ") + "\n"
-	how += "1. Программа собирает данные о работе батареи\n"
-	how += "2. Анализирует реальные показатели vs. заявленные\n"  
-	how += "3. Выявляет аномалии и проблемы\n"
-	how += "4. Даёт чёткую рекомендацию с обоснованием\n\n"
+		Render(T("welcome.how")) + "\n"
+	how += T("welcome.how.1")
+	how += T("welcome.how.2")  
+	how += T("welcome.how.3")
+	how += T("welcome.how.4")
 	
 	example := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("9")).
+		Foreground(theme.Critical).
 		Bold(true).
-		Render("⚠️ ЗАЧЕМ ЭТО НУЖНО") + "\n"
-	example += "Стандартные показатели macOS могут обманывать:\n"
-	example += "• Батарея показывает 5 часов, а садится за 2 часа\n"
-	example += "• Заряд резко проваливается с 90% до 40%\n"  
-	example += "• Перегрев при обычной нагрузке\n\n"
+		Render(T("welcome.why")) + "\n"
+	example += T("welcome.why.text")
+	example += T("welcome.why.1")
+	example += T("welcome.why.2")  
+	example += T("welcome.why.3")
 	example += lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10")).
-		Render("BatMon выявит такие проблемы и объяснит их причины!") + "\n\n"
+		Foreground(theme.Good).
+		Render(T("welcome.why.promise")) + "\n\n"
 	
 	instruction := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("13")).
+		Foreground(theme.Secondary).
 		Bold(true).
-		Render("🚀 НАЧНЁМ!") + "\n"
-	instruction += "Для максимально точного анализа:\n"
-	instruction += "1. Зарядите MacBook до 100%\n"
-	instruction += "2. Выберите 'Полный анализ батареи'\n"  
-	instruction += "3. Используйте MacBook как обычно до разрядки\n"
-	instruction += "4. MacBook не будет засыпать (кроме закрытия крышки)\n\n"
+		Render(T("welcome.start")) + "\n"
+	instruction += T("welcome.start.text")
+	instruction += T("welcome.start.1")
+	instruction += T("welcome.start.2")  
+	instruction += T("welcome.start.3")
+	instruction += T("welcome.start.4")
 	
 	controls := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(theme.Border).
 		Align(lipgloss.Center).
-		Render("Нажмите Enter или Пробел для продолжения\n") +
+		Render(T("welcome.continue")) +
 		lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(theme.Border).
 		Align(lipgloss.Center).
-		Render("'q' для выхода")
+		Render(T("welcome.quit"))
 	
 	content := title + subtitle + purpose + how + example + instruction + controls
 	
 	return lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("39")).
+		BorderForeground(theme.Accent).
 		Padding(2).
 		Width(80).
 		Align(lipgloss.Center).
@@ -5687,9 +7072,9 @@ func (a *App) renderQuickDiag() string {
 	if a.latest == nil {
 		return lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("9")).
+			BorderForeground(theme.Critical).
 			Padding(2).
-			Render("❌ Данные о батарее недоступны\n\nНажмите 'q' для выхода в меню")
+			Render(T("quick.no_data"))
 	}
 	
 	wear := computeWear(a.latest.DesignCapacity, a.latest.FullChargeCap)
@@ -5698,25 +7083,25 @@ func (a *App) renderQuickDiag() string {
 	
 	// Заголовок
 	title := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("39")).
+		Foreground(theme.Accent).
 		Bold(true).
 		Align(lipgloss.Center).
-		Render("⚡ БЫСТРАЯ ДИАГНОСТИКА БАТАРЕИ") + "\n\n"
+		Render(T("quick.title")) + "\n\n"
 	
 	// Основные показатели
 	currentSection := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("12")).
+		Foreground(theme.Accent).
 		Bold(true).
-		Render("📊 ТЕКУЩЕЕ СОСТОЯНИЕ") + "\n"
+		Render(T("quick.current")) + "\n"
 	
-	currentSection += fmt.Sprintf("🔋 Заряд: %s\n", 
+	currentSection += T("quick.charge", 
 		lipgloss.NewStyle().
 			Foreground(getBatteryColor(a.latest.Percentage)).
 			Bold(true).
 			Render(fmt.Sprintf("%d%%", a.latest.Percentage)))
 	
-	currentSection += fmt.Sprintf("🔄 Состояние: %s\n", formatBatteryState(a.latest.State))
-	currentSection += fmt.Sprintf("🌡️ Температура: %s\n", 
+	currentSection += T("quick.state", formatBatteryState(a.latest.State))
+	currentSection += T("quick.temperature", 
 		lipgloss.NewStyle().
 			Foreground(getTemperatureColor(a.latest.Temperature)).
 			Render(fmt.Sprintf("%d°C", a.latest.Temperature)))
@@ -5724,68 +7109,68 @@ func (a *App) renderQuickDiag() string {
 	
 	// Здоровье батареи
 	healthSection := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10")).
+		Foreground(theme.Good).
 		Bold(true).
-		Render("💚 ЗДОРОВЬЕ БАТАРЕИ") + "\n"
+		Render(T("quick.health")) + "\n"
 	
-	healthSection += fmt.Sprintf("📉 Износ: %s\n", 
+	healthSection += T("quick.wear", 
 		lipgloss.NewStyle().
 			Foreground(getWearColor(wear)).
 			Bold(true).
 			Render(fmt.Sprintf("%.1f%%", wear)))
 	
-	healthSection += fmt.Sprintf("🔁 Циклы: %s\n", 
+	healthSection += T("quick.cycles", 
 		lipgloss.NewStyle().
 			Foreground(getCycleColor(a.latest.CycleCount)).
 			Render(fmt.Sprintf("%d", a.latest.CycleCount)))
 	
-	healthSection += fmt.Sprintf("💚 Общая оценка: %s\n\n", 
+	healthSection += T("quick.overall", 
 		lipgloss.NewStyle().
-			Foreground(lipgloss.Color(healthColor)).
+			Foreground(healthColor).
 			Bold(true).
 			Render(healthStatus))
 	
 	// Быстрая рекомендация
 	recommendationSection := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("11")).
+		Foreground(theme.Warning).
 		Bold(true).
-		Render("🎯 БЫСТРАЯ РЕКОМЕНДАЦИЯ") + "\n"
+		Render(T("quick.recommendation")) + "\n"
 	
 	var recommendation string
 	if wear < 20 && a.latest.CycleCount < 1000 {
 		recommendation = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("10")).
-			Render("✅ Батарея в хорошем состоянии. Замена не требуется.")
+			Foreground(theme.Good).
+			Render(T("quick.good"))
 	} else if wear < 30 && a.latest.CycleCount < 1500 {
 		recommendation = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11")).
-			Render("⚠️ Батарея работает, но стоит планировать замену.")
+			Foreground(theme.Warning).
+			Render(T("quick.plan"))
 	} else {
 		recommendation = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("9")).
-			Render("🔴 Рекомендуется замена батареи.")
+			Foreground(theme.Critical).
+			Render(T("quick.replace"))
 	}
 	recommendationSection += recommendation + "\n\n"
 	
 	// Дополнительные советы
 	tipsSection := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("14")).
+		Foreground(theme.Info).
 		Bold(true).
-		Render("💡 СОВЕТ") + "\n"
-	tipsSection += "Для полного анализа выберите '🔋 Полный анализ батареи'\n"
-	tipsSection += "или '📊 Детальный отчет' для графиков и трендов\n\n"
+		Render(T("quick.tip")) + "\n"
+	tipsSection += T("quick.tip.1")
+	tipsSection += T("quick.tip.2")
 	
 	// Управление
 	controls := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("8")).
+		Foreground(theme.Border).
 		Align(lipgloss.Center).
-		Render("Нажмите 'q' для выхода в главное меню")
+		Render(T("tui.back_to_menu"))
 	
 	content := title + currentSection + healthSection + recommendationSection + tipsSection + controls
 	
 	return lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("39")).
+		BorderForeground(theme.Accent).
 		Padding(2).
 		Width(70).
 		Render(content)
@@ -5799,6 +7184,8 @@ func (a *App) initQuickDiag() {
 
 // initDashboard инициализирует dashboard
 func (a *App) initDashboard() {
+	a.refreshPowerStatus()
+	
 	// Создаем кастомные прогресс-бары с адаптивной шириной
 	progressWidth := 30
 	if a.windowWidth > 0 {
@@ -5823,10 +7210,10 @@ func (a *App) initDashboard() {
 	
 	// Создаем таблицу с фиксированными колонками для компактности
 	columns := []table.Column{
-		{Title: "Время", Width: 5},
-		{Title: "Заряд", Width: 5},
-		{Title: "Состояние", Width: 10},
-		{Title: "Темп.", Width: 5},
+		{Title: T("dashboard.col.time"), Width: 5},
+		{Title: T("dashboard.col.charge"), Width: 5},
+		{Title: T("dashboard.col.state"), Width: 10},
+		{Title: T("dashboard.col.temp"), Width: 5},
 	}
 	
 	measureTable := table.New(
@@ -5841,6 +7228,7 @@ func (a *App) initDashboard() {
 		wearGauge:    wearGauge,
 		measureTable: measureTable,
 		lastUpdate:   time.Now(),
+		chartView:    ChartView{Span: parseChartWindow(getConfig().Dashboard.ChartWindow)},
 	}
 }
 
@@ -5848,11 +7236,14 @@ func (a *App) initDashboard() {
 func (a *App) initReport() {
 	// Инициализация вкладок
 	tabs := []string{
-		"📊 Обзор",
-		"📈 Графики", 
-		"⚠️ Аномалии",
-		"📜 История",
-		"🔮 Прогнозы",
+		"📊 " + T("tab.overview"),
+		"📈 " + T("tab.charts"), 
+		"⚠️ " + T("tab.anomalies"),
+		"📜 " + T("tab.history"),
+		"🔮 " + T("tab.forecast"),
+		"🔋 " + T("tab.sessions"),
+		"⚡ " + T("tab.charging"),
+		"🔬 " + T("tab.metrics"),
 	}
 	
 	// Создаем таблицу истории с адаптивными колонками
@@ -5862,14 +7253,7 @@ func (a *App) initReport() {
 	}
 	columnWidths := a.calculateReportTableColumnWidths(tableWidth)
 	
-	columns := []table.Column{
-		{Title: "Время", Width: columnWidths[0]},
-		{Title: "Заряд", Width: columnWidths[1]},
-		{Title: "Состояние", Width: columnWidths[2]},
-		{Title: "Циклы", Width: columnWidths[3]},
-		{Title: "Темп.", Width: columnWidths[4]},
-		{Title: "Износ", Width: columnWidths[5]},
-	}
+	columns := historyTableColumns(columnWidths, 0, true)
 	
 	tableHeight := 15
 	if a.windowHeight > 30 {
@@ -5888,6 +7272,7 @@ func (a *App) initReport() {
 		activeTab:    0,
 		historyTable: historyTable,
 		filterState:  "all",
+		filterInput:  newHistoryFilterInput(),
 		sortColumn:   0,
 		sortDesc:     true,
 		lastUpdate:   time.Now(),
@@ -5902,13 +7287,27 @@ func (a *App) updateDashboardData() {
 
 // clearDatabase очищает всю базу данных
 func (a *App) clearDatabase() error {
+	if a.dataService != nil && a.dataService.replay != nil {
+		return errors.New(T("replay.err.no_clear"))
+	}
+
+	// Без резервной копии не удаляем: очистку можно будет отменить через db restore
+	if a.dataService != nil && a.dataService.db != nil {
+		if err := a.dataService.collector.Flush(); err != nil {
+			logWarnf("⚠️ %v", err)
+		}
+		if _, err := autoBackup(a.dataService.db, "clear"); err != nil {
+			return err
+		}
+	}
+
 	// Останавливаем сервис сбора данных
 	if a.dataService != nil {
 		a.dataService.Stop()
 		
 		// Закрываем соединение с БД
-		if a.dataService.db != nil {
-			a.dataService.db.Close()
+		if err := a.dataService.store.Close(); err != nil {
+			logWarnf("⚠️ %v", err)
 		}
 	}
 	
@@ -5937,16 +7336,16 @@ func (a *App) clearDatabase() error {
 	a.latest = nil
 	
 	// Переинициализируем базу данных и сервис
-	db, err := initDB(getDBPath())
+	store, err := openStore(getDBPath())
 	if err != nil {
-		return fmt.Errorf("не удалось переинициализировать БД: %v", err)
+		return fmt.Errorf(T("db.err.reinit"), err)
 	}
 	
 	// Создаем новый буфер памяти
 	buffer := NewMemoryBuffer(100) // Создаем буфер на 100 записей
 	
 	// Создаем новый сервис сбора данных
-	a.dataService = NewDataService(db, buffer)
+	a.dataService = NewDataService(store, buffer)
 	a.dataService.Start()
 	
 	return nil
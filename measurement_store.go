@@ -0,0 +1,161 @@
+// measurement_store.go
+//
+// MeasurementStore – хранилище измерений: запись пакетов, чтение последних
+// измерений и страниц истории, очистка по сроку хранения и статистика.
+// SQL для таблицы measurements собран здесь, в sqliteMeasurements; очередь
+// записи коллектора и ретенция работают через интерфейс, поэтому в тестах их
+// можно запустить на хранилище в памяти, а другой бэкенд – подключить, не
+// трогая коллектор. Функции insertMeasurement, getLastNMeasurements и
+// getMeasurementsBefore остались обертками для кода, которому нужна *sqlx.DB.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MeasurementSpan – сколько измерений хранится и за какой период
+type MeasurementSpan struct {
+	Count  int
+	Oldest string // timestamp самого старого измерения; пусто – измерений нет
+	Newest string
+}
+
+// MeasurementStore – хранилище измерений батареи
+type MeasurementStore interface {
+	// InsertMeasurements записывает измерения и значения производных метрик
+	// для них одной транзакцией: либо всё, либо ничего
+	InsertMeasurements(ms []Measurement, metrics []DerivedMetric) error
+	// LastMeasurements возвращает последние n измерений, старые первыми
+	LastMeasurements(n int) ([]Measurement, error)
+	// MeasurementsBefore возвращает не более limit измерений раньше before
+	// (пусто – с последнего), старые первыми
+	MeasurementsBefore(before string, limit int) ([]Measurement, error)
+	// Prune удаляет измерения и связанные с ними выборки старше cutoff;
+	// возвращает число удаленных строк и обновленных почасовых сводок
+	Prune(cutoff time.Time) (removed, rolledUp int64, err error)
+	// Compact возвращает освободившееся после Prune место
+	Compact() error
+	// Span возвращает число измерений и их период
+	Span() (MeasurementSpan, error)
+}
+
+// sqliteMeasurements – хранилище измерений в SQLite
+type sqliteMeasurements struct {
+	db *sqlx.DB
+}
+
+// newSQLiteMeasurements создает хранилище измерений поверх открытой БД
+func newSQLiteMeasurements(db *sqlx.DB) MeasurementStore {
+	return sqliteMeasurements{db: db}
+}
+
+func (s sqliteMeasurements) InsertMeasurements(ms []Measurement, metrics []DerivedMetric) error {
+	if len(ms) == 0 {
+		return nil
+	}
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf(T("store.err.transaction"), err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Preparex(insertMeasurementQuery)
+	if err != nil {
+		return fmt.Errorf(T("store.err.prepare"), err)
+	}
+	defer stmt.Close()
+	for i := range ms {
+		m := &ms[i]
+		if _, err := stmt.Exec(measurementArgs(m)...); err != nil {
+			return fmt.Errorf(T("store.err.save"), m.Timestamp, err)
+		}
+		if err := insertDerivedMetrics(tx, *m, metrics); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf(T("store.err.batch"), err)
+	}
+	return nil
+}
+
+func (s sqliteMeasurements) LastMeasurements(n int) ([]Measurement, error) {
+	return s.MeasurementsBefore("", n)
+}
+
+// MeasurementsBefore читает страницу по индексу timestamp: следующая
+// страница запрашивается с before = timestamp первого измерения, что не
+// зависит от глубины, в отличие от OFFSET
+func (s sqliteMeasurements) MeasurementsBefore(before string, limit int) ([]Measurement, error) {
+	var ms []Measurement
+	query := `SELECT * FROM measurements ORDER BY timestamp DESC LIMIT ?`
+	args := []interface{}{limit}
+	if before != "" {
+		query = `SELECT * FROM measurements WHERE timestamp < ? ORDER BY timestamp DESC LIMIT ?`
+		args = []interface{}{before, limit}
+	}
+	if err := s.db.Select(&ms, query, args...); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(ms)-1; i < j; i, j = i+1, j-1 {
+		ms[i], ms[j] = ms[j], ms[i]
+	}
+	return ms, nil
+}
+
+// Prune перед удалением сворачивает старые измерения в почасовые сводки,
+// чтобы долгосрочный тренд износа не терялся вместе с ними. Измерения
+// удаляются по границе часа, как и сворачиваются.
+func (s sqliteMeasurements) Prune(cutoff time.Time) (int64, int64, error) {
+	rolledUp, err := rollupMeasurements(s.db, rollupCutoff(cutoff))
+	if err != nil {
+		return 0, 0, err
+	}
+	result, err := s.db.Exec(`DELETE FROM measurements WHERE timestamp < ?`, rollupCutoff(cutoff))
+	if err != nil {
+		return 0, rolledUp, fmt.Errorf(T("store.err.cleanup"), err)
+	}
+	removed, _ := result.RowsAffected()
+
+	// Выборки по приложениям, производные метрики, мощность, тепловое
+	// давление и сетевой контекст живут столько же, сколько измерения;
+	// ошибка в них пишется в лог и не мешает очистке измерений. Время в
+	// таблицах хранится в UTC, поэтому и граница – в UTC.
+	for _, table := range []string{"app_power_samples", "derived_metrics", "power_samples", "thermal_samples", "net_samples"} {
+		res, err := s.db.Exec(`DELETE FROM `+table+` WHERE timestamp < ?`, cutoff.UTC().Format(time.RFC3339))
+		if err != nil {
+			logWarnf("⚠️ очистка %s: %v", table, err)
+			continue
+		}
+		rows, _ := res.RowsAffected()
+		removed += rows
+	}
+	return removed, rolledUp, nil
+}
+
+func (s sqliteMeasurements) Compact() error {
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("VACUUM: %w", err)
+	}
+	return nil
+}
+
+func (s sqliteMeasurements) Span() (MeasurementSpan, error) {
+	var span struct {
+		Count  int     `db:"count"`
+		Oldest *string `db:"oldest"`
+		Newest *string `db:"newest"`
+	}
+	err := s.db.Get(&span, `SELECT COUNT(*) AS count, MIN(timestamp) AS oldest, MAX(timestamp) AS newest FROM measurements`)
+	if err != nil {
+		return MeasurementSpan{}, fmt.Errorf(T("store.err.count"), err)
+	}
+	result := MeasurementSpan{Count: span.Count}
+	if span.Oldest != nil {
+		result.Oldest, result.Newest = *span.Oldest, *span.Newest
+	}
+	return result, nil
+}
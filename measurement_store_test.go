@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryMeasurements – хранилище измерений в памяти для тестов
+type memoryMeasurements struct {
+	mu        sync.Mutex
+	ms        []Measurement // по возрастанию timestamp
+	insertErr error         // ошибка, которую вернет InsertMeasurements
+}
+
+func (s *memoryMeasurements) InsertMeasurements(ms []Measurement, _ []DerivedMetric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.insertErr != nil {
+		return s.insertErr
+	}
+	s.ms = append(s.ms, ms...)
+	sort.SliceStable(s.ms, func(i, j int) bool { return s.ms[i].Timestamp < s.ms[j].Timestamp })
+	return nil
+}
+
+func (s *memoryMeasurements) LastMeasurements(n int) ([]Measurement, error) {
+	return s.MeasurementsBefore("", n)
+}
+
+func (s *memoryMeasurements) MeasurementsBefore(before string, limit int) ([]Measurement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	end := len(s.ms)
+	if before != "" {
+		end = sort.Search(len(s.ms), func(i int) bool { return s.ms[i].Timestamp >= before })
+	}
+	start := max(end-limit, 0)
+	return append([]Measurement(nil), s.ms[start:end]...), nil
+}
+
+func (s *memoryMeasurements) Prune(cutoff time.Time) (int64, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	boundary := rollupCutoff(cutoff)
+	kept := s.ms[:0]
+	for _, m := range s.ms {
+		if m.Timestamp >= boundary {
+			kept = append(kept, m)
+		}
+	}
+	removed := int64(len(s.ms) - len(kept))
+	s.ms = kept
+	return removed, 0, nil
+}
+
+func (s *memoryMeasurements) Compact() error { return nil }
+
+func (s *memoryMeasurements) Span() (MeasurementSpan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.ms) == 0 {
+		return MeasurementSpan{}, nil
+	}
+	return MeasurementSpan{Count: len(s.ms), Oldest: s.ms[0].Timestamp, Newest: s.ms[len(s.ms)-1].Timestamp}, nil
+}
+
+// TestMeasurementStores проверяет, что SQLite и хранилище в памяти ведут
+// себя одинаково: на фейке можно тестировать код поверх MeasurementStore
+func TestMeasurementStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) MeasurementStore{
+		"sqlite": func(t *testing.T) MeasurementStore { return newSQLiteMeasurements(newTestDB(t)) },
+		"memory": func(t *testing.T) MeasurementStore { return &memoryMeasurements{} },
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			if span, err := store.Span(); err != nil || span.Count != 0 || span.Oldest != "" {
+				t.Fatalf("пустое хранилище: %+v, %v", span, err)
+			}
+
+			ms := steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12) // 08:00 – 09:50
+			if err := store.InsertMeasurements(ms, nil); err != nil {
+				t.Fatalf("запись: %v", err)
+			}
+
+			last, err := store.LastMeasurements(3)
+			if err != nil || len(last) != 3 || last[2].Timestamp != ms[11].Timestamp || last[0].Timestamp != ms[9].Timestamp {
+				t.Fatalf("последние 3: %d измерений, %v", len(last), err)
+			}
+			page, err := store.MeasurementsBefore(ms[5].Timestamp, 2)
+			if err != nil || len(page) != 2 || page[0].Timestamp != ms[3].Timestamp || page[1].Timestamp != ms[4].Timestamp {
+				t.Fatalf("страница до %s: %+v, %v", ms[5].Timestamp, page, err)
+			}
+
+			span, err := store.Span()
+			if err != nil || span.Count != 12 || span.Oldest != ms[0].Timestamp || span.Newest != ms[11].Timestamp {
+				t.Fatalf("статистика: %+v, %v", span, err)
+			}
+
+			// Граница очистки округляется до часа: 09:20 → удаляется всё до 09:00
+			removed, _, err := store.Prune(fixtureStart.Add(80 * time.Minute))
+			if err != nil || removed != 6 {
+				t.Fatalf("очистка: удалено %d, %v", removed, err)
+			}
+			if span, _ := store.Span(); span.Count != 6 || span.Oldest != ms[6].Timestamp {
+				t.Errorf("после очистки: %+v", span)
+			}
+		})
+	}
+}
+
+// Граница очистки приходит в местном времени, а выборки хранятся в UTC:
+// в часовом поясе восточнее UTC свежие выборки не должны удаляться
+func TestPruneSideTablesInLocalZone(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(24*time.Hour))
+	time.Local = mustLoadLocation(t, "Asia/Tokyo")
+	db := newTestDB(t)
+
+	at := func(d time.Duration) string { return fixtureStart.Add(d).UTC().Format(time.RFC3339) }
+	tables := []string{"power_samples", "thermal_samples", "net_samples"}
+	for _, table := range tables {
+		for _, ts := range []string{at(time.Hour), at(5 * time.Hour)} {
+			if _, err := db.Exec(`INSERT INTO `+table+` (timestamp) VALUES (?)`, ts); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// 11:00 UTC – 20:00 по Токио; выборка в 13:00 UTC новее границы
+	cutoff := fixtureStart.Add(3 * time.Hour).In(time.Local)
+	if _, _, err := newSQLiteMeasurements(db).Prune(cutoff); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		var kept []string
+		if err := db.Select(&kept, `SELECT timestamp FROM `+table+` ORDER BY timestamp`); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(kept, []string{at(5 * time.Hour)}) {
+			t.Errorf("%s: осталось %v, ожидалось только %s", table, kept, at(5*time.Hour))
+		}
+	}
+}
+
+func TestWriteQueueKeepsPendingOnError(t *testing.T) {
+	store := &memoryMeasurements{insertErr: errors.New("диск занят")}
+	q := newWriteQueue(store)
+	for _, m := range steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 3) {
+		q.Add(m)
+	}
+	if err := q.Flush(nil); err == nil {
+		t.Fatal("ошибка записи потеряна")
+	}
+	if len(store.ms) != 0 || len(q.pending) != 3 {
+		t.Fatalf("после ошибки: в хранилище %d, в очереди %d", len(store.ms), len(q.pending))
+	}
+
+	store.insertErr = nil
+	if err := q.Flush(nil); err != nil {
+		t.Fatalf("повторная запись: %v", err)
+	}
+	if len(store.ms) != 3 || len(q.pending) != 0 {
+		t.Errorf("после записи: в хранилище %d, в очереди %d", len(store.ms), len(q.pending))
+	}
+}
+
+// TestSchemaDoc проверяет, что batmon schema описывает то, что программа
+// действительно пишет: каждому тегу db измерения есть столбец в measurements,
+// а каждому тегу json – свойство в JSON Schema
+func TestSchemaDoc(t *testing.T) {
+	doc, err := buildSchemaDoc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.SchemaVersion != latestSchemaVersion() || len(doc.Migrations) != len(migrations) {
+		t.Errorf("версия схемы %d, миграций %d", doc.SchemaVersion, len(doc.Migrations))
+	}
+
+	columns := map[string]bool{}
+	for _, table := range doc.Tables {
+		if table.Name == "measurements" {
+			for _, c := range table.Columns {
+				columns[c.Name] = true
+			}
+		}
+	}
+	var measurement *JSONSchema
+	for _, e := range doc.Exports {
+		if e.Name == "measurement" {
+			measurement = e.Schema
+		}
+	}
+	if measurement == nil {
+		t.Fatal("нет JSON Schema измерения")
+	}
+
+	typ := reflect.TypeOf(Measurement{})
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if col := f.Tag.Get("db"); !columns[col] {
+			t.Errorf("поле %s: нет столбца %q в measurements", f.Name, col)
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if measurement.Properties[name] == nil {
+			t.Errorf("поле %s: нет свойства %q в JSON Schema", f.Name, name)
+		}
+	}
+	if got := measurement.Properties["cell_voltages"]; got == nil || got.Type != "string" {
+		t.Errorf("cell_voltages: %+v", got)
+	}
+	for _, name := range measurement.Required {
+		if name == "cell_voltages" {
+			t.Error("cell_voltages с omitempty не должно быть обязательным")
+		}
+	}
+}
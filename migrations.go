@@ -0,0 +1,286 @@
+// migrations.go
+//
+// Версионированные миграции схемы БД. Примененные версии записываются в
+// schema_version, initDB применяет недостающие по порядку, каждую в своей
+// транзакции; ошибка миграции останавливает запуск, а не теряется молча.
+// Опубликованные миграции не меняются: новый столбец или таблица – это новая
+// миграция в конце списка.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// schemaVersionSchema – примененные миграции
+const schemaVersionSchema = `
+CREATE TABLE IF NOT EXISTS schema_version (
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TEXT NOT NULL
+);`
+
+// measurementsSchema – таблица измерений в исходном виде; столбцы,
+// появившиеся позже, добавляются своими миграциями
+const measurementsSchema = `
+CREATE TABLE IF NOT EXISTS measurements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp TEXT NOT NULL,
+	percentage INTEGER,
+	state TEXT,
+	cycle_count INTEGER,
+	full_charge_capacity INTEGER,
+	design_capacity INTEGER,
+	current_capacity INTEGER,
+	temperature INTEGER DEFAULT 0
+);`
+
+// migration – шаг схемы с откатом
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sqlx.Tx) error
+	Down    func(tx *sqlx.Tx) error
+}
+
+// migrations – все миграции по возрастанию версии
+var migrations = []migration{
+	{1, "измерения", execSQL(measurementsSchema), dropTables("measurements")},
+	{2, "напряжение, ток и мощность", addColumns("measurements",
+		"voltage INTEGER DEFAULT 0", "amperage INTEGER DEFAULT 0", "power INTEGER DEFAULT 0", "apple_condition TEXT DEFAULT ''"),
+		dropColumns("measurements", "voltage", "amperage", "power", "apple_condition")},
+	{3, "серийный номер батареи", addColumns("measurements", "battery_serial TEXT DEFAULT ''"),
+		dropColumns("measurements", "battery_serial")},
+	{4, "расход по приложениям", steps(execSQL(appPowerSchema), addColumns("app_power_samples", "source TEXT DEFAULT 'top'")),
+		dropTables("app_power_samples")},
+	{5, "сессии", execSQL(sessionsSchema), dropTables("sessions")},
+	{6, "калибровка", execSQL(calibrationSchema), dropTables("calibration_milestones", "calibration_tests")},
+	{7, "базовая точка", execSQL(baselineSchema), dropTables("battery_baseline")},
+	{8, "пользовательские метрики", execSQL(derivedMetricsSchema), dropTables("derived_metrics")},
+	{9, "powermetrics", execSQL(powerSamplesSchema), dropTables("power_samples")},
+	{10, "яркость и крышка", addColumns("measurements", "brightness INTEGER DEFAULT 0", "lid_state TEXT DEFAULT ''"),
+		dropColumns("measurements", "brightness", "lid_state")},
+	{11, "пауза калибровки", addColumns("calibration_tests",
+		"paused_at TEXT NOT NULL DEFAULT ''", "paused_seconds INTEGER NOT NULL DEFAULT 0",
+		"recharged_percent INTEGER NOT NULL DEFAULT 0", "recharged_capacity INTEGER NOT NULL DEFAULT 0"),
+		dropColumns("calibration_tests", "paused_at", "paused_seconds", "recharged_percent", "recharged_capacity")},
+	{12, "адаптеры питания", execSQL(chargersSchema), dropTables("charger_connections")},
+	{13, "сводки по дням", execSQL(dailyUsageSchema), dropTables("daily_usage")},
+	{14, "состояние анализа", execSQL(analysisStateSchema), dropTables("analysis_state")},
+	{15, "почасовые сводки", execSQL(hourlySchema), dropTables("measurements_hourly")},
+	{16, "индексы по времени", createIndexes, dropIndexes},
+	{17, "температурные события", execSQL(thermalEventsSchema), dropTables("thermal_events")},
+	{18, "снимки состояния", execSQL(snapshotsSchema), dropTables("snapshots")},
+	{19, "напряжения ячеек", addColumns("measurements", "cell_voltages TEXT DEFAULT ''"),
+		dropColumns("measurements", "cell_voltages")},
+	{20, "вехи износа", execSQL(wearEventsSchema), dropTables("wear_events")},
+	{21, "парк машин", execSQL(fleetSchema), dropTables("fleet_status")},
+	{22, "события питания", execSQL(powerEventsSchema), dropTables("power_events")},
+	{23, "тепловое давление", execSQL(thermalSamplesSchema), dropTables("thermal_samples")},
+	{24, "сетевой контекст", execSQL(netSamplesSchema), dropTables("net_samples")},
+	{25, "заметки", execSQL(notesSchema), dropTables("notes")},
+	{26, "выданные сертификаты", execSQL(certificatesSchema), dropTables("certificates")},
+	{27, "дата изготовления в сертификатах", addColumns("certificates", "manufactured_at TEXT NOT NULL DEFAULT ''"),
+		dropColumns("certificates", "manufactured_at")},
+}
+
+// latestSchemaVersion возвращает версию последней миграции
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// migrationTitle возвращает название миграции на языке интерфейса; в
+// schema_version оно записано по-русски
+func migrationTitle(version int, name string) string {
+	return localized(fmt.Sprintf("migration.%d", version), name)
+}
+
+// steps объединяет шаги миграции
+func steps(fns ...func(tx *sqlx.Tx) error) func(tx *sqlx.Tx) error {
+	return func(tx *sqlx.Tx) error {
+		for _, fn := range fns {
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// execSQL выполняет DDL-запросы
+func execSQL(query string) func(tx *sqlx.Tx) error {
+	return func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+// dropTables удаляет таблицы
+func dropTables(tables ...string) func(tx *sqlx.Tx) error {
+	return func(tx *sqlx.Tx) error {
+		for _, table := range tables {
+			if _, err := tx.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumns добавляет столбцы, которых еще нет: базы прежних версий могли
+// получить часть из них до появления миграций
+func addColumns(table string, defs ...string) func(tx *sqlx.Tx) error {
+	return func(tx *sqlx.Tx) error {
+		existing, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, def := range defs {
+			if existing[strings.Fields(def)[0]] {
+				continue
+			}
+			if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, def)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// dropColumns удаляет столбцы, если они есть
+func dropColumns(table string, columns ...string) func(tx *sqlx.Tx) error {
+	return func(tx *sqlx.Tx) error {
+		existing, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			if !existing[column] {
+				continue
+			}
+			if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// tableColumns возвращает имена столбцов таблицы
+func tableColumns(tx *sqlx.Tx, table string) (map[string]bool, error) {
+	var names []string
+	if err := tx.Select(&names, "SELECT name FROM pragma_table_info(?)", table); err != nil {
+		return nil, err
+	}
+	columns := make(map[string]bool, len(names))
+	for _, name := range names {
+		columns[name] = true
+	}
+	return columns, nil
+}
+
+// SchemaVersion – запись о примененной миграции
+type SchemaVersion struct {
+	Version   int    `db:"version"`
+	Name      string `db:"name"`
+	AppliedAt string `db:"applied_at"`
+}
+
+// currentSchemaVersion возвращает версию схемы БД (0 – миграции не применялись)
+func currentSchemaVersion(db *sqlx.DB) (int, error) {
+	if _, err := db.Exec(schemaVersionSchema); err != nil {
+		return 0, fmt.Errorf(T("migrate.err.table"), err)
+	}
+	var version int
+	if err := db.Get(&version, "SELECT COALESCE(MAX(version), 0) FROM schema_version"); err != nil {
+		return 0, fmt.Errorf(T("migrate.err.version"), err)
+	}
+	return version, nil
+}
+
+// getSchemaVersions возвращает примененные миграции по порядку
+func getSchemaVersions(db *sqlx.DB) ([]SchemaVersion, error) {
+	var versions []SchemaVersion
+	if err := db.Select(&versions, "SELECT * FROM schema_version ORDER BY version"); err != nil {
+		return nil, fmt.Errorf(T("migrate.err.versions"), err)
+	}
+	return versions, nil
+}
+
+// migrateUp применяет недостающие миграции. База от более новой версии
+// программы не трогается: старый код не знает ее столбцов.
+func migrateUp(db *sqlx.DB) error {
+	current, err := currentSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > latestSchemaVersion() {
+		return fmt.Errorf(T("migrate.err.newer"), current, latestSchemaVersion())
+	}
+	return migrateTo(db, latestSchemaVersion())
+}
+
+// migrateTo приводит схему к версии target: вперед – миграциями Up, назад – Down
+func migrateTo(db *sqlx.DB, target int) error {
+	current, err := currentSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if target < 0 || target > latestSchemaVersion() {
+		return fmt.Errorf(T("migrate.err.unknown"), target, latestSchemaVersion())
+	}
+	if target >= current {
+		for _, m := range migrations {
+			if m.Version <= current || m.Version > target {
+				continue
+			}
+			err := runMigration(db, m, m.Up, func(tx *sqlx.Tx) error {
+				_, err := tx.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+					m.Version, m.Name, time.Now().UTC().Format(time.RFC3339))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > current || m.Version <= target {
+			continue
+		}
+		err := runMigration(db, m, m.Down, func(tx *sqlx.Tx) error {
+			_, err := tx.Exec("DELETE FROM schema_version WHERE version = ?", m.Version)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runMigration выполняет шаг и запись о версии в одной транзакции
+func runMigration(db *sqlx.DB, m migration, step, record func(tx *sqlx.Tx) error) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf(T("migrate.err.step"), m.Version, m.Name, err)
+	}
+	if err := step(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf(T("migrate.err.step"), m.Version, m.Name, err)
+	}
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf(T("migrate.err.record"), m.Version, m.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf(T("migrate.err.step"), m.Version, m.Name, err)
+	}
+	return nil
+}
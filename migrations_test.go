@@ -0,0 +1,117 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// legacySchema – база batmon до версионированных миграций: одна таблица
+// measurements и никакой schema_version
+const legacySchema = `CREATE TABLE measurements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp TEXT NOT NULL,
+	percentage INTEGER,
+	state TEXT,
+	cycle_count INTEGER,
+	full_charge_capacity INTEGER,
+	design_capacity INTEGER,
+	current_capacity INTEGER,
+	temperature INTEGER DEFAULT 0,
+	voltage INTEGER DEFAULT 0,
+	amperage INTEGER DEFAULT 0,
+	power INTEGER DEFAULT 0,
+	apple_condition TEXT DEFAULT ''
+);
+INSERT INTO measurements (timestamp, percentage, state, cycle_count, full_charge_capacity, design_capacity, current_capacity)
+VALUES ('2024-01-15T10:00:00Z', 80, 'discharging', 300, 4500, 5000, 3600);`
+
+// schemaSnapshot – таблицы с их столбцами и примененные миграции
+type schemaSnapshot struct {
+	Tables   map[string][]string
+	Versions []SchemaVersion
+}
+
+func takeSchemaSnapshot(t *testing.T, db *sqlx.DB) schemaSnapshot {
+	t.Helper()
+	var tables []string
+	if err := db.Select(&tables, `SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`); err != nil {
+		t.Fatal(err)
+	}
+	s := schemaSnapshot{Tables: map[string][]string{}}
+	for _, table := range tables {
+		var columns []string
+		if err := db.Select(&columns, `SELECT name FROM pragma_table_info(?) ORDER BY name`, table); err != nil {
+			t.Fatal(err)
+		}
+		s.Tables[table] = columns
+	}
+	versions, err := getSchemaVersions(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Versions = versions
+	return s
+}
+
+func TestMigrations(t *testing.T) {
+	dir := t.TempDir()
+
+	empty, err := initDB(filepath.Join(dir, "empty.sqlite"))
+	if err != nil {
+		t.Fatalf("пустая база: %v", err)
+	}
+	defer empty.Close()
+	fresh := takeSchemaSnapshot(t, empty)
+	if len(fresh.Versions) != len(migrations) {
+		t.Fatalf("применено %d миграций из %d", len(fresh.Versions), len(migrations))
+	}
+	for i, v := range fresh.Versions {
+		if v.Version != migrations[i].Version || v.Name != migrations[i].Name {
+			t.Errorf("миграция %d: %+v, ожидалась %d %q", i, v, migrations[i].Version, migrations[i].Name)
+		}
+	}
+
+	path := filepath.Join(dir, "baseline.sqlite")
+	old, err := sqlx.Connect("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Exec(legacySchema); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+	baseline, err := initDB(path)
+	if err != nil {
+		t.Fatalf("база исходной схемы: %v", err)
+	}
+	defer baseline.Close()
+	if version, err := currentSchemaVersion(baseline); err != nil || version != latestSchemaVersion() {
+		t.Fatalf("версия после миграции %d, %v", version, err)
+	}
+	upgraded := takeSchemaSnapshot(t, baseline)
+	if !reflect.DeepEqual(upgraded.Tables, fresh.Tables) {
+		t.Errorf("схема после обновления отличается от новой базы:\n%v\n%v", upgraded.Tables, fresh.Tables)
+	}
+	var m Measurement
+	if err := baseline.Get(&m, `SELECT * FROM measurements`); err != nil {
+		t.Fatalf("измерение исходной схемы: %v", err)
+	}
+	if m.Timestamp != "2024-01-15T10:00:00Z" || m.FullChargeCap != 4500 || m.BatterySerial != "" || m.LidState != "" {
+		t.Errorf("измерение после миграции: %+v", m)
+	}
+
+	// Повторный запуск ничего не меняет, в том числе время применения
+	for name, db := range map[string]*sqlx.DB{"новая": empty, "обновленная": baseline} {
+		before := takeSchemaSnapshot(t, db)
+		if err := migrateUp(db); err != nil {
+			t.Fatalf("%s: повторная миграция: %v", name, err)
+		}
+		if after := takeSchemaSnapshot(t, db); !reflect.DeepEqual(after, before) {
+			t.Errorf("%s: повторная миграция изменила схему", name)
+		}
+	}
+}
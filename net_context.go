@@ -0,0 +1,275 @@
+// net_context.go
+//
+// Контекст сетевой активности: грубая скорость обмена по сети (сумма по
+// всем интерфейсам, кроме loopback) и число подключенных устройств
+// Bluetooth. Записывается рядом с измерениями, только если в config.json
+// включен collector.net_context: по трафику можно судить о том, чем занят
+// Mac, поэтому по умолчанию он не собирается. Отчет добавляет эти данные к
+// аномалиям расхода – «резкое падение заряда, совпало с обменом по сети
+// 300 МБ/с» – чтобы всплеск разряда не принимали за проблему батареи.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	bluetoothInterval = 5 * time.Minute // system_profiler медленный, число устройств обновляем реже
+	netBusyRate       = 5 << 20         // обмен по сети, с которого он упоминается в аномалиях, Б/с
+)
+
+// netSamplesSchema – контекст сетевой активности по измерениям
+const netSamplesSchema = `
+CREATE TABLE IF NOT EXISTS net_samples (
+	timestamp TEXT PRIMARY KEY,
+	rx_rate REAL NOT NULL DEFAULT 0,
+	tx_rate REAL NOT NULL DEFAULT 0,
+	bt_devices INTEGER NOT NULL DEFAULT -1
+);`
+
+// NetSample – сетевая активность в момент измерения
+type NetSample struct {
+	Timestamp string  `db:"timestamp"`
+	RxRate    float64 `db:"rx_rate"`    // прием, Б/с
+	TxRate    float64 `db:"tx_rate"`    // передача, Б/с
+	BTDevices int     `db:"bt_devices"` // подключенных устройств Bluetooth, -1 – неизвестно
+}
+
+// Rate возвращает суммарную скорость обмена, Б/с
+func (s NetSample) Rate() float64 {
+	return s.RxRate + s.TxRate
+}
+
+// netCounters – счетчики байт интерфейсов с момента загрузки
+type netCounters struct {
+	rx, tx uint64
+}
+
+// netContextSampler считает скорость по разнице счетчиков между выборками
+// и кэширует число устройств Bluetooth
+type netContextSampler struct {
+	last     netCounters
+	lastAt   time.Time
+	bt       int
+	btAt     time.Time
+	disabled bool // счетчики на этой платформе недоступны
+}
+
+// enabled сообщает, включен ли сбор сетевого контекста
+func (s *netContextSampler) enabled() bool {
+	return !s.disabled && getConfig().Collector.NetContext
+}
+
+// sample снимает выборку; первая выборка только запоминает счетчики
+func (s *netContextSampler) sample(timestamp string) (*NetSample, error) {
+	counters, err := readNetCounters()
+	if err != nil {
+		s.disabled = true
+		return nil, fmt.Errorf(T("net.err.disabled"), err)
+	}
+	now := timeNow()
+	prev, prevAt := s.last, s.lastAt
+	s.last, s.lastAt = counters, now
+	if prevAt.IsZero() || counters.rx < prev.rx || counters.tx < prev.tx {
+		return nil, nil // нет предыдущей выборки или счетчики сброшены
+	}
+	elapsed := now.Sub(prevAt).Seconds()
+	if elapsed <= 0 {
+		return nil, nil
+	}
+	if now.Sub(s.btAt) >= bluetoothInterval {
+		s.btAt = now
+		s.bt = countBluetoothDevices()
+	}
+	return &NetSample{
+		Timestamp: timestamp,
+		RxRate:    float64(counters.rx-prev.rx) / elapsed,
+		TxRate:    float64(counters.tx-prev.tx) / elapsed,
+		BTDevices: s.bt,
+	}, nil
+}
+
+// readNetCounters суммирует счетчики байт всех интерфейсов, кроме loopback
+func readNetCounters() (netCounters, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		out, err := runCommand("netstat", "-ib")
+		if err != nil {
+			return netCounters{}, fmt.Errorf("netstat: %w", err)
+		}
+		return parseNetstatBytes(out), nil
+	case "linux":
+		raw, err := os.ReadFile("/proc/net/dev")
+		if err != nil {
+			return netCounters{}, err
+		}
+		return parseProcNetDev(raw), nil
+	}
+	return netCounters{}, fmt.Errorf(T("net.err.unsupported"), runtime.GOOS)
+}
+
+// parseNetstatBytes разбирает netstat -ib: берутся строки уровня канала
+// (<Link#N>), по одной на интерфейс. Адреса может не быть, поэтому байты
+// отсчитываются с конца строки: … Ibytes Opkts Oerrs Obytes Coll.
+func parseNetstatBytes(out []byte) netCounters {
+	var c netCounters
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || !strings.HasPrefix(fields[2], "<Link#") || strings.HasPrefix(fields[0], "lo") {
+			continue
+		}
+		rx, err1 := strconv.ParseUint(fields[len(fields)-5], 10, 64)
+		tx, err2 := strconv.ParseUint(fields[len(fields)-2], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		c.rx += rx
+		c.tx += tx
+	}
+	return c
+}
+
+// parseProcNetDev разбирает /proc/net/dev: «eth0: rx_bytes … (8 полей) tx_bytes …»
+func parseProcNetDev(raw []byte) netCounters {
+	var c netCounters
+	for _, line := range strings.Split(string(raw), "\n") {
+		name, stats, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		fields := strings.Fields(stats)
+		if len(fields) < 9 {
+			continue
+		}
+		rx, err1 := strconv.ParseUint(fields[0], 10, 64)
+		tx, err2 := strconv.ParseUint(fields[8], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		c.rx += rx
+		c.tx += tx
+	}
+	return c
+}
+
+// countBluetoothDevices возвращает число подключенных устройств Bluetooth
+// (только на macOS) или -1. Имена устройств не сохраняются.
+func countBluetoothDevices() int {
+	if runtime.GOOS != "darwin" {
+		return -1
+	}
+	out, err := runCommand("system_profiler", "SPBluetoothDataType", "-json")
+	if err != nil {
+		return -1
+	}
+	return parseBluetoothDevices(out)
+}
+
+// parseBluetoothDevices считает устройства в device_connected вывода
+// system_profiler SPBluetoothDataType -json
+func parseBluetoothDevices(out []byte) int {
+	var doc struct {
+		Bluetooth []struct {
+			Connected []json.RawMessage `json:"device_connected"`
+		} `json:"SPBluetoothDataType"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil || len(doc.Bluetooth) == 0 {
+		return -1
+	}
+	n := 0
+	for _, controller := range doc.Bluetooth {
+		n += len(controller.Connected)
+	}
+	return n
+}
+
+// insertNetSample сохраняет выборку сетевого контекста
+func insertNetSample(db *sqlx.DB, s NetSample) error {
+	_, err := db.NamedExec(`INSERT OR REPLACE INTO net_samples (timestamp, rx_rate, tx_rate, bt_devices)
+		VALUES (:timestamp, :rx_rate, :tx_rate, :bt_devices)`, s)
+	if err != nil {
+		return fmt.Errorf(T("net.err.save"), err)
+	}
+	return nil
+}
+
+// getNetSamples возвращает выборки за время измерений по возрастанию
+func getNetSamples(db *sqlx.DB, ms []Measurement) ([]NetSample, error) {
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	var samples []NetSample
+	if err := db.Select(&samples, `SELECT * FROM net_samples WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp`,
+		ms[0].Timestamp, ms[len(ms)-1].Timestamp); err != nil {
+		return nil, fmt.Errorf(T("net.err.read"), err)
+	}
+	return samples, nil
+}
+
+// netContextNote возвращает пояснение к аномалии расхода по выборкам ее
+// окна: пиковый обмен по сети, если он заметный, и число устройств Bluetooth
+func netContextNote(samples []NetSample, start, end time.Time) (string, float64, int) {
+	var peak float64
+	bt := -1
+	for _, s := range samples {
+		at := parseStoredTime(s.Timestamp)
+		if at.Before(start) || at.After(end) {
+			continue
+		}
+		peak = math.Max(peak, s.Rate())
+		bt = max(bt, s.BTDevices)
+	}
+	var notes []string
+	if peak >= netBusyRate {
+		notes = append(notes, T("net_context.traffic", formatBytes(uint64(peak))))
+	}
+	if bt > 0 {
+		notes = append(notes, T("net_context.bluetooth", bt))
+	}
+	if len(notes) == 0 {
+		return "", peak, bt
+	}
+	return ", " + strings.Join(notes, ", "), peak, bt
+}
+
+// explainNetContext дополняет аномалии расхода сетевым контекстом их окна.
+// Исходный срез не меняется.
+func explainNetContext(anomalies []Anomaly, samples []NetSample) []Anomaly {
+	if len(samples) == 0 {
+		return anomalies
+	}
+	result := make([]Anomaly, len(anomalies))
+	for i, a := range anomalies {
+		result[i] = a
+		if a.Type != anomalyChargeDrop && a.Type != anomalyVoltageSag {
+			continue
+		}
+		note, peak, bt := netContextNote(samples, a.Start, a.End)
+		if note == "" {
+			continue
+		}
+		metrics := map[string]float64{"net_rate": peak}
+		for k, v := range a.Metrics {
+			metrics[k] = v
+		}
+		if bt >= 0 {
+			metrics["bt_devices"] = float64(bt)
+		}
+		result[i].Message += note
+		result[i].Metrics = metrics
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExplainNetContext(t *testing.T) {
+	netstat := "Name       Mtu   Network       Address            Ipkts Ierrs     Ibytes    Opkts Oerrs     Obytes  Coll\n" +
+		"lo0        16384 <Link#1>                        512     0      90000      512     0      90000     0\n" +
+		"en0        1500  <Link#6>      a1:b2:c3:d4:e5:f6  9000     0   50000000     4000     0    2000000     0\n" +
+		"en0        1500  192.168.1     192.168.1.10       9000     -   50000000     4000     -    2000000     -\n" +
+		"utun0      1380  <Link#15>                          10     0       1000       12     0       1500     0\n"
+	if c := parseNetstatBytes([]byte(netstat)); c.rx != 50001000 || c.tx != 2001500 {
+		t.Errorf("netstat -ib: прием %d, передача %d", c.rx, c.tx)
+	}
+	if n := parseBluetoothDevices([]byte(`{"SPBluetoothDataType":[{"device_connected":[{"AirPods":{}},{"Magic Mouse":{}}]}]}`)); n != 2 {
+		t.Errorf("устройств Bluetooth %d", n)
+	}
+
+	start := fixtureStart
+	drop := Anomaly{Type: anomalyChargeDrop, Start: start, End: start.Add(time.Minute), Message: "Резкое падение заряда",
+		Metrics: map[string]float64{"delta_pct": -4}}
+	thermal := Anomaly{Type: anomalyThermal, Start: start, End: start.Add(time.Minute), Message: "Перегрев"}
+	samples := []NetSample{
+		{Timestamp: start.Add(time.Minute).UTC().Format(time.RFC3339), RxRate: 300 << 20, BTDevices: 2},
+		{Timestamp: start.Add(time.Hour).UTC().Format(time.RFC3339), RxRate: 900 << 20, BTDevices: 5},
+	}
+	anomalies := []Anomaly{drop, thermal}
+	got := explainNetContext(anomalies, samples)
+	if want := "Резкое падение заряда, совпало с обменом по сети 300.0 МБ/с, устройств Bluetooth: 2"; got[0].Message != want {
+		t.Errorf("пояснение %q, ожидалось %q", got[0].Message, want)
+	}
+	if got[0].Metrics["delta_pct"] != -4 || got[0].Metrics["bt_devices"] != 2 {
+		t.Errorf("метрики %v", got[0].Metrics)
+	}
+	if got[1].Message != "Перегрев" || anomalies[0].Message != "Резкое падение заряда" {
+		t.Errorf("изменены не те аномалии: %q, %q", got[1].Message, anomalies[0].Message)
+	}
+}
@@ -0,0 +1,328 @@
+// notes.go
+//
+// Заметки пользователя к моменту, промежутку времени или сессии: «поставил
+// macOS 15.2», «заменил батарею», «весь день собирал проект в Xcode». Без
+// них через несколько месяцев уже не вспомнить, почему в истории провал
+// или скачок. Заметки хранятся в таблице notes, отмечаются под графиком
+// заряда и попадают в отчеты и выгрузки. Добавляются командой batmon note.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jmoiron/sqlx"
+)
+
+const noteGlyph = "✎" // отметка заметки под графиком
+
+// notesSchema – заметки пользователя
+const notesSchema = `
+CREATE TABLE IF NOT EXISTS notes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	start_time TEXT NOT NULL,
+	end_time TEXT NOT NULL,
+	text TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_notes_start_time ON notes(start_time);`
+
+// Note – заметка к моменту или промежутку времени
+type Note struct {
+	ID        int    `db:"id" json:"id"`
+	StartTime string `db:"start_time" json:"start_time"`
+	EndTime   string `db:"end_time" json:"end_time"` // совпадает с start_time у заметки к моменту
+	Text      string `db:"text" json:"text"`
+	CreatedAt string `db:"created_at" json:"created_at"`
+}
+
+// Start возвращает начало заметки в местном времени
+func (n Note) Start() time.Time {
+	return parseStoredTime(n.StartTime).Local()
+}
+
+// End возвращает конец заметки в местном времени
+func (n Note) End() time.Time {
+	return parseStoredTime(n.EndTime).Local()
+}
+
+// Period возвращает время заметки: «02.01 15:04» или «02.01 10:00–18:00»
+func (n Note) Period() string {
+	start, end := n.Start(), n.End()
+	switch {
+	case !end.After(start):
+		return start.Format("02.01 15:04")
+	case sameDay(start, end):
+		return start.Format("02.01 15:04") + "–" + end.Format("15:04")
+	}
+	return start.Format("02.01 15:04") + " – " + end.Format("02.01 15:04")
+}
+
+// String возвращает строку заметки для отчетов
+func (n Note) String() string {
+	return n.Period() + ": " + n.Text
+}
+
+// covers сообщает, относится ли заметка к моменту t (заметка к моменту –
+// с точностью до минуты)
+func (n Note) covers(t time.Time) bool {
+	start, end := n.Start(), n.End()
+	if !end.After(start) {
+		start, end = start.Add(-time.Minute), end.Add(time.Minute)
+	}
+	return !t.Before(start) && !t.After(end)
+}
+
+// sameDay сообщает, приходятся ли моменты на один календарный день
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// insertNote сохраняет заметку и возвращает ее номер
+func insertNote(db *sqlx.DB, n Note) (int64, error) {
+	res, err := db.NamedExec(`INSERT INTO notes (start_time, end_time, text, created_at)
+		VALUES (:start_time, :end_time, :text, :created_at)`, n)
+	if err != nil {
+		return 0, fmt.Errorf(T("note.err.save"), err)
+	}
+	return res.LastInsertId()
+}
+
+// getNotes возвращает заметки, пересекающиеся с периодом, по возрастанию;
+// пустой To – до текущего момента, пустой период – все заметки
+func getNotes(db *sqlx.DB, rng ReportRange) ([]Note, error) {
+	var notes []Note
+	var err error
+	switch {
+	case rng.IsZero():
+		err = db.Select(&notes, `SELECT * FROM notes ORDER BY start_time, id`)
+	case rng.To.IsZero():
+		err = db.Select(&notes, `SELECT * FROM notes WHERE end_time >= ? ORDER BY start_time, id`,
+			rng.From.UTC().Format(time.RFC3339))
+	default:
+		err = db.Select(&notes, `SELECT * FROM notes WHERE end_time >= ? AND start_time <= ? ORDER BY start_time, id`,
+			rng.From.UTC().Format(time.RFC3339), rng.To.UTC().Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("note.err.read"), err)
+	}
+	return notes, nil
+}
+
+// GetNotes возвращает заметки видимого окна графика
+func (ds *DataService) GetNotes(view ChartView) []Note {
+	since, until := view.Range(ds.Now())
+	notes, err := getNotes(ds.db, ReportRange{From: since, To: until})
+	if err != nil {
+		return nil
+	}
+	return notes
+}
+
+// notesDuring возвращает заметки, пересекающиеся с промежутком
+func notesDuring(notes []Note, start, end time.Time) []Note {
+	var result []Note
+	for _, n := range notes {
+		if !n.End().Before(start) && !n.Start().After(end) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// noteChartEvents переводит заметки в отметки графика. Заметка, начатая до
+// окна графика, отмечается в его начале.
+func noteChartEvents(notes []Note, from time.Time) []ChartEvent {
+	marks := make([]ChartEvent, 0, len(notes))
+	for _, n := range notes {
+		at := n.Start()
+		if at.Before(from) {
+			at = from
+		}
+		marks = append(marks, ChartEvent{At: at, Glyph: noteGlyph, Color: theme.Highlight})
+	}
+	return marks
+}
+
+// notesCursorLine возвращает заметки в точке перекрестья графика
+func notesCursorLine(notes []Note, t time.Time) string {
+	var texts []string
+	for _, n := range notes {
+		if n.covers(t) {
+			texts = append(texts, n.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(theme.Highlight).Render(noteGlyph + " " + strings.Join(texts, "; "))
+}
+
+// sessionNoteRange возвращает время сессии по ее номеру
+func sessionNoteRange(db *sqlx.DB, id int) (start, end string, err error) {
+	var s SessionRecord
+	if err := db.Get(&s, `SELECT * FROM sessions WHERE id = ?`, id); err != nil {
+		return "", "", fmt.Errorf(T("note.err.no_session"), id)
+	}
+	return s.StartTime, s.EndTime, nil
+}
+
+// runNoteCommand добавляет, показывает и удаляет заметки
+func runNoteCommand(args []string) error {
+	fs := newCommandFlags("note")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	action, rest := "list", fs.Args()
+	if len(rest) > 0 {
+		action, rest = rest[0], rest[1:]
+	}
+	if action != "add" && action != "list" && action != "delete" {
+		fmt.Fprintln(os.Stderr, T("note.unknown_action", action))
+		return errUsage
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	switch action {
+	case "add":
+		return runNoteAdd(db, rest)
+	case "delete":
+		id, err := strconv.Atoi(fs.Arg(1))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, T("note.delete.usage"))
+			return errUsage
+		}
+		res, err := db.Exec(`DELETE FROM notes WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf(T("note.err.delete"), err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf(T("note.err.not_found"), id)
+		}
+		fmt.Println(T("note.deleted", id))
+		return nil
+	}
+	return runNoteList(db, rest)
+}
+
+// runNoteAdd добавляет заметку: к текущему моменту, к --at, к промежутку
+// --from/--to или ко времени сессии --session
+func runNoteAdd(db *sqlx.DB, args []string) error {
+	fs := newCommandFlags("note add")
+	at := fs.String("at", "", localized("flag.note.add.at", "момент: 14:00, 2025-01-31 или \"2025-01-31 18:00\" (по умолчанию – сейчас)"))
+	reportRange := addRangeFlags(fs)
+	session := fs.Int("session", 0, localized("flag.note.add.session", "номер сессии разрядки или зарядки"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	text := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if text == "" {
+		fmt.Fprintln(os.Stderr, T("note.add.usage"))
+		return errUsage
+	}
+
+	now := time.Now()
+	note := Note{Text: text, CreatedAt: now.UTC().Format(time.RFC3339)}
+	rng, err := reportRange()
+	if err != nil {
+		return err
+	}
+	switch {
+	case *session > 0:
+		if note.StartTime, note.EndTime, err = sessionNoteRange(db, *session); err != nil {
+			return err
+		}
+	case !rng.IsZero():
+		to := rng.To
+		if to.IsZero() {
+			to = now
+		}
+		note.StartTime, note.EndTime = rng.From.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)
+	default:
+		t, err := parseReportTime(*at, now, false)
+		if err != nil {
+			return fmt.Errorf("--at: %w", err)
+		}
+		if t.IsZero() {
+			t = now
+		}
+		note.StartTime = t.UTC().Format(time.RFC3339)
+		note.EndTime = note.StartTime
+	}
+
+	id, err := insertNote(db, note)
+	if err != nil {
+		return err
+	}
+	note.ID = int(id)
+	fmt.Println(T("note.added", noteGlyph, note.ID, note))
+	return nil
+}
+
+// runNoteList выводит заметки периода
+func runNoteList(db *sqlx.DB, args []string) error {
+	fs := newCommandFlags("note list")
+	reportRange := addRangeFlags(fs)
+	asJSON := fs.Bool("json", false, localized("flag.note.list.json", "вывести заметки в JSON"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	rng, err := reportRange()
+	if err != nil {
+		return err
+	}
+	notes, err := getNotes(db, rng)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if notes == nil {
+			notes = []Note{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(notes)
+	}
+	if len(notes) == 0 {
+		fmt.Println(T("note.empty"))
+		return nil
+	}
+	for _, n := range notes {
+		fmt.Printf("%4d  %s\n", n.ID, n)
+	}
+	return nil
+}
+
+// noteChartMarkers подписывает заметки под текстовым графиком заряда отчета
+// так же, как replacementChartMarker – замену батареи
+func noteChartMarkers(chartData []Measurement, notes []Note, width int) string {
+	if len(chartData) == 0 || len(notes) == 0 {
+		return ""
+	}
+	first := parseStoredTime(chartData[0].Timestamp)
+	last := parseStoredTime(chartData[len(chartData)-1].Timestamp)
+	step := float64(width) / float64(len(chartData))
+	var lines []string
+	for _, n := range notesDuring(notes, first, last) {
+		j := 0
+		for j < len(chartData)-1 && parseStoredTime(chartData[j].Timestamp).Before(n.Start()) {
+			j++
+		}
+		x := min(int(float64(j)*step), width-1)
+		lines = append(lines, fmt.Sprintf("      %*s%s %s", x, "", noteGlyph, n))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotes(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(48*time.Hour))
+	db := newTestDB(t)
+	at := func(h int) string { return fixtureStart.Add(time.Duration(h) * time.Hour).UTC().Format(time.RFC3339) }
+	for _, n := range []Note{
+		{StartTime: at(1), EndTime: at(1), Text: "поставил macOS 15.2"},
+		{StartTime: at(3), EndTime: at(9), Text: "сборка в Xcode"},
+		{StartTime: at(30), EndTime: at(30), Text: "заменил батарею"},
+	} {
+		if _, err := insertNote(db, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from, to := fixtureStart.Add(5*time.Hour), fixtureStart.Add(20*time.Hour)
+	notes, err := getNotes(db, ReportRange{From: from, To: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].Text != "сборка в Xcode" {
+		t.Fatalf("заметки периода: %v", notes)
+	}
+	if want := fixtureStart.Add(3*time.Hour).Format("02.01 15:04") + "–" + fixtureStart.Add(9*time.Hour).Format("15:04"); notes[0].Period() != want {
+		t.Errorf("период %q, ожидался %q", notes[0].Period(), want)
+	}
+
+	all, err := getNotes(db, ReportRange{})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("все заметки: %v, %v", all, err)
+	}
+	if got := notesDuring(all, fixtureStart, fixtureStart.Add(2*time.Hour)); len(got) != 1 || got[0].Text != "поставил macOS 15.2" {
+		t.Errorf("заметки сессии: %v", got)
+	}
+	if line := notesCursorLine(all, fixtureStart.Add(4*time.Hour)); !strings.Contains(line, "сборка в Xcode") {
+		t.Errorf("строка перекрестья %q", line)
+	}
+	if line := notesCursorLine(all, fixtureStart.Add(12*time.Hour)); line != "" {
+		t.Errorf("заметка вне своего времени: %q", line)
+	}
+	if marks := noteChartEvents(all[1:2], from); !marks[0].At.Equal(from) {
+		t.Errorf("отметка начатой до окна заметки: %v", marks[0].At)
+	}
+}
@@ -0,0 +1,103 @@
+// platform.go
+//
+// Платформенные абстракции поверх системных утилит: предотвращение
+// засыпания во время измерений, список утилит для диагностики, версия ОС
+// и системные уведомления.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// windowsKeepAwakeScript удерживает систему от засыпания, пока жив процесс
+// PowerShell (ES_CONTINUOUS | ES_SYSTEM_REQUIRED). Дисплей при этом гаснет.
+const windowsKeepAwakeScript = `$sig = '[DllImport("kernel32.dll")] public static extern uint SetThreadExecutionState(uint f);'
+$k = Add-Type -MemberDefinition $sig -Name Power -Namespace BatMon -PassThru
+$k::SetThreadExecutionState([uint32]"0x80000001") | Out-Null
+while ($true) { Start-Sleep -Seconds 60 }`
+
+// sleepInhibitorCommand возвращает команду, которая не даёт системе уснуть
+// от бездействия, пока запущена. ok=false, если способа на этой ОС нет.
+func sleepInhibitorCommand() (name string, args []string, ok bool) {
+	switch runtime.GOOS {
+	case "darwin":
+		// -i предотвращает idle-засыпание, но не мешает засыпанию при закрытии крышки;
+		// -w завершает caffeinate вместе с batmon, даже если тот убит
+		return "caffeinate", []string{"-i", "-w", strconv.Itoa(os.Getpid())}, true
+	case "linux":
+		return "systemd-inhibit", []string{"--what=idle", "--who=batmon", "--why=" + T("platform.inhibit_why"), "sleep", "infinity"}, true
+	case "windows":
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", windowsKeepAwakeScript}, true
+	default:
+		return "", nil, false
+	}
+}
+
+// platformTools возвращает системные утилиты, от которых зависит сбор данных на этой ОС
+func platformTools() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"pmset", "ioreg", "system_profiler", "caffeinate"}
+	case "linux":
+		return []string{"systemd-inhibit"}
+	case "windows":
+		return []string{"powershell"}
+	default:
+		return nil
+	}
+}
+
+// osVersion возвращает название и версию ОС, например "macOS 14.5".
+// Если версию узнать не удалось, возвращается runtime.GOOS.
+func osVersion() string {
+	switch runtime.GOOS {
+	case "darwin":
+		if out, err := exec.Command("sw_vers", "-productVersion").Output(); err == nil {
+			return "macOS " + strings.TrimSpace(string(out))
+		}
+	case "linux":
+		if raw, err := os.ReadFile("/etc/os-release"); err == nil {
+			if name := parseOSRelease(raw); name != "" {
+				return name
+			}
+		}
+	case "windows":
+		if out, err := exec.Command("cmd", "/c", "ver").Output(); err == nil {
+			if v := strings.TrimSpace(string(out)); v != "" {
+				return v
+			}
+		}
+	}
+	return runtime.GOOS
+}
+
+// parseOSRelease извлекает PRETTY_NAME из /etc/os-release
+func parseOSRelease(raw []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "PRETTY_NAME="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
+
+// notifyUser показывает системное уведомление: osascript на macOS,
+// notify-send на Linux. Уведомления необязательны, ошибки игнорируются.
+func notifyUser(title, text string) {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", text, title)
+		runCommand("osascript", "-e", script)
+	case "linux":
+		runCommand("notify-send", title, text)
+	}
+}
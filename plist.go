@@ -0,0 +1,174 @@
+// plist.go
+//
+// Минимальный декодер XML plist (вывод `ioreg -a`, `pmset -g ... -a` и
+// подобных). Значения превращаются в обычные типы Go: dict – map[string]any,
+// array – []any, integer – int64, real – float64, true/false – bool,
+// string/date – string, data – []byte. Зависимость ради одного формата не
+// нужна, а encoding/xml дает все необходимое.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// decodePlist разбирает XML plist и возвращает корневое значение
+func decodePlist(data []byte) (any, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New(T("plist.err.no_value"))
+			}
+			return nil, fmt.Errorf("plist: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local != "plist" {
+			return decodePlistValue(dec, start)
+		}
+	}
+}
+
+// decodePlistValue разбирает значение, открытое элементом start
+func decodePlistValue(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := map[string]any{}
+		var key string
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("plist dict: %w", err)
+			}
+			switch t := tok.(type) {
+			case xml.EndElement:
+				return dict, nil
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if key, err = plistText(dec); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				v, err := decodePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = v
+			}
+		}
+	case "array":
+		var arr []any
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("plist array: %w", err)
+			}
+			switch t := tok.(type) {
+			case xml.EndElement:
+				return arr, nil
+			case xml.StartElement:
+				v, err := decodePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+		}
+	case "true", "false":
+		if err := dec.Skip(); err != nil {
+			return nil, fmt.Errorf("plist %s: %w", start.Name.Local, err)
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	text, err := plistText(dec)
+	if err != nil {
+		return nil, err
+	}
+	switch start.Name.Local {
+	case "integer":
+		// ioreg печатает отрицательные числа как uint64 в дополнительном коде
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n, nil
+		}
+		n, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("plist integer %q: %w", text, err)
+		}
+		return int64(n), nil
+	case "real":
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("plist real %q: %w", text, err)
+		}
+		return f, nil
+	case "data":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, fmt.Errorf("plist data: %w", err)
+		}
+		return b, nil
+	default: // string, date
+		return text, nil
+	}
+}
+
+// plistText читает текст элемента до его закрытия
+func plistText(dec *xml.Decoder) (string, error) {
+	var sb strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("plist: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			sb.Write(t)
+		case xml.EndElement:
+			return strings.TrimSpace(sb.String()), nil
+		}
+	}
+}
+
+// plistInt возвращает целое значение ключа словаря
+func plistInt(dict map[string]any, key string) (int, bool) {
+	switch v := dict[key].(type) {
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// plistString возвращает строковое значение ключа словаря
+func plistString(dict map[string]any, key string) string {
+	s, _ := dict[key].(string)
+	return s
+}
+
+// plistInts возвращает массив целых значений ключа словаря
+func plistInts(dict map[string]any, key string) []int {
+	arr, _ := dict[key].([]any)
+	var out []int
+	for _, v := range arr {
+		if n, ok := v.(int64); ok {
+			out = append(out, int(n))
+		}
+	}
+	return out
+}
@@ -0,0 +1,399 @@
+// power_events.go
+//
+// События питания из журнала pmset -g log: засыпания, пробуждения (в том
+// числе темные – DarkWake, когда Mac просыпается для фоновых задач с
+// закрытой крышкой) и смена источника питания. Коллектор раз в час
+// дочитывает журнал в таблицу power_events. События отмечаются под графиком
+// заряда, а отчет сопоставляет их с периодами сна: большая потеря заряда во
+// сне почти всегда объясняется частыми пробуждениями, и причина пробуждений
+// указывает на виновника.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	powerLogInterval = time.Hour                   // как часто дочитывать журнал pmset
+	powerLogLayout   = "2006-01-02 15:04:05 -0700" // время в начале строки журнала
+	sleepWakesShown  = 5                           // периодов сна с пробуждениями в отчете
+	wakeReasonsShown = 5                           // причин пробуждения в отчете
+)
+
+// Виды событий питания
+const (
+	powerEventSleep    = "sleep"
+	powerEventWake     = "wake"
+	powerEventDarkWake = "darkwake"
+	powerEventSource   = "power" // смена источника питания
+)
+
+// powerEventsSchema – события питания из журнала pmset
+const powerEventsSchema = `
+CREATE TABLE IF NOT EXISTS power_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	percentage INTEGER NOT NULL DEFAULT -1,
+	on_battery INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (timestamp, kind)
+);
+CREATE INDEX IF NOT EXISTS idx_power_events_timestamp ON power_events(timestamp);`
+
+// PowerEvent – событие питания
+type PowerEvent struct {
+	ID         int    `db:"id" json:"id"`
+	Timestamp  string `db:"timestamp" json:"timestamp"`
+	Kind       string `db:"kind" json:"kind"`
+	Reason     string `db:"reason" json:"reason,omitempty"` // причина сна или пробуждения из журнала
+	Percentage int    `db:"percentage" json:"percentage"`   // заряд в момент события, -1 – неизвестен
+	OnBattery  bool   `db:"on_battery" json:"on_battery"`
+}
+
+// Time возвращает момент события в местном времени
+func (e PowerEvent) Time() time.Time {
+	return parseStoredTime(e.Timestamp).Local()
+}
+
+// Glyph возвращает отметку события под графиком: один символ в колонку
+func (e PowerEvent) Glyph() string {
+	switch e.Kind {
+	case powerEventSleep:
+		return "z"
+	case powerEventWake:
+		return "↑"
+	case powerEventDarkWake:
+		return "·"
+	}
+	return "ϟ"
+}
+
+// Label возвращает название события
+func (e PowerEvent) Label() string {
+	switch e.Kind {
+	case powerEventSleep:
+		return T("power_event.sleep")
+	case powerEventWake:
+		return T("power_event.wake")
+	case powerEventDarkWake:
+		return T("power_event.dark_wake")
+	}
+	if e.OnBattery {
+		return T("power_event.battery")
+	}
+	return T("power_event.ac")
+}
+
+// String возвращает строку события для списков
+func (e PowerEvent) String() string {
+	s := e.Time().Format("02.01 15:04") + " " + e.Glyph() + " " + e.Label()
+	if e.Percentage >= 0 {
+		s += fmt.Sprintf(" (%d%%)", e.Percentage)
+	}
+	if e.Reason != "" {
+		s += ": " + e.Reason
+	}
+	return s
+}
+
+// ChartEvent возвращает отметку события для графика заряда
+func (e PowerEvent) ChartEvent() ChartEvent {
+	color := theme.Muted
+	switch e.Kind {
+	case powerEventSleep:
+		color = theme.Info
+	case powerEventWake:
+		color = theme.Accent
+	case powerEventSource:
+		color = theme.Good
+		if e.OnBattery {
+			color = theme.Warning
+		}
+	}
+	return ChartEvent{At: e.Time(), Glyph: e.Glyph(), Color: color, Minor: e.Kind == powerEventDarkWake}
+}
+
+var (
+	pmsetChargeRe  = regexp.MustCompile(`Charge:\s*(\d+)%`)
+	pmsetReasonEnd = regexp.MustCompile(`(?i)\s+using\s+(ac|batt)|\s*\(charge:`)
+)
+
+// pmsetKinds – строки журнала, которые превращаются в события
+var pmsetKinds = map[string]string{
+	"Sleep":    powerEventSleep,
+	"Wake":     powerEventWake,
+	"DarkWake": powerEventDarkWake,
+	"Charge":   powerEventSource,
+}
+
+// parsePmsetLog разбирает вывод pmset -g log. Строки Charge повторяются при
+// каждом изменении заряда, из них остается только смена источника питания.
+func parsePmsetLog(out []byte) []PowerEvent {
+	var events []PowerEvent
+	var sourceKnown, lastOnBattery bool
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) <= len(powerLogLayout) {
+			continue
+		}
+		at, err := time.Parse(powerLogLayout, line[:len(powerLogLayout)])
+		if err != nil {
+			continue
+		}
+		head, details, found := strings.Cut(line[len(powerLogLayout):], "\t")
+		if !found {
+			fields := strings.Fields(head)
+			if len(fields) == 0 {
+				continue
+			}
+			head, details = fields[0], strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(head), fields[0]))
+		}
+		kind, ok := pmsetKinds[strings.TrimSpace(head)]
+		if !ok {
+			continue
+		}
+
+		e := PowerEvent{
+			Timestamp:  at.UTC().Format(time.RFC3339),
+			Kind:       kind,
+			Percentage: -1,
+			OnBattery:  strings.Contains(strings.ToLower(details), "using batt"),
+		}
+		if m := pmsetChargeRe.FindStringSubmatch(details); m != nil {
+			e.Percentage, _ = strconv.Atoi(m[1])
+		}
+		if kind == powerEventSource {
+			if sourceKnown && e.OnBattery == lastOnBattery {
+				continue
+			}
+			sourceKnown, lastOnBattery = true, e.OnBattery
+		} else {
+			e.Reason = pmsetReason(details)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// pmsetReason извлекает причину из строки журнала: для сна – текст в
+// кавычках («'Clamshell Sleep'»), для пробуждения – после «due to» до
+// источника питания
+func pmsetReason(details string) string {
+	_, reason, ok := strings.Cut(details, "due to ")
+	if !ok {
+		return ""
+	}
+	if quoted, ok := strings.CutPrefix(reason, "'"); ok {
+		if i := strings.Index(quoted, "'"); i >= 0 {
+			return strings.TrimSpace(quoted[:i])
+		}
+	}
+	if loc := pmsetReasonEnd.FindStringIndex(reason); loc != nil {
+		reason = reason[:loc[0]]
+	}
+	return strings.Trim(reason, " :/")
+}
+
+// syncPowerEvents дочитывает журнал pmset: сохраняет события не старше
+// последнего записанного (одинаковые пропускаются)
+func syncPowerEvents(db *sqlx.DB) error {
+	if runtime.GOOS != "darwin" {
+		return nil // журнал pmset есть только на macOS
+	}
+	out, err := runCommand("pmset", "-g", "log")
+	if err != nil {
+		return fmt.Errorf(T("power_events.err.pmset"), err)
+	}
+	return insertPowerEvents(db, parsePmsetLog(out))
+}
+
+// insertPowerEvents сохраняет события не старше последнего записанного
+func insertPowerEvents(db *sqlx.DB, events []PowerEvent) error {
+	var last string
+	if err := db.Get(&last, `SELECT COALESCE(MAX(timestamp), '') FROM power_events`); err != nil {
+		return fmt.Errorf(T("power_events.err.read"), err)
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf(T("power_events.err.transaction"), err)
+	}
+	defer tx.Rollback()
+	for _, e := range events {
+		if e.Timestamp < last {
+			continue
+		}
+		_, err := tx.NamedExec(`INSERT OR IGNORE INTO power_events (timestamp, kind, reason, percentage, on_battery)
+			VALUES (:timestamp, :kind, :reason, :percentage, :on_battery)`, e)
+		if err != nil {
+			return fmt.Errorf(T("power_events.err.save"), err)
+		}
+	}
+	return tx.Commit()
+}
+
+// getPowerEvents возвращает события периода по возрастанию; пустой To – до текущего момента
+func getPowerEvents(db *sqlx.DB, rng ReportRange) ([]PowerEvent, error) {
+	var events []PowerEvent
+	var err error
+	if rng.To.IsZero() {
+		err = db.Select(&events, `SELECT * FROM power_events WHERE timestamp >= ? ORDER BY timestamp`,
+			rng.From.UTC().Format(time.RFC3339))
+	} else {
+		err = db.Select(&events, `SELECT * FROM power_events WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp`,
+			rng.From.UTC().Format(time.RFC3339), rng.To.UTC().Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("power_events.err.read"), err)
+	}
+	return events, nil
+}
+
+// GetPowerEvents возвращает события питания видимого окна графика
+func (ds *DataService) GetPowerEvents(view ChartView) []PowerEvent {
+	since, until := view.Range(ds.Now())
+	events, err := getPowerEvents(ds.db, ReportRange{From: since, To: until})
+	if err != nil {
+		return nil
+	}
+	return events
+}
+
+// chartEvents переводит события питания в отметки графика
+func chartEvents(events []PowerEvent) []ChartEvent {
+	marks := make([]ChartEvent, 0, len(events))
+	for _, e := range events {
+		marks = append(marks, e.ChartEvent())
+	}
+	return marks
+}
+
+// SleepWakes – период сна от батареи и пробуждения за него
+type SleepWakes struct {
+	Period    SleepPeriod
+	Wakes     int    // все пробуждения, включая темные
+	DarkWakes int    // темные пробуждения
+	Reason    string // самая частая причина пробуждений
+}
+
+// String возвращает строку для отчетов
+func (s SleepWakes) String() string {
+	str := T("sleep_wakes.summary",
+		s.Period.Start.Local().Format("02.01 15:04"), s.Period.Drain(), formatDuration(s.Period.Duration()), s.Wakes, s.DarkWakes)
+	if s.Reason != "" {
+		str += T("sleep_wakes.reason", s.Reason)
+	}
+	return str
+}
+
+// WakeReason – причина пробуждений во сне и их число
+type WakeReason struct {
+	Reason string
+	Count  int
+}
+
+// String возвращает строку для отчетов
+func (r WakeReason) String() string {
+	return fmt.Sprintf("%s – %d", r.Reason, r.Count)
+}
+
+// isWake сообщает, что событие – пробуждение
+func (e PowerEvent) isWake() bool {
+	return e.Kind == powerEventWake || e.Kind == powerEventDarkWake
+}
+
+// wakesDuring возвращает пробуждения внутри периода сна
+func wakesDuring(p SleepPeriod, events []PowerEvent) []PowerEvent {
+	var wakes []PowerEvent
+	for _, e := range events {
+		if at := e.Time(); e.isWake() && at.After(p.Start) && at.Before(p.End) {
+			wakes = append(wakes, e)
+		}
+	}
+	return wakes
+}
+
+// countWakeReasons считает пробуждения по причинам, частые первыми
+func countWakeReasons(wakes []PowerEvent) []WakeReason {
+	counts := map[string]int{}
+	for _, e := range wakes {
+		if e.Reason != "" {
+			counts[e.Reason]++
+		}
+	}
+	reasons := make([]WakeReason, 0, len(counts))
+	for reason, n := range counts {
+		reasons = append(reasons, WakeReason{Reason: reason, Count: n})
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasons[i].Count != reasons[j].Count {
+			return reasons[i].Count > reasons[j].Count
+		}
+		return reasons[i].Reason < reasons[j].Reason
+	})
+	return reasons
+}
+
+// sleepWakeStats сопоставляет периоды сна от батареи с пробуждениями:
+// периоды с наибольшей потерей заряда и самые частые причины пробуждений
+// во всех периодах. Без журнала pmset оба списка пустые.
+func sleepWakeStats(periods []SleepPeriod, events []PowerEvent) ([]SleepWakes, []WakeReason) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	var stats []SleepWakes
+	var all []PowerEvent
+	for _, p := range periods {
+		if !p.OnBattery || p.Drain() <= 0 {
+			continue
+		}
+		wakes := wakesDuring(p, events)
+		if len(wakes) == 0 {
+			continue
+		}
+		all = append(all, wakes...)
+		s := SleepWakes{Period: p, Wakes: len(wakes)}
+		for _, e := range wakes {
+			if e.Kind == powerEventDarkWake {
+				s.DarkWakes++
+			}
+		}
+		if reasons := countWakeReasons(wakes); len(reasons) > 0 {
+			s.Reason = reasons[0].Reason
+		}
+		stats = append(stats, s)
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Period.Drain() > stats[j].Period.Drain() })
+	reasons := countWakeReasons(all)
+	if len(stats) > sleepWakesShown {
+		stats = stats[:sleepWakesShown]
+	}
+	if len(reasons) > wakeReasonsShown {
+		reasons = reasons[:wakeReasonsShown]
+	}
+	return stats, reasons
+}
+
+// powerEventsLegend – подпись к отметкам событий под графиком; notes – на
+// графике есть заметки
+func powerEventsLegend(notes bool) string {
+	legend := T("power_event.legend")
+	if notes {
+		legend += "  " + noteGlyph + " " + T("power_event.legend.note")
+	}
+	return lipgloss.NewStyle().Foreground(theme.Muted).Render(legend)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePmsetLogWakes(t *testing.T) {
+	log := "2026-03-02 23:10:00 +0000 Charge              \tUsing AC (Charge:98%)\n" +
+		"2026-03-02 23:20:00 +0000 Charge              \tUsing Batt (Charge:98%)\n" +
+		"2026-03-02 23:25:00 +0000 Charge              \tUsing Batt (Charge:97%)\n" +
+		"2026-03-02 23:30:00 +0000 Sleep               \tEntering Sleep state due to 'Clamshell Sleep':TCPKeepAlive=active Using Batt (Charge:97%) 3600 secs\n" +
+		"2026-03-03 01:00:00 +0000 DarkWake            \tDarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty smc.70070000 wifibt/ Using BATT (Charge:95%) 45 secs\n" +
+		"2026-03-03 03:00:00 +0000 DarkWake            \tDarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty smc.70070000 wifibt/ Using BATT (Charge:93%) 30 secs\n" +
+		"2026-03-03 04:00:00 +0000 Assertions          \tPID 123(backupd) Created PreventUserIdleSystemSleep\n" +
+		"2026-03-03 05:00:00 +0000 DarkWake            \tDarkWake from Deep Idle [CDN] : due to RTC/Maintenance Using BATT (Charge:91%) 20 secs\n" +
+		"2026-03-03 07:00:00 +0000 Wake                \tWake from Deep Idle [CDNVA] : due to EC.LidOpen/Lid Open Using BATT (Charge:90%)\n"
+
+	events := parsePmsetLog([]byte(log))
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	want := []string{powerEventSource, powerEventSource, powerEventSleep, powerEventDarkWake, powerEventDarkWake, powerEventDarkWake, powerEventWake}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("события %v, ожидалось %v", kinds, want)
+	}
+	if !events[1].OnBattery || events[0].OnBattery {
+		t.Errorf("смена источника: %+v", events[:2])
+	}
+	if events[2].Reason != "Clamshell Sleep" || events[2].Percentage != 97 {
+		t.Errorf("сон: %+v", events[2])
+	}
+	if events[3].Reason != "SMC.OutboxNotEmpty smc.70070000 wifibt" {
+		t.Errorf("причина темного пробуждения %q", events[3].Reason)
+	}
+	if events[6].Reason != "EC.LidOpen/Lid Open" {
+		t.Errorf("причина пробуждения %q", events[6].Reason)
+	}
+
+	period := SleepPeriod{
+		Start:        time.Date(2026, 3, 2, 23, 29, 0, 0, time.UTC),
+		End:          time.Date(2026, 3, 3, 7, 1, 0, 0, time.UTC),
+		StartPercent: 97,
+		EndPercent:   90,
+		OnBattery:    true,
+	}
+	stats, reasons := sleepWakeStats([]SleepPeriod{period}, events)
+	if len(stats) != 1 || stats[0].Wakes != 4 || stats[0].DarkWakes != 3 {
+		t.Fatalf("пробуждения во сне: %+v", stats)
+	}
+	if stats[0].Reason != "SMC.OutboxNotEmpty smc.70070000 wifibt" {
+		t.Errorf("главная причина %q", stats[0].Reason)
+	}
+	if len(reasons) != 3 || reasons[0].Count != 2 {
+		t.Errorf("причины %+v", reasons)
+	}
+}
@@ -0,0 +1,202 @@
+// power_metrics.go
+//
+// Подробный режим сбора: powermetrics раз в измерение снимает мощность
+// CPU/GPU/ANE и уровень теплового давления – на Apple Silicon ioreg
+// не показывает, на что именно уходит энергия внутри SoC. Режим
+// включается в config.json ("power": {"powermetrics": true}) или флагом
+// batmon collect --powermetrics и требует root или sudo без пароля.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// powerSamplesSchema – выборки powermetrics
+const powerSamplesSchema = `
+CREATE TABLE IF NOT EXISTS power_samples (
+	timestamp TEXT PRIMARY KEY,
+	cpu_power INTEGER DEFAULT 0,
+	gpu_power INTEGER DEFAULT 0,
+	ane_power INTEGER DEFAULT 0,
+	combined_power INTEGER DEFAULT 0,
+	package_power INTEGER DEFAULT 0,
+	thermal_pressure TEXT DEFAULT ''
+);`
+
+// PowerSample – мощность компонентов SoC по данным powermetrics, мВт
+type PowerSample struct {
+	Timestamp       string `db:"timestamp"`
+	CPUPower        int    `db:"cpu_power"`
+	GPUPower        int    `db:"gpu_power"`
+	ANEPower        int    `db:"ane_power"`
+	CombinedPower   int    `db:"combined_power"`   // CPU + GPU + ANE (Apple Silicon)
+	PackagePower    int    `db:"package_power"`    // мощность пакета CPU (Intel)
+	ThermalPressure string `db:"thermal_pressure"` // Nominal, Moderate, Heavy, Trapping, Sleeping
+}
+
+// Total возвращает суммарную мощность SoC, мВт
+func (p PowerSample) Total() int {
+	switch {
+	case p.CombinedPower > 0:
+		return p.CombinedPower
+	case p.PackagePower > 0:
+		return p.PackagePower
+	}
+	return p.CPUPower + p.GPUPower + p.ANEPower
+}
+
+// thermalPressureLabels – ключи перевода уровней теплового давления
+var thermalPressureLabels = map[string]string{
+	"Nominal":  "thermal_pressure.nominal",
+	"Moderate": "thermal_pressure.moderate",
+	"Heavy":    "thermal_pressure.heavy",
+	"Trapping": "thermal_pressure.critical",
+	"Sleeping": "thermal_pressure.critical",
+}
+
+// ThermalLabel возвращает уровень теплового давления для интерфейса
+func (p PowerSample) ThermalLabel() string {
+	if label, ok := thermalPressureLabels[p.ThermalPressure]; ok {
+		return T(label)
+	}
+	if p.ThermalPressure == "" {
+		return T("thermal_pressure.none")
+	}
+	return p.ThermalPressure
+}
+
+// runPowermetrics делает одну секундную выборку powermetrics. Без root
+// команда запускается через sudo -n: если sudo требует пароль, она сразу
+// завершается ошибкой и batmon ничего не спрашивает у пользователя.
+func runPowermetrics(args ...string) ([]byte, error) {
+	args = append(args, "-n", "1", "-i", "1000")
+	name := "powermetrics"
+	if os.Geteuid() != 0 {
+		args = append([]string{"-n", name}, args...)
+		name = "sudo"
+	}
+	out, err := runCommand(name, args...)
+	if err != nil {
+		return nil, fmt.Errorf("powermetrics: %w", err)
+	}
+	return out, nil
+}
+
+var (
+	powermetricsCPURe      = regexp.MustCompile(`(?m)^CPU Power:\s*([\d.]+)\s*mW`)
+	powermetricsGPURe      = regexp.MustCompile(`(?m)^GPU Power:\s*([\d.]+)\s*mW`)
+	powermetricsANERe      = regexp.MustCompile(`(?m)^ANE Power:\s*([\d.]+)\s*mW`)
+	powermetricsCombinedRe = regexp.MustCompile(`(?m)^Combined Power \(CPU \+ GPU \+ ANE\):\s*([\d.]+)\s*mW`)
+	powermetricsPackageRe  = regexp.MustCompile(`(?m)package power \(CPUs\+GT\+SA\):\s*([\d.]+)\s*W`)
+	powermetricsThermalRe  = regexp.MustCompile(`(?m)^Current pressure level:\s*(\w+)`)
+)
+
+// parsePowermetricsPower разбирает вывод samplers cpu_power, gpu_power и thermal.
+// На Intel вместо мощности компонентов есть только мощность пакета в ваттах.
+func parsePowermetricsPower(out []byte, timestamp string) (PowerSample, error) {
+	s := PowerSample{Timestamp: timestamp}
+	milliwatts := func(re *regexp.Regexp, scale float64) int {
+		if m := re.FindSubmatch(out); m != nil {
+			if v, err := strconv.ParseFloat(string(m[1]), 64); err == nil {
+				return int(v * scale)
+			}
+		}
+		return 0
+	}
+	s.CPUPower = milliwatts(powermetricsCPURe, 1)
+	s.GPUPower = milliwatts(powermetricsGPURe, 1)
+	s.ANEPower = milliwatts(powermetricsANERe, 1)
+	s.CombinedPower = milliwatts(powermetricsCombinedRe, 1)
+	s.PackagePower = milliwatts(powermetricsPackageRe, 1000)
+	if m := powermetricsThermalRe.FindSubmatch(out); m != nil {
+		s.ThermalPressure = string(m[1])
+	}
+	if s.Total() == 0 && s.ThermalPressure == "" {
+		return s, errors.New(T("powermetrics.err.no_power"))
+	}
+	return s, nil
+}
+
+// powerSampler снимает выборки powermetrics; после первой неудачи
+// (нет root и sudo требует пароль) до перезапуска больше не пытается
+type powerSampler struct {
+	disabled bool
+}
+
+// enabled сообщает, включен ли подробный режим
+func (p *powerSampler) enabled() bool {
+	return !p.disabled && getConfig().Power.Powermetrics
+}
+
+// sample снимает одну выборку
+func (p *powerSampler) sample(timestamp string) (*PowerSample, error) {
+	out, err := runPowermetrics("--samplers", "cpu_power,gpu_power,thermal,battery")
+	if err == nil {
+		var s PowerSample
+		if s, err = parsePowermetricsPower(out, timestamp); err == nil {
+			return &s, nil
+		}
+	}
+	p.disabled = true
+	logWarnf("⚠️ Подробный режим отключен до перезапуска: %v (нужен root или sudo без пароля для powermetrics)", err)
+	return nil, err
+}
+
+// insertPowerSample сохраняет выборку powermetrics
+func insertPowerSample(db *sqlx.DB, s PowerSample) error {
+	_, err := db.NamedExec(`INSERT OR REPLACE INTO power_samples
+		(timestamp, cpu_power, gpu_power, ane_power, combined_power, package_power, thermal_pressure)
+		VALUES (:timestamp, :cpu_power, :gpu_power, :ane_power, :combined_power, :package_power, :thermal_pressure)`, s)
+	if err != nil {
+		return fmt.Errorf(T("powermetrics.err.save"), err)
+	}
+	return nil
+}
+
+// getLatestPowerSample возвращает последнюю выборку powermetrics или nil
+func getLatestPowerSample(db *sqlx.DB) (*PowerSample, error) {
+	var s PowerSample
+	err := db.Get(&s, `SELECT * FROM power_samples ORDER BY timestamp DESC LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("powermetrics.err.read"), err)
+	}
+	return &s, nil
+}
+
+// renderPowerPanel рендерит панель мощности SoC для дашборда
+func renderPowerPanel(p PowerSample, latest Measurement) string {
+	var b strings.Builder
+	b.WriteString(T("soc_power.title"))
+	if p.CombinedPower > 0 || p.CPUPower > 0 {
+		fmt.Fprintf(&b, T("soc_power.components"),
+			formatMilliwatts(p.CPUPower), formatMilliwatts(p.GPUPower), formatMilliwatts(p.ANEPower), formatMilliwatts(p.Total()))
+	} else if p.PackagePower > 0 {
+		fmt.Fprintf(&b, T("soc_power.package"), formatMilliwatts(p.PackagePower))
+	}
+	// Разница между разрядом батареи и SoC – дисплей, накопитель и периферия
+	if latest.State == "discharging" && latest.Voltage > 0 && latest.Amperage < 0 {
+		battery := latest.Voltage * -latest.Amperage / 1000
+		if rest := battery - p.Total(); rest > 0 {
+			fmt.Fprintf(&b, T("soc_power.battery"), formatMilliwatts(battery), formatMilliwatts(rest))
+		}
+	}
+	fmt.Fprintf(&b, T("soc_power.pressure"), p.ThermalLabel())
+	return b.String()
+}
+
+// formatMilliwatts форматирует мощность в ваттах
+func formatMilliwatts(mw int) string {
+	return T("soc_power.watts", float64(mw)/1000)
+}
@@ -0,0 +1,135 @@
+// remaining.go
+//
+// Прогноз оставшегося времени с учетом нагрузки. Среднее по последним
+// интервалам (computeRemainingTime) скачет вслед за любым всплеском нагрузки,
+// поэтому здесь скорость разрядки сглаживается экспоненциально по времени,
+// а по разбросу скорости строится доверительный интервал ("2 ч 40 мин ± 25 мин").
+// Нелинейность разрядки учитывается грубо: ниже lowChargeThreshold напряжение
+// проседает и при той же мощности ток растет, а последние проценты система
+// держит в резерве и выключает Mac раньше нуля.
+
+package main
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	remainingHalfLife    = 20 * time.Minute // через столько вес старого окна падает вдвое
+	remainingWindow      = 10 * time.Minute // скорость считается по окнам: отдельные 30 с слишком шумные
+	remainingMinWindows  = 3                // меньше окон разрядки – прогноза нет
+	lowChargeThreshold   = 10               // %, ниже – ток растет из-за просадки напряжения
+	lowChargeCurrentRise = 1.15             // во сколько раз растет ток ниже lowChargeThreshold
+	shutdownReservePct   = 3                // %, на этом заряде macOS засыпает принудительно
+)
+
+// RemainingEstimate – прогноз оставшегося времени с доверительным интервалом
+type RemainingEstimate struct {
+	Expected time.Duration // наиболее вероятное время
+	Margin   time.Duration // полуширина интервала (± один разброс скорости)
+	Windows  int           // окон разрядки в расчете
+}
+
+// String форматирует прогноз: "2 ч 40 мин ± 25 мин"
+func (e RemainingEstimate) String() string {
+	if e.Expected <= 0 {
+		return ""
+	}
+	if e.Margin < time.Minute {
+		return formatDuration(e.Expected)
+	}
+	return formatDuration(e.Expected) + " ± " + formatDuration(e.Margin)
+}
+
+// estimateRemaining прогнозирует время до разрядки по последнему непрерывному
+// отрезку разрядки. Если Mac не разряжается или данных мало, прогноза нет.
+func estimateRemaining(ms []Measurement) RemainingEstimate {
+	if len(ms) < 2 {
+		return RemainingEstimate{}
+	}
+	latest := ms[len(ms)-1]
+	if strings.ToLower(latest.State) != "discharging" || latest.CurrentCapacity <= 0 {
+		return RemainingEstimate{}
+	}
+	start := len(ms) - 1
+	for start > 0 && strings.ToLower(ms[start-1].State) == "discharging" {
+		start--
+	}
+
+	// Экспоненциально взвешенные среднее и дисперсия скорости по окнам: вес
+	// окна зависит от его длительности, а не от числа измерений
+	var mean, variance, windowDiff, windowHours float64
+	windows := 0
+	for i := start; i < len(ms)-1; i++ {
+		prev, curr := ms[i], ms[i+1]
+		if abs(curr.Percentage-prev.Percentage) > 20 || abs(curr.CurrentCapacity-prev.CurrentCapacity) > 500 {
+			continue // аномальный скачок, как в computeAvgRateRobust
+		}
+		hours := parseStoredTime(curr.Timestamp).Sub(parseStoredTime(prev.Timestamp)).Hours()
+		diff := float64(prev.CurrentCapacity - curr.CurrentCapacity)
+		if hours <= 0 || hours > 2 || diff < 0 {
+			continue
+		}
+		windowDiff += diff
+		windowHours += hours
+		if windowHours < remainingWindow.Hours() {
+			continue
+		}
+		rate := windowDiff / windowHours
+		if windows == 0 {
+			mean = rate
+		} else {
+			alpha := 1 - math.Exp(-windowHours*math.Ln2/remainingHalfLife.Hours())
+			delta := rate - mean
+			mean += alpha * delta
+			variance = (1 - alpha) * (variance + alpha*delta*delta)
+		}
+		windows++
+		windowDiff, windowHours = 0, 0
+	}
+	if windows < remainingMinWindows || mean <= 0 {
+		return RemainingEstimate{Windows: windows}
+	}
+
+	capacity := usableCapacity(latest)
+	spread := math.Sqrt(variance)
+	expected := capacity / mean
+	// Нижняя скорость ограничена снизу, иначе при большом разбросе верхняя
+	// граница уходит в бесконечность
+	slow := math.Max(mean-spread, mean/2)
+	fast := mean + spread
+	margin := (capacity/slow - capacity/fast) / 2
+	return RemainingEstimate{
+		Expected: time.Duration(expected * float64(time.Hour)),
+		Margin:   time.Duration(margin * float64(time.Hour)),
+		Windows:  windows,
+	}
+}
+
+// usableCapacity возвращает ёмкость (мАч), которую Mac реально израсходует до
+// выключения, в пересчете на ток при нормальном напряжении
+func usableCapacity(m Measurement) float64 {
+	if m.Percentage <= 0 {
+		return float64(m.CurrentCapacity)
+	}
+	perPercent := float64(m.CurrentCapacity) / float64(m.Percentage)
+	pct := float64(m.Percentage)
+	low := math.Min(pct, lowChargeThreshold)
+	normal := pct - low
+	low = math.Max(low-shutdownReservePct, 0)
+	return perPercent * (normal + low/lowChargeCurrentRise)
+}
+
+// forecastRemaining возвращает прогноз с учетом нагрузки, а если его нет
+// (Mac заряжается, мало данных) – прежнюю оценку по средней скорости без интервала
+func forecastRemaining(segment []Measurement, robustRate float64) RemainingEstimate {
+	if estimate := estimateRemaining(segment); estimate.Expected > 0 {
+		return estimate
+	}
+	if len(segment) == 0 {
+		return RemainingEstimate{}
+	}
+	return RemainingEstimate{Expected: computeRemainingTime(segment[len(segment)-1].CurrentCapacity, robustRate)}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateRemaining(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(24*time.Hour))
+	const rate = 300.0 // мАч/ч: 50 мАч за 10 минут
+	hoursAt := func(m Measurement, rate float64) time.Duration {
+		return time.Duration(usableCapacity(m) / rate * float64(time.Hour))
+	}
+
+	// Ровная разрядка: прогноз по скорости без разброса
+	steady := steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12)
+	e := estimateRemaining(steady)
+	if want := hoursAt(steady[11], rate); (e.Expected-want).Abs() > time.Second || e.Margin != 0 || e.Windows != 11 {
+		t.Errorf("ровная разрядка: %+v, ожидалось %v без интервала по 11 окнам", e, want)
+	}
+	if s := e.String(); strings.Contains(s, "±") {
+		t.Errorf("прогноз без разброса с интервалом: %q", s)
+	}
+
+	// Шумная разрядка со сбоем контроллера: та же скорость в среднем, но с интервалом
+	noisy := noisyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 24, 7)
+	e = estimateRemaining(noisy)
+	want := hoursAt(noisy[23], rate)
+	if e.Expected < want*8/10 || e.Expected > want*12/10 || e.Margin <= 0 {
+		t.Errorf("шумная разрядка: %+v, ожидалось около %v с интервалом", e, want)
+	}
+	if e.Windows >= 23 {
+		t.Errorf("сбой контроллера попал в расчет: %d окон", e.Windows)
+	}
+	if s := e.String(); !strings.Contains(s, "±") {
+		t.Errorf("прогноз с разбросом без интервала: %q", s)
+	}
+
+	// Считается только последний отрезок разрядки, прежний быстрый не влияет
+	fast := fixtureHealthyBattery
+	fast.DrainMAh = 150
+	segments := steadyDischarge(fast, fixtureStart, 10*time.Minute, 6)
+	segments[5].State = "charging"
+	segments = append(segments, steadyDischarge(fixtureHealthyBattery, fixtureStart.Add(time.Hour), 10*time.Minute, 8)...)
+	e = estimateRemaining(segments)
+	if want := hoursAt(segments[len(segments)-1], rate); (e.Expected-want).Abs() > time.Second || e.Windows != 7 {
+		t.Errorf("после зарядки: %+v, ожидалось %v по 7 окнам", e, want)
+	}
+
+	// Нет прогноза: заряжается, скорость нулевая или данных мало
+	charging := steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12)
+	charging[11].State = "charging"
+	idle := steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 12)
+	for i := range idle {
+		idle[i].CurrentCapacity = 4000
+	}
+	cases := []struct {
+		name    string
+		ms      []Measurement
+		windows int
+	}{
+		{"заряжается", charging, 0},
+		{"нулевая скорость", idle, 11},
+		{"нет измерений", nil, 0},
+		{"одно измерение", steady[:1], 0},
+		{"два окна", steady[:3], 2},
+	}
+	for _, c := range cases {
+		if e := estimateRemaining(c.ms); e.Expected != 0 || e.Margin != 0 || e.Windows != c.windows || e.String() != "" {
+			t.Errorf("%s: %+v, ожидалось без прогноза по %d окнам", c.name, e, c.windows)
+		}
+	}
+
+	// Без прогноза по нагрузке остается оценка по средней скорости
+	if e := forecastRemaining(steady[:3], rate); e.Expected != computeRemainingTime(steady[2].CurrentCapacity, rate) || e.Margin != 0 {
+		t.Errorf("запасная оценка: %+v", e)
+	}
+}
@@ -0,0 +1,173 @@
+// replay.go
+//
+// Режим воспроизведения: batmon replay <файл.sqlite|файл.json> [скорость]
+// прогоняет сохранённые измерения через дашборд в ускоренном времени.
+// Запись не изменяется – измерения копируются в БД в памяти.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultReplaySpeed = 60                     // 1 секунда воспроизведения = 1 минута записи
+	replayTick         = 250 * time.Millisecond // шаг подачи измерений
+)
+
+// loadReplayMeasurements читает измерения из БД batmon или JSON-массива в хронологическом порядке
+func loadReplayMeasurements(path string) ([]Measurement, error) {
+	var ms []Measurement
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf(T("replay.err.read"), err)
+		}
+		if err := json.Unmarshal(raw, &ms); err != nil {
+			return nil, fmt.Errorf(T("err.parse"), path, err)
+		}
+	default:
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf(T("replay.err.read"), err)
+		}
+		db, err := sqlx.Connect("sqlite3", "file:"+path+"?mode=ro")
+		if err != nil {
+			return nil, fmt.Errorf(T("err.open"), path, err)
+		}
+		defer db.Close()
+		// Unsafe: записи старых версий могут не содержать новых столбцов
+		if err := db.Unsafe().Select(&ms, `SELECT * FROM measurements ORDER BY timestamp`); err != nil {
+			return nil, fmt.Errorf(T("replay.err.measurements"), path, err)
+		}
+	}
+
+	valid := ms[:0]
+	for _, m := range ms {
+		if _, err := time.Parse(time.RFC3339, m.Timestamp); err == nil {
+			valid = append(valid, m)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf(T("replay.err.no_measurements"), path)
+	}
+	sort.SliceStable(valid, func(i, j int) bool { return valid[i].Timestamp < valid[j].Timestamp })
+	return valid, nil
+}
+
+// replayFeed подаёт измерения записи в сервис данных по виртуальным часам
+type replayFeed struct {
+	mu           sync.Mutex
+	measurements []Measurement
+	speed        float64
+	clock        time.Time // виртуальное время воспроизведения
+	next         int       // индекс следующего измерения
+}
+
+func newReplayFeed(ms []Measurement, speed float64) *replayFeed {
+	start, _ := time.Parse(time.RFC3339, ms[0].Timestamp)
+	return &replayFeed{measurements: ms, speed: speed, clock: start}
+}
+
+// Now возвращает виртуальное время воспроизведения
+func (r *replayFeed) Now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clock
+}
+
+// Done сообщает, что все измерения записи поданы
+func (r *replayFeed) Done() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.next >= len(r.measurements)
+}
+
+// advance сдвигает виртуальные часы на step и возвращает измерения, время которых наступило
+func (r *replayFeed) advance(step time.Duration) []Measurement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clock = r.clock.Add(step)
+	until := r.clock.UTC().Format(time.RFC3339)
+	start := r.next
+	for r.next < len(r.measurements) && r.measurements[r.next].Timestamp <= until {
+		r.next++
+	}
+	if r.next == len(r.measurements) {
+		// Запись закончилась – останавливаем часы на последнем измерении
+		r.clock, _ = time.Parse(time.RFC3339, r.measurements[r.next-1].Timestamp)
+	}
+	return r.measurements[start:r.next]
+}
+
+// run подаёт измерения в БД и буфер сервиса до конца записи или остановки сервиса
+func (r *replayFeed) run(ds *DataService) {
+	ticker := time.NewTicker(replayTick)
+	defer ticker.Stop()
+
+	// Первое измерение показываем сразу, не дожидаясь тика
+	r.feed(ds, r.advance(0))
+	step := time.Duration(float64(replayTick) * r.speed)
+	for !r.Done() {
+		select {
+		case <-ds.ctx.Done():
+			return
+		case <-ticker.C:
+			r.feed(ds, r.advance(step))
+		}
+	}
+}
+
+func (r *replayFeed) feed(ds *DataService, ms []Measurement) {
+	for _, m := range ms {
+		if err := insertMeasurement(ds.db, &m); err != nil {
+			continue
+		}
+		ds.buffer.Add(m)
+		ds.collector.publish(m)
+	}
+	if len(ms) > 0 {
+		syncSessions(ds.db)
+		syncThermalEvents(ds.db)
+	}
+}
+
+// runReplayMode запускает дашборд поверх записанной сессии
+func runReplayMode(path string, speed float64) error {
+	ms, err := loadReplayMeasurements(path)
+	if err != nil {
+		return err
+	}
+
+	db, err := initDB(":memory:")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	dataService := NewDataService(newStore(db, ":memory:"), NewMemoryBuffer(100))
+	dataService.replay = newReplayFeed(ms, speed)
+	dataService.Start()
+	defer dataService.Stop()
+
+	app := newApp(dataService)
+	app.refreshInterval = time.Second
+	app.menu.list.Title = T("replay.title", filepath.Base(path), speed)
+
+	p := tea.NewProgram(app, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf(T("err.tui"), err)
+	}
+	return nil
+}
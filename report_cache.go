@@ -0,0 +1,73 @@
+// report_cache.go
+//
+// Кэш данных отчета в TUI. View вызывается на каждое нажатие клавиши и тик
+// анимации, а сборка ReportData открывает соединение с БД и заново
+// прогоняет весь анализ. Поэтому данные собираются один раз для выбранного
+// периода и последнего измерения: переключение вкладок и прокрутка берут их
+// из кэша, а пересборку вызывают новое измерение, смена периода или явное
+// обновление (r).
+
+package main
+
+import "time"
+
+// reportCacheKey – от чего зависят данные отчета
+type reportCacheKey struct {
+	preset int    // индекс периода в reportRangePresets
+	latest string // отметка времени последнего измерения
+}
+
+// ReportCache – собранные данные отчета и ключ, для которого они верны
+type ReportCache struct {
+	key     reportCacheKey
+	data    *ReportData
+	err     error // ошибка сборки тоже кэшируется, чтобы не опрашивать БД на каждый кадр
+	builtAt time.Time
+	valid   bool
+}
+
+// Get возвращает данные для ключа, если они уже собраны
+func (c *ReportCache) Get(key reportCacheKey) (*ReportData, error, bool) {
+	if !c.valid || c.key != key {
+		return nil, nil, false
+	}
+	return c.data, c.err, true
+}
+
+// Store запоминает результат сборки для ключа
+func (c *ReportCache) Store(key reportCacheKey, data *ReportData, err error) {
+	c.key, c.data, c.err, c.builtAt, c.valid = key, data, err, timeNow(), true
+}
+
+// Invalidate сбрасывает кэш: следующий кадр соберет данные заново
+func (c *ReportCache) Invalidate() {
+	*c = ReportCache{}
+}
+
+// reportCacheKey возвращает ключ кэша для текущего периода и последнего измерения
+func (a *App) reportCacheKey() reportCacheKey {
+	key := reportCacheKey{preset: a.report.rangePreset}
+	if a.latest != nil {
+		key.latest = a.latest.Timestamp
+	}
+	return key
+}
+
+// cachedReportData возвращает данные отчета из кэша или собирает их заново
+func (a *App) cachedReportData() (*ReportData, error) {
+	key := a.reportCacheKey()
+	if data, err, ok := a.report.cache.Get(key); ok {
+		return data, err
+	}
+	data, err := a.generateUIReportData()
+	a.report.cache.Store(key, data, err)
+	return data, err
+}
+
+// reportCacheStatus описывает возраст данных отчета для строки подсказок
+func (a *App) reportCacheStatus() string {
+	if !a.report.cache.valid {
+		return ""
+	}
+	return T("report.cache.built_at", a.report.cache.builtAt.Format("15:04:05"))
+}
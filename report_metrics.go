@@ -0,0 +1,97 @@
+// report_metrics.go
+//
+// Вкладка «Метрики» отчета: расширенные метрики analyzeAdvancedMetrics
+// (стабильность напряжения, энергоэффективность, эффективность зарядки,
+// тренд мощности, рейтинг здоровья) в виде шкал с пояснением, что значит
+// каждая метрика и как она считается. Раньше они были доступны только из
+// консольного меню.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// metricGauge – шкала метрики со значением 0–100 и пояснением
+type metricGauge struct {
+	title   string
+	value   float64
+	suffix  string
+	meaning string        // что показывает метрика
+	formula string        // как считается
+	factors []ScoreFactor // вклад факторов (для рейтинга)
+}
+
+// renderReportMetrics рендерит вкладку расширенных метрик
+func (a *App) renderReportMetrics(data *ReportData) string {
+	var content strings.Builder
+	m := data.Advanced
+
+	content.WriteString(T("metrics.tab.title"))
+	content.WriteString(strings.Repeat("─", 50) + "\n\n")
+	if len(data.Measurements) == 0 {
+		content.WriteString(T("metrics.tab.no_data"))
+		return content.String()
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+	noteStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	gauges := []metricGauge{
+		{
+			title:   T("metrics.tab.stability"),
+			value:   m.VoltageStability,
+			suffix:  "%",
+			meaning: T("metrics.tab.stability.meaning"),
+			formula: T("metrics.tab.stability.formula"),
+		},
+		{
+			title:   T("metrics.tab.efficiency"),
+			value:   m.PowerEfficiency,
+			suffix:  "%",
+			meaning: T("metrics.tab.efficiency.meaning"),
+			formula: T("metrics.tab.efficiency.formula"),
+		},
+		{
+			title:   T("metrics.tab.health"),
+			value:   float64(m.HealthRating),
+			suffix:  "/100",
+			meaning: T("metrics.tab.health.meaning"),
+			formula: T("metrics.tab.health.formula"),
+			factors: m.HealthBreakdown,
+		},
+	}
+	for _, g := range gauges {
+		content.WriteString(titleStyle.Render(g.title) + "\n")
+		content.WriteString(fmt.Sprintf("%s %.1f%s\n", a.renderCompactProgressBar(g.value, 100, 30), g.value, g.suffix))
+		content.WriteString(renderScoreBreakdown(g.factors, ""))
+		content.WriteString(g.meaning + "\n")
+		content.WriteString(noteStyle.Render(T("metrics.tab.formula", g.formula)) + "\n\n")
+	}
+
+	content.WriteString(titleStyle.Render(T("metrics.tab.charging")) + "\n")
+	if m.ChargingEfficiency > 0 {
+		content.WriteString(T("metrics.tab.charging.value", m.ChargingEfficiency))
+	} else {
+		content.WriteString(T("metrics.tab.charging.none"))
+	}
+	content.WriteString(T("metrics.tab.charging.meaning"))
+	content.WriteString(noteStyle.Render(T("metrics.tab.formula", T("metrics.tab.charging.formula"))) + "\n\n")
+
+	content.WriteString(titleStyle.Render(T("metrics.tab.trend")) + "\n")
+	trend := m.PowerTrend
+	if trend == "" {
+		trend = T("metrics.tab.trend.none")
+	}
+	content.WriteString(trend + "\n")
+	content.WriteString(noteStyle.Render(T("metrics.tab.formula", T("metrics.tab.trend.formula"))) + "\n\n")
+
+	if m.AppleStatus != "" {
+		content.WriteString(titleStyle.Render(T("metrics.tab.apple")) + "\n")
+		content.WriteString(m.AppleStatus + "\n")
+		content.WriteString(noteStyle.Render(T("metrics.tab.apple.note")) + "\n")
+	}
+	return content.String()
+}
@@ -0,0 +1,158 @@
+// report_range.go
+//
+// Период отчета: --from/--to в командной строке и выбор периода на экране
+// отчета. Пустой период означает прежнее поведение – последние измерения.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	reportLastN      = 50  // измерений в отчете без явного периода
+	reportMaxPoints  = 300 // точек в графиках и таблицах отчета за период
+	reportDateLayout = "02.01.2006 15:04"
+)
+
+// ReportRange – период отчета. Нулевые границы означают «без ограничения»,
+// полностью пустой период – последние reportLastN измерений.
+type ReportRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// allTimeFrom – начало периода «всё время»
+var allTimeFrom = time.Unix(0, 0).UTC()
+
+// IsZero сообщает, что период не задан
+func (r ReportRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// Label возвращает подпись периода для отчетов
+func (r ReportRange) Label() string {
+	switch {
+	case r.IsZero():
+		return T("range.last_n", reportLastN)
+	case !r.From.After(allTimeFrom) && r.To.IsZero():
+		return T("range.all")
+	case r.To.IsZero():
+		return T("range.since", r.From.Local().Format(reportDateLayout))
+	case !r.From.After(allTimeFrom):
+		return T("range.until", r.To.Local().Format(reportDateLayout))
+	}
+	return r.From.Local().Format(reportDateLayout) + " – " + r.To.Local().Format(reportDateLayout)
+}
+
+// parseReportTime разбирает границу периода: относительное время назад
+// ("30m", "24h", "7d"), время сегодняшнего дня ("14:00"), дату ("2006-01-02"),
+// дату со временем ("2006-01-02 15:04") или RFC3339. Для конца периода дата
+// без времени означает конец дня.
+func parseReportTime(s string, now time.Time, end bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if s == "now" {
+		return now, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("15:04", s, time.Local); err == nil {
+		// Только время – сегодняшний день
+		y, m, d := now.In(time.Local).Date()
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, time.Local), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if end {
+			return t.AddDate(0, 0, 1).Add(-time.Second), nil
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf(T("range.err.parse"), s)
+}
+
+// parseReportRange разбирает значения --from и --to
+func parseReportRange(from, to string, now time.Time) (ReportRange, error) {
+	var r ReportRange
+	var err error
+	if r.From, err = parseReportTime(from, now, false); err != nil {
+		return r, fmt.Errorf("--from: %w", err)
+	}
+	if r.To, err = parseReportTime(to, now, true); err != nil {
+		return r, fmt.Errorf("--to: %w", err)
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		return r, errors.New(T("range.err.order"))
+	}
+	if !r.IsZero() && r.From.IsZero() {
+		r.From = allTimeFrom
+	}
+	return r, nil
+}
+
+// getMeasurementsInRange возвращает измерения периода в хронологическом порядке
+func getMeasurementsInRange(db *sqlx.DB, r ReportRange) ([]Measurement, error) {
+	if r.To.IsZero() {
+		return getMeasurementsSince(db, r.From)
+	}
+	var ms []Measurement
+	query := `SELECT * FROM measurements WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp`
+	err := db.Select(&ms, query, r.From.UTC().Format(time.RFC3339), r.To.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// loadReportMeasurements возвращает измерения для отчета: за период
+// или последние lastN, если период не задан
+func loadReportMeasurements(db *sqlx.DB, r ReportRange, lastN int) ([]Measurement, error) {
+	if r.IsZero() {
+		return getLastNMeasurements(db, lastN)
+	}
+	return getMeasurementsInRange(db, r)
+}
+
+// reportRangePresets – периоды, переключаемые на экране отчета клавишей p
+var reportRangePresets = []struct {
+	label  string        // ключ перевода
+	period time.Duration // 0 – последние измерения, <0 – всё время
+}{
+	{"range.preset.last", 0},
+	{"range.preset.24h", 24 * time.Hour},
+	{"range.preset.7d", 7 * 24 * time.Hour},
+	{"range.preset.30d", 30 * 24 * time.Hour},
+	{"range.preset.all", -1},
+}
+
+// reportRangePreset возвращает период для пресета с индексом i
+func reportRangePreset(i int, now time.Time) ReportRange {
+	p := reportRangePresets[i%len(reportRangePresets)]
+	switch {
+	case p.period == 0:
+		return ReportRange{}
+	case p.period < 0:
+		return ReportRange{From: allTimeFrom}
+	}
+	return ReportRange{From: now.Add(-p.period)}
+}
@@ -0,0 +1,114 @@
+// rollup.go
+//
+// Долгосрочное хранение: перед удалением старых измерений ретенция
+// сворачивает их в почасовые сводки measurements_hourly (мин./макс./среднее
+// заряда, ёмкости и температуры). Сводки не удаляются, поэтому тренд
+// износа виден дальше 90 дней без миллионов сырых строк.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// hourlySchema – почасовые сводки свернутых измерений
+const hourlySchema = `
+CREATE TABLE IF NOT EXISTS measurements_hourly (
+	hour TEXT PRIMARY KEY,
+	samples INTEGER NOT NULL,
+	battery_serial TEXT NOT NULL DEFAULT '',
+	min_percentage INTEGER NOT NULL DEFAULT 0,
+	max_percentage INTEGER NOT NULL DEFAULT 0,
+	avg_percentage REAL NOT NULL DEFAULT 0,
+	min_full_charge_capacity INTEGER NOT NULL DEFAULT 0,
+	max_full_charge_capacity INTEGER NOT NULL DEFAULT 0,
+	avg_full_charge_capacity REAL NOT NULL DEFAULT 0,
+	design_capacity INTEGER NOT NULL DEFAULT 0,
+	cycle_count INTEGER NOT NULL DEFAULT 0,
+	min_temperature INTEGER NOT NULL DEFAULT 0,
+	max_temperature INTEGER NOT NULL DEFAULT 0,
+	avg_temperature REAL NOT NULL DEFAULT 0,
+	discharging_samples INTEGER NOT NULL DEFAULT 0
+);`
+
+// HourlyMeasurement – сводка измерений за час
+type HourlyMeasurement struct {
+	Hour               string  `db:"hour"` // начало часа, RFC3339 UTC
+	Samples            int     `db:"samples"`
+	BatterySerial      string  `db:"battery_serial"`
+	MinPercentage      int     `db:"min_percentage"`
+	MaxPercentage      int     `db:"max_percentage"`
+	AvgPercentage      float64 `db:"avg_percentage"`
+	MinFullChargeCap   int     `db:"min_full_charge_capacity"`
+	MaxFullChargeCap   int     `db:"max_full_charge_capacity"`
+	AvgFullChargeCap   float64 `db:"avg_full_charge_capacity"`
+	DesignCapacity     int     `db:"design_capacity"`
+	CycleCount         int     `db:"cycle_count"`
+	MinTemperature     int     `db:"min_temperature"`
+	MaxTemperature     int     `db:"max_temperature"`
+	AvgTemperature     float64 `db:"avg_temperature"`
+	DischargingSamples int     `db:"discharging_samples"`
+}
+
+// rollupCutoff выравнивает границу ретенции по началу часа, чтобы час не
+// сворачивался по частям: повторная свертка заменяет строку целиком
+func rollupCutoff(cutoff time.Time) string {
+	return cutoff.UTC().Truncate(time.Hour).Format(time.RFC3339)
+}
+
+// rollupMeasurements сворачивает измерения старше cutoff в почасовые сводки.
+// Нулевые ёмкость и температура (данные недоступны) в статистику не входят.
+func rollupMeasurements(db *sqlx.DB, cutoff string) (int64, error) {
+	res, err := db.Exec(`INSERT OR REPLACE INTO measurements_hourly
+		(hour, samples, battery_serial, min_percentage, max_percentage, avg_percentage,
+		min_full_charge_capacity, max_full_charge_capacity, avg_full_charge_capacity,
+		design_capacity, cycle_count, min_temperature, max_temperature, avg_temperature, discharging_samples)
+		SELECT strftime('%Y-%m-%dT%H:00:00Z', timestamp) AS hour, COUNT(*), MAX(battery_serial),
+			MIN(percentage), MAX(percentage), AVG(percentage),
+			COALESCE(MIN(NULLIF(full_charge_capacity, 0)), 0), MAX(full_charge_capacity),
+			COALESCE(AVG(NULLIF(full_charge_capacity, 0)), 0),
+			MAX(design_capacity), MAX(cycle_count),
+			COALESCE(MIN(NULLIF(temperature, 0)), 0), MAX(temperature), COALESCE(AVG(NULLIF(temperature, 0)), 0),
+			SUM(state = 'discharging')
+		FROM measurements WHERE timestamp < ? GROUP BY hour`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf(T("rollup.err.hourly"), err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// MonthlyCapacity – средняя полная ёмкость за месяц
+type MonthlyCapacity struct {
+	Month          string  `db:"month"` // 2006-01
+	FullChargeCap  float64 `db:"full_charge_capacity"`
+	DesignCapacity int     `db:"design_capacity"`
+}
+
+// Wear возвращает износ за месяц, %
+func (m MonthlyCapacity) Wear() float64 {
+	return computeWear(m.DesignCapacity, int(m.FullChargeCap+0.5))
+}
+
+// getMonthlyCapacity возвращает среднюю полную ёмкость по месяцам за всю историю:
+// почасовые сводки для свернутого периода и сырые измерения для остального.
+// Сводка весит столько, сколько измерений в нее свернуто.
+func getMonthlyCapacity(db *sqlx.DB) ([]MonthlyCapacity, error) {
+	var months []MonthlyCapacity
+	err := db.Select(&months, `SELECT substr(ts, 1, 7) AS month,
+			SUM(cap * n) / SUM(n) AS full_charge_capacity, MAX(design) AS design_capacity
+		FROM (
+			SELECT hour AS ts, avg_full_charge_capacity AS cap, samples AS n, design_capacity AS design
+			FROM measurements_hourly WHERE avg_full_charge_capacity > 0
+			UNION ALL
+			SELECT timestamp, full_charge_capacity, 1, design_capacity
+			FROM measurements WHERE full_charge_capacity > 0
+		) GROUP BY month ORDER BY month`)
+	if err != nil {
+		return nil, fmt.Errorf(T("rollup.err.monthly"), err)
+	}
+	return months, nil
+}
@@ -0,0 +1,310 @@
+// rules.go
+//
+// Правила рекомендаций анализа здоровья: условие на метрики батареи и
+// шаблон сообщения. Встроенные правила повторяют прежние эвристики
+// analyzeBatteryHealth, а раздел rules в config.json меняет их пороги и
+// тексты, отключает или добавляет свои без пересборки – например, советует
+// замену батареи с 25% износа вместо 20%:
+//
+//	"rules": [{"id": "replace", "when": "wear > 25"}]
+//
+// Условие – сравнения «метрика оператор число», соединенные &&. Сообщение –
+// шаблон text/template с полями RuleContext. Тексты встроенных правил берутся
+// из каталога i18n на языке отчета, а сообщения из config.json остаются как
+// написаны. batmon rules показывает действующие правила и ошибки в них.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// RecommendationRule – правило рекомендации
+type RecommendationRule struct {
+	ID       string `json:"id"`                 // имя; правило config.json с именем встроенного заменяет его
+	When     string `json:"when,omitempty"`     // условие: "wear > 20 && cycles > 500"; пусто – как у встроенного
+	Message  string `json:"message,omitempty"`  // шаблон сообщения; пусто – как у встроенного
+	Disabled bool   `json:"disabled,omitempty"` // отключить правило
+}
+
+// RuleContext – метрики, доступные условиям и шаблонам правил
+type RuleContext struct {
+	Wear          float64 // износ, %
+	Cycles        int
+	Anomalies     int
+	DischargeRate float64 // робастная скорость разрядки, мАч/ч
+	Degradation   float64 // потеря ёмкости, %/мес; 0 – тренд в норме
+	Percentage    int
+	Charging      bool
+	Temperature   int     // °C
+	Brightness    float64 // средняя яркость при работе от батареи, %; 0 – нет данных
+	HealthScore   int
+	ThermalLevel  int    // 0 – норма, 1 – предупреждение, 2 – тревога
+	ThermalAlert  string // текст температурного предупреждения
+}
+
+// ruleMetrics – метрики условий по имени
+var ruleMetrics = map[string]func(RuleContext) float64{
+	"wear":           func(c RuleContext) float64 { return c.Wear },
+	"cycles":         func(c RuleContext) float64 { return float64(c.Cycles) },
+	"anomalies":      func(c RuleContext) float64 { return float64(c.Anomalies) },
+	"discharge_rate": func(c RuleContext) float64 { return c.DischargeRate },
+	"degradation":    func(c RuleContext) float64 { return c.Degradation },
+	"percentage":     func(c RuleContext) float64 { return float64(c.Percentage) },
+	"charging": func(c RuleContext) float64 {
+		if c.Charging {
+			return 1
+		}
+		return 0
+	},
+	"temperature":   func(c RuleContext) float64 { return float64(c.Temperature) },
+	"brightness":    func(c RuleContext) float64 { return c.Brightness },
+	"health_score":  func(c RuleContext) float64 { return float64(c.HealthScore) },
+	"thermal_level": func(c RuleContext) float64 { return float64(c.ThermalLevel) },
+}
+
+// builtinRules возвращает встроенные правила в порядке вывода рекомендаций;
+// сообщения – шаблоны из каталога "rule.<id>" на текущем языке
+func builtinRules() []RecommendationRule {
+	return []RecommendationRule{
+		{ID: "replace", When: "wear > 20", Message: T("rule.replace")},
+		{ID: "power_settings", When: "anomalies > 3", Message: T("rule.power_settings")},
+		{ID: "end_of_life", When: "cycles > 1000", Message: T("rule.end_of_life")},
+		// При ярком экране высокий расход ожидаем
+		{ID: "high_drain_brightness", When: fmt.Sprintf("discharge_rate > 1000 && brightness >= %d", highBrightness),
+			Message: T("rule.high_drain_brightness")},
+		{ID: "high_drain", When: fmt.Sprintf("discharge_rate > 1000 && brightness < %d", highBrightness),
+			Message: T("rule.high_drain")},
+		// На зарядке пороги температуры ниже, текст подбирает thermalAlert
+		{ID: "temperature", When: "thermal_level > 0", Message: "{{.ThermalAlert}}"},
+		{ID: "degradation", When: "degradation > 0.5", Message: T("rule.degradation")},
+		{ID: "full_charge", When: "charging == 1 && percentage == 100", Message: T("rule.full_charge")},
+		{ID: "calibration", When: "wear > 15 && cycles > 500", Message: calibrationAdvice()},
+	}
+}
+
+// ruleCondition – одно сравнение условия
+type ruleCondition struct {
+	metric string
+	op     string
+	value  float64
+}
+
+var ruleConditionRe = regexp.MustCompile(`^\s*([a-z_]+)\s*(>=|<=|==|!=|>|<)\s*(-?[\d.]+)\s*$`)
+
+// compiledRule – правило с разобранным условием и шаблоном
+type compiledRule struct {
+	RecommendationRule
+	conditions []ruleCondition
+	message    *template.Template
+}
+
+// compileRule разбирает условие и шаблон правила
+func compileRule(r RecommendationRule) (*compiledRule, error) {
+	c := &compiledRule{RecommendationRule: r}
+	if strings.TrimSpace(r.When) == "" {
+		return nil, fmt.Errorf(T("rule.err.empty"), r.ID)
+	}
+	for _, part := range strings.Split(r.When, "&&") {
+		m := ruleConditionRe.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf(T("rule.err.parse"), r.ID, strings.TrimSpace(part))
+		}
+		if _, ok := ruleMetrics[m[1]]; !ok {
+			return nil, fmt.Errorf(T("rule.err.metric"), r.ID, m[1], strings.Join(ruleMetricNames(), ", "))
+		}
+		value, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf(T("rule.err.number"), r.ID, m[3])
+		}
+		c.conditions = append(c.conditions, ruleCondition{metric: m[1], op: m[2], value: value})
+	}
+	tmpl, err := template.New(r.ID).Parse(r.Message)
+	if err != nil {
+		return nil, fmt.Errorf(T("rule.err.template"), r.ID, err)
+	}
+	// Неизвестные поля шаблона видны только при выполнении – проверяем сразу
+	if err := tmpl.Execute(&strings.Builder{}, RuleContext{}); err != nil {
+		return nil, fmt.Errorf(T("rule.err.template"), r.ID, err)
+	}
+	c.message = tmpl
+	return c, nil
+}
+
+// ruleMetricNames возвращает имена метрик по алфавиту
+func ruleMetricNames() []string {
+	names := make([]string, 0, len(ruleMetrics))
+	for name := range ruleMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matches проверяет условие правила
+func (c *compiledRule) matches(ctx RuleContext) bool {
+	for _, cond := range c.conditions {
+		v := ruleMetrics[cond.metric](ctx)
+		var ok bool
+		switch cond.op {
+		case ">":
+			ok = v > cond.value
+		case ">=":
+			ok = v >= cond.value
+		case "<":
+			ok = v < cond.value
+		case "<=":
+			ok = v <= cond.value
+		case "==":
+			ok = v == cond.value
+		case "!=":
+			ok = v != cond.value
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// EffectiveRule – действующее правило и его происхождение
+type EffectiveRule struct {
+	RecommendationRule
+	Source string `json:"source"` // builtin, config или override (встроенное, измененное в config.json)
+}
+
+// effectiveRules накладывает правила config.json на встроенные: совпадающее
+// имя заменяет условие и/или сообщение, новые имена добавляются в конец
+func effectiveRules(custom []RecommendationRule) []EffectiveRule {
+	builtin := builtinRules()
+	rules := make([]EffectiveRule, len(builtin))
+	index := map[string]int{}
+	for i, r := range builtin {
+		rules[i] = EffectiveRule{RecommendationRule: r, Source: "builtin"}
+		index[r.ID] = i
+	}
+	for _, r := range custom {
+		i, ok := index[r.ID]
+		if !ok {
+			index[r.ID] = len(rules)
+			rules = append(rules, EffectiveRule{RecommendationRule: r, Source: "config"})
+			continue
+		}
+		merged := rules[i].RecommendationRule
+		if r.When != "" {
+			merged.When = r.When
+		}
+		if r.Message != "" {
+			merged.Message = r.Message
+		}
+		merged.Disabled = r.Disabled
+		rules[i] = EffectiveRule{RecommendationRule: merged, Source: "override"}
+	}
+	return rules
+}
+
+// ruleWarnings – правила с ошибкой, о которых уже написано в лог
+var ruleWarnings sync.Map
+
+// Recommendation – сообщение сработавшего правила; по RuleID отчет находит
+// совет, который уточняет своими данными, независимо от языка
+type Recommendation struct {
+	RuleID string
+	Text   string
+}
+
+// recommendationTexts возвращает тексты рекомендаций
+func recommendationTexts(recs []Recommendation) []string {
+	texts := make([]string, 0, len(recs))
+	for _, r := range recs {
+		texts = append(texts, r.Text)
+	}
+	return texts
+}
+
+// evaluateRules возвращает сообщения сработавших правил. Правило с ошибкой
+// пропускается, а ошибка один раз пишется в лог.
+func evaluateRules(rules []EffectiveRule, ctx RuleContext) []Recommendation {
+	var messages []Recommendation
+	for _, r := range rules {
+		if r.Disabled {
+			continue
+		}
+		c, err := compileRule(r.RecommendationRule)
+		if err == nil && !c.matches(ctx) {
+			continue
+		}
+		var msg strings.Builder
+		if err == nil {
+			err = c.message.Execute(&msg, ctx)
+		}
+		if err != nil {
+			if _, warned := ruleWarnings.LoadOrStore(r.ID+"\x00"+r.When+"\x00"+r.Message, true); !warned {
+				logWarnf("⚠️ %v", err)
+			}
+			continue
+		}
+		if text := strings.TrimSpace(msg.String()); text != "" {
+			messages = append(messages, Recommendation{RuleID: r.ID, Text: text})
+		}
+	}
+	return messages
+}
+
+// runRulesCommand выводит действующие правила рекомендаций и проверяет их
+func runRulesCommand(args []string) error {
+	fs := newCommandFlags("rules")
+	asJSON := fs.Bool("json", false, localized("flag.rules.json", "вывести правила в JSON"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	rules := effectiveRules(getConfig().Rules)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rules); err != nil {
+			return err
+		}
+	}
+
+	invalid := 0
+	for _, r := range rules {
+		_, err := compileRule(r.RecommendationRule)
+		if err != nil {
+			invalid++
+		}
+		if *asJSON {
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "❌ "+err.Error())
+			}
+			continue
+		}
+		marker := "✅"
+		switch {
+		case err != nil:
+			marker = "❌"
+		case r.Disabled:
+			marker = "⏸️"
+		}
+		source := map[string]string{"builtin": T("rules.source.builtin"), "config": "config.json", "override": T("rules.source.override")}[r.Source]
+		fmt.Println(T("rules.row", marker, r.ID, source, r.When, r.Message))
+		if err != nil {
+			fmt.Println(T("rules.error", err))
+		}
+	}
+	if !*asJSON {
+		fmt.Println(T("rules.metrics", strings.Join(ruleMetricNames(), ", ")))
+	}
+	if invalid > 0 {
+		return fmt.Errorf(T("rule.err.invalid"), invalid)
+	}
+	return nil
+}
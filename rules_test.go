@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecommendationRules(t *testing.T) {
+	freezeEnvironment(t, fixtureStart)
+	ctx := RuleContext{Wear: 22, Cycles: 600, DischargeRate: 1200}
+	builtin := recommendationTexts(evaluateRules(effectiveRules(nil), ctx))
+	want := []string{"Рассмотрите замену батареи", "Высокое энергопотребление - закройте ресурсоемкие приложения", calibrationAdvice()}
+	if strings.Join(builtin, "|") != strings.Join(want, "|") {
+		t.Fatalf("встроенные правила: %q", builtin)
+	}
+
+	custom := []RecommendationRule{
+		{ID: "replace", When: "wear > 25"},
+		{ID: "calibration", Disabled: true},
+		{ID: "fleet_cycles", When: "cycles >= 600 && wear > 20", Message: "Износ {{printf \"%.0f\" .Wear}}% при {{.Cycles}} циклах – заявка в ИТ"},
+		{ID: "broken", When: "voltage > 1", Message: "не сработает"},
+	}
+	got := recommendationTexts(evaluateRules(effectiveRules(custom), ctx))
+	want = []string{"Высокое энергопотребление - закройте ресурсоемкие приложения", "Износ 22% при 600 циклах – заявка в ИТ"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("правила config.json: %q", got)
+	}
+
+	// Встроенные сообщения переводятся, сообщения из config.json – нет
+	t.Setenv("LANG", "en_US.UTF-8")
+	custom[0].Message = "Замените батарею: износ {{printf \"%.0f\" .Wear}}%"
+	custom[0].When = ""
+	got = recommendationTexts(evaluateRules(effectiveRules(custom), ctx))
+	want = []string{"Замените батарею: износ 22%", "High power draw - close resource-heavy apps", "Износ 22% при 600 циклах – заявка в ИТ"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("правила на английском: %q", got)
+	}
+	if _, err := compileRule(RecommendationRule{ID: "bad", When: "wear > 1", Message: "{{.Voltage}}"}); err == nil {
+		t.Error("неизвестное поле шаблона не обнаружено")
+	}
+}
+
+// Список приложений заменяет только совет правила high_drain, на любом языке
+func TestRecommendationTopApps(t *testing.T) {
+	freezeEnvironment(t, fixtureStart)
+	apps := []AppEnergyUsage{{App: "Xcode", MAh: 900}, {App: "Safari", MAh: 300}}
+	for _, lang := range []string{"ru_RU.UTF-8", "en_US.UTF-8"} {
+		t.Setenv("LANG", lang)
+		for _, ctx := range []RuleContext{
+			{DischargeRate: 1200, Brightness: 30},
+			{DischargeRate: 1200, Brightness: 90},
+		} {
+			recs := evaluateRules(effectiveRules(nil), ctx)
+			if len(recs) != 1 {
+				t.Fatalf("%s: рекомендации %+v", lang, recs)
+			}
+			got := withTopApps(recs, apps)[0]
+			switch recs[0].RuleID {
+			case "high_drain":
+				if got.Text != T("rec.high_drain_apps", topAppNames(apps, 3)) || !strings.Contains(got.Text, "Xcode") {
+					t.Errorf("%s: %q", lang, got.Text)
+				}
+			case "high_drain_brightness":
+				if got.Text != recs[0].Text {
+					t.Errorf("%s: совет о яркости заменен: %q", lang, got.Text)
+				}
+			default:
+				t.Errorf("%s: неожиданное правило %s", lang, recs[0].RuleID)
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// sampling.go
+//
+// Адаптивная частота опроса батареи. После каждого измерения политика
+// выбирает интервал до следующего: при быстром разряде и во время теста
+// калибровки – чаще (по умолчанию 10 с), чтобы не пропустить резкие
+// падения, а от сети без зарядки и на 100% – реже (2 и 5 минут), чтобы не
+// раздувать базу одинаковыми строками. Политики подключаются через
+// samplingPolicies и выбираются в config.json (collector.sampling.policy).
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSamplingPolicy = "adaptive"
+	defaultFastInterval   = 10  // интервал при быстром разряде и калибровке, с
+	defaultIdleInterval   = 120 // интервал от сети без зарядки, с
+	defaultFullInterval   = 300 // интервал от сети на 100%, с
+	defaultFastDrain      = 0.5 // разряд, %/мин, выше которого опрос ускоряется
+	drainRateWindow       = 3 * time.Minute
+)
+
+// SamplingConfig – политика частоты опроса в config.json
+type SamplingConfig struct {
+	Policy       string  `json:"policy"`        // adaptive или fixed
+	FastInterval int     `json:"fast_interval"` // интервал при быстром разряде и калибровке, с
+	IdleInterval int     `json:"idle_interval"` // интервал от сети без зарядки, с
+	FullInterval int     `json:"full_interval"` // интервал от сети на 100%, с
+	FastDrain    float64 `json:"fast_drain"`    // порог быстрого разряда, %/мин
+}
+
+// defaultSamplingConfig – адаптивный опрос с интервалами по умолчанию
+func defaultSamplingConfig() SamplingConfig {
+	return SamplingConfig{
+		Policy:       defaultSamplingPolicy,
+		FastInterval: defaultFastInterval,
+		IdleInterval: defaultIdleInterval,
+		FullInterval: defaultFullInterval,
+		FastDrain:    defaultFastDrain,
+	}
+}
+
+// samplingState – то, от чего зависит интервал до следующего измерения
+type samplingState struct {
+	Base        time.Duration // интервал опроса из настроек
+	Recent      []Measurement // последние измерения, старые первыми
+	Calibrating bool          // идет тест полной разрядки
+	LowBattery  bool          // щадящий режим: опрос не ускоряется
+}
+
+// SamplingPolicy выбирает интервал до следующего измерения
+type SamplingPolicy interface {
+	Interval(s samplingState) time.Duration
+}
+
+// samplingPolicies – доступные политики по имени из config.json
+var samplingPolicies = map[string]func(SamplingConfig) SamplingPolicy{
+	"fixed":    func(SamplingConfig) SamplingPolicy { return fixedSampling{} },
+	"adaptive": func(cfg SamplingConfig) SamplingPolicy { return adaptiveSampling{cfg: cfg} },
+}
+
+// newSamplingPolicy создает политику из настроек; при неизвестном имени
+// возвращает адаптивную политику и ошибку
+func newSamplingPolicy(cfg SamplingConfig) (SamplingPolicy, error) {
+	if create, ok := samplingPolicies[strings.ToLower(cfg.Policy)]; ok {
+		return create(cfg), nil
+	}
+	if cfg.Policy == "" {
+		return samplingPolicies[defaultSamplingPolicy](cfg), nil
+	}
+	return samplingPolicies[defaultSamplingPolicy](cfg),
+		fmt.Errorf(T("sampling.err.policy"), cfg.Policy, defaultSamplingPolicy)
+}
+
+// samplingPolicyName возвращает имя действующей политики опроса
+func samplingPolicyName(cfg SamplingConfig) string {
+	if _, ok := samplingPolicies[strings.ToLower(cfg.Policy)]; ok {
+		return strings.ToLower(cfg.Policy)
+	}
+	return defaultSamplingPolicy
+}
+
+// fixedSampling – опрос всегда с интервалом из настроек
+type fixedSampling struct{}
+
+func (fixedSampling) Interval(s samplingState) time.Duration {
+	return s.Base
+}
+
+// adaptiveSampling ускоряет опрос при быстром разряде и калибровке и
+// замедляет от сети
+type adaptiveSampling struct {
+	cfg SamplingConfig
+}
+
+func (p adaptiveSampling) Interval(s samplingState) time.Duration {
+	if len(s.Recent) == 0 {
+		return s.Base
+	}
+	latest := s.Recent[len(s.Recent)-1]
+	state := strings.ToLower(latest.State)
+
+	if state == "discharging" {
+		fast := secondsOr(p.cfg.FastInterval, defaultFastInterval)
+		if s.LowBattery || fast >= s.Base {
+			return s.Base
+		}
+		threshold := p.cfg.FastDrain
+		if threshold <= 0 {
+			threshold = defaultFastDrain
+		}
+		if s.Calibrating || drainPerMinute(s.Recent) >= threshold {
+			return fast
+		}
+		return s.Base
+	}
+	if state == "charging" && latest.Percentage < 100 {
+		return s.Base
+	}
+	// От сети: на 100% реже всего, без зарядки – реже обычного
+	slow := secondsOr(p.cfg.IdleInterval, defaultIdleInterval)
+	if latest.Percentage >= 100 {
+		slow = secondsOr(p.cfg.FullInterval, defaultFullInterval)
+	}
+	if slow < s.Base {
+		return s.Base
+	}
+	return slow
+}
+
+// secondsOr переводит секунды из настроек в интервал; 0 и меньше – значение по умолчанию
+func secondsOr(seconds, fallback int) time.Duration {
+	if seconds <= 0 {
+		seconds = fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// drainPerMinute оценивает скорость разряда, %/мин, по измерениям разрядки
+// за последние drainRateWindow. По ёмкости в мАч оценка точнее целых
+// процентов, поэтому проценты – только если ёмкость неизвестна. 0 – данных
+// меньше минуты.
+func drainPerMinute(ms []Measurement) float64 {
+	last := ms[len(ms)-1]
+	lastTime := parseStoredTime(last.Timestamp)
+	first := last
+	for i := len(ms) - 2; i >= 0; i-- {
+		m := ms[i]
+		if strings.ToLower(m.State) != "discharging" || lastTime.Sub(parseStoredTime(m.Timestamp)) > drainRateWindow {
+			break
+		}
+		first = m
+	}
+	minutes := lastTime.Sub(parseStoredTime(first.Timestamp)).Minutes()
+	if minutes < 1 {
+		return 0
+	}
+	if first.CurrentCapacity > 0 && last.FullChargeCap > 0 {
+		used := float64(first.CurrentCapacity-last.CurrentCapacity) / float64(last.FullChargeCap) * 100
+		return used / minutes
+	}
+	return float64(first.Percentage-last.Percentage) / minutes
+}
+
+// NextInterval возвращает интервал до следующего измерения по политике из
+// config.json; base – интервал опроса из настроек. Вызывается из цикла
+// опроса, пока измерение может собираться в другой горутине, поэтому
+// состояние берется только из буфера и БД.
+func (dc *DataCollector) NextInterval(base time.Duration) time.Duration {
+	cfg := getConfig()
+	s := samplingState{Base: base, Recent: dc.buffer.GetLast(20)}
+	if len(s.Recent) == 0 {
+		return base
+	}
+	s.LowBattery = isLowBattery(s.Recent[len(s.Recent)-1], cfg.Power.LowBatteryThreshold)
+	if t, err := getActiveCalibration(dc.db); err == nil && t != nil {
+		s.Calibrating = t.Status == calibrationRunning
+	}
+	policy, _ := newSamplingPolicy(cfg.Collector.Sampling) // ошибка выводится при запуске коллектора
+	return policy.Interval(s)
+}
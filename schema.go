@@ -0,0 +1,264 @@
+// schema.go
+//
+// batmon schema: машиночитаемое описание форматов, с которыми работают
+// внешние инструменты, – схема БД (таблицы, столбцы и индексы после всех
+// миграций) и JSON Schema выгрузок (отчет JSON, status --json, измерения
+// HTTP API, столбцы CSV). Схема БД снимается с пустой базы в памяти, а JSON
+// Schema строится по тегам json структур, поэтому описание не расходится с
+// кодом: утилита может сверить его со своей версией и понять, совместима ли
+// она с установленным batmon.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// jsonSchemaDialect – версия JSON Schema в поле $schema
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaDoc – описание схемы БД и выгрузок
+type SchemaDoc struct {
+	BatmonVersion string            `json:"batmon_version"`
+	SchemaVersion int               `json:"schema_version"` // последняя миграция
+	Migrations    []SchemaMigration `json:"migrations"`
+	Tables        []SchemaTable     `json:"tables"`
+	Exports       []SchemaExport    `json:"exports"`
+	CSVColumns    []string          `json:"csv_columns"`
+}
+
+// SchemaMigration – миграция схемы БД
+type SchemaMigration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+// SchemaTable – таблица БД
+type SchemaTable struct {
+	Name    string         `json:"name"`
+	Columns []SchemaColumn `json:"columns"`
+	Indexes []SchemaIndex  `json:"indexes,omitempty"`
+}
+
+// SchemaColumn – столбец таблицы
+type SchemaColumn struct {
+	Name       string  `db:"name" json:"name"`
+	Type       string  `db:"type" json:"type"`
+	NotNull    bool    `db:"notnull" json:"not_null"`
+	Default    *string `db:"dflt_value" json:"default,omitempty"`
+	PrimaryKey bool    `db:"pk" json:"primary_key"`
+}
+
+// SchemaIndex – индекс таблицы
+type SchemaIndex struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique"`
+	Columns []string `json:"columns"`
+}
+
+// SchemaExport – JSON-выгрузка и ее JSON Schema
+type SchemaExport struct {
+	Name   string      `json:"name"`
+	Source string      `json:"source"` // где выгрузка встречается
+	Schema *JSONSchema `json:"schema"`
+}
+
+// JSONSchema – подмножество JSON Schema, которого хватает для структур batmon
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// schemaExports – описываемые JSON-выгрузки
+var schemaExports = []struct {
+	name   string
+	source string // ключ перевода
+	value  interface{}
+}{
+	{"report", "schema.source.report", reportJSON{}},
+	{"status", "schema.source.status", BatteryStatus{}},
+	{"measurement", "schema.source.measurement", Measurement{}},
+	{"health", "schema.source.health", apiHealth{}},
+}
+
+// buildSchemaDoc собирает описание: схему БД – с пустой базы в памяти после
+// всех миграций, JSON Schema – по структурам выгрузок
+func buildSchemaDoc() (*SchemaDoc, error) {
+	db, err := initDB(":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	doc := &SchemaDoc{
+		BatmonVersion: getVersion(),
+		SchemaVersion: latestSchemaVersion(),
+		CSVColumns:    csvColumns,
+	}
+	for _, m := range migrations {
+		doc.Migrations = append(doc.Migrations, SchemaMigration{Version: m.Version, Name: migrationTitle(m.Version, m.Name)})
+	}
+	if doc.Tables, err = describeTables(db); err != nil {
+		return nil, err
+	}
+	for _, e := range schemaExports {
+		s := jsonSchemaFor(reflect.TypeOf(e.value))
+		s.Schema = jsonSchemaDialect
+		s.Title = e.name
+		doc.Exports = append(doc.Exports, SchemaExport{Name: e.name, Source: T(e.source), Schema: s})
+	}
+	return doc, nil
+}
+
+// describeTables читает таблицы, столбцы и индексы из sqlite_master
+func describeTables(db *sqlx.DB) ([]SchemaTable, error) {
+	var names []string
+	if err := db.Select(&names, `SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`); err != nil {
+		return nil, fmt.Errorf(T("schema.err.tables"), err)
+	}
+	tables := make([]SchemaTable, 0, len(names))
+	for _, name := range names {
+		table := SchemaTable{Name: name}
+		if err := db.Select(&table.Columns, `SELECT name, type, "notnull", dflt_value, pk > 0 AS pk FROM pragma_table_info(?) ORDER BY cid`, name); err != nil {
+			return nil, fmt.Errorf(T("schema.err.columns"), name, err)
+		}
+		var indexes []struct {
+			Name   string `db:"name"`
+			Unique bool   `db:"unique"`
+		}
+		if err := db.Select(&indexes, `SELECT name, "unique" FROM pragma_index_list(?) ORDER BY name`, name); err != nil {
+			return nil, fmt.Errorf(T("schema.err.indexes"), name, err)
+		}
+		for _, idx := range indexes {
+			index := SchemaIndex{Name: idx.Name, Unique: idx.Unique}
+			if err := db.Select(&index.Columns, `SELECT name FROM pragma_index_info(?) ORDER BY seqno`, idx.Name); err != nil {
+				return nil, fmt.Errorf(T("schema.err.index"), idx.Name, err)
+			}
+			table.Indexes = append(table.Indexes, index)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// jsonSchemaFor строит JSON Schema типа по тем же правилам, что encoding/json:
+// имя поля из тега json, поля с omitempty необязательны, встроенные структуры
+// без тега раскрываются
+func jsonSchemaFor(t reflect.Type) *JSONSchema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonSchemaFor(t.Elem())
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: jsonSchemaFor(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: jsonSchemaFor(t.Elem())}
+	case reflect.Struct:
+		s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+		addStructFields(s, t)
+		return s
+	}
+	return &JSONSchema{}
+}
+
+// addStructFields добавляет поля структуры в свойства схемы
+func addStructFields(s *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addStructFields(s, f.Type)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = jsonSchemaFor(f.Type)
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// runSchemaCommand выводит схему БД и выгрузок: --json – для программ,
+// без флага – кратко для человека
+func runSchemaCommand(args []string) error {
+	fs := newCommandFlags("schema")
+	asJSON := fs.Bool("json", false, localized("flag.schema.json", "вывести описание в JSON"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	doc, err := buildSchemaDoc()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	}
+
+	fmt.Println(T("schema.title", doc.SchemaVersion, doc.BatmonVersion))
+	for _, table := range doc.Tables {
+		fmt.Printf("\n📋 %s\n", table.Name)
+		for _, c := range table.Columns {
+			var attrs []string
+			if c.PrimaryKey {
+				attrs = append(attrs, "PRIMARY KEY")
+			}
+			if c.NotNull {
+				attrs = append(attrs, "NOT NULL")
+			}
+			if c.Default != nil {
+				attrs = append(attrs, "DEFAULT "+*c.Default)
+			}
+			fmt.Printf("   %-28s %-8s %s\n", c.Name, c.Type, strings.Join(attrs, " "))
+		}
+		for _, idx := range table.Indexes {
+			kind := T("schema.index")
+			if idx.Unique {
+				kind = T("schema.unique_index")
+			}
+			fmt.Printf("   📇 %s %s (%s)\n", kind, idx.Name, strings.Join(idx.Columns, ", "))
+		}
+	}
+	fmt.Println(T("schema.exports"))
+	for _, e := range doc.Exports {
+		fmt.Println(T("schema.export_row", e.Name, len(e.Schema.Properties), e.Source))
+	}
+	fmt.Println(T("schema.csv_row", "csv", len(doc.CSVColumns)))
+	fmt.Println(T("schema.json_hint"))
+	return nil
+}
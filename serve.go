@@ -0,0 +1,323 @@
+// serve.go
+//
+// Локальный HTTP API (batmon serve): последние измерения и отчет в JSON
+// для виджетов и скриптов, поток новых измерений через Server-Sent Events. По умолчанию слушает только loopback –
+// наружу batmon данные не отдаёт. Эти адреса без версии не требуют токена, но
+// отвечают только запросам с этой машины; с --remote токен нужен и им.
+// Версионированный API с токеном – в api.go.
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultServeAddr   = "127.0.0.1:8787"
+	serveMaxLimit      = 1000
+	streamPollInterval = 2 * time.Second  // как часто поток проверяет новые измерения в БД
+	streamKeepAlive    = 15 * time.Second // комментарий-пинг, чтобы прокси не рвали соединение
+	streamMaxBatchSize = 100              // измерений за одну проверку
+)
+
+// checkLoopbackAddr проверяет, что адрес привязан к loopback-интерфейсу
+func checkLoopbackAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf(T("serve.err.addr"), addr, err)
+	}
+	if !isLoopbackHost(host) {
+		return fmt.Errorf(T("serve.err.loopback"), addr)
+	}
+	return nil
+}
+
+// isLoopbackHost – имя или IP без порта указывает на loopback
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// loopbackOnly пропускает к адресам без токена только запросы с этой машины,
+// адресованные loopback-имени. Проверка Host защищает от DNS rebinding:
+// иначе любая открытая в браузере страница могла бы перепривязать свой домен
+// на 127.0.0.1 и читать измерения.
+func loopbackOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if checkLoopbackAddr(r.RemoteAddr) != nil || !isLoopbackHost(host) {
+			writeJSONError(w, http.StatusForbidden, errors.New(T("serve.err.unversioned")))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeJSON отправляет ответ в JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logWarnf("⚠️ Ответ API: %v", err)
+	}
+}
+
+// writeJSONError отправляет ошибку в JSON
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// newServeMux регистрирует обработчики API. /api/v1 всегда требует токен.
+// Адреса без версии (/api/latest, /api/measurements, /api/report, /api/stream)
+// остались для локальных виджетов и без --remote отвечают без токена, но
+// только запросам с loopback (loopbackOnly); с remote токен нужен и для них.
+func newServeMux(db *sqlx.DB, token string, remote bool) *http.ServeMux {
+	mux := http.NewServeMux()
+	legacy := func(h http.HandlerFunc) http.Handler {
+		if remote {
+			return requireToken(token, h)
+		}
+		return loopbackOnly(h)
+	}
+	mux.Handle("GET /api/latest", legacy(handleLatest(db)))
+	mux.Handle("GET /api/measurements", legacy(handleMeasurements(db)))
+	mux.Handle("GET /api/report", legacy(func(w http.ResponseWriter, r *http.Request) {
+		data, err := generateReportData(db)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, data)
+	}))
+	mux.Handle("GET /api/stream", legacy(func(w http.ResponseWriter, r *http.Request) {
+		streamMeasurements(w, r, db)
+	}))
+	registerAPIv1(mux, db, token)
+	return mux
+}
+
+// handleLatest – последнее измерение
+func handleLatest(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ms, err := getLastNMeasurements(db, 1)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if len(ms) == 0 {
+			writeJSONError(w, http.StatusNotFound, errors.New(T("serve.err.no_measurements")))
+			return
+		}
+		writeJSON(w, http.StatusOK, ms[0])
+	}
+}
+
+// handleMeasurements – страница измерений: limit новых, before – timestamp
+// первого измерения предыдущей страницы
+func handleMeasurements(db *sqlx.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 100
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 || n > serveMaxLimit {
+				writeJSONError(w, http.StatusBadRequest, fmt.Errorf(T("serve.err.limit"), serveMaxLimit))
+				return
+			}
+			limit = n
+		}
+		ms, err := getMeasurementsBefore(db, r.URL.Query().Get("before"), limit)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if ms == nil {
+			ms = []Measurement{}
+		}
+		writeJSON(w, http.StatusOK, ms)
+	}
+}
+
+// streamCursor – последнее отправленное потоком измерение. Поток идет по
+// времени измерений, а не по id: импорт истории добавляет старые измерения
+// с новыми id, и поток по id выдал бы их за новые.
+type streamCursor struct {
+	Timestamp string
+	ID        int // различает измерения с одинаковым временем
+}
+
+// advance переносит курсор на отправленное измерение
+func (c *streamCursor) advance(m Measurement) {
+	c.Timestamp, c.ID = m.Timestamp, m.ID
+}
+
+// getMeasurementsAfter возвращает измерения после курсора по времени
+func getMeasurementsAfter(db *sqlx.DB, cur streamCursor, limit int) ([]Measurement, error) {
+	var ms []Measurement
+	query := `SELECT * FROM measurements WHERE timestamp > ? OR (timestamp = ? AND id > ?)
+		ORDER BY timestamp, id LIMIT ?`
+	if err := db.Select(&ms, query, cur.Timestamp, cur.Timestamp, cur.ID, limit); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// writeSSEMeasurement отправляет измерение событием SSE; id события – id измерения,
+// поэтому браузер после переподключения продолжит с места обрыва (Last-Event-ID)
+func writeSSEMeasurement(w io.Writer, m Measurement) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: measurement\ndata: %s\n\n", m.ID, raw)
+	return err
+}
+
+// streamStart возвращает курсор, с которого поток начинает отправку:
+// resume – id последнего полученного клиентом измерения, иначе (и если это
+// измерение уже удалено очисткой) первым уйдет последнее измерение
+func streamStart(db *sqlx.DB, resume string) (streamCursor, error) {
+	if id, err := strconv.Atoi(resume); err == nil && id >= 0 {
+		var m Measurement
+		err := db.Get(&m, `SELECT * FROM measurements WHERE id = ?`, id)
+		if err == nil {
+			return streamCursor{Timestamp: m.Timestamp, ID: m.ID}, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return streamCursor{}, err
+		}
+	}
+	latest, err := getLastNMeasurements(db, 1)
+	if err != nil || len(latest) == 0 {
+		return streamCursor{}, err
+	}
+	return streamCursor{Timestamp: latest[0].Timestamp, ID: latest[0].ID - 1}, nil
+}
+
+// streamMeasurements – поток новых измерений в формате Server-Sent Events.
+// Измерения пишет другой процесс batmon, поэтому поток опрашивает БД.
+// При подключении сразу отправляется последнее измерение.
+func streamMeasurements(w http.ResponseWriter, r *http.Request, db *sqlx.DB) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, errors.New(T("serve.err.streaming")))
+		return
+	}
+
+	cursor, err := streamStart(db, r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamPollInterval.Milliseconds())
+	flusher.Flush()
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	send := func() bool {
+		ms, err := getMeasurementsAfter(db, cursor, streamMaxBatchSize)
+		if err != nil {
+			logWarnf("⚠️ Поток измерений: %v", err)
+			return true // БД может быть занята, попробуем на следующем тике
+		}
+		for _, m := range ms {
+			if err := writeSSEMeasurement(w, m); err != nil {
+				return false
+			}
+			cursor.advance(m)
+		}
+		if len(ms) > 0 {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	if !send() {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-poll.C:
+			if !send() {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// runServeCommand запускает HTTP API; batmon serve token выводит токен /api/v1
+func runServeCommand(args []string) error {
+	fs := newCommandFlags("serve")
+	addr := fs.String("addr", defaultServeAddr, localized("flag.serve.addr", "адрес для прослушивания"))
+	remote := fs.Bool("remote", false, localized("flag.serve.remote", "разрешить адрес не на loopback (токен нужен для всех запросов)"))
+	rotate := fs.Bool("rotate", false, localized("flag.serve.rotate", "token: выпустить новый токен"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.Arg(0) == "token" {
+		token, _, err := loadAPIToken(*rotate)
+		if err != nil {
+			return err
+		}
+		fmt.Println(token)
+		return nil
+	}
+	if !*remote {
+		if err := checkLoopbackAddr(*addr); err != nil {
+			return fmt.Errorf(T("serve.err.remote"), err)
+		}
+	}
+	token, created, err := loadAPIToken(false)
+	if err != nil {
+		return err
+	}
+	if created {
+		// в консоль, а не в лог: файл лога прикладывают к сообщениям об ошибках
+		fmt.Println(T("serve.token_created", apiTokenPath(), token))
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newServeMux(db, token, *remote),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	logInfof("🌐 API доступен на http://%s/api/v1 (токен: batmon serve token)", *addr)
+	return server.ListenAndServe()
+}
@@ -0,0 +1,125 @@
+// session_store.go
+//
+// Таблица sessions: сессии разрядки и зарядки, сохранённые для просмотра
+// на вкладке «Сессии» отчета. Пересчитываются из измерений начиная с
+// последней сохранённой (ещё открытой) сессии.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sessionSyncInterval – как часто коллектор обновляет таблицу sessions
+const sessionSyncInterval = 5 * time.Minute
+
+// sessionsSchema – таблица сессий разрядки и зарядки
+const sessionsSchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind TEXT NOT NULL,
+	start_time TEXT NOT NULL UNIQUE,
+	end_time TEXT NOT NULL,
+	start_percent INTEGER NOT NULL,
+	end_percent INTEGER NOT NULL,
+	duration_seconds INTEGER NOT NULL,
+	avg_rate REAL NOT NULL DEFAULT 0,
+	measurements INTEGER NOT NULL
+);`
+
+// SessionRecord – сохранённая сессия разрядки или зарядки
+type SessionRecord struct {
+	ID              int     `db:"id" json:"id"`
+	Kind            string  `db:"kind" json:"kind"` // discharge или charge
+	StartTime       string  `db:"start_time" json:"start_time"`
+	EndTime         string  `db:"end_time" json:"end_time"`
+	StartPercent    int     `db:"start_percent" json:"start_percent"`
+	EndPercent      int     `db:"end_percent" json:"end_percent"`
+	DurationSeconds int     `db:"duration_seconds" json:"duration_seconds"`
+	AvgRate         float64 `db:"avg_rate" json:"avg_rate"` // средний расход/прирост, %/ч
+	Measurements    int     `db:"measurements" json:"measurements"`
+}
+
+// Duration возвращает длительность сессии
+func (r SessionRecord) Duration() time.Duration {
+	return time.Duration(r.DurationSeconds) * time.Second
+}
+
+// Start возвращает начало сессии в местном времени
+func (r SessionRecord) Start() time.Time {
+	t, _ := time.Parse(time.RFC3339, r.StartTime)
+	return t.Local()
+}
+
+// KindLabel возвращает подпись вида сессии
+func (r SessionRecord) KindLabel() string {
+	if r.Kind == sessionCharge {
+		return T("session.kind.charge")
+	}
+	return T("session.kind.discharge")
+}
+
+// syncSessions пересчитывает сессии начиная с последней сохранённой:
+// она могла продолжиться, поэтому удаляется и записывается заново.
+// Сессии «от сети без зарядки» и из одного измерения не сохраняются.
+func syncSessions(db *sqlx.DB) error {
+	var lastStart string
+	if err := db.Get(&lastStart, `SELECT COALESCE(MAX(start_time), '') FROM sessions`); err != nil {
+		return fmt.Errorf(T("sessions.err.read"), err)
+	}
+
+	var since time.Time
+	if lastStart != "" {
+		since, _ = time.Parse(time.RFC3339, lastStart)
+	}
+	ms, err := getMeasurementsSince(db, since)
+	if err != nil {
+		return fmt.Errorf(T("sessions.err.measurements"), err)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf(T("sessions.err.transaction"), err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM sessions WHERE start_time >= ?`, lastStart); err != nil {
+		return fmt.Errorf(T("sessions.err.delete_open"), err)
+	}
+	for _, s := range detectSessions(ms) {
+		if s.Kind == sessionIdle || s.Measurements < 2 {
+			continue
+		}
+		_, err := tx.Exec(`INSERT INTO sessions (kind, start_time, end_time, start_percent, end_percent,
+			duration_seconds, avg_rate, measurements) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			s.Kind, s.Start.UTC().Format(time.RFC3339), s.End.UTC().Format(time.RFC3339),
+			s.StartPercent, s.EndPercent, int(s.Duration().Seconds()), s.RatePerHour(), s.Measurements)
+		if err != nil {
+			return fmt.Errorf(T("sessions.err.save"), err)
+		}
+	}
+	return tx.Commit()
+}
+
+// getSessions возвращает сессии периода (новые первыми), для пустого периода – последние limit
+func getSessions(db *sqlx.DB, rng ReportRange, limit int) ([]SessionRecord, error) {
+	var sessions []SessionRecord
+	var err error
+	switch {
+	case rng.IsZero():
+		err = db.Select(&sessions, `SELECT * FROM sessions ORDER BY start_time DESC LIMIT ?`, limit)
+	case rng.To.IsZero():
+		err = db.Select(&sessions, `SELECT * FROM sessions WHERE end_time >= ? ORDER BY start_time DESC`,
+			rng.From.UTC().Format(time.RFC3339))
+	default:
+		err = db.Select(&sessions, `SELECT * FROM sessions WHERE end_time >= ? AND start_time <= ? ORDER BY start_time DESC`,
+			rng.From.UTC().Format(time.RFC3339), rng.To.UTC().Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("sessions.err.read"), err)
+	}
+	return sessions, nil
+}
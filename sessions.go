@@ -0,0 +1,228 @@
+// sessions.go
+//
+// Сессии работы (непрерывные отрезки от батареи или от сети) и сводки по
+// дням. Сессии определяются по абсолютному времени, поэтому полночь,
+// переход на летнее время и смена часового пояса их не разрывают.
+// На календарные дни сессии делятся уже в заданном часовом поясе:
+// границы дня считаются через time.Date, так что сутки при переводе
+// часов длятся 23 или 25 часов.
+
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// sessionMaxGap – пропуск в данных, после которого начинается новая сессия
+// (сон, выключение, остановка сбора)
+const sessionMaxGap = 20 * time.Minute
+
+// Виды сессий
+const (
+	sessionDischarge = "discharge" // работа от батареи
+	sessionCharge    = "charge"    // зарядка
+	sessionIdle      = "idle"      // от сети без зарядки (заряжена, удержание заряда)
+)
+
+// BatterySession – непрерывный отрезок работы в одном режиме питания
+type BatterySession struct {
+	Start        time.Time
+	End          time.Time
+	Kind         string // sessionDischarge, sessionCharge или sessionIdle
+	OnBattery    bool
+	StartPercent int
+	EndPercent   int
+	Measurements int
+
+	points []sessionPoint // измерения сессии для разбивки по дням
+}
+
+// sessionPoint – измерение с разобранным временем
+type sessionPoint struct {
+	at     time.Time
+	pct    int
+	screen bool // экран включен: крышка открыта и яркость известна
+}
+
+// Duration возвращает длительность сессии
+func (s BatterySession) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// RatePerHour возвращает среднюю скорость изменения заряда в %/ч:
+// расход для разрядки, прирост для зарядки
+func (s BatterySession) RatePerHour() float64 {
+	hours := s.Duration().Hours()
+	if hours <= 0 {
+		return 0
+	}
+	delta := s.EndPercent - s.StartPercent
+	if s.Kind == sessionDischarge {
+		delta = -delta
+	}
+	return float64(delta) / hours
+}
+
+// Drain возвращает израсходованный за сессию заряд в процентах
+func (s BatterySession) Drain() int {
+	if d := s.StartPercent - s.EndPercent; d > 0 {
+		return d
+	}
+	return 0
+}
+
+// DailySummary – использование батареи за календарный день
+type DailySummary struct {
+	Date        time.Time     // полночь дня в часовом поясе сводки
+	BatteryTime time.Duration // время работы от батареи
+	ACTime      time.Duration // время работы от сети
+	ChargeTime  time.Duration // из них на зарядке
+	ScreenTime  time.Duration // время с включенным экраном (если яркость записывается)
+	Drain       float64       // израсходовано заряда, %
+	Sessions    int           // сессий, затронувших день
+}
+
+// FullCycles возвращает израсходованный заряд в эквивалентах полной разрядки
+func (d DailySummary) FullCycles() float64 {
+	return d.Drain / 100
+}
+
+// sessionKind определяет вид сессии по состоянию питания
+func sessionKind(state string) string {
+	switch strings.ToLower(state) {
+	case "discharging":
+		return sessionDischarge
+	case "charging":
+		return sessionCharge
+	}
+	return sessionIdle
+}
+
+// detectSessions делит измерения на сессии. Порядок входных данных и
+// смещение часового пояса в метках времени значения не имеют: измерения
+// сортируются по абсолютному времени, дубликаты одного момента отбрасываются.
+func detectSessions(ms []Measurement) []BatterySession {
+	type point struct {
+		at     time.Time
+		pct    int
+		kind   string
+		screen bool
+	}
+	points := make([]point, 0, len(ms))
+	for _, m := range ms {
+		at, err := time.Parse(time.RFC3339, m.Timestamp)
+		if err != nil {
+			continue
+		}
+		screen := m.Brightness > 0 && m.LidState != lidClosed
+		points = append(points, point{at: at, pct: m.Percentage, kind: sessionKind(m.State), screen: screen})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].at.Before(points[j].at) })
+
+	var sessions []BatterySession
+	var current *BatterySession
+	var last time.Time
+	for _, p := range points {
+		if current != nil && p.at.Equal(last) {
+			continue // одно и то же измерение, записанное дважды
+		}
+		if current == nil || p.kind != current.Kind || p.at.Sub(last) > sessionMaxGap {
+			if current != nil {
+				sessions = append(sessions, *current)
+			}
+			current = &BatterySession{Start: p.at, Kind: p.kind, OnBattery: p.kind == sessionDischarge, StartPercent: p.pct}
+		}
+		current.End = p.at
+		current.EndPercent = p.pct
+		current.Measurements++
+		current.points = append(current.points, sessionPoint{at: p.at, pct: p.pct, screen: p.screen})
+		last = p.at
+	}
+	if current != nil {
+		sessions = append(sessions, *current)
+	}
+	return sessions
+}
+
+// startOfDay возвращает полночь дня, в который попадает t, в поясе loc
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// nextDay возвращает полночь следующего дня. AddDate, а не Add(24h):
+// при переводе часов сутки короче или длиннее 24 часов.
+func nextDay(day time.Time) time.Time {
+	return day.AddDate(0, 0, 1)
+}
+
+// summarizeByDay распределяет сессии по календарным дням пояса loc.
+// Интервал между соседними измерениями, пересекающий полночь, делится
+// между днями пропорционально времени, так же делится и расход заряда.
+func summarizeByDay(sessions []BatterySession, loc *time.Location) []DailySummary {
+	if loc == nil {
+		loc = time.Local
+	}
+	byDay := make(map[int64]*DailySummary)
+	touch := func(day time.Time) *DailySummary {
+		key := day.Unix()
+		if s, ok := byDay[key]; ok {
+			return s
+		}
+		s := &DailySummary{Date: day}
+		byDay[key] = s
+		return s
+	}
+
+	for _, session := range sessions {
+		seen := make(map[int64]bool)
+		mark := func(day time.Time) {
+			if !seen[day.Unix()] {
+				seen[day.Unix()] = true
+				touch(day).Sessions++
+			}
+		}
+		mark(startOfDay(session.Start, loc))
+
+		for i := 1; i < len(session.points); i++ {
+			from, to := session.points[i-1], session.points[i]
+			total := to.at.Sub(from.at)
+			drop := float64(from.pct - to.pct)
+
+			for start := from.at; start.Before(to.at); {
+				day := startOfDay(start, loc)
+				end := nextDay(day)
+				if end.After(to.at) {
+					end = to.at
+				}
+				part := end.Sub(start)
+				summary := touch(day)
+				mark(day)
+				if from.screen {
+					summary.ScreenTime += part
+				}
+				if session.OnBattery {
+					summary.BatteryTime += part
+					if drop > 0 {
+						summary.Drain += drop * float64(part) / float64(total)
+					}
+				} else {
+					summary.ACTime += part
+					if session.Kind == sessionCharge {
+						summary.ChargeTime += part
+					}
+				}
+				start = end
+			}
+		}
+	}
+
+	result := make([]DailySummary, 0, len(byDay))
+	for _, s := range byDay {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+	_ "time/tzdata" // тесты не зависят от базы часовых поясов системы
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("часовой пояс %s: %v", name, err)
+	}
+	return loc
+}
+
+// dischargeSeries строит измерения разрядки с шагом step, заряд падает на 1% за шаг
+func dischargeSeries(start time.Time, step time.Duration, n, startPct int) []Measurement {
+	ms := make([]Measurement, n)
+	for i := range ms {
+		ms[i] = Measurement{
+			Timestamp:  start.Add(time.Duration(i) * step).UTC().Format(time.RFC3339),
+			Percentage: startPct - i,
+			State:      "discharging",
+		}
+	}
+	return ms
+}
+
+func TestSessionAcrossMidnight(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	start := time.Date(2025, 1, 14, 23, 30, 0, 0, berlin)
+	ms := dischargeSeries(start, 10*time.Minute, 7, 80) // 23:30 – 00:30
+
+	sessions := detectSessions(ms)
+	if len(sessions) != 1 {
+		t.Fatalf("сессий %d, ожидалась 1", len(sessions))
+	}
+	if got := sessions[0].Duration(); got != time.Hour {
+		t.Errorf("длительность %v, ожидался 1ч", got)
+	}
+
+	days := summarizeByDay(sessions, berlin)
+	if len(days) != 2 {
+		t.Fatalf("дней %d, ожидалось 2", len(days))
+	}
+	for _, d := range days {
+		if d.BatteryTime != 30*time.Minute {
+			t.Errorf("%s: от батареи %v, ожидалось 30м", d.Date.Format("02.01"), d.BatteryTime)
+		}
+		if d.Sessions != 1 {
+			t.Errorf("%s: сессий %d, ожидалась 1", d.Date.Format("02.01"), d.Sessions)
+		}
+		if math.Abs(d.Drain-3) > 1e-9 {
+			t.Errorf("%s: расход %.2f%%, ожидалось 3%%", d.Date.Format("02.01"), d.Drain)
+		}
+	}
+	if days[1].Date != time.Date(2025, 1, 15, 0, 0, 0, 0, berlin) {
+		t.Errorf("второй день начинается %v", days[1].Date)
+	}
+}
+
+func TestSessionAcrossSpringForward(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	// 30.03.2025 в 02:00 часы переводятся на 03:00: 01:30 CET – 04:00 CEST это 1.5 часа
+	start := time.Date(2025, 3, 30, 1, 30, 0, 0, berlin)
+	ms := dischargeSeries(start, 10*time.Minute, 10, 90)
+
+	sessions := detectSessions(ms)
+	if len(sessions) != 1 {
+		t.Fatalf("сессий %d, ожидалась 1", len(sessions))
+	}
+	if got := sessions[0].Duration(); got != 90*time.Minute {
+		t.Errorf("длительность %v, ожидалось 1.5ч", got)
+	}
+	if local := sessions[0].End.In(berlin).Format("15:04"); local != "04:00" {
+		t.Errorf("конец сессии по местному времени %s, ожидалось 04:00", local)
+	}
+
+	days := summarizeByDay(sessions, berlin)
+	if len(days) != 1 || days[0].BatteryTime != 90*time.Minute {
+		t.Fatalf("сводка по дням: %+v", days)
+	}
+}
+
+func TestSessionAcrossFallBack(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	// 26.10.2025 час 02:00–03:00 повторяется дважды: по местному времени
+	// измерения идут не по порядку, но сессия должна остаться одной
+	start := time.Date(2025, 10, 26, 0, 0, 0, 0, time.UTC) // 02:00 CEST
+	ms := dischargeSeries(start, 10*time.Minute, 10, 70)
+
+	sessions := detectSessions(ms)
+	if len(sessions) != 1 {
+		t.Fatalf("сессий %d, ожидалась 1", len(sessions))
+	}
+	if sessions[0].Measurements != 10 || sessions[0].Drain() != 9 {
+		t.Errorf("измерений %d, расход %d%%", sessions[0].Measurements, sessions[0].Drain())
+	}
+
+	days := summarizeByDay(sessions, berlin)
+	if len(days) != 1 || days[0].BatteryTime != 90*time.Minute {
+		t.Fatalf("сводка по дням: %+v", days)
+	}
+}
+
+func TestDSTDayLength(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	cases := []struct {
+		name string
+		day  time.Time
+		want time.Duration
+	}{
+		{"весна", time.Date(2025, 3, 30, 0, 0, 0, 0, berlin), 23 * time.Hour},
+		{"осень", time.Date(2025, 10, 26, 0, 0, 0, 0, berlin), 25 * time.Hour},
+		{"обычный", time.Date(2025, 6, 1, 0, 0, 0, 0, berlin), 24 * time.Hour},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Сессия от батареи с 22:00 предыдущего дня до 01:00 следующего
+			start := tc.day.Add(-2 * time.Hour)
+			end := nextDay(tc.day).Add(time.Hour)
+			n := int(end.Sub(start)/(10*time.Minute)) + 1
+			ms := dischargeSeries(start, 10*time.Minute, n, 100)
+			for i := range ms {
+				ms[i].Percentage = 50 // заряд не важен, проверяем время
+			}
+
+			days := summarizeByDay(detectSessions(ms), berlin)
+			if len(days) != 3 {
+				t.Fatalf("дней %d, ожидалось 3", len(days))
+			}
+			if days[1].Date != tc.day || days[1].BatteryTime != tc.want {
+				t.Errorf("день %v: от батареи %v, ожидалось %v", days[1].Date, days[1].BatteryTime, tc.want)
+			}
+		})
+	}
+}
+
+func TestSessionsAcrossTimezoneTravel(t *testing.T) {
+	// Перелет Москва → Нью-Йорк: метки времени записаны с разными смещениями,
+	// и в строковом виде идут не в хронологическом порядке
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+	newYork := mustLoadLocation(t, "America/New_York")
+	base := time.Date(2025, 6, 1, 20, 30, 0, 0, time.UTC) // 23:30 в Москве, 16:30 в Нью-Йорке
+
+	var ms []Measurement
+	for i := 0; i < 6; i++ {
+		at := base.Add(time.Duration(i) * 10 * time.Minute)
+		loc := moscow
+		if i >= 3 {
+			loc = newYork
+		}
+		ms = append(ms, Measurement{Timestamp: at.In(loc).Format(time.RFC3339), Percentage: 60 - i, State: "discharging"})
+	}
+	// То же измерение, записанное повторно в другом поясе, не должно дублироваться
+	ms = append(ms, Measurement{Timestamp: base.Add(50 * time.Minute).In(moscow).Format(time.RFC3339), Percentage: 55, State: "discharging"})
+
+	sessions := detectSessions(ms)
+	if len(sessions) != 1 {
+		t.Fatalf("сессий %d, ожидалась 1", len(sessions))
+	}
+	s := sessions[0]
+	if s.Measurements != 6 || s.Duration() != 50*time.Minute || s.StartPercent != 60 || s.EndPercent != 55 {
+		t.Errorf("сессия: %d измерений, %v, %d%% → %d%%", s.Measurements, s.Duration(), s.StartPercent, s.EndPercent)
+	}
+
+	// В московском времени сессия пересекает полночь, в нью-йоркском – нет
+	if days := summarizeByDay(sessions, moscow); len(days) != 2 {
+		t.Errorf("дней по Москве %d, ожидалось 2", len(days))
+	}
+	if days := summarizeByDay(sessions, newYork); len(days) != 1 {
+		t.Errorf("дней по Нью-Йорку %d, ожидался 1", len(days))
+	}
+}
+
+func TestSessionBoundaries(t *testing.T) {
+	start := time.Date(2025, 5, 5, 10, 0, 0, 0, time.UTC)
+	ms := dischargeSeries(start, 5*time.Minute, 4, 90)
+	// Пропуск больше sessionMaxGap – сон, новая сессия
+	ms = append(ms, dischargeSeries(start.Add(time.Hour), 5*time.Minute, 3, 80)...)
+	// Подключили зарядку – новая сессия от сети
+	charging := dischargeSeries(start.Add(80*time.Minute), 5*time.Minute, 3, 78)
+	for i := range charging {
+		charging[i].State = "charging"
+	}
+	ms = append(ms, charging...)
+
+	sessions := detectSessions(ms)
+	if len(sessions) != 3 {
+		t.Fatalf("сессий %d, ожидалось 3", len(sessions))
+	}
+	if !sessions[0].OnBattery || !sessions[1].OnBattery || sessions[2].OnBattery {
+		t.Errorf("режимы питания: %v %v %v", sessions[0].OnBattery, sessions[1].OnBattery, sessions[2].OnBattery)
+	}
+
+	days := summarizeByDay(sessions, time.UTC)
+	if len(days) != 1 {
+		t.Fatalf("дней %d, ожидался 1", len(days))
+	}
+	if days[0].Sessions != 3 || days[0].BatteryTime != 25*time.Minute || days[0].ACTime != 10*time.Minute {
+		t.Errorf("сводка: %+v", days[0])
+	}
+}
+
+func TestDischargeCycles(t *testing.T) {
+	at := func(h int) string {
+		return time.Date(2025, 5, 5, h, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+	sessions := []SessionRecord{
+		// Полная разрядка, прерванная сном: две сессии подряд без зарядки
+		{Kind: sessionDischarge, StartTime: at(0), EndTime: at(3), StartPercent: 100, EndPercent: 55},
+		{Kind: sessionDischarge, StartTime: at(8), EndTime: at(11), StartPercent: 54, EndPercent: 7},
+		{Kind: sessionCharge, StartTime: at(11), EndTime: at(13), StartPercent: 7, EndPercent: 100},
+		// Неполная: до 20%
+		{Kind: sessionDischarge, StartTime: at(13), EndTime: at(18), StartPercent: 100, EndPercent: 20},
+		{Kind: sessionCharge, StartTime: at(18), EndTime: at(19), StartPercent: 20, EndPercent: 80},
+		// Неполная: началась с 80%
+		{Kind: sessionDischarge, StartTime: at(19), EndTime: at(23), StartPercent: 80, EndPercent: 5},
+	}
+	cycles := dischargeCycles(sessions)
+	if len(cycles) != 1 {
+		t.Fatalf("циклов %d, ожидался 1: %+v", len(cycles), cycles)
+	}
+	c := cycles[0]
+	if c.StartPercent != 100 || c.EndPercent != 7 || c.End.Sub(c.Start) != 11*time.Hour {
+		t.Errorf("цикл: %+v", c)
+	}
+}
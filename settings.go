@@ -0,0 +1,365 @@
+// settings.go
+//
+// Экран настроек: список параметров из config.json (интервал опроса, срок
+// хранения, пороги проверки, язык, caffeinate), которые меняются клавишами
+// ←/→ и сразу сохраняются в файл и применяются к работающему сбору данных.
+// Последний пункт – очистка базы с подтверждением.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CollectorConfig – параметры сбора данных
+type CollectorConfig struct {
+	Interval      int            `json:"interval"`       // интервал опроса батареи, с
+	RetentionDays int            `json:"retention_days"` // срок хранения измерений, дней
+	Caffeinate    CaffeinateMode `json:"caffeinate"`     // запрет сна: off, calibration или always
+	Sampling      SamplingConfig `json:"sampling"`       // адаптивная частота опроса
+	NetContext    bool           `json:"net_context"`    // записывать трафик сети и число устройств Bluetooth
+}
+
+// defaultCollectorConfig – опрос раз в 30 секунд, хранение 3 месяца
+func defaultCollectorConfig() CollectorConfig {
+	return CollectorConfig{
+		Interval:      int(pmsetInterval / time.Second),
+		RetentionDays: 90,
+		Caffeinate:    caffeinateCalibration,
+		Sampling:      defaultSamplingConfig(),
+	}
+}
+
+// PollInterval возвращает интервал опроса; неверное значение – по умолчанию
+func (c CollectorConfig) PollInterval() time.Duration {
+	if c.Interval <= 0 {
+		return pmsetInterval
+	}
+	return time.Duration(c.Interval) * time.Second
+}
+
+// Retention возвращает срок хранения измерений; неверное значение – по умолчанию
+func (c CollectorConfig) Retention() time.Duration {
+	days := c.RetentionDays
+	if days <= 0 {
+		days = defaultCollectorConfig().RetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+var (
+	settingsIntervals  = []int{10, 15, 30, 60, 120, 300}  // интервалы опроса, с
+	settingsRetentions = []int{30, 60, 90, 180, 365, 730} // сроки хранения, дней
+	settingsLanguages  = []string{"", localeRU, localeEN} // пусто – по LANG
+)
+
+// settingsOption – редактируемый параметр экрана настроек
+type settingsOption struct {
+	label  string                       // id названия в каталоге i18n
+	value  func(cfg Config) string      // текущее значение для экрана
+	change func(cfg *Config, delta int) // delta: -1 – влево, +1 – вправо
+}
+
+// settingsOptions – параметры в порядке на экране
+var settingsOptions = []settingsOption{
+	{
+		label: "settings.interval",
+		value: func(cfg Config) string { return formatDuration(cfg.Collector.PollInterval()) },
+		change: func(cfg *Config, delta int) {
+			cfg.Collector.Interval = stepInt(settingsIntervals, cfg.Collector.Interval, delta)
+		},
+	},
+	{
+		label: "settings.retention",
+		value: func(cfg Config) string { return T("settings.days", int(cfg.Collector.Retention().Hours()/24)) },
+		change: func(cfg *Config, delta int) {
+			cfg.Collector.RetentionDays = stepInt(settingsRetentions, cfg.Collector.RetentionDays, delta)
+		},
+	},
+	{
+		label: "settings.low_battery",
+		value: func(cfg Config) string { return settingsPercent(float64(cfg.Power.LowBatteryThreshold)) },
+		change: func(cfg *Config, delta int) {
+			cfg.Power.LowBatteryThreshold = clampInt(cfg.Power.LowBatteryThreshold+5*delta, 0, 50)
+		},
+	},
+	{
+		label: "settings.wear_warning",
+		value: func(cfg Config) string { return settingsPercent(cfg.Health.WearWarning) },
+		change: func(cfg *Config, delta int) {
+			cfg.Health.WearWarning = float64(clampInt(int(cfg.Health.WearWarning)+delta, 0, 100))
+		},
+	},
+	{
+		label: "settings.wear_critical",
+		value: func(cfg Config) string { return settingsPercent(cfg.Health.WearCritical) },
+		change: func(cfg *Config, delta int) {
+			cfg.Health.WearCritical = float64(clampInt(int(cfg.Health.WearCritical)+delta, 0, 100))
+		},
+	},
+	{
+		label: "settings.cycles_warning",
+		value: func(cfg Config) string { return settingsCount(cfg.Health.CyclesWarning) },
+		change: func(cfg *Config, delta int) {
+			cfg.Health.CyclesWarning = clampInt(cfg.Health.CyclesWarning+50*delta, 0, 5000)
+		},
+	},
+	{
+		label: "settings.cycles_critical",
+		value: func(cfg Config) string { return settingsCount(cfg.Health.CyclesCritical) },
+		change: func(cfg *Config, delta int) {
+			cfg.Health.CyclesCritical = clampInt(cfg.Health.CyclesCritical+50*delta, 0, 5000)
+		},
+	},
+	{
+		label: "settings.language",
+		value: func(cfg Config) string {
+			if cfg.Language == "" {
+				return T("settings.language.auto", currentLocale())
+			}
+			return cfg.Language
+		},
+		change: func(cfg *Config, delta int) {
+			i := 0
+			for j, lang := range settingsLanguages {
+				if lang == normalizeLocale(cfg.Language) {
+					i = j
+				}
+			}
+			cfg.Language = settingsLanguages[(i+delta+len(settingsLanguages))%len(settingsLanguages)]
+		},
+	},
+	{
+		label: "settings.sampling",
+		value: func(cfg Config) string { return T("sampling." + samplingPolicyName(cfg.Collector.Sampling)) },
+		change: func(cfg *Config, delta int) {
+			if samplingPolicyName(cfg.Collector.Sampling) == "fixed" {
+				cfg.Collector.Sampling.Policy = "adaptive"
+			} else {
+				cfg.Collector.Sampling.Policy = "fixed"
+			}
+		},
+	},
+	{
+		label: "settings.caffeinate",
+		value: func(cfg Config) string { return cfg.Collector.Caffeinate.Label() },
+		change: func(cfg *Config, delta int) {
+			cfg.Collector.Caffeinate = cfg.Collector.Caffeinate.Next(delta)
+		},
+	},
+	{
+		label: "settings.score_model",
+		value: func(cfg Config) string { return HealthModel(cfg.Health.ScoreModel).Label() },
+		change: func(cfg *Config, delta int) {
+			cfg.Health.ScoreModel = string(HealthModel(cfg.Health.ScoreModel).Next(delta))
+		},
+	},
+	{
+		label: "settings.net_context",
+		value: func(cfg Config) string {
+			if cfg.Collector.NetContext {
+				return T("settings.on")
+			}
+			return T("settings.off")
+		},
+		change: func(cfg *Config, delta int) {
+			cfg.Collector.NetContext = !cfg.Collector.NetContext
+		},
+	},
+}
+
+// stepInt переходит к соседнему значению из списка; значение не из списка
+// сначала заменяется ближайшим в сторону шага
+func stepInt(steps []int, current, delta int) int {
+	i := len(steps) - 1
+	for j, s := range steps {
+		if s >= current {
+			i = j
+			break
+		}
+	}
+	if steps[i] == current || (delta < 0 && steps[i] > current) {
+		i += delta
+	}
+	return steps[clampInt(i, 0, len(steps)-1)]
+}
+
+// clampInt ограничивает значение диапазоном [lo, hi]
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// settingsPercent форматирует порог в процентах, 0 – выключен
+func settingsPercent(v float64) string {
+	if v <= 0 {
+		return T("settings.off")
+	}
+	return fmt.Sprintf("%.0f%%", v)
+}
+
+// settingsCount форматирует порог-количество, 0 – выключен
+func settingsCount(v int) string {
+	if v <= 0 {
+		return T("settings.off")
+	}
+	return fmt.Sprintf("%d", v)
+}
+
+// SettingsModel – состояние экрана настроек
+type SettingsModel struct {
+	cursor      int    // выбранная строка; len(settingsOptions) – очистка базы
+	confirmWipe bool   // показано подтверждение очистки
+	status      string // итог последнего изменения
+}
+
+// applySettingsChange меняет выбранный параметр, сохраняет config.json и
+// применяет настройки к работающему сбору данных
+func (a *App) applySettingsChange(delta int) {
+	opt := settingsOptions[a.settings.cursor]
+	cfg := getConfig()
+	language := cfg.Language
+	opt.change(&cfg, delta)
+	if err := saveConfig(getConfigPath(), cfg); err != nil {
+		a.settings.status = "❌ " + err.Error()
+		return
+	}
+	setConfig(cfg)
+	a.dataService.applyConfig(cfg.Collector)
+	if cfg.Language != language {
+		a.menu.list.SetItems(mainMenuItems())
+		a.menu.list.Title = T("menu.title")
+	}
+	a.settings.status = "💾 " + T("settings.saved", T(opt.label), opt.value(cfg))
+}
+
+// updateSettings обрабатывает нажатия на экране настроек
+func (a *App) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.settings.confirmWipe {
+		switch msg.String() {
+		case "y", "Y", "д", "Д":
+			if err := a.clearDatabase(); err != nil {
+				a.lastError = fmt.Errorf(T("db.err.clear"), err)
+				a.settings.status = "❌ " + err.Error()
+			} else {
+				a.lastError = nil
+				a.settings.status = "🗑️ " + T("settings.cleared")
+				// Сервис данных создан заново – подписываемся на его коллектор
+				a.settings.confirmWipe = false
+				return a, waitForMeasurement(a.dataService, a.dataService.events, a.dashboard.chartView)
+			}
+			a.settings.confirmWipe = false
+		case "ctrl+c", "q", "й", "n", "N", "н", "Н", "esc":
+			a.settings.confirmWipe = false
+		}
+		return a, nil
+	}
+
+	switch normalizeKeyInput(msg.String()) {
+	case "ctrl+c", "q", "esc":
+		a.state = StateMenu
+	case "up", "k":
+		if a.settings.cursor > 0 {
+			a.settings.cursor--
+		}
+	case "down", "j":
+		if a.settings.cursor < len(settingsOptions) {
+			a.settings.cursor++
+		}
+	case "left", "h", "-":
+		if a.settings.cursor < len(settingsOptions) {
+			a.applySettingsChange(-1)
+		}
+	case "right", "l", "+", "=", "enter", " ":
+		if a.settings.cursor < len(settingsOptions) {
+			a.applySettingsChange(1)
+		} else {
+			a.settings.confirmWipe = true
+		}
+	}
+	return a, nil
+}
+
+// renderSettings рендерит экран настроек или подтверждение очистки БД
+func (a *App) renderSettings() string {
+	if a.settings.confirmWipe {
+		return a.renderClearConfirm()
+	}
+	cfg := getConfig()
+	selected := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(T("settings.title")) + "\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(theme.Border).Render(getConfigPath()) + "\n\n")
+	for i, opt := range settingsOptions {
+		line := fmt.Sprintf("%-32s ‹ %s ›", T(opt.label), opt.value(cfg))
+		if i == a.settings.cursor {
+			line = selected.Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		content.WriteString(line + "\n")
+	}
+	wipe := T("settings.clear")
+	if a.settings.cursor == len(settingsOptions) {
+		wipe = selected.Render("▶ " + wipe)
+	} else {
+		wipe = "  " + wipe
+	}
+	content.WriteString("\n" + wipe + "\n\n")
+	if a.settings.status != "" {
+		content.WriteString(a.settings.status + "\n\n")
+	}
+	content.WriteString("🌐 " + cfg.Network.StatusLine() + "\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(theme.Border).Render(T("settings.help")))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1).
+		Render(content.String())
+}
+
+// renderClearConfirm рендерит подтверждение очистки БД
+func (a *App) renderClearConfirm() string {
+	content := T("clear.title")
+	content += T("clear.warning")
+	content += T("clear.will_delete")
+	content += T("clear.item.measurements")
+	content += T("clear.item.states")
+	content += T("clear.item.stats")
+	content += T("clear.backup")
+	content += T("clear.confirm")
+	content += T("clear.cancel")
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1).
+		Render(content)
+}
+
+// applyConfig применяет настройки сбора к работающему сервису: интервал
+// опроса, срок хранения и caffeinate
+func (ds *DataService) applyConfig(cfg CollectorConfig) {
+	if ds.replay != nil {
+		return
+	}
+	ds.collector.retention.SetPeriod(cfg.Retention())
+	select {
+	case <-ds.interval: // новое значение заменяет еще не прочитанное
+	default:
+	}
+	ds.interval <- cfg.PollInterval()
+	ds.syncCaffeinate()
+}
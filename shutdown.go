@@ -0,0 +1,94 @@
+// shutdown.go
+//
+// Штатное завершение интерфейса: по выходу из TUI или SIGTERM сервис данных
+// останавливает caffeinate, дожидаясь его выхода, снимает последнее
+// измерение, записывает очередь пакетной записи и сводит WAL в основной файл.
+// Раньше процесс мог завершиться посреди этого: в -wal оставались
+// несведенные данные, а caffeinate – запрещать сон без хозяина. Последнее
+// измерение и остановка caffeinate ограничены по времени, чтобы зависший
+// pmset не задерживал выход; итог пишется в журнал одной строкой. Если
+// последнее измерение не успело, очередь все равно записывается (она под
+// мьютексом), но коллектор и база не закрываются: измерение еще идет и может
+// писать в них, а WAL сведется при следующем открытии.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const caffeinateStopTimeout = 2 * time.Second // на выход caffeinate после сигнала
+
+// shutdownSampleTimeout – время на последнее измерение (подменяется в тестах)
+var shutdownSampleTimeout = 5 * time.Second
+
+// errTimeout – runWithTimeout не дождался функции, и она еще выполняется
+var errTimeout = messageError("shutdown.err.timed_out")
+
+// Shutdown штатно завершает работу сервиса; reason попадает в журнал.
+// Повторные вызовы (выход из TUI и сигнал одновременно) ничего не делают.
+func (ds *DataService) Shutdown(reason string) {
+	ds.shutdownOnce.Do(func() { ds.shutdown(reason) })
+}
+
+func (ds *DataService) shutdown(reason string) {
+	started := time.Now()
+	var problems []string
+
+	// caffeinate запущен с контекстом сервиса, и отмена контекста убила бы
+	// его без ожидания – останавливаем раньше
+	ds.stopCaffeinate()
+	// Цикл опроса больше не нужен: последнее измерение снимается здесь
+	ds.cancel()
+	if ds.unsubscribe != nil {
+		ds.unsubscribe()
+	}
+
+	sample := "не снято"
+	if ds.replay != nil {
+		sample = "воспроизведение"
+	} else if err := runWithTimeout(shutdownSampleTimeout, ds.collector.CollectAndStore); errors.Is(err, errTimeout) {
+		queue := "очередь записана"
+		if err := ds.collector.Flush(); err != nil {
+			queue = err.Error()
+		}
+		logWarnf("⚠️ BatMon завершен (%s) без сведения WAL: последнее измерение %v; %s",
+			reason, err, queue)
+		return
+	} else if err != nil {
+		problems = append(problems, fmt.Sprintf("последнее измерение: %v", err))
+	} else {
+		sample = "снято"
+	}
+
+	if err := ds.collector.Close(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := ds.store.Close(); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) > 0 {
+		logWarnf("⚠️ BatMon завершен (%s) с ошибками за %v: %s", reason, time.Since(started).Round(time.Millisecond), strings.Join(problems, "; "))
+		return
+	}
+	logInfof("👋 BatMon завершен штатно (%s) за %v: последнее измерение %s, очередь записана, WAL сведен",
+		reason, time.Since(started).Round(time.Millisecond), sample)
+}
+
+// runWithTimeout выполняет fn не дольше timeout. По истечении времени fn
+// продолжает работать в фоне, а вызывающий получает errTimeout и не должен
+// освобождать то, чем fn пользуется.
+func runWithTimeout(timeout time.Duration, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf(T("shutdown.err.timeout"), timeout, errTimeout)
+	}
+}
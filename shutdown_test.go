@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// blockingSource зависает на Status, как pmset, который не отвечает
+type blockingSource struct {
+	release chan struct{}
+}
+
+func (s blockingSource) Name() string { return "blocking" }
+
+func (s blockingSource) Status() (int, string, error) {
+	<-s.release
+	return 0, "", errors.New("источник остановлен")
+}
+
+func (s blockingSource) Details() (BatteryDetails, error) {
+	return BatteryDetails{}, errors.New("источник остановлен")
+}
+
+// Зависшее последнее измерение не мешает записать очередь: теряется только
+// оно само, а база остается открытой для него
+func TestShutdownFlushesQueueWhenSampleTimesOut(t *testing.T) {
+	freezeEnvironment(t, fixtureStart.Add(time.Hour))
+	timeout := shutdownSampleTimeout
+	t.Cleanup(func() { shutdownSampleTimeout = timeout })
+	shutdownSampleTimeout = 50 * time.Millisecond
+
+	store, err := openStore(filepath.Join(t.TempDir(), "batmon.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := NewDataService(store, NewMemoryBuffer(100))
+	source := blockingSource{release: make(chan struct{})}
+	ds.collector.source = source
+	t.Cleanup(func() {
+		// Дожидаемся зависшего измерения, прежде чем вернуть часы и закрыть базу
+		close(source.release)
+		for deadline := time.Now().Add(time.Second); ds.collector.health.State().Failures == 0 && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+		store.Close()
+	})
+
+	queued := steadyDischarge(fixtureHealthyBattery, fixtureStart, 10*time.Minute, 3)
+	for _, m := range queued {
+		ds.collector.writes.Add(m)
+	}
+	ds.Shutdown("тест")
+
+	var count int
+	if err := ds.db.Get(&count, `SELECT COUNT(*) FROM measurements`); err != nil {
+		t.Fatalf("база закрыта, пока измерение еще идет: %v", err)
+	}
+	if count != len(queued) {
+		t.Errorf("записано %d измерений из очереди, ожидалось %d", count, len(queued))
+	}
+}
@@ -0,0 +1,94 @@
+// sleep.go
+//
+// Периоды сна: пока Mac спит, коллектор не работает, и в истории остается
+// пропуск. Пропуск длиннее sleepGapMin считается сном: он не участвует в
+// расчете скорости разрядки под нагрузкой, а потеря заряда за него
+// показывается отдельно – «разряд во сне: 4% за 8 ч».
+
+package main
+
+import (
+	"time"
+)
+
+// SleepPeriod – пропуск в измерениях, который считается сном
+type SleepPeriod struct {
+	Start        time.Time
+	End          time.Time
+	StartPercent int
+	EndPercent   int
+	OnBattery    bool // до и после сна компьютер работал от батареи
+}
+
+// Duration возвращает длительность сна
+func (p SleepPeriod) Duration() time.Duration {
+	return p.End.Sub(p.Start)
+}
+
+// Drain возвращает потерю заряда за сон, %
+func (p SleepPeriod) Drain() int {
+	return p.StartPercent - p.EndPercent
+}
+
+// isSleepGap сообщает, что пропуск между измерениями – сон, а не обычный интервал опроса
+func isSleepGap(prevAt, currAt time.Time) bool {
+	return currAt.Sub(prevAt) >= sleepGapMin
+}
+
+// detectSleepPeriods находит периоды сна в измерениях
+func detectSleepPeriods(ms []Measurement) []SleepPeriod {
+	var periods []SleepPeriod
+	for i := 1; i < len(ms); i++ {
+		prev, curr := ms[i-1], ms[i]
+		prevAt, currAt := parseStoredTime(prev.Timestamp), parseStoredTime(curr.Timestamp)
+		if prevAt.IsZero() || currAt.IsZero() || !isSleepGap(prevAt, currAt) {
+			continue
+		}
+		periods = append(periods, SleepPeriod{
+			Start:        prevAt,
+			End:          currAt,
+			StartPercent: prev.Percentage,
+			EndPercent:   curr.Percentage,
+			OnBattery:    sessionKind(prev.State) == sessionDischarge && sessionKind(curr.State) == sessionDischarge,
+		})
+	}
+	return periods
+}
+
+// SleepSummary – итоги сна от батареи за период отчета
+type SleepSummary struct {
+	Periods  int
+	Duration time.Duration
+	Drain    int // потеря заряда за все периоды, %
+}
+
+// summarizeSleep суммирует периоды сна от батареи; сон на зарядке не
+// говорит о саморазряде и не учитывается
+func summarizeSleep(periods []SleepPeriod) SleepSummary {
+	var s SleepSummary
+	for _, p := range periods {
+		if !p.OnBattery || p.Drain() < 0 {
+			continue
+		}
+		s.Periods++
+		s.Duration += p.Duration()
+		s.Drain += p.Drain()
+	}
+	return s
+}
+
+// Rate возвращает средний саморазряд во сне, %/ч
+func (s SleepSummary) Rate() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Drain) / s.Duration.Hours()
+}
+
+// String возвращает строку для отчетов; пустая строка – сна от батареи не было
+func (s SleepSummary) String() string {
+	if s.Periods == 0 {
+		return ""
+	}
+	return T("report.sleep", s.Drain, formatDuration(s.Duration), s.Rate(), s.Periods)
+}
@@ -0,0 +1,303 @@
+// snapshot.go
+//
+// Именованные снимки состояния батареи: `batmon snapshot save до-замены`
+// запоминает износ, циклы, скорость разрядки и время работы, а отчет с
+// --compare до-замены показывает «было – стало». Так отвечают на вопрос,
+// помогли ли замена батареи или обновление macOS.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jmoiron/sqlx"
+)
+
+// snapshotDrainWindow – за сколько дней до снимка считается средняя скорость разрядки
+const snapshotDrainWindow = 14 * 24 * time.Hour
+
+// snapshotsSchema – именованные снимки состояния
+const snapshotsSchema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	name TEXT PRIMARY KEY,
+	created_at TEXT NOT NULL,
+	battery_serial TEXT NOT NULL DEFAULT '',
+	full_charge_capacity INTEGER NOT NULL,
+	design_capacity INTEGER NOT NULL,
+	wear REAL NOT NULL,
+	cycle_count INTEGER NOT NULL,
+	health_score INTEGER NOT NULL DEFAULT 0,
+	drain_rate REAL NOT NULL DEFAULT 0,
+	os_version TEXT NOT NULL DEFAULT ''
+);`
+
+// HealthSnapshot – показатели батареи на момент снимка
+type HealthSnapshot struct {
+	Name           string  `db:"name" json:"name"`
+	CreatedAt      string  `db:"created_at" json:"created_at"` // RFC3339 UTC
+	BatterySerial  string  `db:"battery_serial" json:"battery_serial"`
+	FullChargeCap  int     `db:"full_charge_capacity" json:"full_charge_capacity"`
+	DesignCapacity int     `db:"design_capacity" json:"design_capacity"`
+	Wear           float64 `db:"wear" json:"wear"`
+	CycleCount     int     `db:"cycle_count" json:"cycle_count"`
+	HealthScore    int     `db:"health_score" json:"health_score"`
+	DrainRate      float64 `db:"drain_rate" json:"drain_rate"` // средняя разрядка, %/ч; 0 – не измерена
+	OSVersion      string  `db:"os_version" json:"os_version"`
+}
+
+// Runtime возвращает время работы от полного заряда при средней скорости разрядки
+func (s HealthSnapshot) Runtime() time.Duration {
+	if s.DrainRate <= 0 {
+		return 0
+	}
+	return time.Duration(100 / s.DrainRate * float64(time.Hour))
+}
+
+// Date возвращает дату снимка в местном времени
+func (s HealthSnapshot) Date() string {
+	t, err := time.Parse(time.RFC3339, s.CreatedAt)
+	if err != nil {
+		return s.CreatedAt
+	}
+	return t.Local().Format("02.01.2006")
+}
+
+// currentSnapshot снимает текущие показатели из истории
+func currentSnapshot(db *sqlx.DB, name string) (HealthSnapshot, error) {
+	ms, err := getLastNMeasurements(db, reportLastN)
+	if err != nil {
+		return HealthSnapshot{}, fmt.Errorf(T("err.data"), err)
+	}
+	if len(ms) == 0 {
+		return HealthSnapshot{}, errors.New(T("err.no_measurements_collect"))
+	}
+	latest := ms[len(ms)-1]
+	now := timeNow()
+	snapshot := HealthSnapshot{
+		Name:           name,
+		CreatedAt:      now.UTC().Format(time.RFC3339),
+		BatterySerial:  latest.BatterySerial,
+		FullChargeCap:  latest.FullChargeCap,
+		DesignCapacity: latest.DesignCapacity,
+		Wear:           computeWear(latest.DesignCapacity, latest.FullChargeCap),
+		CycleCount:     latest.CycleCount,
+		OSVersion:      osVersion(),
+	}
+	if health := analyzeBatteryHealth(ms); health != nil {
+		snapshot.HealthScore = health.HealthScore
+	}
+	if err := syncSessions(db); err != nil {
+		return HealthSnapshot{}, err
+	}
+	if snapshot.DrainRate, err = averageDrainRate(db, now.Add(-snapshotDrainWindow), now); err != nil {
+		return HealthSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// averageDrainRate возвращает среднюю скорость разрядки (%/ч) по сессиям
+// разрядки периода, взвешенную по длительности
+func averageDrainRate(db *sqlx.DB, from, to time.Time) (float64, error) {
+	var weighted, seconds float64
+	err := db.QueryRow(`SELECT COALESCE(SUM(avg_rate * duration_seconds), 0), COALESCE(SUM(duration_seconds), 0)
+		FROM sessions WHERE kind = ? AND start_time >= ? AND start_time <= ?`,
+		sessionDischarge, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)).Scan(&weighted, &seconds)
+	if err != nil {
+		return 0, fmt.Errorf(T("snapshot.err.rate"), err)
+	}
+	if seconds <= 0 {
+		return 0, nil
+	}
+	return weighted / seconds, nil
+}
+
+// saveSnapshot сохраняет снимок; снимок с тем же именем заменяется
+func saveSnapshot(db *sqlx.DB, s HealthSnapshot) error {
+	_, err := db.NamedExec(`INSERT OR REPLACE INTO snapshots (name, created_at, battery_serial, full_charge_capacity,
+		design_capacity, wear, cycle_count, health_score, drain_rate, os_version)
+		VALUES (:name, :created_at, :battery_serial, :full_charge_capacity, :design_capacity, :wear,
+		:cycle_count, :health_score, :drain_rate, :os_version)`, s)
+	if err != nil {
+		return fmt.Errorf(T("snapshot.err.save"), err)
+	}
+	return nil
+}
+
+// getSnapshot возвращает снимок по имени
+func getSnapshot(db *sqlx.DB, name string) (*HealthSnapshot, error) {
+	var s HealthSnapshot
+	err := db.Get(&s, `SELECT * FROM snapshots WHERE name = ?`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf(T("snapshot.err.not_found_hint"), name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("snapshot.err.read"), err)
+	}
+	return &s, nil
+}
+
+// getSnapshots возвращает все снимки, старые первыми
+func getSnapshots(db *sqlx.DB) ([]HealthSnapshot, error) {
+	var snapshots []HealthSnapshot
+	if err := db.Select(&snapshots, `SELECT * FROM snapshots ORDER BY created_at`); err != nil {
+		return nil, fmt.Errorf(T("snapshot.err.list"), err)
+	}
+	return snapshots, nil
+}
+
+// SnapshotComparison – сравнение сохраненного снимка с текущим состоянием
+type SnapshotComparison struct {
+	Then HealthSnapshot
+	Now  HealthSnapshot
+}
+
+// ComparisonRow – строка таблицы «было – стало»
+type ComparisonRow struct {
+	Label string
+	Then  string
+	Now   string
+	Delta string
+}
+
+// Title возвращает заголовок сравнения
+func (c SnapshotComparison) Title() string {
+	return T("report.compare", c.Then.Name, c.Then.Date())
+}
+
+// BatteryReplaced сообщает, что снимок сделан на другой батарее
+func (c SnapshotComparison) BatteryReplaced() bool {
+	return c.Then.BatterySerial != "" && c.Now.BatterySerial != "" && c.Then.BatterySerial != c.Now.BatterySerial
+}
+
+// Rows возвращает строки сравнения: износ, циклы, полная ёмкость, оценка,
+// скорость разрядки и время работы от полного заряда
+func (c SnapshotComparison) Rows() []ComparisonRow {
+	then, now := c.Then, c.Now
+	rows := []ComparisonRow{
+		{T("report.wear"), fmt.Sprintf("%.1f%%", then.Wear), fmt.Sprintf("%.1f%%", now.Wear), signedFloat(now.Wear-then.Wear) + "%"},
+		{T("report.cycles"), fmt.Sprint(then.CycleCount), fmt.Sprint(now.CycleCount), signedInt(now.CycleCount - then.CycleCount)},
+		{T("report.full_cap"), fmt.Sprintf("%d %s", then.FullChargeCap, T("unit.mah")), fmt.Sprintf("%d %s", now.FullChargeCap, T("unit.mah")),
+			signedInt(now.FullChargeCap - then.FullChargeCap)},
+		{T("report.score"), fmt.Sprint(then.HealthScore), fmt.Sprint(now.HealthScore), signedInt(now.HealthScore - then.HealthScore)},
+	}
+	if then.DrainRate > 0 && now.DrainRate > 0 {
+		rows = append(rows,
+			ComparisonRow{T("report.avg_drain"), fmt.Sprintf("%.1f %s", then.DrainRate, T("unit.pct_per_hour")), fmt.Sprintf("%.1f %s", now.DrainRate, T("unit.pct_per_hour")),
+				signedFloat((now.DrainRate-then.DrainRate)/then.DrainRate*100) + "%"},
+			ComparisonRow{T("report.runtime"), formatDuration(then.Runtime()), formatDuration(now.Runtime()),
+				signedDuration(now.Runtime() - then.Runtime())})
+	}
+	return rows
+}
+
+// signedDuration форматирует изменение длительности с явным знаком
+func signedDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "−" + formatDuration(-d)
+	case d > 0:
+		return "+" + formatDuration(d)
+	}
+	return "±0"
+}
+
+// loadComparison сравнивает снимок name с текущим состоянием
+func loadComparison(db *sqlx.DB, name string) (*SnapshotComparison, error) {
+	then, err := getSnapshot(db, name)
+	if err != nil {
+		return nil, err
+	}
+	now, err := currentSnapshot(db, "")
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotComparison{Then: *then, Now: now}, nil
+}
+
+// printComparison выводит сравнение в терминал
+func printComparison(c SnapshotComparison) {
+	color.Cyan("=== %s ===", c.Title())
+	if c.BatteryReplaced() {
+		fmt.Println("🔁 " + T("report.compare.replaced"))
+	}
+	for _, row := range c.Rows() {
+		fmt.Printf("%-28s %14s → %-14s %s\n", row.Label, row.Then, row.Now, row.Delta)
+	}
+}
+
+// runSnapshotCommand сохраняет, показывает, сравнивает и удаляет снимки
+func runSnapshotCommand(args []string) error {
+	fs := newCommandFlags("snapshot")
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	action := "list"
+	if fs.NArg() > 0 {
+		action = fs.Arg(0)
+	}
+	name := fs.Arg(1)
+	if action != "list" && name == "" {
+		fmt.Fprintln(os.Stderr, T("snapshot.usage", action))
+		return errUsage
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	switch action {
+	case "save":
+		s, err := currentSnapshot(db, name)
+		if err != nil {
+			return err
+		}
+		if err := saveSnapshot(db, s); err != nil {
+			return err
+		}
+		color.Green(T("snapshot.saved"), name, s.Wear, s.CycleCount)
+		return nil
+	case "list":
+		snapshots, err := getSnapshots(db)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			fmt.Println(T("snapshot.empty"))
+			return nil
+		}
+		for _, s := range snapshots {
+			runtime := "—"
+			if s.Runtime() > 0 {
+				runtime = formatDuration(s.Runtime())
+			}
+			fmt.Println(T("snapshot.row", s.Name, s.Date(), s.Wear, s.CycleCount, runtime))
+		}
+		return nil
+	case "compare":
+		c, err := loadComparison(db, name)
+		if err != nil {
+			return err
+		}
+		printComparison(*c)
+		return nil
+	case "delete":
+		res, err := db.Exec(`DELETE FROM snapshots WHERE name = ?`, name)
+		if err != nil {
+			return fmt.Errorf(T("snapshot.err.delete"), err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf(T("snapshot.err.not_found"), name)
+		}
+		fmt.Println(T("snapshot.deleted", name))
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, T("snapshot.unknown_action", action))
+	return errUsage
+}
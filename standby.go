@@ -0,0 +1,196 @@
+// standby.go
+//
+// Разряд в режиме сна («vampire drain»): сколько процентов в час батарея
+// теряет, пока Mac спит от батареи, как это меняется по неделям и как
+// соотносится с ориентиром Apple. Повышенный саморазряд во сне – одна из
+// самых частых жалоб, а без отдельного анализа он незаметен.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	standbyWeeks        = 8   // недель в анализе
+	appleStandbyRate    = 1.0 // ориентир саморазряда во сне, %/ч
+	standbyMinHours     = 4   // часов сна, без которых оценка недели ненадежна
+	standbyAdviceFactor = 1.5 // во сколько раз выше ориентира саморазряд требует внимания
+)
+
+// StandbyWeek – сон от батареи за неделю
+type StandbyWeek struct {
+	WeekStart time.Time // понедельник, местное время
+	Hours     float64   // часов сна от батареи
+	Drain     int       // потеря заряда за сон, %
+	Periods   int
+}
+
+// Label возвращает подпись недели
+func (w StandbyWeek) Label() string {
+	return HotChargingWeek{WeekStart: w.WeekStart}.Label()
+}
+
+// Rate возвращает саморазряд за неделю, %/ч; 0 – сна было слишком мало для оценки
+func (w StandbyWeek) Rate() float64 {
+	if w.Hours < standbyMinHours {
+		return 0
+	}
+	return float64(w.Drain) / w.Hours
+}
+
+// StandbyAnalysis – разряд во сне по неделям, последняя – текущая
+type StandbyAnalysis struct {
+	Weeks []StandbyWeek
+}
+
+// standbyDrainByWeek находит сон от батареи за последние недели по пропускам
+// в измерениях (см. isSleepGap). Сон относится к неделе, в которую начался.
+// Измерения читаются построчно, без загрузки периода в память.
+func standbyDrainByWeek(db *sqlx.DB, weeks int, now time.Time) (StandbyAnalysis, error) {
+	first := weekStart(now).AddDate(0, 0, -7*(weeks-1))
+	rows, err := db.Queryx(`SELECT timestamp, state, percentage FROM measurements
+		WHERE timestamp >= ? ORDER BY timestamp`, first.UTC().Format(time.RFC3339))
+	if err != nil {
+		return StandbyAnalysis{}, fmt.Errorf(T("standby.err.measurements"), err)
+	}
+	defer rows.Close()
+
+	result := StandbyAnalysis{Weeks: make([]StandbyWeek, weeks)}
+	for i := range result.Weeks {
+		result.Weeks[i].WeekStart = first.AddDate(0, 0, 7*i)
+	}
+	var prev *Measurement
+	for rows.Next() {
+		var m Measurement
+		if err := rows.StructScan(&m); err != nil {
+			return StandbyAnalysis{}, fmt.Errorf(T("measurement.err.read"), err)
+		}
+		if prev != nil {
+			for _, p := range detectSleepPeriods([]Measurement{*prev, m}) {
+				idx := int(math.Round(weekStart(p.Start).Sub(first).Hours() / (24 * 7)))
+				if !p.OnBattery || p.Drain() < 0 || idx < 0 || idx >= weeks {
+					continue
+				}
+				result.Weeks[idx].Hours += p.Duration().Hours()
+				result.Weeks[idx].Drain += p.Drain()
+				result.Weeks[idx].Periods++
+			}
+		}
+		prev = &m
+	}
+	if err := rows.Err(); err != nil {
+		return StandbyAnalysis{}, fmt.Errorf(T("standby.err.measurements"), err)
+	}
+	return result, nil
+}
+
+// Hours возвращает часы сна от батареи за все недели
+func (s StandbyAnalysis) Hours() float64 {
+	var hours float64
+	for _, w := range s.Weeks {
+		hours += w.Hours
+	}
+	return hours
+}
+
+// Rate возвращает средний саморазряд во сне за все недели, %/ч
+func (s StandbyAnalysis) Rate() float64 {
+	var drain int
+	for _, w := range s.Weeks {
+		drain += w.Drain
+	}
+	if hours := s.Hours(); hours >= standbyMinHours {
+		return float64(drain) / hours
+	}
+	return 0
+}
+
+// Trend возвращает изменение саморазряда: средний по второй половине
+// недель с данными минус средний по первой, %/ч. ok=false – мало данных.
+func (s StandbyAnalysis) Trend() (delta float64, ok bool) {
+	var rates []float64
+	for _, w := range s.Weeks {
+		if r := w.Rate(); r > 0 {
+			rates = append(rates, r)
+		}
+	}
+	if len(rates) < 2 {
+		return 0, false
+	}
+	half := len(rates) / 2
+	return average(rates[len(rates)-half:]) - average(rates[:half]), true
+}
+
+// Summary возвращает строку со средним саморазрядом и сравнением с
+// ориентиром Apple; пустая строка – сна от батареи слишком мало
+func (s StandbyAnalysis) Summary() string {
+	rate := s.Rate()
+	if rate <= 0 {
+		return ""
+	}
+	summary := T("report.standby.summary", rate, formatDuration(time.Duration(s.Hours()*float64(time.Hour))), appleStandbyRate)
+	if delta, ok := s.Trend(); ok {
+		summary += "; " + T("report.standby.trend", signedFloat(delta))
+	}
+	return summary
+}
+
+// Recommendation советует, что проверить при повышенном саморазряде во сне
+func (s StandbyAnalysis) Recommendation() string {
+	if rate := s.Rate(); rate > appleStandbyRate*standbyAdviceFactor {
+		return T("rec.standby", rate, appleStandbyRate)
+	}
+	return ""
+}
+
+// renderStandbyWidget рисует виджет саморазряда во сне для вкладки прогнозов:
+// столбик на неделю, красный – выше ориентира Apple в standbyAdviceFactor раз
+func renderStandbyWidget(s StandbyAnalysis) string {
+	summary := s.Summary()
+	if summary == "" {
+		return ""
+	}
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(theme.Secondary).Render(T("report.standby")) + "\n")
+	content.WriteString(summary + "\n")
+
+	maxRate := appleStandbyRate * standbyAdviceFactor
+	for _, w := range s.Weeks {
+		maxRate = math.Max(maxRate, w.Rate())
+	}
+	normal := lipgloss.NewStyle().Foreground(theme.Good)
+	high := lipgloss.NewStyle().Foreground(theme.Critical)
+	for _, w := range s.Weeks {
+		rate := w.Rate()
+		if rate <= 0 {
+			continue
+		}
+		style := normal
+		if rate > appleStandbyRate*standbyAdviceFactor {
+			style = high
+		}
+		bar := strings.Repeat("█", max(1, int(rate/maxRate*20)))
+		content.WriteString(T("standby.week", w.Label(), style.Render(fmt.Sprintf("%-20s", bar)), rate))
+	}
+	content.WriteString("\n")
+	return content.String()
+}
+
+// average возвращает среднее значение
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
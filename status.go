@@ -0,0 +1,180 @@
+// status.go
+//
+// Разовый статус батареи для скриптов: `batmon status --json` опрашивает
+// источник один раз (pmset + ioreg на macOS), печатает JSON и завершается.
+// Подходит для виджетов SwiftBar/xbar и приглашения командной строки.
+// Измерение в базу не записывается.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// BatteryStatus – разовый снимок состояния батареи
+type BatteryStatus struct {
+	Timestamp          string  `json:"timestamp"` // RFC3339 UTC
+	Source             string  `json:"source"`
+	Hostname           string  `json:"hostname,omitempty"`
+	Model              string  `json:"model,omitempty"` // идентификатор hw.model, например Mac14,2
+	Percentage         int     `json:"percentage"`
+	State              string  `json:"state"`
+	CycleCount         int     `json:"cycle_count"`
+	FullChargeCapacity int     `json:"full_charge_capacity"`     // мАч
+	DesignCapacity     int     `json:"design_capacity"`          // мАч
+	CurrentCapacity    int     `json:"current_capacity"`         // мАч
+	FullChargeWh       float64 `json:"full_charge_wh,omitempty"` // Вт·ч по номинальному напряжению; 0 – напряжение неизвестно
+	DesignWh           float64 `json:"design_wh,omitempty"`
+	CurrentWh          float64 `json:"current_wh,omitempty"`
+	NominalVoltage     int     `json:"nominal_voltage,omitempty"` // мВ
+	WearPercent        float64 `json:"wear_percent"`
+	Temperature        int     `json:"temperature"` // °C
+	Voltage            int     `json:"voltage"`     // мВ
+	Amperage           int     `json:"amperage"`    // мА (+ заряд, - разряд)
+	Power              int     `json:"power"`       // мВт
+	Condition          string  `json:"condition,omitempty"`
+	Serial             string  `json:"serial,omitempty"`
+	HealthScore        int     `json:"health_score,omitempty"`             // рейтинг 0-100 по истории; 0 – истории нет
+	HealthModel        string  `json:"health_model,omitempty"`             // модель, посчитавшая рейтинг
+	CellVoltages       []int   `json:"cell_voltages,omitempty"`            // мВ по ячейкам
+	RemainingMinutes   int     `json:"remaining_minutes,omitempty"`        // до разрядки; 0 – неизвестно
+	RemainingMargin    int     `json:"remaining_margin_minutes,omitempty"` // ± минут к прогнозу по истории
+	RemainingSource    string  `json:"remaining_source,omitempty"`         // "os" – оценка системы, "history" – по истории batmon
+	Thermal            string  `json:"thermal"`                            // ok, warning или alarm
+	DetailsError       string  `json:"details_error,omitempty"`            // подробные данные недоступны
+}
+
+// collectStatus опрашивает источник один раз
+func collectStatus(source BatterySource) (*BatteryStatus, error) {
+	pct, state, err := source.Status()
+	if err != nil {
+		return nil, fmt.Errorf(T("err.status"), err)
+	}
+	status := &BatteryStatus{
+		Timestamp:  timeNow().UTC().Format(time.RFC3339),
+		Source:     source.Name(),
+		Percentage: pct,
+		State:      state,
+		Model:      hardwareModel(),
+	}
+	status.Hostname, _ = os.Hostname()
+	details, err := source.Details()
+	if err != nil {
+		status.DetailsError = err.Error()
+	} else {
+		status.CycleCount = details.CycleCount
+		status.FullChargeCapacity = details.FullChargeCap
+		status.DesignCapacity = details.DesignCapacity
+		status.CurrentCapacity = details.CurrentCapacity
+		status.WearPercent = computeWear(details.DesignCapacity, details.FullChargeCap)
+		status.Temperature = details.Temperature
+		status.Voltage = details.Voltage
+		status.Amperage = details.Amperage
+		status.Power = details.Voltage * details.Amperage / 1000
+		status.Condition = details.Condition
+		status.Serial = details.Serial
+		status.CellVoltages = details.CellVoltages
+		status.NominalVoltage = detailsNominalVoltage(details)
+		if status.NominalVoltage > 0 {
+			status.FullChargeWh = roundWh(capacityWh(details.FullChargeCap, status.NominalVoltage))
+			status.DesignWh = roundWh(capacityWh(details.DesignCapacity, status.NominalVoltage))
+			status.CurrentWh = roundWh(capacityWh(details.CurrentCapacity, status.NominalVoltage))
+		}
+	}
+
+	switch thermalLevel(Measurement{State: state, Percentage: pct, Temperature: status.Temperature}) {
+	case thermalAlarm:
+		status.Thermal = "alarm"
+	case thermalWarning:
+		status.Thermal = "warning"
+	default:
+		status.Thermal = "ok"
+	}
+
+	history := statusHistory()
+	if health := analyzeBatteryHealth(history); health != nil {
+		status.HealthScore = health.HealthScore
+		status.HealthModel = string(health.ScoreModel)
+	}
+
+	if state == "discharging" {
+		if estimator, ok := source.(RemainingEstimator); ok {
+			if d, ok, err := estimator.Remaining(); err == nil && ok {
+				status.RemainingMinutes = int(d.Minutes())
+				status.RemainingSource = "os"
+			}
+		}
+		if status.RemainingMinutes == 0 && status.CurrentCapacity > 0 {
+			if estimate := historyRemaining(history); estimate.Expected > 0 {
+				status.RemainingMinutes = int(estimate.Expected.Minutes())
+				status.RemainingMargin = int(estimate.Margin.Minutes())
+				status.RemainingSource = "history"
+			}
+		}
+	}
+	return status, nil
+}
+
+// statusHistory возвращает измерения из базы для оценок по истории. Базу не
+// создает: без истории оценок просто нет.
+func statusHistory() []Measurement {
+	if _, err := os.Stat(getDBPath()); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+	ms, err := loadReportMeasurements(db, ReportRange{}, reportLastN)
+	if err != nil {
+		return nil
+	}
+	return ms
+}
+
+// historyRemaining оценивает остаток по скорости разрядки за последние
+// измерения истории
+func historyRemaining(history []Measurement) RemainingEstimate {
+	ms := lastMeasurements(history, 200)
+	rate, _ := computeAvgRateRobust(ms, 10)
+	return forecastRemaining(currentBatterySegment(ms), rate)
+}
+
+// runStatusCommand печатает текущий статус: JSON для скриптов или строку для человека
+func runStatusCommand(args []string) error {
+	fs := newCommandFlags("status")
+	asJSON := fs.Bool("json", false, localized("flag.status.json", "вывести JSON"))
+	if err := parseCommandFlags(fs, args); err != nil {
+		return err
+	}
+	status, err := collectStatus(newBatterySource())
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	}
+
+	line := fmt.Sprintf("%d%% %s", status.Percentage, formatStateWithEmoji(status.State, status.Percentage))
+	if status.RemainingMinutes > 0 {
+		line += T("status.remaining") + RemainingEstimate{
+			Expected: time.Duration(status.RemainingMinutes) * time.Minute,
+			Margin:   time.Duration(status.RemainingMargin) * time.Minute,
+		}.String()
+	}
+	if status.DesignCapacity > 0 {
+		line += T("status.wear", status.WearPercent, status.CycleCount)
+	}
+	if status.Temperature > 0 {
+		line += fmt.Sprintf(", %d°C", status.Temperature)
+	}
+	fmt.Println(line)
+	return nil
+}
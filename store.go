@@ -0,0 +1,104 @@
+// store.go
+//
+// Store – единственное соединение интерфейса с базой данных. Раньше отчет,
+// экспорт и очистка в TUI открывали собственные соединения поверх
+// соединения сборщика, и запись измерения во время экспорта могла
+// получить «database is locked». Теперь App создает Store один раз и
+// передает его всем частям интерфейса; пул sqlx внутри сам распределяет
+// чтения, а WAL позволяет читать во время записи. Store же отвечает за
+// контрольные точки WAL: периодически во время работы и при закрытии,
+// чтобы файл -wal не разрастался и не оставался с несведенными данными.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// storeCheckpointInterval – как часто сборщик сводит WAL в основной файл
+const storeCheckpointInterval = time.Hour
+
+// Store – общее соединение с БД и его обслуживание
+type Store struct {
+	db   *sqlx.DB
+	path string
+
+	mu             sync.Mutex
+	lastCheckpoint time.Time
+	closed         bool
+}
+
+// openStore открывает БД по пути и применяет миграции
+func openStore(path string) (*Store, error) {
+	db, err := initDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return newStore(db, path), nil
+}
+
+// newStore оборачивает уже открытое соединение (воспроизведение записи, bench)
+func newStore(db *sqlx.DB, path string) *Store {
+	return &Store{db: db, path: path, lastCheckpoint: timeNow()}
+}
+
+// DB возвращает общее соединение
+func (s *Store) DB() *sqlx.DB {
+	return s.db
+}
+
+// Measurements возвращает хранилище измерений поверх общего соединения
+func (s *Store) Measurements() MeasurementStore {
+	return newSQLiteMeasurements(s.db)
+}
+
+// Path возвращает путь к файлу БД
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Checkpoint переносит содержимое WAL в основной файл и обрезает журнал
+func (s *Store) Checkpoint() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpointLocked()
+}
+
+func (s *Store) checkpointLocked() error {
+	s.lastCheckpoint = timeNow()
+	if _, err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf(T("store.err.checkpoint"), err)
+	}
+	return nil
+}
+
+// CheckpointIfDue делает контрольную точку, если с прошлой прошло больше
+// storeCheckpointInterval; вызывается из цикла сбора
+func (s *Store) CheckpointIfDue() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || timeNow().Sub(s.lastCheckpoint) < storeCheckpointInterval {
+		return nil
+	}
+	return s.checkpointLocked()
+}
+
+// Close сводит WAL и закрывает соединение. Повторный вызов (обработчик
+// сигнала и выход из TUI) ничего не делает.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	checkpointErr := s.checkpointLocked()
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf(T("store.err.close"), err)
+	}
+	return checkpointErr
+}
@@ -0,0 +1,170 @@
+// sync_storage.go
+//
+// Хранение базы в синхронизируемой папке (iCloud Drive, Dropbox): история
+// переезжает между Mac вместе с папкой. SQLite не рассчитан на одновременную
+// запись с разных машин через облако, поэтому:
+//   - писать может только один Mac – он держит файл блокировки с отметкой
+//     времени, и пока она свежая, коллекторы других Mac ничего не пишут;
+//   - после каждой записи WAL сбрасывается в основной файл (checkpoint), чтобы
+//     облако синхронизировало целостную базу, а не файл с отдельным журналом.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const syncLockStale = 15 * time.Minute // блокировка без обновления дольше считается брошенной
+
+// StorageConfig – где хранится база
+type StorageConfig struct {
+	SyncDir    string           `json:"sync_dir,omitempty"` // синхронизируемая папка для базы; пусто – локальная папка данных
+	ChangeOnly ChangeOnlyConfig `json:"change_only"`        // писать только измерения с изменениями
+}
+
+// syncDir возвращает синхронизируемую папку из конфига (пусто – режим выключен
+// или путь к базе задан флагом --db)
+func syncDir() string {
+	dir := getConfig().Storage.SyncDir
+	if dir == "" || dbPathOverride != "" {
+		return ""
+	}
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[2:])
+		}
+	}
+	return dir
+}
+
+// syncLockInfo – содержимое файла блокировки
+type syncLockInfo struct {
+	Host      string `json:"host"`
+	PID       int    `json:"pid"`
+	UpdatedAt string `json:"updated_at"` // RFC3339 UTC
+}
+
+// Fresh сообщает, держит ли владелец блокировку
+func (l syncLockInfo) Fresh(now time.Time) bool {
+	return now.Sub(parseStoredTime(l.UpdatedAt)) < syncLockStale
+}
+
+// readSyncLock читает блокировку базы; nil – блокировки нет
+func readSyncLock(dbPath string) (*syncLockInfo, error) {
+	raw, err := os.ReadFile(dbPath + ".lock")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("sync.err.read_lock"), err)
+	}
+	var info syncLockInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return nil, nil // поврежденная блокировка (например, конфликт синхронизации) не держит базу
+	}
+	return &info, nil
+}
+
+// syncStorage – блокировка и сброс журнала для базы в синхронизируемой папке.
+// Методы nil-значения ничего не делают: без sync_dir база локальная.
+type syncStorage struct {
+	dbPath string
+	host   string
+	held   bool // блокировка наша
+	warned bool // о чужой блокировке уже сообщили
+}
+
+// newSyncStorage возвращает управление синхронизируемой базой или nil, если режим выключен
+func newSyncStorage(dbPath string) *syncStorage {
+	if syncDir() == "" {
+		return nil
+	}
+	host, _ := os.Hostname()
+	for _, copy := range syncConflictCopies(filepath.Dir(dbPath)) {
+		logWarnf("⚠️ Конфликтная копия базы от облака: %s - объедините ее командой batmon db import", copy)
+	}
+	return &syncStorage{dbPath: dbPath, host: host}
+}
+
+// BeforeWrite захватывает или продлевает блокировку. Если базу держит другой
+// Mac, возвращает ошибку – записывать нельзя.
+func (s *syncStorage) BeforeWrite() error {
+	if s == nil {
+		return nil
+	}
+	now := timeNow()
+	info, err := readSyncLock(s.dbPath)
+	if err != nil {
+		return err
+	}
+	if info != nil && info.Host != s.host && info.Fresh(now) {
+		if !s.warned {
+			logInfof("🔒 База в %s используется на %s - запись с этого Mac приостановлена", s.dbPath, info.Host)
+			s.warned = true
+		}
+		s.held = false
+		return fmt.Errorf(T("sync.err.in_use"), info.Host,
+			parseStoredTime(info.UpdatedAt).Local().Format("02.01 15:04"))
+	}
+	if s.warned {
+		logInfof("🔓 Блокировка базы освобождена, запись возобновлена")
+		s.warned = false
+	}
+	raw, err := json.Marshal(syncLockInfo{Host: s.host, PID: os.Getpid(), UpdatedAt: now.UTC().Format(time.RFC3339)})
+	if err != nil {
+		return fmt.Errorf(T("sync.err.lock"), err)
+	}
+	tmp := s.dbPath + ".lock.tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return fmt.Errorf(T("sync.err.lock"), err)
+	}
+	if err := os.Rename(tmp, s.dbPath+".lock"); err != nil {
+		return fmt.Errorf(T("sync.err.lock"), err)
+	}
+	s.held = true
+	return nil
+}
+
+// AfterWrite переносит WAL в основной файл: облако синхронизирует
+// целостную базу, пока коллектор ждет следующую запись
+func (s *syncStorage) AfterWrite(db *sqlx.DB) {
+	if s == nil {
+		return
+	}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		logWarnf("⚠️ Сброс журнала БД: %v", err)
+	}
+}
+
+// Release сбрасывает журнал и снимает блокировку при остановке сбора
+func (s *syncStorage) Release(db *sqlx.DB) {
+	if s == nil || !s.held {
+		return
+	}
+	s.AfterWrite(db)
+	if info, err := readSyncLock(s.dbPath); err == nil && info != nil && info.Host == s.host {
+		os.Remove(s.dbPath + ".lock")
+	}
+	s.held = false
+}
+
+// syncConflictCopies ищет копии базы, которые облако создает при конфликте
+// ("batmon 2.sqlite" в iCloud, "batmon (conflicted copy).sqlite" в Dropbox)
+func syncConflictCopies(dir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "batmon*.sqlite"))
+	var copies []string
+	for _, path := range matches {
+		if filepath.Base(path) != "batmon.sqlite" {
+			copies = append(copies, path)
+		}
+	}
+	return copies
+}
@@ -0,0 +1,149 @@
+// theme.go
+//
+// Цветовые темы интерфейса. Все цвета TUI берутся из текущей темы theme по
+// смысловым ролям (норма, предупреждение, рамка…), а не задаются номерами
+// 256-цветной палитры в местах отрисовки. Встроенные темы: темная (по
+// умолчанию), светлая для светлого фона терминала и контрастная. В
+// config.json можно выбрать тему и переопределить отдельные цвета, Ctrl+T
+// переключает темы на ходу.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme – палитра интерфейса по смысловым ролям
+type Theme struct {
+	Name      string
+	Good      lipgloss.Color // норма, зарядка, низкие значения
+	Warning   lipgloss.Color // предупреждение
+	Caution   lipgloss.Color // между предупреждением и тревогой
+	Critical  lipgloss.Color // тревога, ошибка
+	AlertBg   lipgloss.Color // фон компактных предупреждений
+	Accent    lipgloss.Color // заголовки, рамки активных панелей, основной график
+	Info      lipgloss.Color // справочные значения
+	Secondary lipgloss.Color // второстепенные акценты и заголовки разделов
+	Border    lipgloss.Color // рамки, оси графиков
+	Muted     lipgloss.Color // подсказки, неактивные элементы
+	Highlight lipgloss.Color // выделенный текст (строка перекрестья)
+	OnAccent  lipgloss.Color // текст на цветном фоне активной вкладки
+	Selection lipgloss.Color // фон колонки перекрестья
+	Empty     lipgloss.Color // пустая ячейка тепловой карты
+	Dim       lipgloss.Color // слабый уровень тепловой карты
+}
+
+// Встроенные темы
+var (
+	darkTheme = Theme{
+		Name: "dark", Good: "82", Warning: "226", Caution: "214", Critical: "196", AlertBg: "52",
+		Accent: "39", Info: "14", Secondary: "141", Border: "240", Muted: "241",
+		Highlight: "229", OnAccent: "230", Selection: "238", Empty: "236", Dim: "22",
+	}
+	lightTheme = Theme{
+		Name: "light", Good: "28", Warning: "136", Caution: "166", Critical: "160", AlertBg: "224",
+		Accent: "25", Info: "30", Secondary: "91", Border: "250", Muted: "244",
+		Highlight: "94", OnAccent: "231", Selection: "253", Empty: "254", Dim: "151",
+	}
+	highContrastTheme = Theme{
+		Name: "high-contrast", Good: "10", Warning: "11", Caution: "208", Critical: "9", AlertBg: "0",
+		Accent: "14", Info: "14", Secondary: "13", Border: "15", Muted: "15",
+		Highlight: "15", OnAccent: "0", Selection: "4", Empty: "8", Dim: "2",
+	}
+)
+
+// builtinThemes – встроенные темы в порядке переключения
+var builtinThemes = []Theme{darkTheme, lightTheme, highContrastTheme}
+
+// theme – текущая тема интерфейса
+var theme = darkTheme
+
+// ThemeConfig – выбор темы в config.json
+type ThemeConfig struct {
+	Name   string            `json:"name,omitempty"`   // dark, light или high-contrast; пусто – dark
+	Colors map[string]string `json:"colors,omitempty"` // переопределение ролей: {"good": "#2e7d32", "border": "245"}
+}
+
+// roles возвращает цвета темы по именам ролей из config.json
+func (t *Theme) roles() map[string]*lipgloss.Color {
+	return map[string]*lipgloss.Color{
+		"good": &t.Good, "warning": &t.Warning, "caution": &t.Caution, "critical": &t.Critical,
+		"alert_bg": &t.AlertBg, "accent": &t.Accent, "info": &t.Info, "secondary": &t.Secondary,
+		"border": &t.Border, "muted": &t.Muted, "highlight": &t.Highlight, "on_accent": &t.OnAccent,
+		"selection": &t.Selection, "empty": &t.Empty, "dim": &t.Dim,
+	}
+}
+
+// findTheme возвращает встроенную тему по имени
+func findTheme(name string) (Theme, bool) {
+	for _, t := range builtinThemes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// resolveTheme собирает тему из настроек: встроенная тема и поверх нее
+// переопределенные цвета. Тема с переопределениями называется custom.
+func resolveTheme(cfg ThemeConfig) (Theme, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.Name))
+	if name == "" {
+		name = darkTheme.Name
+	}
+	t, ok := findTheme(name)
+	if !ok {
+		return darkTheme, fmt.Errorf(T("theme.err.unknown"), cfg.Name, themeNames())
+	}
+	if len(cfg.Colors) == 0 {
+		return t, nil
+	}
+	roles := t.roles()
+	for role, value := range cfg.Colors {
+		color, ok := roles[strings.ToLower(role)]
+		if !ok {
+			return t, fmt.Errorf(T("theme.err.role"), role)
+		}
+		*color = lipgloss.Color(value)
+	}
+	t.Name = "custom"
+	return t, nil
+}
+
+// themeNames возвращает имена встроенных тем через запятую
+func themeNames() string {
+	names := make([]string, len(builtinThemes))
+	for i, t := range builtinThemes {
+		names[i] = t.Name
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// applyThemeConfig делает текущей тему из настроек; при ошибке остается
+// темная тема или выбранная встроенная без переопределений
+func applyThemeConfig(cfg ThemeConfig) error {
+	t, err := resolveTheme(cfg)
+	theme = t
+	return err
+}
+
+// nextTheme переключает тему по кругу: настроенная в config.json (если в ней
+// переопределены цвета), затем встроенные
+func nextTheme() {
+	cycle := builtinThemes
+	if custom, err := resolveTheme(getConfig().Theme); err == nil && custom.Name == "custom" {
+		cycle = append([]Theme{custom}, builtinThemes...)
+	}
+	for i, t := range cycle {
+		if t.Name == theme.Name {
+			theme = cycle[(i+1)%len(cycle)]
+			return
+		}
+	}
+	theme = cycle[0]
+}
@@ -0,0 +1,172 @@
+// thermal.go
+//
+// Температурные предупреждения с учетом состояния питания: нагрев на
+// зарядке, особенно при высоком заряде, изнашивает батарею сильнее, чем
+// тот же нагрев при разрядке. Минуты "горячей зарядки" за неделю
+// показываются в отчете как показатель риска износа.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	hotChargeHighPercent = 80 // заряд, выше которого пороги на зарядке ниже, %
+	hotChargeWeeks       = 8  // недель истории горячей зарядки в отчете
+)
+
+// Уровни температурного предупреждения
+const (
+	thermalOK = iota
+	thermalWarning
+	thermalAlarm
+)
+
+// thermalThresholds возвращает пороги предупреждения и тревоги (°C) для состояния измерения
+func thermalThresholds(m Measurement) (warn, alarm int) {
+	switch {
+	case m.State == "charging" && m.Percentage >= hotChargeHighPercent:
+		return 30, 35
+	case m.State == "charging":
+		return 33, 38
+	}
+	return 35, 40
+}
+
+// thermalLevel возвращает уровень предупреждения для измерения
+func thermalLevel(m Measurement) int {
+	if m.Temperature <= 0 {
+		return thermalOK
+	}
+	warn, alarm := thermalThresholds(m)
+	switch {
+	case m.Temperature > alarm:
+		return thermalAlarm
+	case m.Temperature > warn:
+		return thermalWarning
+	}
+	return thermalOK
+}
+
+// thermalAlert возвращает текст предупреждения или пустую строку
+func thermalAlert(m Measurement) string {
+	level := thermalLevel(m)
+	charging := m.State == "charging"
+	switch {
+	case level == thermalAlarm && charging:
+		return T("thermal.alarm.charging", m.Temperature, m.Percentage)
+	case level == thermalAlarm:
+		return T("thermal.alarm", m.Temperature)
+	case level == thermalWarning && charging:
+		return T("thermal.warning.charging", m.Temperature, m.Percentage, hotChargeHighPercent)
+	case level == thermalWarning:
+		return T("thermal.warning")
+	}
+	return ""
+}
+
+// thermalStatusLevel переводит уровень в уровень printColoredStatus
+func thermalStatusLevel(m Measurement) string {
+	switch thermalLevel(m) {
+	case thermalAlarm:
+		return "critical"
+	case thermalWarning:
+		return "warning"
+	}
+	return "info"
+}
+
+// updateThermal сообщает о переходе в тревогу, чтобы не повторять уведомление каждые 30 секунд
+func (dc *DataCollector) updateThermal(m Measurement) {
+	alarm := thermalLevel(m) == thermalAlarm
+	if alarm && !dc.thermalAlarm {
+		alert := thermalAlert(m)
+		logInfof("🌡️ %s", alert)
+		if m.State == "charging" {
+			sendAlert(alertCritical, T("thermal.alert.title"), alert)
+		}
+	}
+	dc.thermalAlarm = alarm
+}
+
+// HotChargingWeek – минуты горячей зарядки за неделю
+type HotChargingWeek struct {
+	WeekStart time.Time // понедельник, местное время
+	Minutes   float64
+}
+
+// Label возвращает подпись недели
+func (w HotChargingWeek) Label() string {
+	return w.WeekStart.Format("02.01") + "–" + w.WeekStart.AddDate(0, 0, 6).Format("02.01")
+}
+
+// weekStart возвращает начало недели (понедельник 00:00) для момента t
+func weekStart(t time.Time) time.Time {
+	t = t.Local()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.Local)
+}
+
+// hotChargingByWeek считает минуты зарядки с температурой выше порога
+// предупреждения за последние недели. Интервал относится к состоянию в его
+// начале; пропуски дольше часа (сон, выключение) не учитываются. Измерения
+// читаются построчно, без загрузки периода в память.
+func hotChargingByWeek(db *sqlx.DB, weeks int, now time.Time) ([]HotChargingWeek, error) {
+	first := weekStart(now).AddDate(0, 0, -7*(weeks-1))
+	rows, err := db.Queryx(`SELECT timestamp, state, percentage, temperature FROM measurements
+		WHERE timestamp >= ? ORDER BY timestamp`, first.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf(T("thermal.err.read"), err)
+	}
+	defer rows.Close()
+
+	result := make([]HotChargingWeek, weeks)
+	for i := range result {
+		result[i].WeekStart = first.AddDate(0, 0, 7*i)
+	}
+	var prev *Measurement
+	for rows.Next() {
+		var m Measurement
+		if err := rows.StructScan(&m); err != nil {
+			return nil, fmt.Errorf(T("measurement.err.read"), err)
+		}
+		if prev != nil && prev.State == "charging" && thermalLevel(*prev) >= thermalWarning {
+			t1 := parseStoredTime(prev.Timestamp)
+			dt := parseStoredTime(m.Timestamp).Sub(t1)
+			if idx := int(math.Round(weekStart(t1).Sub(first).Hours() / (24 * 7))); dt > 0 && dt <= time.Hour && idx >= 0 && idx < weeks {
+				result[idx].Minutes += dt.Minutes()
+			}
+		}
+		prev = &m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf(T("thermal.err.read"), err)
+	}
+	return result, nil
+}
+
+// hotChargingRecommendation предупреждает о частой горячей зарядке за последнюю неделю
+func hotChargingRecommendation(weeks []HotChargingWeek) string {
+	if len(weeks) == 0 {
+		return ""
+	}
+	last := weeks[len(weeks)-1]
+	if last.Minutes < 60 {
+		return ""
+	}
+	return T("rec.hot_charging", formatDuration(time.Duration(last.Minutes*float64(time.Minute))))
+}
+
+// hotChargingTotal возвращает минуты горячей зарядки за все недели
+func hotChargingTotal(weeks []HotChargingWeek) float64 {
+	total := 0.0
+	for _, w := range weeks {
+		total += w.Minutes
+	}
+	return total
+}
@@ -0,0 +1,209 @@
+// thermal_events.go
+//
+// Температурные события: периоды, когда батарея дольше thermalEventMinDuration
+// держалась выше thermalEventTemp. Разовый скачок температуры безвреден, а
+// длительный нагрев ускоряет износ, поэтому такие периоды сохраняются в
+// таблицу thermal_events и показываются на вкладке «Аномалии» с
+// длительностью и пиковой температурой.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	thermalEventTemp        = 40               // °C, выше – батарея перегрета
+	thermalEventMinDuration = 10 * time.Minute // более короткий нагрев событием не считается
+	thermalEventsShown      = 20               // событий в отчете без периода
+)
+
+// thermalEventsSchema – таблица периодов длительного перегрева
+const thermalEventsSchema = `
+CREATE TABLE IF NOT EXISTS thermal_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	start_time TEXT NOT NULL UNIQUE,
+	end_time TEXT NOT NULL,
+	duration_seconds INTEGER NOT NULL,
+	peak_temperature INTEGER NOT NULL,
+	avg_temperature REAL NOT NULL,
+	charging INTEGER NOT NULL DEFAULT 0,
+	measurements INTEGER NOT NULL
+);`
+
+// ThermalEvent – период перегрева, найденный в измерениях
+type ThermalEvent struct {
+	Start        time.Time
+	End          time.Time
+	Peak         int     // °C
+	AvgTemp      float64 // °C
+	Charging     bool    // хотя бы часть периода шла зарядка
+	Measurements int
+}
+
+// Duration возвращает длительность события
+func (e ThermalEvent) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// ThermalEventRecord – сохранённое температурное событие
+type ThermalEventRecord struct {
+	ID              int     `db:"id" json:"id"`
+	StartTime       string  `db:"start_time" json:"start_time"`
+	EndTime         string  `db:"end_time" json:"end_time"`
+	DurationSeconds int     `db:"duration_seconds" json:"duration_seconds"`
+	PeakTemperature int     `db:"peak_temperature" json:"peak_temperature"` // °C
+	AvgTemperature  float64 `db:"avg_temperature" json:"avg_temperature"`   // °C
+	Charging        bool    `db:"charging" json:"charging"`
+	Measurements    int     `db:"measurements" json:"measurements"`
+}
+
+// Duration возвращает длительность события
+func (r ThermalEventRecord) Duration() time.Duration {
+	return time.Duration(r.DurationSeconds) * time.Second
+}
+
+// Start возвращает начало события в местном времени
+func (r ThermalEventRecord) Start() time.Time {
+	t, _ := time.Parse(time.RFC3339, r.StartTime)
+	return t.Local()
+}
+
+// detectThermalEvents находит периоды, когда температура держалась выше
+// thermalEventTemp не меньше thermalEventMinDuration. Пропуск в данных
+// больше sessionMaxGap (сон) завершает период.
+func detectThermalEvents(ms []Measurement) []ThermalEvent {
+	var events []ThermalEvent
+	var current *ThermalEvent
+	var tempSum int
+	var last time.Time
+
+	finish := func() {
+		if current != nil && current.Duration() >= thermalEventMinDuration {
+			current.AvgTemp = float64(tempSum) / float64(current.Measurements)
+			events = append(events, *current)
+		}
+		current = nil
+	}
+
+	for _, m := range ms {
+		at := parseStoredTime(m.Timestamp)
+		if at.IsZero() {
+			continue
+		}
+		if current != nil && at.Sub(last) > sessionMaxGap {
+			finish()
+		}
+		last = at
+		if m.Temperature <= thermalEventTemp {
+			if current != nil {
+				// Период заканчивается на первом остывшем измерении
+				current.End = at
+				finish()
+			}
+			continue
+		}
+		if current == nil {
+			current = &ThermalEvent{Start: at}
+			tempSum = 0
+		}
+		current.End = at
+		current.Measurements++
+		tempSum += m.Temperature
+		if m.Temperature > current.Peak {
+			current.Peak = m.Temperature
+		}
+		if m.State == "charging" {
+			current.Charging = true
+		}
+	}
+	finish()
+	return events
+}
+
+// thermalEventAnomalies описывает периоды перегрева для списка аномалий
+func thermalEventAnomalies(ms []Measurement) []Anomaly {
+	var anomalies []Anomaly
+	for _, e := range detectThermalEvents(ms) {
+		note := ""
+		if e.Charging {
+			note = T("thermal_event.charging")
+		}
+		severity := alertWarning
+		if e.Peak >= thermalCriticalTemp {
+			severity = alertCritical
+		}
+		anomalies = append(anomalies, Anomaly{
+			Type:     anomalyThermal,
+			Severity: severity,
+			Start:    e.Start,
+			End:      e.End,
+			Message: T("thermal_event.message",
+				note, thermalEventTemp, formatDuration(e.Duration()), e.Peak,
+				e.Start.Local().Format("15:04"), e.End.Local().Format("15:04")),
+			Metrics: map[string]float64{"peak_c": float64(e.Peak), "minutes": e.Duration().Minutes()},
+		})
+	}
+	return anomalies
+}
+
+// syncThermalEvents пересчитывает события начиная с последнего сохранённого:
+// перегрев мог продолжиться, поэтому событие удаляется и записывается заново
+func syncThermalEvents(db *sqlx.DB) error {
+	var lastStart string
+	if err := db.Get(&lastStart, `SELECT COALESCE(MAX(start_time), '') FROM thermal_events`); err != nil {
+		return fmt.Errorf(T("thermal_events.err.read"), err)
+	}
+
+	var since time.Time
+	if lastStart != "" {
+		since, _ = time.Parse(time.RFC3339, lastStart)
+	}
+	ms, err := getMeasurementsSince(db, since)
+	if err != nil {
+		return fmt.Errorf(T("thermal_events.err.measurements"), err)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf(T("thermal_events.err.transaction"), err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM thermal_events WHERE start_time >= ?`, lastStart); err != nil {
+		return fmt.Errorf(T("thermal_events.err.delete_open"), err)
+	}
+	for _, e := range detectThermalEvents(ms) {
+		_, err := tx.Exec(`INSERT INTO thermal_events (start_time, end_time, duration_seconds,
+			peak_temperature, avg_temperature, charging, measurements) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			e.Start.UTC().Format(time.RFC3339), e.End.UTC().Format(time.RFC3339), int(e.Duration().Seconds()),
+			e.Peak, e.AvgTemp, e.Charging, e.Measurements)
+		if err != nil {
+			return fmt.Errorf(T("thermal_events.err.save"), err)
+		}
+	}
+	return tx.Commit()
+}
+
+// getThermalEvents возвращает события периода (новые первыми), для пустого периода – последние limit
+func getThermalEvents(db *sqlx.DB, rng ReportRange, limit int) ([]ThermalEventRecord, error) {
+	var events []ThermalEventRecord
+	var err error
+	switch {
+	case rng.IsZero():
+		err = db.Select(&events, `SELECT * FROM thermal_events ORDER BY start_time DESC LIMIT ?`, limit)
+	case rng.To.IsZero():
+		err = db.Select(&events, `SELECT * FROM thermal_events WHERE end_time >= ? ORDER BY start_time DESC`,
+			rng.From.UTC().Format(time.RFC3339))
+	default:
+		err = db.Select(&events, `SELECT * FROM thermal_events WHERE end_time >= ? AND start_time <= ? ORDER BY start_time DESC`,
+			rng.From.UTC().Format(time.RFC3339), rng.To.UTC().Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, fmt.Errorf(T("thermal_events.err.read"), err)
+	}
+	return events, nil
+}
@@ -0,0 +1,361 @@
+// throttling.go
+//
+// Тепловое давление и троттлинг: раз в минуту коллектор записывает
+// ограничение частоты CPU из pmset -g therm и уровень теплового давления
+// (из powermetrics в подробном режиме, иначе из notifyutil – без root).
+// Жалобы «батарея села за два часа» и «Mac тормозит» часто вызваны одним и
+// тем же перегревом процессора, а не батареей. Отчет сопоставляет периоды
+// троттлинга с температурой батареи и скоростью разрядки и говорит, что
+// виновато: нагрузка и охлаждение или сама батарея.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	thermalSampleInterval = time.Minute      // как часто снимать тепловое давление
+	thermalSampleGap      = 5 * time.Minute  // дольше выборка не описывает состояние (сон, пропуски)
+	throttlePeriodsShown  = 5                // периодов троттлинга в отчете
+	throttleMinDrainTime  = 15 * time.Minute // меньше разрядки в режиме – скорость не сравниваем
+)
+
+// thermalSamplesSchema – выборки теплового давления
+const thermalSamplesSchema = `
+CREATE TABLE IF NOT EXISTS thermal_samples (
+	timestamp TEXT PRIMARY KEY,
+	pressure TEXT NOT NULL DEFAULT '',
+	speed_limit INTEGER NOT NULL DEFAULT 100,
+	warning_level INTEGER NOT NULL DEFAULT 0,
+	source TEXT NOT NULL DEFAULT ''
+);`
+
+// Источники уровня теплового давления
+const (
+	thermalSourcePowermetrics = "powermetrics"
+	thermalSourceNotify       = "notifyutil"
+)
+
+// thermalPressureLevels – уровни теплового давления по возрастанию; с
+// Heavy система ограничивает производительность
+var thermalPressureLevels = []string{"Nominal", "Moderate", "Heavy", "Trapping", "Sleeping"}
+
+// throttlePressureLevel – индекс Heavy в thermalPressureLevels
+const throttlePressureLevel = 2
+
+// ThermalSample – выборка теплового давления
+type ThermalSample struct {
+	Timestamp    string `db:"timestamp"`
+	Pressure     string `db:"pressure"`      // Nominal…Sleeping, пусто – неизвестно
+	SpeedLimit   int    `db:"speed_limit"`   // CPU_Speed_Limit из pmset -g therm, %; 100 – без ограничения
+	WarningLevel int    `db:"warning_level"` // уровень теплового предупреждения pmset, 0 – нет
+	Source       string `db:"source"`        // откуда уровень давления
+}
+
+// PressureLevel возвращает индекс уровня давления или -1
+func (s ThermalSample) PressureLevel() int {
+	for i, level := range thermalPressureLevels {
+		if strings.EqualFold(level, s.Pressure) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Throttled сообщает, что система ограничивала производительность
+func (s ThermalSample) Throttled() bool {
+	return s.PressureLevel() >= throttlePressureLevel || s.SpeedLimit < 100 || s.WarningLevel > 0
+}
+
+var (
+	pmsetSpeedLimitRe = regexp.MustCompile(`CPU_Speed_Limit\s*=\s*(\d+)`)
+	pmsetWarningRe    = regexp.MustCompile(`(?i)thermal\s*warning\s*level\s*(?:=|:|set to)\s*(\d+)`)
+	notifyPressureRe  = regexp.MustCompile(`thermalpressurelevel\s+(\d+)`)
+)
+
+// parsePmsetTherm разбирает pmset -g therm: последнее ограничение частоты
+// CPU и уровень теплового предупреждения. Без записей – ограничений нет.
+func parsePmsetTherm(out []byte) (speedLimit, warning int) {
+	speedLimit = 100
+	if all := pmsetSpeedLimitRe.FindAllSubmatch(out, -1); len(all) > 0 {
+		speedLimit, _ = strconv.Atoi(string(all[len(all)-1][1]))
+	}
+	if all := pmsetWarningRe.FindAllSubmatch(out, -1); len(all) > 0 {
+		warning, _ = strconv.Atoi(string(all[len(all)-1][1]))
+	}
+	return speedLimit, warning
+}
+
+// parseNotifyPressure переводит уровень из notifyutil -g
+// com.apple.system.thermalpressurelevel в название, как у powermetrics
+func parseNotifyPressure(out []byte) string {
+	m := notifyPressureRe.FindSubmatch(out)
+	if m == nil {
+		return ""
+	}
+	level, err := strconv.Atoi(string(m[1]))
+	if err != nil || level < 0 || level >= len(thermalPressureLevels) {
+		return ""
+	}
+	return thermalPressureLevels[level]
+}
+
+// readThermalSample снимает выборку. pressure – уровень из выборки
+// powermetrics, если она была; иначе уровень спрашивается у notifyutil.
+func readThermalSample(timestamp, pressure string) (*ThermalSample, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, nil // тепловое давление пока есть только на macOS
+	}
+	out, err := runCommand("pmset", "-g", "therm")
+	if err != nil {
+		return nil, fmt.Errorf("pmset -g therm: %w", err)
+	}
+	s := ThermalSample{Timestamp: timestamp, Pressure: pressure, Source: thermalSourcePowermetrics}
+	s.SpeedLimit, s.WarningLevel = parsePmsetTherm(out)
+	if pressure == "" {
+		s.Source = ""
+		if out, err := runCommand("notifyutil", "-g", "com.apple.system.thermalpressurelevel"); err == nil {
+			if s.Pressure = parseNotifyPressure(out); s.Pressure != "" {
+				s.Source = thermalSourceNotify
+			}
+		}
+	}
+	return &s, nil
+}
+
+// insertThermalSample сохраняет выборку теплового давления
+func insertThermalSample(db *sqlx.DB, s ThermalSample) error {
+	_, err := db.NamedExec(`INSERT OR REPLACE INTO thermal_samples (timestamp, pressure, speed_limit, warning_level, source)
+		VALUES (:timestamp, :pressure, :speed_limit, :warning_level, :source)`, s)
+	if err != nil {
+		return fmt.Errorf(T("throttling.err.save"), err)
+	}
+	return nil
+}
+
+// getThermalSamples возвращает выборки за время измерений по возрастанию
+func getThermalSamples(db *sqlx.DB, ms []Measurement) ([]ThermalSample, error) {
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	var samples []ThermalSample
+	if err := db.Select(&samples, `SELECT * FROM thermal_samples WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp`,
+		ms[0].Timestamp, ms[len(ms)-1].Timestamp); err != nil {
+		return nil, fmt.Errorf(T("throttling.err.read"), err)
+	}
+	return samples, nil
+}
+
+// ThrottlePeriod – период троттлинга
+type ThrottlePeriod struct {
+	Start      time.Time
+	End        time.Time
+	Pressure   string  // наибольшее давление за период
+	SpeedLimit int     // наименьшее ограничение частоты CPU, %
+	AvgTemp    float64 // средняя температура батареи, °C; 0 – нет данных
+}
+
+// Duration возвращает длительность периода
+func (p ThrottlePeriod) Duration() time.Duration {
+	return p.End.Sub(p.Start)
+}
+
+// String возвращает строку для отчетов
+func (p ThrottlePeriod) String() string {
+	s := fmt.Sprintf("%s – %s", p.Start.Local().Format("02.01 15:04"), formatDuration(p.Duration()))
+	if p.Pressure != "" {
+		s += T("throttling.period.pressure", (PowerSample{ThermalPressure: p.Pressure}).ThermalLabel())
+	}
+	if p.SpeedLimit < 100 {
+		s += T("throttling.period.speed", p.SpeedLimit)
+	}
+	if p.AvgTemp > 0 {
+		s += T("throttling.period.temp", p.AvgTemp)
+	}
+	return s
+}
+
+// ThrottlingAnalysis – троттлинг за период отчета и его связь с батареей
+type ThrottlingAnalysis struct {
+	Observed       time.Duration    // время, покрытое выборками
+	Throttled      time.Duration    // из него с троттлингом
+	Periods        []ThrottlePeriod // самые длинные первыми, не больше throttlePeriodsShown
+	Episodes       int              // всего периодов троттлинга
+	TempThrottled  float64          // средняя температура батареи при троттлинге, °C
+	TempNormal     float64          // и без него
+	DrainThrottled float64          // разрядка при троттлинге, %/ч; 0 – мало данных
+	DrainNormal    float64          // и без него
+	HotBattery     time.Duration    // батарея выше thermalEventTemp без троттлинга
+}
+
+// Share возвращает долю времени с троттлингом, %
+func (a ThrottlingAnalysis) Share() float64 {
+	if a.Observed <= 0 {
+		return 0
+	}
+	return float64(a.Throttled) / float64(a.Observed) * 100
+}
+
+// Summary возвращает строку итогов; пустая строка – выборок нет
+func (a ThrottlingAnalysis) Summary() string {
+	if a.Observed <= 0 {
+		return ""
+	}
+	if a.Throttled <= 0 {
+		return T("throttling.none", formatDuration(a.Observed))
+	}
+	s := T("throttling.summary",
+		formatDuration(a.Throttled), formatDuration(a.Observed), a.Share(), a.Episodes)
+	if a.TempThrottled > 0 && a.TempNormal > 0 {
+		s += T("throttling.summary.temp", a.TempThrottled, a.TempNormal)
+	}
+	if a.DrainThrottled > 0 && a.DrainNormal > 0 {
+		s += T("throttling.summary.drain", a.DrainThrottled, a.DrainNormal)
+	}
+	return s
+}
+
+// Verdict объясняет, что виновато: нагрузка и охлаждение или батарея
+func (a ThrottlingAnalysis) Verdict() string {
+	if a.Observed <= 0 {
+		return ""
+	}
+	var parts []string
+	switch {
+	case a.Throttled > 0 && a.TempThrottled > 0 && a.TempThrottled < thermalEventTemp:
+		parts = append(parts, T("throttling.verdict.cpu", a.TempThrottled))
+	case a.Throttled > 0 && a.TempThrottled >= thermalEventTemp:
+		parts = append(parts, T("throttling.verdict.both"))
+	}
+	if a.DrainThrottled > 0 && a.DrainNormal > 0 && a.DrainThrottled > a.DrainNormal*1.2 {
+		parts = append(parts, T("throttling.verdict.drain", a.DrainThrottled/a.DrainNormal))
+	}
+	if a.HotBattery > 0 {
+		parts = append(parts, T("throttling.verdict.hot_battery", thermalEventTemp, formatDuration(a.HotBattery)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// throttleState находит выборку, описывающую момент t: последнюю не позже
+// t и не старше thermalSampleGap
+func throttleState(samples []ThermalSample, times []time.Time, t time.Time) (ThermalSample, bool) {
+	i := sort.Search(len(times), func(i int) bool { return times[i].After(t) }) - 1
+	if i < 0 || t.Sub(times[i]) > thermalSampleGap {
+		return ThermalSample{}, false
+	}
+	return samples[i], true
+}
+
+// analyzeThrottling сопоставляет выборки теплового давления с измерениями
+// батареи. Интервал между измерениями относится к состоянию в его начале.
+func analyzeThrottling(samples []ThermalSample, ms []Measurement) ThrottlingAnalysis {
+	var a ThrottlingAnalysis
+	if len(samples) == 0 {
+		return a
+	}
+	times := make([]time.Time, len(samples))
+	for i, s := range samples {
+		times[i] = parseStoredTime(s.Timestamp)
+	}
+
+	// Время с троттлингом и его периоды
+	var current *ThrottlePeriod
+	for i, s := range samples {
+		end := times[i].Add(thermalSampleGap)
+		if i+1 < len(samples) && times[i+1].Before(end) {
+			end = times[i+1]
+		}
+		a.Observed += end.Sub(times[i])
+		if !s.Throttled() {
+			current = nil
+			continue
+		}
+		a.Throttled += end.Sub(times[i])
+		if current == nil || times[i].After(current.End) {
+			a.Periods = append(a.Periods, ThrottlePeriod{Start: times[i], SpeedLimit: 100})
+			current = &a.Periods[len(a.Periods)-1]
+		}
+		current.End = end
+		if s.PressureLevel() > (ThermalSample{Pressure: current.Pressure}).PressureLevel() {
+			current.Pressure = s.Pressure
+		}
+		current.SpeedLimit = min(current.SpeedLimit, s.SpeedLimit)
+	}
+
+	// Температура и разрядка батареи в обоих режимах
+	var tempSum, tempN [2]float64
+	var drain [2]int
+	var hours [2]float64
+	for i, m := range ms {
+		at := parseStoredTime(m.Timestamp)
+		s, ok := throttleState(samples, times, at)
+		if !ok {
+			continue
+		}
+		mode := 0
+		if s.Throttled() {
+			mode = 1
+		}
+		if m.Temperature > 0 {
+			tempSum[mode] += float64(m.Temperature)
+			tempN[mode]++
+		}
+		if i+1 == len(ms) {
+			continue
+		}
+		next := ms[i+1]
+		nextAt := parseStoredTime(next.Timestamp)
+		if isSleepGap(at, nextAt) {
+			continue
+		}
+		if mode == 0 && m.Temperature >= thermalEventTemp {
+			a.HotBattery += nextAt.Sub(at)
+		}
+		if m.State == "discharging" && next.State == "discharging" {
+			drain[mode] += m.Percentage - next.Percentage
+			hours[mode] += nextAt.Sub(at).Hours()
+		}
+	}
+	if tempN[0] > 0 {
+		a.TempNormal = tempSum[0] / tempN[0]
+	}
+	if tempN[1] > 0 {
+		a.TempThrottled = tempSum[1] / tempN[1]
+	}
+	if hours[0] >= throttleMinDrainTime.Hours() && drain[0] > 0 {
+		a.DrainNormal = float64(drain[0]) / hours[0]
+	}
+	if hours[1] >= throttleMinDrainTime.Hours() && drain[1] > 0 {
+		a.DrainThrottled = float64(drain[1]) / hours[1]
+	}
+
+	// Средняя температура батареи в каждом периоде
+	for i := range a.Periods {
+		p := &a.Periods[i]
+		var sum, n float64
+		for _, m := range ms {
+			if at := parseStoredTime(m.Timestamp); m.Temperature > 0 && !at.Before(p.Start) && !at.After(p.End) {
+				sum += float64(m.Temperature)
+				n++
+			}
+		}
+		if n > 0 {
+			p.AvgTemp = sum / n
+		}
+	}
+	a.Episodes = len(a.Periods)
+	sort.SliceStable(a.Periods, func(i, j int) bool { return a.Periods[i].Duration() > a.Periods[j].Duration() })
+	if len(a.Periods) > throttlePeriodsShown {
+		a.Periods = a.Periods[:throttlePeriodsShown]
+	}
+	return a
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeThrottling(t *testing.T) {
+	// Четыре часа разрядки; с 2:00 до 3:00 троттлинг, батарея садится вдвое
+	// быстрее, но остается прохладной
+	const step = 10 * time.Minute
+	throttled := func(at time.Time) bool {
+		d := at.Sub(fixtureStart)
+		return d >= 2*time.Hour && d < 3*time.Hour
+	}
+	var ms []Measurement
+	current := fixtureHealthyBattery.Full
+	for i := 0; i < 24; i++ {
+		at := fixtureStart.Add(time.Duration(i) * step)
+		m := fixtureHealthyBattery.measurement(at, current)
+		if throttled(at) {
+			m.Temperature = 33
+			current -= 2 * fixtureHealthyBattery.DrainMAh
+		} else {
+			current -= fixtureHealthyBattery.DrainMAh
+		}
+		ms = append(ms, m)
+	}
+	var samples []ThermalSample
+	for at := fixtureStart; !at.After(fixtureStart.Add(230 * time.Minute)); at = at.Add(5 * time.Minute) {
+		s := ThermalSample{Timestamp: at.UTC().Format(time.RFC3339), Pressure: "Nominal", SpeedLimit: 100}
+		if throttled(at) {
+			s.Pressure, s.SpeedLimit = "Heavy", 70
+		}
+		samples = append(samples, s)
+	}
+
+	a := analyzeThrottling(samples, ms)
+	if a.Observed != 235*time.Minute || a.Throttled != time.Hour {
+		t.Fatalf("наблюдение %s, троттлинг %s", a.Observed, a.Throttled)
+	}
+	if a.Episodes != 1 || a.Periods[0].SpeedLimit != 70 || a.Periods[0].Pressure != "Heavy" {
+		t.Fatalf("периоды %+v", a.Periods)
+	}
+	if a.TempThrottled != 33 || a.TempNormal != 31 {
+		t.Errorf("температура %.1f / %.1f", a.TempThrottled, a.TempNormal)
+	}
+	if a.DrainThrottled < 1.8*a.DrainNormal {
+		t.Errorf("разрядка при троттлинге %.1f%%/ч, без него %.1f%%/ч", a.DrainThrottled, a.DrainNormal)
+	}
+	if v := a.Verdict(); !strings.Contains(v, "батарея тут ни при чем") || !strings.Contains(v, "быстрее") {
+		t.Errorf("вывод %q", v)
+	}
+
+	if limit, warning := parsePmsetTherm([]byte("Note: No thermal warning level has been recorded\n" +
+		"2026-03-02 12:00:00 +0000 CPU Power notify\n\tCPU_Scheduler_Limit \t= 100\n\tCPU_Speed_Limit \t= 76\n")); limit != 76 || warning != 0 {
+		t.Errorf("pmset -g therm: ограничение %d, предупреждение %d", limit, warning)
+	}
+	if p := parseNotifyPressure([]byte("com.apple.system.thermalpressurelevel 2\n")); p != "Heavy" {
+		t.Errorf("notifyutil: %q", p)
+	}
+}
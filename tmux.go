@@ -0,0 +1,136 @@
+// tmux.go
+//
+// Короткий виджет заряда для строки статуса tmux: `batmon tmux-status`.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tmuxDefaultCacheTTL = 30 * time.Second // как часто разрешено обращаться к SQLite
+	tmuxStaleAfter      = 10 * time.Minute // после этого данные считаются устаревшими
+)
+
+// getTmuxCachePath возвращает путь к файлу кэша виджета tmux
+func getTmuxCachePath() string {
+	dataDir, err := getDataDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "batmon-tmux-status.cache")
+	}
+	return filepath.Join(dataDir, "tmux-status.cache")
+}
+
+// readTmuxCache возвращает закэшированную строку, если она моложе ttl.
+// Формат файла: первая строка – unix-время записи, дальше – готовый вывод.
+func readTmuxCache(path string, ttl time.Duration) (string, bool) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	header, body, found := strings.Cut(string(raw), "\n")
+	if !found {
+		return "", false
+	}
+	unix, err := strconv.ParseInt(header, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	if time.Since(time.Unix(unix, 0)) >= ttl {
+		return "", false
+	}
+	return body, true
+}
+
+// writeTmuxCache сохраняет строку статуса вместе с отметкой времени
+func writeTmuxCache(path, status string) error {
+	content := fmt.Sprintf("%d\n%s", time.Now().Unix(), status)
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// tmuxStateSymbol возвращает короткий символ состояния питания
+func tmuxStateSymbol(state string) string {
+	switch strings.ToLower(state) {
+	case "charging":
+		return "⚡"
+	case "discharging":
+		return "▼"
+	case "charged", "finishing":
+		return "🔌"
+	default:
+		return "?"
+	}
+}
+
+// tmuxPercentColor подбирает цвет tmux по уровню заряда
+func tmuxPercentColor(pct int) string {
+	switch {
+	case pct > 50:
+		return "green"
+	case pct > 20:
+		return "yellow"
+	default:
+		return "red"
+	}
+}
+
+// formatTmuxStatus формирует строку с кодами форматирования tmux, например
+// "#[fg=green]85% ▼ 3:20#[default]". Устаревшие данные выводятся серым.
+func formatTmuxStatus(ms []Measurement, now time.Time) string {
+	if len(ms) == 0 {
+		return "#[fg=colour244]🔋 --#[default]"
+	}
+
+	latest := ms[len(ms)-1]
+	fg := tmuxPercentColor(latest.Percentage)
+	if ts, err := time.Parse(time.RFC3339, latest.Timestamp); err == nil && now.Sub(ts) > tmuxStaleAfter {
+		fg = "colour244"
+	}
+
+	parts := []string{fmt.Sprintf("%d%%", latest.Percentage), tmuxStateSymbol(latest.State)}
+	if strings.ToLower(latest.State) == "discharging" {
+		rate, _ := computeAvgRateRobust(ms, 10)
+		if remaining := forecastRemaining(ms, rate).Expected; remaining > 0 {
+			hours := int(remaining.Hours())
+			minutes := int(remaining.Minutes()) % 60
+			parts = append(parts, fmt.Sprintf("%d:%02d", hours, minutes))
+		}
+	}
+
+	return fmt.Sprintf("#[fg=%s]%s#[default]", fg, strings.Join(parts, " "))
+}
+
+// runTmuxStatus печатает строку для status-right в tmux.
+// Обращается к БД не чаще одного раза за ttl, в остальное время отдаёт кэш.
+func runTmuxStatus(ttl time.Duration) error {
+	cachePath := getTmuxCachePath()
+	if cached, ok := readTmuxCache(cachePath, ttl); ok {
+		fmt.Print(cached)
+		return nil
+	}
+
+	db, err := initDB(getDBPath())
+	if err != nil {
+		return fmt.Errorf(T("err.db_init"), err)
+	}
+	defer db.Close()
+
+	ms, err := getLastNMeasurements(db, 20)
+	if err != nil {
+		return fmt.Errorf(T("err.data"), err)
+	}
+
+	status := formatTmuxStatus(ms, time.Now())
+	if err := writeTmuxCache(cachePath, status); err != nil {
+		// Кэш – лишь оптимизация, вывод важнее
+		fmt.Fprintln(os.Stderr, T("tmux.cache_failed", err))
+	}
+	fmt.Print(status)
+	return nil
+}
@@ -0,0 +1,162 @@
+// usage_heatmap.go
+//
+// Тепловая карта использования: дни × часы, ячейка окрашена по средней
+// скорости разряда или по времени от батареи в этот час. Повторяющиеся
+// пятна («каждый будний день в 9–11 батарея тает на созвонах») видны сразу,
+// чего не дают ни графики, ни суточные сводки. Карта строится по всем
+// измерениям периода отчета в местном времени и показывается на вкладке
+// «Графики» и в HTML-экспорте.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	heatmapDays       = 14              // последних дней на карте
+	heatmapMinBattery = 5 * time.Minute // меньше – скорость разряда за час не оценивается
+	heatmapLevels     = 5               // уровней цвета, не считая пустых ячеек
+)
+
+// heatmapColors возвращает цвета уровней карты в терминале, от пустой
+// ячейки к максимуму
+func heatmapColors() []lipgloss.Color {
+	return []lipgloss.Color{theme.Empty, theme.Dim, theme.Good, theme.Warning, theme.Caution, theme.Critical}
+}
+
+// UsageHeatmap – время от батареи и расход заряда по дням и часам
+type UsageHeatmap struct {
+	Days    []time.Time // полночь каждого дня, старые первыми
+	Battery [][24]time.Duration
+	Drain   [][24]float64 // израсходовано процентов заряда
+}
+
+// heatmapRow – строка карты для отрисовки
+type heatmapRow struct {
+	Label string
+	Cells [24]heatmapCell
+}
+
+// heatmapCell – ячейка карты: уровень цвета (0 – нет данных) и подсказка
+type heatmapCell struct {
+	Level int
+	Title string
+}
+
+// usageHeatmap раскладывает интервалы разрядки по дням и часам в часовом
+// поясе loc. Интервал относится к часу своего начала; пропуски дольше часа
+// (сон, выключение) не учитываются. На карте остаются последние days дней.
+func usageHeatmap(ms []Measurement, loc *time.Location, days int) UsageHeatmap {
+	var h UsageHeatmap
+	index := map[time.Time]int{}
+	for i := 1; i < len(ms); i++ {
+		prev := ms[i-1]
+		if prev.State != "discharging" {
+			continue
+		}
+		start := parseStoredTime(prev.Timestamp).In(loc)
+		dt := parseStoredTime(ms[i].Timestamp).Sub(start)
+		if dt <= 0 || dt > time.Hour {
+			continue
+		}
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		row, ok := index[day]
+		if !ok {
+			row = len(h.Days)
+			index[day] = row
+			h.Days = append(h.Days, day)
+			h.Battery = append(h.Battery, [24]time.Duration{})
+			h.Drain = append(h.Drain, [24]float64{})
+		}
+		h.Battery[row][start.Hour()] += dt
+		if drop := prev.Percentage - ms[i].Percentage; drop > 0 {
+			h.Drain[row][start.Hour()] += float64(drop)
+		}
+	}
+	if len(h.Days) > days {
+		cut := len(h.Days) - days
+		h.Days, h.Battery, h.Drain = h.Days[cut:], h.Battery[cut:], h.Drain[cut:]
+	}
+	return h
+}
+
+// Rate возвращает среднюю скорость разряда в ячейке, %/ч (0 – мало данных)
+func (h UsageHeatmap) Rate(day, hour int) float64 {
+	battery := h.Battery[day][hour]
+	if battery < heatmapMinBattery {
+		return 0
+	}
+	return h.Drain[day][hour] / battery.Hours()
+}
+
+// Rows возвращает строки карты; byTime – окраска по времени от батареи
+// вместо скорости разряда. Уровни считаются от максимума по всей карте.
+func (h UsageHeatmap) Rows(byTime bool) []heatmapRow {
+	value := func(d, hour int) float64 {
+		if byTime {
+			return h.Battery[d][hour].Minutes()
+		}
+		return h.Rate(d, hour)
+	}
+	var peak float64
+	for d := range h.Days {
+		for hour := 0; hour < 24; hour++ {
+			if v := value(d, hour); v > peak {
+				peak = v
+			}
+		}
+	}
+
+	rows := make([]heatmapRow, len(h.Days))
+	for d, day := range h.Days {
+		rows[d].Label = T(fmt.Sprintf("weekday.%d", day.Weekday())) + " " + day.Format("02.01")
+		for hour := 0; hour < 24; hour++ {
+			cell := &rows[d].Cells[hour]
+			cell.Title = T("report.heatmap.cell", day.Format("02.01"), hour, h.Battery[d][hour].Minutes())
+			if rate := h.Rate(d, hour); rate > 0 {
+				cell.Title += T("report.heatmap.rate", rate)
+			}
+			if v := value(d, hour); v > 0 && peak > 0 {
+				cell.Level = min(int(v/peak*heatmapLevels)+1, heatmapLevels)
+			}
+		}
+	}
+	return rows
+}
+
+// renderUsageHeatmap рендерит тепловую карту для терминала: строка – день,
+// колонка – час
+func renderUsageHeatmap(h UsageHeatmap, byTime bool) string {
+	if len(h.Days) == 0 {
+		return T("heatmap.empty")
+	}
+	var content strings.Builder
+	content.WriteString(strings.Repeat(" ", 10))
+	for hour := 0; hour < 24; hour += 3 {
+		content.WriteString(fmt.Sprintf("%-6d", hour))
+	}
+	content.WriteString("\n")
+	for _, row := range h.Rows(byTime) {
+		content.WriteString(fmt.Sprintf("%-10s", row.Label))
+		for _, cell := range row.Cells {
+			content.WriteString(lipgloss.NewStyle().Foreground(heatmapColors()[cell.Level]).Render("██"))
+		}
+		content.WriteString("\n")
+	}
+
+	legend := T("heatmap.legend.rate")
+	if byTime {
+		legend = T("heatmap.legend.time")
+	}
+	content.WriteString(T("heatmap.less"))
+	for _, c := range heatmapColors()[1:] {
+		content.WriteString(lipgloss.NewStyle().Foreground(c).Render("██"))
+	}
+	content.WriteString(T("heatmap.more", legend))
+	return content.String()
+}
@@ -0,0 +1,245 @@
+// wear_events.go
+//
+// Вехи износа: момент, когда износ батареи впервые перешел очередной целый
+// процент (11%, 12%, …). Вехи хранятся в таблице wear_events, о новых
+// коллектор присылает уведомление, а отчет рисует по ним шкалу деградации –
+// сколько дней и циклов ушло на каждый процент. Полная ёмкость от
+// измерения к измерению скачет, поэтому веха засчитывается, только когда
+// wearMilestoneConfirm измерений подряд держатся на ней или выше.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	wearMilestoneConfirm = 3              // измерений подряд на вехе или выше
+	wearNotifyWindow     = 24 * time.Hour // о вехе старше этого уведомление не приходит (найдена в истории)
+)
+
+// wearEventsSchema – вехи износа по батареям
+const wearEventsSchema = `
+CREATE TABLE IF NOT EXISTS wear_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	battery_serial TEXT NOT NULL DEFAULT '',
+	milestone INTEGER NOT NULL,
+	reached_at TEXT NOT NULL,
+	wear REAL NOT NULL,
+	cycle_count INTEGER NOT NULL,
+	full_charge_capacity INTEGER NOT NULL,
+	design_capacity INTEGER NOT NULL,
+	initial INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (battery_serial, milestone)
+);`
+
+// WearEvent – веха износа
+type WearEvent struct {
+	ID             int     `db:"id" json:"id"`
+	BatterySerial  string  `db:"battery_serial" json:"battery_serial"`
+	Milestone      int     `db:"milestone" json:"milestone"` // целый процент износа
+	ReachedAt      string  `db:"reached_at" json:"reached_at"`
+	Wear           float64 `db:"wear" json:"wear"` // точный износ в момент вехи, %
+	CycleCount     int     `db:"cycle_count" json:"cycle_count"`
+	FullChargeCap  int     `db:"full_charge_capacity" json:"full_charge_capacity"`
+	DesignCapacity int     `db:"design_capacity" json:"design_capacity"`
+	Initial        bool    `db:"initial" json:"initial"` // износ на начало наблюдений, а не переход
+}
+
+// Time возвращает момент вехи в местном времени
+func (e WearEvent) Time() time.Time {
+	return parseStoredTime(e.ReachedAt).Local()
+}
+
+// detectWearEvents находит новые вехи в измерениях. known – последняя
+// записанная веха по серийному номеру батареи; для батареи без вех первая
+// подтвержденная становится начальной точкой, а не переходом.
+func detectWearEvents(ms []Measurement, known map[string]int) []WearEvent {
+	type streak struct {
+		first Measurement
+		level int // наименьшая веха в серии
+		n     int
+	}
+	streaks := map[string]*streak{}
+	var events []WearEvent
+
+	for _, m := range ms {
+		if m.DesignCapacity <= 0 || m.FullChargeCap <= 0 {
+			continue
+		}
+		level := int(computeWear(m.DesignCapacity, m.FullChargeCap))
+		last, seen := known[m.BatterySerial]
+		if level < 0 || (seen && level <= last) {
+			delete(streaks, m.BatterySerial)
+			continue
+		}
+		s := streaks[m.BatterySerial]
+		if s == nil {
+			s = &streak{first: m, level: level}
+			streaks[m.BatterySerial] = s
+		}
+		s.level = min(s.level, level)
+		s.n++
+		if s.n < wearMilestoneConfirm {
+			continue
+		}
+
+		from := s.level
+		if seen {
+			from = last + 1
+		}
+		for milestone := from; milestone <= s.level; milestone++ {
+			events = append(events, WearEvent{
+				BatterySerial:  m.BatterySerial,
+				Milestone:      milestone,
+				ReachedAt:      parseStoredTime(s.first.Timestamp).UTC().Format(time.RFC3339),
+				Wear:           computeWear(s.first.DesignCapacity, s.first.FullChargeCap),
+				CycleCount:     s.first.CycleCount,
+				FullChargeCap:  s.first.FullChargeCap,
+				DesignCapacity: s.first.DesignCapacity,
+				Initial:        !seen,
+			})
+		}
+		known[m.BatterySerial] = s.level
+		delete(streaks, m.BatterySerial)
+	}
+	return events
+}
+
+// syncWearEvents ищет новые вехи в измерениях после последней записанной и
+// возвращает их
+func syncWearEvents(db *sqlx.DB) ([]WearEvent, error) {
+	var rows []struct {
+		Serial    string `db:"battery_serial"`
+		Milestone int    `db:"milestone"`
+		ReachedAt string `db:"reached_at"`
+	}
+	if err := db.Select(&rows, `SELECT battery_serial, MAX(milestone) AS milestone, MAX(reached_at) AS reached_at
+		FROM wear_events GROUP BY battery_serial`); err != nil {
+		return nil, fmt.Errorf(T("wear_events.err.read"), err)
+	}
+	known := map[string]int{}
+	var since time.Time
+	for _, r := range rows {
+		known[r.Serial] = r.Milestone
+		if t := parseStoredTime(r.ReachedAt); t.After(since) {
+			since = t
+		}
+	}
+
+	ms, err := getMeasurementsSince(db, since)
+	if err != nil {
+		return nil, fmt.Errorf(T("wear_events.err.measurements"), err)
+	}
+	events := detectWearEvents(ms, known)
+	for _, e := range events {
+		_, err := db.NamedExec(`INSERT OR IGNORE INTO wear_events (battery_serial, milestone, reached_at, wear,
+			cycle_count, full_charge_capacity, design_capacity, initial) VALUES (:battery_serial, :milestone,
+			:reached_at, :wear, :cycle_count, :full_charge_capacity, :design_capacity, :initial)`, e)
+		if err != nil {
+			return nil, fmt.Errorf(T("wear_events.err.save"), err)
+		}
+	}
+	return events, nil
+}
+
+// getWearEvents возвращает вехи батареи по возрастанию
+func getWearEvents(db *sqlx.DB, serial string) ([]WearEvent, error) {
+	var events []WearEvent
+	if err := db.Select(&events, `SELECT * FROM wear_events WHERE battery_serial = ? ORDER BY milestone`, serial); err != nil {
+		return nil, fmt.Errorf(T("wear_events.err.read"), err)
+	}
+	return events, nil
+}
+
+// wearMilestoneMessage описывает новую веху и темп: сколько дней и циклов
+// ушло на последний процент
+func wearMilestoneMessage(e WearEvent, prev *WearEvent) string {
+	msg := T("wear_event.reached", e.Milestone, e.CycleCount)
+	if prev != nil {
+		days := int(e.Time().Sub(prev.Time()).Hours() / 24)
+		msg += T("wear_event.pace", prev.Milestone, e.Milestone, days, e.CycleCount-prev.CycleCount)
+	}
+	return msg
+}
+
+// updateWearEvents записывает новые вехи и сообщает о последней из них
+func (dc *DataCollector) updateWearEvents() {
+	events, err := syncWearEvents(dc.db)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+		return
+	}
+	var latest *WearEvent
+	for i, e := range events {
+		if !e.Initial && timeNow().Sub(parseStoredTime(e.ReachedAt)) < wearNotifyWindow {
+			latest = &events[i]
+		}
+	}
+	if latest == nil {
+		return
+	}
+	history, err := getWearEvents(dc.db, latest.BatterySerial)
+	if err != nil {
+		logWarnf("⚠️ %v", err)
+		return
+	}
+	var prev *WearEvent
+	for i := range history {
+		if history[i].Milestone < latest.Milestone {
+			prev = &history[i]
+		}
+	}
+	message := wearMilestoneMessage(*latest, prev)
+	logInfof("📉 %s", message)
+	sendAlert(alertInfo, T("wear_event.alert"), message)
+}
+
+// WearStep – шаг шкалы деградации: переход к вехе от предыдущей
+type WearStep struct {
+	Event  WearEvent
+	Days   float64 // дней от предыдущей вехи; 0 – начальная точка
+	Cycles int     // циклов от предыдущей вехи
+}
+
+// wearTimeline превращает вехи в шаги шкалы деградации
+func wearTimeline(events []WearEvent) []WearStep {
+	steps := make([]WearStep, len(events))
+	for i, e := range events {
+		steps[i].Event = e
+		if i > 0 {
+			steps[i].Days = e.Time().Sub(events[i-1].Time()).Hours() / 24
+			steps[i].Cycles = e.CycleCount - events[i-1].CycleCount
+		}
+	}
+	return steps
+}
+
+// Bar возвращает полосу длиной в число недель на процент износа: чем
+// длиннее, тем медленнее деградация
+func (s WearStep) Bar() string {
+	if s.Days <= 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, int(s.Days/7+0.5)))
+}
+
+// Label возвращает подпись шага
+func (s WearStep) Label() string {
+	if s.Event.Initial {
+		return T("wear_event.initial", s.Event.Milestone)
+	}
+	return fmt.Sprintf("%d%%", s.Event.Milestone)
+}
+
+// Pace возвращает темп шага: дней и циклов на процент
+func (s WearStep) Pace() string {
+	if s.Event.Initial || s.Days <= 0 {
+		return "–"
+	}
+	return T("wear_event.step", s.Days, s.Cycles)
+}
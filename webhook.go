@@ -0,0 +1,222 @@
+// webhook.go
+//
+// Оповещения: тот же поток событий, что и системные уведомления (перегрев,
+// порог заряда, износ по порогам batmon check), можно отправлять в вебхуки –
+// например, в канал Slack ИТ-отдела, а не на экран пользователя. Тело
+// запроса задается шаблоном text/template, для Slack есть готовый пресет.
+// Отправка требует разрешения network.webhooks.
+
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// Уровни оповещений
+const (
+	alertInfo     = "info"
+	alertWarning  = "warning"
+	alertCritical = "critical"
+)
+
+// alertLevels – порядок уровней для фильтра min_level
+var alertLevels = map[string]int{alertInfo: 0, alertWarning: 1, alertCritical: 2}
+
+// Шаблоны тела запроса: обычный JSON и сообщение Slack (incoming webhook)
+const (
+	webhookPresetSlack     = "slack"
+	webhookGenericTemplate = `{"level": {{json .Level}}, "title": {{json .Title}}, "text": {{json .Text}}, "timestamp": {{json .Timestamp}}, "host": {{json .Host}}}`
+	webhookSlackTemplate   = `{"text": {{json (printf "%s *%s*\n%s\n_%s_" .Icon .Title .Text .Host)}}}`
+	defaultWebhookMinLevel = alertWarning
+)
+
+// WebhookConfig – адрес вебхука и формат сообщения
+type WebhookConfig struct {
+	URL      string `json:"url"`
+	Preset   string `json:"preset,omitempty"`    // slack; пусто – обычный JSON
+	Template string `json:"template,omitempty"`  // свой шаблон тела, важнее пресета
+	MinLevel string `json:"min_level,omitempty"` // info, warning или critical; пусто – warning
+}
+
+// Alert – оповещение для уведомления и вебхуков
+type Alert struct {
+	Level     string `json:"level"`
+	Title     string `json:"title"`
+	Text      string `json:"text"`
+	Timestamp string `json:"timestamp"` // RFC3339 UTC
+	Host      string `json:"host"`
+}
+
+// Icon возвращает эмодзи уровня для мессенджеров
+func (a Alert) Icon() string {
+	switch a.Level {
+	case alertCritical:
+		return "🚨"
+	case alertWarning:
+		return "⚠️"
+	}
+	return "ℹ️"
+}
+
+// sendAlert показывает системное уведомление и отправляет оповещение в вебхуки
+func sendAlert(level, title, text string) {
+	notifyUser(title, text)
+	sendWebhooks(newAlert(level, title, text))
+}
+
+// newAlert заполняет время и имя компьютера
+func newAlert(level, title, text string) Alert {
+	host, _ := os.Hostname()
+	return Alert{Level: level, Title: title, Text: text, Timestamp: timeNow().UTC().Format(time.RFC3339), Host: host}
+}
+
+// sendWebhooks отправляет оповещение во все подходящие по уровню вебхуки в фоне
+func sendWebhooks(alert Alert) {
+	cfg := getConfig()
+	if len(cfg.Webhooks) == 0 {
+		return
+	}
+	if err := requireNetwork(NetworkWebhooks); err != nil {
+		logWarnf("⚠️ %v", err)
+		return
+	}
+	for _, hook := range cfg.Webhooks {
+		if alertLevels[alert.Level] < alertLevels[hook.minLevel()] {
+			continue
+		}
+		go func(hook WebhookConfig) {
+			if err := postWebhook(hook, alert); err != nil {
+				logWarnf("⚠️ %v", err)
+			}
+		}(hook)
+	}
+}
+
+// minLevel возвращает минимальный уровень оповещений вебхука
+func (w WebhookConfig) minLevel() string {
+	if _, ok := alertLevels[w.MinLevel]; ok {
+		return w.MinLevel
+	}
+	return defaultWebhookMinLevel
+}
+
+// body формирует тело запроса по шаблону вебхука
+func (w WebhookConfig) body(alert Alert) ([]byte, error) {
+	text := w.Template
+	if text == "" {
+		text = webhookGenericTemplate
+		if strings.EqualFold(w.Preset, webhookPresetSlack) {
+			text = webhookSlackTemplate
+		}
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			raw, err := json.Marshal(v)
+			return string(raw), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf(T("webhook.err.template"), err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return nil, fmt.Errorf(T("webhook.err.template"), err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf(T("webhook.err.not_json"), buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// postWebhook отправляет одно оповещение
+func postWebhook(hook WebhookConfig, alert Alert) error {
+	body, err := hook.body(alert)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(hook.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf(T("err.webhook"), hook.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf(T("webhook.err.status"), hook.URL, resp.Status)
+	}
+	return nil
+}
+
+// updateHealthAlert оповещает, когда износ, циклы или число аномалий впервые
+// переходят пороги batmon check (раздел health в config.json). Последний
+// уровень хранится в analysis_state, чтобы перезапуск коллектора или
+// collect --once не повторяли оповещение.
+func (dc *DataCollector) updateHealthAlert(m Measurement) {
+	if m.DesignCapacity <= 0 {
+		return
+	}
+	if dc.healthCode == nil {
+		code, err := loadHealthAlertCode(dc.db)
+		if err != nil {
+			logWarnf("⚠️ %v", err)
+			return
+		}
+		dc.healthCode = &code
+	}
+	check := &HealthCheck{Wear: computeWear(m.DesignCapacity, m.FullChargeCap), Cycles: m.CycleCount}
+	if dc.history != nil {
+		check.Anomalies = dc.history.Anomalies
+	}
+	evaluateHealth(check, getConfig().Health)
+	if check.Code == *dc.healthCode {
+		return
+	}
+	if check.Code > *dc.healthCode {
+		level := alertWarning
+		if check.Code == checkCritical {
+			level = alertCritical
+		}
+		sendAlert(level, T("webhook.health_alert"), strings.Join(check.Problems, "; "))
+	}
+	*dc.healthCode = check.Code
+	if err := saveHealthAlertCode(dc.db, check.Code); err != nil {
+		logWarnf("⚠️ %v", err)
+	}
+}
+
+// loadHealthAlertCode возвращает уровень последнего оповещения о состоянии
+func loadHealthAlertCode(db *sqlx.DB) (int, error) {
+	var raw string
+	err := db.Get(&raw, `SELECT state FROM analysis_state WHERE name = 'health_alert'`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return checkOK, nil
+	}
+	if err != nil {
+		return checkOK, fmt.Errorf(T("alert_level.err.read"), err)
+	}
+	code, _ := strconv.Atoi(raw)
+	return code, nil
+}
+
+// saveHealthAlertCode запоминает уровень последнего оповещения о состоянии
+func saveHealthAlertCode(db *sqlx.DB, code int) error {
+	_, err := db.Exec(`INSERT OR REPLACE INTO analysis_state (name, state, updated_at) VALUES ('health_alert', ?, ?)`,
+		strconv.Itoa(code), timeNow().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf(T("alert_level.err.save"), err)
+	}
+	return nil
+}
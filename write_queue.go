@@ -0,0 +1,118 @@
+// write_queue.go
+//
+// Пакетная запись измерений: коллектор копит измерения в памяти и пишет их
+// в БД одной транзакцией раз в несколько измерений или минут. Запись каждые
+// 30 секунд будит диск и гоняет WAL – на батарее это сам по себе расход.
+// Интерфейс получает измерения из буфера памяти сразу, без ожидания записи.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWriteBatchSize = 10              // измерений в пакете по умолчанию (~5 минут)
+	writeBatchMaxDelay    = 5 * time.Minute // дольше измерение в очереди не ждет
+)
+
+// writeQueue – измерения, ожидающие записи в БД. Сбор в TUI идет в
+// отдельных горутинах, поэтому очередь защищена мьютексом.
+type writeQueue struct {
+	mu           sync.Mutex
+	store        MeasurementStore
+	pending      []Measurement
+	since        time.Time // когда в очередь попало первое измерение
+	lastState    string    // режим питания последнего измерения
+	stateChanged bool      // сменился режим питания – сессии должны увидеть измерения сразу
+}
+
+// newWriteQueue создает очередь записи
+func newWriteQueue(store MeasurementStore) *writeQueue {
+	return &writeQueue{store: store}
+}
+
+// Add ставит измерение в очередь
+func (q *writeQueue) Add(m Measurement) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		q.since = timeNow()
+	}
+	if q.lastState != "" && m.State != q.lastState {
+		q.stateChanged = true
+	}
+	q.lastState = m.State
+	q.pending = append(q.pending, m)
+}
+
+// Due сообщает, пора ли записать очередь: пакет набран, первое измерение
+// ждет слишком долго или сменился режим питания
+func (q *writeQueue) Due(batchSize int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending) > 0 &&
+		(len(q.pending) >= batchSize || q.stateChanged || timeNow().Sub(q.since) >= writeBatchMaxDelay)
+}
+
+// Drop отбрасывает очередь
+func (q *writeQueue) Drop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = q.pending[:0]
+	q.stateChanged = false
+}
+
+// Flush записывает очередь и значения производных метрик одной транзакцией.
+// При ошибке очередь сохраняется для следующей попытки.
+func (q *writeQueue) Flush(metrics []DerivedMetric) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return nil
+	}
+	if err := q.store.InsertMeasurements(q.pending, metrics); err != nil {
+		return err
+	}
+	q.pending = q.pending[:0]
+	q.stateChanged = false
+	return nil
+}
+
+// writeBatchSize возвращает размер пакета из конфига; 1 – писать сразу
+func writeBatchSize() int {
+	if n := getConfig().Power.WriteBatchSize; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// flushWrites записывает очередь измерений коллектора. Если базу в
+// синхронизируемой папке держит другой Mac, очередь отбрасывается:
+// копить ее в памяти бесконечно нельзя.
+func (dc *DataCollector) flushWrites() error {
+	if err := dc.storage.BeforeWrite(); err != nil {
+		dc.writes.Drop()
+		return fmt.Errorf(T("queue.err.save"), err)
+	}
+	metrics, _ := configuredMetrics() // ошибки в метриках показывает batmon metrics
+	if err := dc.writes.Flush(metrics); err != nil {
+		return fmt.Errorf(T("queue.err.save"), err)
+	}
+	dc.storage.AfterWrite(dc.db)
+	return nil
+}
+
+// Flush записывает измерения, ожидающие в очереди
+func (dc *DataCollector) Flush() error {
+	return dc.flushWrites()
+}
+
+// Close записывает очередь и снимает блокировку базы при остановке сбора
+func (dc *DataCollector) Close() error {
+	err := dc.flushWrites()
+	dc.storage.Release(dc.db)
+	return err
+}
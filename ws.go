@@ -0,0 +1,99 @@
+// ws.go
+//
+// WebSocket-поток измерений /api/v1/ws для внешних интерфейсов (веб-дашборды,
+// расширения Raycast): каждое новое измерение уходит отдельным текстовым
+// сообщением в том же JSON, что /api/v1/latest. Как и поток SSE, опрашивает
+// БД – измерения пишет другой процесс batmon. При подключении сразу
+// отправляется последнее измерение, а ?after=<id> продолжает поток с места
+// обрыва.
+
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPongTimeout  = 2 * streamKeepAlive // столько ждем ответа на пинг, прежде чем закрыть соединение
+)
+
+// wsUpgrader принимает подключения с любых Origin: страницы внешних
+// дашбордов открыты с других адресов, а доступ защищает токен
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// wsMeasurements отправляет новые измерения в WebSocket до отключения клиента
+func wsMeasurements(w http.ResponseWriter, r *http.Request, db *sqlx.DB) {
+	cursor, err := streamStart(db, r.URL.Query().Get("after"))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade уже ответил клиенту
+	}
+	defer conn.Close()
+
+	// Клиент ничего не присылает, но читать нужно: так обрабатываются
+	// закрытие соединения и ответы на пинги
+	closed := make(chan struct{})
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	ping := time.NewTicker(streamKeepAlive)
+	defer ping.Stop()
+
+	send := func() bool {
+		ms, err := getMeasurementsAfter(db, cursor, streamMaxBatchSize)
+		if err != nil {
+			logWarnf("⚠️ Поток WebSocket: %v", err)
+			return true // БД может быть занята, попробуем на следующем тике
+		}
+		for _, m := range ms {
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(m); err != nil {
+				return false
+			}
+			cursor.advance(m)
+		}
+		return true
+	}
+
+	if !send() {
+		return
+	}
+	for {
+		select {
+		case <-closed:
+			return
+		case <-poll.C:
+			if !send() {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}